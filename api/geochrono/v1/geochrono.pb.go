@@ -0,0 +1,794 @@
+// Service definition of geo-chrono as a rendering microservice.
+//
+// Serve mode offers the service with -grpc-addr, next to its HTTP track API.
+// The messages follow the settings and outputs the command already has, so
+// tracks are processed exactly like uploads to the HTTP track API. Regenerate
+// the Go code with "buf generate" in the api directory.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.35.2
+// 	protoc        (unknown)
+// source: geochrono/v1/geochrono.proto
+
+package geochronov1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Format is an output format of GenerateMap, named as in -export.
+type Format int32
+
+const (
+	Format_FORMAT_UNSPECIFIED Format = 0 // HTML
+	Format_FORMAT_HTML        Format = 1
+	Format_FORMAT_KML         Format = 2
+	Format_FORMAT_GEOJSON     Format = 3
+	Format_FORMAT_GPX         Format = 4
+)
+
+// Enum value maps for Format.
+var (
+	Format_name = map[int32]string{
+		0: "FORMAT_UNSPECIFIED",
+		1: "FORMAT_HTML",
+		2: "FORMAT_KML",
+		3: "FORMAT_GEOJSON",
+		4: "FORMAT_GPX",
+	}
+	Format_value = map[string]int32{
+		"FORMAT_UNSPECIFIED": 0,
+		"FORMAT_HTML":        1,
+		"FORMAT_KML":         2,
+		"FORMAT_GEOJSON":     3,
+		"FORMAT_GPX":         4,
+	}
+)
+
+func (x Format) Enum() *Format {
+	p := new(Format)
+	*p = x
+	return p
+}
+
+func (x Format) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Format) Descriptor() protoreflect.EnumDescriptor {
+	return file_geochrono_v1_geochrono_proto_enumTypes[0].Descriptor()
+}
+
+func (Format) Type() protoreflect.EnumType {
+	return &file_geochrono_v1_geochrono_proto_enumTypes[0]
+}
+
+func (x Format) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Format.Descriptor instead.
+func (Format) EnumDescriptor() ([]byte, []int) {
+	return file_geochrono_v1_geochrono_proto_rawDescGZIP(), []int{0}
+}
+
+// Point is one GPS fix, as read from a CSV row or GPX track point.
+type Point struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Timestamp   *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unset for untimed points
+	Latitude    float64                `protobuf:"fixed64,2,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude   float64                `protobuf:"fixed64,3,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Elevation   float64                `protobuf:"fixed64,4,opt,name=elevation,proto3" json:"elevation,omitempty"` // Meters
+	Title       string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	Description string                 `protobuf:"bytes,6,opt,name=description,proto3" json:"description,omitempty"`
+	Category    string                 `protobuf:"bytes,7,opt,name=category,proto3" json:"category,omitempty"`
+	User        string                 `protobuf:"bytes,8,opt,name=user,proto3" json:"user,omitempty"` // Owner in multi-user tracks
+}
+
+func (x *Point) Reset() {
+	*x = Point{}
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Point) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Point) ProtoMessage() {}
+
+func (x *Point) ProtoReflect() protoreflect.Message {
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Point.ProtoReflect.Descriptor instead.
+func (*Point) Descriptor() ([]byte, []int) {
+	return file_geochrono_v1_geochrono_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Point) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Point) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Point) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Point) GetElevation() float64 {
+	if x != nil {
+		return x.Elevation
+	}
+	return 0
+}
+
+func (x *Point) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Point) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Point) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Point) GetUser() string {
+	if x != nil {
+		return x.User
+	}
+	return ""
+}
+
+// TrackOptions describes a track; it is the first message of a stream.
+type TrackOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // Map title, as the name of an uploaded track
+}
+
+func (x *TrackOptions) Reset() {
+	*x = TrackOptions{}
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackOptions) ProtoMessage() {}
+
+func (x *TrackOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackOptions.ProtoReflect.Descriptor instead.
+func (*TrackOptions) Descriptor() ([]byte, []int) {
+	return file_geochrono_v1_geochrono_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TrackOptions) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// ProcessTrackRequest is one message of a ProcessTrack stream: the options
+// first, then the points in any order.
+type ProcessTrackRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Item:
+	//	*ProcessTrackRequest_Options
+	//	*ProcessTrackRequest_Point
+	Item isProcessTrackRequest_Item `protobuf_oneof:"item"`
+}
+
+func (x *ProcessTrackRequest) Reset() {
+	*x = ProcessTrackRequest{}
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessTrackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessTrackRequest) ProtoMessage() {}
+
+func (x *ProcessTrackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessTrackRequest.ProtoReflect.Descriptor instead.
+func (*ProcessTrackRequest) Descriptor() ([]byte, []int) {
+	return file_geochrono_v1_geochrono_proto_rawDescGZIP(), []int{2}
+}
+
+func (m *ProcessTrackRequest) GetItem() isProcessTrackRequest_Item {
+	if m != nil {
+		return m.Item
+	}
+	return nil
+}
+
+func (x *ProcessTrackRequest) GetOptions() *TrackOptions {
+	if x, ok := x.GetItem().(*ProcessTrackRequest_Options); ok {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ProcessTrackRequest) GetPoint() *Point {
+	if x, ok := x.GetItem().(*ProcessTrackRequest_Point); ok {
+		return x.Point
+	}
+	return nil
+}
+
+type isProcessTrackRequest_Item interface {
+	isProcessTrackRequest_Item()
+}
+
+type ProcessTrackRequest_Options struct {
+	Options *TrackOptions `protobuf:"bytes,1,opt,name=options,proto3,oneof"`
+}
+
+type ProcessTrackRequest_Point struct {
+	Point *Point `protobuf:"bytes,2,opt,name=point,proto3,oneof"`
+}
+
+func (*ProcessTrackRequest_Options) isProcessTrackRequest_Item() {}
+
+func (*ProcessTrackRequest_Point) isProcessTrackRequest_Item() {}
+
+// MapOptions chooses the output of GenerateMap; it is the first message of a
+// stream.
+type MapOptions struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Track  *TrackOptions `protobuf:"bytes,1,opt,name=track,proto3" json:"track,omitempty"`
+	Format Format        `protobuf:"varint,2,opt,name=format,proto3,enum=geochrono.v1.Format" json:"format,omitempty"`
+}
+
+func (x *MapOptions) Reset() {
+	*x = MapOptions{}
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MapOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MapOptions) ProtoMessage() {}
+
+func (x *MapOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MapOptions.ProtoReflect.Descriptor instead.
+func (*MapOptions) Descriptor() ([]byte, []int) {
+	return file_geochrono_v1_geochrono_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *MapOptions) GetTrack() *TrackOptions {
+	if x != nil {
+		return x.Track
+	}
+	return nil
+}
+
+func (x *MapOptions) GetFormat() Format {
+	if x != nil {
+		return x.Format
+	}
+	return Format_FORMAT_UNSPECIFIED
+}
+
+// GenerateMapRequest is one message of a GenerateMap stream: the options
+// first, then the points in any order.
+type GenerateMapRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Item:
+	//	*GenerateMapRequest_Options
+	//	*GenerateMapRequest_Point
+	Item isGenerateMapRequest_Item `protobuf_oneof:"item"`
+}
+
+func (x *GenerateMapRequest) Reset() {
+	*x = GenerateMapRequest{}
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerateMapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateMapRequest) ProtoMessage() {}
+
+func (x *GenerateMapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateMapRequest.ProtoReflect.Descriptor instead.
+func (*GenerateMapRequest) Descriptor() ([]byte, []int) {
+	return file_geochrono_v1_geochrono_proto_rawDescGZIP(), []int{4}
+}
+
+func (m *GenerateMapRequest) GetItem() isGenerateMapRequest_Item {
+	if m != nil {
+		return m.Item
+	}
+	return nil
+}
+
+func (x *GenerateMapRequest) GetOptions() *MapOptions {
+	if x, ok := x.GetItem().(*GenerateMapRequest_Options); ok {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *GenerateMapRequest) GetPoint() *Point {
+	if x, ok := x.GetItem().(*GenerateMapRequest_Point); ok {
+		return x.Point
+	}
+	return nil
+}
+
+type isGenerateMapRequest_Item interface {
+	isGenerateMapRequest_Item()
+}
+
+type GenerateMapRequest_Options struct {
+	Options *MapOptions `protobuf:"bytes,1,opt,name=options,proto3,oneof"`
+}
+
+type GenerateMapRequest_Point struct {
+	Point *Point `protobuf:"bytes,2,opt,name=point,proto3,oneof"`
+}
+
+func (*GenerateMapRequest_Options) isGenerateMapRequest_Item() {}
+
+func (*GenerateMapRequest_Point) isGenerateMapRequest_Item() {}
+
+// MapChunk is part of the rendered output; the chunks concatenated in order
+// are the file the command would write.
+type MapChunk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data      []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	MediaType string `protobuf:"bytes,2,opt,name=media_type,json=mediaType,proto3" json:"media_type,omitempty"` // Set on the first chunk, such as "text/html; charset=utf-8"
+}
+
+func (x *MapChunk) Reset() {
+	*x = MapChunk{}
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MapChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MapChunk) ProtoMessage() {}
+
+func (x *MapChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MapChunk.ProtoReflect.Descriptor instead.
+func (*MapChunk) Descriptor() ([]byte, []int) {
+	return file_geochrono_v1_geochrono_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MapChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *MapChunk) GetMediaType() string {
+	if x != nil {
+		return x.MediaType
+	}
+	return ""
+}
+
+// TrackSummary holds the route statistics of a processed track, as written to
+// the statistics JSON export.
+type TrackSummary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Points          int64                  `protobuf:"varint,1,opt,name=points,proto3" json:"points,omitempty"`
+	DistanceMeters  float64                `protobuf:"fixed64,2,opt,name=distance_meters,json=distanceMeters,proto3" json:"distance_meters,omitempty"`
+	DurationSeconds int64                  `protobuf:"varint,3,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	MovingSeconds   int64                  `protobuf:"varint,4,opt,name=moving_seconds,json=movingSeconds,proto3" json:"moving_seconds,omitempty"`
+	AverageSpeedKmh float64                `protobuf:"fixed64,5,opt,name=average_speed_kmh,json=averageSpeedKmh,proto3" json:"average_speed_kmh,omitempty"`
+	MaxSpeedKmh     float64                `protobuf:"fixed64,6,opt,name=max_speed_kmh,json=maxSpeedKmh,proto3" json:"max_speed_kmh,omitempty"`
+	Start           *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=start,proto3" json:"start,omitempty"`
+	End             *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=end,proto3" json:"end,omitempty"`
+	Id              string                 `protobuf:"bytes,9,opt,name=id,proto3" json:"id,omitempty"` // ID of the stored track, as in /maps/{id}.html
+}
+
+func (x *TrackSummary) Reset() {
+	*x = TrackSummary{}
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackSummary) ProtoMessage() {}
+
+func (x *TrackSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_geochrono_v1_geochrono_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackSummary.ProtoReflect.Descriptor instead.
+func (*TrackSummary) Descriptor() ([]byte, []int) {
+	return file_geochrono_v1_geochrono_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TrackSummary) GetPoints() int64 {
+	if x != nil {
+		return x.Points
+	}
+	return 0
+}
+
+func (x *TrackSummary) GetDistanceMeters() float64 {
+	if x != nil {
+		return x.DistanceMeters
+	}
+	return 0
+}
+
+func (x *TrackSummary) GetDurationSeconds() int64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+func (x *TrackSummary) GetMovingSeconds() int64 {
+	if x != nil {
+		return x.MovingSeconds
+	}
+	return 0
+}
+
+func (x *TrackSummary) GetAverageSpeedKmh() float64 {
+	if x != nil {
+		return x.AverageSpeedKmh
+	}
+	return 0
+}
+
+func (x *TrackSummary) GetMaxSpeedKmh() float64 {
+	if x != nil {
+		return x.MaxSpeedKmh
+	}
+	return 0
+}
+
+func (x *TrackSummary) GetStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *TrackSummary) GetEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.End
+	}
+	return nil
+}
+
+func (x *TrackSummary) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+var File_geochrono_v1_geochrono_proto protoreflect.FileDescriptor
+
+var file_geochrono_v1_geochrono_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2f, 0x76, 0x31, 0x2f, 0x67,
+	0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c,
+	0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x1a, 0x1f, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x81, 0x02,
+	0x0a, 0x05, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x38, 0x0a, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x08, 0x6c, 0x61, 0x74, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x09, 0x6c, 0x6f, 0x6e, 0x67, 0x69, 0x74, 0x75, 0x64, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x65,
+	0x6c, 0x65, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09,
+	0x65, 0x6c, 0x65, 0x76, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74,
+	0x6c, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12,
+	0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x12, 0x12, 0x0a,
+	0x04, 0x75, 0x73, 0x65, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x73, 0x65,
+	0x72, 0x22, 0x22, 0x0a, 0x0c, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x82, 0x01, 0x0a, 0x13, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73,
+	0x73, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x36, 0x0a,
+	0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a,
+	0x2e, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72,
+	0x61, 0x63, 0x6b, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00, 0x52, 0x07, 0x6f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2b, 0x0a, 0x05, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f,
+	0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x05, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x42, 0x06, 0x0a, 0x04, 0x69, 0x74, 0x65, 0x6d, 0x22, 0x6c, 0x0a, 0x0a, 0x4d, 0x61,
+	0x70, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x30, 0x0a, 0x05, 0x74, 0x72, 0x61, 0x63,
+	0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72,
+	0x6f, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x4f, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x05, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x12, 0x2c, 0x0a, 0x06, 0x66, 0x6f,
+	0x72, 0x6d, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x14, 0x2e, 0x67, 0x65, 0x6f,
+	0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74,
+	0x52, 0x06, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x22, 0x7f, 0x0a, 0x12, 0x47, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x74, 0x65, 0x4d, 0x61, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x34,
+	0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x18, 0x2e, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
+	0x61, 0x70, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x48, 0x00, 0x52, 0x07, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2b, 0x0a, 0x05, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2e,
+	0x76, 0x31, 0x2e, 0x50, 0x6f, 0x69, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x05, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x42, 0x06, 0x0a, 0x04, 0x69, 0x74, 0x65, 0x6d, 0x22, 0x3d, 0x0a, 0x08, 0x4d, 0x61, 0x70,
+	0x43, 0x68, 0x75, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6d,
+	0x65, 0x64, 0x69, 0x61, 0x54, 0x79, 0x70, 0x65, 0x22, 0xe1, 0x02, 0x0a, 0x0c, 0x54, 0x72, 0x61,
+	0x63, 0x6b, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x6f, 0x69,
+	0x6e, 0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x70, 0x6f, 0x69, 0x6e, 0x74,
+	0x73, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x5f, 0x6d, 0x65,
+	0x74, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x64, 0x69, 0x73, 0x74,
+	0x61, 0x6e, 0x63, 0x65, 0x4d, 0x65, 0x74, 0x65, 0x72, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x64, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x6d, 0x6f, 0x76, 0x69, 0x6e, 0x67, 0x5f,
+	0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x6d,
+	0x6f, 0x76, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x2a, 0x0a, 0x11,
+	0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x6b, 0x6d,
+	0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65,
+	0x53, 0x70, 0x65, 0x65, 0x64, 0x4b, 0x6d, 0x68, 0x12, 0x22, 0x0a, 0x0d, 0x6d, 0x61, 0x78, 0x5f,
+	0x73, 0x70, 0x65, 0x65, 0x64, 0x5f, 0x6b, 0x6d, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0b, 0x6d, 0x61, 0x78, 0x53, 0x70, 0x65, 0x65, 0x64, 0x4b, 0x6d, 0x68, 0x12, 0x30, 0x0a, 0x05,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x2c,
+	0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x03, 0x65, 0x6e, 0x64, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x2a, 0x65, 0x0a, 0x06,
+	0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x12, 0x16, 0x0a, 0x12, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0f,
+	0x0a, 0x0b, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x48, 0x54, 0x4d, 0x4c, 0x10, 0x01, 0x12,
+	0x0e, 0x0a, 0x0a, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x4b, 0x4d, 0x4c, 0x10, 0x02, 0x12,
+	0x12, 0x0a, 0x0e, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x47, 0x45, 0x4f, 0x4a, 0x53, 0x4f,
+	0x4e, 0x10, 0x03, 0x12, 0x0e, 0x0a, 0x0a, 0x46, 0x4f, 0x52, 0x4d, 0x41, 0x54, 0x5f, 0x47, 0x50,
+	0x58, 0x10, 0x04, 0x32, 0xa9, 0x01, 0x0a, 0x09, 0x47, 0x65, 0x6f, 0x43, 0x68, 0x72, 0x6f, 0x6e,
+	0x6f, 0x12, 0x4f, 0x0a, 0x0c, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x54, 0x72, 0x61, 0x63,
+	0x6b, 0x12, 0x21, 0x2e, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f,
+	0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79,
+	0x28, 0x01, 0x12, 0x4b, 0x0a, 0x0b, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x4d, 0x61,
+	0x70, 0x12, 0x20, 0x2e, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2e, 0x76, 0x31,
+	0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x4d, 0x61, 0x70, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2e,
+	0x76, 0x31, 0x2e, 0x4d, 0x61, 0x70, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x28, 0x01, 0x30, 0x01, 0x42,
+	0x3d, 0x5a, 0x3b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x61,
+	0x72, 0x61, 0x74, 0x69, 0x6c, 0x79, 0x2f, 0x67, 0x65, 0x6f, 0x2d, 0x63, 0x68, 0x72, 0x6f, 0x6e,
+	0x6f, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x2f,
+	0x76, 0x31, 0x3b, 0x67, 0x65, 0x6f, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x6f, 0x76, 0x31, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_geochrono_v1_geochrono_proto_rawDescOnce sync.Once
+	file_geochrono_v1_geochrono_proto_rawDescData = file_geochrono_v1_geochrono_proto_rawDesc
+)
+
+func file_geochrono_v1_geochrono_proto_rawDescGZIP() []byte {
+	file_geochrono_v1_geochrono_proto_rawDescOnce.Do(func() {
+		file_geochrono_v1_geochrono_proto_rawDescData = protoimpl.X.CompressGZIP(file_geochrono_v1_geochrono_proto_rawDescData)
+	})
+	return file_geochrono_v1_geochrono_proto_rawDescData
+}
+
+var file_geochrono_v1_geochrono_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_geochrono_v1_geochrono_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_geochrono_v1_geochrono_proto_goTypes = []any{
+	(Format)(0),                   // 0: geochrono.v1.Format
+	(*Point)(nil),                 // 1: geochrono.v1.Point
+	(*TrackOptions)(nil),          // 2: geochrono.v1.TrackOptions
+	(*ProcessTrackRequest)(nil),   // 3: geochrono.v1.ProcessTrackRequest
+	(*MapOptions)(nil),            // 4: geochrono.v1.MapOptions
+	(*GenerateMapRequest)(nil),    // 5: geochrono.v1.GenerateMapRequest
+	(*MapChunk)(nil),              // 6: geochrono.v1.MapChunk
+	(*TrackSummary)(nil),          // 7: geochrono.v1.TrackSummary
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+}
+var file_geochrono_v1_geochrono_proto_depIdxs = []int32{
+	8,  // 0: geochrono.v1.Point.timestamp:type_name -> google.protobuf.Timestamp
+	2,  // 1: geochrono.v1.ProcessTrackRequest.options:type_name -> geochrono.v1.TrackOptions
+	1,  // 2: geochrono.v1.ProcessTrackRequest.point:type_name -> geochrono.v1.Point
+	2,  // 3: geochrono.v1.MapOptions.track:type_name -> geochrono.v1.TrackOptions
+	0,  // 4: geochrono.v1.MapOptions.format:type_name -> geochrono.v1.Format
+	4,  // 5: geochrono.v1.GenerateMapRequest.options:type_name -> geochrono.v1.MapOptions
+	1,  // 6: geochrono.v1.GenerateMapRequest.point:type_name -> geochrono.v1.Point
+	8,  // 7: geochrono.v1.TrackSummary.start:type_name -> google.protobuf.Timestamp
+	8,  // 8: geochrono.v1.TrackSummary.end:type_name -> google.protobuf.Timestamp
+	3,  // 9: geochrono.v1.GeoChrono.ProcessTrack:input_type -> geochrono.v1.ProcessTrackRequest
+	5,  // 10: geochrono.v1.GeoChrono.GenerateMap:input_type -> geochrono.v1.GenerateMapRequest
+	7,  // 11: geochrono.v1.GeoChrono.ProcessTrack:output_type -> geochrono.v1.TrackSummary
+	6,  // 12: geochrono.v1.GeoChrono.GenerateMap:output_type -> geochrono.v1.MapChunk
+	11, // [11:13] is the sub-list for method output_type
+	9,  // [9:11] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_geochrono_v1_geochrono_proto_init() }
+func file_geochrono_v1_geochrono_proto_init() {
+	if File_geochrono_v1_geochrono_proto != nil {
+		return
+	}
+	file_geochrono_v1_geochrono_proto_msgTypes[2].OneofWrappers = []any{
+		(*ProcessTrackRequest_Options)(nil),
+		(*ProcessTrackRequest_Point)(nil),
+	}
+	file_geochrono_v1_geochrono_proto_msgTypes[4].OneofWrappers = []any{
+		(*GenerateMapRequest_Options)(nil),
+		(*GenerateMapRequest_Point)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_geochrono_v1_geochrono_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_geochrono_v1_geochrono_proto_goTypes,
+		DependencyIndexes: file_geochrono_v1_geochrono_proto_depIdxs,
+		EnumInfos:         file_geochrono_v1_geochrono_proto_enumTypes,
+		MessageInfos:      file_geochrono_v1_geochrono_proto_msgTypes,
+	}.Build()
+	File_geochrono_v1_geochrono_proto = out.File
+	file_geochrono_v1_geochrono_proto_rawDesc = nil
+	file_geochrono_v1_geochrono_proto_goTypes = nil
+	file_geochrono_v1_geochrono_proto_depIdxs = nil
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/hooks"
+	"github.com/saratily/geo-chrono/internal/mapgen"
+	"github.com/saratily/geo-chrono/internal/staticmap"
+	"github.com/saratily/geo-chrono/internal/stats"
+)
+
+// batchResult holds the outcome of processing a single input file in batch mode.
+type batchResult struct {
+	InputFile  string         // Path to the processed CSV file
+	OutputFile string         // Path to the generated HTML map
+	Summary    *stats.Summary // Route statistics (nil if processing failed)
+	Thumbnail  []byte         // PNG preview of the track (nil if processing failed)
+	Err        error          // Processing error, if any
+}
+
+// runBatch processes every CSV file matching the batch glob pattern, generating one
+// HTML map per input in the output directory, then prints a summary table.
+// Files are processed by -workers workers at once. Failed inputs are reported in
+// the table and do not stop the remaining files; the returned error then names
+// every failed file and carries the exit status of the first one. Hooks run once
+// for the whole batch. When ctx is cancelled, files not yet started are skipped
+// and the index and summary still cover the files that were processed.
+func runBatch(ctx context.Context, cfg *config.Config, flags *Flags) error {
+	if flags.Workers < 0 {
+		return withExit(exitConfig, fmt.Errorf("invalid number of workers %d (use 0 for one per CPU)", flags.Workers))
+	}
+
+	// Run the pre_ingest hooks once for the whole batch, before the pattern is expanded
+	if err := runHooks(ctx, cfg, hooks.Event{Name: hooks.PreIngest, Input: flags.Batch}); err != nil {
+		return withExit(exitInput, err)
+	}
+
+	// Expand the glob pattern into a list of input files
+	inputs, err := filepath.Glob(flags.Batch)
+	if err != nil {
+		return withExit(exitConfig, fmt.Errorf("invalid batch pattern %s: %w", flags.Batch, err))
+	}
+	if len(inputs) == 0 {
+		return withExit(exitInput, fmt.Errorf("no input files match %s", flags.Batch))
+	}
+	outputs, err := batchOutputs(inputs, flags.OutputDir)
+	if err != nil {
+		return withExit(exitConfig, err)
+	}
+
+	// Ensure the output directory exists before generating any maps
+	if err := os.MkdirAll(flags.OutputDir, 0755); err != nil {
+		return withExit(exitOutput, fmt.Errorf("cannot create output directory %s: %w", flags.OutputDir, err))
+	}
+
+	// Process the inputs independently so one bad file doesn't abort the batch
+	results := processBatch(ctx, cfg, inputs, outputs, batchWorkers(flags.Workers, len(inputs)))
+	var failures []string
+	var code int
+	for _, result := range results {
+		if result.Err != nil {
+			if len(failures) == 0 {
+				code = exitInput
+				if errors.Is(result.Err, mapgen.ErrConfig) {
+					code = exitConfig
+				}
+				code = exitCode(result.Err, code)
+			}
+			failures = append(failures, fmt.Sprintf("%s: %v", result.InputFile, result.Err))
+		}
+	}
+
+	// Link every generated map from an index page with track thumbnails
+	index := filepath.Join(flags.OutputDir, "index.html")
+	if err := prepareOutput(index, cfg.Output.Overwrite); err != nil {
+		return withExit(exitOutput, err)
+	}
+	if err := writeBatchIndex(index, results, cfg.Statistics.DistanceUnits); err != nil {
+		return withExit(exitOutput, err)
+	}
+	report("Index written to %s\n", index)
+
+	// Print the aligned overview table, unless quiet, and optionally persist it as CSV
+	units := cfg.Statistics.DistanceUnits
+	if verbosity > verbosityQuiet {
+		printBatchSummary(os.Stdout, results, units)
+	}
+	if flags.SummaryCSV != "" {
+		if err := prepareOutput(flags.SummaryCSV, cfg.Output.Overwrite); err != nil {
+			return withExit(exitOutput, err)
+		}
+		if err := writeBatchSummaryCSV(flags.SummaryCSV, results, units); err != nil {
+			return withExit(exitOutput, err)
+		}
+		report("Summary written to %s\n", flags.SummaryCSV)
+	}
+
+	// An interrupted batch keeps its partial reports but publishes nothing
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted with %d of %d files not processed: %w", skipped(results), len(inputs), ctx.Err())
+	}
+
+	// Run the post_generate hooks once, even when some files failed, so the maps
+	// that were generated are published with the index
+	event := hooks.Event{Name: hooks.PostGenerate, Input: flags.Batch, Output: index}
+	for _, result := range results {
+		if result.Err == nil {
+			event.Files = append(event.Files, result.OutputFile)
+		}
+	}
+	event.Files = append(event.Files, index)
+	if flags.SummaryCSV != "" {
+		event.Files = append(event.Files, flags.SummaryCSV)
+	}
+	if err := runHooks(ctx, cfg, event); err != nil {
+		return withExit(exitOutput, err)
+	}
+
+	if len(failures) > 0 {
+		return withExit(code, fmt.Errorf("%d of %d files failed: %s", len(failures), len(inputs), strings.Join(failures, "; ")))
+	}
+	return nil
+}
+
+// batchWorkers returns how many files are processed at once: the requested
+// number, or one per CPU for 0, but never more than there are files.
+func batchWorkers(requested, files int) int {
+	workers := requested
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+	return max(min(workers, files), 1)
+}
+
+// batchOutputs returns the HTML map of every input in outputDir, named after
+// the input file. Inputs whose maps would overwrite each other, such as
+// a/track.csv and b/track.csv, or the index page, are an error, so no map is
+// generated only to be replaced. Names are compared ignoring case, as on
+// case-insensitive file systems.
+func batchOutputs(inputs []string, outputDir string) ([]string, error) {
+	outputs := make([]string, len(inputs))
+	taken := map[string]string{"index": "the index page"}
+	for i, input := range inputs {
+		base := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+		outputs[i] = filepath.Join(outputDir, base+".html")
+
+		if other, ok := taken[strings.ToLower(base)]; ok {
+			return nil, fmt.Errorf("%s would overwrite %s in %s (rename the file or narrow the batch pattern)", input, other, outputs[i])
+		}
+		taken[strings.ToLower(base)] = "the map of " + input
+	}
+	return outputs, nil
+}
+
+// processBatch renders every input into its HTML map in outputs, the given
+// number of workers at a time, and returns the results in input order. One
+// progress bar counts the finished files. Once ctx is cancelled, the remaining
+// files are not started and fail with errNotProcessed.
+func processBatch(ctx context.Context, cfg *config.Config, inputs, outputs []string, workers int) []batchResult {
+	bar := newProgress("Processing files", int64(len(inputs)), "files")
+	defer bar.Finish()
+	// The bars of files read at the same time would overwrite each other
+	progressOutput = nil
+
+	results := make([]batchResult, len(inputs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range next {
+				input, output := inputs[index], outputs[index]
+				summary, thumbnail, err := processTrack(ctx, cfg, input, output)
+				results[index] = batchResult{InputFile: input, OutputFile: output, Summary: summary, Thumbnail: thumbnail, Err: err}
+				bar.Add(1)
+			}
+		}()
+	}
+	for index := range inputs {
+		select {
+		case next <- index:
+		case <-ctx.Done():
+			results[index] = batchResult{InputFile: inputs[index], Err: fmt.Errorf("%w: %w", errNotProcessed, ctx.Err())}
+		}
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// errNotProcessed marks the batch files skipped after an interruption.
+var errNotProcessed = errors.New("not processed")
+
+// skipped counts the files of a batch that were never started.
+func skipped(results []batchResult) int {
+	count := 0
+	for _, result := range results {
+		if errors.Is(result.Err, errNotProcessed) {
+			count++
+		}
+	}
+	return count
+}
+
+// processTrack reads, sorts, and renders a single CSV file into an HTML map.
+// Returns the route statistics and a PNG thumbnail so callers can summarize results.
+// Errors other than reading the input carry their exit status.
+func processTrack(ctx context.Context, cfg *config.Config, csvFile, htmlFile string) (*stats.Summary, []byte, error) {
+	points, err := loadPoints(ctx, cfg, csvFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := prepareOutput(htmlFile, cfg.Output.Overwrite); err != nil {
+		return nil, nil, withExit(exitOutput, err)
+	}
+	if err := mapgen.NewGenerator(cfg).Generate(ctx, points, htmlFile); err != nil {
+		return nil, nil, withExit(exitOutput, err)
+	}
+
+	thumbnail, err := staticmap.Thumbnail(points, staticmap.DefaultThumbnailSize)
+	if err != nil {
+		return nil, nil, withExit(exitOutput, err)
+	}
+
+	return stats.Compute(points, &cfg.Statistics), thumbnail, nil
+}
+
+// batchSummaryHeader lists the columns shared by the console table and CSV summary.
+var batchSummaryHeader = []string{"file", "points", "distance", "duration", "output"}
+
+// batchSummaryRow formats a single batch result as table cells.
+// Failed inputs show the error in place of the output path.
+func batchSummaryRow(result batchResult, units string) []string {
+	if result.Err != nil {
+		return []string{result.InputFile, "-", "-", "-", "error: " + result.Err.Error()}
+	}
+	return []string{
+		result.InputFile,
+		strconv.Itoa(result.Summary.Points),
+		stats.FormatDistance(result.Summary.Distance, units),
+		stats.FormatDuration(result.Summary.Duration),
+		result.OutputFile,
+	}
+}
+
+// printBatchSummary writes an aligned table with one row per processed file.
+func printBatchSummary(w io.Writer, results []batchResult, units string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(batchSummaryHeader, "\t")))
+	for _, result := range results {
+		fmt.Fprintln(tw, strings.Join(batchSummaryRow(result, units), "\t"))
+	}
+	tw.Flush()
+}
+
+// writeBatchSummaryCSV writes the batch summary table to a CSV file.
+func writeBatchSummaryCSV(filename string, results []batchResult, units string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("cannot create summary file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write(batchSummaryHeader); err != nil {
+		return fmt.Errorf("cannot write summary file: %w", err)
+	}
+	for _, result := range results {
+		if err := writer.Write(batchSummaryRow(result, units)); err != nil {
+			return fmt.Errorf("cannot write summary file: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// batchIndexTemplate renders the batch index page linking every generated map.
+var batchIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <title>GeoChrono Batch Index</title>
+    <meta charset="utf-8">
+    <style>
+        body { font-family: Arial, sans-serif; margin: 20px; background-color: #f5f5f5; }
+        table { border-collapse: collapse; background: white; }
+        td, th { padding: 8px 12px; border-bottom: 1px solid #ddd; text-align: left; vertical-align: middle; }
+        .error { color: #c00; }
+    </style>
+</head>
+<body>
+    <h1>GeoChrono Batch Index</h1>
+    <table>
+        <tr><th></th><th>File</th><th>Points</th><th>Distance</th><th>Duration</th></tr>
+        {{range .}}
+        <tr>
+            {{if .Link}}
+            <td><a href="{{.Link}}"><img src="{{.Thumbnail}}" alt="{{.Name}}" width="64" height="64"></a></td>
+            <td><a href="{{.Link}}">{{.Name}}</a></td>
+            <td>{{.Points}}</td><td>{{.Distance}}</td><td>{{.Duration}}</td>
+            {{else}}
+            <td></td><td>{{.Name}}</td><td colspan="3" class="error">{{.Error}}</td>
+            {{end}}
+        </tr>
+        {{end}}
+    </table>
+</body>
+</html>
+`))
+
+// batchIndexEntry is the template view of a single batch result.
+type batchIndexEntry struct {
+	Name      string
+	Link      string
+	Thumbnail template.URL // data URI, marked safe so html/template keeps it
+	Points    int
+	Distance  string
+	Duration  string
+	Error     string
+}
+
+// writeBatchIndex writes an HTML page linking each generated map with its thumbnail.
+// Links are relative to the index file, which lives in the batch output directory.
+func writeBatchIndex(filename string, results []batchResult, units string) error {
+	var entries []batchIndexEntry
+	for _, result := range results {
+		entry := batchIndexEntry{Name: filepath.Base(result.InputFile)}
+		if result.Err != nil {
+			entry.Error = result.Err.Error()
+		} else {
+			entry.Link = filepath.Base(result.OutputFile)
+			entry.Thumbnail = template.URL(staticmap.DataURI(result.Thumbnail))
+			entry.Points = result.Summary.Points
+			entry.Distance = stats.FormatDistance(result.Summary.Distance, units)
+			entry.Duration = stats.FormatDuration(result.Summary.Duration)
+		}
+		entries = append(entries, entry)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("cannot create index file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	if err := batchIndexTemplate.Execute(file, entries); err != nil {
+		return fmt.Errorf("cannot write index file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+const (
+	goodTrack = "timestamp,latitude,longitude\n" +
+		"2025-10-28T10:00:00Z,37.7749,-122.4194\n" +
+		"2025-10-28T10:10:00Z,37.7849,-122.4094\n" +
+		"2025-10-28T10:20:00Z,37.7949,-122.3994\n"
+	malformedTrack = "timestamp,latitude,longitude\n" +
+		"yesterday,north,west\n" +
+		"2025-10-28T10:10:00Z,not-a-latitude,-122.4094\n"
+)
+
+func TestProcessBatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		inputs  []string // Base names of the input files, good or malformed
+		workers int
+	}{
+		{name: "good first", inputs: []string{"good", "malformed"}, workers: 1},
+		{name: "malformed first", inputs: []string{"malformed", "good"}, workers: 1},
+		{name: "concurrent", inputs: []string{"malformed", "good"}, workers: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Load(filepath.Join("..", "..", "config.yaml"))
+			if err != nil {
+				t.Fatalf("Load(config.yaml) error = %v", err)
+			}
+			dir := t.TempDir()
+			outputDir := filepath.Join(dir, "maps")
+			if err := os.Mkdir(outputDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			tracks := map[string]string{"good": goodTrack, "malformed": malformedTrack}
+			var inputs []string
+			for _, name := range tt.inputs {
+				input := filepath.Join(dir, name+".csv")
+				if err := os.WriteFile(input, []byte(tracks[name]), 0644); err != nil {
+					t.Fatal(err)
+				}
+				inputs = append(inputs, input)
+			}
+
+			outputs, err := batchOutputs(inputs, outputDir)
+			if err != nil {
+				t.Fatalf("batchOutputs() error = %v", err)
+			}
+			results := processBatch(context.Background(), cfg, inputs, outputs, tt.workers)
+			if len(results) != len(inputs) {
+				t.Fatalf("processBatch() returned %d results for %d inputs", len(results), len(inputs))
+			}
+			if n := skipped(results); n != 0 {
+				t.Errorf("processBatch() skipped %d files", n)
+			}
+
+			// Results come in input order; the malformed file fails on its own
+			var good, malformed batchResult
+			for i, result := range results {
+				if result.InputFile != inputs[i] {
+					t.Errorf("result %d is for %s, want %s", i, result.InputFile, inputs[i])
+				}
+				if tt.inputs[i] == "good" {
+					good = result
+				} else {
+					malformed = result
+				}
+			}
+			if good.Err != nil {
+				t.Fatalf("good file error = %v", good.Err)
+			}
+			if good.OutputFile != filepath.Join(outputDir, "good.html") || good.Summary.Points != 3 || !bytes.HasPrefix(good.Thumbnail, []byte("\x89PNG")) {
+				t.Errorf("good file result = %+v, want a map, 3 points, and a PNG thumbnail", good)
+			}
+			if page, err := os.ReadFile(good.OutputFile); err != nil || !strings.Contains(string(page), "<html") {
+				t.Errorf("good file map = %.40q, %v, want an HTML page", page, err)
+			}
+			if malformed.Err == nil || !strings.Contains(malformed.Err.Error(), "no valid GPS points") {
+				t.Errorf("malformed file error = %v, want no valid GPS points", malformed.Err)
+			}
+			if _, err := os.Stat(filepath.Join(outputDir, "malformed.html")); !os.IsNotExist(err) {
+				t.Errorf("malformed file left a map behind: %v", err)
+			}
+
+			// The console table, CSV summary, and index report both files
+			var table bytes.Buffer
+			printBatchSummary(&table, results, cfg.Statistics.DistanceUnits)
+			lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+			if len(lines) != 3 || !strings.HasPrefix(lines[0], "FILE") {
+				t.Fatalf("printBatchSummary() = %q, want a header and two rows", table.String())
+			}
+			for i, line := range lines[1:] {
+				if !strings.HasPrefix(line, inputs[i]) {
+					t.Errorf("summary row %d = %q, want it for %s", i, line, inputs[i])
+				}
+				if want := map[string]string{"good": good.OutputFile, "malformed": "error: no valid GPS points"}[tt.inputs[i]]; !strings.Contains(line, want) {
+					t.Errorf("summary row %q, want %q", line, want)
+				}
+			}
+
+			summaryCSV := filepath.Join(dir, "summary.csv")
+			if err := writeBatchSummaryCSV(summaryCSV, results, cfg.Statistics.DistanceUnits); err != nil {
+				t.Fatalf("writeBatchSummaryCSV() error = %v", err)
+			}
+			file, err := os.Open(summaryCSV)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			rows, err := csv.NewReader(file).ReadAll()
+			if err != nil || len(rows) != 3 || strings.Join(rows[0], ",") != "file,points,distance,duration,output" {
+				t.Fatalf("summary CSV = %q, %v, want a header and two rows", rows, err)
+			}
+			for i, row := range rows[1:] {
+				ok := row[0] == inputs[i]
+				if tt.inputs[i] == "good" {
+					ok = ok && row[1] == "3" && row[2] != "-" && row[3] != "-" && row[4] == good.OutputFile
+				} else {
+					ok = ok && row[1] == "-" && row[2] == "-" && row[3] == "-" && row[4] == "error: "+malformed.Err.Error()
+				}
+				if !ok {
+					t.Errorf("summary CSV row %d = %q for %s", i, row, tt.inputs[i])
+				}
+			}
+
+			index := filepath.Join(outputDir, "index.html")
+			if err := writeBatchIndex(index, results, cfg.Statistics.DistanceUnits); err != nil {
+				t.Fatalf("writeBatchIndex() error = %v", err)
+			}
+			page, err := os.ReadFile(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range []string{`<a href="good.html">good.csv</a>`, `src="data:image/png;base64,`, `<td>malformed.csv</td><td colspan="3" class="error">`} {
+				if !strings.Contains(string(page), want) {
+					t.Errorf("index page does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestBatchOutputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		inputs  []string
+		want    []string // Output base names
+		wantErr string
+	}{
+		{name: "distinct", inputs: []string{"a/walk.csv", "b/ride.csv"}, want: []string{"walk.html", "ride.html"}},
+		{name: "same base name", inputs: []string{"a/track.csv", "b/track.csv"}, wantErr: "b/track.csv would overwrite the map of a/track.csv"},
+		{name: "differing case", inputs: []string{"Walk.csv", "walk.gpx"}, wantErr: "walk.gpx would overwrite the map of Walk.csv"},
+		{name: "index", inputs: []string{"a/index.csv"}, wantErr: "a/index.csv would overwrite the index page"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs, err := batchOutputs(tt.inputs, "maps")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("batchOutputs() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("batchOutputs() error = %v", err)
+			}
+			for i, want := range tt.want {
+				if outputs[i] != filepath.Join("maps", want) {
+					t.Errorf("batchOutputs()[%d] = %s, want %s", i, outputs[i], filepath.Join("maps", want))
+				}
+			}
+		})
+	}
+}
+
+func TestBatchWorkers(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested int
+		files     int
+		want      int
+	}{
+		{name: "requested", requested: 2, files: 4, want: 2},
+		{name: "more than files", requested: 8, files: 3, want: 3},
+		{name: "one per CPU", requested: 0, files: 1000, want: min(runtime.NumCPU(), 1000)},
+		{name: "no files", requested: 4, files: 0, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := batchWorkers(tt.requested, tt.files); got != tt.want {
+				t.Errorf("batchWorkers(%d, %d) = %d, want %d", tt.requested, tt.files, got, tt.want)
+			}
+		})
+	}
+}
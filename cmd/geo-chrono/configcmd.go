@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	geochrono "github.com/saratily/geo-chrono"
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// initPrompt is a question of "config init" and the setting its answer sets.
+type initPrompt struct {
+	question string // Question shown to the user
+	path     string // Dotted path of the setting in the example configuration
+	fallback string // Answer used when the user just presses enter
+}
+
+// initPrompts are asked in order by "config init -interactive".
+var initPrompts = []initPrompt{
+	{question: "CSV file with GPS points", path: "input.csv_file", fallback: "data/coordinates.csv"},
+	{question: "Timestamp column", path: "input.csv_format.timestamp_column", fallback: "timestamp"},
+	{question: "Latitude column", path: "input.csv_format.latitude_column", fallback: "latitude"},
+	{question: "Longitude column", path: "input.csv_format.longitude_column", fallback: "longitude"},
+	{question: `Google Maps API key, or "none" for keyless Leaflet maps`, path: "google_maps.api_key", fallback: "${GOOGLE_MAPS_API_KEY}"},
+}
+
+// runConfig runs an action of the config command. "init" writes the commented
+// example configuration to the -config path; with -interactive, it asks for
+// the input file, its columns, and the API key first. "check" reports every
+// problem of the -config file. Failures to write the file carry exitOutput.
+func runConfig(action string, flags *Flags, in io.Reader, out io.Writer) error {
+	switch action {
+	case "init":
+		return initConfig(flags.ConfigFile, flags.Force, flags.Interactive, in, out)
+	case "check":
+		return reportConfig(flags.ConfigFile, out)
+	case "":
+		return fmt.Errorf("the config command needs an action (available: init, check)")
+	default:
+		return fmt.Errorf("unknown config action %q (available: init, check)", action)
+	}
+}
+
+// reportConfig prints every problem of the configuration file at path and
+// fails if there are any.
+func reportConfig(path string, out io.Writer) error {
+	problems := config.Check(path)
+	if len(problems) == 0 {
+		fmt.Fprintf(out, "%s: no problems found\n", path)
+		return nil
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(out, "%s: %v\n", path, problem)
+	}
+	if len(problems) == 1 {
+		return fmt.Errorf("1 problem in %s", path)
+	}
+	return fmt.Errorf("%d problems in %s", len(problems), path)
+}
+
+// initConfig writes the example configuration to path, refusing to replace an
+// existing file unless force is set. With interactive set, the answers read
+// from in are filled into the example first.
+func initConfig(path string, force, interactive bool, in io.Reader, out io.Writer) error {
+	if _, err := os.Stat(path); err == nil && !force {
+		return withExit(exitOutput, fmt.Errorf("%s already exists (use -force to replace it)", path))
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return withExit(exitOutput, fmt.Errorf("cannot check %s: %w", path, err))
+	}
+
+	data := geochrono.ExampleConfig
+	if interactive {
+		var err error
+		if data, err = askConfig(data, in, out); err != nil {
+			return err
+		}
+	}
+
+	// A .json path gets the same settings without the comments
+	described := "every setting is described in its comments"
+	if config.IsJSON(path) {
+		var err error
+		if data, err = config.ToJSON(data); err != nil {
+			return err
+		}
+		described = "config.yaml from \"geo-chrono config init\" describes every setting"
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return withExit(exitOutput, fmt.Errorf("cannot write %s: %w", path, err))
+	}
+	fmt.Fprintf(out, "Wrote %s; %s. Run \"geo-chrono doctor -config %s\" to check it.\n", path, described, path)
+	return nil
+}
+
+// askConfig asks the init prompts and fills the answers into the example
+// configuration. Answering "none" for the API key switches the map provider to
+// Leaflet, which needs no key.
+func askConfig(data []byte, in io.Reader, out io.Writer) ([]byte, error) {
+	answers := bufio.NewScanner(in)
+	for _, prompt := range initPrompts {
+		fmt.Fprintf(out, "%s [%s]: ", prompt.question, prompt.fallback)
+		answer := prompt.fallback
+		if answers.Scan() {
+			if text := strings.TrimSpace(answers.Text()); text != "" {
+				answer = text
+			}
+		}
+		keyless := prompt.path == "google_maps.api_key" && strings.EqualFold(answer, "none")
+		if keyless {
+			answer = ""
+		}
+
+		var err error
+		if data, err = config.SetYAMLValue(data, prompt.path, answer); err != nil {
+			return nil, err
+		}
+		if keyless {
+			if data, err = config.SetYAMLValue(data, "map.provider", "leaflet"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := answers.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read answers: %w", err)
+	}
+	return data, nil
+}
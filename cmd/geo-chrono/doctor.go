@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/export"
+	"github.com/saratily/geo-chrono/internal/input"
+	"github.com/saratily/geo-chrono/internal/mapgen"
+	"github.com/saratily/geo-chrono/internal/roads"
+	"github.com/saratily/geo-chrono/internal/tiles"
+	"github.com/saratily/geo-chrono/internal/weather"
+)
+
+// Outcomes of a single doctor check.
+const (
+	statusPass = "PASS"
+	statusWarn = "WARN"
+	statusFail = "FAIL"
+)
+
+// doctorTimeout bounds each network reachability probe.
+const doctorTimeout = 5 * time.Second
+
+// googleAPIKeyPattern matches the format of Google Cloud API keys.
+var googleAPIKeyPattern = regexp.MustCompile(`^AIza[0-9A-Za-z_-]{35}$`)
+
+// doctorCheck is one line of the doctor report.
+type doctorCheck struct {
+	Name   string // Short check name
+	Status string // PASS, WARN, or FAIL
+	Detail string // What was checked or what went wrong
+}
+
+// runDoctor checks the configuration, input, API key, output locations, and
+// provider reachability, then prints a pass/fail report.
+// Returns false if any check failed.
+func runDoctor(w io.Writer, flags *Flags) bool {
+	checks := doctorChecks(flags)
+	printDoctorReport(w, checks)
+
+	for _, check := range checks {
+		if check.Status == statusFail {
+			return false
+		}
+	}
+	return true
+}
+
+// doctorChecks runs every check in order. A configuration that cannot be loaded
+// stops the remaining checks, since they all depend on it.
+func doctorChecks(flags *Flags) []doctorCheck {
+	cfg, err := loadConfig(flags)
+	if err != nil {
+		return []doctorCheck{{"config", statusFail, err.Error()}}
+	}
+	overrideConfigWithFlags(cfg, flags)
+	keyErr := cfg.ResolveAPIKey()
+
+	checks := []doctorCheck{checkConfig(cfg, flags.ConfigFile, keyErr)}
+	if cfg.RequiresAPIKey() {
+		checks = append(checks, checkAPIKey(cfg.GoogleMaps.APIKey, keyErr))
+	} else {
+		checks = append(checks, doctorCheck{"api key", statusPass, "not needed for the " + cfg.Map.Provider + " provider"})
+	}
+	checks = append(checks, checkInput(cfg, flags))
+	checks = append(checks, checkOutputs(cfg, flags)...)
+	checks = append(checks, checkProviders(cfg)...)
+	return checks
+}
+
+// checkConfig reports whether the loaded configuration passes validation.
+func checkConfig(cfg *config.Config, filename string, keyErr error) doctorCheck {
+	if keyErr != nil {
+		return doctorCheck{"config", statusFail, keyErr.Error()}
+	}
+	if err := cfg.Validate(); err != nil {
+		return doctorCheck{"config", statusFail, err.Error()}
+	}
+	return doctorCheck{"config", statusPass, "loaded and validated " + filename}
+}
+
+// checkAPIKey verifies the Google Maps API key looks like a real Google Cloud key.
+func checkAPIKey(key string, keyErr error) doctorCheck {
+	switch {
+	case keyErr != nil || key == "":
+		return doctorCheck{"api key", statusFail, "no Google Maps API key configured"}
+	case key == "DEMO":
+		return doctorCheck{"api key", statusWarn, "DEMO key renders a development-only map"}
+	case !googleAPIKeyPattern.MatchString(key):
+		return doctorCheck{"api key", statusFail, "key does not look like a Google API key (AIza... with 39 characters)"}
+	default:
+		return doctorCheck{"api key", statusPass, "key format is valid"}
+	}
+}
+
+// checkInput reads the configured input file (or every batch input) in its
+// format and counts points.
+func checkInput(cfg *config.Config, flags *Flags) doctorCheck {
+	inputs := []string{cfg.Input.CSVFile}
+	if flags.Batch != "" {
+		matches, err := filepath.Glob(flags.Batch)
+		if err != nil || len(matches) == 0 {
+			return doctorCheck{"input", statusFail, "no input files match " + flags.Batch}
+		}
+		inputs = matches
+	}
+
+	total := 0
+	for _, file := range inputs {
+		reader, err := input.New(file, cfg.Input.Format, cfg)
+		if err != nil {
+			return doctorCheck{"input", statusFail, err.Error()}
+		}
+		var readErr error
+		points := reader.Stream(context.Background(), file, &readErr).Collect()
+		if readErr != nil {
+			return doctorCheck{"input", statusFail, readErr.Error()}
+		}
+		if points.IsEmpty() {
+			return doctorCheck{"input", statusFail, "no valid GPS points in " + file}
+		}
+		total += len(points)
+	}
+	return doctorCheck{"input", statusPass, fmt.Sprintf("read %d points from %d file(s)", total, len(inputs))}
+}
+
+// checkOutputs verifies that every configured output location is writable.
+func checkOutputs(cfg *config.Config, flags *Flags) []doctorCheck {
+	var dirs []string
+	var checks []doctorCheck
+	if flags.Batch != "" {
+		dirs = append(dirs, flags.OutputDir)
+	} else if formats, err := exportFormats(cfg, flags); err != nil {
+		checks = append(checks, doctorCheck{"output", statusFail, err.Error()})
+	} else {
+		for _, format := range formats {
+			dirs = append(dirs, filepath.Dir(export.Filename(format, cfg)))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		probe, err := os.CreateTemp(dir, ".geo-chrono-doctor-*")
+		if err != nil {
+			checks = append(checks, doctorCheck{"output", statusFail, fmt.Sprintf("cannot write to %s: %v", dir, err)})
+			continue
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+		checks = append(checks, doctorCheck{"output", statusPass, dir + " is writable"})
+	}
+	return checks
+}
+
+// checkProviders probes the network reachability of every configured provider.
+func checkProviders(cfg *config.Config) []doctorCheck {
+	targets := []struct{ name, url string }{
+		{"google maps", "https://maps.googleapis.com/maps/api/js"},
+	}
+
+	if cfg.Map.Fallback.Provider != "" {
+		tileURL := cfg.Map.Fallback.TileURL
+		if tileURL == "" {
+			tileURL = mapgen.DefaultFallbackTileURL
+		}
+		tileURL = strings.NewReplacer("{s}", "a", "{z}", "0", "{x}", "0", "{y}", "0").Replace(tileURL)
+		targets = append(targets, struct{ name, url string }{"fallback tiles", tileURL})
+	}
+
+	switch snap := cfg.Processing.SnapToRoads; snap.Provider {
+	case roads.ProviderGoogle:
+		targets = append(targets, struct{ name, url string }{"snap to roads", roads.GoogleRoadsURL})
+	case roads.ProviderOSRM:
+		base := snap.URL
+		if base == "" {
+			base = roads.DefaultOSRMURL
+		}
+		targets = append(targets, struct{ name, url string }{"snap to roads", base})
+	}
+
+	if cfg.Weather.Enabled {
+		base := cfg.Weather.URL
+		if base == "" {
+			base = weather.DefaultURL
+		}
+		targets = append(targets, struct{ name, url string }{"weather", base})
+	}
+
+	if offline := cfg.Map.OfflineTiles; offline.Enabled {
+		tileURL := cfg.Map.TileURL
+		if tileURL == "" {
+			tileURL = mapgen.DefaultTileURL
+		}
+		// Tiles read from a local directory need no network
+		if strings.HasPrefix(tileURL, "http://") || strings.HasPrefix(tileURL, "https://") {
+			targets = append(targets, struct{ name, url string }{"offline tiles", tiles.Tile{}.URL(tileURL)})
+		}
+	}
+
+	client := &http.Client{Timeout: doctorTimeout}
+	var checks []doctorCheck
+	for _, target := range targets {
+		// Any HTTP response proves the host is reachable; auth errors are expected here
+		resp, err := client.Head(target.url)
+		if err != nil {
+			checks = append(checks, doctorCheck{target.name, statusFail, fmt.Sprintf("%s unreachable: %v", target.url, err)})
+			continue
+		}
+		resp.Body.Close()
+		checks = append(checks, doctorCheck{target.name, statusPass, fmt.Sprintf("%s reachable (%s)", target.url, resp.Status)})
+	}
+	return checks
+}
+
+// printDoctorReport writes the aligned check table followed by a one-line tally.
+func printDoctorReport(w io.Writer, checks []doctorCheck) {
+	counts := make(map[string]int)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, check := range checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", check.Status, check.Name, check.Detail)
+		counts[check.Status]++
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d passed, %d warnings, %d failed\n", counts[statusPass], counts[statusWarn], counts[statusFail])
+}
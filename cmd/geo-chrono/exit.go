@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+)
+
+// Exit statuses, documented in the README so wrapper scripts can tell the
+// kinds of failure apart. Invalid flags also exit with exitConfig, as the
+// flag package does.
+const (
+	exitFailure = 1 // Any other failure, such as a failed doctor check or a stopped server
+	exitConfig  = 2 // Invalid configuration, flags, or command
+	exitInput   = 3 // Input or reference route that cannot be read or holds no points
+	exitAPI     = 4 // Road snapping, weather, or tile server that failed
+	exitOutput  = 5 // Output file that exists or cannot be written
+
+	exitInterrupted = 130 // Stopped by Ctrl-C or SIGTERM, as shells report SIGINT
+)
+
+// exitError marks an error with the exit status of the step that failed.
+type exitError struct {
+	code int   // Exit status, such as exitAPI
+	err  error // Failure of the step
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExit marks err with an exit status, keeping its message. A nil error
+// stays nil.
+func withExit(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns exitInterrupted for an error caused by an interruption,
+// otherwise the exit status marked on err or on an error it wraps, or fallback
+// when there is none.
+func exitCode(err error, fallback int) int {
+	if errors.Is(err, context.Canceled) {
+		return exitInterrupted
+	}
+	var marked *exitError
+	if errors.As(err, &marked) {
+		return marked.code
+	}
+	return fallback
+}
+
+// fatal logs an error that ends the run, closes the log file, and exits with
+// the given status. Arguments after the message are slog attributes.
+func fatal(code int, msg string, args ...any) {
+	slog.Error(msg, args...)
+	closeLog()
+	os.Exit(code)
+}
@@ -6,15 +6,61 @@
 // @description Creates HTML maps with walking trails and chronological GPS visualization
 //
 // @usage geo-chrono [flags]
+// @usage geo-chrono doctor [flags]
+// @usage geo-chrono serve [flags]
+// @usage geo-chrono config init [flags]
+// @usage geo-chrono config check [flags]
+// @usage geo-chrono version [-json]
 // @flags
 //
 //	-config string    Path to configuration file (default "config.yaml")
-//	-csv string       Path to CSV file (overrides config)
+//	-profile string   Profile of the configuration file to apply
+//	-csv string       Path to the input CSV or GPX file (overrides config)
 //	-apikey string    Google Maps API key (overrides config)
 //	-out string       Output HTML file (overrides config)
 //	-title string     Map title (overrides config)
+//	-batch string     Glob pattern of CSV files to process in batch mode
+//	-outdir string    Output directory for batch mode maps (default ".")
+//	-summary string   Write the batch summary table to this CSV file
+//	-workers n        Files processed at once in batch mode (default one per CPU)
+//	-compare string   Reference route (.gpx or .csv) to compare the track against
+//	-export string    Comma-separated output formats (html, kml, geojson, gpx, stats, image)
+//	-force            Replace existing output files
+//	-backup           Keep existing output files as timestamped backups
+//	-addr string      Address the serve command listens on (default ":8080")
+//	-grpc-addr string Address of the serve command's gRPC service (off when empty)
+//	-interactive      Ask for the input file, columns, and API key (config init)
+//	-quiet            Show errors only: no warnings, results, or progress bars
+//	-version          Print the version, commit, and build date, then exit
+//	-json             Print the version as JSON (version command)
+//	-zoom int         Initial zoom level (overrides config)
+//	-maptype string   Base map type: roadmap, satellite, hybrid, or terrain
+//	-provider string  Map provider: google, leaflet, maplibre, or cesium
+//	-color string     Path color, such as #FF0000
+//	-dedupe           Remove duplicate GPS points
+//	-mindistance n    Minimum distance between points in meters
+//	-maxspeed n       Maximum realistic speed in km/h
+//	-smooth           Smooth the path with a moving average
+//	-simplify n       Simplify the path within this many meters (0 to disable)
+//	-timezone string  Time zone for timestamps and summaries
+//	-kml, -geojson, -gpx, -stats, -xlsx, -image string
+//	                  Write that export to the given file
+//	-v, -verbose      Log processing details and print the route summary
+//	-vv               Also log each request to external services
 //
 // @example geo-chrono -csv data.csv -out map.html -title "My Walking Trail"
+// @example geo-chrono -csv actual.csv -compare planned.gpx
+// @example geo-chrono -csv data.csv -export html,kml,geojson,stats
+// @example geo-chrono -profile hiking -csv data.csv
+// @example geo-chrono -quiet -csv data.csv
+// @example geo-chrono -vv -csv data.csv
+// @example geo-chrono -csv data.csv -provider leaflet -zoom 14 -color "#2E7D32" -maxspeed 15 -gpx walk.gpx
+// @example geo-chrono doctor -config config.yaml
+// @example geo-chrono serve -csv data.csv -addr :8080
+// @example geo-chrono serve -csv data.csv -grpc-addr :9090
+// @example geo-chrono config init -config trip.yaml
+// @example geo-chrono config check -config trip.yaml
+// @example geo-chrono version -json
 //
 // Features:
 // - CSV GPS data processing
@@ -25,15 +71,42 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
+	"log/slog"
+	"math"
+	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
+	geochrono "github.com/saratily/geo-chrono"
+	"github.com/saratily/geo-chrono/internal/aggregate"
 	"github.com/saratily/geo-chrono/internal/config"
-	"github.com/saratily/geo-chrono/internal/csv"
+	"github.com/saratily/geo-chrono/internal/export"
+	"github.com/saratily/geo-chrono/internal/geojson"
 	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/hooks"
+	"github.com/saratily/geo-chrono/internal/input"
+	"github.com/saratily/geo-chrono/internal/logging"
 	"github.com/saratily/geo-chrono/internal/mapgen"
+	"github.com/saratily/geo-chrono/internal/metrics"
+	"github.com/saratily/geo-chrono/internal/pipeline"
+	"github.com/saratily/geo-chrono/internal/progress"
+	"github.com/saratily/geo-chrono/internal/roads"
+	"github.com/saratily/geo-chrono/internal/server"
+	"github.com/saratily/geo-chrono/internal/staticmap"
+	"github.com/saratily/geo-chrono/internal/stats"
+	"github.com/saratily/geo-chrono/internal/tiles"
+	"github.com/saratily/geo-chrono/internal/weather"
 )
 
 // main is the entry point for the GeoChrono application.
@@ -42,71 +115,579 @@ import (
 // @description Application entry point and orchestration function
 // @steps Parse flags, Load config, Override settings, Process CSV, Generate map
 // @workflow Configuration → CSV Reading → GPS Processing → Map Generation
-// @exit Exits with status code 1 on any error, 0 on success
+// @exit Exits with 0 on success; 2 for configuration, 3 input, 4 API, and 5 output errors; 130 when interrupted; 1 otherwise
 func main() {
+	// Log readable lines to the console until the configuration says otherwise
+	slog.SetDefault(slog.New(logging.NewConsoleHandler(os.Stderr, slog.LevelInfo)))
+
+	// Detect an optional subcommand before the flags
+	command := subcommand()
+
+	// The config command takes an action, such as "init", before its flags
+	var action string
+	if command == "config" {
+		action = subcommand()
+	}
+
 	// Parse command line flags to get user input
 	flags := parseFlags()
+	if flags.Version {
+		command = "version"
+	}
 
-	// Load configuration from YAML file
-	cfg, err := config.Load(flags.ConfigFile)
+	// The doctor command checks the whole setup and reports instead of generating;
+	// the serve command serves the map over HTTP instead of writing files; the
+	// config command manages the configuration file itself; the version command
+	// identifies the binary
+	switch command {
+	case "", "serve":
+	case "config":
+		if err := runConfig(action, flags, os.Stdin, os.Stdout); err != nil {
+			fatal(exitCode(err, exitConfig), "Config command failed", "action", action, "error", err)
+		}
+		return
+	case "doctor":
+		if !runDoctor(os.Stdout, flags) {
+			os.Exit(exitFailure)
+		}
+		return
+	case "version":
+		if err := printVersion(os.Stdout, flags.JSON); err != nil {
+			fatal(exitOutput, "Cannot print version", "error", err)
+		}
+		return
+	default:
+		fatal(exitConfig, "Unknown command", "command", command, "available", "config, doctor, serve, version")
+	}
+
+	// Load configuration from the YAML or JSON file and the environment
+	cfg, err := loadConfig(flags)
 	if err != nil {
-		log.Fatalf("Error loading configuration: %v", err)
+		fatal(exitConfig, "Cannot load configuration", "error", err)
 	}
 
 	// Override configuration values with command line flags if provided
 	overrideConfigWithFlags(cfg, flags)
 
+	// Log at the configured level, to the log file too if one is configured
+	logger, closer, err := logging.New(&cfg.Logging, os.Stderr)
+	if err != nil {
+		fatal(exitConfig, "Cannot set up logging", "error", err)
+	}
+	slog.SetDefault(logger)
+	closeLog = closer
+	defer func() { closeLog() }()
+
+	// Show as much of the run as the logging settings ask for
+	verbosity = verbosityOf(&cfg.Logging)
+	progressOutput = progressWriter(verbosity == verbosityQuiet)
+	if verbosity >= verbosityTrace {
+		http.DefaultTransport = &logging.Transport{Base: http.DefaultTransport}
+	}
+	if command == "serve" {
+		// Count the requests to enrichment services for /metrics
+		http.DefaultTransport = &metrics.Transport{Base: http.DefaultTransport}
+	}
+
+	// Stop cleanly on Ctrl-C or SIGTERM: requests, reading, and rendering are
+	// cancelled, files already written are kept, and none is left half-written
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopWarning := context.AfterFunc(ctx, func() { slog.Warn("Interrupted, stopping") })
+	defer stopWarning()
+
 	// Resolve Google Maps API key from environment variables if needed
 	if err := cfg.ResolveAPIKey(); err != nil {
-		log.Fatalf("Error resolving API key: %v", err)
+		fatal(exitConfig, "Cannot resolve API key", "error", err)
+	}
+
+	// Batch mode processes many CSV files and prints a summary table instead
+	if flags.Batch != "" {
+		if command == "serve" {
+			fatal(exitConfig, "The serve command serves a single track and cannot be combined with -batch")
+		}
+		if err := runBatch(ctx, cfg, flags); err != nil {
+			fatal(exitCode(err, exitFailure), "Batch processing failed", "error", err)
+		}
+		return
+	}
+
+	// Only serve mode uses the API tokens, so only it needs their variables set
+	if command == "serve" {
+		if err := cfg.ResolveTokens(); err != nil {
+			fatal(exitConfig, "Cannot resolve API tokens", "error", err)
+		}
 	}
 
 	// Validate that all required configuration values are present
 	if err := cfg.Validate(); err != nil {
-		log.Fatalf("Configuration validation failed: %v", err)
+		fatal(exitConfig, "Configuration validation failed", "error", err)
+	}
+
+	// Run the pre_ingest hooks, such as fetching the input from a device
+	if err := runHooks(ctx, cfg, hooks.Event{Name: hooks.PreIngest, Input: cfg.Input.CSVFile}); err != nil {
+		fatal(exitCode(err, exitInput), "Cannot run hooks", "error", err)
+	}
+
+	// Read and analyze the track once for every output format
+	job, err := loadJob(ctx, cfg)
+	if err != nil {
+		fatal(exitCode(err, exitInput), "Cannot process track", "error", err)
+	}
+
+	// Log detailed information about the loaded GPS points at debug level
+	logPointsInfo(job.Points, cfg.Input.CSVFile)
+	logStatsInfo(job.Summary, cfg.Statistics.DistanceUnits)
+
+	// Serve mode renders the map in memory for each request until interrupted
+	if command == "serve" {
+		err := runServe(ctx, job, flags.Addr, flags.GRPCAddr)
+		if errors.Is(err, context.Canceled) {
+			// Interrupting is the usual way to stop the server, not an error
+			slog.Info("Server stopped")
+			closeLog()
+			os.Exit(exitInterrupted)
+		}
+		fatal(exitFailure, "Server stopped", "error", err)
+	}
+
+	// Write every requested format from the same processed points
+	formats, err := exportFormats(cfg, flags)
+	if err != nil {
+		fatal(exitConfig, "Invalid export formats", "error", err)
+	}
+	// Check the period summaries too, so an existing file stops the run before anything is written
+	if err := export.CheckOutputs(periodFiles(cfg), cfg.Output.Overwrite); err != nil {
+		fatal(exitOutput, "Cannot export", "error", err)
+	}
+	outputs, err := export.Run(ctx, formats, job)
+	for _, output := range outputs {
+		if output.Backup != "" {
+			report("Previous %s saved as: %s\n", strings.ToLower(output.Description), output.Backup)
+		}
+		if output.Format != export.FormatHTML {
+			report("%s written to: %s\n", output.Description, output.File)
+		}
+	}
+	if err != nil {
+		code := exitOutput
+		if errors.Is(err, staticmap.ErrAPI) {
+			code = exitAPI
+		} else if errors.Is(err, mapgen.ErrConfig) {
+			code = exitConfig
+		}
+		fatal(exitCode(err, code), "Cannot export", "error", err)
+	}
+
+	// Export per-day and per-week summaries if configured
+	if err := writePeriodFiles(cfg, job.Points); err != nil {
+		fatal(exitCode(err, exitOutput), "Cannot write period summaries", "error", err)
+	}
+
+	// Inform user of successful completion, with the route totals in verbose mode
+	for _, output := range outputs {
+		if output.Format == export.FormatHTML {
+			report("Map generated successfully: %s\n", output.File)
+			report("Open the file in your browser to view the interactive map\n")
+		}
+	}
+	if verbosity >= verbosityVerbose {
+		printTrackSummary(os.Stdout, job)
+	}
+
+	// Run the post_generate hooks, such as publishing the map to a web host
+	event := hooks.Event{Name: hooks.PostGenerate, Input: cfg.Input.CSVFile}
+	for _, output := range outputs {
+		if output.Format == export.FormatHTML {
+			event.Output = output.File
+		}
+		event.Files = append(event.Files, output.File)
+	}
+	event.Files = append(event.Files, periodFiles(cfg)...)
+	if err := runHooks(ctx, cfg, event); err != nil {
+		fatal(exitCode(err, exitOutput), "Cannot run hooks", "error", err)
 	}
+}
 
-	// Create CSV reader with appropriate format configuration
-	reader := csv.NewReader(&cfg.Input.CSVFormat, &cfg.Processing)
+// runHooks runs the hooks of an event, passing command output through to stderr.
+func runHooks(ctx context.Context, cfg *config.Config, event hooks.Event) error {
+	return hooks.Run(ctx, &cfg.Hooks, event, os.Stderr)
+}
 
-	// Read and parse GPS points from the CSV file
-	points, err := reader.ReadFile(cfg.Input.CSVFile)
+// loadJob reads the configured track and reference route, computes the route
+// statistics, and looks up the weather and offline tiles when enabled. Errors
+// read as the failed step, such as "reading input file: ...", and configuration
+// and service failures carry their exit status; the rest are input errors.
+func loadJob(ctx context.Context, cfg *config.Config) (*export.Job, error) {
+	// Read, filter, and sort GPS points from the CSV file
+	points, err := loadPoints(ctx, cfg, cfg.Input.CSVFile)
 	if err != nil {
-		log.Fatalf("Error reading CSV file: %v", err)
+		return nil, fmt.Errorf("reading input file: %w", err)
+	}
+	return jobFor(ctx, cfg, points)
+}
+
+// jobFor completes a job from processed points: the reference route, route
+// statistics, weather, and offline tiles, as configured.
+func jobFor(ctx context.Context, cfg *config.Config, points gps.Points) (*export.Job, error) {
+	// Load the reference route for comparison mode, if one is configured
+	reference, err := loadReference(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("reading reference route: %w", err)
+	}
+
+	// Calculate route statistics for logging and export
+	summary := stats.Compute(points, &cfg.Statistics)
+	if reference != nil {
+		summary.Deviation = stats.CompareTracks(points, reference, cfg.Compare.OffRouteThreshold)
+	}
+
+	// Look up the weather during the track to annotate the map
+	var report *weather.Report
+	if cfg.Weather.Enabled {
+		client, err := weather.New(&cfg.Weather)
+		if err != nil {
+			return nil, withExit(exitConfig, fmt.Errorf("looking up weather: %w", err))
+		}
+		if report, err = client.Lookup(ctx, points, cfg.Statistics.DistanceUnits == "imperial"); err != nil {
+			return nil, withExit(exitAPI, fmt.Errorf("looking up weather: %w", err))
+		}
+	}
+
+	// Bundle the tiles around the track so the leaflet map works without network access
+	var bundle *tiles.Bundle
+	if cfg.Map.OfflineTiles.Enabled {
+		tileURL := cfg.Map.TileURL
+		if tileURL == "" {
+			tileURL = mapgen.DefaultTileURL
+		}
+		client, err := tiles.New(tileURL, &cfg.Map.OfflineTiles)
+		if err != nil {
+			return nil, withExit(exitConfig, fmt.Errorf("bundling offline tiles: %w", err))
+		}
+		client.Progress = newProgress("Downloading tiles", 0, progress.Bytes)
+		bundle, err = client.Fetch(ctx, points)
+		client.Progress.Finish()
+		if err != nil {
+			return nil, withExit(exitAPI, fmt.Errorf("bundling offline tiles: %w", err))
+		}
+		slog.Info("Bundled offline tiles", "tiles", len(bundle.Tiles), "min_zoom", bundle.MinZoom, "max_zoom", bundle.MaxZoom)
+	}
+
+	return &export.Job{Points: points, Reference: reference, Summary: summary, Config: cfg, Weather: report, Tiles: bundle}, nil
+}
+
+// exportFormats returns the formats to write: the -export list when given,
+// otherwise those enabled in the configuration.
+func exportFormats(cfg *config.Config, flags *Flags) ([]string, error) {
+	if flags.Export != "" {
+		return export.Parse(flags.Export)
+	}
+	return export.Configured(cfg)
+}
+
+// loadPoints reads GPS points from an input file in the configured or detected
+// format, applies area filters, sorts the result chronologically, runs the
+// processing pipeline, and optionally snaps it to roads. Returns an error if no
+// valid points remain.
+func loadPoints(ctx context.Context, cfg *config.Config, csvFile string) (gps.Points, error) {
+	// Create the reader for the file's format. Duplicates are left for the
+	// pipeline's dedupe stage so they run in the configured order.
+	readCfg := *cfg
+	readCfg.Processing.RemoveDuplicates = false
+	reader, err := input.New(csvFile, cfg.Input.Format, &readCfg)
+	if err != nil {
+		return nil, withExit(exitConfig, err)
+	}
+	bar := newProgress("Reading "+filepath.Base(csvFile), 0, "rows")
+	if reporter, ok := reader.(input.ProgressReporter); ok {
+		reporter.SetProgress(bar)
+	}
+
+	// Stream GPS points from the file so filtered-out rows are never stored
+	var readErr error
+	seq := reader.Stream(ctx, csvFile, &readErr)
+
+	// Restrict points to the configured GeoJSON areas
+	inAreas, err := areaFilter(&cfg.Processing)
+	if err != nil {
+		return nil, err
+	}
+	if inAreas != nil {
+		seq = seq.Filter(inAreas)
+	}
+
+	points := seq.Collect()
+	bar.Finish()
+	if readErr != nil {
+		return nil, readErr
 	}
 
 	// Ensure we have valid GPS data to work with
 	if points.IsEmpty() {
-		log.Fatal("No valid GPS points found in CSV file")
+		return nil, fmt.Errorf("no valid GPS points found in %s", csvFile)
+	}
+	return processPoints(ctx, cfg, points, csvFile)
+}
+
+// processPoints sorts points chronologically, runs the configured filter stages,
+// and snaps the result onto the road network if a provider is configured. The
+// source names the points in errors.
+func processPoints(ctx context.Context, cfg *config.Config, points gps.Points, source string) (gps.Points, error) {
+	processor, err := pipeline.New(&cfg.Processing)
+	if err != nil {
+		return nil, withExit(exitConfig, err)
 	}
 
+	metrics.PointsIngested.Add(len(points))
+
 	// Sort GPS points by timestamp to create chronological path
 	points.SortByTimestamp()
 
-	// Log detailed information about loaded GPS points if verbose mode is enabled
-	if cfg.Logging.Verbose {
-		logPointsInfo(points, cfg.Input.CSVFile)
+	// Run the configured filter stages, reporting how many points each removed
+	points, results := processor.Run(points)
+	logPipelineInfo(results)
+	if points.IsEmpty() {
+		return nil, fmt.Errorf("no GPS points left in %s after processing", source)
 	}
 
-	// Create map generator and generate interactive HTML map
-	generator := mapgen.NewGenerator(cfg)
-	if err := generator.Generate(points, cfg.Output.HTMLFile); err != nil {
-		log.Fatalf("Error generating map: %v", err)
+	// Snap the track onto the road network if a provider is configured
+	snapper, err := roads.New(&cfg.Processing.SnapToRoads, cfg.GoogleMaps.APIKey)
+	if err != nil {
+		return nil, withExit(exitConfig, err)
+	}
+	if snapper != nil {
+		bar := newProgress("Snapping to roads", int64(len(points)), "points")
+		if reporter, ok := snapper.(roads.ProgressReporter); ok {
+			reporter.SetProgress(bar)
+		}
+		points, err = snapper.Snap(ctx, points)
+		bar.Finish()
+		if err != nil {
+			return nil, withExit(exitAPI, err)
+		}
 	}
 
-	// Inform user of successful completion
-	fmt.Printf("Map generated successfully: %s\n", cfg.Output.HTMLFile)
-	fmt.Printf("Open the file in your browser to view the interactive map\n")
+	metrics.TracksProcessed.Inc()
+	return points, nil
+}
+
+// loadReference reads the reference route configured for comparison mode, in the
+// format detected from its extension: GPX files are read as tracks and routes, and
+// CSV files using the configured columns. Returns nil when no reference file is
+// configured.
+func loadReference(ctx context.Context, cfg *config.Config) (gps.Points, error) {
+	file := cfg.Compare.File
+	if file == "" {
+		return nil, nil
+	}
+
+	reader, err := input.New(file, "", cfg)
+	if err != nil {
+		return nil, err
+	}
+	var readErr error
+	route := reader.Stream(ctx, file, &readErr).Collect()
+	if readErr != nil {
+		return nil, readErr
+	}
+	if route.IsEmpty() {
+		return nil, fmt.Errorf("no valid GPS points found in %s", file)
+	}
+	return route, nil
+}
+
+// areaFilter builds a predicate keeping points inside the include polygons and outside
+// the exclude polygons loaded from the configured GeoJSON files. Unset files are skipped;
+// nil is returned when neither file is configured.
+func areaFilter(proc *config.ProcessingConfig) (func(gps.Point) bool, error) {
+	if proc.IncludeAreas == "" && proc.ExcludeAreas == "" {
+		return nil, nil
+	}
+
+	var include, exclude []gps.Area
+	var err error
+	if proc.IncludeAreas != "" {
+		if include, err = geojson.ReadAreas(proc.IncludeAreas); err != nil {
+			return nil, err
+		}
+	}
+	if proc.ExcludeAreas != "" {
+		if exclude, err = geojson.ReadAreas(proc.ExcludeAreas); err != nil {
+			return nil, err
+		}
+	}
+
+	return gps.InAreas(include, exclude), nil
+}
+
+// loadConfig loads the configuration file with the -profile (or
+// GEOCHRONO_PROFILE) profile applied, then the GEOCHRONO_ environment
+// variables and ${VAR} references. When no -config flag is given,
+// the default config.yaml does not exist, and GEOCHRONO_ variables are set, the
+// environment configures the run on top of the example configuration, so
+// containers need no mounted config file.
+func loadConfig(flags *Flags) (*config.Config, error) {
+	profile := flags.Profile
+	if profile == "" {
+		profile = os.Getenv(config.ProfileEnv)
+	}
+	cfg, err := config.LoadProfile(flags.ConfigFile, profile)
+	if errors.Is(err, fs.ErrNotExist) && !flagGiven("config") && config.HasEnv(os.Environ()) {
+		cfg, err = config.ParseProfile(geochrono.ExampleConfig, profile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.ApplyEnv(os.Environ()); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// progressOutput receives the progress bars of long operations, such as reading
+// a large CSV file. It is nil when progress is hidden.
+var progressOutput io.Writer
+
+// progressWriter returns where progress bars are drawn: stderr when it is a
+// terminal, so redirected output and logs never see them, and nowhere with -quiet.
+func progressWriter(quiet bool) io.Writer {
+	if quiet {
+		return nil
+	}
+	info, err := os.Stderr.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+	return os.Stderr
+}
+
+// newProgress starts a progress bar on progressOutput, or returns nil, which
+// reports nothing, when progress is hidden.
+func newProgress(label string, total int64, unit string) *progress.Bar {
+	if progressOutput == nil {
+		return nil
+	}
+	return progress.New(progressOutput, label, total, unit)
+}
+
+// Verbosity tiers of the console output, chosen with -quiet, -v, and -vv or the
+// logging settings.
+const (
+	verbosityQuiet   = -1 // Errors only
+	verbosityNormal  = 0  // Warnings, and the files written
+	verbosityVerbose = 1  // Processing details, and the route summary at the end
+	verbosityTrace   = 2  // Each request to external services too
+)
+
+// verbosity is the tier of the current run.
+var verbosity = verbosityNormal
+
+// verbosityOf returns the tier matching the logging settings: level error is
+// quiet, debug (or verbose) is verbose, and trace is the most detailed.
+func verbosityOf(cfg *config.LoggingConfig) int {
+	level, _ := logging.ParseLevel(cfg.Level)
+	switch {
+	case level >= slog.LevelError:
+		return verbosityQuiet
+	case level <= logging.LevelTrace:
+		return verbosityTrace
+	case level <= slog.LevelDebug || cfg.Verbose:
+		return verbosityVerbose
+	}
+	return verbosityNormal
+}
+
+// report prints a result line, such as a file written, unless the run is quiet.
+func report(format string, args ...any) {
+	if verbosity > verbosityQuiet {
+		fmt.Printf(format, args...)
+	}
+}
+
+// printTrackSummary writes the route totals printed at the end of verbose runs.
+func printTrackSummary(w io.Writer, job *export.Job) {
+	summary, units := job.Summary, job.Config.Statistics.DistanceUnits
+	start, end := job.Points.TimeRange()
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Points:\t%d (%s to %s)\n", len(job.Points), start.Format("2006-01-02 15:04:05"), end.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(tw, "Distance:\t%s\n", stats.FormatDistance(summary.Distance, units))
+	fmt.Fprintf(tw, "Duration:\t%s (moving %s)\n", stats.FormatDuration(summary.Duration), stats.FormatDuration(summary.MovingTime))
+	fmt.Fprintf(tw, "Speed:\t%s moving average, %s max\n", stats.FormatSpeed(summary.MovingAvgSpeed, units), stats.FormatSpeed(summary.MaxSpeed, units))
+	tw.Flush()
+}
+
+// closeLog closes the log file once logging is set up from the configuration.
+var closeLog = func() error { return nil }
+
+// flagGiven reports whether the named flag was set on the command line.
+func flagGiven(name string) bool {
+	given := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			given = true
+		}
+	})
+	return given
+}
+
+// subcommand removes and returns a leading subcommand name (such as "doctor") from
+// the command line arguments, so the remaining flags parse normally.
+// Returns an empty string when the first argument is a flag or absent.
+func subcommand() string {
+	if len(os.Args) < 2 || strings.HasPrefix(os.Args[1], "-") {
+		return ""
+	}
+	command := os.Args[1]
+	os.Args = append(os.Args[:1], os.Args[2:]...)
+	return command
 }
 
 // Flags holds command line flag values that can override configuration file settings.
 // This allows users to customize behavior without modifying the config file.
 type Flags struct {
-	ConfigFile string // Path to YAML configuration file
-	CSVFile    string // Path to input CSV file with GPS data
-	APIKey     string // Google Maps API key for map generation
-	Output     string // Path to output HTML file
-	Title      string // Title to display on the generated map
+	ConfigFile  string // Path to YAML configuration file
+	Profile     string // Profile of the configuration file to apply
+	CSVFile     string // Path to input CSV file with GPS data
+	APIKey      string // Google Maps API key for map generation
+	Output      string // Path to output HTML file
+	Title       string // Title to display on the generated map
+	Batch       string // Glob pattern of CSV files for batch mode
+	OutputDir   string // Output directory for batch mode maps
+	SummaryCSV  string // Optional CSV file for the batch summary table
+	Workers     int    // Files processed at once in batch mode, 0 for one per CPU
+	Compare     string // Reference route file for comparison mode
+	Export      string // Comma-separated output formats, overriding output.formats
+	Force       bool   // Replace existing output files
+	Backup      bool   // Keep existing output files as timestamped backups
+	Addr        string // Address the serve command listens on
+	GRPCAddr    string // Address of the serve command's gRPC service, empty for none
+	Interactive bool   // Ask for the main settings in config init
+	Version     bool   // Print the version and exit
+	Quiet       bool   // Show errors only
+	JSON        bool   // Print the version as JSON
+	// Map and path appearance
+	Zoom     int    // Initial zoom level, overriding map.initial_view.zoom
+	MapType  string // Base map type, overriding map.initial_view.map_type
+	Provider string // Map provider, overriding map.provider
+	Color    string // Path color, overriding path.style.color
+	// Processing filters
+	Dedupe      bool    // Remove duplicate points, overriding processing.remove_duplicates
+	MinDistance float64 // Minimum distance between points in meters
+	MaxSpeed    float64 // Maximum realistic speed in km/h
+	Smooth      bool    // Smooth the path, overriding processing.smooth_path
+	Simplify    float64 // Path simplification tolerance in meters
+	Timezone    string  // Time zone for timestamps and summaries
+	// Export files
+	KMLFile     string // KML output file, enabling KML export
+	GeoJSONFile string // GeoJSON output file
+	GPXFile     string // GPX output file
+	StatsFile   string // Statistics JSON output file
+	XLSXFile    string // Excel report output file
+	ImageFile   string // Static map image output file
+	// Output detail
+	Verbose     bool // Log processing details, overriding logging.verbose
+	VeryVerbose bool // Log each request to external services too
 }
 
 // parseFlags parses and validates command line arguments.
@@ -116,10 +697,44 @@ func parseFlags() *Flags {
 
 	// Define command line flags with descriptions and defaults
 	flag.StringVar(&flags.ConfigFile, "config", "config.yaml", "Path to configuration file")
-	flag.StringVar(&flags.CSVFile, "csv", "", "Path to CSV file (overrides config)")
+	flag.StringVar(&flags.Profile, "profile", "", "Profile of the configuration file to apply (such as hiking)")
+	flag.StringVar(&flags.CSVFile, "csv", "", "Path to the input CSV or GPX file (overrides config)")
 	flag.StringVar(&flags.APIKey, "apikey", "", "Google Maps API key (overrides config)")
 	flag.StringVar(&flags.Output, "out", "", "Output HTML file (overrides config)")
 	flag.StringVar(&flags.Title, "title", "", "Map title (overrides config)")
+	flag.StringVar(&flags.Batch, "batch", "", "Glob pattern of CSV files to process in batch mode")
+	flag.StringVar(&flags.OutputDir, "outdir", ".", "Output directory for batch mode maps")
+	flag.StringVar(&flags.SummaryCSV, "summary", "", "Write the batch summary table to this CSV file")
+	flag.IntVar(&flags.Workers, "workers", 0, "Files processed at once in batch mode (default one per CPU)")
+	flag.StringVar(&flags.Compare, "compare", "", "Reference route (.gpx or .csv) to compare the track against")
+	flag.StringVar(&flags.Export, "export", "", "Comma-separated output formats (html, kml, geojson, gpx, stats, image)")
+	flag.BoolVar(&flags.Force, "force", false, "Replace existing output files")
+	flag.BoolVar(&flags.Backup, "backup", false, "Keep existing output files as timestamped backups (takes precedence over -force)")
+	flag.StringVar(&flags.Addr, "addr", server.DefaultAddr, "Address the serve command listens on")
+	flag.StringVar(&flags.GRPCAddr, "grpc-addr", "", "Address of the serve command's gRPC service (off when empty)")
+	flag.BoolVar(&flags.Interactive, "interactive", false, "Ask for the input file, columns, and API key (config init)")
+	flag.BoolVar(&flags.Quiet, "quiet", false, "Show errors only: no warnings, results, or progress bars")
+	flag.BoolVar(&flags.Version, "version", false, "Print the version, commit, and build date, then exit")
+	flag.BoolVar(&flags.JSON, "json", false, "Print the version as JSON (version command)")
+	flag.IntVar(&flags.Zoom, "zoom", 0, "Initial zoom level (overrides config)")
+	flag.StringVar(&flags.MapType, "maptype", "", "Base map type: roadmap, satellite, hybrid, or terrain (overrides config)")
+	flag.StringVar(&flags.Provider, "provider", "", "Map provider: google, leaflet, maplibre, or cesium (overrides config)")
+	flag.StringVar(&flags.Color, "color", "", "Path color, such as #FF0000 (overrides config)")
+	flag.BoolVar(&flags.Dedupe, "dedupe", false, "Remove duplicate GPS points (overrides config)")
+	flag.Float64Var(&flags.MinDistance, "mindistance", 0, "Minimum distance between points in meters (overrides config)")
+	flag.Float64Var(&flags.MaxSpeed, "maxspeed", 0, "Maximum realistic speed in km/h (overrides config)")
+	flag.BoolVar(&flags.Smooth, "smooth", false, "Smooth the path with a moving average (overrides config)")
+	flag.Float64Var(&flags.Simplify, "simplify", 0, "Simplify the path within this many meters, 0 to disable (overrides config)")
+	flag.StringVar(&flags.Timezone, "timezone", "", "Time zone for timestamps and summaries, such as Europe/Berlin (overrides config)")
+	flag.StringVar(&flags.KMLFile, "kml", "", "Write a KML file (overrides config)")
+	flag.StringVar(&flags.GeoJSONFile, "geojson", "", "Write a GeoJSON file (overrides config)")
+	flag.StringVar(&flags.GPXFile, "gpx", "", "Write a GPX file (overrides config)")
+	flag.StringVar(&flags.StatsFile, "stats", "", "Write a statistics JSON file (overrides config)")
+	flag.StringVar(&flags.XLSXFile, "xlsx", "", "Write an Excel report (overrides config)")
+	flag.StringVar(&flags.ImageFile, "image", "", "Write a static map image (overrides config)")
+	flag.BoolVar(&flags.Verbose, "verbose", false, "Log processing details and print the route summary (overrides config)")
+	flag.BoolVar(&flags.Verbose, "v", false, "Same as -verbose")
+	flag.BoolVar(&flags.VeryVerbose, "vv", false, "Also log each request to external services")
 
 	// Parse all provided command line arguments
 	flag.Parse()
@@ -155,18 +770,202 @@ func overrideConfigWithFlags(cfg *config.Config, flags *Flags) {
 	if flags.Title != "" {
 		cfg.Map.Title = flags.Title
 	}
+
+	// Override the comparison reference route if provided
+	if flags.Compare != "" {
+		cfg.Compare.File = flags.Compare
+	}
+
+	// Override map and path appearance. Flags whose zero value is meaningful,
+	// such as zoom level 0 or -dedupe=false, apply only when given
+	if flagGiven("zoom") {
+		zoom := flags.Zoom
+		cfg.Map.InitialView.Zoom = &zoom
+	}
+	if flags.MapType != "" {
+		cfg.Map.InitialView.MapType = flags.MapType
+	}
+	if flags.Provider != "" {
+		cfg.Map.Provider = flags.Provider
+	}
+	if flags.Color != "" {
+		cfg.Path.Style.Color = flags.Color
+	}
+
+	// Override processing filters
+	if flagGiven("dedupe") {
+		cfg.Processing.RemoveDuplicates = flags.Dedupe
+	}
+	if flagGiven("mindistance") {
+		cfg.Processing.MinDistanceFilter = flags.MinDistance
+	}
+	if flagGiven("maxspeed") {
+		cfg.Processing.MaxSpeedFilter = flags.MaxSpeed
+	}
+	if flagGiven("smooth") {
+		cfg.Processing.SmoothPath = flags.Smooth
+	}
+	if flagGiven("simplify") {
+		cfg.Processing.SimplifyTolerance = flags.Simplify
+	}
+	if flags.Timezone != "" {
+		cfg.Processing.Timezone = flags.Timezone
+	}
+
+	// Override export files; each one given is written alongside the map
+	if flags.KMLFile != "" {
+		cfg.Output.KMLFile = flags.KMLFile
+		cfg.Output.ExportKML = true
+	}
+	if flags.GeoJSONFile != "" {
+		cfg.Output.GeoJSONFile = flags.GeoJSONFile
+	}
+	if flags.GPXFile != "" {
+		cfg.Output.GPXFile = flags.GPXFile
+	}
+	if flags.StatsFile != "" {
+		cfg.Output.StatsFile = flags.StatsFile
+	}
+	if flags.XLSXFile != "" {
+		cfg.Output.XLSXFile = flags.XLSXFile
+	}
+	if flags.ImageFile != "" {
+		cfg.Output.Image.File = flags.ImageFile
+	}
+
+	// Override output detail: -quiet keeps errors only, -vv logs at trace level
+	switch {
+	case flags.Quiet:
+		cfg.Logging.Level = "error"
+		cfg.Logging.Verbose = false
+	case flags.VeryVerbose:
+		cfg.Logging.Level = "trace"
+	case flagGiven("verbose") || flagGiven("v"):
+		cfg.Logging.Verbose = flags.Verbose
+	}
+
+	// Override the policy for existing output files, preferring the one that keeps data
+	switch {
+	case flags.Backup:
+		cfg.Output.Overwrite = export.OverwriteBackup
+	case flags.Force:
+		cfg.Output.Overwrite = export.OverwriteForce
+	}
+}
+
+// prepareOutput applies the overwrite policy to an output file about to be written
+// and reports where a replaced file was backed up.
+func prepareOutput(filename, overwrite string) error {
+	backup, err := export.PrepareOutput(filename, overwrite)
+	if backup != "" {
+		report("Previous %s saved as: %s\n", filename, backup)
+	}
+	return err
 }
 
-// logPointsInfo displays detailed information about the loaded GPS points,
-// including the total count and time range of the data.
-// This helps users understand the scope and coverage of their GPS data.
+// logPointsInfo logs the number of loaded GPS points and their time range at
+// debug level, which verbose mode enables.
 func logPointsInfo(points gps.Points, filename string) {
-	// Get the time span of the GPS data
 	start, end := points.TimeRange()
+	slog.Debug("Loaded GPS points",
+		"file", filename,
+		"points", len(points),
+		"start", start.Format("2006-01-02 15:04:05"),
+		"end", end.Format("2006-01-02 15:04:05"))
+}
+
+// logPipelineInfo logs the point counts before and after each processing stage
+// at debug level.
+func logPipelineInfo(results []pipeline.Result) {
+	for _, result := range results {
+		slog.Debug("Pipeline stage",
+			"stage", result.Stage,
+			"before", result.Before,
+			"after", result.After,
+			"removed", result.Removed())
+	}
+}
 
-	// Display summary information
-	fmt.Printf("Loaded %d GPS points from %s\n", len(points), filename)
-	fmt.Printf("Time range: %s to %s\n",
-		start.Format("2006-01-02 15:04:05"),
-		end.Format("2006-01-02 15:04:05"))
+// logStatsInfo logs the route statistics at debug level, including distance and
+// the split between moving and stopped time.
+func logStatsInfo(summary *stats.Summary, units string) {
+	slog.Debug("Route statistics",
+		"distance", stats.FormatDistance(summary.Distance, units),
+		"duration", stats.FormatDuration(summary.Duration),
+		"moving_time", stats.FormatDuration(summary.MovingTime),
+		"stopped_time", stats.FormatDuration(summary.StoppedTime),
+		"moving_average_speed", stats.FormatSpeed(summary.MovingAvgSpeed, units),
+		"max_speed", stats.FormatSpeed(summary.MaxSpeed, units),
+		"pace", stats.FormatPace(summary.Pace, units),
+		"splits", len(summary.Splits),
+		"route", stats.FormatLoop(summary))
+	if summary.HasBearing {
+		slog.Debug("Route heading",
+			"initial", stats.FormatBearing(summary.InitialBearing),
+			"average", stats.FormatBearing(summary.AverageBearing))
+	}
+	if summary.Deviation != nil {
+		slog.Debug("Off-route deviation",
+			"max_m", math.Round(summary.Deviation.MaxDistance),
+			"average_m", math.Round(summary.Deviation.AvgDistance),
+			"beyond_threshold_percent", math.Round(summary.Deviation.OffRouteFraction*100),
+			"threshold_m", summary.Deviation.Threshold)
+	}
+}
+
+// periodFiles returns the configured daily and weekly summary files.
+func periodFiles(cfg *config.Config) []string {
+	var files []string
+	for _, file := range []string{cfg.Output.DailyFile, cfg.Output.WeeklyFile} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// writePeriodFiles exports the configured daily and weekly summaries, using the
+// processing timezone for day boundaries.
+func writePeriodFiles(cfg *config.Config, points gps.Points) error {
+	if cfg.Output.DailyFile == "" && cfg.Output.WeeklyFile == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(cfg.Processing.Timezone)
+	if err != nil {
+		return withExit(exitConfig, fmt.Errorf("invalid processing timezone: %w", err))
+	}
+
+	if cfg.Output.DailyFile != "" {
+		if err := writePeriodFile(aggregate.Daily(points, &cfg.Statistics, loc), cfg.Output.DailyFile, cfg.Output.Overwrite); err != nil {
+			return err
+		}
+		report("Daily summaries written to: %s\n", cfg.Output.DailyFile)
+	}
+	if cfg.Output.WeeklyFile != "" {
+		if err := writePeriodFile(aggregate.Weekly(points, &cfg.Statistics, loc), cfg.Output.WeeklyFile, cfg.Output.Overwrite); err != nil {
+			return err
+		}
+		report("Weekly summaries written to: %s\n", cfg.Output.WeeklyFile)
+	}
+	return nil
+}
+
+// writePeriodFile exports period summaries as CSV for .csv files and JSON otherwise,
+// applying the overwrite policy to an existing file.
+func writePeriodFile(periods []aggregate.Period, filename, overwrite string) error {
+	if err := prepareOutput(filename, overwrite); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("cannot create summary file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return aggregate.WriteCSV(file, periods)
+	}
+	return aggregate.WriteJSON(file, periods)
 }
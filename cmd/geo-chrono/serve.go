@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/export"
+	"github.com/saratily/geo-chrono/internal/grpcserver"
+	"github.com/saratily/geo-chrono/internal/server"
+	"github.com/saratily/geo-chrono/internal/store"
+)
+
+// serveReadHeaderTimeout bounds how long a client may take to send request headers.
+const serveReadHeaderTimeout = 10 * time.Second
+
+// serveShutdownTimeout bounds how long an interrupted server waits for requests
+// in progress to finish.
+const serveShutdownTimeout = 5 * time.Second
+
+// serveWatchInterval is how often serve mode checks the input file for new points.
+const serveWatchInterval = 2 * time.Second
+
+// runServe serves the map and data of a processed track on addr until the server
+// fails or ctx is cancelled, which shuts the server down and returns ctx.Err().
+// The map is rendered on each request, so no output files are written.
+// Points a tracker appends to the input file are pushed to open maps, and the
+// track API stores uploaded tracks in the configured backend. With grpcAddr set,
+// the gRPC service processes and renders tracks on that address too, sharing
+// the backend.
+func runServe(ctx context.Context, job *export.Job, addr, grpcAddr string) error {
+	tracks, err := store.New(&job.Config.Server.Storage)
+	if err != nil {
+		return err
+	}
+	slog.Info("Serving track", "file", job.Config.Input.CSVFile, "url", serveURL(addr))
+
+	loader := trackLoader(ctx, job.Config)
+	handler := server.New(job)
+	handler.EnableAPI(tracks, loader)
+	go watchInput(ctx, job.Config, handler)
+
+	if grpcAddr != "" {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			return fmt.Errorf("cannot listen for gRPC: %w", err)
+		}
+		grpcServer := grpcserver.New(&job.Config.Server, tracks, loader).NewServer()
+		defer grpcServer.Stop()
+		stopGRPC := context.AfterFunc(ctx, grpcServer.GracefulStop)
+		defer stopGRPC()
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				slog.Error("gRPC service stopped", "error", err)
+			}
+		}()
+		slog.Info("Serving gRPC", "addr", listener.Addr().String())
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: serveReadHeaderTimeout,
+		// Requests share ctx, so open live update streams end on shutdown
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	stopShutdown := context.AfterFunc(ctx, func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	})
+	defer stopShutdown()
+
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return ctx.Err()
+}
+
+// watchInput reprocesses the input file whenever its size or modification time
+// changes and hands the result to the server, until ctx is cancelled. A file
+// caught mid-write or otherwise unreadable is logged and retried on the next change.
+func watchInput(ctx context.Context, cfg *config.Config, handler *server.Server) {
+	last, _ := os.Stat(cfg.Input.CSVFile)
+	ticker := time.NewTicker(serveWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(cfg.Input.CSVFile)
+		if err != nil || (last != nil && info.Size() == last.Size() && info.ModTime().Equal(last.ModTime())) {
+			continue
+		}
+		last = info
+
+		job, err := loadJob(ctx, cfg)
+		if err != nil {
+			slog.Error("Cannot reload input", "file", cfg.Input.CSVFile, "error", err)
+			continue
+		}
+		handler.Update(job)
+		slog.Info("Reloaded input", "file", cfg.Input.CSVFile, "points", len(job.Points))
+	}
+}
+
+// trackLoader processes uploaded tracks like the input file, titling each map
+// with the track name.
+func trackLoader(ctx context.Context, cfg *config.Config) server.Loader {
+	return func(track *store.Track) (*export.Job, error) {
+		trackCfg := *cfg
+		trackCfg.Map.Title = track.Name
+
+		// Work on a copy, since processing sorts the points in place
+		points := slices.Clone(track.Points)
+		inAreas, err := areaFilter(&trackCfg.Processing)
+		if err != nil {
+			return nil, err
+		}
+		if inAreas != nil {
+			points = points.Filter(inAreas)
+		}
+		if points.IsEmpty() {
+			return nil, fmt.Errorf("no GPS points of track %s inside the configured areas", track.ID)
+		}
+
+		if points, err = processPoints(ctx, &trackCfg, points, "track "+track.ID); err != nil {
+			return nil, err
+		}
+		return jobFor(ctx, &trackCfg, points)
+	}
+}
+
+// serveURL returns the browser URL of a listen address, using localhost when the
+// address leaves out the host.
+func serveURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr + "/"
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port) + "/"
+}
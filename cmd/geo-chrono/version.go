@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+)
+
+// Build metadata, injected at build time with
+//
+//	go build -ldflags "-X main.version=1.4.0 -X main.commit=$(git rev-parse HEAD) -X main.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/geo-chrono
+//
+// Builds without them report version "dev" and take the commit and date from
+// the version control information Go records in the binary, when there is any.
+var (
+	version = "dev" // Semantic version of the release
+	commit  = ""    // Git commit the binary was built from
+	date    = ""    // Build date (RFC 3339)
+)
+
+// versionInfo describes the running binary for bug reports.
+type versionInfo struct {
+	Version   string `json:"version"`            // Semantic version, or "dev"
+	Commit    string `json:"commit,omitempty"`   // Git commit hash
+	Date      string `json:"date,omitempty"`     // Build date or commit time
+	Modified  bool   `json:"modified,omitempty"` // Built from a working tree with uncommitted changes
+	GoVersion string `json:"go_version"`         // Go toolchain used for the build
+	Platform  string `json:"platform"`           // Operating system and architecture
+}
+
+// buildVersion returns the injected build metadata, completed from the build
+// information recorded by the Go toolchain.
+func buildVersion() versionInfo {
+	info := versionInfo{
+		Version:   version,
+		Commit:    commit,
+		Date:      date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true" && commit == ""
+			}
+		}
+	}
+	return info
+}
+
+// printVersion writes the version line, or with asJSON set, the version
+// information as a JSON object.
+func printVersion(w io.Writer, asJSON bool) error {
+	info := buildVersion()
+	if asJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+
+	line := "geo-chrono " + info.Version
+	if info.Commit != "" {
+		line += " (commit " + shortCommit(info.Commit)
+		if info.Modified {
+			line += "-dirty"
+		}
+		if info.Date != "" {
+			line += ", built " + info.Date
+		}
+		line += ")"
+	} else if info.Date != "" {
+		line += " (built " + info.Date + ")"
+	}
+	_, err := fmt.Fprintf(w, "%s %s %s\n", line, info.GoVersion, info.Platform)
+	return err
+}
+
+// shortCommit abbreviates a full commit hash, still unambiguous in practice.
+func shortCommit(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
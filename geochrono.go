@@ -0,0 +1,10 @@
+// Package geochrono holds repository files that the command embeds.
+package geochrono
+
+import _ "embed"
+
+// ExampleConfig is the commented example configuration, config.yaml, written
+// by "geo-chrono config init".
+//
+//go:embed config.yaml
+var ExampleConfig []byte
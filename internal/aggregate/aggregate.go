@@ -0,0 +1,176 @@
+// Package aggregate provides per-day and per-week summaries of multi-day GPS datasets.
+//
+// @title Aggregation Package
+// @version 1.0
+// @description Groups GPS points into calendar periods and summarizes each one
+// @description Reuses route statistics so every period matches the main summary
+//
+// Features:
+// - Daily and ISO-week grouping in a configurable timezone
+// - Distance, duration, moving time, and point count per period
+// - JSON and CSV export
+package aggregate
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/stats"
+)
+
+// Supported aggregation periods.
+const (
+	Day  = "day"  // Calendar days
+	Week = "week" // ISO weeks, starting on Monday
+)
+
+// Period summarizes the GPS points recorded during one calendar period.
+//
+// @struct Period
+// @description Route statistics for a single day or week
+// @property Label string Period name, such as "2025-10-28" or "2025-W44"
+// @property Start time.Time Start of the period in the aggregation timezone
+// @property Summary *stats.Summary Statistics for the points inside the period
+// @property Points gps.Points Points recorded during the period
+type Period struct {
+	Label   string         // @field Label Period name, such as "2025-10-28" or "2025-W44"
+	Start   time.Time      // @field Start Start of the period in the aggregation timezone
+	Summary *stats.Summary // @field Summary Statistics for the points inside the period
+	Points  gps.Points     // @field Points Points recorded during the period
+}
+
+// Daily groups chronologically sorted points by calendar day and summarizes each day.
+//
+// @function Daily
+// @description Produces one summary per calendar day that has points
+// @param points gps.Points GPS points sorted in chronological order
+// @param cfg *config.StatisticsConfig Statistics settings applied to each day
+// @param loc *time.Location Timezone that defines day boundaries
+// @return []Period Daily summaries in chronological order
+// @note Segments spanning midnight are not counted towards either day
+// @example days := aggregate.Daily(points, &cfg.Statistics, time.UTC)
+func Daily(points gps.Points, cfg *config.StatisticsConfig, loc *time.Location) []Period {
+	return group(points, cfg, loc, Day)
+}
+
+// Weekly groups chronologically sorted points by ISO week (Monday to Sunday) and
+// summarizes each week. Segments spanning the week boundary are not counted.
+func Weekly(points gps.Points, cfg *config.StatisticsConfig, loc *time.Location) []Period {
+	return group(points, cfg, loc, Week)
+}
+
+// group splits points into consecutive runs sharing the same period and computes
+// statistics for each run.
+func group(points gps.Points, cfg *config.StatisticsConfig, loc *time.Location, period string) []Period {
+	var periods []Period
+	begin := 0
+	for i := 1; i <= len(points); i++ {
+		if i < len(points) && periodStart(points[i].Timestamp, loc, period).Equal(periodStart(points[begin].Timestamp, loc, period)) {
+			continue
+		}
+
+		start := periodStart(points[begin].Timestamp, loc, period)
+		periods = append(periods, Period{
+			Label:   label(start, period),
+			Start:   start,
+			Summary: stats.Compute(points[begin:i], cfg),
+			Points:  points[begin:i],
+		})
+		begin = i
+	}
+	return periods
+}
+
+// periodStart returns midnight at the start of the day or ISO week containing t.
+func periodStart(t time.Time, loc *time.Location, period string) time.Time {
+	t = t.In(loc)
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	if period == Week {
+		// Go weeks start on Sunday; ISO weeks start on Monday
+		offset := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -offset)
+	}
+	return start
+}
+
+// label formats a period start as an ISO date or ISO week.
+func label(start time.Time, period string) string {
+	if period == Week {
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	}
+	return start.Format("2006-01-02")
+}
+
+// periodJSON is the machine-readable representation of a Period.
+type periodJSON struct {
+	Period            string  `json:"period"`
+	Start             string  `json:"start"`
+	Points            int     `json:"points"`
+	DistanceMeters    float64 `json:"distance_meters"`
+	DurationSeconds   float64 `json:"duration_seconds"`
+	MovingTimeSeconds float64 `json:"moving_time_seconds"`
+}
+
+// WriteJSON writes the periods as an indented JSON array with durations in seconds
+// and distances in meters.
+//
+// @function WriteJSON
+// @description Exports period summaries as machine-readable JSON
+// @param w io.Writer Destination for the JSON document
+// @param periods []Period Summaries to export
+// @return error Error if encoding or writing fails
+// @example err := aggregate.WriteJSON(file, days)
+func WriteJSON(w io.Writer, periods []Period) error {
+	out := make([]periodJSON, 0, len(periods))
+	for _, p := range periods {
+		out = append(out, periodJSON{
+			Period:            p.Label,
+			Start:             p.Start.Format(time.RFC3339),
+			Points:            p.Summary.Points,
+			DistanceMeters:    p.Summary.Distance,
+			DurationSeconds:   p.Summary.Duration.Seconds(),
+			MovingTimeSeconds: p.Summary.MovingTime.Seconds(),
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("cannot encode period summaries: %w", err)
+	}
+	return nil
+}
+
+// csvHeader lists the columns of the CSV export.
+var csvHeader = []string{"period", "start", "points", "distance_meters", "duration_seconds", "moving_time_seconds"}
+
+// WriteCSV writes the periods as CSV rows with a header, using the same units as
+// WriteJSON so spreadsheets and scripts can treat both formats alike.
+func WriteCSV(w io.Writer, periods []Period) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return fmt.Errorf("cannot write period summaries: %w", err)
+	}
+	for _, p := range periods {
+		row := []string{
+			p.Label,
+			p.Start.Format(time.RFC3339),
+			strconv.Itoa(p.Summary.Points),
+			strconv.FormatFloat(p.Summary.Distance, 'f', 1, 64),
+			strconv.FormatFloat(p.Summary.Duration.Seconds(), 'f', 0, 64),
+			strconv.FormatFloat(p.Summary.MovingTime.Seconds(), 'f', 0, 64),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("cannot write period summaries: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
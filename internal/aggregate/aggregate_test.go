@@ -0,0 +1,131 @@
+// Package aggregate_test provides unit tests for daily and weekly summaries.
+// It tests calendar and ISO-week grouping, timezone-aware boundaries,
+// and the JSON and CSV exports.
+package aggregate
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// multiDayPoints returns two points on each of three days spanning a week boundary:
+// Saturday 2025-11-01, Sunday 2025-11-02, and Monday 2025-11-03.
+func multiDayPoints() gps.Points {
+	var points gps.Points
+	for day := 0; day < 3; day++ {
+		start := time.Date(2025, 11, 1+day, 9, 0, 0, 0, time.UTC)
+		points = append(points,
+			gps.Point{Timestamp: start, Latitude: 0, Longitude: 0},
+			gps.Point{Timestamp: start.Add(time.Hour), Latitude: 0.01 * float64(day+1), Longitude: 0},
+		)
+	}
+	return points
+}
+
+func TestDaily(t *testing.T) {
+	days := Daily(multiDayPoints(), nil, time.UTC)
+
+	wantLabels := []string{"2025-11-01", "2025-11-02", "2025-11-03"}
+	if len(days) != len(wantLabels) {
+		t.Fatalf("Daily() returned %d periods, want %d", len(days), len(wantLabels))
+	}
+	for i, want := range wantLabels {
+		if days[i].Label != want {
+			t.Errorf("Daily()[%d].Label = %q, want %q", i, days[i].Label, want)
+		}
+		if days[i].Summary.Points != 2 || days[i].Summary.Duration != time.Hour {
+			t.Errorf("Daily()[%d] = %d points over %v, want 2 over 1h", i, days[i].Summary.Points, days[i].Summary.Duration)
+		}
+		if len(days[i].Points) != 2 || days[i].Points[0].Timestamp.Format("2006-01-02") != want {
+			t.Errorf("Daily()[%d].Points = %v, want the two points of %s", i, days[i].Points, want)
+		}
+	}
+
+	// The overnight segments between days are not counted in any day
+	if got := days[2].Summary.Distance; got < 3330 || got > 3340 {
+		t.Errorf("Daily()[2] distance = %v, want ~3336", got)
+	}
+}
+
+func TestDailyTimezone(t *testing.T) {
+	// 23:30 and 00:30 UTC fall on the same day in New York
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 11, 1, 23, 30, 0, 0, time.UTC)},
+		{Timestamp: time.Date(2025, 11, 2, 0, 30, 0, 0, time.UTC)},
+	}
+
+	if got := Daily(points, nil, time.UTC); len(got) != 2 {
+		t.Errorf("Daily() in UTC returned %d periods, want 2", len(got))
+	}
+
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone database unavailable: %v", err)
+	}
+	got := Daily(points, nil, loc)
+	if len(got) != 1 || got[0].Label != "2025-11-01" {
+		t.Errorf("Daily() in New York = %+v, want a single 2025-11-01 period", got)
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	weeks := Weekly(multiDayPoints(), nil, time.UTC)
+
+	if len(weeks) != 2 {
+		t.Fatalf("Weekly() returned %d periods, want 2", len(weeks))
+	}
+	if weeks[0].Label != "2025-W44" || weeks[1].Label != "2025-W45" {
+		t.Errorf("Weekly() labels = %q, %q, want 2025-W44, 2025-W45", weeks[0].Label, weeks[1].Label)
+	}
+	if want := time.Date(2025, 10, 27, 0, 0, 0, 0, time.UTC); !weeks[0].Start.Equal(want) {
+		t.Errorf("Weekly()[0].Start = %v, want Monday %v", weeks[0].Start, want)
+	}
+	if weeks[0].Summary.Points != 4 || weeks[1].Summary.Points != 2 {
+		t.Errorf("Weekly() points = %d, %d, want 4, 2", weeks[0].Summary.Points, weeks[1].Summary.Points)
+	}
+
+	if got := Weekly(nil, nil, time.UTC); len(got) != 0 {
+		t.Errorf("Weekly() on empty = %v, want none", got)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, Daily(multiDayPoints(), nil, time.UTC)); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON() produced invalid JSON: %v", err)
+	}
+	if len(decoded) != 3 {
+		t.Fatalf("WriteJSON() wrote %d periods, want 3", len(decoded))
+	}
+	if decoded[0]["period"] != "2025-11-01" || decoded[0]["duration_seconds"] != 3600.0 || decoded[0]["points"] != 2.0 {
+		t.Errorf("WriteJSON() first period = %v", decoded[0])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, Weekly(multiDayPoints(), nil, time.UTC)); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("WriteCSV() wrote %d lines, want header and 2 rows", len(lines))
+	}
+	if lines[0] != strings.Join(csvHeader, ",") {
+		t.Errorf("WriteCSV() header = %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[2], "2025-W45,2025-11-03T00:00:00Z,2,") {
+		t.Errorf("WriteCSV() last row = %q", lines[2])
+	}
+}
@@ -0,0 +1,259 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// maxZoom is the highest zoom level of any supported map provider.
+const maxZoom = 22
+
+// colorPattern matches the CSS colors accepted in color settings besides color
+// names: hex codes and rgb()/rgba()/hsl()/hsla() notation.
+var colorPattern = regexp.MustCompile(`^(#([0-9A-Fa-f]{3,4}|[0-9A-Fa-f]{6}|[0-9A-Fa-f]{8})|(rgb|rgba|hsl|hsla)\([0-9.,%\s/]+\))$`)
+
+// colorNames holds the CSS named colors, such as "red", in lowercase.
+var colorNames = map[string]bool{
+	"aliceblue": true, "antiquewhite": true, "aqua": true, "aquamarine": true, "azure": true,
+	"beige": true, "bisque": true, "black": true, "blanchedalmond": true, "blue": true,
+	"blueviolet": true, "brown": true, "burlywood": true, "cadetblue": true, "chartreuse": true,
+	"chocolate": true, "coral": true, "cornflowerblue": true, "cornsilk": true, "crimson": true,
+	"cyan": true, "darkblue": true, "darkcyan": true, "darkgoldenrod": true, "darkgray": true,
+	"darkgreen": true, "darkgrey": true, "darkkhaki": true, "darkmagenta": true,
+	"darkolivegreen": true, "darkorange": true, "darkorchid": true, "darkred": true,
+	"darksalmon": true, "darkseagreen": true, "darkslateblue": true, "darkslategray": true,
+	"darkslategrey": true, "darkturquoise": true, "darkviolet": true, "deeppink": true,
+	"deepskyblue": true, "dimgray": true, "dimgrey": true, "dodgerblue": true, "firebrick": true,
+	"floralwhite": true, "forestgreen": true, "fuchsia": true, "gainsboro": true, "ghostwhite": true,
+	"gold": true, "goldenrod": true, "gray": true, "green": true, "greenyellow": true, "grey": true,
+	"honeydew": true, "hotpink": true, "indianred": true, "indigo": true, "ivory": true,
+	"khaki": true, "lavender": true, "lavenderblush": true, "lawngreen": true, "lemonchiffon": true,
+	"lightblue": true, "lightcoral": true, "lightcyan": true, "lightgoldenrodyellow": true,
+	"lightgray": true, "lightgreen": true, "lightgrey": true, "lightpink": true, "lightsalmon": true,
+	"lightseagreen": true, "lightskyblue": true, "lightslategray": true, "lightslategrey": true,
+	"lightsteelblue": true, "lightyellow": true, "lime": true, "limegreen": true, "linen": true,
+	"magenta": true, "maroon": true, "mediumaquamarine": true, "mediumblue": true,
+	"mediumorchid": true, "mediumpurple": true, "mediumseagreen": true, "mediumslateblue": true,
+	"mediumspringgreen": true, "mediumturquoise": true, "mediumvioletred": true, "midnightblue": true,
+	"mintcream": true, "mistyrose": true, "moccasin": true, "navajowhite": true, "navy": true,
+	"oldlace": true, "olive": true, "olivedrab": true, "orange": true, "orangered": true,
+	"orchid": true, "palegoldenrod": true, "palegreen": true, "paleturquoise": true,
+	"palevioletred": true, "papayawhip": true, "peachpuff": true, "peru": true, "pink": true,
+	"plum": true, "powderblue": true, "purple": true, "rebeccapurple": true, "red": true,
+	"rosybrown": true, "royalblue": true, "saddlebrown": true, "salmon": true, "sandybrown": true,
+	"seagreen": true, "seashell": true, "sienna": true, "silver": true, "skyblue": true,
+	"slateblue": true, "slategray": true, "slategrey": true, "snow": true, "springgreen": true,
+	"steelblue": true, "tan": true, "teal": true, "thistle": true, "tomato": true, "turquoise": true,
+	"violet": true, "wheat": true, "white": true, "whitesmoke": true, "yellow": true,
+	"yellowgreen": true, "transparent": true,
+}
+
+// hexColorSettings are the color settings the maps compute colors from, which
+// Config.Problems requires to be hex codes; the CSS color check skips them.
+var hexColorSettings = map[string]bool{
+	"users.colors": true,
+}
+
+// yamlLinePattern matches the line number prefix of YAML decoding errors.
+var yamlLinePattern = regexp.MustCompile(`^line \d+: `)
+
+// Check loads a configuration file strictly and reports every problem found,
+// rather than the first one a run would stop at: settings that are not
+// recognized or have the wrong type, everything Validate rejects, malformed
+// colors, opacities and zoom levels out of range, and input files that do not
+// exist. Each profile is checked with its settings merged over the base ones;
+// its problems are reported once, after those of the base settings.
+//
+// @function Check
+// @description Diagnoses a configuration file completely
+// @param filename string Path to YAML or JSON configuration file
+// @return []error One error per problem, nil for a sound file
+// @note Environment variables such as ${GOOGLE_MAPS_API_KEY} are not resolved,
+// so a file can be checked where its secrets are not set
+// @example for _, problem := range config.Check("config.yaml") { fmt.Println(problem) }
+func Check(filename string) []error {
+	data, err := readFile(filename)
+	if err != nil {
+		return []error{err}
+	}
+
+	// The base settings are decoded from the file as written, so that their
+	// problems keep the file's line numbers
+	var file struct {
+		Config   `yaml:",inline"`
+		Profiles map[string]yaml.MapSlice `yaml:"profiles"`
+	}
+	problems, err := decodeStrict(data, &file, IsJSON(filename))
+	if err != nil {
+		return []error{err}
+	}
+	problems = append(problems, configProblems(file.Config)...)
+
+	seen := make(map[string]bool, len(problems))
+	for _, problem := range problems {
+		seen[problem.Error()] = true
+	}
+	profiles := make([]string, 0, len(file.Profiles))
+	for profile := range file.Profiles {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	for _, profile := range profiles {
+		merged, err := applyProfile(data, profile)
+		if err != nil {
+			problems = append(problems, err)
+			continue
+		}
+		var cfg Config
+		profileProblems, err := decodeStrict(merged, &cfg, true)
+		if err != nil {
+			problems = append(problems, fmt.Errorf("profile %s: %w", profile, err))
+			continue
+		}
+		for _, problem := range append(profileProblems, configProblems(cfg)...) {
+			if !seen[problem.Error()] {
+				problems = append(problems, fmt.Errorf("profile %s: %w", profile, problem))
+			}
+		}
+	}
+	return problems
+}
+
+// decodeStrict decodes data strictly into out, returning the settings that
+// were not recognized or had the wrong type as problems; strict decoding
+// still fills in every setting it understood. Line numbers are dropped when
+// they do not refer to the file as written, such as for converted JSON.
+func decodeStrict(data []byte, out any, dropLines bool) ([]error, error) {
+	err := yaml.UnmarshalStrict(data, out)
+	if err == nil {
+		return nil, nil
+	}
+	var typeErr *yaml.TypeError
+	if !errors.As(err, &typeErr) {
+		return nil, fmt.Errorf("cannot parse config file: %w", err)
+	}
+	problems := make([]error, 0, len(typeErr.Errors))
+	for _, message := range typeErr.Errors {
+		if dropLines {
+			message = yamlLinePattern.ReplaceAllString(message, "")
+		}
+		problems = append(problems, errors.New(message))
+	}
+	return problems, nil
+}
+
+// configProblems reports the problems of decoded settings: everything
+// Validate rejects, unusable colors, opacities and zoom levels, and missing
+// input files.
+func configProblems(cfg Config) []error {
+	problems := cfg.Problems()
+	problems = append(problems, settingProblems("", reflect.ValueOf(cfg))...)
+	for _, input := range []struct{ setting, path string }{
+		{"input.csv_file", cfg.Input.CSVFile},
+		{"compare.file", cfg.Compare.File},
+		{"processing.include_areas", cfg.Processing.IncludeAreas},
+		{"processing.exclude_areas", cfg.Processing.ExcludeAreas},
+		{"output.template", cfg.Output.Template},
+	} {
+		if input.path == "" {
+			continue
+		}
+		if _, err := os.Stat(input.path); err != nil {
+			problems = append(problems, fmt.Errorf("%s %s: %w", input.setting, input.path, unwrapPathError(err)))
+		}
+	}
+	return problems
+}
+
+// settingProblems walks the settings below v, named by their YAML path, and
+// reports colors, opacities, and zoom levels that the maps cannot use.
+func settingProblems(path string, v reflect.Value) []error {
+	var problems []error
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			problems = append(problems, settingProblems(path, v.Elem())...)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			key, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+			if key == "" || key == "-" {
+				continue
+			}
+			problems = append(problems, settingProblems(joinSetting(path, key), v.Field(i))...)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			problems = append(problems, settingProblems(fmt.Sprintf("%s[%d]", path, i), v.Index(i))...)
+		}
+	case reflect.Map:
+		if isColorSetting(path) && v.Type().Elem().Kind() == reflect.String {
+			keys := v.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+			for _, key := range keys {
+				problems = append(problems, colorProblem(path+"."+key.String(), v.MapIndex(key).String())...)
+			}
+		}
+	case reflect.String:
+		if isColorSetting(path) {
+			problems = append(problems, colorProblem(path, v.String())...)
+		}
+	case reflect.Float32, reflect.Float64:
+		if strings.HasSuffix(lastSetting(path), "opacity") && (v.Float() < 0 || v.Float() > 1) {
+			problems = append(problems, fmt.Errorf("%s must be between 0.0 and 1.0, got %g", path, v.Float()))
+		}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if strings.HasSuffix(lastSetting(path), "zoom") && (v.Int() < 0 || v.Int() > maxZoom) {
+			problems = append(problems, fmt.Errorf("%s must be a zoom level between 0 and %d, got %d", path, maxZoom, v.Int()))
+		}
+	}
+	return problems
+}
+
+// colorProblem reports a color setting that is set but not a CSS color.
+func colorProblem(setting, color string) []error {
+	if color == "" || colorPattern.MatchString(color) || colorNames[strings.ToLower(color)] {
+		return nil
+	}
+	return []error{fmt.Errorf("%s %q is not a color (use a hex code such as #FF0000, rgb(), or a color name)", setting, color)}
+}
+
+// isColorSetting reports whether the setting at path holds a CSS color or a map
+// of them, judged by its key.
+func isColorSetting(path string) bool {
+	if hexColorSettings[path] {
+		return false
+	}
+	key := lastSetting(path)
+	return key == "color" || key == "colors" || strings.HasSuffix(key, "_color")
+}
+
+// lastSetting returns the last key of a setting path.
+func lastSetting(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+// joinSetting appends a key to a setting path.
+func joinSetting(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// unwrapPathError drops the operation and path of a file error, which the
+// problem message names already.
+func unwrapPathError(err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
+}
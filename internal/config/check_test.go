@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "walk.csv")
+	if err := os.WriteFile(csvFile, []byte("timestamp,latitude,longitude\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		yaml string
+		want []string // Substrings of the expected problems, in order
+	}{
+		{
+			name: "sound",
+			yaml: `
+google_maps:
+  api_key: "${GOOGLE_MAPS_API_KEY}"
+input:
+  csv_file: "` + csvFile + `"
+output:
+  html_file: "map.html"
+path:
+  style:
+    color: "#FF0000"
+    opacity: 0.8
+users:
+  colors:
+    alice: "#0080FF"
+    bob: "#008080"
+`,
+		},
+		{
+			name: "every problem",
+			yaml: `
+google_maps:
+  api_key: "test-key"
+input:
+  csv_file: "` + filepath.Join(dir, "missing.csv") + `"
+  csv_fiel: "typo.csv"
+output:
+  html_file: "map.html"
+  overwrite: "always"
+map:
+  zoom_level: 12
+  initial_view:
+    zoom: 30
+path:
+  style:
+    color: "FF0000"
+    opacity: 1.5
+    gradient: ["blue", "#zz0000"]
+    day_ramp: ["notahex"]
+users:
+  colors:
+    alice: "#12345"
+heatmap:
+  opacity: "half"
+`,
+			want: []string{
+				"field csv_fiel not found",
+				"field zoom_level not found",
+				"cannot unmarshal !!str `half`",
+				`invalid path gradient: "blue" is not a hex color`,
+				`invalid path gradient: "#zz0000" is not a hex color`,
+				`invalid path day ramp: "notahex" is not a hex color`,
+				`invalid color for user alice: "#12345" is not a hex color`,
+				"unknown output overwrite policy",
+				"map.initial_view.zoom must be a zoom level between 0 and 22, got 30",
+				`path.style.color "FF0000" is not a color`,
+				"path.style.opacity must be between 0.0 and 1.0, got 1.5",
+				"input.csv_file " + filepath.Join(dir, "missing.csv") + ": no such file",
+			},
+		},
+		{
+			name: "profiles",
+			yaml: `
+google_maps:
+  api_key: "test-key"
+output:
+  html_file: "map.html"
+  overwrite: "always"
+profiles:
+  hiking:
+    path:
+      style:
+        color: "blu"
+  driving:
+    map:
+      zom: 3
+`,
+			want: []string{
+				"input CSV file is required",
+				"unknown output overwrite policy",
+				"profile driving: field zom not found",
+				`profile hiking: path.style.color "blu" is not a color`,
+			},
+		},
+		{
+			name: "syntax error",
+			yaml: "input: [unclosed\n",
+			want: []string{"cannot parse config file"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, "config.yaml")
+			if err := os.WriteFile(file, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			problems := Check(file)
+			if len(problems) != len(tt.want) {
+				t.Fatalf("Check() = %d problems %v, want %d", len(problems), problems, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(problems[i].Error(), want) {
+					t.Errorf("Check() problem %d = %q, want it to mention %q", i, problems[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckExampleConfig(t *testing.T) {
+	// Only the sample input path is relative to the repository root
+	for _, problem := range Check(filepath.Join("..", "..", "config.yaml")) {
+		if !strings.HasPrefix(problem.Error(), "input.csv_file ") {
+			t.Errorf("Check(config.yaml) problem: %v", problem)
+		}
+	}
+}
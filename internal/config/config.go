@@ -2,22 +2,30 @@
 //
 // @title Configuration Management Package
 // @version 1.0
-// @description Handles YAML configuration loading, parsing, and validation
+// @description Handles YAML and JSON configuration loading, parsing, and validation
 // @description Manages GPS processing settings and map generation options
 // @description Supports environment variable substitution and validation
 //
 // Features:
 // - YAML configuration file parsing
-// - Environment variable substitution
+// - JSON configuration files with the same setting names
+// - Environment variable substitution in every string setting
+// - GEOCHRONO_* environment variables overriding any setting
+// - Named profiles overriding the base settings of one file
 // - Comprehensive validation
 // - Default value application
 // - Structured configuration hierarchy
 package config
 
 import (
+	"bytes"
 	"fmt"
 	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.yaml.in/yaml/v2"
 )
@@ -33,9 +41,17 @@ import (
 // @property Map MapConfig Map display and styling options
 // @property Markers MarkersConfig GPS point marker customization
 // @property Path PathConfig Path/trail visualization settings
+// @property Users UsersConfig Per-user track colors for multi-user datasets
 // @property InfoWindows InfoWindowsConfig Popup window configuration
+// @property Heatmap HeatmapConfig Density heatmap rendering options
+// @property Geofences GeofencesConfig Named geofence definitions
+// @property Stops StopsConfig Stop detection and stop markers
+// @property Weather WeatherConfig Historical weather annotations
+// @property Statistics StatisticsConfig Route statistics and analysis options
 // @property Processing ProcessingConfig Data processing and filtering options
 // @property Logging LoggingConfig Debug and logging settings
+// @property Hooks HooksConfig Commands run before reading the input and after writing the outputs
+// @property Server ServerConfig Serve mode track API settings
 type Config struct {
 	GoogleMaps  GoogleMapsConfig  `yaml:"google_maps"`  // @field GoogleMaps Google Maps API configuration
 	Input       InputConfig       `yaml:"input"`        // @field Input Input file and format settings
@@ -43,9 +59,20 @@ type Config struct {
 	Map         MapConfig         `yaml:"map"`          // @field Map Map display and styling options
 	Markers     MarkersConfig     `yaml:"markers"`      // @field Markers GPS point marker configuration
 	Path        PathConfig        `yaml:"path"`         // @field Path Path/trail visualization settings
+	Users       UsersConfig       `yaml:"users"`        // @field Users Per-user track colors
 	InfoWindows InfoWindowsConfig `yaml:"info_windows"` // @field InfoWindows Popup window configuration
+	Heatmap     HeatmapConfig     `yaml:"heatmap"`      // @field Heatmap Density heatmap settings
+	Geofences   GeofencesConfig   `yaml:"geofences"`    // @field Geofences Geofence definitions
+	Proximity   ProximityConfig   `yaml:"proximity"`    // @field Proximity Multi-user encounter detection
+	Stops       StopsConfig       `yaml:"stops"`        // @field Stops Stop detection and stop markers
+	Weather     WeatherConfig     `yaml:"weather"`      // @field Weather Historical weather annotations
+	Statistics  StatisticsConfig  `yaml:"statistics"`   // @field Statistics Route statistics settings
+	Compare     CompareConfig     `yaml:"compare"`      // @field Compare Reference route comparison
+	Privacy     PrivacyConfig     `yaml:"privacy"`      // @field Privacy Third-party request restrictions
 	Processing  ProcessingConfig  `yaml:"processing"`   // @field Processing Data processing options
 	Logging     LoggingConfig     `yaml:"logging"`      // @field Logging Logging and debug settings
+	Hooks       HooksConfig       `yaml:"hooks"`        // @field Hooks Commands run around a run
+	Server      ServerConfig      `yaml:"server"`       // @field Server Serve mode track API settings
 }
 
 // GoogleMapsConfig holds Google Maps API configuration settings.
@@ -60,6 +87,7 @@ type GoogleMapsConfig struct {
 // This defines where to find GPS data and how to interpret it.
 type InputConfig struct {
 	CSVFile   string          `yaml:"csv_file"`   // Path to the input CSV file
+	Format    string          `yaml:"format"`     // Input format, such as csv or gpx (empty to choose by file extension)
 	CSVFormat CSVFormatConfig `yaml:"csv_format"` // CSV parsing configuration
 }
 
@@ -72,6 +100,8 @@ type CSVFormatConfig struct {
 	TitleColumn       string `yaml:"title_column"`       // Name of title/name column (optional)
 	DescriptionColumn string `yaml:"description_column"` // Name of description column (optional)
 	CategoryColumn    string `yaml:"category_column"`    // Name of category column (optional)
+	UserColumn        string `yaml:"user_column"`        // Name of user/person column for multi-user tracks (optional)
+	ElevationColumn   string `yaml:"elevation_column"`   // Name of elevation/altitude column in meters (optional)
 	HasHeader         bool   `yaml:"has_header"`         // Whether CSV file has a header row
 	Delimiter         string `yaml:"delimiter"`          // Field delimiter (default: comma)
 	SkipRows          int    `yaml:"skip_rows"`          // Number of rows to skip at beginning
@@ -80,21 +110,115 @@ type CSVFormatConfig struct {
 // OutputConfig holds output file configuration and export options.
 // This controls where and how the generated map and related files are saved.
 type OutputConfig struct {
-	HTMLFile  string `yaml:"html_file"`  // Path to output HTML file
-	Debug     bool   `yaml:"debug"`      // Enable debug output in generated files
-	ExportKML bool   `yaml:"export_kml"` // Whether to export KML file
-	KMLFile   string `yaml:"kml_file"`   // Path to output KML file (if enabled)
+	HTMLFile    string            `yaml:"html_file"`    // Path to output HTML file
+	Debug       bool              `yaml:"debug"`        // Enable debug output in generated files
+	ExportKML   bool              `yaml:"export_kml"`   // Whether to export KML file
+	KMLFile     string            `yaml:"kml_file"`     // Path to output KML file (if enabled)
+	StatsFile   string            `yaml:"stats_file"`   // Path to output statistics JSON file (optional)
+	GeoJSONFile string            `yaml:"geojson_file"` // Path to output GeoJSON track file (optional)
+	GPXFile     string            `yaml:"gpx_file"`     // Path to output GPX track file (optional)
+	XLSXFile    string            `yaml:"xlsx_file"`    // Path to output Excel report with points and summary sheets (optional)
+	Formats     []string          `yaml:"formats"`      // Output formats written per run (html, kml, geojson, gpx, stats, image, xlsx)
+	Downloads   bool              `yaml:"downloads"`    // Download buttons on the map page for the GPX, KML, and GeoJSON files written with it
+	DailyFile   string            `yaml:"daily_file"`   // Path to per-day summaries (.csv for CSV, otherwise JSON; optional)
+	WeeklyFile  string            `yaml:"weekly_file"`  // Path to per-week summaries (.csv for CSV, otherwise JSON; optional)
+	Image       ImageConfig       `yaml:"image"`        // Static map image export
+	Template    string            `yaml:"template"`     // Custom page template file, or directory with map.html (empty for built-in)
+	Partials    map[string]string `yaml:"partials"`     // Files replacing named page blocks (head, header, stats, legend, footer, scripts)
+	Widget      bool              `yaml:"widget"`       // Map-only page for iframes, plus an embed snippet script
+	PagesDir    string            `yaml:"pages_dir"`    // Directory for one map page per day plus index.html (optional)
+	Timestamp   bool              `yaml:"timestamp"`    // Show the generation time in the page footer (honors SOURCE_DATE_EPOCH)
+	Minify      bool              `yaml:"minify"`       // Strip whitespace and comments and compact the embedded points
+	Overwrite   string            `yaml:"overwrite"`    // Existing output files: refuse (default), force, or backup
+	// Single-file archival output
+	SelfContained  bool   `yaml:"self_contained"`  // Inline every script and stylesheet; fail on other external references
+	LeafletScript  string `yaml:"leaflet_script"`  // Local copy of leaflet.js inlined in self-contained and strict privacy mode
+	LeafletStyle   string `yaml:"leaflet_style"`   // Local copy of leaflet.css inlined in self-contained and strict privacy mode
+	MapLibreScript string `yaml:"maplibre_script"` // Local copy of maplibre-gl.js inlined in self-contained and strict privacy mode
+	MapLibreStyle  string `yaml:"maplibre_style"`  // Local copy of maplibre-gl.css inlined in self-contained and strict privacy mode
+}
+
+// ImageConfig holds settings for exporting the track as a static map image,
+// for reports and documents where an interactive HTML map cannot be embedded.
+type ImageConfig struct {
+	File     string `yaml:"file"`     // Path to output image (.png, .jpg, or .jpeg; empty to disable)
+	Width    int    `yaml:"width"`    // Image width in pixels
+	Height   int    `yaml:"height"`   // Image height in pixels
+	Provider string `yaml:"provider"` // Renderer (local: track only, offline; google: Static Maps API background)
 }
 
 // MapConfig holds map display and presentation configuration.
 // This controls the overall appearance and behavior of the generated map.
 type MapConfig struct {
-	Title         string            `yaml:"title"`           // Map title displayed in browser
-	Width         string            `yaml:"width"`           // Map width (CSS units)
-	Height        string            `yaml:"height"`          // Map height (CSS units)
-	InitialView   InitialViewConfig `yaml:"initial_view"`    // Initial map view settings
-	AutoFitBounds bool              `yaml:"auto_fit_bounds"` // Auto-fit map to GPS points
-	Controls      ControlsConfig    `yaml:"controls"`        // Map control visibility
+	Provider        string             `yaml:"provider"`         // Map provider (google, leaflet, maplibre, cesium)
+	TileURL         string             `yaml:"tile_url"`         // Tile URL template for the leaflet provider ("none" for no tiles)
+	Attribution     string             `yaml:"attribution"`      // Attribution text required by the tile provider
+	Title           string             `yaml:"title"`            // Map title displayed in browser
+	Width           string             `yaml:"width"`            // Map width (CSS units)
+	Height          string             `yaml:"height"`           // Map height (CSS units)
+	InitialView     InitialViewConfig  `yaml:"initial_view"`     // Initial map view settings
+	AutoFitBounds   bool               `yaml:"auto_fit_bounds"`  // Auto-fit map to GPS points
+	Controls        ControlsConfig     `yaml:"controls"`         // Map control visibility
+	RenderMode      string             `yaml:"render_mode"`      // Rendering mode (trail, heatmap, combined)
+	RestrictBounds  bool               `yaml:"restrict_bounds"`  // Prevent panning far outside the track
+	RestrictPadding float64            `yaml:"restrict_padding"` // Padding around the track as a fraction of its extent
+	LayerOrder      []string           `yaml:"layer_order"`      // Overlay drawing order from bottom to top
+	CenterMethod    string             `yaml:"center_method"`    // Auto-center calculation (mean, spherical, median)
+	Fallback        FallbackConfig     `yaml:"fallback"`         // Backup provider if Google Maps fails to load
+	MapLibre        MapLibreConfig     `yaml:"maplibre"`         // Vector map settings for the maplibre provider
+	Cesium          CesiumConfig       `yaml:"cesium"`           // 3D globe settings for the cesium provider
+	StyleJSON       string             `yaml:"style_json"`       // Google Maps styles: "dark", an inline JSON array, or a JSON file path
+	Print           PrintConfig        `yaml:"print"`            // Paper size and orientation of printed maps
+	Language        string             `yaml:"language"`         // Page language (en, es, de, fr); also sets date and number formats
+	OfflineTiles    OfflineTilesConfig `yaml:"offline_tiles"`    // Tiles bundled into the page for maps without network access
+	QRCode          QRCodeConfig       `yaml:"qr_code"`          // Header QR code linking to the hosted map
+}
+
+// QRCodeConfig holds the QR code shown in the page header, so a printed map can be
+// opened on a phone.
+type QRCodeConfig struct {
+	Enabled bool   `yaml:"enabled"` // Show the QR code in the page header
+	URL     string `yaml:"url"`     // URL of the hosted interactive map encoded in the code
+	Size    int    `yaml:"size"`    // Width and height in pixels (default 96)
+}
+
+// OfflineTilesConfig holds the tiles bundled into leaflet maps for air-gapped use.
+// Tiles are fetched from map.tile_url, which may also be a path template of a
+// directory of pre-fetched tiles.
+type OfflineTilesConfig struct {
+	Enabled  bool `yaml:"enabled"`   // Embed the tiles covering the track in the page
+	MinZoom  *int `yaml:"min_zoom"`  // Lowest zoom level to bundle (default 10; 0 bundles the whole world)
+	MaxZoom  int  `yaml:"max_zoom"`  // Highest zoom level to bundle (default 16)
+	MaxTiles int  `yaml:"max_tiles"` // Largest number of tiles to bundle (default 500)
+}
+
+// PrintConfig holds the paper layout used when the map page is printed.
+type PrintConfig struct {
+	PaperSize   string `yaml:"paper_size"`  // Paper size (a4 or letter, default a4)
+	Orientation string `yaml:"orientation"` // Page orientation (landscape or portrait, default landscape)
+}
+
+// MapLibreConfig holds settings for the MapLibre GL vector map provider.
+type MapLibreConfig struct {
+	Style       string  `yaml:"style"`        // Style JSON URL (mapbox:// styles need access_token)
+	AccessToken string  `yaml:"access_token"` // Mapbox access token for mapbox:// styles and tiles
+	Pitch       float64 `yaml:"pitch"`        // Initial camera tilt in degrees (0-85)
+	Bearing     float64 `yaml:"bearing"`      // Initial camera rotation in degrees clockwise from north
+}
+
+// CesiumConfig holds settings for the CesiumJS 3D globe provider.
+type CesiumConfig struct {
+	IonToken string `yaml:"ion_token"` // Cesium ion access token (only needed for terrain)
+	Terrain  bool   `yaml:"terrain"`   // Show Cesium World Terrain instead of a smooth globe
+}
+
+// FallbackConfig holds the backup map provider used when Google Maps fails at runtime
+// (invalid API key, exceeded quota, blocked script).
+type FallbackConfig struct {
+	Provider       string `yaml:"provider"`        // Fallback provider ("" for none, leaflet)
+	TileURL        string `yaml:"tile_url"`        // Tile URL template for the fallback map
+	Attribution    string `yaml:"attribution"`     // Attribution text required by the tile provider
+	TimeoutSeconds int    `yaml:"timeout_seconds"` // Seconds to wait for Google Maps before falling back
 }
 
 // InitialViewConfig holds initial map view and positioning settings.
@@ -102,7 +226,7 @@ type MapConfig struct {
 type InitialViewConfig struct {
 	Center  CenterConfig `yaml:"center"`   // Initial center coordinates
 	Zoom    *int         `yaml:"zoom"`     // Initial zoom level (1-20)
-	MapType string       `yaml:"map_type"` // Map type (roadmap, satellite, hybrid, terrain)
+	MapType string       `yaml:"map_type"` // Google Maps base map: roadmap (default), satellite, hybrid, or terrain
 }
 
 // CenterConfig holds geographical center coordinates for map positioning.
@@ -120,6 +244,13 @@ type ControlsConfig struct {
 	FullscreenControl bool `yaml:"fullscreen_control"`  // Show fullscreen button
 	MapTypeControl    bool `yaml:"map_type_control"`    // Show map type selector
 	ScaleControl      bool `yaml:"scale_control"`       // Show map scale indicator
+	TimeSlider        bool `yaml:"time_slider"`         // Show a slider limiting markers and the path to a time window
+	LayerToggles      bool `yaml:"layer_toggles"`       // Show checkboxes hiding individual map layers and users
+	Sidebar           bool `yaml:"sidebar"`             // Show a collapsible list of points beside the map
+	Search            bool `yaml:"search"`              // Show a box filtering markers by title, description, or category
+	MeasureTool       bool `yaml:"measure_tool"`        // Show a tool measuring distances between points clicked on the map
+	Permalink         bool `yaml:"permalink"`           // Keep the view, hidden layers, and time window in the page URL for sharing
+	Print             bool `yaml:"print"`               // Show a button printing the map in the print layout
 }
 
 // MarkersConfig holds configuration for GPS point markers on the map.
@@ -129,6 +260,7 @@ type MarkersConfig struct {
 	Start      MarkerStyleConfig `yaml:"start"`      // Special style for start point
 	End        MarkerStyleConfig `yaml:"end"`        // Special style for end point
 	Categories map[string]string `yaml:"categories"` // Category-specific marker colors/styles
+	Spiderfy   bool              `yaml:"spiderfy"`   // Expand co-located markers on click
 }
 
 // MarkerStyleConfig holds styling configuration for individual markers.
@@ -181,25 +313,42 @@ type FontConfig struct {
 // PathConfig holds configuration for the GPS trail/path visualization.
 // This controls how the chronological path between GPS points is displayed.
 type PathConfig struct {
-	Enabled   bool            `yaml:"enabled"`   // Whether to show connecting path
-	Style     PathStyleConfig `yaml:"style"`     // Path visual styling
-	Animation AnimationConfig `yaml:"animation"` // Path animation settings
+	Enabled    bool             `yaml:"enabled"`    // Whether to show connecting path
+	Style      PathStyleConfig  `yaml:"style"`      // Path visual styling
+	Animation  AnimationConfig  `yaml:"animation"`  // Path animation settings
+	Milestones MilestonesConfig `yaml:"milestones"` // Distance markers along the path
+}
+
+// MilestonesConfig holds settings for the distance milestone markers placed along
+// the path, such as "1 km", "2 km", and so on.
+type MilestonesConfig struct {
+	Enabled  bool    `yaml:"enabled"`  // Mark every interval of distance along the path
+	Interval float64 `yaml:"interval"` // Distance between milestones in statistics.distance_units (default 1 km or 1 mi)
 }
 
 // PathStyleConfig holds visual styling for the GPS path.
 // This defines the appearance of the line connecting GPS points.
 type PathStyleConfig struct {
-	Color         string  `yaml:"color"`          // Path line color (hex code)
-	Opacity       float64 `yaml:"opacity"`        // Path transparency (0.0-1.0)
-	Weight        int     `yaml:"weight"`         // Path line thickness in pixels
-	StrokePattern string  `yaml:"stroke_pattern"` // Line pattern (solid, dashed, etc.)
+	Color         string   `yaml:"color"`          // Path line color (hex code)
+	Opacity       float64  `yaml:"opacity"`        // Path transparency (0.0-1.0)
+	Weight        int      `yaml:"weight"`         // Path line thickness in pixels
+	StrokePattern string   `yaml:"stroke_pattern"` // Line pattern: solid (default), dashed, or dotted
+	ColorBy       string   `yaml:"color_by"`       // Segment coloring: "" for a single color, speed, or day
+	Gradient      []string `yaml:"gradient"`       // Hex colors from slow to fast for color_by speed
+	DayRamp       []string `yaml:"day_ramp"`       // Hex colors from the first to the last day for color_by day
+}
+
+// UsersConfig holds settings for the users of a multi-user dataset, whose points
+// come from the input's user column.
+type UsersConfig struct {
+	Colors map[string]string `yaml:"colors"` // Hex path and marker color by user name (others take palette colors)
 }
 
 // AnimationConfig holds configuration for path animation effects.
 // This controls how the GPS trail is animated to show movement over time.
 type AnimationConfig struct {
-	Enabled             bool `yaml:"enabled"`               // Enable path animation
-	Speed               int  `yaml:"speed"`                 // Animation speed (1-10)
+	Enabled             bool `yaml:"enabled"`               // Add a play/pause playback control
+	Speed               int  `yaml:"speed"`                 // Playback speed (1-10, slowest to fastest)
 	ShowDirectionArrows bool `yaml:"show_direction_arrows"` // Show movement direction
 }
 
@@ -212,53 +361,215 @@ type InfoWindowsConfig struct {
 	MaxWidth      int    `yaml:"max_width"`       // Maximum popup width in pixels
 }
 
+// HeatmapConfig holds configuration for density heatmap rendering.
+// This controls how points are aggregated and drawn when map.render_mode is "heatmap" or "combined".
+type HeatmapConfig struct {
+	CellSize float64 `yaml:"cell_size"` // Grid cell size in meters (0 = one weight per point)
+	Radius   int     `yaml:"radius"`    // Heatmap point radius in pixels
+	Opacity  float64 `yaml:"opacity"`   // Heatmap layer opacity (0.0-1.0)
+}
+
+// GeofencesConfig holds named geofence definitions and display options.
+// Entry and exit events are computed for every fence the track crosses.
+type GeofencesConfig struct {
+	ShowBoundaries bool          `yaml:"show_boundaries"` // Draw labelled fence boundaries on the map
+	Fences         []FenceConfig `yaml:"fences"`          // Geofence definitions
+}
+
+// FenceConfig holds a single geofence definition.
+// A fence is either a circle (center and radius) or a polygon.
+type FenceConfig struct {
+	Name    string             `yaml:"name"`    // Display name for the fence
+	Center  *CoordinateConfig  `yaml:"center"`  // Circle center (circular fences)
+	Radius  float64            `yaml:"radius"`  // Circle radius in meters (circular fences)
+	Polygon []CoordinateConfig `yaml:"polygon"` // Polygon vertices (polygon fences)
+}
+
+// CoordinateConfig holds a single latitude/longitude pair.
+// Used for geofence centers and polygon vertices.
+type CoordinateConfig struct {
+	Latitude  float64 `yaml:"latitude"`  // Latitude (-90 to 90)
+	Longitude float64 `yaml:"longitude"` // Longitude (-180 to 180)
+}
+
+// ProximityConfig holds settings for detecting encounters between users.
+// Encounters are computed when the input has a user column with at least two users.
+type ProximityConfig struct {
+	Enabled       bool    `yaml:"enabled"`         // Detect and highlight encounters between users
+	Radius        float64 `yaml:"radius"`          // Distance in meters within which users count as together (default 50)
+	MaxGapSeconds int     `yaml:"max_gap_seconds"` // Longest gap between GPS fixes to interpolate across (default 300)
+	Color         string  `yaml:"color"`           // Highlight color for encounter segments and markers
+	MeetingPoint  bool    `yaml:"meeting_point"`   // Suggest a meeting point for the users
+	MeetingTime   string  `yaml:"meeting_time"`    // RFC 3339 time of the user positions (empty for the latest fix)
+}
+
+// StopsConfig holds settings for detecting stops, the places where the track stayed
+// within a small radius for a while, and drawing them as stop markers.
+type StopsConfig struct {
+	Enabled            bool    `yaml:"enabled"`              // Detect stops and draw stop markers
+	Radius             float64 `yaml:"radius"`               // Distance in meters the track may wander while stopped (default 50)
+	MinDurationSeconds int     `yaml:"min_duration_seconds"` // Shortest stay counted as a stop (default 300)
+	Color              string  `yaml:"color"`                // Stop marker color
+}
+
+// WeatherConfig holds settings for annotating the map with the historical weather
+// during the track, looked up from an Open-Meteo compatible archive API.
+type WeatherConfig struct {
+	Enabled bool   `yaml:"enabled"` // Look up the hourly weather for the track's dates and location
+	URL     string `yaml:"url"`     // Archive API endpoint (default: the public Open-Meteo archive)
+}
+
+// StatisticsConfig holds configuration for route statistics and analysis.
+// This controls which summary values are calculated and displayed for the track.
+type StatisticsConfig struct {
+	Enabled               bool    `yaml:"enabled"`                 // Show statistics panel
+	ShowDistance          bool    `yaml:"show_distance"`           // Display total distance traveled
+	ShowDuration          bool    `yaml:"show_duration"`           // Display total time duration
+	ShowSpeed             bool    `yaml:"show_speed"`              // Display average speeds
+	ShowElevation         bool    `yaml:"show_elevation"`          // Display elevation profile
+	ShowSplits            bool    `yaml:"show_splits"`             // Display per-km/mile splits table
+	ShowDaily             bool    `yaml:"show_daily"`              // Display per-day summary table for multi-day tracks
+	ShowWeekly            bool    `yaml:"show_weekly"`             // Display per-week summary table for multi-week tracks
+	DistanceUnits         string  `yaml:"distance_units"`          // Distance units (metric, imperial)
+	StoppedSpeedThreshold float64 `yaml:"stopped_speed_threshold"` // Speed below which the track is stopped (km/h)
+	DistanceMethod        string  `yaml:"distance_method"`         // Distance formula (haversine, vincenty)
+	LoopThreshold         float64 `yaml:"loop_threshold"`          // Max start-to-end distance (m) for a loop (default 100)
+}
+
+// CompareConfig holds settings for comparing the track against a reference route.
+// The reference (for example a planned GPX route) is drawn in its own color and
+// off-route distances are added to the statistics.
+type CompareConfig struct {
+	File              string  `yaml:"file"`                // Reference route file (.gpx or .csv; empty to disable)
+	Color             string  `yaml:"color"`               // Line color for the reference route
+	StrokePattern     string  `yaml:"stroke_pattern"`      // Line pattern for the reference route: solid (default), dashed, or dotted
+	OffRouteThreshold float64 `yaml:"off_route_threshold"` // Distance in meters beyond which a point is off-route (default 50)
+}
+
+// PrivacyConfig holds settings for privacy-conscious publishing.
+// Strict mode guarantees the generated page contacts no hosts besides the map provider.
+type PrivacyConfig struct {
+	Strict    bool   `yaml:"strict"`    // Fail generation if the page references third-party hosts
+	Statement string `yaml:"statement"` // Privacy statement shown in the page footer (default text if empty)
+}
+
 // ProcessingConfig holds configuration for GPS data processing and filtering.
 // This controls how raw GPS data is cleaned and prepared for visualization.
 type ProcessingConfig struct {
-	RemoveDuplicates  bool     `yaml:"remove_duplicates"`   // Remove duplicate GPS points
-	MinDistanceFilter float64  `yaml:"min_distance_filter"` // Minimum distance between points (meters)
-	SmoothPath        bool     `yaml:"smooth_path"`         // Apply path smoothing algorithms
-	MaxSpeedFilter    float64  `yaml:"max_speed_filter"`    // Maximum realistic speed (km/h)
-	Timezone          string   `yaml:"timezone"`            // Timezone for timestamp processing
-	TimestampFormats  []string `yaml:"timestamp_formats"`   // Supported timestamp formats
+	RemoveDuplicates  bool       `yaml:"remove_duplicates"`   // Remove duplicate GPS points
+	MinDistanceFilter float64    `yaml:"min_distance_filter"` // Minimum distance between points (meters)
+	SmoothPath        bool       `yaml:"smooth_path"`         // Apply path smoothing algorithms
+	SmoothWindow      int        `yaml:"smooth_window"`       // Points averaged by path smoothing (odd number)
+	SimplifyTolerance float64    `yaml:"simplify_tolerance"`  // Maximum deviation when simplifying the path (meters, 0 to disable)
+	MaxSpeedFilter    float64    `yaml:"max_speed_filter"`    // Maximum realistic speed (km/h)
+	Timezone          string     `yaml:"timezone"`            // Timezone for timestamp processing
+	TimestampFormats  []string   `yaml:"timestamp_formats"`   // Supported timestamp formats
+	IncludeAreas      string     `yaml:"include_areas"`       // GeoJSON file of polygons points must fall within
+	ExcludeAreas      string     `yaml:"exclude_areas"`       // GeoJSON file of polygons whose points are dropped
+	SnapToRoads       SnapConfig `yaml:"snap_to_roads"`       // Road-network snapping before rendering
+	Pipeline          []string   `yaml:"pipeline"`            // Filter stages in execution order (empty for the default order)
+}
+
+// SnapConfig holds settings for snapping tracks onto the road network.
+// Snapping sends the track coordinates to the configured provider's web service.
+type SnapConfig struct {
+	Provider    string `yaml:"provider"`    // Snapping provider ("" to disable, google, osrm)
+	APIKey      string `yaml:"api_key"`     // Provider API key (defaults to google_maps.api_key)
+	Interpolate bool   `yaml:"interpolate"` // Add points so the path follows road curves
+	URL         string `yaml:"url"`         // OSRM server URL (self-hosted match service)
+	Profile     string `yaml:"profile"`     // OSRM routing profile (driving, walking, cycling)
 }
 
 // LoggingConfig holds configuration for application logging and debugging.
 // This controls how the application reports its operations and any issues.
 type LoggingConfig struct {
-	Level   string `yaml:"level"`   // Log level (debug, info, warn, error)
-	File    string `yaml:"file"`    // Log file path (empty for stdout)
+	Level   string `yaml:"level"`   // Log level (trace, debug, info, warn, error)
+	File    string `yaml:"file"`    // Log file path, written in addition to the console (empty for console only)
 	Verbose bool   `yaml:"verbose"` // Enable verbose output
+	Format  string `yaml:"format"`  // Record format: text (default, readable lines) or json (one object per line)
+}
+
+// HooksConfig holds shell commands run around a run, such as fetching the input
+// from a device first or publishing the map afterwards.
+type HooksConfig struct {
+	PreIngest      []string `yaml:"pre_ingest"`      // Commands run before the input is read
+	PostGenerate   []string `yaml:"post_generate"`   // Commands run after every output is written
+	TimeoutSeconds int      `yaml:"timeout_seconds"` // Seconds each command may run (0 for no limit)
+}
+
+// MinTokenLength is the shortest accepted server user token.
+const MinTokenLength = 16
+
+// userNamePattern matches server user names, which appear in URL paths.
+var userNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// ServerConfig holds the settings of the track API offered by serve mode.
+type ServerConfig struct {
+	Storage       StorageConfig      `yaml:"storage"`         // Where uploaded tracks are kept
+	MaxUploadSize int64              `yaml:"max_upload_size"` // Largest accepted upload in bytes (0 for the 32 MiB default)
+	Users         []ServerUserConfig `yaml:"users"`           // API accounts (empty for an open API)
+}
+
+// ServerUserConfig is an account of the track API, identified by its token.
+type ServerUserConfig struct {
+	Name  string `yaml:"name"`  // User name, also the path of the user's page /users/{name}
+	Token string `yaml:"token"` // Secret API token (supports ${VAR} substitution)
 }
 
-// Load reads and parses a YAML configuration file from the specified path.
+// StorageConfig selects the backend keeping uploaded tracks.
+type StorageConfig struct {
+	Backend string `yaml:"backend"` // Storage backend (memory, dir, sqlite, postgres; default memory)
+	Dir     string `yaml:"dir"`     // Directory of the dir backend
+	DSN     string `yaml:"dsn"`     // Database file or connection URL of the sqlite and postgres backends
+	Driver  string `yaml:"driver"`  // database/sql driver name (default sqlite or pgx)
+	PostGIS bool   `yaml:"postgis"` // Add a PostGIS geometry column to the points of the postgres backend
+}
+
+// Load reads and parses a YAML or JSON configuration file from the specified
+// path. Files ending in .json are read as JSON, with the same setting names.
 //
 // @function Load
-// @description Loads and parses YAML configuration file into Config struct
-// @param filename string Path to YAML configuration file
+// @description Loads and parses YAML or JSON configuration file into Config struct
+// @param filename string Path to YAML or JSON configuration file
 // @return *Config Parsed configuration structure with all settings
-// @return error Error if file cannot be read or YAML is invalid
+// @return error Error if file cannot be read or YAML/JSON is invalid
 // @throws FileNotFoundError When config file doesn't exist
-// @throws ParseError When YAML syntax is invalid
+// @throws ParseError When YAML/JSON syntax is invalid
 // @example config, err := Load("config.yaml")
 func Load(filename string) (*Config, error) {
-	// Open the configuration file
-	file, err := os.Open(filename)
+	// Read the configuration file, converting JSON to YAML
+	data, err := readFile(filename)
 	if err != nil {
-		return nil, fmt.Errorf("cannot open config file %s: %w", filename, err)
+		return nil, err
 	}
-	defer file.Close()
+	return Parse(data)
+}
 
-	// Parse YAML content into Config struct
+// Parse parses YAML configuration contents, such as the embedded example
+// configuration.
+func Parse(data []byte) (*Config, error) {
 	var config Config
-	decoder := yaml.NewDecoder(file)
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
 	if err := decoder.Decode(&config); err != nil {
 		return nil, fmt.Errorf("cannot parse config file: %w", err)
 	}
-
 	return &config, nil
 }
 
+// readFile returns the contents of a configuration file as YAML.
+func readFile(filename string) ([]byte, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open config file %s: %w", filename, err)
+	}
+	if IsJSON(filename) {
+		if data, err = jsonToYAML(data); err != nil {
+			return nil, fmt.Errorf("cannot parse config file: %w", err)
+		}
+	}
+	return data, nil
+}
+
 // ResolveAPIKey resolves the Google Maps API key from environment variables
 // if the configuration uses environment variable placeholders.
 //
@@ -269,6 +580,9 @@ func Load(filename string) (*Config, error) {
 // @example API key "${GOOGLE_MAPS_KEY}" resolves to env var value
 func (c *Config) ResolveAPIKey() error {
 	if c.GoogleMaps.APIKey == "" {
+		if !c.RequiresAPIKey() {
+			return nil
+		}
 		return fmt.Errorf("google Maps API key is required (use 'DEMO' for demonstration)")
 	}
 
@@ -285,33 +599,307 @@ func (c *Config) ResolveAPIKey() error {
 		// Replace with actual environment variable value
 		if envValue := os.Getenv(envVar); envValue != "" {
 			c.GoogleMaps.APIKey = envValue
-		} else {
+		} else if c.RequiresAPIKey() {
 			return fmt.Errorf("environment variable %s is not set", envVar)
+		} else {
+			c.GoogleMaps.APIKey = ""
 		}
 	}
 
 	return nil
 }
 
+// RequiresAPIKey reports whether the configured map provider needs a Google Maps
+// API key. Only the default Google Maps provider does.
+func (c *Config) RequiresAPIKey() bool {
+	return c.Map.Provider == "" || c.Map.Provider == "google"
+}
+
+// ResolveTokens replaces server user tokens written as ${VAR} with the value
+// of the environment variable, so tokens need not be kept in the file.
+//
+// @method ResolveTokens
+// @description Resolves server user tokens from environment variables
+// @return error Error if a referenced environment variable is not set
+// @example Token "${ALICE_TOKEN}" resolves to env var value
+func (c *Config) ResolveTokens() error {
+	for i, user := range c.Server.Users {
+		if !strings.HasPrefix(user.Token, "${") || !strings.HasSuffix(user.Token, "}") {
+			continue
+		}
+		envVar := strings.TrimSuffix(strings.TrimPrefix(user.Token, "${"), "}")
+		envValue := os.Getenv(envVar)
+		if envValue == "" {
+			return fmt.Errorf("environment variable %s for the token of server user %s is not set", envVar, user.Name)
+		}
+		c.Server.Users[i].Token = envValue
+	}
+	return nil
+}
+
 // Validate performs comprehensive validation on the configuration to ensure
 // all required fields are present and have valid values.
 // It checks for missing API keys, file paths, and other critical settings.
+// Only the first problem is returned; Problems lists them all.
 func (c *Config) Validate() error {
+	if problems := c.Problems(); len(problems) > 0 {
+		return problems[0]
+	}
+	return nil
+}
+
+// Problems returns every problem Validate checks for, in the order Validate
+// checks them, or nil for a valid configuration.
+//
+// @method Problems
+// @description Validates the whole configuration without stopping at the first problem
+// @return []error One error per invalid setting
+func (c *Config) Problems() []error {
+	var problems []error
+
+	// Validate the map provider
+	switch c.Map.Provider {
+	case "", "google", "leaflet", "maplibre", "cesium":
+	default:
+		problems = append(problems, fmt.Errorf("unknown map provider %q (use google, leaflet, maplibre, or cesium)", c.Map.Provider))
+	}
+
+	// Cesium World Terrain is served by Cesium ion
+	if c.Map.Cesium.Terrain && c.Map.Cesium.IonToken == "" {
+		problems = append(problems, fmt.Errorf("cesium terrain requires a Cesium ion access token (map.cesium.ion_token)"))
+	}
+
+	// Validate the MapLibre camera tilt
+	if c.Map.MapLibre.Pitch < 0 || c.Map.MapLibre.Pitch > 85 {
+		problems = append(problems, fmt.Errorf("MapLibre pitch must be between 0 and 85 degrees, got %g", c.Map.MapLibre.Pitch))
+	}
+
 	// Validate Google Maps API key (allow "DEMO" for demonstration purposes)
-	if c.GoogleMaps.APIKey == "" {
-		return fmt.Errorf("google Maps API key is required (use 'DEMO' for demonstration)")
+	if c.GoogleMaps.APIKey == "" && c.RequiresAPIKey() {
+		problems = append(problems, fmt.Errorf("google Maps API key is required (use 'DEMO' for demonstration)"))
 	}
 
 	// Validate input file path
 	if c.Input.CSVFile == "" {
-		return fmt.Errorf("input CSV file is required")
+		problems = append(problems, fmt.Errorf("input CSV file is required"))
 	}
 
 	// Validate output file path
 	if c.Output.HTMLFile == "" {
-		return fmt.Errorf("output HTML file is required")
+		problems = append(problems, fmt.Errorf("output HTML file is required"))
 	}
 
-	// All validation checks passed
-	return nil
+	// Validate the path segment coloring
+	switch c.Path.Style.ColorBy {
+	case "", "speed", "day":
+	default:
+		problems = append(problems, fmt.Errorf("unknown path color_by %q (use speed or day, or leave empty for a single color)", c.Path.Style.ColorBy))
+	}
+
+	// Validate the colors path segments and users are colored from, which are
+	// interpolated and compared and so must be hex codes
+	for _, color := range c.Path.Style.Gradient {
+		if _, err := ParseHexColor(color); err != nil {
+			problems = append(problems, fmt.Errorf("invalid path gradient: %w", err))
+		}
+	}
+	for _, color := range c.Path.Style.DayRamp {
+		if _, err := ParseHexColor(color); err != nil {
+			problems = append(problems, fmt.Errorf("invalid path day ramp: %w", err))
+		}
+	}
+	users := make([]string, 0, len(c.Users.Colors))
+	for user := range c.Users.Colors {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	for _, user := range users {
+		if _, err := ParseHexColor(c.Users.Colors[user]); err != nil {
+			problems = append(problems, fmt.Errorf("invalid color for user %s: %w", user, err))
+		}
+	}
+
+	// Validate the distance between path milestones
+	if c.Path.Milestones.Interval < 0 {
+		problems = append(problems, fmt.Errorf("path milestones interval must not be negative, got %g", c.Path.Milestones.Interval))
+	}
+
+	// Validate the Google Maps base map type
+	switch c.Map.InitialView.MapType {
+	case "", "roadmap", "satellite", "hybrid", "terrain":
+	default:
+		problems = append(problems, fmt.Errorf("unknown map type %q (use roadmap, satellite, hybrid, or terrain)", c.Map.InitialView.MapType))
+	}
+
+	// Validate the page language
+	switch c.Map.Language {
+	case "", "en", "es", "de", "fr":
+	default:
+		problems = append(problems, fmt.Errorf("unknown map language %q (use en, es, de, or fr)", c.Map.Language))
+	}
+
+	// Validate the offline tile bundle
+	if c.Map.OfflineTiles.Enabled {
+		offline := c.Map.OfflineTiles
+		if c.Map.Provider != "leaflet" {
+			problems = append(problems, fmt.Errorf("offline tiles need the leaflet map provider, got %q", c.Map.Provider))
+		}
+		if c.Map.TileURL == "none" {
+			problems = append(problems, fmt.Errorf("offline tiles need a map tile_url"))
+		}
+		minZoom := 0
+		if offline.MinZoom != nil {
+			minZoom = *offline.MinZoom
+		}
+		if minZoom < 0 || minZoom > 19 || offline.MaxZoom < 0 || offline.MaxZoom > 19 {
+			problems = append(problems, fmt.Errorf("offline tiles zoom levels must be between 0 and 19, got %d-%d", minZoom, offline.MaxZoom))
+		}
+		if offline.MaxZoom != 0 && minZoom > offline.MaxZoom {
+			problems = append(problems, fmt.Errorf("offline tiles min_zoom %d is above max_zoom %d", minZoom, offline.MaxZoom))
+		}
+		if offline.MaxTiles < 0 {
+			problems = append(problems, fmt.Errorf("offline tiles max_tiles must not be negative, got %d", offline.MaxTiles))
+		}
+	}
+
+	// Validate the header QR code
+	if c.Map.QRCode.Enabled && c.Map.QRCode.URL == "" {
+		problems = append(problems, fmt.Errorf("map qr_code needs the url of the hosted map"))
+	}
+	if c.Map.QRCode.Size < 0 {
+		problems = append(problems, fmt.Errorf("map qr_code size must not be negative, got %d", c.Map.QRCode.Size))
+	}
+
+	// Validate the hook commands
+	if c.Hooks.TimeoutSeconds < 0 {
+		problems = append(problems, fmt.Errorf("hooks timeout_seconds must not be negative, got %d", c.Hooks.TimeoutSeconds))
+	}
+	for _, command := range append(append([]string(nil), c.Hooks.PreIngest...), c.Hooks.PostGenerate...) {
+		if strings.TrimSpace(command) == "" {
+			problems = append(problems, fmt.Errorf("hook commands must not be empty"))
+			break
+		}
+	}
+
+	// Validate the track API of serve mode; storage backends are checked when opened
+	if c.Server.MaxUploadSize < 0 {
+		problems = append(problems, fmt.Errorf("server max_upload_size must not be negative, got %d", c.Server.MaxUploadSize))
+	}
+	names, tokens := make(map[string]bool), make(map[string]bool)
+	for _, user := range c.Server.Users {
+		if !userNamePattern.MatchString(user.Name) {
+			problems = append(problems, fmt.Errorf("server user name %q must be letters, digits, '.', '_', or '-'", user.Name))
+		}
+		if len(user.Token) < MinTokenLength {
+			problems = append(problems, fmt.Errorf("server user %s needs a token of at least %d characters", user.Name, MinTokenLength))
+		}
+		if names[user.Name] || tokens[user.Token] {
+			problems = append(problems, fmt.Errorf("server user %s is not unique: every user needs its own name and token", user.Name))
+		}
+		names[user.Name], tokens[user.Token] = true, true
+	}
+
+	// Validate the print layout
+	switch c.Map.Print.PaperSize {
+	case "", "a4", "letter":
+	default:
+		problems = append(problems, fmt.Errorf("unknown print paper size %q (use a4 or letter)", c.Map.Print.PaperSize))
+	}
+	switch c.Map.Print.Orientation {
+	case "", "landscape", "portrait":
+	default:
+		problems = append(problems, fmt.Errorf("unknown print orientation %q (use landscape or portrait)", c.Map.Print.Orientation))
+	}
+
+	// Validate the line patterns of the track and the reference route
+	for _, pattern := range []struct{ setting, value string }{
+		{"path.style.stroke_pattern", c.Path.Style.StrokePattern},
+		{"compare.stroke_pattern", c.Compare.StrokePattern},
+	} {
+		switch pattern.value {
+		case "", "solid", "dashed", "dotted":
+		default:
+			problems = append(problems, fmt.Errorf("unknown %s %q (use solid, dashed, or dotted)", pattern.setting, pattern.value))
+		}
+	}
+
+	// Validate the policy for existing output files
+	switch c.Output.Overwrite {
+	case "", "refuse", "force", "backup":
+	default:
+		problems = append(problems, fmt.Errorf("unknown output overwrite policy %q (use refuse, force, or backup)", c.Output.Overwrite))
+	}
+
+	// Validate the automatic map centering method
+	switch c.Map.CenterMethod {
+	case "", "mean", "spherical", "median":
+	default:
+		problems = append(problems, fmt.Errorf("unknown map center method %q (use mean, spherical, or median)", c.Map.CenterMethod))
+	}
+
+	// Validate the distance formula used for statistics
+	switch c.Statistics.DistanceMethod {
+	case "", "haversine", "vincenty":
+	default:
+		problems = append(problems, fmt.Errorf("unknown statistics distance method %q (use haversine or vincenty)", c.Statistics.DistanceMethod))
+	}
+
+	// Validate the timezone used for day and week boundaries
+	if _, err := time.LoadLocation(c.Processing.Timezone); err != nil {
+		problems = append(problems, fmt.Errorf("unknown processing timezone %q: %w", c.Processing.Timezone, err))
+	}
+
+	// Validate the processing pipeline stage names
+	for _, stage := range c.Processing.Pipeline {
+		switch stage {
+		case "dedupe", "max_speed", "min_distance", "smooth", "simplify":
+		default:
+			problems = append(problems, fmt.Errorf("unknown processing pipeline stage %q (use dedupe, max_speed, min_distance, smooth, or simplify)", stage))
+		}
+	}
+
+	// Validate the log format
+	switch c.Logging.Format {
+	case "", "text", "json":
+	default:
+		problems = append(problems, fmt.Errorf("unknown log format %q (use text or json)", c.Logging.Format))
+	}
+
+	// Validate the log level
+	switch strings.ToLower(c.Logging.Level) {
+	case "", "trace", "debug", "info", "warn", "warning", "error":
+	default:
+		problems = append(problems, fmt.Errorf("unknown log level %q (use trace, debug, info, warn, or error)", c.Logging.Level))
+	}
+
+	// Validate the meeting point time so a typo fails before generation
+	if c.Proximity.MeetingTime != "" {
+		if _, err := time.Parse(time.RFC3339, c.Proximity.MeetingTime); err != nil {
+			problems = append(problems, fmt.Errorf("invalid proximity meeting_time %q (use RFC 3339, e.g. 2025-10-28T15:00:00Z)", c.Proximity.MeetingTime))
+		}
+	}
+
+	return problems
+}
+
+// ParseHexColor parses a #RRGGBB or #RGB color into its red, green, and blue values.
+//
+// @function ParseHexColor
+// @description Parses the hex colors of path gradients, day ramps, and user colors
+// @param color string Hex color, with or without the leading #
+// @return [3]float64 Red, green, and blue values from 0 to 255
+// @return error Error if color is not a hex color
+// @example rgb, err := config.ParseHexColor("#FF8800")
+func ParseHexColor(color string) ([3]float64, error) {
+	var rgb [3]float64
+	hex := strings.TrimPrefix(strings.TrimSpace(color), "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 6 || err != nil {
+		return rgb, fmt.Errorf("%q is not a hex color such as #FF0000", color)
+	}
+	rgb[0], rgb[1], rgb[2] = float64(value>>16), float64(value>>8&0xFF), float64(value&0xFF)
+	return rgb, nil
 }
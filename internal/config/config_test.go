@@ -118,6 +118,7 @@ func TestConfigResolveAPIKey(t *testing.T) {
 		apiKey      string
 		envVar      string
 		envValue    string
+		provider    string
 		wantErr     bool
 		expectedKey string
 	}{
@@ -145,6 +146,19 @@ func TestConfigResolveAPIKey(t *testing.T) {
 			apiKey:  "",
 			wantErr: true,
 		},
+		{
+			name:        "empty api key with leaflet provider",
+			apiKey:      "",
+			provider:    "leaflet",
+			wantErr:     false,
+			expectedKey: "",
+		},
+		{
+			name:     "missing environment variable with leaflet provider",
+			apiKey:   "${MISSING_KEY}",
+			provider: "leaflet",
+			wantErr:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -166,6 +180,7 @@ func TestConfigResolveAPIKey(t *testing.T) {
 				GoogleMaps: GoogleMapsConfig{
 					APIKey: tt.apiKey,
 				},
+				Map: MapConfig{Provider: tt.provider},
 			}
 
 			err := config.ResolveAPIKey()
@@ -189,6 +204,28 @@ func TestConfigResolveAPIKey(t *testing.T) {
 	}
 }
 
+func TestConfigResolveTokens(t *testing.T) {
+	t.Setenv("TEST_ALICE_TOKEN", "alice-token-from-env")
+	config := &Config{Server: ServerConfig{Users: []ServerUserConfig{
+		{Name: "alice", Token: "${TEST_ALICE_TOKEN}"},
+		{Name: "bob", Token: "bob-token-in-config"},
+	}}}
+	if err := config.ResolveTokens(); err != nil {
+		t.Fatalf("ResolveTokens() error = %v", err)
+	}
+	if got := config.Server.Users[0].Token; got != "alice-token-from-env" {
+		t.Errorf("ResolveTokens() alice token = %q, want the environment value", got)
+	}
+	if got := config.Server.Users[1].Token; got != "bob-token-in-config" {
+		t.Errorf("ResolveTokens() bob token = %q, want it unchanged", got)
+	}
+
+	missing := &Config{Server: ServerConfig{Users: []ServerUserConfig{{Name: "carol", Token: "${MISSING_TOKEN}"}}}}
+	if err := missing.ResolveTokens(); err == nil {
+		t.Error("ResolveTokens() error = nil for an unset environment variable")
+	}
+}
+
 func TestConfigValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -212,6 +249,52 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "leaflet provider without api key",
+			config: &Config{
+				Map:    MapConfig{Provider: "leaflet"},
+				Input:  InputConfig{CSVFile: "test.csv"},
+				Output: OutputConfig{HTMLFile: "test.html"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "maplibre provider without api key",
+			config: &Config{
+				Map:    MapConfig{Provider: "maplibre", MapLibre: MapLibreConfig{Pitch: 60}},
+				Input:  InputConfig{CSVFile: "test.csv"},
+				Output: OutputConfig{HTMLFile: "test.html"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "maplibre pitch out of range",
+			config: &Config{
+				Map:    MapConfig{Provider: "maplibre", MapLibre: MapLibreConfig{Pitch: 90}},
+				Input:  InputConfig{CSVFile: "test.csv"},
+				Output: OutputConfig{HTMLFile: "test.html"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "cesium terrain without ion token",
+			config: &Config{
+				Map:    MapConfig{Provider: "cesium", Cesium: CesiumConfig{Terrain: true}},
+				Input:  InputConfig{CSVFile: "test.csv"},
+				Output: OutputConfig{HTMLFile: "test.html"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown map provider",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Map:        MapConfig{Provider: "bing"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing csv file",
 			config: &Config{
@@ -228,6 +311,380 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "vincenty distance method",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Statistics: StatisticsConfig{DistanceMethod: "vincenty"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown distance method",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Statistics: StatisticsConfig{DistanceMethod: "flat"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "backup overwrite policy",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html", Overwrite: "backup"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown overwrite policy",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html", Overwrite: "always"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown log level",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Logging:    LoggingConfig{Level: "verbose"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown log format",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Logging:    LoggingConfig{Format: "logfmt"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative hook timeout",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Hooks:      HooksConfig{PostGenerate: []string{"rsync map.html host:"}, TimeoutSeconds: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty hook command",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Hooks:      HooksConfig{PreIngest: []string{" "}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown path color_by",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Path:       PathConfig{Style: PathStyleConfig{ColorBy: "elevation"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "path colored by day",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Path:       PathConfig{Style: PathStyleConfig{ColorBy: "day"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative milestone interval",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Path:       PathConfig{Milestones: MilestonesConfig{Enabled: true, Interval: -1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown map type",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{InitialView: InitialViewConfig{MapType: "ROADMAP"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown map language",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{Language: "German"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "supported map language",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{Language: "fr"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "offline tiles without leaflet",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{Provider: "google", OfflineTiles: OfflineTilesConfig{Enabled: true}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "offline tiles without a tile URL",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{Provider: "leaflet", TileURL: "none", OfflineTiles: OfflineTilesConfig{Enabled: true}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "offline tiles zoom out of range",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{Provider: "leaflet", OfflineTiles: OfflineTilesConfig{Enabled: true, MaxZoom: 22}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "offline tiles min zoom above max zoom",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{Provider: "leaflet", OfflineTiles: OfflineTilesConfig{Enabled: true, MinZoom: intPtr(15), MaxZoom: 12}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "offline tiles negative max tiles",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{Provider: "leaflet", OfflineTiles: OfflineTilesConfig{Enabled: true, MaxTiles: -1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid offline tiles",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{Provider: "leaflet", OfflineTiles: OfflineTilesConfig{Enabled: true, MinZoom: intPtr(12), MaxZoom: 14}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "qr code without url",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{QRCode: QRCodeConfig{Enabled: true}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "qr code with url",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{QRCode: QRCodeConfig{Enabled: true, URL: "https://maps.example.org/trip.html"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative max upload size",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Server:     ServerConfig{MaxUploadSize: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "server users",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Server: ServerConfig{Users: []ServerUserConfig{
+					{Name: "alice", Token: "alice-0123456789abcdef"},
+					{Name: "bob.smith", Token: "bob-0123456789abcdef"},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "server user name with slash",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Server:     ServerConfig{Users: []ServerUserConfig{{Name: "alice/admin", Token: "alice-0123456789abcdef"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "short server user token",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Server:     ServerConfig{Users: []ServerUserConfig{{Name: "alice", Token: "secret"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "shared server user token",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Server: ServerConfig{Users: []ServerUserConfig{
+					{Name: "alice", Token: "shared-0123456789abcdef"},
+					{Name: "bob", Token: "shared-0123456789abcdef"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown print paper size",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{Print: PrintConfig{PaperSize: "A3"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown print orientation",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{Print: PrintConfig{PaperSize: "letter", Orientation: "upright"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown path stroke pattern",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Path:       PathConfig{Style: PathStyleConfig{StrokePattern: "dash-dot"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown reference stroke pattern",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Compare:    CompareConfig{StrokePattern: "wavy"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown center method",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Map:        MapConfig{CenterMethod: "centroid"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid meeting time",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Proximity:  ProximityConfig{MeetingTime: "2025-10-28T15:00:00Z"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown timezone",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Processing: ProcessingConfig{Timezone: "Mars/Olympus_Mons"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid pipeline",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Processing: ProcessingConfig{Pipeline: []string{"max_speed", "dedupe", "simplify"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown pipeline stage",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Processing: ProcessingConfig{Pipeline: []string{"dedupe", "kalman"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid meeting time",
+			config: &Config{
+				GoogleMaps: GoogleMapsConfig{APIKey: "test-key"},
+				Input:      InputConfig{CSVFile: "test.csv"},
+				Output:     OutputConfig{HTMLFile: "test.html"},
+				Proximity:  ProximityConfig{MeetingTime: "3pm"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts the names of the environment variables that override
+// settings, such as GEOCHRONO_MAP_TITLE for map.title.
+const EnvPrefix = "GEOCHRONO_"
+
+// envReferencePattern matches ${VAR} references in string settings. Only upper
+// case names are references, so JavaScript template literals such as ${title}
+// in custom templates are left alone.
+var envReferencePattern = regexp.MustCompile(`\$\{([A-Z_][A-Z0-9_]*)\}`)
+
+// envResolvedSettings are resolved by their own methods (ResolveAPIKey and
+// ResolveTokens), which allow unset variables where the value is not needed.
+// Hook commands are left to the shell, which also sees the variables the hook
+// is run with.
+var envResolvedSettings = regexp.MustCompile(`^(google_maps\.api_key|server\.users\[\d+\]\.token|hooks\.\w+\[\d+\])$`)
+
+// EnvName returns the environment variable overriding the setting at a dotted
+// path: the path in upper case with dots as underscores, after EnvPrefix.
+//
+// @function EnvName
+// @description Names the environment override of a setting
+// @param path string Dotted setting path, such as "input.csv_format.delimiter"
+// @return string Variable name, such as "GEOCHRONO_INPUT_CSV_FORMAT_DELIMITER"
+func EnvName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// HasEnv reports whether environ, as returned by os.Environ, sets any
+// GEOCHRONO_ variable.
+func HasEnv(environ []string) bool {
+	for _, entry := range environ {
+		if strings.HasPrefix(entry, EnvPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyEnv overrides settings with GEOCHRONO_ environment variables, then
+// replaces ${VAR} references in every string setting with the variable's value.
+// Settings holding single values or lists of strings (comma-separated) can be
+// overridden; maps and lists of sections cannot.
+//
+// @method ApplyEnv
+// @description Configures the application from the environment
+// @param environ []string Environment as returned by os.Environ
+// @return error Error naming an unknown GEOCHRONO_ variable, a value of the
+// wrong type, or an unset variable referenced by a setting
+// @note google_maps.api_key and server user tokens keep their references for
+// ResolveAPIKey and ResolveTokens, which know when a missing value matters
+// @example err := cfg.ApplyEnv(os.Environ())
+func (c *Config) ApplyEnv(environ []string) error {
+	env := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		if name, value, ok := strings.Cut(entry, "="); ok {
+			env[name] = value
+		}
+	}
+
+	// Override settings, reporting variables that name no setting
+	used := make(map[string]bool)
+	if err := overrideSettings("", reflect.ValueOf(c).Elem(), env, used); err != nil {
+		return err
+	}
+	var unknown []string
+	for name := range env {
+		if strings.HasPrefix(name, EnvPrefix) && name != ProfileEnv && !used[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("environment variable %s names no setting", strings.Join(unknown, ", "))
+	}
+
+	return expandSettings("", reflect.ValueOf(c).Elem(), env)
+}
+
+// overrideSettings sets the settings below v from their GEOCHRONO_ variables,
+// recording the variables it used.
+func overrideSettings(path string, v reflect.Value, env map[string]string, used map[string]bool) error {
+	if v.Kind() == reflect.Struct {
+		for i := 0; i < v.NumField(); i++ {
+			key, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+			if key == "" || key == "-" {
+				continue
+			}
+			if err := overrideSettings(joinSetting(path, key), v.Field(i), env, used); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	name := EnvName(path)
+	value, ok := env[name]
+	if !ok || !overridable(v.Type()) {
+		return nil
+	}
+	if err := setSetting(v, value); err != nil {
+		return fmt.Errorf("environment variable %s: %w", name, err)
+	}
+	used[name] = true
+	return nil
+}
+
+// overridable reports whether settings of type t can be set from a variable:
+// single values, optional single values, and lists of strings.
+func overridable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return isScalar(t.Elem().Kind())
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return isScalar(t.Kind())
+}
+
+// setSetting parses value into the overridable setting v.
+func setSetting(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		target := reflect.New(v.Type().Elem())
+		if err := setSetting(target.Elem(), value); err != nil {
+			return err
+		}
+		v.Set(target)
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		v.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+	}
+	return nil
+}
+
+// isScalar reports whether settings of kind k hold a single value.
+func isScalar(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// expandSettings replaces the ${VAR} references in the string settings below v.
+func expandSettings(path string, v reflect.Value, env map[string]string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return expandSettings(path, v.Elem(), env)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			key, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ",")
+			if key == "" || key == "-" {
+				continue
+			}
+			if err := expandSettings(joinSetting(path, key), v.Field(i), env); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := expandSettings(fmt.Sprintf("%s[%d]", path, i), v.Index(i), env); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			expanded, err := expandString(fmt.Sprintf("%s.%v", path, iter.Key()), iter.Value().String(), env)
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(expanded).Convert(v.Type().Elem()))
+		}
+	case reflect.String:
+		expanded, err := expandString(path, v.String(), env)
+		if err != nil {
+			return err
+		}
+		v.SetString(expanded)
+	}
+	return nil
+}
+
+// expandString replaces the ${VAR} references in the value of a setting.
+func expandString(path, value string, env map[string]string) (string, error) {
+	if envResolvedSettings.MatchString(path) || !strings.Contains(value, "${") {
+		return value, nil
+	}
+	var missing string
+	expanded := envReferencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		name := envReferencePattern.FindStringSubmatch(reference)[1]
+		resolved, ok := env[name]
+		if !ok && missing == "" {
+			missing = name
+		}
+		return resolved
+	})
+	if missing != "" {
+		return "", fmt.Errorf("%s: environment variable %s is not set", path, missing)
+	}
+	return expanded, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	if got := EnvName("input.csv_format.delimiter"); got != "GEOCHRONO_INPUT_CSV_FORMAT_DELIMITER" {
+		t.Errorf("EnvName() = %q", got)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	cfg := &Config{
+		GoogleMaps: GoogleMapsConfig{APIKey: "${GOOGLE_MAPS_API_KEY}"},
+		Input:      InputConfig{CSVFile: "${DATA_DIR}/walk.csv"},
+		Map:        MapConfig{Title: "From the file", Width: "100%"},
+		Users:      UsersConfig{Colors: map[string]string{"alice": "${ALICE_COLOR}"}},
+		InfoWindows: InfoWindowsConfig{
+			Template: "<b>{{.Title}}</b> ${title}",
+		},
+		Server: ServerConfig{Users: []ServerUserConfig{{Name: "alice", Token: "${ALICE_TOKEN}"}}},
+	}
+	environ := []string{
+		"DATA_DIR=/srv/tracks",
+		"ALICE_COLOR=#00AA00",
+		"GEOCHRONO_MAP_TITLE=Container Walk ${DATA_DIR}",
+		"GEOCHRONO_MAP_INITIAL_VIEW_ZOOM=14",
+		"GEOCHRONO_PATH_STYLE_OPACITY=0.5",
+		"GEOCHRONO_MAP_CONTROLS_SEARCH=true",
+		"GEOCHRONO_OUTPUT_FORMATS=html, geojson,stats",
+		"GEOCHRONO_SERVER_MAX_UPLOAD_SIZE=1048576",
+	}
+	if err := cfg.ApplyEnv(environ); err != nil {
+		t.Fatalf("ApplyEnv() error = %v", err)
+	}
+
+	if cfg.Map.Title != "Container Walk /srv/tracks" || cfg.Map.Width != "100%" {
+		t.Errorf("ApplyEnv() map title %q, width %q", cfg.Map.Title, cfg.Map.Width)
+	}
+	if cfg.Map.InitialView.Zoom == nil || *cfg.Map.InitialView.Zoom != 14 || cfg.Path.Style.Opacity != 0.5 || !cfg.Map.Controls.Search {
+		t.Errorf("ApplyEnv() zoom %v, opacity %g, search %v", cfg.Map.InitialView.Zoom, cfg.Path.Style.Opacity, cfg.Map.Controls.Search)
+	}
+	if strings.Join(cfg.Output.Formats, "|") != "html|geojson|stats" || cfg.Server.MaxUploadSize != 1048576 {
+		t.Errorf("ApplyEnv() formats %q, max upload size %d", cfg.Output.Formats, cfg.Server.MaxUploadSize)
+	}
+	if cfg.Input.CSVFile != "/srv/tracks/walk.csv" || cfg.Users.Colors["alice"] != "#00AA00" {
+		t.Errorf("ApplyEnv() csv file %q, alice color %q", cfg.Input.CSVFile, cfg.Users.Colors["alice"])
+	}
+	// References with their own resolution and JavaScript template literals stay
+	if cfg.GoogleMaps.APIKey != "${GOOGLE_MAPS_API_KEY}" || cfg.Server.Users[0].Token != "${ALICE_TOKEN}" || !strings.HasSuffix(cfg.InfoWindows.Template, "${title}") {
+		t.Errorf("ApplyEnv() api key %q, token %q, template %q", cfg.GoogleMaps.APIKey, cfg.Server.Users[0].Token, cfg.InfoWindows.Template)
+	}
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		environ []string
+		wantErr string
+	}{
+		{name: "unknown setting", environ: []string{"GEOCHRONO_MAP_TITEL=x"}, wantErr: "GEOCHRONO_MAP_TITEL names no setting"},
+		{name: "section", environ: []string{"GEOCHRONO_MAP=x"}, wantErr: "GEOCHRONO_MAP names no setting"},
+		{name: "map setting", environ: []string{"GEOCHRONO_USERS_COLORS=x"}, wantErr: "names no setting"},
+		{name: "not a number", environ: []string{"GEOCHRONO_PATH_STYLE_WEIGHT=thick"}, wantErr: `GEOCHRONO_PATH_STYLE_WEIGHT: "thick" is not a whole number`},
+		{name: "not a bool", environ: []string{"GEOCHRONO_PATH_ENABLED=sometimes"}, wantErr: "not true or false"},
+		{name: "unset reference", environ: []string{"GEOCHRONO_OUTPUT_HTML_FILE=${OUT_DIR}/map.html"}, wantErr: "output.html_file: environment variable OUT_DIR is not set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Config{}).ApplyEnv(tt.environ)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ApplyEnv() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
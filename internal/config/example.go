@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// yamlKeyPattern matches a YAML mapping line, capturing its indentation, key,
+// and the rest of the line after the colon.
+var yamlKeyPattern = regexp.MustCompile(`^(\s*)([A-Za-z0-9_]+):(.*)$`)
+
+// SetYAMLValue returns a copy of a YAML document with the scalar setting at a
+// dotted path replaced by a quoted string, keeping the document's comments and
+// layout. It edits the text rather than re-encoding the document, so commented
+// example files stay readable.
+//
+// @function SetYAMLValue
+// @description Changes one setting of a commented YAML file in place
+// @param data []byte YAML document, such as the example config.yaml
+// @param path string Dotted setting path, such as "input.csv_format.latitude_column"
+// @param value string New value, written as a double-quoted string
+// @return []byte Document with the setting replaced
+// @return error Error if the document has no such setting
+// @example data, err := config.SetYAMLValue(data, "input.csv_file", "walk.csv")
+func SetYAMLValue(data []byte, path, value string) ([]byte, error) {
+	lines := strings.Split(string(data), "\n")
+
+	type parent struct {
+		indent int
+		key    string
+	}
+	var parents []parent
+	for i, line := range lines {
+		match := yamlKeyPattern.FindStringSubmatch(line)
+		if match == nil || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		indent, key, rest := len(match[1]), match[2], match[3]
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+		keys := make([]string, 0, len(parents)+1)
+		for _, p := range parents {
+			keys = append(keys, p.key)
+		}
+		if strings.Join(append(keys, key), ".") != path {
+			parents = append(parents, parent{indent: indent, key: key})
+			continue
+		}
+
+		if strings.TrimSpace(yamlValue(rest)) == "" {
+			return nil, fmt.Errorf("setting %s is not a single value", path)
+		}
+		newLine := match[1] + key + ": " + strconv.Quote(value)
+		if comment := rest[len(yamlValue(rest)):]; comment != "" {
+			newLine += "  " + strings.TrimLeft(comment, " \t")
+		}
+		lines[i] = newLine
+		return []byte(strings.Join(lines, "\n")), nil
+	}
+	return nil, fmt.Errorf("setting %s not found", path)
+}
+
+// yamlValue returns the value part of the text after a key's colon, without a
+// trailing comment. Comment markers inside quoted strings are kept.
+func yamlValue(rest string) string {
+	var quote rune
+	for i, r := range rest {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || rest[i-1] == ' ' || rest[i-1] == '\t'):
+			return rest[:i]
+		}
+	}
+	return rest
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v2"
+)
+
+func TestSetYAMLValue(t *testing.T) {
+	doc := `# Example
+input:
+  csv_file: "data/coordinates.csv"
+  csv_format:
+    latitude_column: "latitude"     # Required
+    title_column: "#title"
+map:
+  title: "Trail"
+  libraries:
+    - geometry
+`
+	tests := []struct {
+		name     string
+		path     string
+		value    string
+		wantLine string
+		wantErr  bool
+	}{
+		{name: "top level section", path: "map.title", value: "Bay Walk", wantLine: `  title: "Bay Walk"`},
+		{name: "nested with comment", path: "input.csv_format.latitude_column", value: "lat", wantLine: `    latitude_column: "lat"  # Required`},
+		{name: "hash in quotes", path: "input.csv_format.title_column", value: "name", wantLine: `    title_column: "name"`},
+		{name: "quoting", path: "input.csv_file", value: `C:\tracks\"walk".csv`, wantLine: `  csv_file: "C:\\tracks\\\"walk\".csv"`},
+		{name: "same key elsewhere", path: "title", value: "x", wantErr: true},
+		{name: "section", path: "input.csv_format", value: "x", wantErr: true},
+		{name: "unknown", path: "output.html_file", value: "x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SetYAMLValue([]byte(doc), tt.path, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetYAMLValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !strings.Contains(string(got), "\n"+tt.wantLine+"\n") {
+				t.Errorf("SetYAMLValue() =\n%s\nwant line %q", got, tt.wantLine)
+			}
+			if strings.Count(string(got), "\n") != strings.Count(doc, "\n") || !strings.HasPrefix(string(got), "# Example\n") {
+				t.Errorf("SetYAMLValue() changed other lines:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestSetYAMLValueExampleConfig(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "config.yaml"))
+	if err != nil {
+		t.Fatalf("reading the example config: %v", err)
+	}
+	// The settings "geo-chrono config init -interactive" fills in
+	for path, value := range map[string]string{
+		"input.csv_file":                    "walk.csv",
+		"input.csv_format.timestamp_column": "time",
+		"input.csv_format.latitude_column":  "lat",
+		"input.csv_format.longitude_column": "lon",
+		"google_maps.api_key":               "",
+		"map.provider":                      "leaflet",
+	} {
+		if data, err = SetYAMLValue(data, path, value); err != nil {
+			t.Fatalf("SetYAMLValue(%s) error = %v", path, err)
+		}
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("edited example config does not parse: %v", err)
+	}
+	format := cfg.Input.CSVFormat
+	if cfg.Input.CSVFile != "walk.csv" || format.TimestampColumn != "time" || format.LatitudeColumn != "lat" || format.LongitudeColumn != "lon" || cfg.GoogleMaps.APIKey != "" || cfg.Map.Provider != "leaflet" {
+		t.Errorf("edited example config = input %+v, key %q, provider %q", cfg.Input, cfg.GoogleMaps.APIKey, cfg.Map.Provider)
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// IsJSON reports whether filename names a JSON configuration file, by its
+// .json extension. Every other file is read as YAML.
+func IsJSON(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".json")
+}
+
+// jsonToYAML converts a JSON configuration to YAML, so that it decodes through
+// the same yaml struct tags as a YAML file. JSON itself is close to YAML, but
+// not close enough to hand to the YAML parser as is (escaped slashes, for one).
+func jsonToYAML(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, fmt.Errorf("line %d: %w", bytes.Count(data[:syntaxErr.Offset], []byte("\n"))+1, err)
+		}
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after the top-level JSON object")
+	}
+	if _, ok := value.(map[string]any); !ok {
+		return nil, errors.New("a JSON config must be an object")
+	}
+	return yaml.Marshal(fromJSON(value))
+}
+
+// fromJSON turns JSON numbers into Go integers where they are whole, so they
+// decode into integer settings, and floats elsewhere.
+func fromJSON(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = fromJSON(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = fromJSON(item)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	}
+	return value
+}
+
+// ToJSON converts a YAML configuration, such as the commented example, to an
+// indented JSON configuration with the settings in the same order. Comments
+// are dropped, since JSON has none.
+//
+// @function ToJSON
+// @description Converts a YAML configuration file's contents to JSON
+// @param data []byte YAML configuration
+// @return []byte Equivalent JSON configuration
+// @return error Error if the YAML is invalid or uses non-string keys
+// @example data, err := config.ToJSON(geochrono.ExampleConfig)
+func ToJSON(data []byte) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("cannot parse config: %w", err)
+	}
+	return marshalJSON(orderedJSON(doc), "  ")
+}
+
+// marshalJSON encodes value, indented unless indent is empty, leaving HTML
+// characters of templates unescaped.
+func marshalJSON(value any, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonObject is a YAML mapping marshaled as a JSON object in its YAML order.
+type jsonObject yaml.MapSlice
+
+// MarshalJSON writes the mapping's keys in order.
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, item := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := marshalJSON(jsonKey(item.Key), "")
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshalJSON(item.Value, "")
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(bytes.TrimSpace(key))
+		buf.WriteByte(':')
+		buf.Write(bytes.TrimSpace(value))
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonKey returns the JSON object key of a YAML mapping key. YAML 1.1 reads
+// the key y, of marker anchors, as the boolean true; n is its false.
+func jsonKey(key any) string {
+	if b, ok := key.(bool); ok {
+		if b {
+			return "y"
+		}
+		return "n"
+	}
+	return fmt.Sprint(key)
+}
+
+// orderedJSON replaces the YAML mappings in value with jsonObjects.
+func orderedJSON(value any) any {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		object := make(jsonObject, len(v))
+		for i, item := range v {
+			object[i] = yaml.MapItem{Key: item.Key, Value: orderedJSON(item.Value)}
+		}
+		return object
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = orderedJSON(item)
+		}
+		return items
+	}
+	return value
+}
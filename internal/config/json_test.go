@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadJSON(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		file    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "json",
+			file: "config.json",
+			data: `{
+	"google_maps": {"api_key": "${GOOGLE_MAPS_API_KEY}"},
+	"input": {"csv_file": "data\/walk.csv", "csv_format": {"has_header": true, "skip_rows": 2}},
+	"map": {"title": "Café Tour", "initial_view": {"zoom": 12}},
+	"path": {"style": {"opacity": 0.5}},
+	"server": {"max_upload_size": 33554432}
+}`,
+		},
+		{name: "upper case extension", file: "CONFIG.JSON", data: `{"map": {"title": "Café Tour"}}`},
+		{name: "syntax error", file: "broken.json", data: "{\n  \"map\": {\"title\": \"x\",}\n}", wantErr: "line 2"},
+		{name: "not an object", file: "list.json", data: `["map"]`, wantErr: "must be an object"},
+		{name: "yaml in json file", file: "yaml.json", data: "map:\n  title: x\n", wantErr: "cannot parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(file, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := Load(file)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Load() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.Map.Title != "Café Tour" {
+				t.Errorf("Load() title = %q, want %q", cfg.Map.Title, "Café Tour")
+			}
+			if tt.name == "json" && (cfg.Input.CSVFile != "data/walk.csv" || !cfg.Input.CSVFormat.HasHeader || cfg.Input.CSVFormat.SkipRows != 2 ||
+				*cfg.Map.InitialView.Zoom != 12 || cfg.Path.Style.Opacity != 0.5 || cfg.Server.MaxUploadSize != 33554432) {
+				t.Errorf("Load() = input %+v, zoom %d, opacity %g, max upload %d", cfg.Input, *cfg.Map.InitialView.Zoom, cfg.Path.Style.Opacity, cfg.Server.MaxUploadSize)
+			}
+		})
+	}
+}
+
+func TestCheckJSON(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, []byte(`{"map": {"titel": "x"}, "path": {"style": {"opacity": 2}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	problems := Check(file)
+	if len(problems) == 0 || problems[0].Error() != "field titel not found in type config.MapConfig" {
+		t.Errorf("Check() = %v, want the unknown setting first, without a line number", problems)
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	example := filepath.Join("..", "..", "config.yaml")
+	data, err := os.ReadFile(example)
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted, err := ToJSON(data)
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	if !strings.HasPrefix(string(converted), "{\n  \"google_maps\": {") {
+		t.Errorf("ToJSON() does not keep the setting order:\n%.200s", converted)
+	}
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(file, converted, 0644); err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := Load(file)
+	if err != nil {
+		t.Fatalf("Load(converted) error = %v", err)
+	}
+	fromYAML, err := Load(example)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON, fromYAML) {
+		t.Errorf("Load(converted) = %+v, want %+v", fromJSON, fromYAML)
+	}
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"go.yaml.in/yaml/v2"
+)
+
+// ProfileEnv is the environment variable selecting a profile when no -profile
+// flag is given.
+const ProfileEnv = EnvPrefix + "PROFILE"
+
+// profilesKey is the top-level section holding the named profiles.
+const profilesKey = "profiles"
+
+// LoadProfile loads a configuration file like Load, then merges the settings
+// of a named profile from its profiles section over the base settings.
+// Sections merge setting by setting; lists and single values are replaced.
+//
+// @function LoadProfile
+// @description Loads a configuration file with one of its profiles applied
+// @param filename string Path to YAML or JSON configuration file
+// @param profile string Profile name ("" for the base settings alone)
+// @return *Config Parsed configuration with the profile applied
+// @return error Error if the file cannot be read or has no such profile
+// @example cfg, err := config.LoadProfile("config.yaml", "hiking")
+func LoadProfile(filename, profile string) (*Config, error) {
+	data, err := readFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseProfile(data, profile)
+}
+
+// ParseProfile parses YAML configuration contents like Parse, with the named
+// profile merged over the base settings.
+func ParseProfile(data []byte, profile string) (*Config, error) {
+	data, err := applyProfile(data, profile)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// applyProfile returns YAML configuration data with the named profile merged
+// over the base settings and the profiles section removed. Data without a
+// profiles section is returned unchanged when no profile is selected.
+func applyProfile(data []byte, profile string) ([]byte, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("cannot parse config file: %w", err)
+	}
+	base, profiles, found := cutProfiles(doc)
+	if !found && profile == "" {
+		return data, nil
+	}
+
+	if profile != "" {
+		settings, ok := lookupKey(profiles, profile)
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q (available: %v)", profile, profileNames(profiles))
+		}
+		overrides, ok := settings.(yaml.MapSlice)
+		if !ok && settings != nil {
+			return nil, fmt.Errorf("profile %q must be a section of settings", profile)
+		}
+		base = mergeSettings(base, overrides)
+	}
+	return yaml.Marshal(normalizeKeys(base))
+}
+
+// cutProfiles splits the profiles section off a configuration document.
+func cutProfiles(doc yaml.MapSlice) (base yaml.MapSlice, profiles yaml.MapSlice, found bool) {
+	for _, item := range doc {
+		if item.Key == profilesKey {
+			profiles, _ = item.Value.(yaml.MapSlice)
+			found = true
+			continue
+		}
+		base = append(base, item)
+	}
+	return base, profiles, found
+}
+
+// profileNames returns the sorted names of profiles.
+func profileNames(profiles yaml.MapSlice) []string {
+	names := make([]string, 0, len(profiles))
+	for _, item := range profiles {
+		names = append(names, fmt.Sprint(item.Key))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookupKey returns the value of a key of a YAML mapping.
+func lookupKey(m yaml.MapSlice, key string) (any, bool) {
+	for _, item := range m {
+		if fmt.Sprint(item.Key) == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+// mergeSettings returns base with overrides merged in: sections present in
+// both merge recursively, and every other override replaces the base value.
+func mergeSettings(base, overrides yaml.MapSlice) yaml.MapSlice {
+	merged := append(yaml.MapSlice(nil), base...)
+	for _, override := range overrides {
+		i := indexKey(merged, override.Key)
+		if i < 0 {
+			merged = append(merged, override)
+			continue
+		}
+		baseSection, baseIsSection := merged[i].Value.(yaml.MapSlice)
+		overrideSection, overrideIsSection := override.Value.(yaml.MapSlice)
+		if baseIsSection && overrideIsSection {
+			merged[i].Value = mergeSettings(baseSection, overrideSection)
+		} else {
+			merged[i].Value = override.Value
+		}
+	}
+	return merged
+}
+
+// indexKey returns the position of key in a YAML mapping, or -1.
+func indexKey(m yaml.MapSlice, key any) int {
+	for i, item := range m {
+		if item.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// normalizeKeys restores mapping keys that YAML 1.1 read as booleans, such as
+// the y of marker anchors, so the document encodes back to the same settings.
+func normalizeKeys(value any) any {
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		out := make(yaml.MapSlice, len(v))
+		for i, item := range v {
+			key := item.Key
+			if _, ok := key.(bool); ok {
+				key = jsonKey(key)
+			}
+			out[i] = yaml.MapItem{Key: key, Value: normalizeKeys(item.Value)}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = normalizeKeys(item)
+		}
+		return out
+	}
+	return value
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const profilesYAML = `
+google_maps:
+  api_key: "test-key"
+input:
+  csv_file: "test.csv"
+output:
+  html_file: "test.html"
+  formats: ["html", "kml"]
+map:
+  title: "Base"
+  width: "80%"
+markers:
+  default:
+    icon:
+      anchor:
+        x: 5
+        y: 7
+path:
+  style:
+    color: "#FF0000"
+    weight: 3
+profiles:
+  hiking:
+    map:
+      title: "Hiking"
+    path:
+      style:
+        color: "#00AA00"
+  publish:
+    output:
+      formats: ["geojson"]
+  empty:
+`
+
+func TestLoadProfile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte(profilesYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		profile string
+		title   string
+		color   string
+		formats string
+	}{
+		{profile: "", title: "Base", color: "#FF0000", formats: "html|kml"},
+		{profile: "hiking", title: "Hiking", color: "#00AA00", formats: "html|kml"},
+		{profile: "publish", title: "Base", color: "#FF0000", formats: "geojson"},
+		{profile: "empty", title: "Base", color: "#FF0000", formats: "html|kml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			cfg, err := LoadProfile(file, tt.profile)
+			if err != nil {
+				t.Fatalf("LoadProfile() error = %v", err)
+			}
+			if cfg.Map.Title != tt.title || cfg.Path.Style.Color != tt.color || strings.Join(cfg.Output.Formats, "|") != tt.formats {
+				t.Errorf("LoadProfile() title %q, color %q, formats %q", cfg.Map.Title, cfg.Path.Style.Color, cfg.Output.Formats)
+			}
+			// Settings the profile leaves alone keep their base values
+			if cfg.Map.Width != "80%" || cfg.Path.Style.Weight != 3 || cfg.Markers.Default.Icon.Anchor.Y != 7 {
+				t.Errorf("LoadProfile() width %q, weight %d, anchor y %d", cfg.Map.Width, cfg.Path.Style.Weight, cfg.Markers.Default.Icon.Anchor.Y)
+			}
+		})
+	}
+}
+
+func TestLoadProfileErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		yaml    string
+		profile string
+		want    string
+	}{
+		{name: "unknown profile", yaml: profilesYAML, profile: "driving", want: `unknown profile "driving" (available: [empty hiking publish])`},
+		{name: "no profiles", yaml: "map:\n  title: Base\n", profile: "hiking", want: `unknown profile "hiking" (available: [])`},
+		{name: "not a section", yaml: "profiles:\n  hiking: 3\n", profile: "hiking", want: `profile "hiking" must be a section of settings`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(dir, "config.yaml")
+			if err := os.WriteFile(file, []byte(tt.yaml), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadProfile(file, tt.profile); err == nil || err.Error() != tt.want {
+				t.Errorf("LoadProfile() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProfileJSON(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.json")
+	data := `{"map": {"title": "Base", "width": "80%"}, "profiles": {"driving": {"map": {"width": "100%"}}}}`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadProfile(file, "driving")
+	if err != nil {
+		t.Fatalf("LoadProfile() error = %v", err)
+	}
+	if cfg.Map.Title != "Base" || cfg.Map.Width != "100%" {
+		t.Errorf("LoadProfile() title %q, width %q", cfg.Map.Title, cfg.Map.Width)
+	}
+}
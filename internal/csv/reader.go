@@ -15,8 +15,11 @@
 package csv
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"strconv"
 	"strings"
@@ -24,6 +27,8 @@ import (
 
 	"github.com/saratily/geo-chrono/internal/config"
 	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/metrics"
+	"github.com/saratily/geo-chrono/internal/progress"
 )
 
 // Reader handles CSV file reading and parsing with configurable format support.
@@ -36,6 +41,7 @@ import (
 type Reader struct {
 	config     *config.CSVFormatConfig  // @field config CSV format configuration (columns, delimiters, etc.)
 	processing *config.ProcessingConfig // @field processing Data processing options (formats, filters, etc.)
+	progress   *progress.Bar            // @field progress Bar counting the data rows read (nil for none)
 }
 
 // NewReader creates a new CSV reader with the specified configuration.
@@ -53,18 +59,25 @@ func NewReader(csvConfig *config.CSVFormatConfig, procConfig *config.ProcessingC
 	}
 }
 
+// SetProgress reports every data row read, valid or not, to bar. The caller
+// finishes the bar once reading is done.
+func (r *Reader) SetProgress(bar *progress.Bar) {
+	r.progress = bar
+}
+
 // ReadFile reads and parses GPS points from a CSV file.
 //
 // @method ReadFile
 // @description Processes CSV file and extracts GPS tracking data
+// @param ctx context.Context Stops reading when cancelled
 // @param filename string Path to the CSV file to process
 // @return gps.Points Collection of parsed GPS points
 // @return error Error if file cannot be read or parsed
 // @throws FileNotFoundError When CSV file cannot be opened
 // @throws ParseError When CSV structure is invalid
 // @throws ValidationError When required columns are missing
-// @example points, err := reader.ReadFile("tracking.csv")
-func (r *Reader) ReadFile(filename string) (gps.Points, error) {
+// @example points, err := reader.ReadFile(ctx, "tracking.csv")
+func (r *Reader) ReadFile(ctx context.Context, filename string) (gps.Points, error) {
 	// Open the CSV file
 	file, err := os.Open(filename)
 	if err != nil {
@@ -72,8 +85,32 @@ func (r *Reader) ReadFile(filename string) (gps.Points, error) {
 	}
 	defer file.Close()
 
+	points, err := r.read(file, slog.With("file", filename))
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// Read parses GPS points from CSV data, such as an uploaded file.
+//
+// @method Read
+// @description Processes CSV data from any source, as ReadFile does for files
+// @param data io.Reader CSV data with a header row
+// @return gps.Points Collection of parsed GPS points
+// @return error Error if the data cannot be parsed or lacks required columns
+// @example points, err := reader.Read(request.Body)
+func (r *Reader) Read(data io.Reader) (gps.Points, error) {
+	return r.read(data, slog.Default())
+}
+
+// read parses CSV data, logging skipped rows to logger.
+func (r *Reader) read(data io.Reader, logger *slog.Logger) (gps.Points, error) {
 	// Configure CSV reader with appropriate delimiter
-	reader := csv.NewReader(file)
+	reader := csv.NewReader(data)
 	if r.config.Delimiter != "" {
 		reader.Comma = rune(r.config.Delimiter[0])
 	}
@@ -85,7 +122,104 @@ func (r *Reader) ReadFile(filename string) (gps.Points, error) {
 	}
 
 	// Parse records into GPS points
-	return r.parseRecords(records)
+	return r.parseRecords(records, logger)
+}
+
+// Each streams GPS points from a CSV file to fn one row at a time, stopping early if
+// fn returns false. Unlike ReadFile, the file is never held in memory as a whole.
+//
+// @method Each
+// @description Lazily parses a CSV file for processing very large datasets
+// @param ctx context.Context Stops reading when cancelled
+// @param filename string Path to the CSV file to process
+// @param fn func(gps.Point) bool Receives each parsed point; return false to stop reading
+// @return error Error if the file cannot be opened, read, or lacks required columns
+// @note Invalid rows are skipped with a warning, and duplicates are dropped when
+// @note processing.remove_duplicates is set, exactly as in ReadFile
+// @example err := reader.Each(ctx, "huge.csv", func(p gps.Point) bool { count++; return true })
+func (r *Reader) Each(ctx context.Context, filename string, fn func(gps.Point) bool) error {
+	var err error
+	seq := r.Stream(ctx, filename, &err)
+	seq(fn)
+	return err
+}
+
+// Stream returns a lazy sequence over the GPS points in a CSV file, so filters can
+// be chained without loading the file. The file is opened each time the sequence is
+// iterated; any error ends the iteration and is stored in *errp, as is ctx.Err()
+// when ctx is cancelled while reading.
+func (r *Reader) Stream(ctx context.Context, filename string, errp *error) gps.Seq {
+	seq := gps.Seq(func(yield func(gps.Point) bool) {
+		*errp = r.stream(ctx, filename, yield)
+	})
+	if r.processing.RemoveDuplicates {
+		seq = seq.Unique()
+	}
+	return seq
+}
+
+// stream reads and parses CSV rows one at a time, passing each valid point to yield.
+func (r *Reader) stream(ctx context.Context, filename string, yield func(gps.Point) bool) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("cannot open file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	if r.config.Delimiter != "" {
+		reader.Comma = rune(r.config.Delimiter[0])
+	}
+	reader.ReuseRecord = true
+
+	// Skip initial rows if configured (e.g., for metadata or comments)
+	for i := 0; i < r.config.SkipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			return fmt.Errorf("CSV file has no data rows")
+		}
+	}
+
+	// The first row determines the column positions, from its names or its width
+	first, err := reader.Read()
+	if err == io.EOF {
+		return fmt.Errorf("CSV file has no data rows")
+	} else if err != nil {
+		return fmt.Errorf("cannot read CSV: %w", err)
+	}
+	colIndices, err := r.findColumnIndices([][]string{first})
+	if err != nil {
+		return err
+	}
+
+	rowNum := 1
+	record := first
+	if r.config.HasHeader {
+		rowNum++
+		if record, err = reader.Read(); err == io.EOF {
+			return fmt.Errorf("CSV file must have at least a header and one data row")
+		}
+	}
+
+	for ; err != io.EOF; record, err = reader.Read() {
+		if err != nil {
+			return fmt.Errorf("cannot read CSV: %w", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r.progress.Add(1)
+		point, parseErr := r.parseRecord(record, colIndices, rowNum)
+		if parseErr != nil {
+			// Log warning but continue processing other rows
+			slog.Warn("Skipping row", "file", filename, "row", rowNum, "error", parseErr)
+			metrics.ParseErrors.Inc()
+		} else if !yield(*point) {
+			return nil
+		}
+		rowNum++
+	}
+	return nil
 }
 
 // parseRecords processes CSV records and converts them into GPS points.
@@ -93,11 +227,12 @@ func (r *Reader) ReadFile(filename string) (gps.Points, error) {
 // @method parseRecords
 // @description Converts raw CSV data into structured GPS points
 // @param records [][]string Raw CSV records from file
+// @param logger *slog.Logger Logger for the warnings about skipped rows
 // @return gps.Points Collection of validated GPS points
 // @return error Error if parsing or validation fails
 // @internal true
 // @steps Skip configured header rows, Detect column indices, Parse each record, Validate coordinates
-func (r *Reader) parseRecords(records [][]string) (gps.Points, error) {
+func (r *Reader) parseRecords(records [][]string, logger *slog.Logger) (gps.Points, error) {
 	// Skip initial rows if configured (e.g., for metadata or comments)
 	if r.config.SkipRows > 0 && len(records) > r.config.SkipRows {
 		records = records[r.config.SkipRows:]
@@ -126,10 +261,12 @@ func (r *Reader) parseRecords(records [][]string) (gps.Points, error) {
 	// Process each data row and convert to GPS points
 	var points gps.Points
 	for i, record := range records[startRow:] {
+		r.progress.Add(1)
 		point, err := r.parseRecord(record, colIndices, i+startRow+1)
 		if err != nil {
 			// Log warning but continue processing other rows
-			fmt.Printf("Warning: Skipping row %d - %v\n", i+startRow+1, err)
+			logger.Warn("Skipping row", "row", i+startRow+1, "error", err)
+			metrics.ParseErrors.Inc()
 			continue
 		}
 		points = append(points, *point)
@@ -155,6 +292,9 @@ type columnIndices struct {
 	longitude   int // @field longitude Column index for longitude coordinates
 	title       int // @field title Column index for location title/name (optional, -1 if not used)
 	description int // @field description Column index for location description (optional, -1 if not used)
+	category    int // @field category Column index for point category (optional, -1 if not used)
+	user        int // @field user Column index for the recording user (optional, -1 if not used)
+	elevation   int // @field elevation Column index for elevation in meters (optional, -1 if not used)
 }
 
 // findColumnIndices determines the column positions for required and optional fields.
@@ -173,6 +313,9 @@ func (r *Reader) findColumnIndices(records [][]string) (*columnIndices, error) {
 		longitude:   -1,
 		title:       -1,
 		description: -1,
+		category:    -1,
+		user:        -1,
+		elevation:   -1,
 	}
 
 	if r.config.HasHeader && len(records) > 0 {
@@ -205,10 +348,25 @@ func (r *Reader) findColumnIndices(records [][]string) (*columnIndices, error) {
 			if r.config.DescriptionColumn != "" && colLower == strings.ToLower(r.config.DescriptionColumn) {
 				indices.description = i
 			}
+
+			// Match optional category column (exact match required if configured)
+			if r.config.CategoryColumn != "" && colLower == strings.ToLower(r.config.CategoryColumn) {
+				indices.category = i
+			}
+
+			// Match optional user column (exact match required if configured)
+			if r.config.UserColumn != "" && colLower == strings.ToLower(r.config.UserColumn) {
+				indices.user = i
+			}
+
+			// Match optional elevation column (exact match required if configured)
+			if r.config.ElevationColumn != "" && colLower == strings.ToLower(r.config.ElevationColumn) {
+				indices.elevation = i
+			}
 		}
 	} else {
 		// Use default column positions when no header is present
-		// Assumed order: timestamp, latitude, longitude, [title], [description]
+		// Assumed order: timestamp, latitude, longitude, [title], [description], [category], [user]
 		indices.timestamp = 0
 		indices.latitude = 1
 		indices.longitude = 2
@@ -220,6 +378,12 @@ func (r *Reader) findColumnIndices(records [][]string) (*columnIndices, error) {
 		if len(records) > 0 && len(records[0]) > 4 {
 			indices.description = 4
 		}
+		if len(records) > 0 && len(records[0]) > 5 {
+			indices.category = 5
+		}
+		if len(records) > 0 && len(records[0]) > 6 {
+			indices.user = 6
+		}
 	}
 
 	// Validate that all required columns were found
@@ -307,6 +471,27 @@ func (r *Reader) parseRecord(record []string, indices *columnIndices, rowNum int
 		point.Description = strings.TrimSpace(record[indices.description])
 	}
 
+	// Add optional category field if configured and present in the record
+	if indices.category != -1 && indices.category < len(record) {
+		point.Category = strings.TrimSpace(record[indices.category])
+	}
+
+	// Add optional user field if configured and present in the record
+	if indices.user != -1 && indices.user < len(record) {
+		point.User = strings.TrimSpace(record[indices.user])
+	}
+
+	// Add optional elevation if configured; empty cells leave the elevation unknown
+	if indices.elevation != -1 && indices.elevation < len(record) {
+		if value := strings.TrimSpace(record[indices.elevation]); value != "" {
+			ele, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid elevation '%s': %w", record[indices.elevation], err)
+			}
+			point.Elevation = ele
+		}
+	}
+
 	return point, nil
 }
 
@@ -5,11 +5,16 @@
 package csv
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/progress"
 )
 
 func TestNewReader(t *testing.T) {
@@ -169,7 +174,7 @@ timestamp,latitude,longitude
 
 			// Test ReadFile
 			reader := NewReader(tt.csvConfig, tt.procConfig)
-			points, err := reader.ReadFile(csvFile)
+			points, err := reader.ReadFile(context.Background(), csvFile)
 
 			if tt.wantErr {
 				if err == nil {
@@ -198,7 +203,7 @@ timestamp,latitude,longitude
 
 func TestReaderReadFileNonExistent(t *testing.T) {
 	reader := NewReader(&config.CSVFormatConfig{}, &config.ProcessingConfig{})
-	_, err := reader.ReadFile("non-existent-file.csv")
+	_, err := reader.ReadFile(context.Background(), "non-existent-file.csv")
 
 	if err == nil {
 		t.Error("ReadFile() should return error for non-existent file")
@@ -512,6 +517,7 @@ func TestParseRecord(t *testing.T) {
 		longitude:   2,
 		title:       3,
 		description: 4,
+		elevation:   -1,
 	}
 
 	tests := []struct {
@@ -593,3 +599,246 @@ func TestParseRecord(t *testing.T) {
 		})
 	}
 }
+
+func TestReaderReadFileCategory(t *testing.T) {
+	csvContent := `timestamp,latitude,longitude,title,category
+2025-10-28T10:00:00Z,37.7749,-122.4194,Diner,food
+2025-10-28T11:00:00Z,37.8044,-122.2711,Gas Station, fuel stop `
+
+	tmpFile := filepath.Join(t.TempDir(), "category.csv")
+	if err := os.WriteFile(tmpFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	reader := NewReader(&config.CSVFormatConfig{
+		HasHeader:      true,
+		TitleColumn:    "title",
+		CategoryColumn: "category",
+	}, &config.ProcessingConfig{})
+
+	points, err := reader.ReadFile(context.Background(), tmpFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("ReadFile() got %d points, want 2", len(points))
+	}
+	if points[0].Category != "food" {
+		t.Errorf("ReadFile() first category = %q, want %q", points[0].Category, "food")
+	}
+	if points[1].Category != "fuel stop" {
+		t.Errorf("ReadFile() second category = %q, want %q", points[1].Category, "fuel stop")
+	}
+}
+
+func TestReaderReadFileUser(t *testing.T) {
+	csvContent := `timestamp,latitude,longitude,user
+2025-10-28T10:00:00Z,37.7749,-122.4194,alice
+2025-10-28T10:00:00Z,37.7750,-122.4195, bob `
+
+	tmpFile := filepath.Join(t.TempDir(), "user.csv")
+	if err := os.WriteFile(tmpFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	reader := NewReader(&config.CSVFormatConfig{
+		HasHeader:  true,
+		UserColumn: "user",
+	}, &config.ProcessingConfig{})
+
+	points, err := reader.ReadFile(context.Background(), tmpFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("ReadFile() got %d points, want 2", len(points))
+	}
+	if points[0].User != "alice" {
+		t.Errorf("ReadFile() first user = %q, want %q", points[0].User, "alice")
+	}
+	if points[1].User != "bob" {
+		t.Errorf("ReadFile() second user = %q, want %q", points[1].User, "bob")
+	}
+}
+
+func TestReaderReadFileElevation(t *testing.T) {
+	csvContent := `timestamp,latitude,longitude,altitude
+2025-10-28T10:00:00Z,37.7749,-122.4194,152.5
+2025-10-28T10:01:00Z,37.7750,-122.4195,
+2025-10-28T10:02:00Z,37.7751,-122.4196,high`
+
+	tmpFile := filepath.Join(t.TempDir(), "elevation.csv")
+	if err := os.WriteFile(tmpFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	reader := NewReader(&config.CSVFormatConfig{
+		HasHeader:       true,
+		ElevationColumn: "altitude",
+	}, &config.ProcessingConfig{})
+
+	points, err := reader.ReadFile(context.Background(), tmpFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	// The row with an unparseable elevation is skipped like any other invalid row
+	if len(points) != 2 {
+		t.Fatalf("ReadFile() got %d points, want 2", len(points))
+	}
+	if points[0].Elevation != 152.5 {
+		t.Errorf("ReadFile() first elevation = %v, want 152.5", points[0].Elevation)
+	}
+	if points[1].Elevation != 0 {
+		t.Errorf("ReadFile() empty elevation = %v, want 0", points[1].Elevation)
+	}
+}
+
+func TestReaderRead(t *testing.T) {
+	data := "timestamp;latitude;longitude;title\n2025-10-28T10:00:00Z;37.7749;-122.4194;Start\n2025-10-28T10:05:00Z;37.7760;-122.4200;End\n"
+	reader := NewReader(&config.CSVFormatConfig{HasHeader: true, Delimiter: ";", TitleColumn: "title"}, &config.ProcessingConfig{})
+
+	points, err := reader.Read(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(points) != 2 || points[1].Title != "End" {
+		t.Errorf("Read() = %+v, want 2 points ending at End", points)
+	}
+
+	if _, err := reader.Read(strings.NewReader("name,notes\nfoo,bar\n")); err == nil {
+		t.Error("Read() accepted data without coordinate columns")
+	}
+}
+
+func TestReaderEach(t *testing.T) {
+	csvContent := `exported,by,tracker
+timestamp,latitude,longitude
+2025-10-28T10:00:00Z,37.7749,-122.4194
+not-a-time,37.7750,-122.4195
+2025-10-28T10:02:00Z,37.7749,-122.4194
+2025-10-28T10:03:00Z,37.7760,-122.4200
+2025-10-28T10:04:00Z,37.7770,-122.4210`
+
+	tmpFile := filepath.Join(t.TempDir(), "stream.csv")
+	if err := os.WriteFile(tmpFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	format := &config.CSVFormatConfig{HasHeader: true, SkipRows: 1}
+	tests := []struct {
+		name       string
+		processing *config.ProcessingConfig
+		stopAfter  int
+		want       int
+	}{
+		{name: "all valid rows", processing: &config.ProcessingConfig{}, want: 4},
+		{name: "duplicates removed", processing: &config.ProcessingConfig{RemoveDuplicates: true}, want: 3},
+		{name: "stop early", processing: &config.ProcessingConfig{}, stopAfter: 2, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(format, tt.processing)
+
+			var got int
+			err := reader.Each(context.Background(), tmpFile, func(gps.Point) bool {
+				got++
+				return tt.stopAfter == 0 || got < tt.stopAfter
+			})
+			if err != nil {
+				t.Fatalf("Each() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Each() yielded %d points, want %d", got, tt.want)
+			}
+
+			// Streaming must agree with reading the whole file
+			if tt.stopAfter == 0 {
+				points, err := reader.ReadFile(context.Background(), tmpFile)
+				if err != nil || len(points) != tt.want {
+					t.Errorf("ReadFile() = %d points, %v, want %d", len(points), err, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReaderStream(t *testing.T) {
+	csvContent := `2025-10-28T10:00:00Z,37.7749,-122.4194,Start
+2025-10-28T10:01:00Z,37.8044,-122.2711,Oakland`
+
+	tmpFile := filepath.Join(t.TempDir(), "noheader.csv")
+	if err := os.WriteFile(tmpFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	reader := NewReader(&config.CSVFormatConfig{}, &config.ProcessingConfig{})
+	var err error
+	points := reader.Stream(context.Background(), tmpFile, &err).
+		Filter(func(p gps.Point) bool { return p.Longitude > -122.3 }).
+		Collect()
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if len(points) != 1 || points[0].Title != "Oakland" {
+		t.Errorf("Stream() filtered points = %+v, want only Oakland", points)
+	}
+
+	reader.Stream(context.Background(), filepath.Join(t.TempDir(), "missing.csv"), &err).Collect()
+	if err == nil {
+		t.Error("Stream() on missing file error = nil, want error")
+	}
+
+	// Cancelling stops the iteration at the next row
+	ctx, cancel := context.WithCancel(context.Background())
+	count := 0
+	reader.Stream(ctx, tmpFile, &err)(func(gps.Point) bool {
+		count++
+		cancel()
+		return true
+	})
+	if !errors.Is(err, context.Canceled) || count != 1 {
+		t.Errorf("Stream() after cancel = %d points, %v, want 1 and context.Canceled", count, err)
+	}
+
+	headerOnly := filepath.Join(t.TempDir(), "header.csv")
+	if err := os.WriteFile(headerOnly, []byte("timestamp,latitude,longitude\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+	header := NewReader(&config.CSVFormatConfig{HasHeader: true}, &config.ProcessingConfig{})
+	if err := header.Each(context.Background(), headerOnly, func(gps.Point) bool { return true }); err == nil {
+		t.Error("Each() on header-only file error = nil, want error")
+	}
+}
+
+func TestReaderProgress(t *testing.T) {
+	csvContent := `timestamp,latitude,longitude
+2025-10-28T10:00:00Z,37.7749,-122.4194
+not a time,37.7750,-122.4195
+2025-10-28T10:02:00Z,37.7751,-122.4196`
+
+	tmpFile := filepath.Join(t.TempDir(), "progress.csv")
+	if err := os.WriteFile(tmpFile, []byte(csvContent), 0644); err != nil {
+		t.Fatalf("Failed to create test CSV file: %v", err)
+	}
+
+	// Every data row counts, including the one skipped as invalid
+	for name, read := range map[string]func(*Reader) error{
+		"ReadFile": func(r *Reader) error { _, err := r.ReadFile(context.Background(), tmpFile); return err },
+		"Each": func(r *Reader) error {
+			return r.Each(context.Background(), tmpFile, func(gps.Point) bool { return true })
+		},
+	} {
+		var out strings.Builder
+		bar := progress.New(&out, "Reading", 0, "rows")
+		bar.Delay = 0
+		reader := NewReader(&config.CSVFormatConfig{HasHeader: true}, &config.ProcessingConfig{})
+		reader.SetProgress(bar)
+		if err := read(reader); err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+		if got := bar.String(); got != "Reading: 3 rows" {
+			t.Errorf("%s() progress = %q, want 3 rows", name, got)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/geojson"
+	"github.com/saratily/geo-chrono/internal/gpx"
+	"github.com/saratily/geo-chrono/internal/kml"
+	"github.com/saratily/geo-chrono/internal/mapgen"
+)
+
+// downloadFormat is a track format the map page offers for download.
+type downloadFormat struct {
+	name      string                            // Format name
+	label     string                            // Button text
+	mediaType string                            // Media type of embedded data URLs
+	encode    func(job *Job, w io.Writer) error // Writes the file content for embedding
+}
+
+// downloadFormats lists the downloadable track formats in button order.
+var downloadFormats = []downloadFormat{
+	{
+		name:      FormatGPX,
+		label:     "Download GPX",
+		mediaType: "application/gpx+xml",
+		encode:    func(job *Job, w io.Writer) error { return gpx.Write(w, job.Points, job.Config.Map.Title) },
+	},
+	{
+		name:      FormatKML,
+		label:     "Download KML",
+		mediaType: "application/vnd.google-earth.kml+xml",
+		encode:    func(job *Job, w io.Writer) error { return kml.Write(w, job.Points, job.Config) },
+	},
+	{
+		name:      FormatGeoJSON,
+		label:     "Download GeoJSON",
+		mediaType: "application/geo+json",
+		encode:    func(job *Job, w io.Writer) error { return geojson.Write(w, job.Points, job.Config.Map.Title) },
+	},
+}
+
+// downloads returns the download buttons of the map written to htmlFile: one per
+// GPX, KML, or GeoJSON file written in the same run, when output.downloads is set.
+// The buttons link to the files relative to the page, except on self-contained
+// pages, which embed the files as data URLs so the page remains a single file.
+//
+// @function downloads
+// @description Builds the map's download buttons for the exported track files
+// @param htmlFile string Path the map page is written to
+// @return []mapgen.Download Buttons in GPX, KML, GeoJSON order (nil when disabled)
+// @return error Error if a file cannot be encoded for embedding
+// @internal true
+func (job *Job) downloads(htmlFile string) ([]mapgen.Download, error) {
+	if !job.Config.Output.Downloads {
+		return nil, nil
+	}
+
+	var downloads []mapgen.Download
+	for _, format := range downloadFormats {
+		if !slices.Contains(job.formats, format.name) {
+			continue
+		}
+		file := Filename(format.name, job.Config)
+		download := mapgen.Download{Label: format.label, File: filepath.Base(file)}
+
+		if job.Config.Output.SelfContained {
+			var buf bytes.Buffer
+			if err := format.encode(job, &buf); err != nil {
+				return nil, fmt.Errorf("cannot embed %s download: %w", formats[format.name].Description, err)
+			}
+			download.URL = template.URL("data:" + format.mediaType + ";base64," + base64.StdEncoding.EncodeToString(buf.Bytes()))
+		} else {
+			href, err := relativeLink(htmlFile, file)
+			if err != nil {
+				return nil, err
+			}
+			download.URL = template.URL(href)
+		}
+		downloads = append(downloads, download)
+	}
+	return downloads, nil
+}
+
+// relativeLink returns the URL path of file relative to the directory of page.
+func relativeLink(page, file string) (string, error) {
+	dir, err := filepath.Abs(filepath.Dir(page))
+	if err != nil {
+		return "", err
+	}
+	target, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(dir, target)
+	if err != nil {
+		return "", fmt.Errorf("cannot link %s from %s: %w", file, page, err)
+	}
+
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/"), nil
+}
@@ -0,0 +1,423 @@
+// Package export provides a registry of output formats written from a single
+// processed track.
+//
+// @title Export Package
+// @version 1.0
+// @description Writes every requested output format from one parsed dataset
+// @description Formats are pluggable behind the Format registry
+//
+// Features:
+// - Built-in html, kml, kmz, geojson, gpx, stats, image, xlsx, and embed formats
+// - Download buttons on the map for the track files written with it
+// - Per-day map pages with an index for multi-day trips
+// - Output paths from configuration or derived from the HTML file name
+// - Comma-separated format lists for the -export flag
+// - Exporter interface and registry for additional formats
+package export
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/geojson"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/gpx"
+	"github.com/saratily/geo-chrono/internal/kml"
+	"github.com/saratily/geo-chrono/internal/mapgen"
+	"github.com/saratily/geo-chrono/internal/staticmap"
+	"github.com/saratily/geo-chrono/internal/stats"
+	"github.com/saratily/geo-chrono/internal/tiles"
+	"github.com/saratily/geo-chrono/internal/weather"
+	"github.com/saratily/geo-chrono/internal/xlsx"
+)
+
+// Names of the built-in formats.
+const (
+	FormatHTML    = "html"
+	FormatKML     = "kml"
+	FormatKMZ     = "kmz"
+	FormatGeoJSON = "geojson"
+	FormatGPX     = "gpx"
+	FormatStats   = "stats"
+	FormatImage   = "image"
+	FormatXLSX    = "xlsx"
+	FormatEmbed   = "embed"
+	FormatPages   = "pages"
+)
+
+// Job holds the processed data shared by every format written in a run, so the
+// input is parsed and analyzed only once.
+//
+// @struct Job
+// @description Processed track and settings passed to each exporter
+// @property Points gps.Points Processed, chronologically ordered GPS points
+// @property Reference gps.Points Reference route for comparison (nil when not comparing)
+// @property Summary stats.Summary Route statistics, including deviation when comparing
+// @property Config config.Config Complete configuration
+// @property Weather weather.Report Historical weather during the track (nil when disabled)
+// @property Tiles tiles.Bundle Map tiles embedded in leaflet maps (nil when loaded online)
+type Job struct {
+	Points    gps.Points      // @field Points Processed GPS points
+	Reference gps.Points      // @field Reference Reference route (nil when not comparing)
+	Summary   *stats.Summary  // @field Summary Route statistics
+	Config    *config.Config  // @field Config Complete configuration
+	Weather   *weather.Report // @field Weather Historical weather during the track (nil when disabled)
+	Tiles     *tiles.Bundle   // @field Tiles Map tiles embedded in leaflet maps (nil when loaded online)
+
+	formats []string // Formats written in the current run, for the map's download buttons
+}
+
+// Exporter writes a job in one format to a file. Exporters calling external
+// services, such as the Static Maps API, stop when ctx is cancelled.
+type Exporter interface {
+	Export(ctx context.Context, job *Job, filename string) error
+}
+
+// ExporterFunc adapts a function writing a job to a file to an Exporter.
+type ExporterFunc func(ctx context.Context, job *Job, filename string) error
+
+// Export calls f(ctx, job, filename).
+func (f ExporterFunc) Export(ctx context.Context, job *Job, filename string) error {
+	return f(ctx, job, filename)
+}
+
+// Format describes a registered output format.
+//
+// @struct Format
+// @description Output format with its file naming and writer
+// @property Description string Human-readable name used in progress messages
+// @property Extension string Suffix replacing the HTML file extension for derived paths
+// @property File func(*config.Config) string Configured output path ("" to derive one)
+// @property Write Exporter Writes the job to a file
+type Format struct {
+	Description string                      // @field Description Name used in "written to" messages
+	Extension   string                      // @field Extension Suffix for derived file names, such as ".kml"
+	File        func(*config.Config) string // @field File Configured output path (nil or "" to derive one)
+	Write       Exporter                    // @field Write Writes the job to a file
+}
+
+// formats maps format names to their definitions.
+var formats = map[string]Format{}
+
+func init() {
+	Register(FormatHTML, Format{
+		Description: "Map",
+		Extension:   ".html",
+		File:        func(cfg *config.Config) string { return cfg.Output.HTMLFile },
+		Write:       ExporterFunc(writeHTML),
+	})
+	Register(FormatKML, Format{
+		Description: "KML",
+		Extension:   ".kml",
+		File:        func(cfg *config.Config) string { return cfg.Output.KMLFile },
+		Write: ExporterFunc(func(_ context.Context, job *Job, filename string) error {
+			return kml.WriteFile(filename, job.Points, job.Config)
+		}),
+	})
+	Register(FormatKMZ, Format{
+		Description: "KMZ",
+		Extension:   ".kmz",
+		Write:       ExporterFunc(writeKMZ),
+	})
+	Register(FormatGeoJSON, Format{
+		Description: "GeoJSON",
+		Extension:   ".geojson",
+		File:        func(cfg *config.Config) string { return cfg.Output.GeoJSONFile },
+		Write: ExporterFunc(func(_ context.Context, job *Job, filename string) error {
+			return geojson.WriteFile(filename, job.Points, job.Config.Map.Title)
+		}),
+	})
+	Register(FormatGPX, Format{
+		Description: "GPX",
+		Extension:   ".gpx",
+		File:        func(cfg *config.Config) string { return cfg.Output.GPXFile },
+		Write: ExporterFunc(func(_ context.Context, job *Job, filename string) error {
+			return gpx.WriteFile(filename, job.Points, job.Config.Map.Title)
+		}),
+	})
+	Register(FormatStats, Format{
+		Description: "Statistics",
+		Extension:   ".stats.json",
+		File:        func(cfg *config.Config) string { return cfg.Output.StatsFile },
+		Write:       ExporterFunc(writeStats),
+	})
+	Register(FormatImage, Format{
+		Description: "Map image",
+		Extension:   ".png",
+		File:        func(cfg *config.Config) string { return cfg.Output.Image.File },
+		Write: ExporterFunc(func(ctx context.Context, job *Job, filename string) error {
+			return staticmap.WriteFile(ctx, filename, job.Points, job.Config)
+		}),
+	})
+	Register(FormatXLSX, Format{
+		Description: "Excel report",
+		Extension:   ".xlsx",
+		File:        func(cfg *config.Config) string { return cfg.Output.XLSXFile },
+		Write: ExporterFunc(func(_ context.Context, job *Job, filename string) error {
+			return xlsx.WriteFile(filename, job.Points, job.summary(), job.Config)
+		}),
+	})
+	Register(FormatPages, Format{
+		Description: "Daily pages",
+		Extension:   "_days",
+		File:        func(cfg *config.Config) string { return cfg.Output.PagesDir },
+		Write:       ExporterFunc(writePages),
+	})
+	Register(FormatEmbed, Format{
+		Description: "Embed snippet",
+		Extension:   ".embed.js",
+		Write:       ExporterFunc(writeEmbed),
+	})
+}
+
+// Register makes an output format available under a name for -export and
+// output.formats. Registering the same name twice replaces the earlier format.
+//
+// @function Register
+// @description Adds a pluggable output format
+// @param name string Format name used in -export and output.formats
+// @param format Format File naming and writer for the format
+// @example export.Register("csv", export.Format{Description: "CSV", Extension: ".csv", Write: export.ExporterFunc(writeCSV)})
+func Register(name string, format Format) {
+	formats[name] = format
+}
+
+// Names returns the names of all registered formats in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse splits a comma-separated format list such as "html,kml,geojson,stats",
+// dropping blanks and repeats. Every name must be registered.
+//
+// @function Parse
+// @description Validates a comma-separated list of format names
+// @param list string Comma-separated format names
+// @return []string Format names in the given order
+// @return error Error naming the first unknown format
+// @example names, err := export.Parse("html,kml")
+func Parse(list string) ([]string, error) {
+	return Validate(strings.Split(list, ","))
+}
+
+// Validate trims and checks a list of format names, dropping blanks and repeats.
+// A format may also be named by its file extension, such as "stats.json".
+func Validate(names []string) ([]string, error) {
+	var result []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = byExtension(strings.ToLower(strings.TrimSpace(name)))
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := formats[name]; !ok {
+			return nil, fmt.Errorf("unknown export format %q (available: %s)", name, strings.Join(Names(), ", "))
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result, nil
+}
+
+// byExtension maps a name matching a format's extension without the leading dot,
+// such as "stats.json", to that format's name. Other names are returned unchanged.
+func byExtension(name string) string {
+	if _, ok := formats[name]; ok || name == "" {
+		return name
+	}
+	for _, format := range Names() {
+		if formats[format].Extension == "."+name {
+			return format
+		}
+	}
+	return name
+}
+
+// Configured returns the formats enabled by the configuration: output.formats when
+// set, otherwise the HTML map plus every output whose file is configured.
+func Configured(cfg *config.Config) ([]string, error) {
+	if len(cfg.Output.Formats) > 0 {
+		return Validate(cfg.Output.Formats)
+	}
+
+	names := []string{FormatHTML}
+	if cfg.Output.Widget {
+		names = append(names, FormatEmbed)
+	}
+	if cfg.Output.StatsFile != "" {
+		names = append(names, FormatStats)
+	}
+	if cfg.Output.ExportKML && cfg.Output.KMLFile != "" {
+		names = append(names, FormatKML)
+	}
+	if cfg.Output.GeoJSONFile != "" {
+		names = append(names, FormatGeoJSON)
+	}
+	if cfg.Output.GPXFile != "" {
+		names = append(names, FormatGPX)
+	}
+	if cfg.Output.Image.File != "" {
+		names = append(names, FormatImage)
+	}
+	if cfg.Output.XLSXFile != "" {
+		names = append(names, FormatXLSX)
+	}
+	if cfg.Output.PagesDir != "" {
+		names = append(names, FormatPages)
+	}
+	return names, nil
+}
+
+// Filename returns the output path for a format: its configured file, or the HTML
+// file name with the format's extension.
+func Filename(name string, cfg *config.Config) string {
+	format := formats[name]
+	if format.File != nil {
+		if file := format.File(cfg); file != "" {
+			return file
+		}
+	}
+	html := cfg.Output.HTMLFile
+	return strings.TrimSuffix(html, filepath.Ext(html)) + format.Extension
+}
+
+// Output records a file written by Run.
+type Output struct {
+	Format      string // Format name
+	Description string // Human-readable format name
+	File        string // Path of the written file
+	Backup      string // Path the previous file was moved to ("" when none was replaced)
+}
+
+// Run writes every named format for the job, in order, stopping at the first error
+// or when ctx is cancelled. Existing files are handled by output.overwrite: by
+// default nothing is written if any of them exists (see PrepareOutput).
+//
+// @function Run
+// @description Writes several output formats from one processed track
+// @param ctx context.Context Stops the run before the next format when cancelled
+// @param names []string Format names (see Parse and Configured)
+// @param job *Job Processed track and configuration
+// @return []Output Files written before any error
+// @return error Error naming the format that failed or the file that exists
+// @example outputs, err := export.Run(ctx, []string{"html", "kml"}, job)
+func Run(ctx context.Context, names []string, job *Job) ([]Output, error) {
+	files := make([]string, len(names))
+	for i, name := range names {
+		if _, ok := formats[name]; !ok {
+			return nil, fmt.Errorf("unknown export format %q", name)
+		}
+		files[i] = Filename(name, job.Config)
+	}
+
+	policy := job.Config.Output.Overwrite
+	if err := CheckOutputs(files, policy); err != nil {
+		return nil, err
+	}
+
+	job.formats = names
+	var outputs []Output
+	for i, name := range names {
+		if err := ctx.Err(); err != nil {
+			return outputs, err
+		}
+		format := formats[name]
+		backup, err := PrepareOutput(files[i], policy)
+		if err != nil {
+			return outputs, err
+		}
+		if err := format.Write.Export(ctx, job, files[i]); err != nil {
+			return outputs, fmt.Errorf("cannot write %s: %w", format.Description, err)
+		}
+		outputs = append(outputs, Output{Format: name, Description: format.Description, File: files[i], Backup: backup})
+	}
+	return outputs, nil
+}
+
+// writeHTML renders the interactive map, including the reference route and the
+// download buttons for the track files written in the same run.
+func writeHTML(ctx context.Context, job *Job, filename string) error {
+	downloads, err := job.downloads(filename)
+	if err != nil {
+		return err
+	}
+	generator := mapgen.NewGenerator(job.Config)
+	generator.SetReference(job.Reference)
+	generator.SetDownloads(downloads)
+	generator.SetWeather(job.Weather)
+	generator.SetTiles(job.Tiles)
+	return generator.Generate(ctx, job.Points, filename)
+}
+
+// summary returns the job's route statistics, computing them if the job has none.
+func (job *Job) summary() *stats.Summary {
+	if job.Summary == nil {
+		job.Summary = stats.Compute(job.Points, &job.Config.Statistics)
+	}
+	return job.Summary
+}
+
+// writePages writes one map per day plus an index into the directory named filename.
+func writePages(ctx context.Context, job *Job, filename string) error {
+	generator := mapgen.NewGenerator(job.Config)
+	generator.SetReference(job.Reference)
+	generator.SetWeather(job.Weather)
+	generator.SetTiles(job.Tiles)
+	_, err := generator.GeneratePages(ctx, job.Points, filename)
+	return err
+}
+
+// writeEmbed writes the script embedding the HTML map, which is expected next to
+// it under the configured name.
+func writeEmbed(_ context.Context, job *Job, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("cannot create embed snippet %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	if err := mapgen.WriteEmbed(file, Filename(FormatHTML, job.Config), filename, job.Config); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// writeKMZ packages the KML document with its marker icons, whatever the file's extension.
+func writeKMZ(_ context.Context, job *Job, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("cannot create KMZ file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	if err := kml.WriteKMZ(file, job.Points, job.Config); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// writeStats exports the route statistics.
+func writeStats(_ context.Context, job *Job, filename string) error {
+	summary := job.summary()
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("cannot create statistics file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	if err := summary.WriteJSON(file); err != nil {
+		return err
+	}
+	return file.Close()
+}
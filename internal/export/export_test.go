@@ -0,0 +1,227 @@
+// Package export_test provides unit tests for the export format registry.
+// It tests format list parsing, configured defaults, output file naming, and
+// writing several formats from one processed track.
+package export
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		list    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "single", list: "html", want: []string{"html"}},
+		{name: "several", list: "html,kml,geojson,stats", want: []string{"html", "kml", "geojson", "stats"}},
+		{name: "spaces, case, and repeats", list: " KML , html,kml,", want: []string{"kml", "html"}},
+		{name: "by extension", list: "stats.json,png,stats", want: []string{"stats", "image"}},
+		{name: "unknown", list: "html,shapefile", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.list)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigured(t *testing.T) {
+	tests := []struct {
+		name   string
+		output config.OutputConfig
+		want   []string
+	}{
+		{name: "html only", want: []string{"html"}},
+		{
+			name:   "configured files",
+			output: config.OutputConfig{StatsFile: "s.json", ExportKML: true, KMLFile: "t.kml", GeoJSONFile: "t.geojson", GPXFile: "t.gpx", XLSXFile: "t.xlsx"},
+			want:   []string{"html", "stats", "kml", "geojson", "gpx", "xlsx"},
+		},
+		{
+			name:   "widget with embed snippet",
+			output: config.OutputConfig{Widget: true},
+			want:   []string{"html", "embed"},
+		},
+		{
+			name:   "kml file without export_kml",
+			output: config.OutputConfig{KMLFile: "t.kml"},
+			want:   []string{"html"},
+		},
+		{
+			name:   "explicit formats",
+			output: config.OutputConfig{Formats: []string{"geojson", "stats"}, KMLFile: "t.kml", ExportKML: true},
+			want:   []string{"geojson", "stats"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Configured(&config.Config{Output: tt.output})
+			if err != nil {
+				t.Fatalf("Configured() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Configured() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilename(t *testing.T) {
+	cfg := &config.Config{Output: config.OutputConfig{HTMLFile: "out/trip.html", KMLFile: "earth/trip.kml"}}
+	tests := map[string]string{
+		FormatHTML:    "out/trip.html",
+		FormatKML:     "earth/trip.kml",
+		FormatKMZ:     "out/trip.kmz",
+		FormatGeoJSON: "out/trip.geojson",
+		FormatStats:   "out/trip.stats.json",
+		FormatImage:   "out/trip.png",
+		FormatXLSX:    "out/trip.xlsx",
+		FormatEmbed:   "out/trip.embed.js",
+	}
+	for format, want := range tests {
+		if got := Filename(format, cfg); got != want {
+			t.Errorf("Filename(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.7749, Longitude: -122.4194},
+		{Timestamp: start.Add(10 * time.Minute), Latitude: 37.7849, Longitude: -122.4094},
+	}
+	dir := t.TempDir()
+	job := &Job{
+		Points: points,
+		Config: &config.Config{
+			Map:    config.MapConfig{Title: "Run"},
+			Output: config.OutputConfig{HTMLFile: filepath.Join(dir, "map.html")},
+			Path:   config.PathConfig{Style: config.PathStyleConfig{Color: "#FF0000", Opacity: 1, Weight: 2}},
+		},
+	}
+
+	outputs, err := Run(context.Background(), []string{"html", "kml", "kmz", "geojson", "gpx", "stats", "image", "xlsx"}, job)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(outputs) != 8 {
+		t.Fatalf("Run() wrote %d outputs, want 8", len(outputs))
+	}
+	for _, output := range outputs {
+		info, err := os.Stat(output.File)
+		if err != nil || info.Size() == 0 {
+			t.Errorf("Run() %s output %s missing or empty: %v", output.Format, output.File, err)
+		}
+	}
+}
+
+func TestRunDownloads(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.7749, Longitude: -122.4194},
+		{Timestamp: start.Add(10 * time.Minute), Latitude: 37.7849, Longitude: -122.4094},
+	}
+	tests := []struct {
+		name    string
+		names   []string
+		output  config.OutputConfig
+		want    []string
+		notWant []string
+	}{
+		{
+			name:    "links to sibling files",
+			names:   []string{"html", "gpx", "geojson", "stats"},
+			output:  config.OutputConfig{Downloads: true, GeoJSONFile: "data/my track.geojson"},
+			want:    []string{`href="map.gpx" download="map.gpx">Download GPX</a>`, `href="data/my%20track.geojson" download="my track.geojson">Download GeoJSON</a>`},
+			notWant: []string{"Download KML", "Download stats"},
+		},
+		{
+			name:    "self-contained pages embed data URLs",
+			names:   []string{"html", "kml"},
+			output:  config.OutputConfig{Downloads: true, SelfContained: true},
+			want:    []string{`href="data:application/vnd.google-earth.kml`, `download="map.kml">Download KML</a>`},
+			notWant: []string{`href="map.kml"`},
+		},
+		{
+			name:    "disabled",
+			names:   []string{"html", "gpx"},
+			notWant: []string{`class="downloads"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.output.HTMLFile = filepath.Join(dir, "map.html")
+			if tt.output.GeoJSONFile != "" {
+				tt.output.GeoJSONFile = filepath.Join(dir, tt.output.GeoJSONFile)
+				if err := os.MkdirAll(filepath.Dir(tt.output.GeoJSONFile), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			job := &Job{
+				Points: points,
+				Config: &config.Config{
+					Map:    config.MapConfig{Title: "Run"},
+					Output: tt.output,
+					Path:   config.PathConfig{Style: config.PathStyleConfig{Color: "#FF0000", Opacity: 1, Weight: 2}},
+				},
+			}
+			if _, err := Run(context.Background(), tt.names, job); err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+
+			html, err := os.ReadFile(tt.output.HTMLFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(html), want) {
+					t.Errorf("map page missing %q", want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(string(html), notWant) {
+					t.Errorf("map page contains %q", notWant)
+				}
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	var got string
+	Register("test", Format{Extension: ".txt", Write: ExporterFunc(func(_ context.Context, job *Job, filename string) error {
+		got = filename
+		return nil
+	})})
+	defer delete(formats, "test")
+
+	cfg := &config.Config{Output: config.OutputConfig{HTMLFile: "map.html"}}
+	if _, err := Run(context.Background(), []string{"test"}, &Job{Config: cfg}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got != "map.txt" {
+		t.Errorf("registered exporter wrote %q, want map.txt", got)
+	}
+}
@@ -0,0 +1,90 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Policies for output files that already exist (output.overwrite).
+const (
+	OverwriteRefuse = "refuse" // Fail instead of replacing the file (the default)
+	OverwriteForce  = "force"  // Replace the file
+	OverwriteBackup = "backup" // Rename the file to a timestamped backup first
+)
+
+// backupTimeFormat names backups after the modification time of the previous output.
+const backupTimeFormat = "20060102-150405"
+
+// ErrExists is returned when an output file exists and the policy refuses to replace it.
+var ErrExists = errors.New("output file already exists")
+
+// PrepareOutput applies an overwrite policy to an output path before it is written.
+// A missing file needs nothing. An existing file (or pages directory) is refused
+// with ErrExists by the default policy, left to be replaced by OverwriteForce, and
+// renamed by OverwriteBackup to a name carrying its modification time, such as
+// map.20251028-150405.html.
+//
+// @function PrepareOutput
+// @description Protects an existing output file from being silently replaced
+// @param filename string Path about to be written
+// @param policy string Overwrite policy ("" or refuse, force, backup)
+// @return string Path of the backup (empty when none was made)
+// @return error ErrExists when refusing, or an error if the backup fails
+// @example backup, err := export.PrepareOutput("map.html", export.OverwriteBackup)
+func PrepareOutput(filename, policy string) (string, error) {
+	info, err := os.Stat(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot check %s: %w", filename, err)
+	}
+
+	switch policy {
+	case OverwriteForce:
+		return "", nil
+	case OverwriteBackup:
+		backup := backupName(filename, info.ModTime().Format(backupTimeFormat))
+		if err := os.Rename(filename, backup); err != nil {
+			return "", fmt.Errorf("cannot back up %s: %w", filename, err)
+		}
+		return backup, nil
+	case "", OverwriteRefuse:
+		return "", fmt.Errorf("%w: %s (use -force to replace it or -backup to keep a copy)", ErrExists, filename)
+	default:
+		return "", fmt.Errorf("unknown overwrite policy %q (use %s, %s, or %s)", policy, OverwriteRefuse, OverwriteForce, OverwriteBackup)
+	}
+}
+
+// backupName inserts the timestamp before the file extension, adding a counter when
+// a backup with the same time already exists.
+func backupName(filename, timestamp string) string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	name := base + "." + timestamp + ext
+	for i := 2; ; i++ {
+		if _, err := os.Stat(name); errors.Is(err, os.ErrNotExist) {
+			return name
+		}
+		name = base + "." + timestamp + "-" + strconv.Itoa(i) + ext
+	}
+}
+
+// CheckOutputs fails with ErrExists if the refusing policy would stop any of the
+// files, so a run can check all of its outputs before writing the first one.
+// Other policies never stop a file and are not checked.
+func CheckOutputs(files []string, policy string) error {
+	if policy != "" && policy != OverwriteRefuse {
+		return nil
+	}
+	for _, file := range files {
+		if _, err := PrepareOutput(file, policy); err != nil {
+			return err
+		}
+	}
+	return nil
+}
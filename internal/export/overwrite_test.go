@@ -0,0 +1,123 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestPrepareOutput(t *testing.T) {
+	modTime := time.Date(2025, 10, 28, 15, 4, 5, 0, time.Local)
+
+	tests := []struct {
+		name       string
+		policy     string
+		exists     bool
+		wantBackup string
+		wantErr    error
+	}{
+		{name: "missing file", policy: OverwriteRefuse},
+		{name: "refuse by default", exists: true, wantErr: ErrExists},
+		{name: "refuse", policy: OverwriteRefuse, exists: true, wantErr: ErrExists},
+		{name: "force", policy: OverwriteForce, exists: true},
+		{name: "backup", policy: OverwriteBackup, exists: true, wantBackup: "map.20251028-150405.html"},
+		{name: "backup without existing file", policy: OverwriteBackup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "map.html")
+			if tt.exists {
+				if err := os.WriteFile(file, []byte("previous"), 0644); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chtimes(file, modTime, modTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			backup, err := PrepareOutput(file, tt.policy)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PrepareOutput() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantBackup == "" {
+				if backup != "" {
+					t.Errorf("PrepareOutput() backup = %q, want none", backup)
+				}
+				return
+			}
+
+			if want := filepath.Join(dir, tt.wantBackup); backup != want {
+				t.Fatalf("PrepareOutput() backup = %q, want %q", backup, want)
+			}
+			if content, err := os.ReadFile(backup); err != nil || string(content) != "previous" {
+				t.Errorf("backup content = %q, %v", content, err)
+			}
+			if _, err := os.Stat(file); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("output file still exists after backup: %v", err)
+			}
+		})
+	}
+}
+
+func TestBackupName(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "map.html")
+	if got, want := backupName(file, "20251028-150405"), filepath.Join(dir, "map.20251028-150405.html"); got != want {
+		t.Fatalf("backupName() = %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "map.20251028-150405.html"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := backupName(file, "20251028-150405"), filepath.Join(dir, "map.20251028-150405-2.html"); got != want {
+		t.Errorf("backupName() with existing backup = %q, want %q", got, want)
+	}
+}
+
+func TestRunOverwrite(t *testing.T) {
+	points := gps.Points{{Latitude: 37.7749, Longitude: -122.4194}, {Latitude: 37.7849, Longitude: -122.4094}}
+	dir := t.TempDir()
+	html := filepath.Join(dir, "map.html")
+	geoJSON := filepath.Join(dir, "map.geojson")
+	if err := os.WriteFile(geoJSON, []byte("previous"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	job := &Job{
+		Points: points,
+		Config: &config.Config{Output: config.OutputConfig{HTMLFile: html}},
+	}
+
+	// Refusing stops before the first format, so the missing map is not written either
+	if _, err := Run(context.Background(), []string{"html", "geojson"}, job); !errors.Is(err, ErrExists) {
+		t.Fatalf("Run() error = %v, want ErrExists", err)
+	}
+	if _, err := os.Stat(html); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Run() wrote %s despite refusing to overwrite", html)
+	}
+
+	job.Config.Output.Overwrite = OverwriteBackup
+	outputs, err := Run(context.Background(), []string{"html", "geojson"}, job)
+	if err != nil {
+		t.Fatalf("Run() with backup error = %v", err)
+	}
+	if outputs[0].Backup != "" || outputs[1].Backup == "" {
+		t.Fatalf("Run() backups = %q, %q, want only the GeoJSON file backed up", outputs[0].Backup, outputs[1].Backup)
+	}
+	if content, err := os.ReadFile(outputs[1].Backup); err != nil || string(content) != "previous" {
+		t.Errorf("backup content = %q, %v", content, err)
+	}
+
+	job.Config.Output.Overwrite = OverwriteForce
+	if _, err := Run(context.Background(), []string{"html", "geojson"}, job); err != nil {
+		t.Fatalf("Run() with force error = %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+// Package geofence provides geofence definitions and entry/exit event detection.
+//
+// @title Geofence Package
+// @version 1.0
+// @description Detects when a GPS track enters or leaves named areas
+// @description Supports circular (center and radius) and polygon fences
+//
+// Features:
+// - Circular and polygon fence definitions
+// - Configuration-driven fence construction
+// - Chronological entry/exit event detection
+package geofence
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// Fence represents a named geographical area.
+//
+// @struct Fence
+// @description Circular or polygon area used for entry/exit detection
+// @property Name string Display name for the fence
+// @property Center *gps.Point Circle center (nil for polygon fences)
+// @property Radius float64 Circle radius in meters
+// @property Polygon gps.Polygon Polygon vertices (empty for circular fences)
+type Fence struct {
+	Name    string      // @field Name Display name for the fence
+	Center  *gps.Point  // @field Center Circle center (nil for polygon fences)
+	Radius  float64     // @field Radius Circle radius in meters
+	Polygon gps.Polygon // @field Polygon Polygon vertices (empty for circular fences)
+}
+
+// IsCircle reports whether the fence is defined by a center and radius.
+func (f Fence) IsCircle() bool {
+	return f.Center != nil
+}
+
+// Contains reports whether a GPS point lies inside the fence.
+// Points exactly on a circle's boundary count as inside.
+func (f Fence) Contains(point gps.Point) bool {
+	if f.IsCircle() {
+		return f.Center.DistanceTo(point) <= f.Radius
+	}
+	return f.Polygon.Contains(point.Latitude, point.Longitude)
+}
+
+// LabelPoint returns where the fence name is drawn on the map: the center of a
+// circle, or the centroid of a polygon. Degenerate polygons with no area fall
+// back to the average of their vertices.
+func (f Fence) LabelPoint() gps.Point {
+	if f.IsCircle() {
+		return *f.Center
+	}
+	if len(f.Polygon) == 0 {
+		return gps.Point{}
+	}
+
+	// Shoelace centroid, relative to the first vertex to keep the products small
+	origin := f.Polygon[0]
+	var area, lat, lng float64
+	for i := range f.Polygon {
+		a, b := f.Polygon[i], f.Polygon[(i+1)%len(f.Polygon)]
+		x1, y1 := a.Longitude-origin.Longitude, a.Latitude-origin.Latitude
+		x2, y2 := b.Longitude-origin.Longitude, b.Latitude-origin.Latitude
+		cross := x1*y2 - x2*y1
+		area += cross
+		lng += (x1 + x2) * cross
+		lat += (y1 + y2) * cross
+	}
+	if math.Abs(area) < 1e-12 {
+		var sum gps.Point
+		for _, vertex := range f.Polygon {
+			sum.Latitude += vertex.Latitude
+			sum.Longitude += vertex.Longitude
+		}
+		n := float64(len(f.Polygon))
+		return gps.Point{Latitude: sum.Latitude / n, Longitude: sum.Longitude / n}
+	}
+	return gps.Point{
+		Latitude:  origin.Latitude + lat/(3*area),
+		Longitude: origin.Longitude + lng/(3*area),
+	}
+}
+
+// EventType identifies whether a track entered or exited a fence.
+type EventType string
+
+// Supported geofence event types.
+const (
+	Enter EventType = "enter" // Track moved from outside to inside the fence
+	Exit  EventType = "exit"  // Track moved from inside to outside the fence
+)
+
+// Event represents a single fence crossing.
+//
+// @struct Event
+// @description Timestamped geofence entry or exit
+// @property Fence string Name of the crossed fence
+// @property Type EventType Entry or exit
+// @property Timestamp time.Time Time of the first point on the new side of the boundary
+// @property Point gps.Point GPS point at which the crossing was detected
+type Event struct {
+	Fence     string    // @field Fence Name of the crossed fence
+	Type      EventType // @field Type Entry or exit
+	Timestamp time.Time // @field Timestamp When the crossing was detected
+	Point     gps.Point // @field Point GPS point at which the crossing was detected
+}
+
+// FromConfig builds fences from their configuration, validating each definition.
+//
+// @function FromConfig
+// @description Converts geofence configuration into Fence values
+// @param cfg *config.GeofencesConfig Geofence definitions
+// @return []Fence Validated fences in configuration order
+// @return error Error if a fence is unnamed, has no shape, or defines both shapes
+// @example fences, err := geofence.FromConfig(&cfg.Geofences)
+func FromConfig(cfg *config.GeofencesConfig) ([]Fence, error) {
+	var fences []Fence
+	for i, fc := range cfg.Fences {
+		if fc.Name == "" {
+			return nil, fmt.Errorf("geofence %d has no name", i+1)
+		}
+
+		fence := Fence{Name: fc.Name}
+		switch {
+		case fc.Center != nil && len(fc.Polygon) > 0:
+			return nil, fmt.Errorf("geofence %q defines both a circle and a polygon", fc.Name)
+		case fc.Center != nil:
+			if fc.Radius <= 0 {
+				return nil, fmt.Errorf("geofence %q needs a positive radius", fc.Name)
+			}
+			fence.Center = &gps.Point{Latitude: fc.Center.Latitude, Longitude: fc.Center.Longitude}
+			fence.Radius = fc.Radius
+		case len(fc.Polygon) >= 3:
+			for _, vertex := range fc.Polygon {
+				fence.Polygon = append(fence.Polygon, gps.Point{Latitude: vertex.Latitude, Longitude: vertex.Longitude})
+			}
+		default:
+			return nil, fmt.Errorf("geofence %q needs a center and radius or at least 3 polygon vertices", fc.Name)
+		}
+
+		fences = append(fences, fence)
+	}
+	return fences, nil
+}
+
+// Detect walks the GPS points in order and reports every fence entry and exit.
+//
+// @function Detect
+// @description Computes chronological entry/exit events for all fences
+// @param points gps.Points GPS points sorted by timestamp
+// @param fences []Fence Fences to test
+// @return []Event Events in chronological order (fence order breaks ties)
+// @logic A track that starts inside a fence produces an entry at its first point
+// @logic Circle membership is resolved with a spatial index instead of testing every point
+// @example events := geofence.Detect(points, fences)
+func Detect(points gps.Points, fences []Fence) []Event {
+	var events []Event
+	inside := make([]bool, len(fences))
+	members := membership(points, fences)
+
+	for p, point := range points {
+		for i, fence := range fences {
+			now := members[i][p]
+			if now == inside[i] {
+				continue
+			}
+
+			eventType := Exit
+			if now {
+				eventType = Enter
+			}
+			events = append(events, Event{
+				Fence:     fence.Name,
+				Type:      eventType,
+				Timestamp: point.Timestamp,
+				Point:     point,
+			})
+			inside[i] = now
+		}
+	}
+
+	return events
+}
+
+// membership reports, for every fence, which points lie inside it.
+// Circles query a spatial index; polygons fall back to testing each point.
+func membership(points gps.Points, fences []Fence) [][]bool {
+	var idx *gps.Index
+	members := make([][]bool, len(fences))
+	for i, fence := range fences {
+		members[i] = make([]bool, len(points))
+		if !fence.IsCircle() {
+			for p, point := range points {
+				members[i][p] = fence.Contains(point)
+			}
+			continue
+		}
+
+		if idx == nil {
+			idx = gps.NewIndex(points)
+		}
+		for _, n := range idx.Within(fence.Center.Latitude, fence.Center.Longitude, fence.Radius) {
+			members[i][n.Index] = true
+		}
+	}
+	return members
+}
@@ -0,0 +1,205 @@
+// Package geofence_test provides unit tests for geofence definitions and event detection.
+// It tests circular and polygon containment, configuration validation, and the ordering
+// of entry/exit events along a track.
+package geofence
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.GeofencesConfig
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "circle and polygon",
+			cfg: config.GeofencesConfig{Fences: []config.FenceConfig{
+				{Name: "Home", Center: &config.CoordinateConfig{Latitude: 1, Longitude: 1}, Radius: 100},
+				{Name: "Park", Polygon: []config.CoordinateConfig{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 1}, {Latitude: 1, Longitude: 1}}},
+			}},
+			want: 2,
+		},
+		{
+			name:    "missing name",
+			cfg:     config.GeofencesConfig{Fences: []config.FenceConfig{{Center: &config.CoordinateConfig{}, Radius: 10}}},
+			wantErr: true,
+		},
+		{
+			name:    "zero radius",
+			cfg:     config.GeofencesConfig{Fences: []config.FenceConfig{{Name: "A", Center: &config.CoordinateConfig{}}}},
+			wantErr: true,
+		},
+		{
+			name:    "too few vertices",
+			cfg:     config.GeofencesConfig{Fences: []config.FenceConfig{{Name: "A", Polygon: []config.CoordinateConfig{{Latitude: 0, Longitude: 0}, {Latitude: 1, Longitude: 1}}}}},
+			wantErr: true,
+		},
+		{
+			name: "both shapes",
+			cfg: config.GeofencesConfig{Fences: []config.FenceConfig{{
+				Name:    "A",
+				Center:  &config.CoordinateConfig{},
+				Radius:  10,
+				Polygon: []config.CoordinateConfig{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 1}, {Latitude: 1, Longitude: 1}},
+			}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fences, err := FromConfig(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(fences) != tt.want {
+				t.Errorf("FromConfig() returned %d fences, want %d", len(fences), tt.want)
+			}
+		})
+	}
+}
+
+func TestFenceContains(t *testing.T) {
+	circle := Fence{Name: "Circle", Center: &gps.Point{Latitude: 0, Longitude: 0}, Radius: 200}
+	square := Fence{Name: "Square", Polygon: gps.Polygon{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 1},
+		{Latitude: 1, Longitude: 1},
+		{Latitude: 1, Longitude: 0},
+	}}
+
+	if !circle.Contains(gps.Point{Latitude: 0.001, Longitude: 0}) {
+		t.Error("circle should contain a point ~111 m from its center")
+	}
+	if circle.Contains(gps.Point{Latitude: 0.003, Longitude: 0}) {
+		t.Error("circle should not contain a point ~333 m from its center")
+	}
+	if !square.Contains(gps.Point{Latitude: 0.5, Longitude: 0.5}) {
+		t.Error("square should contain its center")
+	}
+	if square.IsCircle() || !circle.IsCircle() {
+		t.Error("IsCircle() misidentified fence shapes")
+	}
+}
+
+func TestFenceLabelPoint(t *testing.T) {
+	tests := []struct {
+		name  string
+		fence Fence
+		want  gps.Point
+	}{
+		{
+			name:  "circle center",
+			fence: Fence{Center: &gps.Point{Latitude: 37.79, Longitude: -122.39}, Radius: 150},
+			want:  gps.Point{Latitude: 37.79, Longitude: -122.39},
+		},
+		{
+			name: "square centroid",
+			fence: Fence{Polygon: gps.Polygon{
+				{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 2},
+				{Latitude: 2, Longitude: 2}, {Latitude: 2, Longitude: 0},
+			}},
+			want: gps.Point{Latitude: 1, Longitude: 1},
+		},
+		{
+			// The centroid of an L shape is pulled toward its larger arm
+			name: "L shape centroid",
+			fence: Fence{Polygon: gps.Polygon{
+				{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 3},
+				{Latitude: 1, Longitude: 3}, {Latitude: 1, Longitude: 1},
+				{Latitude: 3, Longitude: 1}, {Latitude: 3, Longitude: 0},
+			}},
+			want: gps.Point{Latitude: 1.1, Longitude: 1.1},
+		},
+		{
+			name: "collinear vertices",
+			fence: Fence{Polygon: gps.Polygon{
+				{Latitude: 0, Longitude: 0}, {Latitude: 1, Longitude: 1}, {Latitude: 2, Longitude: 2},
+			}},
+			want: gps.Point{Latitude: 1, Longitude: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.fence.LabelPoint()
+			if math.Abs(got.Latitude-tt.want.Latitude) > 1e-9 || math.Abs(got.Longitude-tt.want.Longitude) > 1e-9 {
+				t.Errorf("LabelPoint() = (%v, %v), want (%v, %v)", got.Latitude, got.Longitude, tt.want.Latitude, tt.want.Longitude)
+			}
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	home := Fence{Name: "Home", Center: &gps.Point{Latitude: 0, Longitude: 0}, Radius: 200}
+	park := Fence{Name: "Park", Polygon: gps.Polygon{
+		{Latitude: 0.01, Longitude: -0.01},
+		{Latitude: 0.01, Longitude: 0.01},
+		{Latitude: 0.02, Longitude: 0.01},
+		{Latitude: 0.02, Longitude: -0.01},
+	}}
+
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0},                            // starts at home
+		{Timestamp: start.Add(10 * time.Minute), Latitude: 0.005, Longitude: 0},  // left home
+		{Timestamp: start.Add(20 * time.Minute), Latitude: 0.015, Longitude: 0},  // in the park
+		{Timestamp: start.Add(30 * time.Minute), Latitude: 0.005, Longitude: 0},  // left the park
+		{Timestamp: start.Add(40 * time.Minute), Latitude: 0.0001, Longitude: 0}, // back home
+	}
+
+	events := Detect(points, []Fence{home, park})
+
+	want := []struct {
+		fence   string
+		typ     EventType
+		minutes int
+	}{
+		{"Home", Enter, 0},
+		{"Home", Exit, 10},
+		{"Park", Enter, 20},
+		{"Park", Exit, 30},
+		{"Home", Enter, 40},
+	}
+
+	if len(events) != len(want) {
+		t.Fatalf("Detect() returned %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		e := events[i]
+		if e.Fence != w.fence || e.Type != w.typ || !e.Timestamp.Equal(start.Add(time.Duration(w.minutes)*time.Minute)) {
+			t.Errorf("event %d = %s %s at %v, want %s %s at +%dm", i, e.Fence, e.Type, e.Timestamp, w.fence, w.typ, w.minutes)
+		}
+	}
+}
+
+func TestMembershipMatchesContains(t *testing.T) {
+	var points gps.Points
+	for i := 0; i < 50; i++ {
+		points = append(points, gps.Point{Latitude: float64(i) * 0.0005, Longitude: float64(i%7) * 0.0004})
+	}
+	fences := []Fence{
+		{Name: "Circle", Center: &gps.Point{Latitude: 0.01, Longitude: 0.001}, Radius: 400},
+		{Name: "Square", Polygon: gps.Polygon{
+			{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 0.002},
+			{Latitude: 0.005, Longitude: 0.002}, {Latitude: 0.005, Longitude: 0},
+		}},
+	}
+
+	members := membership(points, fences)
+	for i, fence := range fences {
+		for p, point := range points {
+			if members[i][p] != fence.Contains(point) {
+				t.Errorf("membership()[%s][%d] = %v, want %v", fence.Name, p, members[i][p], fence.Contains(point))
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package geojson provides GeoJSON reading for area-based GPS processing and
+// GeoJSON export of GPS tracks.
+//
+// @title GeoJSON Package
+// @version 1.0
+// @description Loads polygon boundaries from GeoJSON documents
+// @description Used for inclusion/exclusion filtering of GPS points
+// @description Writes tracks as GeoJSON feature collections
+//
+// Features:
+// - FeatureCollection, Feature, and bare geometry documents
+// - Polygon and MultiPolygon geometries with holes
+// - Non-polygon geometries are ignored
+// - Track export as a LineString plus one Point feature per GPS point
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// object is the subset of a GeoJSON object needed to locate polygon geometries.
+// Features, feature collections, and geometries share this single structure.
+type object struct {
+	Type        string          `json:"type"`
+	Features    []object        `json:"features"`
+	Geometry    *object         `json:"geometry"`
+	Geometries  []object        `json:"geometries"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// ReadAreas loads every Polygon and MultiPolygon from a GeoJSON file.
+//
+// @function ReadAreas
+// @description Reads polygon areas from a GeoJSON file
+// @param filename string Path to the GeoJSON document
+// @return []gps.Area Polygon areas with holes, in document order
+// @return error Error if the file cannot be read, parsed, or contains no polygons
+// @example areas, err := geojson.ReadAreas("park.geojson")
+func ReadAreas(filename string) ([]gps.Area, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open GeoJSON file %s: %w", filename, err)
+	}
+
+	areas, err := ParseAreas(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse GeoJSON file %s: %w", filename, err)
+	}
+	return areas, nil
+}
+
+// ParseAreas extracts every Polygon and MultiPolygon from a GeoJSON document.
+// An error is returned if the document is invalid or contains no polygons.
+func ParseAreas(data []byte) ([]gps.Area, error) {
+	var root object
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	areas, err := collectAreas(root)
+	if err != nil {
+		return nil, err
+	}
+	if len(areas) == 0 {
+		return nil, fmt.Errorf("no Polygon or MultiPolygon geometries found")
+	}
+	return areas, nil
+}
+
+// collectAreas walks a GeoJSON object tree and converts polygon geometries to areas.
+func collectAreas(obj object) ([]gps.Area, error) {
+	switch obj.Type {
+	case "FeatureCollection":
+		var areas []gps.Area
+		for _, feature := range obj.Features {
+			found, err := collectAreas(feature)
+			if err != nil {
+				return nil, err
+			}
+			areas = append(areas, found...)
+		}
+		return areas, nil
+
+	case "Feature":
+		if obj.Geometry == nil {
+			return nil, nil
+		}
+		return collectAreas(*obj.Geometry)
+
+	case "GeometryCollection":
+		var areas []gps.Area
+		for _, geometry := range obj.Geometries {
+			found, err := collectAreas(geometry)
+			if err != nil {
+				return nil, err
+			}
+			areas = append(areas, found...)
+		}
+		return areas, nil
+
+	case "Polygon":
+		var rings [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &rings); err != nil {
+			return nil, fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		area, err := areaFromRings(rings)
+		if err != nil {
+			return nil, err
+		}
+		return []gps.Area{area}, nil
+
+	case "MultiPolygon":
+		var polygons [][][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+		var areas []gps.Area
+		for _, rings := range polygons {
+			area, err := areaFromRings(rings)
+			if err != nil {
+				return nil, err
+			}
+			areas = append(areas, area)
+		}
+		return areas, nil
+	}
+
+	// Points, lines, and unknown types carry no area
+	return nil, nil
+}
+
+// areaFromRings converts GeoJSON linear rings into an area. The first ring is the
+// outer boundary and any following rings are holes. GeoJSON positions are
+// [longitude, latitude] pairs.
+func areaFromRings(rings [][][]float64) (gps.Area, error) {
+	if len(rings) == 0 {
+		return gps.Area{}, fmt.Errorf("polygon has no rings")
+	}
+
+	var polygons []gps.Polygon
+	for _, ring := range rings {
+		var polygon gps.Polygon
+		for _, position := range ring {
+			if len(position) < 2 {
+				return gps.Area{}, fmt.Errorf("position must have longitude and latitude")
+			}
+			polygon = append(polygon, gps.Point{Latitude: position[1], Longitude: position[0]})
+		}
+		polygons = append(polygons, polygon)
+	}
+
+	return gps.Area{Outer: polygons[0], Holes: polygons[1:]}, nil
+}
@@ -0,0 +1,105 @@
+// Package geojson_test provides unit tests for GeoJSON polygon reading.
+// It tests feature collections, bare geometries, multipolygons with holes,
+// coordinate ordering, and error handling for malformed documents.
+package geojson
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseAreas(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantAreas int
+		wantHoles int
+		wantErr   bool
+	}{
+		{
+			name: "feature collection with polygon",
+			data: `{"type":"FeatureCollection","features":[
+				{"type":"Feature","properties":{"name":"park"},"geometry":{"type":"Polygon","coordinates":[[[-122.51,37.76],[-122.45,37.76],[-122.45,37.77],[-122.51,37.77],[-122.51,37.76]]]}},
+				{"type":"Feature","geometry":{"type":"Point","coordinates":[-122.4,37.7]}}
+			]}`,
+			wantAreas: 1,
+		},
+		{
+			name:      "bare polygon with hole",
+			data:      `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,10],[0,0]],[[4,4],[6,4],[6,6],[4,6],[4,4]]]}`,
+			wantAreas: 1,
+			wantHoles: 1,
+		},
+		{
+			name:      "multipolygon",
+			data:      `{"type":"Feature","geometry":{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]]}}`,
+			wantAreas: 2,
+		},
+		{
+			name:    "no polygons",
+			data:    `{"type":"Feature","geometry":{"type":"LineString","coordinates":[[0,0],[1,1]]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			data:    `{"type":`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid coordinates",
+			data:    `{"type":"Polygon","coordinates":[[[0]]]}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			areas, err := ParseAreas([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAreas() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(areas) != tt.wantAreas {
+				t.Fatalf("ParseAreas() returned %d areas, want %d", len(areas), tt.wantAreas)
+			}
+			if tt.wantAreas > 0 && len(areas[0].Holes) != tt.wantHoles {
+				t.Errorf("ParseAreas() first area has %d holes, want %d", len(areas[0].Holes), tt.wantHoles)
+			}
+		})
+	}
+}
+
+func TestParseAreasCoordinateOrder(t *testing.T) {
+	areas, err := ParseAreas([]byte(`{"type":"Polygon","coordinates":[[[-122.51,37.76],[-122.45,37.76],[-122.45,37.77]]]}`))
+	if err != nil {
+		t.Fatalf("ParseAreas() error = %v", err)
+	}
+
+	first := areas[0].Outer[0]
+	if first.Latitude != 37.76 || first.Longitude != -122.51 {
+		t.Errorf("first vertex = %v,%v, want 37.76,-122.51", first.Latitude, first.Longitude)
+	}
+	if !areas[0].Contains(37.762, -122.46) {
+		t.Error("area should contain a point inside the triangle")
+	}
+}
+
+func TestReadAreas(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "area.geojson")
+	data := `{"type":"Polygon","coordinates":[[[0,0],[1,0],[1,1],[0,1],[0,0]]]}`
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to create test GeoJSON file: %v", err)
+	}
+
+	areas, err := ReadAreas(filename)
+	if err != nil {
+		t.Fatalf("ReadAreas() error = %v", err)
+	}
+	if len(areas) != 1 {
+		t.Errorf("ReadAreas() returned %d areas, want 1", len(areas))
+	}
+
+	if _, err := ReadAreas(filepath.Join(t.TempDir(), "missing.geojson")); err == nil {
+		t.Error("ReadAreas() on missing file error = nil, want error")
+	}
+}
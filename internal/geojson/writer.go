@@ -0,0 +1,115 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// featureCollection is the root of a written GeoJSON document.
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+// feature is a GeoJSON Feature written for a track or one of its points.
+type feature struct {
+	Type       string         `json:"type"`
+	Geometry   geometry       `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// geometry is a Point or LineString geometry with [longitude, latitude] positions,
+// extended with elevation when the track has it.
+type geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// Write encodes GPS points as a GeoJSON FeatureCollection: a LineString for the
+// whole track followed by one Point feature per GPS point with its metadata.
+//
+// @function Write
+// @description Exports a GPS track as GeoJSON
+// @param w io.Writer Destination for the GeoJSON document
+// @param points gps.Points Chronologically ordered GPS points
+// @param name string Track name stored in the LineString properties
+// @return error Error if encoding or writing fails
+// @example err := geojson.Write(file, points, "Morning run")
+func Write(w io.Writer, points gps.Points, name string) error {
+	withElevation := points.HasElevation()
+	position := func(point gps.Point) []float64 {
+		if withElevation {
+			return []float64{point.Longitude, point.Latitude, point.Elevation}
+		}
+		return []float64{point.Longitude, point.Latitude}
+	}
+
+	features := make([]feature, 0, len(points)+1)
+	if len(points) > 1 {
+		line := make([][]float64, len(points))
+		for i, point := range points {
+			line[i] = position(point)
+		}
+		features = append(features, feature{
+			Type:       "Feature",
+			Geometry:   geometry{Type: "LineString", Coordinates: line},
+			Properties: map[string]any{"name": name, "points": len(points)},
+		})
+	}
+
+	for _, point := range points {
+		properties := map[string]any{}
+		if !point.Timestamp.IsZero() {
+			properties["timestamp"] = point.Timestamp.Format(time.RFC3339)
+		}
+		for key, value := range map[string]string{
+			"title":       point.Title,
+			"description": point.Description,
+			"category":    point.Category,
+			"user":        point.User,
+		} {
+			if value != "" {
+				properties[key] = value
+			}
+		}
+		features = append(features, feature{
+			Type:       "Feature",
+			Geometry:   geometry{Type: "Point", Coordinates: position(point)},
+			Properties: properties,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(featureCollection{Type: "FeatureCollection", Features: features}); err != nil {
+		return fmt.Errorf("cannot encode GeoJSON: %w", err)
+	}
+	return nil
+}
+
+// WriteFile writes GPS points as a GeoJSON document to a file.
+//
+// @function WriteFile
+// @description Exports a GPS track to a GeoJSON file
+// @param filename string Target .geojson file path
+// @param points gps.Points Chronologically ordered GPS points
+// @param name string Track name stored in the LineString properties
+// @return error Error if the file cannot be created or written
+// @example err := geojson.WriteFile("track.geojson", points, cfg.Map.Title)
+func WriteFile(filename string, points gps.Points, name string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("cannot create GeoJSON file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	if err := Write(file, points, name); err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,101 @@
+package geojson
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name          string
+		points        gps.Points
+		wantFeatures  int
+		wantDimension int
+	}{
+		{
+			name: "track",
+			points: gps.Points{
+				{Timestamp: start, Latitude: 37.7749, Longitude: -122.4194, Title: "Start", Category: "stop"},
+				{Timestamp: start.Add(time.Minute), Latitude: 37.7750, Longitude: -122.4180},
+			},
+			wantFeatures:  3,
+			wantDimension: 2,
+		},
+		{
+			name: "track with elevation",
+			points: gps.Points{
+				{Latitude: 46.5, Longitude: 7.9, Elevation: 2100},
+				{Latitude: 46.6, Longitude: 8.0, Elevation: 2300},
+			},
+			wantFeatures:  3,
+			wantDimension: 3,
+		},
+		{
+			name:          "single point has no line",
+			points:        gps.Points{{Latitude: 1, Longitude: 2}},
+			wantFeatures:  1,
+			wantDimension: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.points, "Test"); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			var doc struct {
+				Type     string `json:"type"`
+				Features []struct {
+					Geometry struct {
+						Type        string          `json:"type"`
+						Coordinates json.RawMessage `json:"coordinates"`
+					} `json:"geometry"`
+					Properties map[string]any `json:"properties"`
+				} `json:"features"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatalf("Write() produced invalid JSON: %v", err)
+			}
+			if doc.Type != "FeatureCollection" || len(doc.Features) != tt.wantFeatures {
+				t.Fatalf("Write() = %s with %d features, want FeatureCollection with %d", doc.Type, len(doc.Features), tt.wantFeatures)
+			}
+
+			last := doc.Features[len(doc.Features)-1]
+			var position []float64
+			if err := json.Unmarshal(last.Geometry.Coordinates, &position); err != nil || last.Geometry.Type != "Point" {
+				t.Fatalf("last feature = %s %s, want Point", last.Geometry.Type, last.Geometry.Coordinates)
+			}
+			if len(position) != tt.wantDimension {
+				t.Errorf("Point has %d coordinates, want %d", len(position), tt.wantDimension)
+			}
+			if position[0] != tt.points[len(tt.points)-1].Longitude {
+				t.Errorf("Point longitude = %v, want %v (longitude first)", position[0], tt.points[len(tt.points)-1].Longitude)
+			}
+		})
+	}
+}
+
+func TestWriteProperties(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 1, Longitude: 2, Title: "Cafe", User: "alice"},
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, points, "Test"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, want := range []string{`"timestamp": "2025-10-28T10:00:00Z"`, `"title": "Cafe"`, `"user": "alice"`} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Errorf("Write() output missing %s", want)
+		}
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`"description"`)) {
+		t.Error("Write() output contains empty description")
+	}
+}
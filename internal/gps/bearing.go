@@ -0,0 +1,83 @@
+package gps
+
+import (
+	"math"
+)
+
+// BearingTo calculates the initial great-circle bearing from this point to another.
+//
+// @method BearingTo
+// @description Computes the compass heading at the start of the path to another point
+// @param other Point Destination GPS point
+// @return float64 Bearing in degrees clockwise from true north (0-360)
+// @note The heading changes along long great-circle paths; use a midpoint for the mid-segment heading
+// @example heading := start.BearingTo(end)
+func (p Point) BearingTo(other Point) float64 {
+	lat1 := p.Latitude * math.Pi / 180
+	lat2 := other.Latitude * math.Pi / 180
+	dLng := (other.Longitude - p.Longitude) * math.Pi / 180
+
+	y := math.Sin(dLng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLng)
+
+	return normalizeBearing(math.Atan2(y, x) * 180 / math.Pi)
+}
+
+// MidpointTo calculates the point halfway along the great-circle path to another point.
+// Timestamps are interpolated halfway as well; metadata fields are left empty.
+func (p Point) MidpointTo(other Point) Point {
+	lat1 := p.Latitude * math.Pi / 180
+	lng1 := p.Longitude * math.Pi / 180
+	lat2 := other.Latitude * math.Pi / 180
+	dLng := (other.Longitude - p.Longitude) * math.Pi / 180
+
+	bx := math.Cos(lat2) * math.Cos(dLng)
+	by := math.Cos(lat2) * math.Sin(dLng)
+	lat := math.Atan2(math.Sin(lat1)+math.Sin(lat2), math.Sqrt((math.Cos(lat1)+bx)*(math.Cos(lat1)+bx)+by*by))
+	lng := lng1 + math.Atan2(by, math.Cos(lat1)+bx)
+
+	return Point{
+		Timestamp: p.Timestamp.Add(other.Timestamp.Sub(p.Timestamp) / 2),
+		Latitude:  lat * 180 / math.Pi,
+		Longitude: math.Mod(lng*180/math.Pi+540, 360) - 180,
+	}
+}
+
+// AverageBearing calculates the distance-weighted circular mean of all segment bearings.
+// Returns ok=false when the track has no movement, since the heading is undefined.
+func (p Points) AverageBearing() (bearing float64, ok bool) {
+	var sumX, sumY float64
+	for i := 1; i < len(p); i++ {
+		distance := p[i-1].DistanceTo(p[i])
+		if distance == 0 {
+			continue
+		}
+		rad := p[i-1].BearingTo(p[i]) * math.Pi / 180
+		sumX += math.Cos(rad) * distance
+		sumY += math.Sin(rad) * distance
+	}
+
+	if sumX == 0 && sumY == 0 {
+		return 0, false
+	}
+	return normalizeBearing(math.Atan2(sumY, sumX) * 180 / math.Pi), true
+}
+
+// cardinalDirections lists the 8-point compass rose starting at north, clockwise.
+var cardinalDirections = []string{"N", "NE", "E", "SE", "S", "SW", "W", "NW"}
+
+// CardinalDirection converts a bearing in degrees into an 8-point compass label
+// such as "N", "NE", or "SW".
+func CardinalDirection(bearing float64) string {
+	index := int(math.Round(normalizeBearing(bearing)/45)) % len(cardinalDirections)
+	return cardinalDirections[index]
+}
+
+// normalizeBearing maps any angle in degrees into the [0, 360) range.
+func normalizeBearing(degrees float64) float64 {
+	degrees = math.Mod(degrees, 360)
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
@@ -0,0 +1,113 @@
+package gps
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPointBearingTo(t *testing.T) {
+	origin := Point{Latitude: 0, Longitude: 0}
+
+	tests := []struct {
+		name string
+		to   Point
+		want float64
+	}{
+		{"north", Point{Latitude: 1, Longitude: 0}, 0},
+		{"east", Point{Latitude: 0, Longitude: 1}, 90},
+		{"south", Point{Latitude: -1, Longitude: 0}, 180},
+		{"west", Point{Latitude: 0, Longitude: -1}, 270},
+		{"northeast", Point{Latitude: 1, Longitude: 1}, 45},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := origin.BearingTo(tt.to); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("Point.BearingTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Great-circle heading from San Francisco to New York starts roughly east-northeast
+	sf := Point{Latitude: 37.7749, Longitude: -122.4194}
+	ny := Point{Latitude: 40.7128, Longitude: -74.0060}
+	if got := sf.BearingTo(ny); math.Abs(got-69.9) > 0.5 {
+		t.Errorf("SF to NY bearing = %v, want ~69.9", got)
+	}
+}
+
+func TestPointMidpointTo(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	a := Point{Timestamp: start, Latitude: 0, Longitude: 0}
+	b := Point{Timestamp: start.Add(time.Hour), Latitude: 0, Longitude: 10}
+
+	mid := a.MidpointTo(b)
+	if math.Abs(mid.Latitude) > 1e-9 || math.Abs(mid.Longitude-5) > 1e-9 {
+		t.Errorf("MidpointTo() = %v,%v, want 0,5", mid.Latitude, mid.Longitude)
+	}
+	if !mid.Timestamp.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("MidpointTo().Timestamp = %v, want +30m", mid.Timestamp)
+	}
+
+	// Midpoint across the antimeridian stays within ±180
+	mid = Point{Latitude: 0, Longitude: 179}.MidpointTo(Point{Latitude: 0, Longitude: -179})
+	if math.Abs(math.Abs(mid.Longitude)-180) > 1e-9 {
+		t.Errorf("MidpointTo() across antimeridian longitude = %v, want ±180", mid.Longitude)
+	}
+}
+
+func TestPointsAverageBearing(t *testing.T) {
+	// Mostly north with a short hop east
+	points := Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 1, Longitude: 0},
+		{Latitude: 1, Longitude: 0.1},
+	}
+
+	got, ok := points.AverageBearing()
+	if !ok {
+		t.Fatal("AverageBearing() ok = false, want true")
+	}
+	if got < 0 || got > 10 {
+		t.Errorf("AverageBearing() = %v, want slightly east of north", got)
+	}
+
+	// Bearings just either side of north average to north, not south
+	points = Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 1, Longitude: -0.01},
+		{Latitude: 2, Longitude: 0},
+	}
+	if got, _ := points.AverageBearing(); got > 1 && got < 359 {
+		t.Errorf("AverageBearing() = %v, want ~0", got)
+	}
+
+	if _, ok := (Points{{Latitude: 1, Longitude: 1}, {Latitude: 1, Longitude: 1}}).AverageBearing(); ok {
+		t.Error("AverageBearing() on stationary track ok = true, want false")
+	}
+}
+
+func TestCardinalDirection(t *testing.T) {
+	tests := []struct {
+		bearing float64
+		want    string
+	}{
+		{0, "N"},
+		{22, "N"},
+		{23, "NE"},
+		{90, "E"},
+		{180, "S"},
+		{225, "SW"},
+		{337.4, "NW"},
+		{359, "N"},
+		{-45, "NW"},
+		{720, "N"},
+	}
+
+	for _, tt := range tests {
+		if got := CardinalDirection(tt.bearing); got != tt.want {
+			t.Errorf("CardinalDirection(%v) = %q, want %q", tt.bearing, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package gps
+
+import (
+	"math"
+)
+
+// Supported methods for choosing the center of a set of GPS points.
+const (
+	CenterMean      = "mean"      // Arithmetic mean of latitudes and longitudes
+	CenterSpherical = "spherical" // Mean position on the sphere, correct across the antimeridian
+	CenterMedian    = "median"    // Geographic median, robust against outliers
+)
+
+// medianMaxIterations bounds the Weiszfeld iteration used by GeographicMedian.
+const medianMaxIterations = 100
+
+// CenterBy calculates the center of the GPS points using the named method.
+//
+// @method CenterBy
+// @description Selects the arithmetic mean, spherical mean, or geographic median
+// @param method string "mean", "spherical", "median", or empty for the mean
+// @return lat float64 Center latitude
+// @return lng float64 Center longitude
+// @return ok bool False if the method name is not recognized (the mean is returned)
+// @example lat, lng, ok := points.CenterBy(gps.CenterMedian)
+func (p Points) CenterBy(method string) (lat, lng float64, ok bool) {
+	switch method {
+	case "", CenterMean:
+		lat, lng = p.Center()
+	case CenterSpherical:
+		lat, lng = p.SphericalCenter()
+	case CenterMedian:
+		lat, lng = p.GeographicMedian()
+	default:
+		lat, lng = p.Center()
+		return lat, lng, false
+	}
+	return lat, lng, true
+}
+
+// SphericalCenter calculates the mean position of the GPS points on the sphere by
+// averaging their unit vectors. Unlike Center, a track crossing the antimeridian is
+// centered near ±180° longitude rather than on the opposite side of the globe.
+// Falls back to Center when the points cancel out, such as two antipodal points.
+func (p Points) SphericalCenter() (lat, lng float64) {
+	if len(p) == 0 {
+		return 0, 0
+	}
+
+	var sum [3]float64
+	for _, point := range p {
+		v := unitVector(point.Latitude, point.Longitude)
+		sum[0], sum[1], sum[2] = sum[0]+v[0], sum[1]+v[1], sum[2]+v[2]
+	}
+	if math.Sqrt(squaredChord(sum, [3]float64{})) < 1e-9*float64(len(p)) {
+		return p.Center()
+	}
+	return vectorToLatLng(sum)
+}
+
+// GeographicMedian calculates the point minimizing the total distance to all GPS
+// points using Weiszfeld's algorithm on the unit sphere.
+//
+// @method GeographicMedian
+// @description Finds an outlier-resistant center for the track
+// @return lat float64 Median latitude
+// @return lng float64 Median longitude
+// @logic Distances are measured as straight-line chords, which rank identically to
+// @logic great-circle distances and match them closely for regional tracks
+// @example lat, lng := points.GeographicMedian()
+func (p Points) GeographicMedian() (lat, lng float64) {
+	if len(p) == 0 {
+		return 0, 0
+	}
+
+	vectors := make([][3]float64, len(p))
+	for i, point := range p {
+		vectors[i] = unitVector(point.Latitude, point.Longitude)
+	}
+
+	// Start from the spherical mean and refine towards the median
+	startLat, startLng := p.SphericalCenter()
+	current := unitVector(startLat, startLng)
+	for iter := 0; iter < medianMaxIterations; iter++ {
+		var next [3]float64
+		var weights float64
+		for _, v := range vectors {
+			d := math.Sqrt(squaredChord(v, current))
+			if d < 1e-12 {
+				continue // the estimate sits on a data point; skip its infinite weight
+			}
+			next[0], next[1], next[2] = next[0]+v[0]/d, next[1]+v[1]/d, next[2]+v[2]/d
+			weights += 1 / d
+		}
+		if weights == 0 {
+			break
+		}
+
+		norm := math.Sqrt(squaredChord(next, [3]float64{}))
+		if norm == 0 {
+			break
+		}
+		next = [3]float64{next[0] / norm, next[1] / norm, next[2] / norm}
+		moved := squaredChord(next, current)
+		current = next
+		if moved < 1e-24 {
+			break
+		}
+	}
+
+	return vectorToLatLng(current)
+}
+
+// vectorToLatLng converts a (not necessarily unit) vector back into coordinates.
+func vectorToLatLng(v [3]float64) (lat, lng float64) {
+	lat = math.Atan2(v[2], math.Hypot(v[0], v[1])) * 180 / math.Pi
+	lng = math.Atan2(v[1], v[0]) * 180 / math.Pi
+	return lat, lng
+}
@@ -0,0 +1,86 @@
+package gps
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPointsSphericalCenter(t *testing.T) {
+	tests := []struct {
+		name             string
+		points           Points
+		wantLat, wantLng float64
+	}{
+		{
+			name:    "small area matches the mean",
+			points:  Points{{Latitude: 10, Longitude: 20}, {Latitude: 10.002, Longitude: 20.002}},
+			wantLat: 10.001, wantLng: 20.001,
+		},
+		{
+			name:    "antimeridian crossing",
+			points:  Points{{Latitude: 0, Longitude: 179}, {Latitude: 0, Longitude: -179}},
+			wantLat: 0, wantLng: 180,
+		},
+		{
+			name:    "antipodal points fall back to the mean",
+			points:  Points{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 180}},
+			wantLat: 0, wantLng: 90,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lat, lng := tt.points.SphericalCenter()
+			if math.Abs(lat-tt.wantLat) > 1e-4 {
+				t.Errorf("SphericalCenter() lat = %v, want %v", lat, tt.wantLat)
+			}
+			// ±180 are the same meridian
+			if dLng := math.Mod(math.Abs(lng-tt.wantLng), 360); dLng > 1e-4 && 360-dLng > 1e-4 {
+				t.Errorf("SphericalCenter() lng = %v, want %v", lng, tt.wantLng)
+			}
+		})
+	}
+}
+
+func TestPointsGeographicMedian(t *testing.T) {
+	// Four points in a tight cluster and one far outlier
+	points := Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0.001, Longitude: 0},
+		{Latitude: 0, Longitude: 0.001},
+		{Latitude: 0.001, Longitude: 0.001},
+		{Latitude: 10, Longitude: 10},
+	}
+
+	meanLat, _ := points.Center()
+	lat, lng := points.GeographicMedian()
+	if lat > 0.01 || lng > 0.01 {
+		t.Errorf("GeographicMedian() = %v,%v, want inside the cluster", lat, lng)
+	}
+	if meanLat < 1 {
+		t.Errorf("Center() lat = %v, expected the outlier to pull the mean", meanLat)
+	}
+
+	if lat, lng := (Points{{Latitude: 5, Longitude: 6}}).GeographicMedian(); math.Abs(lat-5) > 1e-9 || math.Abs(lng-6) > 1e-9 {
+		t.Errorf("GeographicMedian() single point = %v,%v, want 5,6", lat, lng)
+	}
+	if lat, lng := (Points{}).GeographicMedian(); lat != 0 || lng != 0 {
+		t.Errorf("GeographicMedian() empty = %v,%v, want 0,0", lat, lng)
+	}
+}
+
+func TestPointsCenterBy(t *testing.T) {
+	points := Points{{Latitude: 0, Longitude: 179}, {Latitude: 0, Longitude: -179}}
+
+	for _, method := range []string{"", CenterMean, CenterSpherical, CenterMedian} {
+		if _, _, ok := points.CenterBy(method); !ok {
+			t.Errorf("CenterBy(%q) ok = false, want true", method)
+		}
+	}
+	if _, lng, _ := points.CenterBy(CenterMean); lng != 0 {
+		t.Errorf("CenterBy(mean) lng = %v, want 0", lng)
+	}
+	if _, _, ok := points.CenterBy("centroid"); ok {
+		t.Error("CenterBy(unknown) ok = true, want false")
+	}
+}
@@ -0,0 +1,81 @@
+package gps
+
+// Thin drops points closer than minDistance meters to the previously kept point,
+// collapsing the clusters recorded while standing still. The first point is always kept.
+//
+// @method Thin
+// @description Removes GPS jitter by enforcing a minimum spacing between points
+// @param minDistance float64 Minimum distance in meters between consecutive kept points
+// @return Points New collection; a non-positive distance keeps every point
+// @example spaced := points.Thin(10)
+func (p Points) Thin(minDistance float64) Points {
+	if minDistance <= 0 {
+		return append(Points(nil), p...)
+	}
+	var last *Point
+	return p.Filter(func(point Point) bool {
+		if last != nil && last.DistanceTo(point) < minDistance {
+			return false
+		}
+		last = &point
+		return true
+	})
+}
+
+// DropSpeeding drops points that could only be reached from the previously kept point
+// faster than maxSpeed km/h, removing the jumps caused by bad GPS fixes. Points must be
+// in chronological order. The first point is always kept.
+//
+// @method DropSpeeding
+// @description Removes unrealistic position jumps using an implied-speed limit
+// @param maxSpeed float64 Maximum realistic speed in km/h
+// @return Points New collection; a non-positive speed keeps every point
+// @example cleaned := points.DropSpeeding(300)
+func (p Points) DropSpeeding(maxSpeed float64) Points {
+	if maxSpeed <= 0 {
+		return append(Points(nil), p...)
+	}
+	var last *Point
+	return p.Filter(func(point Point) bool {
+		if last != nil && last.SpeedTo(point) > maxSpeed {
+			return false
+		}
+		last = &point
+		return true
+	})
+}
+
+// Smooth replaces each point's coordinates with the average of the window of points
+// centered on it, evening out GPS noise. The window shrinks at the ends of the track so
+// the first and last points stay put. All other point fields are preserved.
+//
+// @method Smooth
+// @description Applies a centered moving average to latitude and longitude
+// @param window int Number of points averaged; even sizes are rounded up to the next odd
+// @return Points New collection of the same length
+// @example smoothed := points.Smooth(5)
+func (p Points) Smooth(window int) Points {
+	half := window / 2
+	result := make(Points, len(p))
+	for i, point := range p {
+		// Shrink the window near either end so it stays centered
+		reach := half
+		if i < reach {
+			reach = i
+		}
+		if after := len(p) - 1 - i; after < reach {
+			reach = after
+		}
+
+		var lat, lng, ele float64
+		for _, neighbor := range p[i-reach : i+reach+1] {
+			lat += neighbor.Latitude
+			lng += neighbor.Longitude
+			ele += neighbor.Elevation
+		}
+		n := float64(2*reach + 1)
+		point.Latitude, point.Longitude, point.Elevation = lat/n, lng/n, ele/n
+		result[i] = point
+	}
+	return result
+}
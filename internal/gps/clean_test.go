@@ -0,0 +1,87 @@
+package gps
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPointsThin(t *testing.T) {
+	// Roughly 1.1 m per 0.00001° of latitude
+	points := Points{
+		{Latitude: 0},
+		{Latitude: 0.00001},
+		{Latitude: 0.00005},
+		{Latitude: 0.0001},
+		{Latitude: 0.0002},
+	}
+
+	tests := []struct {
+		name        string
+		minDistance float64
+		want        []float64
+	}{
+		{name: "disabled", minDistance: 0, want: []float64{0, 0.00001, 0.00005, 0.0001, 0.0002}},
+		{name: "ten meters", minDistance: 10, want: []float64{0, 0.0001, 0.0002}},
+		{name: "five meters", minDistance: 5, want: []float64{0, 0.00005, 0.0001, 0.0002}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := points.Thin(tt.minDistance)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Points.Thin(%v) kept %d points, want %d", tt.minDistance, len(got), len(tt.want))
+			}
+			for i, lat := range tt.want {
+				if got[i].Latitude != lat {
+					t.Errorf("Points.Thin(%v)[%d].Latitude = %v, want %v", tt.minDistance, i, got[i].Latitude, lat)
+				}
+			}
+		})
+	}
+}
+
+func TestPointsDropSpeeding(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := Points{
+		{Timestamp: start, Latitude: 0},
+		{Timestamp: start.Add(time.Minute), Latitude: 0.001},     // ~6.7 km/h
+		{Timestamp: start.Add(2 * time.Minute), Latitude: 1},     // ~6,600 km/h jump
+		{Timestamp: start.Add(3 * time.Minute), Latitude: 0.002}, // back on track
+	}
+
+	got := points.DropSpeeding(300)
+	if len(got) != 3 || got[2].Latitude != 0.002 {
+		t.Errorf("Points.DropSpeeding(300) = %+v, want the jump removed", got)
+	}
+	if got := points.DropSpeeding(0); len(got) != len(points) {
+		t.Errorf("Points.DropSpeeding(0) kept %d points, want all %d", len(got), len(points))
+	}
+}
+
+func TestPointsSmooth(t *testing.T) {
+	points := Points{
+		{Latitude: 0, Category: "start"},
+		{Latitude: 3},
+		{Latitude: 0},
+		{Latitude: 3},
+		{Latitude: 0, Category: "end"},
+	}
+
+	got := points.Smooth(3)
+	want := []float64{0, 1, 2, 1, 0}
+	for i, lat := range want {
+		if math.Abs(got[i].Latitude-lat) > 1e-9 {
+			t.Errorf("Points.Smooth(3)[%d].Latitude = %v, want %v", i, got[i].Latitude, lat)
+		}
+	}
+	if got[0].Category != "start" || got[4].Category != "end" {
+		t.Errorf("Points.Smooth() lost point metadata: %+v", got)
+	}
+	if points[1].Latitude != 3 {
+		t.Error("Points.Smooth() modified the receiver")
+	}
+	if got := points.Smooth(1); got[1].Latitude != 3 {
+		t.Errorf("Points.Smooth(1)[1].Latitude = %v, want unchanged 3", got[1].Latitude)
+	}
+}
@@ -0,0 +1,58 @@
+package gps
+
+import "math"
+
+// DistanceToSegment calculates the shortest distance from this point to the line
+// segment between two other points.
+//
+// @method DistanceToSegment
+// @description Computes the off-route distance of a point from one leg of a route
+// @param a Point Segment start
+// @param b Point Segment end
+// @return float64 Distance in meters to the closest point on the segment
+// @logic Projects the segment onto a local flat plane centered on this point, which is
+// @logic accurate to well under a meter for the off-route distances found in GPS tracks
+// @example meters := position.DistanceToSegment(route[3], route[4])
+func (p Point) DistanceToSegment(a, b Point) float64 {
+	ax, ay := localXY(p, a)
+	bx, by := localXY(p, b)
+
+	// Parameter of the closest point along the segment, clamped to its ends
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if lengthSq := dx*dx + dy*dy; lengthSq > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSq))
+	}
+
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
+
+// DistanceToPath calculates the shortest distance from a point to the polyline
+// formed by the collection. A single-point path is treated as that point.
+// Returns ok=false for an empty path.
+func (p Points) DistanceToPath(point Point) (meters float64, ok bool) {
+	switch len(p) {
+	case 0:
+		return 0, false
+	case 1:
+		return point.DistanceTo(p[0]), true
+	}
+
+	meters = math.Inf(1)
+	for i := 1; i < len(p); i++ {
+		if d := point.DistanceToSegment(p[i-1], p[i]); d < meters {
+			meters = d
+		}
+	}
+	return meters, true
+}
+
+// localXY converts a point into east/north offsets in meters from an origin using an
+// equirectangular projection. Longitude differences wrap across the antimeridian.
+func localXY(origin, point Point) (x, y float64) {
+	metersPerDegree := EarthRadius * math.Pi / 180
+	dLng := math.Mod(point.Longitude-origin.Longitude+540, 360) - 180
+	x = dLng * metersPerDegree * math.Cos(origin.Latitude*math.Pi/180)
+	y = (point.Latitude - origin.Latitude) * metersPerDegree
+	return x, y
+}
@@ -0,0 +1,56 @@
+package gps
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPointDistanceToSegment(t *testing.T) {
+	a := Point{Latitude: 0, Longitude: 0}
+	b := Point{Latitude: 0, Longitude: 0.01}
+
+	tests := []struct {
+		name  string
+		point Point
+		want  float64
+	}{
+		{name: "on the segment", point: Point{Latitude: 0, Longitude: 0.005}, want: 0},
+		{name: "beside the middle", point: Point{Latitude: 0.001, Longitude: 0.005}, want: 111.2},
+		{name: "beyond the end", point: Point{Latitude: 0, Longitude: 0.011}, want: 111.2},
+		{name: "before the start", point: Point{Latitude: 0.001, Longitude: -0.001}, want: 157.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.point.DistanceToSegment(a, b); math.Abs(got-tt.want) > 0.5 {
+				t.Errorf("Point.DistanceToSegment() = %v, want ~%v", got, tt.want)
+			}
+		})
+	}
+
+	// A zero-length segment behaves like a single point
+	point := Point{Latitude: 0.001, Longitude: 0}
+	if got := point.DistanceToSegment(a, a); math.Abs(got-point.DistanceTo(a)) > 0.5 {
+		t.Errorf("Point.DistanceToSegment() on degenerate segment = %v, want %v", got, point.DistanceTo(a))
+	}
+}
+
+func TestPointsDistanceToPath(t *testing.T) {
+	route := Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 0.01},
+		{Latitude: 0.01, Longitude: 0.01},
+	}
+
+	got, ok := route.DistanceToPath(Point{Latitude: 0.005, Longitude: 0.0105})
+	if !ok || math.Abs(got-55.6) > 0.5 {
+		t.Errorf("Points.DistanceToPath() = %v, %v, want ~55.6, true", got, ok)
+	}
+
+	if _, ok := (Points{}).DistanceToPath(Point{}); ok {
+		t.Error("Points.DistanceToPath() on empty path ok = true, want false")
+	}
+	if got, ok := route[:1].DistanceToPath(Point{Latitude: 0.001}); !ok || math.Abs(got-111.2) > 0.5 {
+		t.Errorf("Points.DistanceToPath() on single point = %v, %v, want ~111.2, true", got, ok)
+	}
+}
@@ -0,0 +1,77 @@
+package gps
+
+import (
+	"math"
+	"sort"
+)
+
+// WeightedPoint represents a geographical location with an associated weight.
+//
+// @struct WeightedPoint
+// @description Aggregated location used for density and heatmap rendering
+// @property Latitude float64 Cell center latitude
+// @property Longitude float64 Cell center longitude
+// @property Weight float64 Number of GPS points that fell into the cell
+type WeightedPoint struct {
+	Latitude  float64 // @field Latitude Cell center latitude
+	Longitude float64 // @field Longitude Cell center longitude
+	Weight    float64 // @field Weight Number of GPS points aggregated into this cell
+}
+
+// Density grids the GPS points into square cells and returns one weighted point per
+// non-empty cell.
+//
+// @method Density
+// @description Aggregates GPS points into a density grid for heatmap rendering
+// @param cellSize float64 Cell edge length in meters (0 returns every point with weight 1)
+// @return []WeightedPoint Cell centers with point counts, sorted by latitude then longitude
+// @logic Longitude cell width is scaled by the cosine of the track's mean latitude
+// @example cells := points.Density(50)
+func (p Points) Density(cellSize float64) []WeightedPoint {
+	if len(p) == 0 {
+		return nil
+	}
+
+	// Without a cell size every point contributes individually
+	if cellSize <= 0 {
+		result := make([]WeightedPoint, len(p))
+		for i, point := range p {
+			result[i] = WeightedPoint{Latitude: point.Latitude, Longitude: point.Longitude, Weight: 1}
+		}
+		return result
+	}
+
+	// Convert the cell size from meters to degrees at the track's latitude
+	centerLat, _ := p.Center()
+	latStep := cellSize / EarthRadius * 180 / math.Pi
+	lngStep := latStep / math.Max(math.Cos(centerLat*math.Pi/180), 0.01)
+
+	type cellKey struct{ row, col int64 }
+	counts := make(map[cellKey]float64)
+	for _, point := range p {
+		key := cellKey{
+			row: int64(math.Floor(point.Latitude / latStep)),
+			col: int64(math.Floor(point.Longitude / lngStep)),
+		}
+		counts[key]++
+	}
+
+	result := make([]WeightedPoint, 0, len(counts))
+	for key, count := range counts {
+		result = append(result, WeightedPoint{
+			Latitude:  (float64(key.row) + 0.5) * latStep,
+			Longitude: (float64(key.col) + 0.5) * lngStep,
+			Weight:    count,
+		})
+	}
+
+	// Sort for stable output regardless of map iteration order
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Latitude != result[j].Latitude {
+			return result[i].Latitude < result[j].Latitude
+		}
+		return result[i].Longitude < result[j].Longitude
+	})
+
+	return result
+}
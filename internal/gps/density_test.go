@@ -0,0 +1,55 @@
+package gps
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPointsDensity(t *testing.T) {
+	points := Points{
+		{Latitude: 37.77490, Longitude: -122.41940},
+		{Latitude: 37.77491, Longitude: -122.41941},
+		{Latitude: 37.77492, Longitude: -122.41939},
+		{Latitude: 37.80000, Longitude: -122.30000},
+	}
+
+	cells := points.Density(100)
+	if len(cells) != 2 {
+		t.Fatalf("Points.Density(100) returned %d cells, want 2", len(cells))
+	}
+
+	var total float64
+	for _, cell := range cells {
+		total += cell.Weight
+	}
+	if total != float64(len(points)) {
+		t.Errorf("Points.Density() total weight = %v, want %d", total, len(points))
+	}
+
+	// Sorted by latitude, so the dense cluster comes first
+	if cells[0].Weight != 3 {
+		t.Errorf("Points.Density() first cell weight = %v, want 3", cells[0].Weight)
+	}
+	if math.Abs(cells[0].Latitude-37.7749) > 0.001 || math.Abs(cells[0].Longitude+122.4194) > 0.001 {
+		t.Errorf("Points.Density() first cell center = %v,%v, want near the cluster", cells[0].Latitude, cells[0].Longitude)
+	}
+}
+
+func TestPointsDensityRaw(t *testing.T) {
+	points := Points{
+		{Latitude: 1, Longitude: 2},
+		{Latitude: 1, Longitude: 2},
+	}
+
+	cells := points.Density(0)
+	if len(cells) != 2 {
+		t.Fatalf("Points.Density(0) returned %d cells, want 2", len(cells))
+	}
+	if cells[0].Weight != 1 || cells[0].Latitude != 1 || cells[0].Longitude != 2 {
+		t.Errorf("Points.Density(0)[0] = %+v, want raw point with weight 1", cells[0])
+	}
+
+	if got := (Points{}).Density(50); got != nil {
+		t.Errorf("Points.Density() on empty = %v, want nil", got)
+	}
+}
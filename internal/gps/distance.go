@@ -0,0 +1,59 @@
+package gps
+
+import (
+	"math"
+	"time"
+)
+
+// EarthRadius is the mean Earth radius in meters used for great-circle calculations.
+const EarthRadius = 6371000.0
+
+// DistanceTo calculates the great-circle distance to another GPS point using the
+// Haversine formula.
+//
+// @method DistanceTo
+// @description Computes the surface distance between two GPS coordinates
+// @param other Point Destination GPS point
+// @return float64 Distance in meters
+// @accuracy Spherical Earth model, error below 0.5% for typical tracks
+// @example meters := start.DistanceTo(end)
+func (p Point) DistanceTo(other Point) float64 {
+	lat1 := p.Latitude * math.Pi / 180
+	lat2 := other.Latitude * math.Pi / 180
+	dLat := (other.Latitude - p.Latitude) * math.Pi / 180
+	dLng := (other.Longitude - p.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return EarthRadius * c
+}
+
+// SpeedTo calculates the average speed needed to travel from this point to another.
+// Returns 0 when the points share a timestamp or are out of chronological order.
+// The result is expressed in kilometers per hour.
+func (p Point) SpeedTo(other Point) float64 {
+	elapsed := other.Timestamp.Sub(p.Timestamp)
+	if elapsed <= 0 {
+		return 0
+	}
+	return p.DistanceTo(other) / elapsed.Seconds() * 3.6
+}
+
+// TotalDistance calculates the cumulative distance along the track in meters.
+// Points are visited in slice order, so sort by timestamp first for chronological tracks.
+func (p Points) TotalDistance() float64 {
+	var total float64
+	for i := 1; i < len(p); i++ {
+		total += p[i-1].DistanceTo(p[i])
+	}
+	return total
+}
+
+// Duration returns the elapsed time between the earliest and latest GPS points.
+// This is a convenience wrapper around TimeRange for summary statistics.
+func (p Points) Duration() time.Duration {
+	start, end := p.TimeRange()
+	return end.Sub(start)
+}
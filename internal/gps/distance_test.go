@@ -0,0 +1,87 @@
+package gps
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPointDistanceTo(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      Point
+		to        Point
+		want      float64
+		tolerance float64
+	}{
+		{
+			name: "same point",
+			from: Point{Latitude: 37.7749, Longitude: -122.4194},
+			to:   Point{Latitude: 37.7749, Longitude: -122.4194},
+			want: 0,
+		},
+		{
+			name:      "one degree of latitude",
+			from:      Point{Latitude: 0, Longitude: 0},
+			to:        Point{Latitude: 1, Longitude: 0},
+			want:      111195,
+			tolerance: 1,
+		},
+		{
+			name:      "San Francisco to Oakland",
+			from:      Point{Latitude: 37.7749, Longitude: -122.4194},
+			to:        Point{Latitude: 37.8044, Longitude: -122.2711},
+			want:      13410,
+			tolerance: 50,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from.DistanceTo(tt.to)
+			if math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("Point.DistanceTo() = %v, want %v ± %v", got, tt.want, tt.tolerance)
+			}
+		})
+	}
+}
+
+func TestPointSpeedTo(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	from := Point{Timestamp: start, Latitude: 0, Longitude: 0}
+	to := Point{Timestamp: start.Add(time.Hour), Latitude: 1, Longitude: 0}
+
+	if got := from.SpeedTo(to); math.Abs(got-111.195) > 0.01 {
+		t.Errorf("Point.SpeedTo() = %v, want ~111.195 km/h", got)
+	}
+	if got := to.SpeedTo(from); got != 0 {
+		t.Errorf("Point.SpeedTo() backwards in time = %v, want 0", got)
+	}
+}
+
+func TestPointsTotalDistance(t *testing.T) {
+	points := Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 1, Longitude: 0},
+		{Latitude: 2, Longitude: 0},
+	}
+
+	if got := points.TotalDistance(); math.Abs(got-2*111195) > 2 {
+		t.Errorf("Points.TotalDistance() = %v, want ~%v", got, 2*111195)
+	}
+	if got := (Points{}).TotalDistance(); got != 0 {
+		t.Errorf("Points.TotalDistance() on empty = %v, want 0", got)
+	}
+}
+
+func TestPointsDuration(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := Points{
+		{Timestamp: start.Add(90 * time.Minute)},
+		{Timestamp: start},
+	}
+
+	if got := points.Duration(); got != 90*time.Minute {
+		t.Errorf("Points.Duration() = %v, want %v", got, 90*time.Minute)
+	}
+}
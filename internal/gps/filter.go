@@ -0,0 +1,52 @@
+package gps
+
+// Filter returns the points for which keep returns true, in their original order.
+// The receiver is not modified.
+//
+// @method Filter
+// @description Eagerly selects points with a predicate; the slice counterpart of Seq.Filter
+// @param keep func(Point) bool Predicate deciding which points are kept
+// @return Points New collection holding the kept points
+// @example tagged := points.Filter(func(p gps.Point) bool { return p.Category != "" })
+func (p Points) Filter(keep func(Point) bool) Points {
+	var result Points
+	for _, point := range p {
+		if keep(point) {
+			result = append(result, point)
+		}
+	}
+	return result
+}
+
+// Transform returns a new collection with fn applied to every point.
+// The receiver is not modified.
+//
+// @method Transform
+// @description Eagerly maps points; the slice counterpart of Seq.Map
+// @param fn func(Point) Point Function producing the replacement for each point
+// @return Points New collection of the same length
+// @example shifted := points.Transform(func(p gps.Point) gps.Point { p.Timestamp = p.Timestamp.UTC(); return p })
+func (p Points) Transform(fn func(Point) Point) Points {
+	if p == nil {
+		return nil
+	}
+	result := make(Points, len(p))
+	for i, point := range p {
+		result[i] = fn(point)
+	}
+	return result
+}
+
+// unique returns a predicate that accepts each coordinate the first time it is seen,
+// shared by Points.RemoveDuplicates and Seq.Unique.
+func unique() func(Point) bool {
+	seen := make(map[string]bool)
+	return func(point Point) bool {
+		key := coordinateKey(point)
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+		return true
+	}
+}
@@ -0,0 +1,54 @@
+package gps
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPointsFilter(t *testing.T) {
+	points := Points{
+		{Latitude: 1, Category: "stop"},
+		{Latitude: 2},
+		{Latitude: 3, Category: "stop"},
+	}
+
+	stops := points.Filter(func(p Point) bool { return p.Category == "stop" })
+	if len(stops) != 2 || stops[0].Latitude != 1 || stops[1].Latitude != 3 {
+		t.Errorf("Points.Filter() = %+v, want the two stops in order", stops)
+	}
+	if len(points) != 3 {
+		t.Errorf("Points.Filter() modified the receiver: %+v", points)
+	}
+	if got := points.Filter(func(Point) bool { return false }); len(got) != 0 {
+		t.Errorf("Points.Filter() rejecting everything = %+v, want empty", got)
+	}
+}
+
+func TestPointsTransform(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := Points{
+		{Timestamp: start, Latitude: 1},
+		{Timestamp: start.Add(time.Minute), Latitude: 2},
+	}
+
+	shifted := points.Transform(func(p Point) Point {
+		p.Timestamp = p.Timestamp.Add(time.Hour)
+		return p
+	})
+	if len(shifted) != 2 || !shifted[1].Timestamp.Equal(start.Add(61*time.Minute)) {
+		t.Errorf("Points.Transform() = %+v, want timestamps shifted by an hour", shifted)
+	}
+	if !points[0].Timestamp.Equal(start) {
+		t.Errorf("Points.Transform() modified the receiver: %+v", points)
+	}
+	if got := (Points)(nil).Transform(func(p Point) Point { return p }); got != nil {
+		t.Errorf("Points.Transform() on nil = %+v, want nil", got)
+	}
+}
+
+func TestUniqueResetsPerIteration(t *testing.T) {
+	seq := Points{{Latitude: 1}, {Latitude: 1}, {Latitude: 2}}.All().Unique()
+	if first, second := seq.Count(), seq.Count(); first != 2 || second != 2 {
+		t.Errorf("Seq.Unique() counts = %d, %d, want 2 on every iteration", first, second)
+	}
+}
@@ -0,0 +1,100 @@
+package gps
+
+import (
+	"math"
+)
+
+// Supported distance calculation methods.
+const (
+	DistanceHaversine = "haversine" // Spherical Earth, fast, error below 0.5%
+	DistanceVincenty  = "vincenty"  // WGS-84 ellipsoid, sub-millimeter accuracy
+)
+
+// WGS-84 ellipsoid parameters used for geodesic calculations.
+const (
+	wgs84SemiMajorAxis = 6378137.0
+	wgs84Flattening    = 1 / 298.257223563
+	wgs84SemiMinorAxis = wgs84SemiMajorAxis * (1 - wgs84Flattening)
+)
+
+// vincentyMaxIterations bounds the iteration for nearly antipodal points, where
+// Vincenty's inverse formula converges slowly or not at all.
+const vincentyMaxIterations = 200
+
+// DistanceFunc computes the distance in meters between two GPS points.
+type DistanceFunc func(from, to Point) float64
+
+// DistanceFuncFor returns the distance calculation for a configured method name.
+//
+// @function DistanceFuncFor
+// @description Selects Haversine or Vincenty distance by name
+// @param method string "haversine", "vincenty", or empty for the default
+// @return DistanceFunc Distance calculation in meters
+// @return bool False if the method name is not recognized (Haversine is returned)
+// @example distance, ok := gps.DistanceFuncFor(cfg.Statistics.DistanceMethod)
+func DistanceFuncFor(method string) (DistanceFunc, bool) {
+	switch method {
+	case "", DistanceHaversine:
+		return Point.DistanceTo, true
+	case DistanceVincenty:
+		return Point.GeodesicDistanceTo, true
+	default:
+		return Point.DistanceTo, false
+	}
+}
+
+// GeodesicDistanceTo calculates the distance to another GPS point on the WGS-84
+// ellipsoid using Vincenty's inverse formula.
+//
+// @method GeodesicDistanceTo
+// @description Computes the ellipsoidal surface distance between two GPS coordinates
+// @param other Point Destination GPS point
+// @return float64 Distance in meters
+// @accuracy Sub-millimeter on the WGS-84 ellipsoid; falls back to Haversine for
+// @accuracy nearly antipodal points where the iteration does not converge
+// @example meters := start.GeodesicDistanceTo(end)
+func (p Point) GeodesicDistanceTo(other Point) float64 {
+	const a, b, f = wgs84SemiMajorAxis, wgs84SemiMinorAxis, wgs84Flattening
+
+	L := (other.Longitude - p.Longitude) * math.Pi / 180
+	U1 := math.Atan((1 - f) * math.Tan(p.Latitude*math.Pi/180))
+	U2 := math.Atan((1 - f) * math.Tan(other.Latitude*math.Pi/180))
+	sinU1, cosU1 := math.Sincos(U1)
+	sinU2, cosU2 := math.Sincos(U2)
+
+	lambda := L
+	for i := 0; i < vincentyMaxIterations; i++ {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma := math.Sqrt(math.Pow(cosU2*sinLambda, 2) +
+			math.Pow(cosU1*sinU2-sinU1*cosU2*cosLambda, 2))
+		if sinSigma == 0 {
+			return 0 // coincident points
+		}
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha := 1 - sinAlpha*sinAlpha
+
+		// Points on the equator have cosSqAlpha = 0
+		cos2SigmaM := 0.0
+		if cosSqAlpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha
+		}
+
+		C := f / 16 * cosSqAlpha * (4 + f*(4-3*cosSqAlpha))
+		prev := lambda
+		lambda = L + (1-C)*f*sinAlpha*
+			(sigma+C*sinSigma*(cos2SigmaM+C*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+
+		if math.Abs(lambda-prev) < 1e-12 {
+			uSq := cosSqAlpha * (a*a - b*b) / (b * b)
+			A := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+			B := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+			deltaSigma := B * sinSigma * (cos2SigmaM + B/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+				B/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+			return b * A * (sigma - deltaSigma)
+		}
+	}
+
+	return p.DistanceTo(other)
+}
@@ -0,0 +1,80 @@
+package gps
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPointGeodesicDistanceTo(t *testing.T) {
+	tests := []struct {
+		name      string
+		from      Point
+		to        Point
+		want      float64
+		tolerance float64
+	}{
+		{
+			name: "same point",
+			from: Point{Latitude: 37.7749, Longitude: -122.4194},
+			to:   Point{Latitude: 37.7749, Longitude: -122.4194},
+			want: 0,
+		},
+		{
+			// Reference geodesic from Flinders Peak to Buninyong (Vincenty 1975)
+			name:      "Flinders Peak to Buninyong",
+			from:      Point{Latitude: -37.95103341666667, Longitude: 144.42486788888889},
+			to:        Point{Latitude: -37.65282113888889, Longitude: 143.92649552777778},
+			want:      54972.271,
+			tolerance: 0.001,
+		},
+		{
+			name:      "one degree along the equator",
+			from:      Point{Latitude: 0, Longitude: 0},
+			to:        Point{Latitude: 0, Longitude: 1},
+			want:      111319.491,
+			tolerance: 0.001,
+		},
+		{
+			name:      "nearly antipodal falls back to haversine",
+			from:      Point{Latitude: 0, Longitude: 0},
+			to:        Point{Latitude: 0.5, Longitude: 179.7},
+			want:      Point{Latitude: 0, Longitude: 0}.DistanceTo(Point{Latitude: 0.5, Longitude: 179.7}),
+			tolerance: 40000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from.GeodesicDistanceTo(tt.to)
+			if math.Abs(got-tt.want) > tt.tolerance {
+				t.Errorf("Point.GeodesicDistanceTo() = %v, want %v ± %v", got, tt.want, tt.tolerance)
+			}
+		})
+	}
+}
+
+func TestDistanceFuncFor(t *testing.T) {
+	a := Point{Latitude: 0, Longitude: 0}
+	b := Point{Latitude: 0, Longitude: 1}
+
+	tests := []struct {
+		method string
+		want   float64
+		ok     bool
+	}{
+		{"", a.DistanceTo(b), true},
+		{DistanceHaversine, a.DistanceTo(b), true},
+		{DistanceVincenty, a.GeodesicDistanceTo(b), true},
+		{"karney", a.DistanceTo(b), false},
+	}
+
+	for _, tt := range tests {
+		fn, ok := DistanceFuncFor(tt.method)
+		if ok != tt.ok {
+			t.Errorf("DistanceFuncFor(%q) ok = %v, want %v", tt.method, ok, tt.ok)
+		}
+		if got := fn(a, b); got != tt.want {
+			t.Errorf("DistanceFuncFor(%q) distance = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package gps
+
+import (
+	"math"
+	"sort"
+)
+
+// Neighbor is a GPS point returned by a spatial index query.
+//
+// @struct Neighbor
+// @description Query result with the point's position in the indexed slice
+// @property Point Point The matching GPS point
+// @property Index int Position of the point in the slice passed to NewIndex
+// @property Distance float64 Great-circle distance from the query location in meters
+type Neighbor struct {
+	Point    Point   // @field Point The matching GPS point
+	Index    int     // @field Index Position in the indexed slice
+	Distance float64 // @field Distance Haversine distance from the query location (meters)
+}
+
+// Index is a static k-d tree over GPS points for fast nearest-point and radius queries.
+// Points are stored as unit vectors on the sphere, so queries behave correctly near the
+// poles and across the antimeridian. Reported distances use the Haversine formula,
+// matching Point.DistanceTo.
+type Index struct {
+	points Points
+	nodes  []indexNode
+	root   int
+}
+
+// indexNode is a k-d tree node splitting on one axis of the unit vector.
+type indexNode struct {
+	pos         [3]float64
+	point       int // index into Index.points
+	axis        int
+	left, right int // child node indices, -1 when absent
+}
+
+// NewIndex builds a spatial index over the GPS points.
+//
+// @function NewIndex
+// @description Builds a k-d tree for Nearest and Within queries
+// @param points Points GPS points to index; the slice must not be modified afterwards
+// @return *Index Spatial index ready for queries
+// @complexity O(n log² n) build, O(log n) typical nearest-point query
+// @example idx := gps.NewIndex(points)
+func NewIndex(points Points) *Index {
+	idx := &Index{points: points, root: -1}
+	order := make([]int, len(points))
+	positions := make([][3]float64, len(points))
+	for i, p := range points {
+		order[i] = i
+		positions[i] = unitVector(p.Latitude, p.Longitude)
+	}
+	idx.root = idx.build(order, positions, 0)
+	return idx
+}
+
+// build recursively splits the points at the median of the current axis.
+func (idx *Index) build(order []int, positions [][3]float64, depth int) int {
+	if len(order) == 0 {
+		return -1
+	}
+
+	axis := depth % 3
+	sort.Slice(order, func(a, b int) bool {
+		return positions[order[a]][axis] < positions[order[b]][axis]
+	})
+	mid := len(order) / 2
+
+	node := len(idx.nodes)
+	idx.nodes = append(idx.nodes, indexNode{pos: positions[order[mid]], point: order[mid], axis: axis})
+	left := idx.build(order[:mid], positions, depth+1)
+	right := idx.build(order[mid+1:], positions, depth+1)
+	idx.nodes[node].left, idx.nodes[node].right = left, right
+	return node
+}
+
+// Len returns the number of indexed points.
+func (idx *Index) Len() int {
+	return len(idx.points)
+}
+
+// Nearest finds the indexed point closest to a location.
+//
+// @method Nearest
+// @description Returns the nearest GPS point by great-circle distance
+// @param lat float64 Query latitude
+// @param lng float64 Query longitude
+// @return Neighbor Closest point with its distance
+// @return bool False if the index is empty
+// @example n, ok := idx.Nearest(37.7749, -122.4194)
+func (idx *Index) Nearest(lat, lng float64) (Neighbor, bool) {
+	if idx.root < 0 {
+		return Neighbor{}, false
+	}
+
+	target := unitVector(lat, lng)
+	best, bestDist := -1, math.Inf(1)
+
+	var search func(n int)
+	search = func(n int) {
+		if n < 0 {
+			return
+		}
+		node := idx.nodes[n]
+		if d := squaredChord(node.pos, target); d < bestDist {
+			best, bestDist = node.point, d
+		}
+
+		diff := target[node.axis] - node.pos[node.axis]
+		near, far := node.left, node.right
+		if diff > 0 {
+			near, far = far, near
+		}
+		search(near)
+		if diff*diff < bestDist {
+			search(far)
+		}
+	}
+	search(idx.root)
+
+	point := idx.points[best]
+	return Neighbor{Point: point, Index: best, Distance: Point{Latitude: lat, Longitude: lng}.DistanceTo(point)}, true
+}
+
+// Within finds every indexed point within a radius of a location.
+//
+// @method Within
+// @description Returns GPS points inside a circle, nearest first
+// @param lat float64 Circle center latitude
+// @param lng float64 Circle center longitude
+// @param radius float64 Radius in meters
+// @return []Neighbor Matching points sorted by distance, then by index
+// @example nearby := idx.Within(37.7749, -122.4194, 500)
+func (idx *Index) Within(lat, lng, radius float64) []Neighbor {
+	if idx.root < 0 || radius < 0 {
+		return nil
+	}
+
+	center := Point{Latitude: lat, Longitude: lng}
+	target := unitVector(lat, lng)
+
+	// Prune with a slightly enlarged chord so floating point error never drops a
+	// match; the exact Haversine distance decides membership
+	limit := 2*math.Sin(math.Min(radius/EarthRadius, math.Pi)/2) + 1e-9
+	limit *= limit
+
+	var results []Neighbor
+	var search func(n int)
+	search = func(n int) {
+		if n < 0 {
+			return
+		}
+		node := idx.nodes[n]
+		if squaredChord(node.pos, target) <= limit {
+			point := idx.points[node.point]
+			if d := center.DistanceTo(point); d <= radius {
+				results = append(results, Neighbor{Point: point, Index: node.point, Distance: d})
+			}
+		}
+
+		diff := target[node.axis] - node.pos[node.axis]
+		if diff <= 0 || diff*diff <= limit {
+			search(node.left)
+		}
+		if diff >= 0 || diff*diff <= limit {
+			search(node.right)
+		}
+	}
+	search(idx.root)
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Distance != results[b].Distance {
+			return results[a].Distance < results[b].Distance
+		}
+		return results[a].Index < results[b].Index
+	})
+	return results
+}
+
+// unitVector converts a coordinate into a point on the unit sphere.
+func unitVector(lat, lng float64) [3]float64 {
+	sinLat, cosLat := math.Sincos(lat * math.Pi / 180)
+	sinLng, cosLng := math.Sincos(lng * math.Pi / 180)
+	return [3]float64{cosLat * cosLng, cosLat * sinLng, sinLat}
+}
+
+// squaredChord returns the squared straight-line distance between two unit vectors,
+// which increases monotonically with great-circle distance.
+func squaredChord(a, b [3]float64) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
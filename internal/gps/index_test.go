@@ -0,0 +1,151 @@
+package gps
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestIndexNearest(t *testing.T) {
+	points := Points{
+		{Latitude: 37.7749, Longitude: -122.4194, Title: "San Francisco"},
+		{Latitude: 37.8044, Longitude: -122.2711, Title: "Oakland"},
+		{Latitude: 34.0522, Longitude: -118.2437, Title: "Los Angeles"},
+		{Latitude: 0, Longitude: 179.9, Title: "East of antimeridian"},
+	}
+	idx := NewIndex(points)
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		want     string
+	}{
+		{"exact match", 37.7749, -122.4194, "San Francisco"},
+		{"near Oakland", 37.80, -122.28, "Oakland"},
+		{"southern California", 33.9, -118.4, "Los Angeles"},
+		{"across the antimeridian", 0, -179.9, "East of antimeridian"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := idx.Nearest(tt.lat, tt.lng)
+			if !ok {
+				t.Fatal("Nearest() ok = false")
+			}
+			if got.Point.Title != tt.want {
+				t.Errorf("Nearest() = %q, want %q", got.Point.Title, tt.want)
+			}
+			if got.Point != points[got.Index] {
+				t.Errorf("Nearest().Index = %d does not match the point", got.Index)
+			}
+		})
+	}
+
+	if _, ok := NewIndex(nil).Nearest(0, 0); ok {
+		t.Error("Nearest() on empty index ok = true, want false")
+	}
+}
+
+func TestIndexWithin(t *testing.T) {
+	points := Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 0.001}, // ~111 m east
+		{Latitude: 0.002, Longitude: 0}, // ~222 m north
+		{Latitude: 1, Longitude: 1},
+	}
+	idx := NewIndex(points)
+
+	got := idx.Within(0, 0, 150)
+	if len(got) != 2 || got[0].Index != 0 || got[1].Index != 1 {
+		t.Fatalf("Within(150) = %+v, want points 0 and 1 nearest first", got)
+	}
+
+	if got := idx.Within(0, 0, 250); len(got) != 3 {
+		t.Errorf("Within(250) returned %d points, want 3", len(got))
+	}
+	if got := idx.Within(50, 50, 1000); len(got) != 0 {
+		t.Errorf("Within() far away returned %d points, want 0", len(got))
+	}
+}
+
+// TestIndexMatchesBruteForce cross-checks random queries against a linear scan.
+func TestIndexMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	var points Points
+	for i := 0; i < 500; i++ {
+		points = append(points, Point{Latitude: rng.Float64()*170 - 85, Longitude: rng.Float64()*360 - 180})
+	}
+	idx := NewIndex(points)
+
+	for q := 0; q < 50; q++ {
+		lat, lng := rng.Float64()*170-85, rng.Float64()*360-180
+		query := Point{Latitude: lat, Longitude: lng}
+
+		best := 0
+		var inside []int
+		for i, p := range points {
+			if query.DistanceTo(p) < query.DistanceTo(points[best]) {
+				best = i
+			}
+			if query.DistanceTo(p) <= 1000000 {
+				inside = append(inside, i)
+			}
+		}
+
+		nearest, _ := idx.Nearest(lat, lng)
+		if nearest.Index != best {
+			t.Errorf("Nearest(%v, %v) = %d, brute force = %d", lat, lng, nearest.Index, best)
+		}
+
+		var got []int
+		for _, n := range idx.Within(lat, lng, 1000000) {
+			got = append(got, n.Index)
+		}
+		sort.Ints(got)
+		if len(got) != len(inside) {
+			t.Errorf("Within(%v, %v) found %d points, brute force = %d", lat, lng, len(got), len(inside))
+		}
+	}
+}
+
+// benchmarkPoints returns a reproducible random walk of n points around San Francisco,
+// the size of a multi-week recording at one fix per few seconds.
+func benchmarkPoints(n int) Points {
+	rng := rand.New(rand.NewSource(1))
+	points := make(Points, n)
+	lat, lng := 37.7749, -122.4194
+	for i := range points {
+		lat += (rng.Float64() - 0.5) * 0.001
+		lng += (rng.Float64() - 0.5) * 0.001
+		points[i] = Point{Latitude: lat, Longitude: lng}
+	}
+	return points
+}
+
+func BenchmarkNewIndex(b *testing.B) {
+	points := benchmarkPoints(50000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewIndex(points)
+	}
+}
+
+func BenchmarkIndexNearest(b *testing.B) {
+	points := benchmarkPoints(50000)
+	idx := NewIndex(points)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		query := points[i%len(points)]
+		idx.Nearest(query.Latitude+0.0001, query.Longitude)
+	}
+}
+
+func BenchmarkIndexWithin(b *testing.B) {
+	points := benchmarkPoints(50000)
+	idx := NewIndex(points)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		query := points[i%len(points)]
+		idx.Within(query.Latitude, query.Longitude, 100)
+	}
+}
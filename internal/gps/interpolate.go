@@ -0,0 +1,45 @@
+package gps
+
+import (
+	"sort"
+	"time"
+)
+
+// PositionAt estimates where the track was at a given moment by interpolating
+// linearly between the recorded points on either side of it.
+//
+// @method PositionAt
+// @description Interpolates the position of a chronologically sorted track at a point in time
+// @param t time.Time Moment to locate
+// @param maxGap time.Duration Longest gap between recorded points to interpolate across (0 means unlimited)
+// @return Point Estimated position; metadata is copied from the earlier recorded point
+// @return bool False when t lies outside the track or inside a gap longer than maxGap
+// @note The points must be sorted by timestamp; interpolation is linear in latitude and longitude,
+// @note which is accurate for the short gaps between GPS fixes
+// @example pos, ok := track.PositionAt(meetingTime, 5*time.Minute)
+func (p Points) PositionAt(t time.Time, maxGap time.Duration) (Point, bool) {
+	if len(p) == 0 || t.Before(p[0].Timestamp) || t.After(p[len(p)-1].Timestamp) {
+		return Point{}, false
+	}
+
+	// Find the first point recorded at or after t
+	i := sort.Search(len(p), func(i int) bool {
+		return !p[i].Timestamp.Before(t)
+	})
+	if p[i].Timestamp.Equal(t) {
+		return p[i], true
+	}
+
+	before, after := p[i-1], p[i]
+	gap := after.Timestamp.Sub(before.Timestamp)
+	if maxGap > 0 && gap > maxGap {
+		return Point{}, false
+	}
+
+	fraction := float64(t.Sub(before.Timestamp)) / float64(gap)
+	position := before
+	position.Timestamp = t
+	position.Latitude += (after.Latitude - before.Latitude) * fraction
+	position.Longitude += (after.Longitude - before.Longitude) * fraction
+	return position, true
+}
@@ -0,0 +1,54 @@
+package gps
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPointsPositionAt(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	track := Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0, User: "alice"},
+		{Timestamp: start.Add(time.Minute), Latitude: 1, Longitude: 2, User: "alice"},
+		{Timestamp: start.Add(time.Hour), Latitude: 2, Longitude: 2, User: "alice"},
+	}
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		maxGap  time.Duration
+		wantOK  bool
+		wantLat float64
+		wantLng float64
+	}{
+		{name: "exact point", at: start.Add(time.Minute), wantOK: true, wantLat: 1, wantLng: 2},
+		{name: "halfway", at: start.Add(30 * time.Second), wantOK: true, wantLat: 0.5, wantLng: 1},
+		{name: "before start", at: start.Add(-time.Second)},
+		{name: "after end", at: start.Add(2 * time.Hour)},
+		{name: "inside long gap", at: start.Add(30 * time.Minute), maxGap: 5 * time.Minute},
+		{name: "long gap without limit", at: start.Add(30*time.Minute + 30*time.Second), wantOK: true, wantLat: 1.5, wantLng: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := track.PositionAt(tt.at, tt.maxGap)
+			if ok != tt.wantOK {
+				t.Fatalf("Points.PositionAt() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(got.Latitude-tt.wantLat) > 1e-9 || math.Abs(got.Longitude-tt.wantLng) > 1e-9 {
+				t.Errorf("Points.PositionAt() = (%v, %v), want (%v, %v)", got.Latitude, got.Longitude, tt.wantLat, tt.wantLng)
+			}
+			if !got.Timestamp.Equal(tt.at) || got.User != "alice" {
+				t.Errorf("Points.PositionAt() = %+v, want timestamp %v and user alice", got, tt.at)
+			}
+		})
+	}
+
+	if _, ok := (Points{}).PositionAt(start, 0); ok {
+		t.Error("Points.PositionAt() on empty track ok = true, want false")
+	}
+}
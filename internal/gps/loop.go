@@ -0,0 +1,58 @@
+package gps
+
+import "math"
+
+// Traversal directions of a closed loop, as returned by Points.Direction.
+const (
+	Clockwise        = "clockwise"
+	Counterclockwise = "counterclockwise"
+)
+
+// IsLoop reports whether the track is a round trip: it ends within threshold meters
+// of where it started and covers more than twice that distance along the way, so a
+// stationary recording is not mistaken for a loop.
+//
+// @method IsLoop
+// @description Detects round-trip tracks that return to their starting point
+// @param threshold float64 Maximum start-to-end distance in meters
+// @return bool True when the track returns to its start after going somewhere
+// @example if points.IsLoop(100) { ... }
+func (p Points) IsLoop(threshold float64) bool {
+	if len(p) < 3 {
+		return false
+	}
+	return p[0].DistanceTo(p[len(p)-1]) <= threshold && p.TotalDistance() > 2*threshold
+}
+
+// SignedArea calculates the area enclosed by the track, closed back to its first
+// point, in square meters. The result is positive when the track runs counterclockwise
+// and negative when it runs clockwise; self-crossing tracks report the net area.
+func (p Points) SignedArea() float64 {
+	if len(p) < 3 {
+		return 0
+	}
+
+	// Shoelace formula on a local flat projection around the first point
+	var twiceArea float64
+	for i := range p {
+		x1, y1 := localXY(p[0], p[i])
+		x2, y2 := localXY(p[0], p[(i+1)%len(p)])
+		twiceArea += x1*y2 - x2*y1
+	}
+	return twiceArea / 2
+}
+
+// Direction returns Clockwise or Counterclockwise depending on how the track winds
+// around the area it encloses. Returns an empty string when the track encloses no area,
+// such as an out-and-back along the same path.
+func (p Points) Direction() string {
+	area := p.SignedArea()
+	switch {
+	case math.Abs(area) < 1:
+		return ""
+	case area > 0:
+		return Counterclockwise
+	default:
+		return Clockwise
+	}
+}
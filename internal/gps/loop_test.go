@@ -0,0 +1,67 @@
+package gps
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPointsLoop(t *testing.T) {
+	// A square of roughly 111 m sides, walked counterclockwise (east, north, west, south)
+	square := Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 0.001},
+		{Latitude: 0.001, Longitude: 0.001},
+		{Latitude: 0.001, Longitude: 0},
+		{Latitude: 0, Longitude: 0},
+	}
+	reversed := make(Points, len(square))
+	for i, point := range square {
+		reversed[len(square)-1-i] = point
+	}
+
+	tests := []struct {
+		name          string
+		points        Points
+		threshold     float64
+		wantLoop      bool
+		wantDirection string
+	}{
+		{name: "counterclockwise square", points: square, threshold: 50, wantLoop: true, wantDirection: Counterclockwise},
+		{name: "clockwise square", points: reversed, threshold: 50, wantLoop: true, wantDirection: Clockwise},
+		{
+			name:          "out and back",
+			points:        Points{{Latitude: 0}, {Latitude: 0.01}, {Latitude: 0}},
+			threshold:     50,
+			wantLoop:      true,
+			wantDirection: "",
+		},
+		{
+			name:          "one way",
+			points:        Points{{Latitude: 0}, {Latitude: 0.01}, {Latitude: 0.02}},
+			threshold:     50,
+			wantDirection: "",
+		},
+		{
+			name:          "loop too small for threshold",
+			points:        square,
+			threshold:     300,
+			wantDirection: Counterclockwise,
+		},
+		{name: "too few points", points: square[:2], threshold: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.points.IsLoop(tt.threshold); got != tt.wantLoop {
+				t.Errorf("Points.IsLoop() = %v, want %v", got, tt.wantLoop)
+			}
+			if got := tt.points.Direction(); got != tt.wantDirection {
+				t.Errorf("Points.Direction() = %q, want %q", got, tt.wantDirection)
+			}
+		})
+	}
+
+	if got := square.SignedArea(); math.Abs(got-111.2*111.2) > 50 {
+		t.Errorf("Points.SignedArea() = %v, want ~%v", got, 111.2*111.2)
+	}
+}
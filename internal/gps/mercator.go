@@ -0,0 +1,58 @@
+package gps
+
+import (
+	"math"
+)
+
+// MaxMercatorLatitude is the latitude limit of the square Web Mercator world.
+// Coordinates beyond ±85.05° are clamped before projection.
+const MaxMercatorLatitude = 85.0511287798
+
+// LatLngToWorld projects a coordinate onto Web Mercator world coordinates, the pixel
+// space of the single 256×256 tile at zoom level 0. x grows east from the
+// antimeridian and y grows south from the top of the map, as in Google Maps.
+//
+// @function LatLngToWorld
+// @description Projects latitude/longitude into zoom-independent world coordinates
+// @param lat float64 Latitude in degrees (clamped to ±MaxMercatorLatitude)
+// @param lng float64 Longitude in degrees
+// @return x float64 World x between 0 and TileSize
+// @return y float64 World y between 0 and TileSize
+// @example x, y := gps.LatLngToWorld(37.7749, -122.4194)
+func LatLngToWorld(lat, lng float64) (x, y float64) {
+	lat = math.Max(math.Min(lat, MaxMercatorLatitude), -MaxMercatorLatitude)
+	x = TileSize * (lng + 180) / 360
+	y = TileSize * (0.5 - math.Log(math.Tan(math.Pi/4+lat*math.Pi/360))/(2*math.Pi))
+	return x, y
+}
+
+// WorldToLatLng converts Web Mercator world coordinates back into a coordinate.
+// It is the inverse of LatLngToWorld for latitudes within the Mercator limit.
+func WorldToLatLng(x, y float64) (lat, lng float64) {
+	lng = x/TileSize*360 - 180
+	n := math.Pi * (1 - 2*y/TileSize)
+	lat = math.Atan(math.Sinh(n)) * 180 / math.Pi
+	return lat, lng
+}
+
+// LatLngToPixel projects a coordinate onto the global pixel grid at a zoom level.
+//
+// @function LatLngToPixel
+// @description Computes absolute pixel coordinates for tile and image rendering
+// @param lat float64 Latitude in degrees
+// @param lng float64 Longitude in degrees
+// @param zoom int Zoom level (0 shows the whole world in one tile)
+// @return x float64 Pixel x across the whole world at this zoom
+// @return y float64 Pixel y across the whole world at this zoom
+// @example x, y := gps.LatLngToPixel(point.Latitude, point.Longitude, 15)
+func LatLngToPixel(lat, lng float64, zoom int) (x, y float64) {
+	scale := math.Exp2(float64(zoom))
+	x, y = LatLngToWorld(lat, lng)
+	return x * scale, y * scale
+}
+
+// PixelToLatLng converts global pixel coordinates at a zoom level back into a coordinate.
+func PixelToLatLng(x, y float64, zoom int) (lat, lng float64) {
+	scale := math.Exp2(float64(zoom))
+	return WorldToLatLng(x/scale, y/scale)
+}
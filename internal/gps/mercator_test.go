@@ -0,0 +1,55 @@
+package gps
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLatLngToWorld(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lng float64
+		wantX    float64
+		wantY    float64
+	}{
+		{"origin", 0, 0, 128, 128},
+		{"north-west corner", MaxMercatorLatitude, -180, 0, 0},
+		{"south-east corner", -MaxMercatorLatitude, 180, 256, 256},
+		{"beyond the limit is clamped", 89, 0, 128, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x, y := LatLngToWorld(tt.lat, tt.lng)
+			if math.Abs(x-tt.wantX) > 1e-6 || math.Abs(y-tt.wantY) > 1e-6 {
+				t.Errorf("LatLngToWorld() = %v,%v, want %v,%v", x, y, tt.wantX, tt.wantY)
+			}
+		})
+	}
+}
+
+func TestMercatorRoundTrip(t *testing.T) {
+	coords := [][2]float64{{37.7749, -122.4194}, {-33.8688, 151.2093}, {0, 0}, {60, 179.5}}
+
+	for _, c := range coords {
+		lat, lng := WorldToLatLng(LatLngToWorld(c[0], c[1]))
+		if math.Abs(lat-c[0]) > 1e-9 || math.Abs(lng-c[1]) > 1e-9 {
+			t.Errorf("world round trip %v = %v,%v", c, lat, lng)
+		}
+
+		x, y := LatLngToPixel(c[0], c[1], 15)
+		lat, lng = PixelToLatLng(x, y, 15)
+		if math.Abs(lat-c[0]) > 1e-9 || math.Abs(lng-c[1]) > 1e-9 {
+			t.Errorf("pixel round trip %v = %v,%v", c, lat, lng)
+		}
+	}
+}
+
+func TestLatLngToPixelScale(t *testing.T) {
+	// Each zoom level doubles the pixel coordinates
+	x1, y1 := LatLngToPixel(37.7749, -122.4194, 10)
+	x2, y2 := LatLngToPixel(37.7749, -122.4194, 11)
+	if math.Abs(x2-2*x1) > 1e-6 || math.Abs(y2-2*y1) > 1e-6 {
+		t.Errorf("LatLngToPixel() zoom 11 = %v,%v, want double %v,%v", x2, y2, x1, y1)
+	}
+}
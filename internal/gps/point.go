@@ -30,12 +30,18 @@ import (
 // @property Longitude float64 Longitude coordinate (-180.0 to 180.0 degrees)
 // @property Title string Display name for this location (optional)
 // @property Description string Additional details about location (optional)
+// @property Category string Grouping label used for marker styling (optional)
+// @property User string Person or device that recorded this point (optional)
+// @property Elevation float64 Altitude above sea level in meters (optional, 0 when unknown)
 type Point struct {
 	Timestamp   time.Time // @field Timestamp When this GPS point was recorded
 	Latitude    float64   // @field Latitude Latitude coordinate (-90.0 to 90.0)
 	Longitude   float64   // @field Longitude Longitude coordinate (-180.0 to 180.0)
 	Title       string    // @field Title Display name for this location (optional)
 	Description string    // @field Description Additional details about this location (optional)
+	Category    string    // @field Category Grouping label for marker styling (optional)
+	User        string    // @field User Person or device that recorded this point (optional)
+	Elevation   float64   // @field Elevation Altitude above sea level in meters (optional)
 }
 
 // Points represents a collection of GPS points that can be manipulated as a group.
@@ -87,19 +93,68 @@ func (p Points) Last() *Point {
 // This helps clean up GPS data by removing redundant points at the same location.
 // The comparison is done with 6 decimal places precision (~0.1 meter accuracy).
 func (p Points) RemoveDuplicates() Points {
+	return p.Filter(unique())
+}
+
+// coordinateKey identifies a location with 6 decimal places precision (~0.1 meter),
+// used to detect duplicate points.
+func coordinateKey(point Point) string {
+	return fmt.Sprintf("%.6f,%.6f", point.Latitude, point.Longitude)
+}
+
+// Categories returns the distinct non-empty categories present in the collection,
+// sorted alphabetically so generated output is stable between runs.
+func (p Points) Categories() []string {
 	seen := make(map[string]bool)
-	var result Points
+	var categories []string
 
 	for _, point := range p {
-		// Create unique key based on coordinates with reasonable precision
-		key := fmt.Sprintf("%.6f,%.6f", point.Latitude, point.Longitude)
-		if !seen[key] {
-			seen[key] = true
-			result = append(result, point)
+		if point.Category != "" && !seen[point.Category] {
+			seen[point.Category] = true
+			categories = append(categories, point.Category)
 		}
 	}
 
-	return result
+	sort.Strings(categories)
+	return categories
+}
+
+// Users returns the distinct non-empty users present in the collection,
+// sorted alphabetically so generated output is stable between runs.
+func (p Points) Users() []string {
+	seen := make(map[string]bool)
+	var users []string
+
+	for _, point := range p {
+		if point.User != "" && !seen[point.User] {
+			seen[point.User] = true
+			users = append(users, point.User)
+		}
+	}
+
+	sort.Strings(users)
+	return users
+}
+
+// HasElevation reports whether any point carries an elevation. Points without
+// elevation data have an elevation of exactly zero.
+func (p Points) HasElevation() bool {
+	for _, point := range p {
+		if point.Elevation != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ByUser splits the collection into one track per user, preserving the original
+// point order within each track. Points without a user are grouped under "".
+func (p Points) ByUser() map[string]Points {
+	tracks := make(map[string]Points)
+	for _, point := range p {
+		tracks[point.User] = append(tracks[point.User], point)
+	}
+	return tracks
 }
 
 // Bounds calculates the geographical bounding box that contains all GPS points.
@@ -135,7 +190,8 @@ func (p Points) Bounds() (minLat, maxLat, minLng, maxLng float64) {
 
 // Center calculates the geographical center point of all GPS coordinates.
 // This uses simple arithmetic mean, which works well for small areas.
-// For larger areas spanning continents, more sophisticated calculations might be needed.
+// See SphericalCenter and GeographicMedian for tracks crossing the antimeridian or
+// containing outliers.
 func (p Points) Center() (lat, lng float64) {
 	if len(p) == 0 {
 		return 0, 0
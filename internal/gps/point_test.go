@@ -432,3 +432,76 @@ func TestPointsLenMethod(t *testing.T) {
 		})
 	}
 }
+
+func TestPointsCategories(t *testing.T) {
+	points := Points{
+		{Category: "work"},
+		{Category: ""},
+		{Category: "food"},
+		{Category: "work"},
+	}
+
+	got := points.Categories()
+	want := []string{"food", "work"}
+	if len(got) != len(want) {
+		t.Fatalf("Points.Categories() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Points.Categories()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if got := (Points{}).Categories(); len(got) != 0 {
+		t.Errorf("Points.Categories() on empty = %v, want none", got)
+	}
+}
+
+func TestPointsUsers(t *testing.T) {
+	points := Points{
+		{User: "bob"},
+		{User: ""},
+		{User: "alice"},
+		{User: "bob"},
+	}
+
+	got := points.Users()
+	want := []string{"alice", "bob"}
+	if len(got) != len(want) {
+		t.Fatalf("Points.Users() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Points.Users()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	tracks := points.ByUser()
+	if len(tracks) != 3 {
+		t.Fatalf("Points.ByUser() returned %d tracks, want 3", len(tracks))
+	}
+	if len(tracks["bob"]) != 2 || len(tracks["alice"]) != 1 || len(tracks[""]) != 1 {
+		t.Errorf("Points.ByUser() track sizes = bob:%d alice:%d none:%d, want 2, 1, 1",
+			len(tracks["bob"]), len(tracks["alice"]), len(tracks[""]))
+	}
+}
+
+func TestPointsHasElevation(t *testing.T) {
+	tests := []struct {
+		name   string
+		points Points
+		want   bool
+	}{
+		{name: "empty", want: false},
+		{name: "no elevation", points: Points{{Latitude: 1}, {Latitude: 2}}, want: false},
+		{name: "some elevation", points: Points{{Latitude: 1}, {Latitude: 2, Elevation: -12}}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.points.HasElevation(); got != tt.want {
+				t.Errorf("Points.HasElevation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package gps
+
+// Polygon represents a closed geographical ring of GPS points.
+// The ring is implicitly closed; the last vertex does not need to repeat the first.
+//
+// @type Polygon []Point
+// @description Closed ring of coordinates used for area containment tests
+// @methods Contains
+type Polygon []Point
+
+// Contains reports whether the given coordinate lies inside the polygon using the
+// even-odd ray casting rule.
+//
+// @method Contains
+// @description Point-in-polygon test on latitude/longitude coordinates
+// @param lat float64 Latitude of the coordinate to test
+// @param lng float64 Longitude of the coordinate to test
+// @return bool True if the coordinate is inside the polygon
+// @limitations Treats coordinates as planar; polygons must not cross the antimeridian
+// @example inside := park.Contains(point.Latitude, point.Longitude)
+func (poly Polygon) Contains(lat, lng float64) bool {
+	if len(poly) < 3 {
+		return false
+	}
+
+	inside := false
+	j := len(poly) - 1
+	for i := range poly {
+		yi, xi := poly[i].Latitude, poly[i].Longitude
+		yj, xj := poly[j].Latitude, poly[j].Longitude
+
+		// Toggle on every edge crossed by a ray cast eastward from the coordinate
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+		j = i
+	}
+
+	return inside
+}
+
+// Area represents a polygon with optional holes, such as a park boundary with a lake
+// cut out of it.
+//
+// @struct Area
+// @description Polygon area with interior exclusions
+// @property Outer Polygon Outer boundary ring
+// @property Holes []Polygon Interior rings excluded from the area
+type Area struct {
+	Outer Polygon   // @field Outer Outer boundary ring
+	Holes []Polygon // @field Holes Interior rings excluded from the area
+}
+
+// Contains reports whether the coordinate is inside the outer ring and outside every hole.
+func (a Area) Contains(lat, lng float64) bool {
+	if !a.Outer.Contains(lat, lng) {
+		return false
+	}
+	for _, hole := range a.Holes {
+		if hole.Contains(lat, lng) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterAreas keeps the GPS points that fall inside at least one include area (when any
+// are given) and outside every exclude area.
+//
+// @method FilterAreas
+// @description Filters GPS points using inclusion and exclusion polygons
+// @param include []Area Areas a point must fall within (nil keeps every point)
+// @param exclude []Area Areas whose points are removed
+// @return Points Filtered collection in the original order
+// @example inPark := points.FilterAreas(parkBoundary, nil)
+func (p Points) FilterAreas(include, exclude []Area) Points {
+	return p.Filter(InAreas(include, exclude))
+}
+
+// InAreas returns a predicate that reports whether a point falls inside at least one
+// include area (when any are given) and outside every exclude area. It can be passed
+// to Seq.Filter to apply area filters lazily.
+func InAreas(include, exclude []Area) func(Point) bool {
+	return func(point Point) bool {
+		if len(include) > 0 && !containedInAny(include, point) {
+			return false
+		}
+		return !containedInAny(exclude, point)
+	}
+}
+
+// containedInAny reports whether the point falls inside any of the given areas.
+func containedInAny(areas []Area, point Point) bool {
+	for _, area := range areas {
+		if area.Contains(point.Latitude, point.Longitude) {
+			return true
+		}
+	}
+	return false
+}
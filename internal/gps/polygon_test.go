@@ -0,0 +1,106 @@
+package gps
+
+import "testing"
+
+func TestPolygonContains(t *testing.T) {
+	square := Polygon{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 10},
+		{Latitude: 10, Longitude: 10},
+		{Latitude: 10, Longitude: 0},
+	}
+
+	// L-shaped concave polygon
+	concave := Polygon{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 10},
+		{Latitude: 5, Longitude: 10},
+		{Latitude: 5, Longitude: 5},
+		{Latitude: 10, Longitude: 5},
+		{Latitude: 10, Longitude: 0},
+	}
+
+	tests := []struct {
+		name    string
+		polygon Polygon
+		lat     float64
+		lng     float64
+		want    bool
+	}{
+		{"inside square", square, 5, 5, true},
+		{"outside square", square, 15, 5, false},
+		{"west of square", square, 5, -1, false},
+		{"inside concave arm", concave, 8, 2, true},
+		{"inside concave notch", concave, 8, 8, false},
+		{"degenerate polygon", Polygon{{Latitude: 0}, {Latitude: 1}}, 0.5, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.polygon.Contains(tt.lat, tt.lng); got != tt.want {
+				t.Errorf("Polygon.Contains(%v, %v) = %v, want %v", tt.lat, tt.lng, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAreaContains(t *testing.T) {
+	park := Area{
+		Outer: Polygon{
+			{Latitude: 0, Longitude: 0},
+			{Latitude: 0, Longitude: 10},
+			{Latitude: 10, Longitude: 10},
+			{Latitude: 10, Longitude: 0},
+		},
+		Holes: []Polygon{{
+			{Latitude: 4, Longitude: 4},
+			{Latitude: 4, Longitude: 6},
+			{Latitude: 6, Longitude: 6},
+			{Latitude: 6, Longitude: 4},
+		}},
+	}
+
+	if !park.Contains(2, 2) {
+		t.Error("Area.Contains() should include points inside the outer ring")
+	}
+	if park.Contains(5, 5) {
+		t.Error("Area.Contains() should exclude points inside a hole")
+	}
+	if park.Contains(20, 20) {
+		t.Error("Area.Contains() should exclude points outside the outer ring")
+	}
+}
+
+func TestPointsFilterAreas(t *testing.T) {
+	square := func(minLat, minLng, maxLat, maxLng float64) Area {
+		return Area{Outer: Polygon{
+			{Latitude: minLat, Longitude: minLng},
+			{Latitude: minLat, Longitude: maxLng},
+			{Latitude: maxLat, Longitude: maxLng},
+			{Latitude: maxLat, Longitude: minLng},
+		}}
+	}
+
+	points := Points{
+		{Latitude: 1, Longitude: 1, Title: "inside"},
+		{Latitude: 5, Longitude: 5, Title: "excluded"},
+		{Latitude: 20, Longitude: 20, Title: "outside"},
+	}
+
+	include := []Area{square(0, 0, 10, 10)}
+	exclude := []Area{square(4, 4, 6, 6)}
+
+	got := points.FilterAreas(include, exclude)
+	if len(got) != 1 || got[0].Title != "inside" {
+		t.Errorf("FilterAreas(include, exclude) = %v, want only the inside point", got)
+	}
+
+	got = points.FilterAreas(nil, exclude)
+	if len(got) != 2 || got[1].Title != "outside" {
+		t.Errorf("FilterAreas(nil, exclude) = %v, want inside and outside points", got)
+	}
+
+	if got := points.FilterAreas(nil, nil); len(got) != len(points) {
+		t.Errorf("FilterAreas(nil, nil) returned %d points, want %d", len(got), len(points))
+	}
+}
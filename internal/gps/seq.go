@@ -0,0 +1,92 @@
+package gps
+
+// Seq is a lazy sequence of GPS points. Calling it pushes points to yield one at a
+// time until the sequence is exhausted or yield returns false.
+//
+// @type Seq func(yield func(Point) bool)
+// @description Lazy, allocation-free alternative to Points for very large datasets
+// @description Has the same shape as iter.Seq[Point], so it works with range-over-func
+// @description once the module moves to Go 1.23
+// @methods Filter, Map, Take, Unique, Collect, Count
+// @example points.All().Filter(keep).Take(100).Collect()
+type Seq func(yield func(Point) bool)
+
+// All returns a sequence over the points in slice order.
+func (p Points) All() Seq {
+	return func(yield func(Point) bool) {
+		for _, point := range p {
+			if !yield(point) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns a sequence of the points for which keep returns true.
+//
+// @method Filter
+// @description Lazily drops points from a sequence
+// @param keep func(Point) bool Predicate deciding which points pass through
+// @return Seq Filtered sequence; nothing is evaluated until it is iterated
+// @example moving := seq.Filter(func(p Point) bool { return p.Category != "stop" })
+func (s Seq) Filter(keep func(Point) bool) Seq {
+	return func(yield func(Point) bool) {
+		s(func(point Point) bool {
+			if !keep(point) {
+				return true
+			}
+			return yield(point)
+		})
+	}
+}
+
+// Map returns a sequence of the points transformed by fn.
+func (s Seq) Map(fn func(Point) Point) Seq {
+	return func(yield func(Point) bool) {
+		s(func(point Point) bool {
+			return yield(fn(point))
+		})
+	}
+}
+
+// Take returns a sequence of at most n points, stopping the source early.
+func (s Seq) Take(n int) Seq {
+	return func(yield func(Point) bool) {
+		if n <= 0 {
+			return
+		}
+		taken := 0
+		s(func(point Point) bool {
+			taken++
+			return yield(point) && taken < n
+		})
+	}
+}
+
+// Unique returns a sequence that skips points at coordinates already seen, matching
+// Points.RemoveDuplicates. Only the set of seen coordinates is kept in memory.
+func (s Seq) Unique() Seq {
+	return func(yield func(Point) bool) {
+		s.Filter(unique())(yield)
+	}
+}
+
+// Collect gathers the sequence into a Points slice.
+func (s Seq) Collect() Points {
+	var points Points
+	s(func(point Point) bool {
+		points = append(points, point)
+		return true
+	})
+	return points
+}
+
+// Count returns the number of points in the sequence without storing them.
+func (s Seq) Count() int {
+	var count int
+	s(func(Point) bool {
+		count++
+		return true
+	})
+	return count
+}
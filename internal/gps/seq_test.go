@@ -0,0 +1,83 @@
+package gps
+
+import "testing"
+
+func TestSeq(t *testing.T) {
+	points := Points{
+		{Latitude: 1, Category: "walk"},
+		{Latitude: 2, Category: "stop"},
+		{Latitude: 3, Category: "walk"},
+		{Latitude: 3, Category: "walk"},
+		{Latitude: 5, Category: "walk"},
+	}
+	walking := func(p Point) bool { return p.Category == "walk" }
+	shift := func(p Point) Point { p.Longitude = p.Latitude * 10; return p }
+
+	tests := []struct {
+		name    string
+		seq     Seq
+		wantLat []float64
+	}{
+		{name: "all", seq: points.All(), wantLat: []float64{1, 2, 3, 3, 5}},
+		{name: "filter", seq: points.All().Filter(walking), wantLat: []float64{1, 3, 3, 5}},
+		{name: "unique", seq: points.All().Unique(), wantLat: []float64{1, 2, 3, 5}},
+		{name: "take", seq: points.All().Filter(walking).Take(2), wantLat: []float64{1, 3}},
+		{name: "take none", seq: points.All().Take(0)},
+		{name: "take more than available", seq: points.All().Take(10), wantLat: []float64{1, 2, 3, 3, 5}},
+		{name: "map", seq: points.All().Map(shift).Take(1), wantLat: []float64{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.seq.Collect()
+			if len(got) != len(tt.wantLat) {
+				t.Fatalf("Collect() = %d points, want %d", len(got), len(tt.wantLat))
+			}
+			for i, want := range tt.wantLat {
+				if got[i].Latitude != want {
+					t.Errorf("Collect()[%d].Latitude = %v, want %v", i, got[i].Latitude, want)
+				}
+			}
+			if count := tt.seq.Count(); count != len(tt.wantLat) {
+				t.Errorf("Count() = %d, want %d", count, len(tt.wantLat))
+			}
+		})
+	}
+
+	if got := points.All().Map(shift).Collect(); got[1].Longitude != 20 {
+		t.Errorf("Map() longitude = %v, want 20", got[1].Longitude)
+	}
+}
+
+func TestSeqStopsEarly(t *testing.T) {
+	var visited int
+	source := Seq(func(yield func(Point) bool) {
+		for i := 0; i < 1000; i++ {
+			visited++
+			if !yield(Point{Latitude: float64(i)}) {
+				return
+			}
+		}
+	})
+
+	source.Filter(func(p Point) bool { return int(p.Latitude)%2 == 0 }).Take(3).Collect()
+	if visited != 5 {
+		t.Errorf("source visited %d points, want 5 (lazy evaluation)", visited)
+	}
+}
+
+func TestInAreas(t *testing.T) {
+	square := Area{Outer: Polygon{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 10}, {Latitude: 10, Longitude: 10}, {Latitude: 10, Longitude: 0}}}
+	inside := Point{Latitude: 5, Longitude: 5}
+	outside := Point{Latitude: 20, Longitude: 20}
+
+	if !InAreas(nil, nil)(outside) {
+		t.Error("InAreas(nil, nil) rejected a point")
+	}
+	if !InAreas([]Area{square}, nil)(inside) || InAreas([]Area{square}, nil)(outside) {
+		t.Error("InAreas(include) did not keep only points inside the area")
+	}
+	if InAreas(nil, []Area{square})(inside) || !InAreas(nil, []Area{square})(outside) {
+		t.Error("InAreas(exclude) did not drop only points inside the area")
+	}
+}
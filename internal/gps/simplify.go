@@ -0,0 +1,48 @@
+package gps
+
+// Simplify reduces the number of points with the Douglas-Peucker algorithm, keeping
+// only the points needed to stay within tolerance meters of the original path.
+// The first and last points are always kept.
+//
+// @method Simplify
+// @description Removes points that add no visible detail to the path
+// @param tolerance float64 Maximum distance in meters between the original and simplified path
+// @return Points New collection in the original order; a non-positive tolerance keeps every point
+// @complexity O(n log n) typical, O(n²) worst case
+// @example simplified := points.Simplify(5)
+func (p Points) Simplify(tolerance float64) Points {
+	if tolerance <= 0 || len(p) < 3 {
+		return append(Points(nil), p...)
+	}
+
+	keep := make([]bool, len(p))
+	keep[0], keep[len(p)-1] = true, true
+
+	// Iterative to avoid deep recursion on long, detailed tracks
+	type span struct{ first, last int }
+	stack := []span{{0, len(p) - 1}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		farthest, maxDistance := -1, tolerance
+		for i := s.first + 1; i < s.last; i++ {
+			if d := p[i].DistanceToSegment(p[s.first], p[s.last]); d > maxDistance {
+				farthest, maxDistance = i, d
+			}
+		}
+		if farthest < 0 {
+			continue
+		}
+		keep[farthest] = true
+		stack = append(stack, span{s.first, farthest}, span{farthest, s.last})
+	}
+
+	var result Points
+	for i, point := range p {
+		if keep[i] {
+			result = append(result, point)
+		}
+	}
+	return result
+}
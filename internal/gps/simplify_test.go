@@ -0,0 +1,36 @@
+package gps
+
+import "testing"
+
+func TestPointsSimplify(t *testing.T) {
+	// A straight line north with a 0.5 m wobble and a 110 m detour east
+	points := Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0.001, Longitude: 0.000005},
+		{Latitude: 0.002, Longitude: 0},
+		{Latitude: 0.003, Longitude: 0.001},
+		{Latitude: 0.004, Longitude: 0},
+	}
+
+	tests := []struct {
+		name      string
+		tolerance float64
+		want      int
+	}{
+		{name: "disabled", tolerance: 0, want: 5},
+		{name: "drops the wobble", tolerance: 5, want: 4},
+		{name: "drops the detour", tolerance: 200, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := points.Simplify(tt.tolerance)
+			if len(got) != tt.want {
+				t.Fatalf("Points.Simplify(%v) kept %d points, want %d", tt.tolerance, len(got), tt.want)
+			}
+			if got[0] != points[0] || got[len(got)-1] != points[len(points)-1] {
+				t.Errorf("Points.Simplify(%v) did not keep both endpoints", tt.tolerance)
+			}
+		})
+	}
+}
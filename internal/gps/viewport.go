@@ -0,0 +1,68 @@
+package gps
+
+import (
+	"math"
+)
+
+// TileSize is the edge length in pixels of a Web Mercator map tile at zoom level 0.
+const TileSize = 256
+
+// MaxZoom is the closest zoom level returned by ZoomForBounds, matching Google Maps
+// and most tile servers.
+const MaxZoom = 21
+
+// BoundsPadded calculates the bounding box of all GPS points expanded on every side
+// by a fraction of its extent, clamped to valid latitude and longitude ranges.
+//
+// @method BoundsPadded
+// @description Computes a viewport box with breathing room around the track
+// @param fraction float64 Padding per side as a fraction of the extent (0.1 = 10%)
+// @return minLat float64 Southern edge
+// @return maxLat float64 Northern edge
+// @return minLng float64 Western edge
+// @return maxLng float64 Eastern edge
+// @note A single point has zero extent, so its padded bounds are the point itself
+// @example minLat, maxLat, minLng, maxLng := points.BoundsPadded(0.1)
+func (p Points) BoundsPadded(fraction float64) (minLat, maxLat, minLng, maxLng float64) {
+	minLat, maxLat, minLng, maxLng = p.Bounds()
+	latPad := (maxLat - minLat) * fraction
+	lngPad := (maxLng - minLng) * fraction
+
+	return math.Max(minLat-latPad, -90), math.Min(maxLat+latPad, 90),
+		math.Max(minLng-lngPad, -180), math.Min(maxLng+lngPad, 180)
+}
+
+// ZoomForBounds estimates the closest Web Mercator zoom level at which the bounding
+// box fits inside a viewport of the given size in pixels.
+//
+// @function ZoomForBounds
+// @description Computes a fitBounds-style zoom level without the Google Maps JS API
+// @param minLat float64 Southern edge
+// @param maxLat float64 Northern edge
+// @param minLng float64 Western edge
+// @param maxLng float64 Eastern edge
+// @param width int Viewport width in pixels
+// @param height int Viewport height in pixels
+// @return int Zoom level between 0 and MaxZoom
+// @example zoom := gps.ZoomForBounds(minLat, maxLat, minLng, maxLng, 800, 600)
+func ZoomForBounds(minLat, maxLat, minLng, maxLng float64, width, height int) int {
+	if width <= 0 || height <= 0 {
+		return 0
+	}
+
+	// Fractions of the whole world covered by the box along each axis
+	west, north := LatLngToWorld(maxLat, minLng)
+	east, south := LatLngToWorld(minLat, maxLng)
+	latFraction := (south - north) / TileSize
+	lngFraction := (east - west) / TileSize
+
+	zoom := float64(MaxZoom)
+	if latFraction > 0 {
+		zoom = math.Min(zoom, math.Log2(float64(height)/TileSize/latFraction))
+	}
+	if lngFraction > 0 {
+		zoom = math.Min(zoom, math.Log2(float64(width)/TileSize/lngFraction))
+	}
+
+	return int(math.Max(0, math.Floor(zoom)))
+}
@@ -0,0 +1,55 @@
+package gps
+
+import (
+	"testing"
+)
+
+func TestPointsBoundsPadded(t *testing.T) {
+	points := Points{
+		{Latitude: 10, Longitude: 20},
+		{Latitude: 12, Longitude: 24},
+	}
+
+	minLat, maxLat, minLng, maxLng := points.BoundsPadded(0.5)
+	if minLat != 9 || maxLat != 13 || minLng != 18 || maxLng != 26 {
+		t.Errorf("BoundsPadded(0.5) = %v,%v,%v,%v, want 9,13,18,26", minLat, maxLat, minLng, maxLng)
+	}
+
+	// Padding is clamped to the valid coordinate ranges
+	wide := Points{{Latitude: -80, Longitude: -170}, {Latitude: 80, Longitude: 170}}
+	minLat, maxLat, minLng, maxLng = wide.BoundsPadded(0.25)
+	if minLat != -90 || maxLat != 90 || minLng != -180 || maxLng != 180 {
+		t.Errorf("BoundsPadded() clamped = %v,%v,%v,%v, want world bounds", minLat, maxLat, minLng, maxLng)
+	}
+
+	// A single point has no extent to pad
+	single := Points{{Latitude: 5, Longitude: 5}}
+	if minLat, maxLat, _, _ := single.BoundsPadded(1); minLat != 5 || maxLat != 5 {
+		t.Errorf("BoundsPadded() single point lat = %v..%v, want 5..5", minLat, maxLat)
+	}
+}
+
+func TestZoomForBounds(t *testing.T) {
+	tests := []struct {
+		name                           string
+		minLat, maxLat, minLng, maxLng float64
+		width, height                  int
+		want                           int
+	}{
+		{"whole world in one tile", -85.0511287798, 85.0511287798, -180, 180, 256, 256, 0},
+		{"whole world in two tiles", -85.0511287798, 85.0511287798, -180, 180, 512, 512, 1},
+		{"half the longitudes", -1, 1, 0, 180, 256, 256, 1},
+		{"city block", 37.7749, 37.7849, -122.4194, -122.4094, 800, 600, 16},
+		{"single point", 10, 10, 10, 10, 800, 600, MaxZoom},
+		{"empty viewport", 0, 1, 0, 1, 0, 600, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ZoomForBounds(tt.minLat, tt.maxLat, tt.minLng, tt.maxLng, tt.width, tt.height)
+			if got != tt.want {
+				t.Errorf("ZoomForBounds() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Package gpx provides reading and writing of GPS Exchange Format (GPX) files.
+//
+// @title GPX Package
+// @version 1.0
+// @description Loads tracks and planned routes from GPX 1.0 and 1.1 documents
+// @description Used to compare recorded tracks against planned routes
+// @description Exports processed tracks as GPX 1.1 for GPS devices and other tools
+//
+// Features:
+// - Track points (trk/trkseg/trkpt) and route points (rte/rtept)
+// - Optional timestamps, names, and descriptions
+// - Waypoints are used only when the document has no track or route
+// - Written documents hold one track per user
+package gpx
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// document is the subset of a GPX document needed to extract points.
+type document struct {
+	Waypoints []waypoint `xml:"wpt"`
+	Routes    []struct {
+		Points []waypoint `xml:"rtept"`
+	} `xml:"rte"`
+	Tracks []struct {
+		Segments []struct {
+			Points []waypoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// waypoint is a GPX wptType, shared by wpt, rtept, and trkpt elements.
+type waypoint struct {
+	Latitude    float64 `xml:"lat,attr"`
+	Longitude   float64 `xml:"lon,attr"`
+	Elevation   float64 `xml:"ele"`
+	Time        string  `xml:"time"`
+	Name        string  `xml:"name"`
+	Description string  `xml:"desc"`
+}
+
+// ReadFile loads the points of every track and route in a GPX file.
+//
+// @function ReadFile
+// @description Reads GPS points from a GPX file
+// @param ctx context.Context Stops reading when cancelled
+// @param filename string Path to the GPX document
+// @return gps.Points Track points followed by route points, in document order
+// @return error Error if the file cannot be read, parsed, or contains no points
+// @example route, err := gpx.ReadFile(ctx, "planned.gpx")
+func ReadFile(ctx context.Context, filename string) (gps.Points, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open GPX file %s: %w", filename, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	points, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse GPX file %s: %w", filename, err)
+	}
+	return points, nil
+}
+
+// Parse extracts the points of every track and route in a GPX document.
+// Waypoints are returned only when there are no tracks or routes, since they are
+// usually unordered points of interest rather than a path.
+func Parse(data []byte) (gps.Points, error) {
+	var doc document
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var raw []waypoint
+	for _, track := range doc.Tracks {
+		for _, segment := range track.Segments {
+			raw = append(raw, segment.Points...)
+		}
+	}
+	for _, route := range doc.Routes {
+		raw = append(raw, route.Points...)
+	}
+	if len(raw) == 0 {
+		raw = doc.Waypoints
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("no track, route, or waypoint elements found")
+	}
+
+	points := make(gps.Points, 0, len(raw))
+	for i, wpt := range raw {
+		point, err := wpt.point()
+		if err != nil {
+			return nil, fmt.Errorf("point %d: %w", i+1, err)
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// point converts a GPX waypoint into a GPS point. The timestamp is left zero for
+// untimed points, which is typical of planned routes.
+func (w waypoint) point() (gps.Point, error) {
+	if w.Latitude < -90 || w.Latitude > 90 || w.Longitude < -180 || w.Longitude > 180 {
+		return gps.Point{}, fmt.Errorf("coordinates out of range (%v, %v)", w.Latitude, w.Longitude)
+	}
+
+	point := gps.Point{
+		Latitude:    w.Latitude,
+		Longitude:   w.Longitude,
+		Title:       strings.TrimSpace(w.Name),
+		Description: strings.TrimSpace(w.Description),
+		Elevation:   w.Elevation,
+	}
+	if value := strings.TrimSpace(w.Time); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return gps.Point{}, fmt.Errorf("invalid time %q: %w", value, err)
+		}
+		point.Timestamp = t
+	}
+	return point, nil
+}
@@ -0,0 +1,143 @@
+// Package gpx_test provides unit tests for GPX reading.
+// It tests tracks, routes, waypoint fallback, optional timestamps,
+// and error handling for malformed documents.
+package gpx
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantPoints int
+		wantTitle  string
+		wantErr    bool
+	}{
+		{
+			name: "track with segments",
+			data: `<?xml version="1.0"?>
+<gpx version="1.1" xmlns="http://www.topografix.com/GPX/1/1">
+  <trk><name>Morning run</name>
+    <trkseg>
+      <trkpt lat="37.7749" lon="-122.4194"><ele>10</ele><time>2025-10-28T09:00:00Z</time><name>Start</name></trkpt>
+      <trkpt lat="37.7750" lon="-122.4180"><time>2025-10-28T09:01:00Z</time></trkpt>
+    </trkseg>
+    <trkseg>
+      <trkpt lat="37.7760" lon="-122.4170"><time>2025-10-28T09:05:00Z</time></trkpt>
+    </trkseg>
+  </trk>
+</gpx>`,
+			wantPoints: 3,
+			wantTitle:  "Start",
+		},
+		{
+			name:       "untimed route",
+			data:       `<gpx><rte><rtept lat="1" lon="2"><name> Trailhead </name></rtept><rtept lat="1.1" lon="2.1"/></rte></gpx>`,
+			wantPoints: 2,
+			wantTitle:  "Trailhead",
+		},
+		{
+			name:       "waypoints only",
+			data:       `<gpx><wpt lat="1" lon="2"><name>Summit</name></wpt></gpx>`,
+			wantPoints: 1,
+			wantTitle:  "Summit",
+		},
+		{
+			name:    "empty document",
+			data:    `<gpx></gpx>`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid time",
+			data:    `<gpx><trk><trkseg><trkpt lat="1" lon="2"><time>yesterday</time></trkpt></trkseg></trk></gpx>`,
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			data:    `<gpx><rte><rtept lat="91" lon="2"/></rte></gpx>`,
+			wantErr: true,
+		},
+		{
+			name:    "not xml",
+			data:    `{"type":"Feature"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points, err := Parse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(points) != tt.wantPoints {
+				t.Fatalf("Parse() got %d points, want %d", len(points), tt.wantPoints)
+			}
+			if points[0].Title != tt.wantTitle {
+				t.Errorf("Parse() first title = %q, want %q", points[0].Title, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestParseTimestamps(t *testing.T) {
+	points, err := Parse([]byte(`<gpx>
+  <trk><trkseg><trkpt lat="1" lon="2"><time>2025-10-28T09:00:00Z</time></trkpt></trkseg></trk>
+  <rte><rtept lat="3" lon="4"/></rte>
+</gpx>`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := time.Date(2025, 10, 28, 9, 0, 0, 0, time.UTC)
+	if !points[0].Timestamp.Equal(want) {
+		t.Errorf("Parse() track timestamp = %v, want %v", points[0].Timestamp, want)
+	}
+	if !points[1].Timestamp.IsZero() {
+		t.Errorf("Parse() route timestamp = %v, want zero", points[1].Timestamp)
+	}
+	if points[1].Latitude != 3 || points[1].Longitude != 4 {
+		t.Errorf("Parse() route point = (%v, %v), want (3, 4)", points[1].Latitude, points[1].Longitude)
+	}
+}
+
+func TestParseElevation(t *testing.T) {
+	points, err := Parse([]byte(`<gpx><trk><trkseg>
+  <trkpt lat="1" lon="2"><ele>1523.4</ele></trkpt>
+  <trkpt lat="1.1" lon="2.1"/>
+</trkseg></trk></gpx>`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if points[0].Elevation != 1523.4 {
+		t.Errorf("Parse() elevation = %v, want 1523.4", points[0].Elevation)
+	}
+	if points[1].Elevation != 0 {
+		t.Errorf("Parse() missing elevation = %v, want 0", points[1].Elevation)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "route.gpx")
+	if err := os.WriteFile(path, []byte(`<gpx><rte><rtept lat="1" lon="2"/></rte></gpx>`), 0644); err != nil {
+		t.Fatalf("Failed to write GPX file: %v", err)
+	}
+
+	points, err := ReadFile(context.Background(), path)
+	if err != nil || len(points) != 1 {
+		t.Errorf("ReadFile() = %d points, %v, want 1 point", len(points), err)
+	}
+
+	if _, err := ReadFile(context.Background(), filepath.Join(t.TempDir(), "missing.gpx")); err == nil {
+		t.Error("ReadFile() on missing file error = nil, want error")
+	}
+}
@@ -0,0 +1,133 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// namespace is the GPX 1.1 schema namespace.
+const namespace = "http://www.topografix.com/GPX/1/1"
+
+// creator identifies this program in the creator attribute of written documents.
+const creator = "geo-chrono"
+
+// outputDocument is the root gpx element of a written document.
+type outputDocument struct {
+	XMLName xml.Name      `xml:"gpx"`
+	Version string        `xml:"version,attr"`
+	Creator string        `xml:"creator,attr"`
+	XMLNS   string        `xml:"xmlns,attr"`
+	Name    string        `xml:"metadata>name,omitempty"`
+	Tracks  []outputTrack `xml:"trk"`
+}
+
+// outputTrack is a trk element holding the points of one user in a single segment.
+type outputTrack struct {
+	Name   string        `xml:"name,omitempty"`
+	Points []outputPoint `xml:"trkseg>trkpt"`
+}
+
+// outputPoint is a trkpt element. Optional children are omitted when empty, in the
+// element order required by the GPX schema.
+type outputPoint struct {
+	Latitude    float64  `xml:"lat,attr"`
+	Longitude   float64  `xml:"lon,attr"`
+	Elevation   *float64 `xml:"ele,omitempty"`
+	Time        string   `xml:"time,omitempty"`
+	Name        string   `xml:"name,omitempty"`
+	Description string   `xml:"desc,omitempty"`
+	Type        string   `xml:"type,omitempty"`
+}
+
+// Write encodes GPS points as a GPX 1.1 document with one track per user, so the
+// track can be opened in GPS devices and other mapping tools. Points keep their
+// timestamps, titles, descriptions, and categories; elevations are written only
+// when the track has elevation data.
+//
+// @function Write
+// @description Exports a GPS track as GPX
+// @param w io.Writer Destination for the GPX document
+// @param points gps.Points Chronologically ordered GPS points
+// @param name string Document name stored in the metadata, also naming a single track
+// @return error Error if encoding or writing fails
+// @example err := gpx.Write(file, points, "Morning run")
+func Write(w io.Writer, points gps.Points, name string) error {
+	doc := outputDocument{Version: "1.1", Creator: creator, XMLNS: namespace, Name: name}
+
+	users := points.Users()
+	if len(users) > 1 {
+		tracks := points.ByUser()
+		// Points without a user come first, as an unnamed track
+		if unassigned := tracks[""]; len(unassigned) > 0 {
+			doc.Tracks = append(doc.Tracks, newTrack("", unassigned))
+		}
+		for _, user := range users {
+			doc.Tracks = append(doc.Tracks, newTrack(user, tracks[user]))
+		}
+	} else if len(points) > 0 {
+		doc.Tracks = []outputTrack{newTrack(name, points)}
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("cannot encode GPX: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteFile writes the GPS points as a GPX document to the named file.
+//
+// @function WriteFile
+// @description Creates a GPX file from GPS points
+// @param filename string Path of the GPX file to create
+// @param points gps.Points Chronologically ordered GPS points
+// @param name string Document and track name
+// @return error Error if the file cannot be created or written
+// @example err := gpx.WriteFile("track.gpx", points, cfg.Map.Title)
+func WriteFile(filename string, points gps.Points, name string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("cannot create GPX file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	if err := Write(file, points, name); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// newTrack converts the points of one track into trkpt elements.
+func newTrack(name string, points gps.Points) outputTrack {
+	withElevation := points.HasElevation()
+	track := outputTrack{Name: name, Points: make([]outputPoint, len(points))}
+	for i, point := range points {
+		wpt := outputPoint{
+			Latitude:    point.Latitude,
+			Longitude:   point.Longitude,
+			Name:        point.Title,
+			Description: point.Description,
+			Type:        point.Category,
+		}
+		if withElevation {
+			elevation := point.Elevation
+			wpt.Elevation = &elevation
+		}
+		// Untimed points, such as planned route points, have no time element
+		if !point.Timestamp.IsZero() {
+			wpt.Time = point.Timestamp.UTC().Format(time.RFC3339)
+		}
+		track.Points[i] = wpt
+	}
+	return track
+}
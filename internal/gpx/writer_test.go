@@ -0,0 +1,84 @@
+package gpx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		points     gps.Points
+		wantTracks int
+		want       []string
+		notWant    []string
+	}{
+		{
+			name: "single track",
+			points: gps.Points{
+				{Timestamp: start, Latitude: 37.7749, Longitude: -122.4194, Title: "Start", Description: "Café & bar", Category: "stop"},
+				{Timestamp: start.Add(time.Minute), Latitude: 37.7750, Longitude: -122.4180},
+			},
+			wantTracks: 1,
+			want:       []string{`<time>2025-10-28T10:00:00Z</time>`, `<name>Start</name>`, `<desc>Café &amp; bar</desc>`, `<type>stop</type>`, `<trk>`, `<name>Morning run</name>`},
+			notWant:    []string{`<ele>`},
+		},
+		{
+			name: "elevation and untimed points",
+			points: gps.Points{
+				{Latitude: 46.5, Longitude: 7.9, Elevation: 2100},
+				{Latitude: 46.6, Longitude: 8.0},
+			},
+			wantTracks: 1,
+			want:       []string{`<ele>2100</ele>`, `<ele>0</ele>`},
+			notWant:    []string{`<time>`},
+		},
+		{
+			name: "one track per user",
+			points: gps.Points{
+				{Timestamp: start, Latitude: 1, Longitude: 1, User: "bob"},
+				{Timestamp: start, Latitude: 2, Longitude: 2, User: "alice"},
+				{Timestamp: start.Add(time.Minute), Latitude: 3, Longitude: 3, User: "bob"},
+			},
+			wantTracks: 2,
+			want:       []string{`<name>alice</name>`, `<name>bob</name>`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.points, "Morning run"); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			output := buf.String()
+			if got := strings.Count(output, "<trk>"); got != tt.wantTracks {
+				t.Errorf("Write() wrote %d tracks, want %d", got, tt.wantTracks)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(output, want) {
+					t.Errorf("Write() output missing %q:\n%s", want, output)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(output, notWant) {
+					t.Errorf("Write() output contains %q", notWant)
+				}
+			}
+
+			// Written documents read back with every point
+			points, err := Parse(buf.Bytes())
+			if err != nil {
+				t.Fatalf("Parse() of written document error = %v", err)
+			}
+			if len(points) != len(tt.points) {
+				t.Errorf("Parse() of written document = %d points, want %d", len(points), len(tt.points))
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Package grpcserver offers track processing and map rendering over gRPC.
+//
+// @title gRPC Service Package
+// @version 1.0
+// @description Implements the GeoChrono service of api/geochrono/v1
+// @description Lets other services use geo-chrono as a rendering microservice
+//
+// Features:
+// - ProcessTrack storing a streamed track like an upload to the HTTP track API
+// - GenerateMap streaming a track's HTML map, KML, GeoJSON, or GPX back in chunks
+// - The same processing pipeline, storage backend, and API tokens as serve mode
+package grpcserver
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	geochronov1 "github.com/saratily/geo-chrono/api/geochrono/v1"
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/export"
+	"github.com/saratily/geo-chrono/internal/geojson"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/gpx"
+	"github.com/saratily/geo-chrono/internal/kml"
+	"github.com/saratily/geo-chrono/internal/mapgen"
+	"github.com/saratily/geo-chrono/internal/metrics"
+	"github.com/saratily/geo-chrono/internal/server"
+	"github.com/saratily/geo-chrono/internal/store"
+)
+
+// ChunkSize is the largest number of bytes GenerateMap sends in one MapChunk.
+const ChunkSize = 64 << 10
+
+// Service implements the GeoChrono gRPC service on top of a track store and the
+// track processing of serve mode.
+//
+// @struct Service
+// @description gRPC service processing and rendering streamed tracks
+// @property tracks store.Store Storage keeping tracks processed by ProcessTrack
+// @property load server.Loader Processes a track into a job for rendering
+// @property users []config.ServerUserConfig API accounts (empty for an open service)
+// @property maxSize int64 Largest accepted stream in bytes
+type Service struct {
+	geochronov1.UnimplementedGeoChronoServer
+
+	tracks  store.Store               // @field tracks Storage keeping tracks processed by ProcessTrack
+	load    server.Loader             // @field load Processes a track into a job for rendering
+	users   []config.ServerUserConfig // @field users API accounts (empty for an open service)
+	maxSize int64                     // @field maxSize Largest accepted stream in bytes
+}
+
+// New returns the service for the server settings of cfg. Like the HTTP track
+// API, it takes an API token in the "authorization" metadata ("Bearer <token>")
+// whenever server.users are configured, and rejects streams larger than
+// server.max_upload_size.
+//
+// @function New
+// @description Creates the gRPC service of serve mode
+// @param cfg *config.ServerConfig Server settings with users and upload limit
+// @param tracks store.Store Storage keeping processed tracks
+// @param load server.Loader Processes a track into a job, as for the track API
+// @return *Service Service to register with a gRPC server
+// @example geochronov1.RegisterGeoChronoServer(srv, grpcserver.New(&cfg.Server, tracks, loader))
+func New(cfg *config.ServerConfig, tracks store.Store, load server.Loader) *Service {
+	maxSize := cfg.MaxUploadSize
+	if maxSize <= 0 {
+		maxSize = server.DefaultMaxUploadSize
+	}
+	return &Service{tracks: tracks, load: load, users: cfg.Users, maxSize: maxSize}
+}
+
+// NewServer returns a gRPC server offering the service.
+//
+// @method NewServer
+// @description Creates a gRPC server with the GeoChrono service registered
+// @param opts ...grpc.ServerOption Options such as TLS credentials
+// @return *grpc.Server Server to serve on a listener
+// @example err := service.NewServer().Serve(listener)
+func (s *Service) NewServer(opts ...grpc.ServerOption) *grpc.Server {
+	srv := grpc.NewServer(opts...)
+	geochronov1.RegisterGeoChronoServer(srv, s)
+	return srv
+}
+
+// ProcessTrack processes the streamed track, stores it, and returns its ID and
+// route statistics.
+func (s *Service) ProcessTrack(stream geochronov1.GeoChrono_ProcessTrackServer) error {
+	user, err := s.authenticate(stream)
+	if err != nil {
+		return err
+	}
+	track, err := s.receive(user, func() (*geochronov1.TrackOptions, *geochronov1.Point, int, error) {
+		req, err := stream.Recv()
+		return req.GetOptions(), req.GetPoint(), proto.Size(req), err
+	})
+	if err != nil {
+		return err
+	}
+
+	job, err := s.process(track)
+	if err != nil {
+		return err
+	}
+	if err := s.tracks.Save(track); err != nil {
+		slog.Error("Cannot store track", "error", err)
+		return status.Error(codes.Internal, "cannot store track")
+	}
+	summary := job.Summary
+	return stream.SendAndClose(&geochronov1.TrackSummary{
+		Id:              track.ID,
+		Points:          int64(summary.Points),
+		DistanceMeters:  summary.Distance,
+		DurationSeconds: int64(summary.Duration / time.Second),
+		MovingSeconds:   int64(summary.MovingTime / time.Second),
+		AverageSpeedKmh: summary.AvgSpeed,
+		MaxSpeedKmh:     summary.MaxSpeed,
+		Start:           timestamp(summary.Start),
+		End:             timestamp(summary.End),
+	})
+}
+
+// GenerateMap processes the streamed track without storing it and streams the
+// output in the requested format back.
+func (s *Service) GenerateMap(stream geochronov1.GeoChrono_GenerateMapServer) error {
+	user, err := s.authenticate(stream)
+	if err != nil {
+		return err
+	}
+	var format geochronov1.Format
+	track, err := s.receive(user, func() (*geochronov1.TrackOptions, *geochronov1.Point, int, error) {
+		req, err := stream.Recv()
+		if options := req.GetOptions(); options != nil {
+			format = options.GetFormat()
+			track := options.GetTrack()
+			if track == nil {
+				track = &geochronov1.TrackOptions{}
+			}
+			return track, nil, proto.Size(req), err
+		}
+		return nil, req.GetPoint(), proto.Size(req), err
+	})
+	if err != nil {
+		return err
+	}
+
+	job, err := s.process(track)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	mediaType, err := render(&buf, job, format)
+	if err != nil {
+		slog.Error("Cannot render map", "format", format, "error", err)
+		return status.Errorf(codes.Internal, "cannot render map: %v", err)
+	}
+
+	for first := true; first || buf.Len() > 0; first = false {
+		chunk := &geochronov1.MapChunk{Data: buf.Next(ChunkSize)}
+		if first {
+			chunk.MediaType = mediaType
+		}
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// receive reads a track from a request stream, whose first message carries
+// the track options and the others its points. next returns the options or the
+// point of the next message, and the message size.
+func (s *Service) receive(owner string, next func() (*geochronov1.TrackOptions, *geochronov1.Point, int, error)) (*store.Track, error) {
+	id, err := store.NewID()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "cannot create track ID")
+	}
+	track := &store.Track{ID: id, Created: time.Now().UTC(), Owner: owner, Public: len(s.users) == 0}
+
+	var size int64
+	for first := true; ; first = false {
+		options, point, n, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if size += int64(n); size > s.maxSize {
+			return nil, status.Errorf(codes.ResourceExhausted, "track larger than %d bytes", s.maxSize)
+		}
+		switch {
+		case first && options != nil:
+			track.Name = options.GetName()
+		case first:
+			return nil, status.Error(codes.InvalidArgument, "the first message must hold the options")
+		case point != nil:
+			track.Points = append(track.Points, pointFrom(point))
+		default:
+			return nil, status.Error(codes.InvalidArgument, "messages after the first must hold points")
+		}
+	}
+
+	if track.Points.IsEmpty() {
+		return nil, status.Error(codes.InvalidArgument, "no GPS points in the track")
+	}
+	if track.Name == "" {
+		track.Name = "Track " + id
+	}
+	return track, nil
+}
+
+// process runs a received track through the loader; tracks that cannot be
+// mapped are rejected as the track API does.
+func (s *Service) process(track *store.Track) (*export.Job, error) {
+	job, err := s.load(track)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot process track: %v", err)
+	}
+	return job, nil
+}
+
+// authenticate returns the server user calling, identified by the token in the
+// authorization metadata. Without server users every call is anonymous.
+func (s *Service) authenticate(stream grpc.ServerStream) (string, error) {
+	if len(s.users) == 0 {
+		return "", nil
+	}
+	var token string
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		for _, value := range md.Get("authorization") {
+			if t, ok := strings.CutPrefix(value, "Bearer "); ok {
+				token = t
+			}
+		}
+	}
+	if token == "" {
+		return "", status.Error(codes.Unauthenticated, "API token required")
+	}
+	for _, user := range s.users {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(user.Token)) == 1 {
+			return user.Name, nil
+		}
+	}
+	return "", status.Error(codes.Unauthenticated, "invalid API token")
+}
+
+// render writes the output of a job in a format and returns its media type.
+func render(w io.Writer, job *export.Job, format geochronov1.Format) (string, error) {
+	title := job.Config.Map.Title
+	switch format {
+	case geochronov1.Format_FORMAT_UNSPECIFIED, geochronov1.Format_FORMAT_HTML:
+		generator := mapgen.NewGenerator(job.Config)
+		generator.SetReference(job.Reference)
+		generator.SetWeather(job.Weather)
+		generator.SetTiles(job.Tiles)
+		start := time.Now()
+		err := generator.GenerateTo(w, job.Points)
+		metrics.GenerationSeconds.ObserveSince(start)
+		return "text/html; charset=utf-8", err
+	case geochronov1.Format_FORMAT_KML:
+		return "application/vnd.google-earth.kml+xml", kml.Write(w, job.Points, job.Config)
+	case geochronov1.Format_FORMAT_GEOJSON:
+		return "application/geo+json", geojson.Write(w, job.Points, title)
+	case geochronov1.Format_FORMAT_GPX:
+		return "application/gpx+xml", gpx.Write(w, job.Points, title)
+	default:
+		return "", fmt.Errorf("unknown format %v", format)
+	}
+}
+
+// pointFrom converts a streamed point.
+func pointFrom(p *geochronov1.Point) gps.Point {
+	point := gps.Point{
+		Latitude:    p.GetLatitude(),
+		Longitude:   p.GetLongitude(),
+		Elevation:   p.GetElevation(),
+		Title:       p.GetTitle(),
+		Description: p.GetDescription(),
+		Category:    p.GetCategory(),
+		User:        p.GetUser(),
+	}
+	if p.GetTimestamp() != nil {
+		point.Timestamp = p.GetTimestamp().AsTime()
+	}
+	return point
+}
+
+// timestamp converts a time, leaving the zero time unset.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,139 @@
+// Package hooks runs user commands and compiled-in functions around a run.
+//
+// @title Hooks Package
+// @version 1.0
+// @description Runs hooks before the input is read and after the outputs are written
+// @description Hooks are shell commands from configuration or registered Go functions
+//
+// Features:
+// - pre_ingest hooks, such as fetching the CSV file from a device
+// - post_generate hooks, such as copying the map to a web host
+// - Input and output files passed to commands in HOOK_ environment variables
+// - Per-command timeout
+// - Registry of Go hook functions, run before the configured commands
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// Events hooks run for.
+const (
+	PreIngest    = "pre_ingest"    // Before the input is read
+	PostGenerate = "post_generate" // After every output is written
+)
+
+// Event describes the run a hook is called for.
+//
+// @struct Event
+// @description Run details passed to hook functions and commands
+// @property Name string PreIngest or PostGenerate
+// @property Input string Input file, or the batch pattern in batch mode
+// @property Output string HTML map of the run ("" before generation)
+// @property Files []string Every file written (empty before generation)
+type Event struct {
+	Name   string   // @field Name PreIngest or PostGenerate
+	Input  string   // @field Input Input file, or the batch pattern in batch mode
+	Output string   // @field Output HTML map of the run ("" before generation)
+	Files  []string // @field Files Every file written (empty before generation)
+}
+
+// environ returns the variables describing the event to commands.
+func (e Event) environ() []string {
+	return []string{
+		"HOOK_EVENT=" + e.Name,
+		"HOOK_INPUT=" + e.Input,
+		"HOOK_OUTPUT=" + e.Output,
+		"HOOK_FILES=" + strings.Join(e.Files, " "),
+	}
+}
+
+// Func is a hook compiled into the program. An error stops the run.
+type Func func(ctx context.Context, event Event) error
+
+// funcs maps events to their registered functions, in registration order.
+var funcs = map[string][]Func{}
+
+// Register adds a function run for every event of the given name, before the
+// configured commands. Functions run in the order they were registered.
+//
+// @function Register
+// @description Adds a Go hook function
+// @param event string PreIngest or PostGenerate
+// @param fn Func Function to run
+// @example hooks.Register(hooks.PostGenerate, uploadToBucket)
+func Register(event string, fn Func) {
+	funcs[event] = append(funcs[event], fn)
+}
+
+// Run runs the registered functions and then the configured commands for an
+// event, in order, stopping at the first failure. Commands run through the
+// shell (cmd on Windows) with the event in HOOK_EVENT, HOOK_INPUT, HOOK_OUTPUT,
+// and HOOK_FILES (space separated), writing their output to out.
+//
+// @function Run
+// @description Runs the hooks of an event
+// @param ctx context.Context Cancels running commands
+// @param cfg *config.HooksConfig Configured commands and timeout
+// @param event Event Run details
+// @param out io.Writer Receives the output of commands, such as os.Stderr
+// @return error Error naming the hook that failed
+// @example err := hooks.Run(ctx, &cfg.Hooks, hooks.Event{Name: hooks.PreIngest, Input: cfg.Input.CSVFile}, os.Stderr)
+func Run(ctx context.Context, cfg *config.HooksConfig, event Event, out io.Writer) error {
+	for i, fn := range funcs[event.Name] {
+		if err := fn(ctx, event); err != nil {
+			return fmt.Errorf("%s hook function %d failed: %w", event.Name, i+1, err)
+		}
+	}
+
+	var commands []string
+	switch event.Name {
+	case PreIngest:
+		commands = cfg.PreIngest
+	case PostGenerate:
+		commands = cfg.PostGenerate
+	}
+	for _, command := range commands {
+		slog.Debug("Running hook", "event", event.Name, "command", command)
+		if err := runCommand(ctx, command, cfg.TimeoutSeconds, event, out); err != nil {
+			return fmt.Errorf("%s hook %q failed: %w", event.Name, command, err)
+		}
+	}
+	return nil
+}
+
+// runCommand runs one command through the shell, killing it after timeout
+// seconds when a timeout is set or when ctx is cancelled.
+func runCommand(ctx context.Context, command string, timeout int, event Event, out io.Writer) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	cmd.Env = append(os.Environ(), event.environ()...)
+	cmd.Stdout, cmd.Stderr = out, out
+
+	err := cmd.Run()
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return fmt.Errorf("timed out after %ds", timeout)
+	case context.Canceled:
+		return ctx.Err()
+	}
+	return err
+}
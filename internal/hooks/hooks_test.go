@@ -0,0 +1,92 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+func TestRunCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands use sh syntax")
+	}
+	cfg := &config.HooksConfig{
+		PreIngest: []string{`echo "fetch $HOOK_INPUT"`},
+		PostGenerate: []string{
+			`echo "$HOOK_EVENT $HOOK_OUTPUT"`,
+			`for file in $HOOK_FILES; do echo "publish $file"; done`,
+		},
+	}
+
+	var out bytes.Buffer
+	if err := Run(context.Background(), cfg, Event{Name: PreIngest, Input: "walk.csv"}, &out); err != nil {
+		t.Fatalf("Run(pre_ingest) error = %v", err)
+	}
+	event := Event{Name: PostGenerate, Input: "walk.csv", Output: "map.html", Files: []string{"map.html", "map.gpx"}}
+	if err := Run(context.Background(), cfg, event, &out); err != nil {
+		t.Fatalf("Run(post_generate) error = %v", err)
+	}
+
+	want := "fetch walk.csv\npost_generate map.html\npublish map.html\npublish map.gpx\n"
+	if out.String() != want {
+		t.Errorf("hook output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestRunStopsAtFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands use sh syntax")
+	}
+	cfg := &config.HooksConfig{PostGenerate: []string{"exit 3", "echo unreachable"}}
+
+	var out bytes.Buffer
+	err := Run(context.Background(), cfg, Event{Name: PostGenerate}, &out)
+	if err == nil || !strings.Contains(err.Error(), `post_generate hook "exit 3" failed`) {
+		t.Errorf("Run() error = %v, want the failed command named", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("later hook ran: %q", out.String())
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands use sh syntax")
+	}
+	cfg := &config.HooksConfig{PreIngest: []string{"sleep 5"}, TimeoutSeconds: 1}
+	err := Run(context.Background(), cfg, Event{Name: PreIngest}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 1s") {
+		t.Errorf("Run() error = %v, want a timeout", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	defer delete(funcs, PostGenerate)
+	var calls []string
+	Register(PostGenerate, func(_ context.Context, event Event) error {
+		calls = append(calls, "first "+event.Output)
+		return nil
+	})
+	Register(PostGenerate, func(context.Context, Event) error {
+		calls = append(calls, "second")
+		return errors.New("upload refused")
+	})
+
+	err := Run(context.Background(), &config.HooksConfig{}, Event{Name: PostGenerate, Output: "map.html"}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "hook function 2 failed: upload refused") {
+		t.Errorf("Run() error = %v", err)
+	}
+	if strings.Join(calls, ", ") != "first map.html, second" {
+		t.Errorf("calls = %v", calls)
+	}
+
+	// Functions of other events are not run
+	if err := Run(context.Background(), &config.HooksConfig{}, Event{Name: PreIngest}, &bytes.Buffer{}); err != nil {
+		t.Errorf("Run(pre_ingest) error = %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package i18n provides the translated text and number and date formats of the
+// generated map pages.
+//
+// @title Page Localization Package
+// @version 1.0
+// @description Looks up page strings in per-language message tables
+// @description Formats dates and decimal numbers the way each language writes them
+//
+// Features:
+// - English, Spanish, German, and French message tables
+// - Fallback to English for messages missing from a table
+// - Locale date order and decimal separator
+package i18n
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultLanguage is the language of pages without a configured language.
+const DefaultLanguage = "en"
+
+// Locale is the text and formatting of one page language.
+//
+// @struct Locale
+// @description Message table and formats of a page language
+// @property Code string Language code, such as "de"
+// @property Name string Name of the language in the language itself
+// @property DateLayout string Go layout of dates, such as "02.01.2006"
+// @property Decimal string Decimal separator of numbers
+// @property Messages map[string]string Page strings by message key
+type Locale struct {
+	Code       string            // @field Code Language code
+	Name       string            // @field Name Native language name
+	DateLayout string            // @field DateLayout Go layout of dates without the time
+	Decimal    string            // @field Decimal Decimal separator
+	Messages   map[string]string // @field Messages Page strings by message key
+}
+
+// locales holds the supported languages by code.
+var locales = map[string]*Locale{
+	"en": {Code: "en", Name: "English", DateLayout: "2006-01-02", Decimal: ".", Messages: english},
+	"es": {Code: "es", Name: "Español", DateLayout: "02/01/2006", Decimal: ",", Messages: spanish},
+	"de": {Code: "de", Name: "Deutsch", DateLayout: "02.01.2006", Decimal: ",", Messages: german},
+	"fr": {Code: "fr", Name: "Français", DateLayout: "02/01/2006", Decimal: ",", Messages: french},
+}
+
+// Lookup returns the locale of a language code. An empty code selects the
+// default language.
+//
+// @function Lookup
+// @description Finds a supported page language
+// @param code string Language code, such as "fr" (empty for English)
+// @return *Locale Locale of the language
+// @return bool Whether the language is supported
+// @example locale, ok := i18n.Lookup(cfg.Map.Language)
+func Lookup(code string) (*Locale, bool) {
+	if code == "" {
+		code = DefaultLanguage
+	}
+	locale, ok := locales[code]
+	return locale, ok
+}
+
+// Languages returns the codes of the supported languages in alphabetical order.
+func Languages() []string {
+	codes := make([]string, 0, len(locales))
+	for code := range locales {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// T returns the message of a key, falling back to English and then to the key
+// itself for messages the locale does not translate.
+func (l *Locale) T(key string) string {
+	if message, ok := l.Messages[key]; ok {
+		return message
+	}
+	if message, ok := english[key]; ok {
+		return message
+	}
+	return key
+}
+
+// Number replaces the decimal point of formatted numbers, such as "12.5 km", with
+// the locale's decimal separator. Only points between two digits are replaced, so
+// abbreviations and sentences keep theirs.
+func (l *Locale) Number(text string) string {
+	if l.Decimal == "." || !strings.Contains(text, ".") {
+		return text
+	}
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] == '.' && i > 0 && i < len(text)-1 && isDigit(text[i-1]) && isDigit(text[i+1]) {
+			b.WriteString(l.Decimal)
+			continue
+		}
+		b.WriteByte(text[i])
+	}
+	return b.String()
+}
+
+// Date formats the date of a time in the locale's date order, such as "28.10.2025".
+func (l *Locale) Date(t time.Time) string {
+	return t.Format(l.DateLayout)
+}
+
+// DateTime formats a time to the minute in the locale's date order, such as
+// "28.10.2025 14:30".
+func (l *Locale) DateTime(t time.Time) string {
+	return t.Format(l.DateLayout + " 15:04")
+}
+
+// Timestamp formats a time to the second in the locale's date order, such as
+// "28.10.2025 14:30:05".
+func (l *Locale) Timestamp(t time.Time) string {
+	return t.Format(l.DateLayout + " 15:04:05")
+}
+
+// DatePattern returns the locale's date order with {y}, {m}, and {d} placeholders,
+// such as "{d}.{m}.{y}", for reformatting dates in page scripts.
+func (l *Locale) DatePattern() string {
+	return strings.NewReplacer("2006", "{y}", "01", "{m}", "02", "{d}").Replace(l.DateLayout)
+}
+
+// isDigit reports whether a byte is an ASCII digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
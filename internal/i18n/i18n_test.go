@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLookup(t *testing.T) {
+	locale, ok := Lookup("")
+	if !ok || locale.Code != DefaultLanguage {
+		t.Errorf("Lookup(\"\") = %+v, %v, want English", locale, ok)
+	}
+	if _, ok := Lookup("xx"); ok {
+		t.Error("Lookup(\"xx\") found an unsupported language")
+	}
+
+	languages := Languages()
+	want := []string{"de", "en", "es", "fr"}
+	if len(languages) != len(want) {
+		t.Fatalf("Languages() = %v, want %v", languages, want)
+	}
+	for i := range want {
+		if languages[i] != want[i] {
+			t.Errorf("Languages() = %v, want %v", languages, want)
+		}
+	}
+}
+
+func TestMessagesComplete(t *testing.T) {
+	for _, code := range Languages() {
+		locale, _ := Lookup(code)
+		for key := range english {
+			if locale.Messages[key] == "" {
+				t.Errorf("%s has no message for %q", code, key)
+			}
+		}
+		for key := range locale.Messages {
+			if _, ok := english[key]; !ok {
+				t.Errorf("%s has unknown message key %q", code, key)
+			}
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	german, _ := Lookup("de")
+	if got := german.T("legend"); got != "Legende" {
+		t.Errorf("T(legend) = %q, want Legende", got)
+	}
+
+	partial := &Locale{Code: "xx", Messages: map[string]string{}}
+	if got := partial.T("legend"); got != "Legend" {
+		t.Errorf("T(legend) without a translation = %q, want the English message", got)
+	}
+	if got := partial.T("no_such_key"); got != "no_such_key" {
+		t.Errorf("T(no_such_key) = %q, want the key", got)
+	}
+}
+
+func TestNumber(t *testing.T) {
+	french, _ := Lookup("fr")
+	english, _ := Lookup("en")
+	tests := []struct {
+		locale *Locale
+		text   string
+		want   string
+	}{
+		{french, "12.50 km", "12,50 km"},
+		{french, "5.2 km/h, 3.1 mph", "5,2 km/h, 3,1 mph"},
+		{french, "Vitesse max.", "Vitesse max."},
+		{french, ".5 and 5.", ".5 and 5."},
+		{english, "12.50 km", "12.50 km"},
+	}
+	for _, tt := range tests {
+		if got := tt.locale.Number(tt.text); got != tt.want {
+			t.Errorf("%s Number(%q) = %q, want %q", tt.locale.Code, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDates(t *testing.T) {
+	moment := time.Date(2025, 10, 28, 14, 30, 5, 0, time.UTC)
+	tests := []struct {
+		code      string
+		date      string
+		dateTime  string
+		timestamp string
+		pattern   string
+	}{
+		{"en", "2025-10-28", "2025-10-28 14:30", "2025-10-28 14:30:05", "{y}-{m}-{d}"},
+		{"de", "28.10.2025", "28.10.2025 14:30", "28.10.2025 14:30:05", "{d}.{m}.{y}"},
+		{"es", "28/10/2025", "28/10/2025 14:30", "28/10/2025 14:30:05", "{d}/{m}/{y}"},
+	}
+	for _, tt := range tests {
+		locale, _ := Lookup(tt.code)
+		if got := locale.Date(moment); got != tt.date {
+			t.Errorf("%s Date() = %q, want %q", tt.code, got, tt.date)
+		}
+		if got := locale.DateTime(moment); got != tt.dateTime {
+			t.Errorf("%s DateTime() = %q, want %q", tt.code, got, tt.dateTime)
+		}
+		if got := locale.Timestamp(moment); got != tt.timestamp {
+			t.Errorf("%s Timestamp() = %q, want %q", tt.code, got, tt.timestamp)
+		}
+		if got := locale.DatePattern(); got != tt.pattern {
+			t.Errorf("%s DatePattern() = %q, want %q", tt.code, got, tt.pattern)
+		}
+	}
+}
@@ -0,0 +1,417 @@
+package i18n
+
+// english holds the page strings by message key. Every other table translates the
+// same keys; {name} placeholders are filled in by the page scripts.
+var english = map[string]string{
+	// Stats bar
+	"total_points":     "Total Points",
+	"start":            "Start",
+	"end":              "End",
+	"moving_time":      "Moving Time",
+	"stopped_time":     "Stopped Time",
+	"moving_avg_speed": "Moving Avg Speed",
+	"max_speed":        "Max Speed",
+	"pace":             "Pace",
+	"initial_heading":  "Initial Heading",
+	"average_heading":  "Average Heading",
+	"route":            "Route",
+	"weather":          "Weather",
+	"max_off_route":    "Max Off-Route",
+	"avg_off_route":    "Avg Off-Route",
+	"off_route":        "Off Route",
+
+	// Route shapes
+	"one_way":               "One way",
+	"loop_out_and_back":     "Loop (out and back)",
+	"loop_clockwise":        "Loop (clockwise)",
+	"loop_counterclockwise": "Loop (counterclockwise)",
+
+	// Controls
+	"categories":         "Categories",
+	"view":               "View",
+	"markers_and_path":   "Markers & path",
+	"markers":            "Markers",
+	"path":               "Path",
+	"direction_arrows":   "Direction arrows",
+	"milestones":         "Milestones",
+	"heatmap":            "Heatmap",
+	"geofences":          "Geofences",
+	"layers":             "Layers",
+	"search":             "Search",
+	"search_placeholder": "Title, description, or category",
+	"search_count":       "{matches} of {total} points",
+	"time":               "Time",
+	"from":               "From",
+	"to":                 "To",
+	"play":               "Play",
+	"pause":              "Pause",
+	"measure_distance":   "Measure distance",
+	"stop_measuring":     "Stop measuring",
+	"clear":              "Clear",
+	"measure_hint":       "Click the map to add points",
+	"print":              "Print",
+	"points":             "Points",
+	"show_point_list":    "Show the point list",
+	"hide_point_list":    "Hide the point list",
+
+	// Charts and tables
+	"elevation_profile": "Elevation Profile",
+	"splits":            "Splits",
+	"distance":          "Distance",
+	"duration":          "Duration",
+	"daily_summary":     "Daily Summary",
+	"day":               "Day",
+	"weekly_summary":    "Weekly Summary",
+	"week":              "Week",
+	"geofence_events":   "Geofence Events",
+	"fence":             "Fence",
+	"event":             "Event",
+	"entered":           "Entered",
+	"exited":            "Exited",
+	"encounters":        "Encounters",
+	"users":             "Users",
+	"closest_approach":  "Closest Approach",
+
+	// Legend and footer
+	"legend":              "Legend",
+	"start_point":         "Start Point",
+	"end_point":           "End Point",
+	"waypoints":           "Waypoints",
+	"distance_milestones": "Distance Milestones",
+	"stops_by_time":       "Stops (sized by time spent)",
+	"walking_trail":       "Walking Trail",
+	"reference_route":     "Reference Route",
+	"download_track":      "Download track",
+	"privacy":             "Privacy",
+	"generated":           "Generated",
+	"scan_to_open":        "Scan to open the interactive map",
+
+	// Map popups
+	"no_points":          "No GPS points to display",
+	"closest":            "Closest",
+	"meeting_point":      "Meeting point",
+	"based_on_positions": "Based on positions at",
+	"distance_away":      "{distance} km away",
+	"stop":               "Stop",
+	"arrived":            "Arrived",
+	"departed":           "Departed",
+	"point":              "Point",
+	"location":           "Location",
+	"sequence":           "Sequence",
+	"sequence_of":        "{index} of {total}",
+	"heading":            "Heading",
+	"description":        "Description",
+	"measured_distance":  "Measured distance",
+	"position":           "Position",
+
+	// Index page of per-day pages
+	"days":     "Days",
+	"all_days": "All days",
+	"track_on": "Track on",
+}
+
+// spanish holds the Spanish page strings.
+var spanish = map[string]string{
+	"total_points":     "Puntos totales",
+	"start":            "Inicio",
+	"end":              "Fin",
+	"moving_time":      "Tiempo en movimiento",
+	"stopped_time":     "Tiempo detenido",
+	"moving_avg_speed": "Velocidad media en movimiento",
+	"max_speed":        "Velocidad máxima",
+	"pace":             "Ritmo",
+	"initial_heading":  "Rumbo inicial",
+	"average_heading":  "Rumbo medio",
+	"route":            "Ruta",
+	"weather":          "Clima",
+	"max_off_route":    "Desvío máximo",
+	"avg_off_route":    "Desvío medio",
+	"off_route":        "Fuera de ruta",
+
+	"one_way":               "Solo ida",
+	"loop_out_and_back":     "Circular (ida y vuelta)",
+	"loop_clockwise":        "Circular (sentido horario)",
+	"loop_counterclockwise": "Circular (sentido antihorario)",
+
+	"categories":         "Categorías",
+	"view":               "Vista",
+	"markers_and_path":   "Marcadores y trazado",
+	"markers":            "Marcadores",
+	"path":               "Trazado",
+	"direction_arrows":   "Flechas de dirección",
+	"milestones":         "Hitos",
+	"heatmap":            "Mapa de calor",
+	"geofences":          "Geocercas",
+	"layers":             "Capas",
+	"search":             "Buscar",
+	"search_placeholder": "Título, descripción o categoría",
+	"search_count":       "{matches} de {total} puntos",
+	"time":               "Hora",
+	"from":               "Desde",
+	"to":                 "Hasta",
+	"play":               "Reproducir",
+	"pause":              "Pausa",
+	"measure_distance":   "Medir distancia",
+	"stop_measuring":     "Dejar de medir",
+	"clear":              "Borrar",
+	"measure_hint":       "Haga clic en el mapa para añadir puntos",
+	"print":              "Imprimir",
+	"points":             "Puntos",
+	"show_point_list":    "Mostrar la lista de puntos",
+	"hide_point_list":    "Ocultar la lista de puntos",
+
+	"elevation_profile": "Perfil de elevación",
+	"splits":            "Parciales",
+	"distance":          "Distancia",
+	"duration":          "Duración",
+	"daily_summary":     "Resumen diario",
+	"day":               "Día",
+	"weekly_summary":    "Resumen semanal",
+	"week":              "Semana",
+	"geofence_events":   "Eventos de geocerca",
+	"fence":             "Geocerca",
+	"event":             "Evento",
+	"entered":           "Entrada",
+	"exited":            "Salida",
+	"encounters":        "Encuentros",
+	"users":             "Usuarios",
+	"closest_approach":  "Distancia mínima",
+
+	"legend":              "Leyenda",
+	"start_point":         "Punto de inicio",
+	"end_point":           "Punto final",
+	"waypoints":           "Puntos de paso",
+	"distance_milestones": "Hitos de distancia",
+	"stops_by_time":       "Paradas (según el tiempo de permanencia)",
+	"walking_trail":       "Recorrido",
+	"reference_route":     "Ruta de referencia",
+	"download_track":      "Descargar recorrido",
+	"privacy":             "Privacidad",
+	"generated":           "Generado",
+	"scan_to_open":        "Escanea para abrir el mapa interactivo",
+
+	"no_points":          "No hay puntos GPS para mostrar",
+	"closest":            "Distancia mínima",
+	"meeting_point":      "Punto de encuentro",
+	"based_on_positions": "Según las posiciones a las",
+	"distance_away":      "a {distance} km",
+	"stop":               "Parada",
+	"arrived":            "Llegada",
+	"departed":           "Salida",
+	"point":              "Punto",
+	"location":           "Ubicación",
+	"sequence":           "Secuencia",
+	"sequence_of":        "{index} de {total}",
+	"heading":            "Rumbo",
+	"description":        "Descripción",
+	"measured_distance":  "Distancia medida",
+	"position":           "Posición",
+
+	"days":     "Días",
+	"all_days": "Todos los días",
+	"track_on": "Recorrido del",
+}
+
+// german holds the German page strings.
+var german = map[string]string{
+	"total_points":     "Gesamtpunkte",
+	"start":            "Start",
+	"end":              "Ende",
+	"moving_time":      "Bewegungszeit",
+	"stopped_time":     "Standzeit",
+	"moving_avg_speed": "Ø Geschwindigkeit in Bewegung",
+	"max_speed":        "Höchstgeschwindigkeit",
+	"pace":             "Tempo",
+	"initial_heading":  "Anfangsrichtung",
+	"average_heading":  "Mittlere Richtung",
+	"route":            "Route",
+	"weather":          "Wetter",
+	"max_off_route":    "Max. Abweichung",
+	"avg_off_route":    "Ø Abweichung",
+	"off_route":        "Abseits der Route",
+
+	"one_way":               "Einfache Strecke",
+	"loop_out_and_back":     "Rundweg (hin und zurück)",
+	"loop_clockwise":        "Rundweg (im Uhrzeigersinn)",
+	"loop_counterclockwise": "Rundweg (gegen den Uhrzeigersinn)",
+
+	"categories":         "Kategorien",
+	"view":               "Ansicht",
+	"markers_and_path":   "Markierungen & Pfad",
+	"markers":            "Markierungen",
+	"path":               "Pfad",
+	"direction_arrows":   "Richtungspfeile",
+	"milestones":         "Distanzmarken",
+	"heatmap":            "Heatmap",
+	"geofences":          "Geofences",
+	"layers":             "Ebenen",
+	"search":             "Suche",
+	"search_placeholder": "Titel, Beschreibung oder Kategorie",
+	"search_count":       "{matches} von {total} Punkten",
+	"time":               "Zeit",
+	"from":               "Von",
+	"to":                 "Bis",
+	"play":               "Abspielen",
+	"pause":              "Pause",
+	"measure_distance":   "Entfernung messen",
+	"stop_measuring":     "Messung beenden",
+	"clear":              "Löschen",
+	"measure_hint":       "Klicken Sie auf die Karte, um Punkte hinzuzufügen",
+	"print":              "Drucken",
+	"points":             "Punkte",
+	"show_point_list":    "Punktliste einblenden",
+	"hide_point_list":    "Punktliste ausblenden",
+
+	"elevation_profile": "Höhenprofil",
+	"splits":            "Abschnitte",
+	"distance":          "Distanz",
+	"duration":          "Dauer",
+	"daily_summary":     "Tagesübersicht",
+	"day":               "Tag",
+	"weekly_summary":    "Wochenübersicht",
+	"week":              "Woche",
+	"geofence_events":   "Geofence-Ereignisse",
+	"fence":             "Zone",
+	"event":             "Ereignis",
+	"entered":           "Betreten",
+	"exited":            "Verlassen",
+	"encounters":        "Begegnungen",
+	"users":             "Benutzer",
+	"closest_approach":  "Geringster Abstand",
+
+	"legend":              "Legende",
+	"start_point":         "Startpunkt",
+	"end_point":           "Endpunkt",
+	"waypoints":           "Wegpunkte",
+	"distance_milestones": "Distanzmarken",
+	"stops_by_time":       "Stopps (nach Aufenthaltsdauer)",
+	"walking_trail":       "Zurückgelegte Strecke",
+	"reference_route":     "Referenzroute",
+	"download_track":      "Strecke herunterladen",
+	"privacy":             "Datenschutz",
+	"generated":           "Erstellt",
+	"scan_to_open":        "Scannen, um die interaktive Karte zu öffnen",
+
+	"no_points":          "Keine GPS-Punkte vorhanden",
+	"closest":            "Geringster Abstand",
+	"meeting_point":      "Treffpunkt",
+	"based_on_positions": "Basierend auf den Positionen um",
+	"distance_away":      "{distance} km entfernt",
+	"stop":               "Stopp",
+	"arrived":            "Ankunft",
+	"departed":           "Abfahrt",
+	"point":              "Punkt",
+	"location":           "Standort",
+	"sequence":           "Reihenfolge",
+	"sequence_of":        "{index} von {total}",
+	"heading":            "Richtung",
+	"description":        "Beschreibung",
+	"measured_distance":  "Gemessene Entfernung",
+	"position":           "Position",
+
+	"days":     "Tage",
+	"all_days": "Alle Tage",
+	"track_on": "Strecke vom",
+}
+
+// french holds the French page strings.
+var french = map[string]string{
+	"total_points":     "Nombre de points",
+	"start":            "Début",
+	"end":              "Fin",
+	"moving_time":      "Temps en mouvement",
+	"stopped_time":     "Temps à l'arrêt",
+	"moving_avg_speed": "Vitesse moyenne en mouvement",
+	"max_speed":        "Vitesse max.",
+	"pace":             "Allure",
+	"initial_heading":  "Cap initial",
+	"average_heading":  "Cap moyen",
+	"route":            "Itinéraire",
+	"weather":          "Météo",
+	"max_off_route":    "Écart max.",
+	"avg_off_route":    "Écart moyen",
+	"off_route":        "Hors itinéraire",
+
+	"one_way":               "Aller simple",
+	"loop_out_and_back":     "Boucle (aller-retour)",
+	"loop_clockwise":        "Boucle (sens horaire)",
+	"loop_counterclockwise": "Boucle (sens antihoraire)",
+
+	"categories":         "Catégories",
+	"view":               "Affichage",
+	"markers_and_path":   "Marqueurs et tracé",
+	"markers":            "Marqueurs",
+	"path":               "Tracé",
+	"direction_arrows":   "Flèches de direction",
+	"milestones":         "Bornes",
+	"heatmap":            "Carte de chaleur",
+	"geofences":          "Zones",
+	"layers":             "Calques",
+	"search":             "Recherche",
+	"search_placeholder": "Titre, description ou catégorie",
+	"search_count":       "{matches} sur {total} points",
+	"time":               "Heure",
+	"from":               "De",
+	"to":                 "À",
+	"play":               "Lecture",
+	"pause":              "Pause",
+	"measure_distance":   "Mesurer une distance",
+	"stop_measuring":     "Arrêter la mesure",
+	"clear":              "Effacer",
+	"measure_hint":       "Cliquez sur la carte pour ajouter des points",
+	"print":              "Imprimer",
+	"points":             "Points",
+	"show_point_list":    "Afficher la liste des points",
+	"hide_point_list":    "Masquer la liste des points",
+
+	"elevation_profile": "Profil d'altitude",
+	"splits":            "Temps intermédiaires",
+	"distance":          "Distance",
+	"duration":          "Durée",
+	"daily_summary":     "Résumé quotidien",
+	"day":               "Jour",
+	"weekly_summary":    "Résumé hebdomadaire",
+	"week":              "Semaine",
+	"geofence_events":   "Événements de zone",
+	"fence":             "Zone",
+	"event":             "Événement",
+	"entered":           "Entrée",
+	"exited":            "Sortie",
+	"encounters":        "Rencontres",
+	"users":             "Utilisateurs",
+	"closest_approach":  "Distance minimale",
+
+	"legend":              "Légende",
+	"start_point":         "Point de départ",
+	"end_point":           "Point d'arrivée",
+	"waypoints":           "Points de passage",
+	"distance_milestones": "Bornes kilométriques",
+	"stops_by_time":       "Arrêts (selon la durée)",
+	"walking_trail":       "Parcours",
+	"reference_route":     "Itinéraire de référence",
+	"download_track":      "Télécharger le parcours",
+	"privacy":             "Confidentialité",
+	"generated":           "Généré le",
+	"scan_to_open":        "Scannez pour ouvrir la carte interactive",
+
+	"no_points":          "Aucun point GPS à afficher",
+	"closest":            "Distance minimale",
+	"meeting_point":      "Point de rendez-vous",
+	"based_on_positions": "D'après les positions à",
+	"distance_away":      "à {distance} km",
+	"stop":               "Arrêt",
+	"arrived":            "Arrivée",
+	"departed":           "Départ",
+	"point":              "Point",
+	"location":           "Position",
+	"sequence":           "Séquence",
+	"sequence_of":        "{index} sur {total}",
+	"heading":            "Cap",
+	"description":        "Description",
+	"measured_distance":  "Distance mesurée",
+	"position":           "Position",
+
+	"days":     "Jours",
+	"all_days": "Tous les jours",
+	"track_on": "Parcours du",
+}
@@ -0,0 +1,172 @@
+// Package input provides a registry of the file formats GPS tracks are read from.
+//
+// @title Input Package
+// @version 1.0
+// @description Reads GPS points from any registered input format
+// @description Formats are pluggable behind the Reader interface
+//
+// Features:
+// - Reader interface streaming the points of a file
+// - Built-in csv and gpx formats
+// - Format chosen by input.format or by the file extension
+// - Registry for additional formats, such as ones compiled in behind build tags
+package input
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/csv"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/gpx"
+	"github.com/saratily/geo-chrono/internal/progress"
+)
+
+// Names of the built-in formats.
+const (
+	FormatCSV = "csv"
+	FormatGPX = "gpx"
+)
+
+// Reader reads the GPS points of one input format. Stream returns a lazy
+// sequence over the points of a file, which is read each time the sequence is
+// iterated; an error ending the iteration, such as ctx being cancelled, is
+// stored in *errp.
+type Reader interface {
+	Stream(ctx context.Context, filename string, errp *error) gps.Seq
+}
+
+// ProgressReporter is implemented by readers that report the records they have
+// read, so large files show their progress. The caller finishes the bar once
+// reading is done.
+type ProgressReporter interface {
+	SetProgress(bar *progress.Bar)
+}
+
+// ReadFunc adapts a function reading a whole file, such as gpx.ReadFile, to a Reader.
+type ReadFunc func(ctx context.Context, filename string) (gps.Points, error)
+
+// Stream reads the file when the sequence is iterated and yields its points.
+func (f ReadFunc) Stream(ctx context.Context, filename string, errp *error) gps.Seq {
+	return func(yield func(gps.Point) bool) {
+		points, err := f(ctx, filename)
+		*errp = err
+		for _, point := range points {
+			if !yield(point) {
+				return
+			}
+		}
+	}
+}
+
+// Factory creates a Reader for the configuration, such as the CSV columns and
+// timestamp formats.
+type Factory func(cfg *config.Config) Reader
+
+// Format describes a registered input format.
+//
+// @struct Format
+// @description Input format with the extensions it is detected by and its reader
+// @property Description string Human-readable name, such as "GPX"
+// @property Extensions []string File extensions read in this format, such as ".gpx"
+// @property New Factory Creates the reader
+type Format struct {
+	Description string   // @field Description Human-readable name
+	Extensions  []string // @field Extensions File extensions detected as this format
+	New         Factory  // @field New Creates the reader
+}
+
+// formats maps format names to their definitions.
+var formats = map[string]Format{}
+
+func init() {
+	Register(FormatCSV, Format{
+		Description: "CSV",
+		Extensions:  []string{".csv", ".txt"},
+		New: func(cfg *config.Config) Reader {
+			return csv.NewReader(&cfg.Input.CSVFormat, &cfg.Processing)
+		},
+	})
+	Register(FormatGPX, Format{
+		Description: "GPX",
+		Extensions:  []string{".gpx"},
+		New:         func(*config.Config) Reader { return ReadFunc(gpx.ReadFile) },
+	})
+}
+
+// Register makes an input format available under a name for input.format and
+// detection by file extension. Registering the same name twice replaces the
+// earlier format.
+//
+// @function Register
+// @description Adds a pluggable input format
+// @param name string Format name used in input.format
+// @param format Format Extensions and reader for the format
+// @example input.Register("fit", input.Format{Description: "FIT", Extensions: []string{".fit"}, New: newFITReader})
+func Register(name string, format Format) {
+	formats[name] = format
+}
+
+// Names returns the names of all registered formats in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Detect returns the format a file is read in: the named format when one is
+// given, otherwise the format registered for the file's extension, and csv for
+// any other file.
+//
+// @function Detect
+// @description Chooses the input format of a file
+// @param filename string Path of the input file
+// @param name string Format name, such as from input.format ("" to detect)
+// @return string Registered format name
+// @return error Error if the named format is not registered
+// @example name, err := input.Detect("walk.gpx", "")
+func Detect(filename, name string) (string, error) {
+	if name != "" {
+		name = strings.ToLower(name)
+		if _, ok := formats[name]; !ok {
+			return "", fmt.Errorf("unknown input format %q (available: %s)", name, strings.Join(Names(), ", "))
+		}
+		return name, nil
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, name := range Names() {
+		for _, known := range formats[name].Extensions {
+			if ext == known {
+				return name, nil
+			}
+		}
+	}
+	return FormatCSV, nil
+}
+
+// New creates the reader for a file in the named format, or in the format
+// detected from its extension when name is empty.
+//
+// @function New
+// @description Looks up and constructs the reader for an input file
+// @param filename string Path of the input file
+// @param name string Format name ("" to detect from the extension)
+// @param cfg *config.Config Configuration passed to the format's factory
+// @return Reader Reader for the file
+// @return error Error if the named format is not registered
+// @example reader, err := input.New(cfg.Input.CSVFile, cfg.Input.Format, cfg)
+func New(filename, name string, cfg *config.Config) (Reader, error) {
+	name, err := Detect(filename, name)
+	if err != nil {
+		return nil, err
+	}
+	return formats[name].New(cfg), nil
+}
@@ -0,0 +1,109 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		filename string
+		name     string
+		want     string
+		wantErr  bool
+	}{
+		{filename: "walk.csv", want: FormatCSV},
+		{filename: "walk.GPX", want: FormatGPX},
+		{filename: "walk.log", want: FormatCSV},
+		{filename: "walk.log", name: "GPX", want: FormatGPX},
+		{filename: "walk.gpx", name: "csv", want: FormatCSV},
+		{filename: "walk.fit", name: "fit", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Detect(tt.filename, tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Detect(%q, %q) = %q, %v, want %q", tt.filename, tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "walk.csv")
+	gpxFile := filepath.Join(dir, "walk.gpx")
+	os.WriteFile(csvFile, []byte("timestamp,latitude,longitude\n2025-01-01T10:00:00Z,40.0,-74.0\n2025-01-01T10:05:00Z,40.01,-74.01\n"), 0644)
+	os.WriteFile(gpxFile, []byte(`<gpx><trk><trkseg>
+<trkpt lat="40.0" lon="-74.0"><time>2025-01-01T10:00:00Z</time></trkpt>
+</trkseg></trk></gpx>`), 0644)
+
+	cfg := &config.Config{
+		Input: config.InputConfig{CSVFormat: config.CSVFormatConfig{
+			TimestampColumn: "timestamp", LatitudeColumn: "latitude", LongitudeColumn: "longitude", HasHeader: true,
+		}},
+		Processing: config.ProcessingConfig{TimestampFormats: []string{"2006-01-02T15:04:05Z07:00"}},
+	}
+
+	for file, want := range map[string]int{csvFile: 2, gpxFile: 1} {
+		reader, err := New(file, "", cfg)
+		if err != nil {
+			t.Fatalf("New(%s) error = %v", file, err)
+		}
+		var readErr error
+		points := reader.Stream(context.Background(), file, &readErr).Collect()
+		if readErr != nil || len(points) != want {
+			t.Errorf("reading %s = %d points, %v, want %d", file, len(points), readErr, want)
+		}
+	}
+
+	// The CSV reader reports its progress
+	reader, _ := New(csvFile, "", cfg)
+	if _, ok := reader.(ProgressReporter); !ok {
+		t.Errorf("CSV reader %T is not a ProgressReporter", reader)
+	}
+
+	var readErr error
+	reader, _ = New(filepath.Join(dir, "missing.gpx"), "", cfg)
+	if points := reader.Stream(context.Background(), filepath.Join(dir, "missing.gpx"), &readErr).Collect(); len(points) != 0 || readErr == nil {
+		t.Errorf("reading a missing file = %d points, %v, want an error", len(points), readErr)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	stub := ReadFunc(func(context.Context, string) (gps.Points, error) {
+		return gps.Points{{Latitude: 1}, {Latitude: 2}}, nil
+	})
+	Register("stub", Format{Description: "Stub", Extensions: []string{".stub"}, New: func(*config.Config) Reader { return stub }})
+	defer delete(formats, "stub")
+
+	reader, err := New("track.stub", "", &config.Config{})
+	if err != nil {
+		t.Fatalf("New(track.stub) error = %v", err)
+	}
+	var readErr error
+	if points := reader.Stream(context.Background(), "track.stub", &readErr).Take(1).Collect(); len(points) != 1 || readErr != nil {
+		t.Errorf("stub reader = %v, %v, want the first point", points, readErr)
+	}
+
+	found := false
+	for _, name := range Names() {
+		found = found || name == "stub"
+	}
+	if !found {
+		t.Errorf("Names() = %v, missing stub", Names())
+	}
+}
+
+func TestReadFuncError(t *testing.T) {
+	failure := errors.New("corrupt file")
+	var readErr error
+	ReadFunc(func(context.Context, string) (gps.Points, error) { return nil, failure }).Stream(context.Background(), "track", &readErr).Count()
+	if !errors.Is(readErr, failure) {
+		t.Errorf("Stream() error = %v, want %v", readErr, failure)
+	}
+}
@@ -0,0 +1,106 @@
+package kml
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// bundledIcon is a local marker image stored inside a KMZ archive.
+type bundledIcon struct {
+	url     string // Icon URL as configured
+	name    string // Path inside the archive
+	content []byte // Image data
+}
+
+// WriteKMZ writes the GPS points as a KMZ archive: the KML document as doc.kml plus
+// every local marker icon image under files/, referenced relative to the document,
+// so the track shows the same icons in Google Earth without network access. Remote
+// icon URLs are kept as links.
+//
+// @function WriteKMZ
+// @description Writes GPS points as a KMZ archive with bundled marker icons
+// @param w io.Writer Destination for the KMZ archive
+// @param points gps.Points Chronologically sorted GPS points
+// @param cfg *config.Config Configuration providing the map title, path style, and marker icons
+// @return error Error if an icon cannot be read or the archive cannot be written
+// @example err := kml.WriteKMZ(file, points, cfg)
+func WriteKMZ(w io.Writer, points gps.Points, cfg *config.Config) error {
+	icons, err := bundleIcons(cfg)
+	if err != nil {
+		return err
+	}
+	names := make(map[string]string, len(icons))
+	for _, icon := range icons {
+		names[icon.url] = icon.name
+	}
+
+	archive := zip.NewWriter(w)
+
+	// Google Earth opens the first .kml entry of the archive, so it is written first
+	doc, err := archive.Create("doc.kml")
+	if err != nil {
+		return err
+	}
+	href := func(url string) string {
+		if name, ok := names[url]; ok {
+			return name
+		}
+		return url
+	}
+	if err := write(doc, points, cfg, href); err != nil {
+		return err
+	}
+
+	for _, icon := range icons {
+		entry, err := archive.Create(icon.name)
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(icon.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// bundleIcons reads the local icon images of the configured markers. Each image is
+// stored once under files/, prefixed with a number when two files share a name.
+func bundleIcons(cfg *config.Config) ([]bundledIcon, error) {
+	var icons []bundledIcon
+	seen := make(map[string]bool)
+	used := make(map[string]bool)
+	for _, marker := range markerStyles(cfg) {
+		url := iconURL(marker.style)
+		if url == "" || seen[url] || isRemote(url) {
+			continue
+		}
+		seen[url] = true
+
+		content, err := os.ReadFile(url)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read %s marker icon: %w", marker.id, err)
+		}
+
+		name := path.Join("files", filepath.Base(url))
+		for i := 2; used[name]; i++ {
+			name = path.Join("files", fmt.Sprintf("%d-%s", i, filepath.Base(url)))
+		}
+		used[name] = true
+		icons = append(icons, bundledIcon{url: url, name: name, content: content})
+	}
+	return icons, nil
+}
+
+// isRemote reports whether an icon URL refers to a network resource or inline data
+// rather than a local file.
+func isRemote(url string) bool {
+	return strings.Contains(url, "://") || strings.HasPrefix(url, "data:")
+}
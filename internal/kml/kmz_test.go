@@ -0,0 +1,125 @@
+package kml
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// iconConfig returns the test configuration with custom start and default icons.
+func iconConfig(start, point string) *config.Config {
+	cfg := testConfig()
+	cfg.Markers.Start.Icon = config.IconConfig{URL: &start, Size: config.SizeConfig{Width: 48, Height: 48}, Anchor: config.PointConfig{X: 24, Y: 48}}
+	cfg.Markers.Default.Icon = config.IconConfig{URL: &point}
+	return cfg
+}
+
+func TestWriteIconStyles(t *testing.T) {
+	points := gps.Points{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}, {Latitude: 5, Longitude: 6}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, points, iconConfig("icons/flag.png", "https://example.com/dot.png")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<Style id="start">`,
+		"<scale>1.5</scale>",
+		"<href>icons/flag.png</href>",
+		`<hotSpot x="0.5" y="0" xunits="fraction" yunits="fraction"></hotSpot>`,
+		"<href>https://example.com/dot.png</href>",
+		"<styleUrl>#start</styleUrl>",
+		"<styleUrl>#point</styleUrl>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Write() output missing %q\n%s", want, out)
+		}
+	}
+	// No end icon is configured, so the last point uses the default icon
+	if strings.Contains(out, "#end") || strings.Count(out, "<styleUrl>#point</styleUrl>") != 2 {
+		t.Errorf("Write() styled the last point incorrectly\n%s", out)
+	}
+}
+
+func TestWriteKMZ(t *testing.T) {
+	dir := t.TempDir()
+	flag := filepath.Join(dir, "flag.png")
+	if err := os.WriteFile(flag, []byte("PNG flag"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "other", "flag.png")
+	if err := os.MkdirAll(filepath.Dir(other), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte("PNG other"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "route.kmz")
+	points := gps.Points{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}
+	if err := WriteFile(filename, points, iconConfig(flag, other)); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	archive, err := zip.OpenReader(filename)
+	if err != nil {
+		t.Fatalf("WriteFile() did not write a zip archive: %v", err)
+	}
+	defer archive.Close()
+
+	entries := make(map[string]string)
+	var order []string
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(r)
+		r.Close()
+		entries[file.Name] = string(content)
+		order = append(order, file.Name)
+	}
+
+	if len(order) != 3 || order[0] != "doc.kml" {
+		t.Fatalf("archive entries = %v, want doc.kml first plus two icons", order)
+	}
+	// The default icon is bundled first, so the start icon gets the numbered name
+	if entries["files/flag.png"] != "PNG other" || entries["files/2-flag.png"] != "PNG flag" {
+		t.Errorf("bundled icons = %v, want both flag images", order)
+	}
+	doc := entries["doc.kml"]
+	for _, want := range []string{"<href>files/flag.png</href>", "<href>files/2-flag.png</href>"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("doc.kml missing %q", want)
+		}
+	}
+}
+
+func TestWriteKMZErrors(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteKMZ(&buf, gps.Points{{Latitude: 1, Longitude: 2}}, iconConfig(filepath.Join(t.TempDir(), "missing.png"), ""))
+	if err == nil || !strings.Contains(err.Error(), "start marker icon") {
+		t.Errorf("WriteKMZ() error = %v, want a missing start icon error", err)
+	}
+
+	// Remote icons stay links and nothing is bundled
+	buf.Reset()
+	if err := WriteKMZ(&buf, gps.Points{{Latitude: 1, Longitude: 2}}, iconConfig("https://example.com/a.png", "data:image/png;base64,AA==")); err != nil {
+		t.Fatalf("WriteKMZ() error = %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archive.File) != 1 {
+		t.Errorf("archive has %d entries, want only doc.kml", len(archive.File))
+	}
+}
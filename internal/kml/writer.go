@@ -0,0 +1,323 @@
+// Package kml provides export of GPS tracks as Keyhole Markup Language (KML) documents.
+//
+// @title KML Writer Package
+// @version 1.0
+// @description Writes GPS points as KML placemarks and a timestamped path
+// @description Produces files that open in Google Earth, Google My Maps, and most GIS tools
+//
+// Features:
+// - One placemark per GPS point with name, description, and timestamp
+// - Time-aware gx:Track path when every point has a timestamp
+// - Plain LineString path otherwise
+// - Path color, opacity, and width from the path style configuration
+// - Custom start, end, and point marker icons
+// - KMZ packaging with local marker icons bundled for offline use
+package kml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// Namespaces declared on the root kml element.
+const (
+	namespace   = "http://www.opengis.net/kml/2.2"
+	gxNamespace = "http://www.google.com/kml/ext/2.2"
+)
+
+// document is the root kml element.
+type document struct {
+	XMLName  xml.Name `xml:"kml"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	XMLNSGX  string   `xml:"xmlns:gx,attr"`
+	Document struct {
+		Name       string      `xml:"name"`
+		Styles     []style     `xml:"Style"`
+		Path       placemark   `xml:"Placemark"`
+		PointsName string      `xml:"Folder>name"`
+		Points     []placemark `xml:"Folder>Placemark"`
+	} `xml:"Document"`
+}
+
+// style is a KML Style with the line appearance of the path or a marker icon.
+type style struct {
+	ID        string     `xml:"id,attr"`
+	Icon      *iconStyle `xml:"IconStyle"`
+	LineColor string     `xml:"LineStyle>color,omitempty"`
+	LineWidth int        `xml:"LineStyle>width,omitempty"`
+}
+
+// iconStyle is a KML IconStyle showing a custom marker image.
+type iconStyle struct {
+	Scale   float64  `xml:"scale,omitempty"`
+	Href    string   `xml:"Icon>href"`
+	HotSpot *hotSpot `xml:"hotSpot"`
+}
+
+// hotSpot is the point of an icon placed on the coordinates, as fractions of the
+// icon size measured from its lower left corner.
+type hotSpot struct {
+	X      float64 `xml:"x,attr"`
+	Y      float64 `xml:"y,attr"`
+	XUnits string  `xml:"xunits,attr"`
+	YUnits string  `xml:"yunits,attr"`
+}
+
+// placemark is a KML Placemark holding either a point, a line, or a gx:Track.
+type placemark struct {
+	Name        string     `xml:"name,omitempty"`
+	Description string     `xml:"description,omitempty"`
+	TimeStamp   *timeStamp `xml:"TimeStamp"`
+	StyleURL    string     `xml:"styleUrl,omitempty"`
+	Point       *geometry  `xml:"Point"`
+	LineString  *geometry  `xml:"LineString"`
+	Track       *track     `xml:"gx:Track"`
+}
+
+// timeStamp is a KML TimeStamp marking when a placemark was recorded.
+type timeStamp struct {
+	When string `xml:"when"`
+}
+
+// geometry holds the coordinates of a Point or LineString.
+type geometry struct {
+	Tessellate  int    `xml:"tessellate,omitempty"`
+	Coordinates string `xml:"coordinates"`
+}
+
+// track is a gx:Track: parallel lists of timestamps and coordinates.
+type track struct {
+	When  []string `xml:"when"`
+	Coord []string `xml:"gx:coord"`
+}
+
+// Write encodes the GPS points as a KML document named after the map title. Every
+// point becomes a placemark, and the path is drawn with the configured path style.
+// Markers with a custom icon URL in markers.default, start, or end use that image.
+// When every point has a timestamp the path is a gx:Track, so Google Earth can play it
+// back with its time slider; otherwise it is a plain LineString.
+//
+// @function Write
+// @description Writes GPS points as a KML document
+// @param w io.Writer Destination for the KML document
+// @param points gps.Points Chronologically sorted GPS points
+// @param cfg *config.Config Configuration providing the map title and path style
+// @return error Error if encoding or writing fails
+// @example err := kml.Write(file, points, cfg)
+func Write(w io.Writer, points gps.Points, cfg *config.Config) error {
+	return write(w, points, cfg, func(url string) string { return url })
+}
+
+// write encodes the KML document, mapping configured icon URLs to the href
+// written for them.
+func write(w io.Writer, points gps.Points, cfg *config.Config, href func(string) string) error {
+	var doc document
+	doc.XMLNS = namespace
+	doc.XMLNSGX = gxNamespace
+	doc.Document.Name = cfg.Map.Title
+	doc.Document.Styles = []style{{
+		ID:        "path",
+		LineColor: Color(cfg.Path.Style.Color, cfg.Path.Style.Opacity),
+		LineWidth: cfg.Path.Style.Weight,
+	}}
+	doc.Document.Path = pathPlacemark(points)
+	doc.Document.PointsName = "Points"
+
+	icons := make(map[string]bool)
+	for _, marker := range markerStyles(cfg) {
+		if url := iconURL(marker.style); url != "" {
+			doc.Document.Styles = append(doc.Document.Styles, style{ID: marker.id, Icon: newIconStyle(href(url), marker.style.Icon)})
+			icons[marker.id] = true
+		}
+	}
+
+	for i, point := range points {
+		name := point.Title
+		if name == "" {
+			name = fmt.Sprintf("Point %d", i+1)
+		}
+		doc.Document.Points = append(doc.Document.Points, placemark{
+			Name:        name,
+			Description: point.Description,
+			StyleURL:    markerStyleURL(icons, i, len(points)),
+			TimeStamp:   pointTime(point),
+			Point:       &geometry{Coordinates: coordinate(point, ",")},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("cannot encode KML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteFile writes the GPS points as a KML document to the named file, or as a KMZ
+// archive with bundled icons when the file name ends in .kmz.
+//
+// @function WriteFile
+// @description Creates a KML or KMZ file from GPS points
+// @param filename string Path of the .kml or .kmz file to create
+// @param points gps.Points Chronologically sorted GPS points
+// @param cfg *config.Config Configuration providing the map title and path style
+// @return error Error if the file cannot be created or written
+// @example err := kml.WriteFile("route.kml", points, cfg)
+func WriteFile(filename string, points gps.Points, cfg *config.Config) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("cannot create KML file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	write := Write
+	if strings.EqualFold(filepath.Ext(filename), ".kmz") {
+		write = WriteKMZ
+	}
+	if err := write(file, points, cfg); err != nil {
+		return fmt.Errorf("cannot write KML file %s: %w", filename, err)
+	}
+	return file.Close()
+}
+
+// markerStyle is a marker configuration with the KML style id it is written under.
+type markerStyle struct {
+	id    string
+	style config.MarkerStyleConfig
+}
+
+// markerStyles lists the configurable markers in the order their styles are written.
+func markerStyles(cfg *config.Config) []markerStyle {
+	return []markerStyle{
+		{"point", cfg.Markers.Default},
+		{"start", cfg.Markers.Start},
+		{"end", cfg.Markers.End},
+	}
+}
+
+// iconURL returns the custom icon URL of a marker, or "" when none is configured.
+func iconURL(marker config.MarkerStyleConfig) string {
+	if marker.Icon.URL == nil {
+		return ""
+	}
+	return strings.TrimSpace(*marker.Icon.URL)
+}
+
+// newIconStyle builds the IconStyle for an icon image. KML scales icons relative to
+// 32 pixels, and the configured anchor (from the top left) becomes the hot spot.
+func newIconStyle(href string, icon config.IconConfig) *iconStyle {
+	result := &iconStyle{Href: href}
+	width, height := icon.Size.Width, icon.Size.Height
+	if width > 0 {
+		result.Scale = float64(width) / 32
+	}
+	if width > 0 && height > 0 && (icon.Anchor.X != 0 || icon.Anchor.Y != 0) {
+		result.HotSpot = &hotSpot{
+			X:      float64(icon.Anchor.X) / float64(width),
+			Y:      1 - float64(icon.Anchor.Y)/float64(height),
+			XUnits: "fraction",
+			YUnits: "fraction",
+		}
+	}
+	return result
+}
+
+// markerStyleURL returns the style of the point at index: the start or end icon
+// for the first and last points, otherwise the default icon, or "" without one.
+func markerStyleURL(icons map[string]bool, index, count int) string {
+	switch {
+	case index == 0 && icons["start"]:
+		return "#start"
+	case index == count-1 && icons["end"]:
+		return "#end"
+	case icons["point"]:
+		return "#point"
+	}
+	return ""
+}
+
+// Color converts a "#RRGGBB" color and an opacity between 0 and 1 into KML's
+// "aabbggrr" notation. Malformed colors fall back to opaque white, and an opacity of
+// zero is treated as fully opaque, matching an unset configuration value.
+//
+// @function Color
+// @description Converts a CSS hex color to a KML color
+// @param hex string Color in #RRGGBB notation
+// @param opacity float64 Opacity between 0 and 1
+// @return string Color in aabbggrr notation
+// @example kml.Color("#FF0000", 0.8) // "cc0000ff"
+func Color(hex string, opacity float64) string {
+	alpha := 255
+	if opacity > 0 && opacity < 1 {
+		alpha = int(opacity*255 + 0.5)
+	}
+
+	hex = strings.TrimPrefix(hex, "#")
+	if _, err := strconv.ParseUint(hex, 16, 32); err != nil || len(hex) != 6 {
+		hex = "ffffff"
+	}
+	hex = strings.ToLower(hex)
+	return fmt.Sprintf("%02x%s%s%s", alpha, hex[4:6], hex[2:4], hex[0:2])
+}
+
+// pathPlacemark builds the placemark for the path through all points.
+func pathPlacemark(points gps.Points) placemark {
+	path := placemark{Name: "Path", StyleURL: "#path"}
+
+	timed := len(points) > 0
+	for _, point := range points {
+		if point.Timestamp.IsZero() {
+			timed = false
+			break
+		}
+	}
+
+	if timed {
+		path.Track = &track{}
+		for _, point := range points {
+			path.Track.When = append(path.Track.When, timestamp(point.Timestamp))
+			path.Track.Coord = append(path.Track.Coord, coordinate(point, " ")+" 0")
+		}
+		return path
+	}
+
+	coords := make([]string, len(points))
+	for i, point := range points {
+		coords[i] = coordinate(point, ",")
+	}
+	path.LineString = &geometry{Tessellate: 1, Coordinates: strings.Join(coords, " ")}
+	return path
+}
+
+// coordinate formats a point as longitude and latitude joined by sep: KML coordinates
+// use commas, gx:coord uses spaces and also expects an altitude.
+func coordinate(point gps.Point, sep string) string {
+	return strconv.FormatFloat(point.Longitude, 'f', -1, 64) + sep +
+		strconv.FormatFloat(point.Latitude, 'f', -1, 64)
+}
+
+// pointTime returns the TimeStamp element for a point, or nil when it has no timestamp.
+func pointTime(point gps.Point) *timeStamp {
+	if point.Timestamp.IsZero() {
+		return nil
+	}
+	return &timeStamp{When: timestamp(point.Timestamp)}
+}
+
+// timestamp formats a time as an RFC 3339 UTC timestamp.
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
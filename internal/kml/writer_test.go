@@ -0,0 +1,113 @@
+// Package kml_test provides unit tests for the KML writer
+package kml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func testConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.Map.Title = "Morning & Evening"
+	cfg.Path.Style = config.PathStyleConfig{Color: "#FF5722", Opacity: 0.8, Weight: 4}
+	return cfg
+}
+
+func TestWriteTimedTrack(t *testing.T) {
+	start := time.Date(2025, 10, 28, 9, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.7749, Longitude: -122.4194, Title: "Home"},
+		{Timestamp: start.Add(time.Hour), Latitude: 37.8044, Longitude: -122.2711, Description: "<b>Lunch</b>"},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, points, testConfig()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		`<kml xmlns="http://www.opengis.net/kml/2.2" xmlns:gx="http://www.google.com/kml/ext/2.2">`,
+		"<name>Morning &amp; Evening</name>",
+		"<color>cc2257ff</color>",
+		"<width>4</width>",
+		"<gx:Track>",
+		"<when>2025-10-28T10:00:00Z</when>",
+		"<gx:coord>-122.2711 37.8044 0</gx:coord>",
+		"<name>Home</name>",
+		"<name>Point 2</name>",
+		"<description>&lt;b&gt;Lunch&lt;/b&gt;</description>",
+		"<coordinates>-122.4194,37.7749</coordinates>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Write() output missing %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "<LineString>") {
+		t.Error("Write() used a LineString although every point has a timestamp")
+	}
+	if err := xml.Unmarshal(buf.Bytes(), new(struct{})); err != nil {
+		t.Errorf("Write() produced invalid XML: %v", err)
+	}
+}
+
+func TestWriteUntimedPath(t *testing.T) {
+	points := gps.Points{
+		{Latitude: 1, Longitude: 2},
+		{Latitude: 3, Longitude: 4},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, points, testConfig()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "<coordinates>2,1 4,3</coordinates>") {
+		t.Errorf("Write() output missing LineString coordinates\n%s", out)
+	}
+	if strings.Contains(out, "gx:Track>") || strings.Contains(out, "<TimeStamp>") {
+		t.Errorf("Write() wrote timestamps for untimed points\n%s", out)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "route.kml")
+	if err := WriteFile(filename, gps.Points{{Latitude: 1, Longitude: 2}}, testConfig()); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("cannot read KML file: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "<?xml") {
+		t.Errorf("WriteFile() wrote %q, want an XML document", data[:20])
+	}
+}
+
+func TestColor(t *testing.T) {
+	tests := []struct {
+		hex     string
+		opacity float64
+		want    string
+	}{
+		{"#FF0000", 1, "ff0000ff"},
+		{"#00FF00", 0.5, "8000ff00"},
+		{"#0000ff", 0, "ffff0000"},
+		{"red", 1, "ffffffff"},
+	}
+
+	for _, tt := range tests {
+		if got := Color(tt.hex, tt.opacity); got != tt.want {
+			t.Errorf("Color(%q, %v) = %q, want %q", tt.hex, tt.opacity, got, tt.want)
+		}
+	}
+}
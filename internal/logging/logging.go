@@ -0,0 +1,299 @@
+// Package logging sets up the application's structured logging from configuration.
+//
+// @title Logging Package
+// @version 1.0
+// @description Builds the slog logger used by every package from the logging settings
+// @description Writes readable lines to the console and timestamped records to a log file
+//
+// Features:
+// - Log levels trace, debug, info, warn, and error, with verbose mode showing debug detail
+// - Trace records of every request to external services, without query strings
+// - Compact console lines ("Warning: Skipping row 7: ...") without timestamps
+// - Optional log file receiving every record with its time and attributes
+// - JSON records for schedulers and log collectors
+// - Records of the standard log package routed through the same logger
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// Log formats of logging.format.
+const (
+	FormatText = "text" // Readable console lines; key=value records in the log file
+	FormatJSON = "json" // One JSON object per record on the console and in the log file
+)
+
+// LevelTrace is below debug: each request to an external service, such as a
+// road snapping batch or a tile download, is logged at this level.
+const LevelTrace = slog.LevelDebug - 4
+
+// ParseLevel returns the slog level named in logging.level. An empty name is info.
+//
+// @function ParseLevel
+// @description Converts a configured level name to a slog level
+// @param name string trace, debug, info, warn, or error (any case)
+// @return slog.Level Matching level
+// @return error Error naming the accepted levels
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "trace":
+		return LevelTrace, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (use trace, debug, info, warn, or error)", name)
+}
+
+// New creates a logger for the logging settings: readable lines on console,
+// and with logging.file set, timestamped records appended to that file too.
+// With logging.format json, both get one JSON object per record instead, for
+// schedulers and log collectors. Verbose mode lowers the level to debug, where
+// the detailed processing information is logged. The returned function closes
+// the log file.
+//
+// @function New
+// @description Builds the application logger from configuration
+// @param cfg *config.LoggingConfig Level, format, file, and verbose settings
+// @param console io.Writer Console destination, normally os.Stderr
+// @return *slog.Logger Logger writing to the console and log file
+// @return func() error Closes the log file; safe to call without one
+// @return error Error if the level or format is unknown or the file cannot be opened
+// @example logger, closeLog, err := logging.New(&cfg.Logging, os.Stderr)
+func New(cfg *config.LoggingConfig, console io.Writer) (*slog.Logger, func() error, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Verbose && level > slog.LevelDebug {
+		level = slog.LevelDebug
+	}
+	options := &slog.HandlerOptions{Level: level, ReplaceAttr: nameTrace}
+
+	var handler slog.Handler
+	switch cfg.Format {
+	case "", FormatText:
+		handler = NewConsoleHandler(console, level)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(console, options)
+	default:
+		return nil, nil, fmt.Errorf("unknown log format %q (use %s or %s)", cfg.Format, FormatText, FormatJSON)
+	}
+
+	closeLog := func() error { return nil }
+	if cfg.File != "" {
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot open log file: %w", err)
+		}
+		fileHandler := slog.Handler(slog.NewTextHandler(file, options))
+		if cfg.Format == FormatJSON {
+			fileHandler = slog.NewJSONHandler(file, options)
+		}
+		handler = teeHandler{handler, fileHandler}
+		closeLog = file.Close
+	}
+	return slog.New(handler), closeLog, nil
+}
+
+// nameTrace names LevelTrace in file and JSON records, which would otherwise
+// read "DEBUG-4".
+func nameTrace(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 && attr.Key == slog.LevelKey {
+		if level, ok := attr.Value.Any().(slog.Level); ok && level == LevelTrace {
+			attr.Value = slog.StringValue("TRACE")
+		}
+	}
+	return attr
+}
+
+// Transport is an HTTP transport logging each request at trace level with its
+// method, address, status, and duration. Query strings are left out of the
+// address, as they can hold API keys.
+//
+// @struct Transport
+// @description http.RoundTripper tracing requests to external services
+// @property Base http.RoundTripper Transport making the requests (nil for http.DefaultTransport)
+// @example http.DefaultTransport = &logging.Transport{Base: http.DefaultTransport}
+type Transport struct {
+	Base http.RoundTripper // @field Base Transport making the requests
+}
+
+// RoundTrip makes the request with the base transport and logs it.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+
+	address := req.URL.Scheme + "://" + req.URL.Host + req.URL.Path
+	attrs := []any{"method", req.Method, "url", address, "duration", time.Since(start).Round(time.Millisecond)}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	} else {
+		attrs = append(attrs, "status", resp.StatusCode)
+	}
+	slog.Log(req.Context(), LevelTrace, "HTTP request", attrs...)
+	return resp, err
+}
+
+// ConsoleHandler writes log records as short lines for people: the message,
+// then the error, then any other attributes as key=value pairs. Warnings and
+// errors are prefixed so they stand out; times are left to the log file.
+//
+// @struct ConsoleHandler
+// @description slog handler for terminal output
+type ConsoleHandler struct {
+	mu    *sync.Mutex  // @field mu Serializes writes shared by derived handlers
+	w     io.Writer    // @field w Destination
+	level slog.Leveler // @field level Lowest level written
+	attrs []slog.Attr  // @field attrs Attributes added with WithAttrs
+	group string       // @field group Prefix of attribute keys added with WithGroup
+}
+
+// NewConsoleHandler creates a ConsoleHandler writing records at level or above to w.
+func NewConsoleHandler(w io.Writer, level slog.Leveler) *ConsoleHandler {
+	return &ConsoleHandler{mu: &sync.Mutex{}, w: w, level: level}
+}
+
+// Enabled reports whether records at level are written.
+func (h *ConsoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle writes one record as a line, such as
+// "Warning: Skipping row 7: invalid latitude file=walk.csv".
+func (h *ConsoleHandler) Handle(_ context.Context, record slog.Record) error {
+	var line bytes.Buffer
+	switch {
+	case record.Level >= slog.LevelError:
+		line.WriteString("Error: ")
+	case record.Level >= slog.LevelWarn:
+		line.WriteString("Warning: ")
+	}
+	line.WriteString(record.Message)
+
+	attrs := append([]slog.Attr(nil), h.attrs...)
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, h.qualify(attr))
+		return true
+	})
+	// The error reads as the reason for the message
+	for _, attr := range attrs {
+		if attr.Key == "error" {
+			fmt.Fprintf(&line, ": %v", attr.Value.Any())
+		}
+	}
+	for _, attr := range attrs {
+		if attr.Key != "error" && !attr.Equal(slog.Attr{}) {
+			fmt.Fprintf(&line, " %s=%s", attr.Key, quote(attr.Value.String()))
+		}
+	}
+	line.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(line.Bytes())
+	return err
+}
+
+// WithAttrs returns a handler adding attrs to every record.
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := *h
+	derived.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		derived.attrs = append(derived.attrs, h.qualify(attr))
+	}
+	return &derived
+}
+
+// WithGroup returns a handler prefixing the keys of later attributes with name.
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	derived := *h
+	derived.group = h.group + name + "."
+	return &derived
+}
+
+// qualify prefixes an attribute's key with the handler's group.
+func (h *ConsoleHandler) qualify(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	if h.group != "" && attr.Key != "" {
+		attr.Key = h.group + attr.Key
+	}
+	return attr
+}
+
+// quote quotes values that would not read as a single token.
+func quote(value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+		return fmt.Sprintf("%q", value)
+	}
+	return value
+}
+
+// teeHandler passes every record to each of its handlers that accepts it.
+type teeHandler []slog.Handler
+
+// Enabled reports whether any handler accepts records at level.
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range t {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle passes the record to every handler accepting its level, returning the
+// first error.
+func (t teeHandler) Handle(ctx context.Context, record slog.Record) error {
+	var first error
+	for _, handler := range t {
+		if handler.Enabled(ctx, record.Level) {
+			if err := handler.Handle(ctx, record.Clone()); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// WithAttrs adds attrs to every handler.
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := make(teeHandler, len(t))
+	for i, handler := range t {
+		derived[i] = handler.WithAttrs(attrs)
+	}
+	return derived
+}
+
+// WithGroup opens a group in every handler.
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	derived := make(teeHandler, len(t))
+	for i, handler := range t {
+		derived[i] = handler.WithGroup(name)
+	}
+	return derived
+}
@@ -0,0 +1,181 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    slog.Level
+		wantErr bool
+	}{
+		{name: "", want: slog.LevelInfo},
+		{name: "trace", want: LevelTrace},
+		{name: "debug", want: slog.LevelDebug},
+		{name: "INFO", want: slog.LevelInfo},
+		{name: "warn", want: slog.LevelWarn},
+		{name: "warning", want: slog.LevelWarn},
+		{name: "error", want: slog.LevelError},
+		{name: "verbose", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v", tt.name, got, err)
+		}
+	}
+}
+
+func TestConsoleHandler(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(NewConsoleHandler(&out, slog.LevelInfo))
+
+	logger.Debug("Hidden")
+	logger.Info("Serving track", "file", "walk.csv", "url", "http://localhost:8080/")
+	logger.With("file", "walk.csv").Warn("Skipping row", "row", 7, "error", errors.New("invalid latitude"))
+	logger.WithGroup("tiles").Error("Cannot fetch", "zoom", 12, "note", "two words")
+
+	want := `Serving track file=walk.csv url=http://localhost:8080/
+Warning: Skipping row: invalid latitude file=walk.csv row=7
+Error: Cannot fetch tiles.zoom=12 tiles.note="two words"
+`
+	if out.String() != want {
+		t.Errorf("console output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestNew(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "geo-chrono.log")
+	var console bytes.Buffer
+	logger, closeLog, err := New(&config.LoggingConfig{Level: "warn", File: file}, &console)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	logger.Info("Hidden")
+	logger.Warn("Skipping row", "row", 3)
+	if err := closeLog(); err != nil {
+		t.Fatalf("closing log error = %v", err)
+	}
+
+	if console.String() != "Warning: Skipping row row=3\n" {
+		t.Errorf("console output = %q", console.String())
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 1 ||
+		!strings.HasPrefix(lines[0], "time=") || !strings.HasSuffix(lines[0], `level=WARN msg="Skipping row" row=3`) {
+		t.Errorf("log file = %q", data)
+	}
+}
+
+func TestNewVerbose(t *testing.T) {
+	var console bytes.Buffer
+	logger, _, err := New(&config.LoggingConfig{Level: "error", Verbose: true}, &console)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	logger.Debug("Pipeline stage", "stage", "dedupe")
+	if console.String() != "Pipeline stage stage=dedupe\n" {
+		t.Errorf("verbose console output = %q", console.String())
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, _, err := New(&config.LoggingConfig{Level: "loud"}, &bytes.Buffer{}); err == nil {
+		t.Error("New() with unknown level succeeded")
+	}
+	missing := filepath.Join(t.TempDir(), "missing", "geo-chrono.log")
+	if _, _, err := New(&config.LoggingConfig{File: missing}, &bytes.Buffer{}); err == nil {
+		t.Error("New() with unwritable file succeeded")
+	}
+}
+
+func TestNewJSON(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "geo-chrono.log")
+	var console bytes.Buffer
+	logger, closeLog, err := New(&config.LoggingConfig{Format: FormatJSON, File: file}, &console)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	logger.Warn("Skipping row", "file", "walk.csv", "row", 7, "error", errors.New("invalid latitude"))
+	if err := closeLog(); err != nil {
+		t.Fatalf("closing log error = %v", err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, output := range map[string]string{"console": console.String(), "log file": string(data)} {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(output), &record); err != nil {
+			t.Fatalf("%s output %q is not one JSON object: %v", name, output, err)
+		}
+		if record["level"] != "WARN" || record["msg"] != "Skipping row" || record["file"] != "walk.csv" ||
+			record["row"] != 7.0 || record["error"] != "invalid latitude" || record["time"] == nil {
+			t.Errorf("%s record = %v", name, record)
+		}
+	}
+
+	if _, _, err := New(&config.LoggingConfig{Format: "xml"}, &console); err == nil {
+		t.Error("New() with unknown format succeeded")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	var out bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(NewConsoleHandler(&out, LevelTrace)))
+	defer slog.SetDefault(previous)
+
+	client := &http.Client{Transport: &Transport{}}
+	resp, err := client.Get(server.URL + "/v1/snap?key=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	line := out.String()
+	if !strings.HasPrefix(line, "HTTP request method=GET url="+server.URL+"/v1/snap duration=") ||
+		!strings.HasSuffix(line, " status=418\n") || strings.Contains(line, "secret") {
+		t.Errorf("trace output = %q", line)
+	}
+}
+
+func TestNewTraceLevelName(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "geo-chrono.log")
+	logger, closeLog, err := New(&config.LoggingConfig{Level: "trace", File: file}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	logger.Log(context.Background(), LevelTrace, "HTTP request")
+	closeLog()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `level=TRACE msg="HTTP request"`) {
+		t.Errorf("log file = %q", data)
+	}
+}
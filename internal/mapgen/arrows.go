@@ -0,0 +1,82 @@
+package mapgen
+
+import (
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// maxDirectionArrows caps the number of arrow markers drawn along the path so that
+// long tracks stay responsive; segments are sampled evenly above this count.
+const maxDirectionArrows = 200
+
+// Arrow is a direction marker placed at the midpoint of a path segment.
+//
+// @struct Arrow
+// @description Rotated arrow symbol showing the direction of travel
+// @property Latitude float64 Latitude of the segment midpoint
+// @property Longitude float64 Longitude of the segment midpoint
+// @property Rotation float64 Heading in degrees clockwise from north
+type Arrow struct {
+	Latitude  float64 // @field Latitude Latitude of the segment midpoint
+	Longitude float64 // @field Longitude Longitude of the segment midpoint
+	Rotation  float64 // @field Rotation Heading at the midpoint in degrees
+}
+
+// directionArrows places one arrow at the great-circle midpoint of each moving segment.
+//
+// @function directionArrows
+// @description Computes correctly rotated arrows for a geodesic path
+// @param points gps.Points GPS points in path order
+// @param limit int Maximum number of arrows to return
+// @return []Arrow Arrows in path order
+// @logic The rotation is the bearing from the midpoint onwards, which matches the
+// @logic drawn geodesic curve even where the initial bearing of the segment does not
+// @internal true
+func directionArrows(points gps.Points, limit int) []Arrow {
+	var segments []int
+	for i := 1; i < len(points); i++ {
+		if points[i-1].DistanceTo(points[i]) > 0 {
+			segments = append(segments, i)
+		}
+	}
+	if limit <= 0 || len(segments) == 0 {
+		return nil
+	}
+
+	// Sample evenly spaced segments when there are more than the limit
+	stride := (len(segments) + limit - 1) / limit
+
+	var arrows []Arrow
+	for j := 0; j < len(segments); j += stride {
+		prev, curr := points[segments[j]-1], points[segments[j]]
+		mid := prev.MidpointTo(curr)
+		arrows = append(arrows, Arrow{
+			Latitude:  mid.Latitude,
+			Longitude: mid.Longitude,
+			Rotation:  mid.BearingTo(curr),
+		})
+	}
+	return arrows
+}
+
+// headings returns the compass label for the direction of travel at each point.
+// Each point uses the bearing towards the next point that differs in position, and
+// trailing points fall back to the last known heading. Tracks without movement yield
+// empty labels.
+func headings(points gps.Points) []string {
+	labels := make([]string, len(points))
+	last := ""
+	for i := len(points) - 2; i >= 0; i-- {
+		if points[i].DistanceTo(points[i+1]) > 0 {
+			last = gps.CardinalDirection(points[i].BearingTo(points[i+1]))
+		}
+		labels[i] = last
+	}
+
+	// Points after the final movement keep the heading they arrived with
+	for i := 1; i < len(points); i++ {
+		if labels[i] == "" {
+			labels[i] = labels[i-1]
+		}
+	}
+	return labels
+}
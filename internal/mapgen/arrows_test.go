@@ -0,0 +1,78 @@
+package mapgen
+
+import (
+	"math"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestDirectionArrows(t *testing.T) {
+	points := gps.Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 0}, // stationary segment gets no arrow
+		{Latitude: 0, Longitude: 1},
+		{Latitude: 1, Longitude: 1},
+	}
+
+	arrows := directionArrows(points, 10)
+	if len(arrows) != 2 {
+		t.Fatalf("directionArrows() returned %d arrows, want 2", len(arrows))
+	}
+	if math.Abs(arrows[0].Longitude-0.5) > 1e-6 || math.Abs(arrows[0].Rotation-90) > 0.01 {
+		t.Errorf("first arrow = %+v, want east-facing at lng 0.5", arrows[0])
+	}
+	if math.Abs(arrows[1].Latitude-0.5) > 1e-6 || math.Abs(arrows[1].Rotation) > 0.01 {
+		t.Errorf("second arrow = %+v, want north-facing at lat 0.5", arrows[1])
+	}
+
+	// A long east-west segment at high latitude curves north, so the midpoint
+	// heading is due east even though the initial bearing is not
+	curved := gps.Points{
+		{Latitude: 60, Longitude: -30},
+		{Latitude: 60, Longitude: 30},
+	}
+	arrow := directionArrows(curved, 10)[0]
+	if math.Abs(arrow.Rotation-90) > 0.01 {
+		t.Errorf("curved segment rotation = %v, want 90", arrow.Rotation)
+	}
+	if initial := curved[0].BearingTo(curved[1]); math.Abs(initial-90) < 10 {
+		t.Errorf("initial bearing = %v, expected it to differ from 90", initial)
+	}
+}
+
+func TestDirectionArrowsLimit(t *testing.T) {
+	var points gps.Points
+	for i := 0; i < 1000; i++ {
+		points = append(points, gps.Point{Latitude: float64(i) * 0.001, Longitude: 0})
+	}
+
+	if got := len(directionArrows(points, 200)); got > 200 || got < 100 {
+		t.Errorf("directionArrows() returned %d arrows, want between 100 and 200", got)
+	}
+	if got := directionArrows(points[:1], 200); got != nil {
+		t.Errorf("directionArrows() on single point = %v, want nil", got)
+	}
+}
+
+func TestHeadings(t *testing.T) {
+	points := gps.Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 1, Longitude: 0},
+		{Latitude: 1, Longitude: 1},
+		{Latitude: 1, Longitude: 1},
+	}
+
+	want := []string{"N", "N", "E", "E", "E"}
+	got := headings(points)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("headings()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if got := headings(gps.Points{{Latitude: 1, Longitude: 1}}); len(got) != 1 || got[0] != "" {
+		t.Errorf("headings() on single point = %v, want one empty label", got)
+	}
+}
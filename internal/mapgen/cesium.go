@@ -0,0 +1,593 @@
+package mapgen
+
+import (
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// ProviderCesium renders the track on a CesiumJS 3D globe, drawing the path at its
+// recorded altitude. It suits flights, drone logs, and mountain routes.
+const ProviderCesium = "cesium"
+
+const (
+	cesiumScriptURL = "https://cesium.com/downloads/cesiumjs/releases/1.122/Build/Cesium/Cesium.js"
+	cesiumStyleURL  = "https://cesium.com/downloads/cesiumjs/releases/1.122/Build/Cesium/Widgets/widgets.css"
+)
+
+// cesiumIonHosts serve Cesium World Terrain when an ion token is configured.
+var cesiumIonHosts = []string{"api.cesium.com", "assets.ion.cesium.com"}
+
+// Cesium holds the resolved imagery, terrain, and library assets for a 3D globe.
+//
+// @struct Cesium
+// @description Imagery, terrain, and CesiumJS library settings
+// @property TileURL string Imagery tile URL template (empty for a plain globe)
+// @property Attribution string Imagery attribution shown in the credits
+// @property IonToken string Cesium ion access token (empty without terrain)
+// @property Terrain bool Whether Cesium World Terrain is shown
+// @property Altitude bool Whether the track is drawn at its recorded elevation
+// @property Assets Assets CesiumJS script and widget stylesheet
+type Cesium struct {
+	TileURL     string // @field TileURL Imagery tile URL template
+	Attribution string // @field Attribution Imagery attribution
+	IonToken    string // @field IonToken Cesium ion access token
+	Terrain     bool   // @field Terrain Show Cesium World Terrain
+	Altitude    bool   // @field Altitude Draw the track at its elevation (false clamps it to the ground)
+	Assets             // @field Assets CesiumJS script and widget stylesheet
+}
+
+// cesiumFor resolves the globe imagery from the map's tile settings. Tracks without
+// elevation data are clamped to the ground rather than drawn at sea level.
+func cesiumFor(cfg *config.MapConfig, points gps.Points) *Cesium {
+	cesium := &Cesium{
+		IonToken: cfg.Cesium.IonToken,
+		Terrain:  cfg.Cesium.Terrain,
+		Altitude: points.HasElevation(),
+		Assets:   Assets{ScriptURL: cesiumScriptURL, StyleURL: cesiumStyleURL},
+	}
+	cesium.TileURL, cesium.Attribution = tileSource(cfg.TileURL, cfg.Attribution)
+	return cesium
+}
+
+// hosts lists the remote hosts the globe loads resources from.
+func (c *Cesium) hosts() []string {
+	hosts := ExternalHosts([]byte(c.TileURL + " " + c.ScriptURL + " " + c.StyleURL))
+	if c.IonToken != "" {
+		hosts = append(hosts, cesiumIonHosts...)
+	}
+	return hosts
+}
+
+// cesiumTemplate defines the CesiumJS parts of the page: the map-head, map-script,
+// and map-loader templates invoked by the shared page template. Overlays are globe
+// entities; the info box shows the same content as the other providers' popups.
+const cesiumTemplate = `{{define "map-head"}}
+    <link rel="stylesheet" href="{{.Cesium.StyleURL}}">
+{{end}}
+
+{{define "map-script"}}
+        // Track heights in meters; without elevation data everything hugs the ground
+        const altitude = {{.Cesium.Altitude}};
+
+        function initMap() {
+            if (points.length === 0) {
+                document.getElementById('map').innerHTML = '<div style="text-align: center; padding: 50px; color: #666;">' + message('no_points') + '</div>';
+                return;
+            }
+
+            {{if .Cesium.IonToken}}
+            Cesium.Ion.defaultAccessToken = "{{.Cesium.IonToken}}";
+            {{end}}
+
+            map = new Cesium.Viewer('map', {
+                {{if .Cesium.TileURL}}
+                baseLayer: new Cesium.ImageryLayer(new Cesium.UrlTemplateImageryProvider({
+                    url: "{{.Cesium.TileURL}}",
+                    credit: "{{.Cesium.Attribution}}",
+                    maximumLevel: 19
+                })),
+                {{else}}
+                baseLayer: false,
+                {{end}}
+                {{if .Cesium.Terrain}}
+                terrain: Cesium.Terrain.fromWorldTerrain(),
+                {{end}}
+                baseLayerPicker: false,
+                geocoder: false,
+                navigationHelpButton: false,
+                homeButton: {{.Config.Map.Controls.ZoomControl}},
+                sceneModePicker: {{.Config.Map.Controls.MapTypeControl}},
+                fullscreenButton: {{.Config.Map.Controls.FullscreenControl}},
+                infoBox: {{.Config.InfoWindows.Enabled}},
+                animation: false,
+                timeline: false
+            });
+
+            // Start above the track center; the height matches the 2D zoom level
+            map.camera.setView({
+                destination: Cesium.Cartesian3.fromDegrees({{.Center.Longitude}}, {{.Center.Latitude}}, 40000000 / Math.pow(2, {{.Zoom}}))
+            });
+
+            {{if .Heatmap}}
+            // Render point density, instead of or next to individual markers
+            addHeatmap();
+            {{end}}
+
+            {{if .Trail}}
+            addMarkers();
+            {{if .LiveURL}}
+            listenForPoints();
+            {{end}}
+            {{if .Config.Path.Enabled}}
+            addWalkingPath();
+            {{end}}
+            {{if .Milestones}}
+            addMilestones();
+            {{end}}
+            {{end}}
+
+            {{if and .Config.Geofences.ShowBoundaries .Fences}}
+            addGeofences();
+            {{end}}
+
+            {{if .Reference}}
+            addReferenceRoute();
+            {{end}}
+
+            {{if .Encounters}}
+            addEncounters();
+            {{end}}
+
+            {{if .Meeting}}
+            addMeetingPoint();
+            {{end}}
+
+            {{if .Config.Map.Controls.MeasureTool}}
+            // Clicks on the globe add points to the measure tool while it is on
+            new Cesium.ScreenSpaceEventHandler(map.scene.canvas).setInputAction(click => {
+                const position = map.camera.pickEllipsoid(click.position, map.scene.globe.ellipsoid);
+                if (position) {
+                    const cartographic = Cesium.Cartographic.fromCartesian(position);
+                    addMeasurePoint(Cesium.Math.toDegrees(cartographic.latitude), Cesium.Math.toDegrees(cartographic.longitude));
+                }
+            }, Cesium.ScreenSpaceEventType.LEFT_CLICK);
+            {{end}}
+
+            {{if .Config.Map.Controls.Permalink}}
+            // Keep the link in step with the view, and reopen the view saved in it
+            map.camera.moveEnd.addEventListener(updatePermalink);
+            if (restorePermalink()) {
+                return;
+            }
+            {{end}}
+
+            {{if .Config.Map.AutoFitBounds}}
+            map.zoomTo(map.entities);
+            {{end}}
+        }
+
+        // The globe is redrawn at the printed map size
+        function setPrintLayout() {
+            map.resize();
+        }
+
+        {{if .Config.Map.Controls.Permalink}}
+        // The view is the point at the center of the screen, with a zoom level derived
+        // from the camera's distance to it as for the initial view
+        function getMapView() {
+            const canvas = map.scene.canvas;
+            const target = map.camera.pickEllipsoid(new Cesium.Cartesian2(canvas.clientWidth / 2, canvas.clientHeight / 2), map.scene.globe.ellipsoid);
+            const cartographic = Cesium.Cartographic.fromCartesian(target || map.camera.position);
+            const distance = target ? Cesium.Cartesian3.distance(map.camera.position, target) : cartographic.height;
+            return {
+                lat: Cesium.Math.toDegrees(cartographic.latitude),
+                lng: Cesium.Math.toDegrees(cartographic.longitude),
+                zoom: Math.log2(40000000 / Math.max(distance, 1))
+            };
+        }
+
+        function setMapView(lat, lng, zoom) {
+            map.camera.setView({ destination: Cesium.Cartesian3.fromDegrees(lng, lat, 40000000 / Math.pow(2, zoom)) });
+        }
+        {{end}}
+
+        {{if .Config.Map.Controls.MeasureTool}}
+        let measureLine;
+
+        function drawMeasureLine(measured) {
+            if (!measureLine) {
+                measureLine = map.entities.add({
+                    name: message('measured_distance'),
+                    polyline: {
+                        clampToGround: true,
+                        material: new Cesium.PolylineDashMaterialProperty({ color: Cesium.Color.BLACK }),
+                        width: 3
+                    }
+                });
+            }
+            measureLine.polyline.positions = groundPositions(measured);
+        }
+        {{end}}
+
+        function trackPosition(point) {
+            return Cesium.Cartesian3.fromDegrees(point.lng, point.lat, altitude ? point.elevation : 0);
+        }
+
+        function groundPositions(positions) {
+            return positions.map(position => Cesium.Cartesian3.fromDegrees(position.lng, position.lat));
+        }
+
+        function color(css, alpha) {
+            return Cesium.Color.fromCssColorString(css).withAlpha(alpha);
+        }
+
+        // lineMaterial paints a solid, dashed, or dotted polyline; dots are dashes a
+        // quarter as long as the default ones
+        function lineMaterial(css, alpha, pattern) {
+            if (pattern === 'dashed') {
+                return new Cesium.PolylineDashMaterialProperty({ color: color(css, alpha) });
+            }
+            if (pattern === 'dotted') {
+                return new Cesium.PolylineDashMaterialProperty({ color: color(css, alpha), dashLength: 4 });
+            }
+            return color(css, alpha);
+        }
+
+        // addPoint places a dot that stays visible through terrain, optionally labelled
+        function addPoint(position, css, size, name, description, text, onGround, label) {
+            return map.entities.add({
+                name: name,
+                description: description,
+                position: position,
+                point: {
+                    pixelSize: size,
+                    color: color(css, 1),
+                    outlineColor: Cesium.Color.BLACK,
+                    outlineWidth: 2,
+                    heightReference: onGround ? Cesium.HeightReference.CLAMP_TO_GROUND : Cesium.HeightReference.NONE,
+                    disableDepthTestDistance: Number.POSITIVE_INFINITY
+                },
+                label: text ? {
+                    text: text,
+                    font: label ? label.font : 'bold 12px Arial, sans-serif',
+                    fillColor: label ? color(label.color, 1) : Cesium.Color.WHITE,
+                    outlineColor: Cesium.Color.BLACK,
+                    outlineWidth: 2,
+                    style: Cesium.LabelStyle.FILL_AND_OUTLINE,
+                    pixelOffset: new Cesium.Cartesian2(0, -size),
+                    heightReference: onGround ? Cesium.HeightReference.CLAMP_TO_GROUND : Cesium.HeightReference.NONE,
+                    disableDepthTestDistance: Number.POSITIVE_INFINITY
+                } : undefined
+            });
+        }
+
+        // addIcon places a custom marker image with its anchor on the position
+        function addIcon(position, icon, name, description, onGround) {
+            return map.entities.add({
+                name: name,
+                description: description,
+                position: position,
+                billboard: {
+                    image: icon.url,
+                    width: icon.width,
+                    height: icon.height,
+                    pixelOffset: new Cesium.Cartesian2(icon.width / 2 - icon.anchorX, icon.height / 2 - icon.anchorY),
+                    heightReference: onGround ? Cesium.HeightReference.CLAMP_TO_GROUND : Cesium.HeightReference.NONE,
+                    disableDepthTestDistance: Number.POSITIVE_INFINITY
+                }
+            });
+        }
+
+        {{if .Heatmap}}
+        const heatmap = [];
+
+        function addHeatmap() {
+            const maxWeight = Math.max(...heatmapCells.map(cell => cell[2]));
+            heatmapCells.forEach(cell => {
+                heatmap.push(map.entities.add({
+                    position: Cesium.Cartesian3.fromDegrees(cell[1], cell[0]),
+                    point: {
+                        pixelSize: {{if .Config.Heatmap.Radius}}{{.Config.Heatmap.Radius}}{{else}}20{{end}},
+                        color: color('#FF0000', {{if .Config.Heatmap.Opacity}}{{.Config.Heatmap.Opacity}}{{else}}0.6{{end}} * cell[2] / maxWeight),
+                        heightReference: Cesium.HeightReference.CLAMP_TO_GROUND
+                    }
+                }));
+            });
+            setHeatmapVisible(!hiddenLayers.has('heatmap'));
+        }
+
+        function setHeatmapVisible(visible) {
+            heatmap.forEach(cell => {
+                cell.show = visible;
+            });
+        }
+        {{end}}
+
+        {{if and .Config.Geofences.ShowBoundaries .Fences}}
+        function addGeofences() {
+            geofences.forEach(fence => {
+                const style = { material: color('#FF8800', 0.15), outline: true, outlineColor: color('#FF8800', 0.9) };
+                const label = {
+                    text: fence.name,
+                    font: 'bold 12px Arial, sans-serif',
+                    fillColor: color('#A34E00', 1),
+                    outlineColor: Cesium.Color.WHITE,
+                    outlineWidth: 3,
+                    style: Cesium.LabelStyle.FILL_AND_OUTLINE,
+                    heightReference: Cesium.HeightReference.CLAMP_TO_GROUND,
+                    disableDepthTestDistance: Number.POSITIVE_INFINITY
+                };
+                if (fence.center) {
+                    geofenceEntities.push(map.entities.add({
+                        name: fence.name,
+                        position: Cesium.Cartesian3.fromDegrees(fence.center.lng, fence.center.lat),
+                        ellipse: Object.assign({ semiMajorAxis: fence.radius, semiMinorAxis: fence.radius }, style),
+                        label: label
+                    }));
+                } else {
+                    geofenceEntities.push(map.entities.add({
+                        name: fence.name,
+                        position: Cesium.Cartesian3.fromDegrees(fence.label.lng, fence.label.lat),
+                        polygon: Object.assign({ hierarchy: groundPositions(fence.polygon) }, style),
+                        label: label
+                    }));
+                }
+            });
+        }
+
+        const geofenceEntities = [];
+
+        function setGeofencesVisible(visible) {
+            geofenceEntities.forEach(entity => {
+                entity.show = visible;
+            });
+        }
+        {{end}}
+
+        {{if .Reference}}
+        function addReferenceRoute() {
+            map.entities.add({
+                name: message('reference_route'),
+                polyline: {
+                    positions: groundPositions(referenceRoute),
+                    clampToGround: true,
+                    material: lineMaterial("{{.ReferenceColor}}", 0.7, "{{.Config.Compare.StrokePattern}}"),
+                    width: {{.Config.Path.Style.Weight}} + 2
+                }
+            });
+        }
+        {{end}}
+
+        {{if .Encounters}}
+        function addEncounters() {
+            encounters.forEach(encounter => {
+                encounter.paths.forEach(path => {
+                    map.entities.add({
+                        polyline: {
+                            positions: groundPositions(path),
+                            clampToGround: true,
+                            material: color("{{.EncounterColor}}", 0.9),
+                            width: {{.Config.Path.Style.Weight}} + 4
+                        }
+                    });
+                });
+
+                addPoint(Cesium.Cartesian3.fromDegrees(encounter.closest.lng, encounter.closest.lat), "{{.EncounterColor}}", 16, encounter.users,
+                    "<div style=\"font-family: Arial, sans-serif;\">" +
+                    "<p><strong>" + message('from') + ":</strong> " + encounter.start + "</p>" +
+                    "<p><strong>" + message('to') + ":</strong> " + encounter.end + "</p>" +
+                    "<p><strong>" + message('closest') + ":</strong> " + encounter.distance.toFixed(0) + " m</p></div>", '&', true);
+            });
+        }
+        {{end}}
+
+        {{if .Meeting}}
+        function addMeetingPoint() {
+            // Dashed lines from each user's position to the meeting point
+            meeting.users.forEach(user => {
+                map.entities.add({
+                    polyline: {
+                        positions: groundPositions([user.position, meeting.point]),
+                        clampToGround: true,
+                        material: new Cesium.PolylineDashMaterialProperty({ color: color('#6A1B9A', 0.8) }),
+                        width: 2
+                    }
+                });
+            });
+
+            addPoint(Cesium.Cartesian3.fromDegrees(meeting.point.lng, meeting.point.lat), '#6A1B9A', 18, message('meeting_point'),
+                "<div style=\"font-family: Arial, sans-serif;\">" +
+                "<p><strong>" + message('based_on_positions') + ":</strong> " + meeting.time + "</p>" +
+                meeting.users.map(user => "<p><strong>" + user.name + ":</strong> " + message('distance_away', { distance: formatNumber(user.distance / 1000, 2) }) + "</p>").join("") +
+                "</div>", 'M', true);
+        }
+        {{end}}
+
+        function addMarkers() {
+            points.forEach(addMarker);
+        }
+
+        // addMarker draws the marker of one point, also for points pushed by a live server
+        function addMarker(point, index) {
+            {{if .Stops}}
+            // Points of a stop are drawn as one stop marker
+            const stop = replacingStop[index];
+            if (stop) {
+                if (stop.anchor === index) {
+                    registerMarker(index, addStopMarker(stop));
+                }
+                return;
+            }
+            {{end}}
+
+            let css = '#0000FF', size = 10, title = point.title;
+
+            // Labels float above the point rather than inside it, so their default
+            // font size is that of a 36 pixel marker
+            const label = markerLabel(index, 36);
+            if (index === 0) {
+                css = '#00FF00';
+                size = 16;
+                title = "START - " + title;
+            } else if (index === points.length - 1) {
+                css = '#FF0000';
+                size = 16;
+                title = "END - " + title;
+            } else {
+                css = pointColor(point);
+            }
+
+            const description = {{if .Config.InfoWindows.Enabled}}createInfoWindowContent(point, title, index){{else}}undefined{{end}};
+            const custom = customIcon(index);
+            const marker = custom
+                ? addIcon(trackPosition(point), custom, title, description, !altitude)
+                : addPoint(trackPosition(point), css, size, title, description, label.text, !altitude, label);
+
+            // Track markers so the category and time filters can show and hide them
+            registerMarker(index, marker);
+        }
+
+        {{if .Milestones}}
+        // Milestones are labels on a white background, standing on the path
+        function addMilestones() {
+            milestones.forEach(milestone => {
+                milestoneMarkers.push(map.entities.add({
+                    name: milestone.label,
+                    position: trackPosition(milestone),
+                    label: {
+                        text: milestone.label,
+                        font: 'bold 11px Arial, sans-serif',
+                        fillColor: color('#333333', 1),
+                        showBackground: true,
+                        backgroundColor: color('#FFFFFF', 0.9),
+                        heightReference: altitude ? Cesium.HeightReference.NONE : Cesium.HeightReference.CLAMP_TO_GROUND,
+                        disableDepthTestDistance: Number.POSITIVE_INFINITY
+                    }
+                }));
+            });
+        }
+        {{end}}
+
+        {{if .Stops}}
+        // Stop points grow with the marker size of the other providers; the dwell time
+        // floats above them like a marker label
+        function addStopMarker(stop) {
+            const description = {{if .Config.InfoWindows.Enabled}}stopInfoContent(stop){{else}}undefined{{end}};
+            return addPoint(trackPosition(stop), "{{.StopColor}}", stop.size / 2, message('stop') + ' (' + stop.duration + ')', description, stop.label, !altitude);
+        }
+        {{end}}
+
+        function setMarkerVisible(marker, visible) {
+            marker.show = visible;
+        }
+
+        {{if .Sidebar}}
+        // focusMarker flies the camera to a marker and selects it, opening its description
+        function focusMarker(marker, point) {
+            map.flyTo(marker, { offset: new Cesium.HeadingPitchRange(0, -Cesium.Math.PI_OVER_FOUR, 1500) });
+            map.selectedEntity = marker;
+        }
+        {{end}}
+
+        {{if or .PlaybackMillis .Profile}}
+        // The track marker follows playback and the elevation profile cursor
+        let trackMarker = null;
+
+        function setTrackMarker(position) {
+            if (!position) {
+                if (trackMarker) {
+                    map.entities.remove(trackMarker);
+                    trackMarker = null;
+                }
+                return;
+            }
+            if (!trackMarker) {
+                trackMarker = addPoint(trackPosition(position), "{{.Config.Path.Style.Color}}", 14, message('position'), undefined, undefined, !altitude);
+                trackMarker.point.outlineColor = Cesium.Color.WHITE;
+            }
+            trackMarker.position = trackPosition(position);
+        }
+        {{end}}
+
+        {{if .Config.Path.Enabled}}
+        let walkingPath, pathWall;
+
+        function addWalkingPath() {
+            const positions = points.map(trackPosition);
+            {{if not (or .SpeedScale .DayScale .UserColors)}}
+            walkingPath = map.entities.add({
+                name: message('path'),
+                polyline: {
+                    positions: positions,
+                    clampToGround: !altitude,
+                    material: lineMaterial("{{.Config.Path.Style.Color}}", {{.Config.Path.Style.Opacity}}, "{{.Config.Path.Style.StrokePattern}}"),
+                    width: {{.Config.Path.Style.Weight}}
+                }
+            });
+            {{else}}
+            setPathRuns(points);
+            {{end}}
+
+            // A translucent curtain down to the ground makes the altitude readable
+            if (altitude) {
+                pathWall = map.entities.add({
+                    wall: {
+                        positions: positions,
+                        material: color("{{.Config.Path.Style.Color}}", 0.15)
+                    }
+                });
+            }
+        }
+
+        {{if or .SpeedScale .DayScale .UserColors}}
+        // Speed and user colored paths are drawn as one polyline per color run,
+        // reused as the filters change the path
+        const pathLines = [];
+
+        function setPathRuns(pathPoints) {
+            const runs = pathRuns(pathPoints);
+            runs.forEach((run, i) => {
+                if (!pathLines[i]) {
+                    pathLines[i] = map.entities.add({
+                        name: message('path'),
+                        polyline: { clampToGround: !altitude, width: {{.Config.Path.Style.Weight}} }
+                    });
+                }
+                pathLines[i].polyline.positions = run.points.map(trackPosition);
+                pathLines[i].polyline.material = lineMaterial(run.color, {{.Config.Path.Style.Opacity}}, "{{.Config.Path.Style.StrokePattern}}");
+                pathLines[i].show = true;
+            });
+            pathLines.slice(runs.length).forEach(entity => {
+                entity.show = false;
+            });
+        }
+        {{end}}
+
+        function setPathPoints(pathPoints) {
+            {{if or .SpeedScale .DayScale .UserColors}}
+            setPathRuns(pathPoints);
+            {{end}}
+
+            // Lines and walls need two positions, so a shorter window hides them
+            const positions = pathPoints.map(trackPosition);
+            [walkingPath, pathWall].forEach(entity => {
+                if (entity) {
+                    entity.show = positions.length > 1;
+                }
+            });
+            if (positions.length > 1) {
+                if (walkingPath) {
+                    walkingPath.polyline.positions = positions;
+                }
+                if (pathWall) {
+                    pathWall.wall.positions = positions;
+                }
+            }
+        }
+        {{end}}
+{{end}}
+
+{{define "map-loader"}}
+    <script src="{{.Cesium.ScriptURL}}"></script>
+    <script>initMap();</script>
+{{end}}`
@@ -0,0 +1,106 @@
+package mapgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestCesiumFor(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfg          config.MapConfig
+		points       gps.Points
+		wantTileURL  string
+		wantAltitude bool
+		wantHosts    []string
+	}{
+		{
+			name:        "flat track with default imagery",
+			points:      gps.Points{{Latitude: 1, Longitude: 2}},
+			wantTileURL: DefaultTileURL,
+			wantHosts:   []string{"cesium.com", "tile.openstreetmap.org"},
+		},
+		{
+			name:         "flight with terrain",
+			cfg:          config.MapConfig{Cesium: config.CesiumConfig{IonToken: "token", Terrain: true}},
+			points:       gps.Points{{Latitude: 1, Longitude: 2, Elevation: 3000}},
+			wantTileURL:  DefaultTileURL,
+			wantAltitude: true,
+			wantHosts:    []string{"cesium.com", "tile.openstreetmap.org", "api.cesium.com", "assets.ion.cesium.com"},
+		},
+		{
+			name:      "plain globe",
+			cfg:       config.MapConfig{TileURL: NoTiles},
+			points:    gps.Points{{Latitude: 1, Longitude: 2}},
+			wantHosts: []string{"cesium.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cesiumFor(&tt.cfg, tt.points)
+			if got.TileURL != tt.wantTileURL {
+				t.Errorf("cesiumFor() TileURL = %q, want %q", got.TileURL, tt.wantTileURL)
+			}
+			if got.Altitude != tt.wantAltitude {
+				t.Errorf("cesiumFor() Altitude = %v, want %v", got.Altitude, tt.wantAltitude)
+			}
+			if hosts := got.hosts(); strings.Join(hosts, ",") != strings.Join(tt.wantHosts, ",") {
+				t.Errorf("hosts() = %v, want %v", hosts, tt.wantHosts)
+			}
+		})
+	}
+}
+
+func TestCesiumGeneration(t *testing.T) {
+	points := gps.Points{
+		{Latitude: 46.5, Longitude: 7.9, Elevation: 2100},
+		{Latitude: 46.55, Longitude: 7.95, Elevation: 3450},
+	}
+	cfg := &config.Config{
+		Map: config.MapConfig{Provider: ProviderCesium, AutoFitBounds: true},
+		Path: config.PathConfig{
+			Enabled: true,
+			Style:   config.PathStyleConfig{Color: "#FF0000", Opacity: 0.8, Weight: 3},
+		},
+		InfoWindows: config.InfoWindowsConfig{Enabled: true},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "globe.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+
+	for _, want := range []string{
+		`<script src="https://cesium.com/downloads/cesiumjs/releases/1.122/Build/Cesium/Cesium.js"></script>`,
+		"new Cesium.Viewer('map'",
+		"const altitude =  true ;",
+		"elevation:  3450 ,",
+		"map.zoomTo(map.entities);",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Cesium HTML missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"maps.googleapis.com", "Cesium.Ion.defaultAccessToken", "fromWorldTerrain"} {
+		if strings.Contains(html, unwanted) {
+			t.Errorf("Cesium HTML contains %q", unwanted)
+		}
+	}
+
+	cfg.Output.SelfContained = true
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err == nil {
+		t.Error("Generate() with self-contained Cesium output succeeded, want error")
+	}
+}
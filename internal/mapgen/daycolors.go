@@ -0,0 +1,106 @@
+package mapgen
+
+import (
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// PathColorByDay colors each path segment by the calendar day it starts on
+// (path.style.color_by).
+const PathColorByDay = "day"
+
+// DefaultDayRamp runs from dark purple for the first day through blue and green
+// to yellow for the last, staying readable when neighbouring days are close.
+var DefaultDayRamp = []string{"#440154", "#3B528B", "#21908C", "#5DC963", "#FDE725"}
+
+// DayColor is the path color of one calendar day of the track.
+//
+// @struct DayColor
+// @description Legend entry of a day-colored path
+// @property Date time.Time Midnight starting the day in the processing timezone
+// @property Color string Hex color of the day's path segments
+type DayColor struct {
+	Date  time.Time // @field Date Start of the day in the processing timezone
+	Color string    // @field Color Hex color of the day's path segments
+}
+
+// DayScale maps each path segment to the color of the day it starts on.
+//
+// @struct DayScale
+// @description Day coloring of the path with the days shown in the legend
+// @property Colors []string Hex color of each segment, from point i to point i+1
+// @property Days []DayColor Color of each day of the track in chronological order
+type DayScale struct {
+	Colors []string   // @field Colors Hex color of each segment in path order
+	Days   []DayColor // @field Days Color of each day in chronological order
+}
+
+// dayScaleFor colors the segments between consecutive points by calendar day in
+// processing.timezone, spreading the days evenly along the ramp from its first
+// color on the first day to its last color on the last. Segments starting at an
+// untimed point take the color of the segment before them. It returns nil unless
+// path.style.color_by is day and the track has a timed segment.
+//
+// @function dayScaleFor
+// @description Computes per-segment day colors for the path
+// @param points gps.Points Chronologically sorted GPS points
+// @param cfg *config.Config Path style and processing timezone
+// @return *DayScale Segment colors and day legend (nil when not coloring by day)
+// @return error Error if a ramp color is not a hex color or the timezone is unknown
+// @internal true
+func dayScaleFor(points gps.Points, cfg *config.Config) (*DayScale, error) {
+	if cfg.Path.Style.ColorBy != PathColorByDay || len(points) < 2 {
+		return nil, nil
+	}
+
+	ramp := cfg.Path.Style.DayRamp
+	if len(ramp) == 0 {
+		ramp = DefaultDayRamp
+	}
+	stops := make([][3]float64, len(ramp))
+	for i, color := range ramp {
+		rgb, err := config.ParseHexColor(color)
+		if err != nil {
+			return nil, configErrorf("invalid path day ramp: %w", err)
+		}
+		stops[i] = rgb
+	}
+	loc, err := time.LoadLocation(cfg.Processing.Timezone)
+	if err != nil {
+		return nil, configErrorf("invalid processing timezone: %w", err)
+	}
+
+	// Number the days of the segment starts, carrying the day over untimed points
+	dayOf := make([]int, len(points)-1)
+	var days []time.Time
+	day := -1
+	for i := range dayOf {
+		if timestamp := points[i].Timestamp; !timestamp.IsZero() {
+			local := timestamp.In(loc)
+			start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+			if len(days) == 0 || !start.Equal(days[len(days)-1]) {
+				days = append(days, start)
+			}
+			day = len(days) - 1
+		}
+		dayOf[i] = day
+	}
+	if len(days) == 0 {
+		return nil, nil
+	}
+
+	scale := &DayScale{Colors: make([]string, len(dayOf)), Days: make([]DayColor, len(days))}
+	for i, start := range days {
+		fraction := 0.0
+		if len(days) > 1 {
+			fraction = float64(i) / float64(len(days)-1)
+		}
+		scale.Days[i] = DayColor{Date: start, Color: interpolateColor(stops, fraction)}
+	}
+	for i, day := range dayOf {
+		scale.Colors[i] = scale.Days[max(day, 0)].Color
+	}
+	return scale, nil
+}
@@ -0,0 +1,137 @@
+package mapgen
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestDayScaleFor(t *testing.T) {
+	start := time.Date(2025, 10, 28, 22, 0, 0, 0, time.UTC)
+	// Three days in UTC, the last two of them on the same day in Los Angeles
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0},
+		{Timestamp: start.Add(3 * time.Hour), Latitude: 0.001, Longitude: 0},
+		{Latitude: 0.002, Longitude: 0}, // untimed points keep the day before them
+		{Timestamp: start.Add(27 * time.Hour), Latitude: 0.003, Longitude: 0},
+		{Timestamp: start.Add(28 * time.Hour), Latitude: 0.004, Longitude: 0},
+	}
+
+	tests := []struct {
+		name     string
+		style    config.PathStyleConfig
+		timezone string
+		points   gps.Points
+		want     []string
+		wantDays int
+		wantNil  bool
+		wantFail bool
+	}{
+		{name: "single color", style: config.PathStyleConfig{}, points: points, wantNil: true},
+		{name: "speed colors", style: config.PathStyleConfig{ColorBy: PathColorBySpeed}, points: points, wantNil: true},
+		{name: "one point", style: config.PathStyleConfig{ColorBy: PathColorByDay}, points: points[:1], wantNil: true},
+		{name: "untimed", style: config.PathStyleConfig{ColorBy: PathColorByDay}, points: gps.Points{{}, {}}, wantNil: true},
+		{
+			name:     "three days",
+			style:    config.PathStyleConfig{ColorBy: PathColorByDay, DayRamp: []string{"#00F", "#F00"}},
+			points:   points,
+			want:     []string{"#0000FF", "#800080", "#800080", "#FF0000"},
+			wantDays: 3,
+		},
+		{
+			name:     "timezone",
+			style:    config.PathStyleConfig{ColorBy: PathColorByDay, DayRamp: []string{"#00F", "#F00"}},
+			timezone: "America/Los_Angeles",
+			points:   points,
+			want:     []string{"#0000FF", "#0000FF", "#0000FF", "#FF0000"},
+			wantDays: 2,
+		},
+		{
+			name:     "single day",
+			style:    config.PathStyleConfig{ColorBy: PathColorByDay},
+			points:   points[:2],
+			want:     []string{DefaultDayRamp[0]},
+			wantDays: 1,
+		},
+		{
+			name:     "named color",
+			style:    config.PathStyleConfig{ColorBy: PathColorByDay, DayRamp: []string{"blue", "red"}},
+			points:   points,
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Path: config.PathConfig{Style: tt.style}, Processing: config.ProcessingConfig{Timezone: tt.timezone}}
+			scale, err := dayScaleFor(tt.points, cfg)
+			if (err != nil) != tt.wantFail {
+				t.Fatalf("dayScaleFor() error = %v, want error %v", err, tt.wantFail)
+			}
+			if tt.wantFail {
+				return
+			}
+			if (scale == nil) != tt.wantNil {
+				t.Fatalf("dayScaleFor() = %+v, want nil %v", scale, tt.wantNil)
+			}
+			if scale == nil {
+				return
+			}
+			if !slices.Equal(scale.Colors, tt.want) {
+				t.Errorf("Colors = %v, want %v", scale.Colors, tt.want)
+			}
+			if len(scale.Days) != tt.wantDays {
+				t.Errorf("Days = %+v, want %d days", scale.Days, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestDayColors(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: start.Add(time.Hour), Latitude: 37.78, Longitude: -122.41},
+		{Timestamp: start.Add(24 * time.Hour), Latitude: 37.79, Longitude: -122.41},
+		{Timestamp: start.Add(25 * time.Hour), Latitude: 37.80, Longitude: -122.41},
+	}
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Day Test", Provider: provider, Language: "de"},
+				Path: config.PathConfig{
+					Enabled: true,
+					Style:   config.PathStyleConfig{Color: "#123456", Opacity: 0.8, Weight: 3, ColorBy: PathColorByDay},
+				},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{
+				`const dayColors = ["#440154","#440154","#FDE725"];`,
+				"dayColors[previous.index]",
+				"setPathPoints(",
+				"28.10.2025",
+				"29.10.2025",
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("generated HTML missing %q", want)
+				}
+			}
+			if strings.Contains(html, "Zurückgelegte Strecke") {
+				t.Error("day colored legend still shows the single path color")
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package mapgen
+
+import "html/template"
+
+// Download is a file offered for download from the map page, such as the GPX or
+// KML export of the track written in the same run.
+//
+// @struct Download
+// @description Download button linking to an exported copy of the track
+// @property Label string Button text, such as "Download GPX"
+// @property File string File name suggested to the browser when saving
+// @property URL template.URL Link to the file next to the page, or a data URL embedding it
+type Download struct {
+	Label string       // @field Label Button text
+	File  string       // @field File File name suggested by the download attribute
+	URL   template.URL // @field URL Relative link or data URL of the file
+}
+
+// SetDownloads sets the files offered by download buttons on the generated pages.
+// The URLs are written as given, so relative links must be relative to the page.
+// Pass nil to leave the buttons out.
+func (g *Generator) SetDownloads(downloads []Download) {
+	g.downloads = downloads
+}
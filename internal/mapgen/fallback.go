@@ -0,0 +1,47 @@
+package mapgen
+
+import (
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// FallbackLeaflet renders the track with Leaflet and raster tiles when Google Maps fails.
+const FallbackLeaflet = "leaflet"
+
+// Defaults for the Leaflet fallback provider.
+const (
+	DefaultFallbackTileURL     = DefaultTileURL
+	DefaultFallbackAttribution = DefaultAttribution
+	DefaultFallbackTimeout     = 10 // seconds
+)
+
+// Fallback holds the resolved settings for the runtime fallback map.
+//
+// @struct Fallback
+// @description Backup Leaflet map loaded by the page when Google Maps fails
+// @property Leaflet Leaflet Tile source and Leaflet library assets
+// @property TimeoutMillis int Time to wait for Google Maps before falling back
+type Fallback struct {
+	Leaflet           // @field Leaflet Tile source and Leaflet library assets
+	TimeoutMillis int // @field TimeoutMillis Google Maps load timeout in milliseconds
+}
+
+// fallbackFor resolves the configured fallback provider, applying defaults.
+// Returns nil when no fallback is configured.
+func fallbackFor(cfg *config.FallbackConfig) (*Fallback, error) {
+	switch cfg.Provider {
+	case "":
+		return nil, nil
+	case FallbackLeaflet:
+	default:
+		return nil, configErrorf("unknown fallback provider %q (use %s)", cfg.Provider, FallbackLeaflet)
+	}
+
+	fallback := &Fallback{
+		Leaflet:       *leafletFor(cfg.TileURL, cfg.Attribution),
+		TimeoutMillis: cfg.TimeoutSeconds * 1000,
+	}
+	if fallback.TimeoutMillis <= 0 {
+		fallback.TimeoutMillis = DefaultFallbackTimeout * 1000
+	}
+	return fallback, nil
+}
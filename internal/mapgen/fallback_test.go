@@ -0,0 +1,95 @@
+package mapgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestFallbackFor(t *testing.T) {
+	if got, err := fallbackFor(&config.FallbackConfig{}); got != nil || err != nil {
+		t.Errorf("fallbackFor(none) = %v, %v, want nil, nil", got, err)
+	}
+
+	if _, err := fallbackFor(&config.FallbackConfig{Provider: "bing"}); err == nil {
+		t.Error("fallbackFor(unknown) error = nil, want error")
+	}
+
+	got, err := fallbackFor(&config.FallbackConfig{Provider: FallbackLeaflet})
+	if err != nil {
+		t.Fatalf("fallbackFor(leaflet) error = %v", err)
+	}
+	if got.TileURL != DefaultFallbackTileURL || got.TimeoutMillis != DefaultFallbackTimeout*1000 {
+		t.Errorf("fallbackFor(leaflet) defaults = %+v", got)
+	}
+
+	want := []string{"tile.openstreetmap.org", "unpkg.com"}
+	if hosts := got.hosts(); !reflect.DeepEqual(hosts, want) {
+		t.Errorf("Fallback.hosts() = %v, want %v", hosts, want)
+	}
+
+	custom, _ := fallbackFor(&config.FallbackConfig{
+		Provider:       FallbackLeaflet,
+		TileURL:        "https://{s}.tiles.example.org/{z}/{x}/{y}.png",
+		TimeoutSeconds: 3,
+	})
+	if custom.TimeoutMillis != 3000 || !containsHost(custom.hosts(), "{s}.tiles.example.org") {
+		t.Errorf("fallbackFor(custom) = %+v, hosts %v", custom, custom.hosts())
+	}
+}
+
+func TestFallbackGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.7749, Longitude: -122.4194},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.8044, Longitude: -122.2711},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map: config.MapConfig{
+			Title:    "Fallback Test",
+			Fallback: config.FallbackConfig{Provider: FallbackLeaflet, TimeoutSeconds: 5},
+		},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "fallback.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		"window.gm_authFailure",
+		`onerror="activateFallback('script error')"`,
+		"function initFallbackMap()",
+		"5000",
+	}
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Generated HTML missing %q", want)
+		}
+	}
+
+	// Without a fallback none of the failover code is emitted
+	cfg.Map.Fallback = config.FallbackConfig{}
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, _ = os.ReadFile(outputFile)
+	if strings.Contains(string(content), "activateFallback") {
+		t.Error("Generated HTML contains fallback code without a fallback provider")
+	}
+}
@@ -12,18 +12,63 @@
 // - Information windows with GPS data
 // - Responsive web design
 // - Template-based HTML generation
+// - Strict privacy mode with a third-party request audit
 package mapgen
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/saratily/geo-chrono/internal/aggregate"
 	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/geofence"
 	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/i18n"
+	"github.com/saratily/geo-chrono/internal/proximity"
+	"github.com/saratily/geo-chrono/internal/stats"
+	"github.com/saratily/geo-chrono/internal/stops"
+	"github.com/saratily/geo-chrono/internal/tiles"
+	"github.com/saratily/geo-chrono/internal/weather"
 )
 
+// DefaultReferenceColor is the line color of a reference route when none is configured.
+const DefaultReferenceColor = "#9C27B0"
+
+// Supported values for MapConfig.RenderMode.
+const (
+	RenderModeTrail    = "trail"    // Markers connected by the chronological path (default)
+	RenderModeHeatmap  = "heatmap"  // Density heatmap of visited locations
+	RenderModeCombined = "combined" // Trail and heatmap with a control to switch between views
+)
+
+// ErrConfig is wrapped by errors of settings the map cannot be generated with,
+// such as an invalid path gradient or a template that does not parse, so callers
+// can tell them apart from failures to write the output.
+var ErrConfig = errors.New("invalid map configuration")
+
+// configError marks err as caused by the configuration, keeping its message.
+type configError struct {
+	err error // Problem with the setting
+}
+
+func (e *configError) Error() string { return e.err.Error() }
+
+func (e *configError) Unwrap() []error { return []error{e.err, ErrConfig} }
+
+// configErrorf formats an error like fmt.Errorf and marks it with ErrConfig.
+func configErrorf(format string, args ...any) error {
+	return &configError{err: fmt.Errorf(format, args...)}
+}
+
 // Generator handles the creation of HTML files containing interactive Google Maps
 // for GPS track visualization.
 //
@@ -32,7 +77,13 @@ import (
 // @description Uses Go templates to create dynamic web pages with JavaScript
 // @property config Config Configuration settings for map appearance and behavior
 type Generator struct {
-	config *config.Config // @field config Configuration settings for map appearance and behavior
+	config     *config.Config  // @field config Configuration settings for map appearance and behavior
+	reference  gps.Points      // @field reference Optional reference route drawn for comparison
+	navigation *Navigation     // @field navigation Links to neighbouring pages of a multi-page output (nil for single maps)
+	downloads  []Download      // @field downloads Exported files offered by download buttons (nil for none)
+	weather    *weather.Report // @field weather Historical weather during the track (nil when disabled)
+	tiles      *tiles.Bundle   // @field tiles Tiles embedded in leaflet maps (nil to load them from the tile server)
+	live       string          // @field live URL of the event stream of newly ingested points (empty for static pages)
 }
 
 // NewGenerator creates a new map generator instance with the provided configuration.
@@ -46,6 +97,13 @@ func NewGenerator(cfg *config.Config) *Generator {
 	return &Generator{config: cfg}
 }
 
+// SetReference sets a reference route, such as a planned GPX route, to draw beneath
+// the track. Generated maps then include off-route deviation statistics.
+// Pass nil to disable comparison.
+func (g *Generator) SetReference(route gps.Points) {
+	g.reference = route
+}
+
 // MapData holds all the data required for HTML template execution and map generation.
 //
 // @struct MapData
@@ -56,12 +114,131 @@ func NewGenerator(cfg *config.Config) *Generator {
 // @property Title string HTML page title and header text
 // @property OutputFile string Target file path for generated HTML
 // @property Config Config Complete configuration for template access
+// @property Stats stats.Summary Route statistics shown in the stats bar
+// @property Categories []string Distinct point categories for visibility toggles
+// @property Heatmap []gps.WeightedPoint Density cells when rendering in heatmap or combined mode
+// @property Trail bool Whether markers and the path are drawn (false in heatmap mode)
+// @property Libraries []string Google Maps libraries required by the page
+// @property Restriction Restriction Padded viewport bounds when map.restrict_bounds is enabled
+// @property Zoom int Initial zoom level (configured or estimated from the track extent)
+// @property Fences []geofence.Fence Configured geofences for boundary rendering
+// @property GeofenceEvents []geofence.Event Chronological fence entry/exit events
+// @property ZIndex map[string]int Z-index for each map layer (see DefaultLayerOrder)
+// @property Headings []string Compass direction of travel at each point
+// @property Arrows []Arrow Rotated direction arrows at segment midpoints
+// @property PrivacyStatement string Footer statement shown in strict privacy mode
+// @property Center gps.Point Initial map center (configured or calculated from the points)
+// @property Fallback Fallback Backup provider used if Google Maps fails to load
+// @property Leaflet Leaflet Tile source and assets when map.provider is leaflet
+// @property MapLibre MapLibre Style, camera, and assets when map.provider is maplibre
+// @property Cesium Cesium Imagery, terrain, and assets when map.provider is cesium
+// @property Encounters []proximity.Encounter Intervals when two users were close together
+// @property EncounterColor string Highlight color for encounter segments and markers
+// @property Meeting *proximity.Meeting Suggested meeting point for the users (nil when disabled)
+// @property Reference gps.Points Reference route drawn for comparison (empty when not comparing)
+// @property ReferenceColor string Line color for the reference route
+// @property Daily []aggregate.Period Per-day summaries (only for multi-day tracks)
+// @property Weekly []aggregate.Period Per-week summaries (only for multi-week tracks)
+// @property Navigation *Navigation Links to the index and neighbouring pages of a multi-page output
+// @property GeneratedAt time.Time Generation time shown in the footer (zero unless output.timestamp is set)
+// @property CompactPoints template.JS Compact points encoding of a minified page (empty otherwise)
+// @property PlaybackMillis int Duration of the playback animation (0 when animation is disabled)
+// @property MarkerIcons MarkerIcons Custom marker images replacing the built-in circles
+// @property SpeedScale *SpeedScale Per-segment path colors when coloring by speed
+// @property DayScale *DayScale Per-segment path colors and day legend when coloring by day
+// @property Profile *Profile Elevation profile chart (nil without elevation data)
+// @property MapStyles template.JS Google Maps style array from map.style_json (empty for the default style)
+// @property InfoWindows []template.HTML Info window content rendered from info_windows.template (nil for the built-in content)
+// @property MarkerLabels MarkerLabels Label text of each marker and the label styles
+// @property LayerToggles []LayerToggle Checkboxes of the layer control (nil when it is off)
+// @property Sidebar bool Whether the point list is shown beside the map
+// @property Downloads []Download Download buttons for the exported track files (nil for none)
+// @property LiveURL string Event stream the page appends newly ingested points from (empty for static pages)
+// @property UserColors []UserColor Path and marker color of each user (nil for single-user tracks)
+// @property Milestones []Milestone Distance markers along the path (nil when disabled)
+// @property Stops []StopMarker Detected stops drawn in place of their points' markers (nil when disabled)
+// @property StopColor string Marker color of the stops
+// @property Weather *WeatherSummary Weather during the track for the stats bar (nil when disabled)
+// @property PointWeather map[int]string Weather of the first point of each hour, by point index
+// @property Print PrintLayout Paper and map size of the print layout
+// @property Locale *i18n.Locale Page text, date, and number formats of the configured language
+// @property Messages map[string]string Translated text shown by the page scripts, by message key
+// @property QRCode *QRCode Header QR code linking to the hosted map (nil when disabled)
+// @property Colocated map[int]int Group of each marker stacked on others, by point index (nil unless spiderfying)
 type MapData struct {
-	Points     gps.Points     // @field Points GPS points to display on the map
-	APIKey     string         // @field APIKey Google Maps API key for map service authentication
-	Title      string         // @field Title Title to display at the top of the generated HTML page
-	OutputFile string         // @field OutputFile Target file path for the generated HTML output
-	Config     *config.Config // @field Config Complete configuration object for template access
+	Points           gps.Points            // @field Points GPS points to display on the map
+	APIKey           string                // @field APIKey Google Maps API key for map service authentication
+	Title            string                // @field Title Title to display at the top of the generated HTML page
+	OutputFile       string                // @field OutputFile Target file path for the generated HTML output
+	Config           *config.Config        // @field Config Complete configuration object for template access
+	Stats            *stats.Summary        // @field Stats Route statistics shown in the stats bar
+	Categories       []string              // @field Categories Distinct point categories for visibility toggles
+	Heatmap          []gps.WeightedPoint   // @field Heatmap Density cells when rendering in heatmap or combined mode
+	Trail            bool                  // @field Trail Whether markers and the path are drawn
+	Libraries        []string              // @field Libraries Google Maps libraries required by the page
+	Restriction      *Restriction          // @field Restriction Padded viewport bounds (nil when unrestricted)
+	Zoom             int                   // @field Zoom Initial zoom level for the map
+	Fences           []geofence.Fence      // @field Fences Configured geofences for boundary rendering
+	GeofenceEvents   []geofence.Event      // @field GeofenceEvents Chronological fence entry/exit events
+	ZIndex           map[string]int        // @field ZIndex Z-index for each map layer
+	Headings         []string              // @field Headings Compass direction of travel at each point
+	Arrows           []Arrow               // @field Arrows Direction arrows along the path
+	PrivacyStatement string                // @field PrivacyStatement Footer statement (empty unless strict privacy)
+	Center           gps.Point             // @field Center Initial map center
+	Fallback         *Fallback             // @field Fallback Backup map provider (nil when disabled)
+	Leaflet          *Leaflet              // @field Leaflet Tile source for the leaflet provider (nil for Google Maps)
+	MapLibre         *MapLibre             // @field MapLibre Vector style for the maplibre provider (nil otherwise)
+	Cesium           *Cesium               // @field Cesium 3D globe settings for the cesium provider (nil otherwise)
+	Encounters       []proximity.Encounter // @field Encounters Intervals when two users were close together
+	EncounterColor   string                // @field EncounterColor Highlight color for encounters
+	Meeting          *proximity.Meeting    // @field Meeting Suggested meeting point (nil when disabled)
+	Reference        gps.Points            // @field Reference Reference route drawn for comparison
+	ReferenceColor   string                // @field ReferenceColor Line color for the reference route
+	Daily            []aggregate.Period    // @field Daily Per-day summaries (only for multi-day tracks)
+	Weekly           []aggregate.Period    // @field Weekly Per-week summaries (only for multi-week tracks)
+	Navigation       *Navigation           // @field Navigation Page links of a multi-page output (nil for single maps)
+	GeneratedAt      time.Time             // @field GeneratedAt Generation time for the footer (zero keeps output reproducible)
+	CompactPoints    template.JS           // @field CompactPoints Points as compact rows for minified output
+	PlaybackMillis   int                   // @field PlaybackMillis Playback animation duration in milliseconds (0 to disable)
+	MarkerIcons      MarkerIcons           // @field MarkerIcons Custom marker images (nil icons keep the built-in circles)
+	SpeedScale       *SpeedScale           // @field SpeedScale Speed colors of the path segments (nil for a single color)
+	DayScale         *DayScale             // @field DayScale Day colors of the path segments (nil unless coloring by day)
+	Profile          *Profile              // @field Profile Elevation profile chart (nil without elevation data)
+	MapStyles        template.JS           // @field MapStyles Custom Google Maps styles (empty for the default style)
+	InfoWindows      []template.HTML       // @field InfoWindows Custom info window content of each point (nil for the built-in content)
+	MarkerLabels     MarkerLabels          // @field MarkerLabels Marker label text and styles from the label settings
+	LayerToggles     []LayerToggle         // @field LayerToggles Layer control checkboxes for the drawn layers and users
+	Sidebar          bool                  // @field Sidebar Show the chronological point list beside the map
+	Downloads        []Download            // @field Downloads Exported track files offered for download
+	LiveURL          string                // @field LiveURL Event stream of newly ingested points
+	UserColors       []UserColor           // @field UserColors Track color of each user of a multi-user dataset
+	Milestones       []Milestone           // @field Milestones Distance markers along the path
+	Stops            []StopMarker          // @field Stops Detected stops sized by dwell time
+	StopColor        string                // @field StopColor Marker color of the stops
+	Weather          *WeatherSummary       // @field Weather Weather during the track for the stats bar
+	PointWeather     map[int]string        // @field PointWeather Hourly weather annotations by point index
+	Print            PrintLayout           // @field Print Paper and map size of the print layout
+	Locale           *i18n.Locale          // @field Locale Page language with its messages and formats
+	Messages         map[string]string     // @field Messages Page script text by message key
+	QRCode           *QRCode               // @field QRCode Header QR code linking to the hosted map
+	Colocated        map[int]int           // @field Colocated Group of each stacked marker by point index
+}
+
+// Restriction holds the viewport limits emitted as Google Maps restriction options.
+//
+// @struct Restriction
+// @description Geographic bounds that viewers cannot pan beyond
+// @property North float64 Northern latitude limit
+// @property South float64 Southern latitude limit
+// @property East float64 Eastern longitude limit
+// @property West float64 Western longitude limit
+// @property StrictBounds bool Prevent zooming out past the bounds (disables world wrapping)
+type Restriction struct {
+	North        float64 // @field North Northern latitude limit
+	South        float64 // @field South Southern latitude limit
+	East         float64 // @field East Eastern longitude limit
+	West         float64 // @field West Western longitude limit
+	StrictBounds bool    // @field StrictBounds Keep the whole viewport inside the bounds
 }
 
 // Generate creates a complete HTML file containing an interactive Google Map visualization
@@ -69,82 +246,499 @@ type MapData struct {
 //
 // @method Generate
 // @description Creates interactive HTML map file from GPS tracking data
+// @param ctx context.Context Stops before the file is written when cancelled
 // @param points gps.Points Collection of GPS points to visualize
 // @param outputFile string Target file path for generated HTML
 // @return error Error if template processing or file creation fails
 // @output HTML file with Google Maps, markers, paths, and info windows
 // @browser Compatible with modern web browsers, requires internet connection
-// @example err := generator.Generate(gpsPoints, "map.html")
-func (g *Generator) Generate(points gps.Points, outputFile string) error {
+// @example err := generator.Generate(ctx, gpsPoints, "map.html")
+func (g *Generator) Generate(ctx context.Context, points gps.Points, outputFile string) error {
+	// Render into memory first so a failed generation leaves no partial file behind
+	var buf bytes.Buffer
+	if err := g.generate(&buf, points, outputFile); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Write the output HTML file
+	if err := writeFile(outputFile, buf.Bytes()); err != nil {
+		return fmt.Errorf("error creating output file: %w", err)
+	}
+
+	return nil
+}
+
+// writeFile writes data to a temporary file next to filename and renames it into
+// place, so an interrupted write never leaves a truncated page. The temporary
+// file is removed if anything fails.
+func writeFile(filename string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// GenerateTo renders the interactive map page for the provided GPS points into w,
+// for programs that serve or archive the page without touching the filesystem.
+// Nothing is written to w unless generation and the output audits succeed.
+//
+// @method GenerateTo
+// @description Renders the HTML map page into any io.Writer
+// @param w io.Writer Destination such as an http.ResponseWriter, buffer, or archive entry
+// @param points gps.Points Collection of GPS points to visualize
+// @return error Error if template processing, an output audit, or the write fails
+// @example err := generator.GenerateTo(responseWriter, gpsPoints)
+func (g *Generator) GenerateTo(w io.Writer, points gps.Points) error {
+	return g.generate(w, points, "")
+}
+
+// generate prepares the template data and renders the page into w. outputFile is
+// only recorded in the template data and may be empty.
+func (g *Generator) generate(w io.Writer, points gps.Points, outputFile string) error {
 	// Prepare all data needed for template execution
 	mapData := MapData{
-		Points:     points,                     // GPS tracking points to visualize
-		APIKey:     g.config.GoogleMaps.APIKey, // Authentication for Google Maps API
-		Title:      g.config.Map.Title,         // Page title from configuration
-		OutputFile: outputFile,                 // Target file path for HTML output
-		Config:     g.config,                   // Full config for template access
+		Points:     points,                                      // GPS tracking points to visualize
+		APIKey:     g.config.GoogleMaps.APIKey,                  // Authentication for Google Maps API
+		Title:      g.config.Map.Title,                          // Page title from configuration
+		OutputFile: outputFile,                                  // Target file path for HTML output
+		Config:     g.config,                                    // Full config for template access
+		Stats:      stats.Compute(points, &g.config.Statistics), // Route statistics for the stats bar
+		Categories: points.Categories(),                         // Categories discovered in the data
+		Libraries:  g.config.GoogleMaps.Libraries,               // Configured Google Maps libraries
+		Zoom:       g.initialZoom(points),                       // Configured or extent-based zoom level
+		Headings:   headings(points),                            // Direction of travel for info windows
+		Center:     g.initialCenter(points),                     // Configured or calculated map center
+	}
+
+	// Write the page text, dates, and numbers in the configured language
+	locale, ok := i18n.Lookup(g.config.Map.Language)
+	if !ok {
+		return configErrorf("unknown map language %q (use %s)", g.config.Map.Language, strings.Join(i18n.Languages(), ", "))
+	}
+	mapData.Locale = locale
+	mapData.Messages = scriptMessagesFor(locale)
+
+	// Compare against the reference route and report how far the track strayed from it
+	if len(g.reference) > 0 {
+		mapData.Reference = g.reference
+		mapData.ReferenceColor = g.config.Compare.Color
+		if mapData.ReferenceColor == "" {
+			mapData.ReferenceColor = DefaultReferenceColor
+		}
+		mapData.Stats.Deviation = stats.CompareTracks(points, g.reference, g.config.Compare.OffRouteThreshold)
+	}
+
+	// Summarize multi-day datasets per day and per week in the configured timezone
+	if g.config.Statistics.ShowDaily || g.config.Statistics.ShowWeekly {
+		loc, err := time.LoadLocation(g.config.Processing.Timezone)
+		if err != nil {
+			return configErrorf("invalid processing timezone: %w", err)
+		}
+		if days := aggregate.Daily(points, &g.config.Statistics, loc); g.config.Statistics.ShowDaily && len(days) > 1 {
+			mapData.Daily = days
+		}
+		if weeks := aggregate.Weekly(points, &g.config.Statistics, loc); g.config.Statistics.ShowWeekly && len(weeks) > 1 {
+			mapData.Weekly = weeks
+		}
+	}
+
+	// Place direction arrows using per-segment bearings so they follow curved paths
+	if g.config.Path.Animation.ShowDirectionArrows {
+		mapData.Arrows = directionArrows(points, maxDirectionArrows)
+	}
+
+	// Restrict panning to the padded track bounds if configured
+	if g.config.Map.RestrictBounds && !points.IsEmpty() {
+		mapData.Restriction = restrictionFor(points, g.config.Map.RestrictPadding)
+	}
+
+	// Resolve the settings of the configured map provider, or the backup provider
+	// used if Google Maps fails at runtime
+	var leaflet *Leaflet
+	switch g.config.Map.Provider {
+	case "", ProviderGoogle:
+		fallback, err := fallbackFor(&g.config.Map.Fallback)
+		if err != nil {
+			return err
+		}
+		if fallback != nil {
+			mapData.Fallback = fallback
+			leaflet = &fallback.Leaflet
+		}
+		if mapData.MapStyles, err = googleStylesFor(g.config.Map.StyleJSON); err != nil {
+			return err
+		}
+	case ProviderLeaflet:
+		mapData.Leaflet = leafletFor(g.config.Map.TileURL, g.config.Map.Attribution)
+		if mapData.Leaflet.TileURL != "" {
+			mapData.Leaflet.Offline = g.tiles
+		}
+		leaflet = mapData.Leaflet
+	case ProviderMapLibre:
+		mapLibre, err := mapLibreFor(&g.config.Map.MapLibre)
+		if err != nil {
+			return err
+		}
+		mapData.MapLibre = mapLibre
+	case ProviderCesium:
+		if reason := inlineReason(g.config); reason != "" {
+			return configErrorf("%s is not supported by the %s provider, which loads its workers and assets from cesium.com at runtime", reason, ProviderCesium)
+		}
+		mapData.Cesium = cesiumFor(&g.config.Map, points)
+	default:
+		return configErrorf("unknown map provider %q (use %s, %s, %s, or %s)", g.config.Map.Provider, ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium)
+	}
+
+	// Embed the library assets so self-contained and strict privacy pages need no CDN
+	if reason := inlineReason(g.config); reason != "" && leaflet != nil {
+		if err := inlineLeaflet(leaflet, reason, &g.config.Output); err != nil {
+			return err
+		}
+	}
+	if reason := inlineReason(g.config); reason != "" && mapData.MapLibre != nil {
+		if err := inlineMapLibre(mapData.MapLibre, reason, &g.config.Output); err != nil {
+			return err
+		}
+	}
+
+	// Use the configured marker images, embedding local ones in self-contained pages
+	markerIcons, err := markerIconsFor(g.config)
+	if err != nil {
+		return err
+	}
+	mapData.MarkerIcons = markerIcons
+
+	// Render the configured label text of each marker
+	if mapData.MarkerLabels, err = markerLabelsFor(&g.config.Markers, points, mapData.Headings, locale); err != nil {
+		return err
+	}
+
+	// Resolve the drawing order of map layers
+	zIndex, err := layerZIndices(g.config.Map.LayerOrder)
+	if err != nil {
+		return err
+	}
+	mapData.ZIndex = zIndex
+
+	// Detect entry/exit events for any configured geofences
+	fences, err := geofence.FromConfig(&g.config.Geofences)
+	if err != nil {
+		return configErrorf("invalid geofence configuration: %w", err)
+	}
+	mapData.Fences = fences
+	mapData.GeofenceEvents = geofence.Detect(points, fences)
+
+	// Highlight where users of a shared multi-user track were together
+	if g.config.Proximity.Enabled && len(points.Users()) > 1 {
+		mapData.Encounters = proximity.DetectPoints(points, &g.config.Proximity)
+		mapData.EncounterColor = g.config.Proximity.Color
+		if mapData.EncounterColor == "" {
+			mapData.EncounterColor = proximity.DefaultColor
+		}
 	}
 
-	// Generate the HTML file using the prepared data
-	return g.generateHTML(mapData)
+	// Suggest where the users should meet based on their positions at the configured time
+	if g.config.Proximity.MeetingPoint && len(points.Users()) > 1 {
+		if mapData.Meeting, err = proximity.SuggestMeetingPoints(points, &g.config.Proximity); err != nil {
+			return err
+		}
+	}
+
+	// Heatmap and combined modes aggregate points into density cells and need the
+	// visualization library; only heatmap mode leaves out the markers and path
+	mode := g.config.Map.RenderMode
+	if mode == RenderModeHeatmap || mode == RenderModeCombined {
+		mapData.Heatmap = points.Density(g.config.Heatmap.CellSize)
+		mapData.Libraries = withLibrary(mapData.Libraries, "visualization")
+	}
+	mapData.Trail = mode != RenderModeHeatmap || mapData.Heatmap == nil
+
+	// Color the path segments by speed or by day when requested
+	if mapData.Trail && g.config.Path.Enabled {
+		if mapData.SpeedScale, err = speedScaleFor(points, g.config); err != nil {
+			return err
+		}
+		if mapData.DayScale, err = dayScaleFor(points, g.config); err != nil {
+			return err
+		}
+	}
+
+	// Draw each user of a shared track in their own color
+	if mapData.Trail {
+		if mapData.UserColors, err = userColorsFor(points, g.config.Users.Colors); err != nil {
+			return err
+		}
+	}
+
+	// Mark every interval of distance along the path
+	if mapData.Trail && g.config.Path.Enabled && g.config.Path.Milestones.Enabled {
+		mapData.Milestones = milestonesFor(points, &g.config.Path.Milestones, &g.config.Statistics)
+		for i := range mapData.Milestones {
+			mapData.Milestones[i].Label = locale.Number(mapData.Milestones[i].Label)
+		}
+	}
+
+	// Find the stacked markers that spiderfying spreads apart on click
+	if mapData.Trail && g.config.Markers.Spiderfy {
+		mapData.Colocated = colocatedGroups(points)
+	}
+
+	// Mark the places where the track stayed put, in place of their waypoint markers
+	if mapData.Trail && g.config.Stops.Enabled {
+		mapData.Stops = stopMarkersFor(points, &g.config.Stops)
+		mapData.StopColor = g.config.Stops.Color
+		if mapData.StopColor == "" {
+			mapData.StopColor = stops.DefaultColor
+		}
+	}
+
+	// Annotate the stats bar and the hourly points with the weather during the track
+	mapData.Weather = weatherSummaryFor(g.weather, points)
+	mapData.PointWeather = hourlyWeather(g.weather, points)
+
+	// Size the printed map for the configured paper
+	mapData.Print = printLayoutFor(&g.config.Map.Print)
+
+	// Chart elevation against distance below the map when the track has elevation data
+	if !g.config.Output.Widget {
+		mapData.Profile = profileFor(points, &g.config.Statistics)
+	}
+
+	// Playback animates a marker along the track, so it needs markers and two points
+	if mapData.Trail && len(points) > 1 {
+		mapData.PlaybackMillis = playbackMillis(&g.config.Path.Animation)
+	}
+
+	// Offer checkboxes for the layers drawn on this page
+	mapData.LayerToggles = layerToggles(&mapData)
+
+	// List the points beside the map; widgets and pages without markers have no room or use for it
+	mapData.Sidebar = g.config.Map.Controls.Sidebar && mapData.Trail && len(points) > 0 && !g.config.Output.Widget
+
+	mapData.Navigation = g.navigation
+
+	// Offer the exported track files; widgets keep to the bare map
+	if !g.config.Output.Widget {
+		mapData.Downloads = g.downloads
+	}
+
+	// Live pages append pushed points as markers, so they need the trail
+	if mapData.Trail {
+		mapData.LiveURL = g.live
+	}
+
+	// Pages carry no generation time unless requested, so identical inputs give identical files
+	if g.config.Output.Timestamp {
+		generatedAt, err := GenerationTime()
+		if err != nil {
+			return err
+		}
+		mapData.GeneratedAt = generatedAt
+	}
+
+	// Render the configured info window template for each point
+	if g.config.InfoWindows.Enabled {
+		if mapData.InfoWindows, err = infoWindowContents(g.config.InfoWindows.Template, points, mapData.Headings, mapData.PointWeather, locale); err != nil {
+			return err
+		}
+	}
+
+	// Minified pages embed the points as compact rows instead of object literals
+	if g.config.Output.Minify {
+		mapData.CompactPoints = compactPoints(points, mapData.Headings)
+	}
+
+	// Link a printed page to the hosted interactive map
+	if mapData.QRCode, err = qrCodeFor(&g.config.Map.QRCode); err != nil {
+		return err
+	}
+
+	// Strict privacy mode adds a statement to the footer and audits the output
+	if privacyEnabled(g.config) {
+		mapData.PrivacyStatement = privacyStatement(g.config, mapHosts(mapData))
+	}
+
+	// Generate the HTML page using the prepared data
+	return g.generateHTML(mapData, w)
 }
 
-// generateHTML creates the HTML file with embedded Google Maps functionality.
+// generateHTML renders the HTML page with the embedded map provider.
 //
 // @method generateHTML
-// @description Processes HTML template and writes final map file
+// @description Processes HTML template and writes the audited page
 // @param data MapData Template context with GPS data and configuration
-// @return error Error if template processing or file writing fails
+// @param w io.Writer Destination for the rendered page
+// @return error Error if template processing, an audit, or writing fails
 // @internal true
-// @steps Parse template, Register functions, Create file, Execute template
-func (g *Generator) generateHTML(data MapData) error {
-	// Get the HTML template containing the complete page structure
-	tmpl := g.getHTMLTemplate()
-
+// @steps Parse template, Register functions, Execute template, Audit privacy, Write page
+func (g *Generator) generateHTML(data MapData, w io.Writer) error {
 	// Define custom template functions for use within the HTML template
-	// These functions provide additional formatting and utility capabilities
+	// These functions provide additional formatting and utility capabilities;
+	// numbers, dates, and text are written in the page language
+	locale := data.Locale
+	speed := func(kmh float64, units string) string { return locale.Number(stats.FormatSpeed(kmh, units)) }
+	distance := func(meters float64, units string) string { return locale.Number(stats.FormatDistance(meters, units)) }
+	loop := func(summary *stats.Summary) string { return locale.T(loopMessage(summary)) }
 	funcMap := template.FuncMap{
-		"add":   func(a, b int) int { return a + b },                                         // Mathematical addition for indexing
-		"sub":   func(a, b int) int { return a - b },                                         // Mathematical subtraction
-		"upper": func(s string) string { return strings.ToUpper(s) },                         // String case conversion
-		"join":  func(slice []string, sep string) string { return strings.Join(slice, sep) }, // Array joining for parameters
-	}
-
-	// Parse the template with custom functions registered
-	t, err := template.New("map").Funcs(funcMap).Parse(tmpl)
-	if err != nil {
-		return fmt.Errorf("error parsing template: %w", err)
+		"add":           func(a, b int) int { return a + b },                                         // Mathematical addition for indexing
+		"sub":           func(a, b int) int { return a - b },                                         // Mathematical subtraction
+		"upper":         func(s string) string { return strings.ToUpper(s) },                         // String case conversion
+		"join":          func(slice []string, sep string) string { return strings.Join(slice, sep) }, // Array joining for parameters
+		"duration":      stats.FormatDuration,                                                        // Compact duration formatting for statistics
+		"speed":         speed,                                                                       // Speed formatting in configured units
+		"distance":      distance,                                                                    // Distance formatting in configured units
+		"pace":          stats.FormatPace,                                                            // Pace formatting in configured units
+		"bearing":       stats.FormatBearing,                                                         // Bearing with compass label
+		"loop":          loop,                                                                        // Loop classification and direction
+		"percent":       func(fraction float64) float64 { return fraction * 100 },                    // Fraction to percentage
+		"categoryColor": categoryColor,                                                               // Configured marker color for a category
+		"elevation":     formatElevation,                                                             // Elevation in configured units
+		"t":             locale.T,                                                                    // Page text in the configured language
+		"date":          locale.Date,                                                                 // Date in the locale's date order
+		"datetime":      locale.DateTime,                                                             // Date and time to the minute in the locale's date order
+		"timestamp":     locale.Timestamp,                                                            // Date and time to the second in the locale's date order
 	}
 
-	// Create the output HTML file
-	file, err := os.Create(data.OutputFile)
+	// Parse the custom or built-in page template with custom functions registered
+	t, err := g.parseTemplate(funcMap)
 	if err != nil {
-		return fmt.Errorf("error creating output file: %w", err)
+		return err
 	}
-	defer file.Close()
 
 	// Execute the template with the map data, generating the final HTML content
-	err = t.Execute(file, data)
-	if err != nil {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
 		return fmt.Errorf("error executing template: %w", err)
 	}
 
+	// Refuse to write a strict privacy page that references third-party hosts
+	if data.PrivacyStatement != "" {
+		if err := auditExternalRequests(buf.Bytes(), allowedHosts(data)); err != nil {
+			return fmt.Errorf("privacy audit failed: %w", err)
+		}
+	}
+
+	// Refuse to write a self-contained page that still depends on other resources
+	if g.config.Output.SelfContained {
+		if err := auditExternalRequests(buf.Bytes(), selfContainedHosts(data)); err != nil {
+			return fmt.Errorf("self-contained output is not self-contained: %w", err)
+		}
+	}
+
+	// Write the audited page
+	if _, err := buf.WriteTo(w); err != nil {
+		return fmt.Errorf("error writing output: %w", err)
+	}
+
 	return nil
 }
 
+// DefaultRestrictPadding is the fraction of the track extent added on every side of
+// the restriction bounds when no padding is configured.
+const DefaultRestrictPadding = 0.5
+
+// minRestrictPadding is half the smallest extent in degrees, so single-point or very
+// compact tracks still leave room to pan around the surrounding area.
+const minRestrictPadding = 0.01
+
+// restrictionFor computes padded viewport bounds around the GPS points.
+// Latitudes are clamped to the Web Mercator range and longitudes to ±180 degrees.
+// Strict bounds (which stop the world from repeating horizontally) are only enabled
+// when the padded area is narrower than half the globe.
+func restrictionFor(points gps.Points, padding float64) *Restriction {
+	if padding <= 0 {
+		padding = DefaultRestrictPadding
+	}
+
+	minLat, maxLat, minLng, maxLng := points.BoundsPadded(padding)
+	minLat, maxLat = widen(minLat, maxLat, minRestrictPadding)
+	minLng, maxLng = widen(minLng, maxLng, minRestrictPadding)
+
+	r := &Restriction{
+		North: math.Min(maxLat, 85),
+		South: math.Max(minLat, -85),
+		East:  math.Min(maxLng, 180),
+		West:  math.Max(minLng, -180),
+	}
+	r.StrictBounds = r.East-r.West < 180
+
+	return r
+}
+
+// widen grows a range around its middle to at least twice the given half extent.
+func widen(from, to, half float64) (float64, float64) {
+	if to-from >= 2*half {
+		return from, to
+	}
+	middle := (from + to) / 2
+	return middle - half, middle + half
+}
+
+// initialCenter returns the configured map center, or calculates one from the points
+// using the configured center method (arithmetic mean by default).
+func (g *Generator) initialCenter(points gps.Points) gps.Point {
+	center := g.config.Map.InitialView.Center
+	if center.Latitude != nil && center.Longitude != nil {
+		return gps.Point{Latitude: *center.Latitude, Longitude: *center.Longitude}
+	}
+
+	lat, lng, _ := points.CenterBy(g.config.Map.CenterMethod)
+	return gps.Point{Latitude: lat, Longitude: lng}
+}
+
+// withLibrary returns the library list with the named Google Maps library appended
+// if it is not already present. The input slice is never modified.
+func withLibrary(libraries []string, name string) []string {
+	for _, lib := range libraries {
+		if lib == name {
+			return libraries
+		}
+	}
+	return append(append([]string{}, libraries...), name)
+}
+
+// categoryColor looks up the configured marker color for a category, falling back to
+// the "default" category entry and finally to the standard waypoint blue.
+func categoryColor(colors map[string]string, category string) string {
+	if color, ok := colors[category]; ok {
+		return color
+	}
+	if color, ok := colors["default"]; ok {
+		return color
+	}
+	return "#0000FF"
+}
+
 // getHTMLTemplate returns the complete HTML template for GPS track visualization.
 //
 // @method getHTMLTemplate
 // @description Returns complete HTML template for GPS map visualization
 // @return string Full HTML template with embedded CSS and JavaScript
 // @internal true
-// @components Responsive CSS, Statistics display, Google Maps, Legend, JavaScript
+// @components Responsive CSS, Statistics display, Legend, Map data, Provider script (see providerTemplate)
 // @features Dynamic content, Custom markers, Path drawing, Info windows
 // @template Integrated with Go template system for data binding
 func (g *Generator) getHTMLTemplate() string {
 	return `<!DOCTYPE html>
-<html>
+<html lang="{{.Locale.Code}}">
 <head>
+    {{block "head" .}}
     <title>{{.Title}}</title>
     <meta charset="utf-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
@@ -163,6 +757,35 @@ func (g *Generator) getHTMLTemplate() string {
             color: #333;
             margin: 0;
         }
+        {{with .QRCode}}
+        .header {
+            position: relative;
+            min-height: {{.Size}}px;
+        }
+        .qr-code {
+            position: absolute;
+            top: 0;
+            right: 0;
+            width: {{.Size}}px;
+            color: #666;
+            font-size: 10px;
+            text-align: center;
+            text-decoration: none;
+        }
+        .qr-code svg {
+            display: block;
+        }
+        @media (max-width: 600px) {
+            .qr-code {
+                display: none;
+            }
+        }
+        {{end}}
+        .page-nav a {
+            display: inline-block;
+            margin: 10px 10px 0;
+            color: #1a73e8;
+        }
         .stats {
             background: white;
             padding: 15px;
@@ -189,6 +812,31 @@ func (g *Generator) getHTMLTemplate() string {
             box-shadow: 0 2px 4px rgba(0,0,0,0.1);
             margin-top: 20px;
         }
+        .downloads {
+            background: white;
+            padding: 15px;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            margin-top: 20px;
+        }
+        .downloads a {
+            display: inline-block;
+            margin: 5px 10px 0 0;
+            padding: 6px 12px;
+            border: 1px solid #ccc;
+            border-radius: 4px;
+            color: #333;
+            text-decoration: none;
+        }
+        .downloads a:hover {
+            background: #f5f5f5;
+        }
+        .privacy-statement, .generated-at {
+            color: #666;
+            font-size: 12px;
+            text-align: center;
+            margin-top: 20px;
+        }
         .legend h3 {
             margin-top: 0;
             color: #333;
@@ -197,6 +845,9 @@ func (g *Generator) getHTMLTemplate() string {
             display: inline-block;
             margin: 5px 15px 5px 0;
         }
+        .legend-user {
+            cursor: pointer;
+        }
         .legend-color {
             display: inline-block;
             width: 20px;
@@ -205,223 +856,1384 @@ func (g *Generator) getHTMLTemplate() string {
             vertical-align: middle;
             border-radius: 50%;
         }
+        .splits, .periods, .geofence-events, .encounters, .elevation-profile {
+            background: white;
+            padding: 15px;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            margin-top: 20px;
+        }
+        .elevation-profile svg {
+            display: block;
+            width: 100%;
+            height: 150px;
+            cursor: crosshair;
+        }
+        .elevation-axis {
+            display: flex;
+            justify-content: space-between;
+            color: #666;
+            font-size: 12px;
+            margin-top: 5px;
+        }
+        .splits h3, .periods h3, .encounters h3, .elevation-profile h3 {
+            margin-top: 0;
+            color: #333;
+        }
+        .geofence-events summary {
+            cursor: pointer;
+            color: #333;
+            font-size: 1.17em;
+            font-weight: bold;
+        }
+        .geofence-events[open] summary {
+            margin-bottom: 10px;
+        }
+        .splits table, .periods table, .geofence-events table, .encounters table {
+            border-collapse: collapse;
+            width: 100%;
+        }
+        .splits th, .splits td, .periods th, .periods td, .geofence-events th, .geofence-events td, .encounters th, .encounters td {
+            padding: 6px 12px;
+            border-bottom: 1px solid #eee;
+            text-align: left;
+        }
+        .category-filters, .time-filter, .search-filter {
+            background: white;
+            padding: 15px;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            margin-bottom: 20px;
+        }
+        .category-filters label, .time-filter label {
+            display: inline-block;
+            margin: 5px 15px 5px 0;
+            cursor: pointer;
+        }
+        .time-filter input {
+            vertical-align: middle;
+            width: 200px;
+        }
+        .search-filter input {
+            width: 250px;
+            margin: 0 15px 0 10px;
+            padding: 4px 8px;
+        }
+        .search-filter span {
+            color: #666;
+        }
+        .playback, .measure-tool {
+            background: white;
+            padding: 15px;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            margin-bottom: 20px;
+        }
+        .playback button {
+            min-width: 90px;
+            margin-right: 15px;
+            cursor: pointer;
+        }
+        .measure-tool button {
+            margin-right: 10px;
+            cursor: pointer;
+        }
+        .measure-tool span {
+            margin-left: 5px;
+            color: #666;
+        }
+        .view-toggle, .layer-toggles {
+            background: white;
+            padding: 15px;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            margin-bottom: 20px;
+        }
+        .map-with-sidebar {
+            display: flex;
+            gap: 20px;
+        }
+        .map-with-sidebar #map {
+            flex: 1 1 0;
+            min-width: 0;
+        }
+        .point-list {
+            display: flex;
+            flex-direction: column;
+            width: 260px;
+            max-height: {{.Config.Map.Height}};
+            background: white;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+        }
+        .point-list-header {
+            display: flex;
+            justify-content: space-between;
+            align-items: center;
+            padding: 10px 15px;
+            border-bottom: 1px solid #eee;
+        }
+        .point-list-header button {
+            cursor: pointer;
+        }
+        .point-list ol {
+            flex: 1;
+            margin: 0;
+            padding: 0;
+            list-style: none;
+            overflow-y: auto;
+        }
+        .point-list li button {
+            display: block;
+            width: 100%;
+            padding: 8px 15px;
+            border: none;
+            border-bottom: 1px solid #eee;
+            background: none;
+            font: inherit;
+            text-align: left;
+            cursor: pointer;
+        }
+        .point-list li button:hover {
+            background: #f0f4ff;
+        }
+        .point-time {
+            display: block;
+            color: #666;
+            font-size: 12px;
+        }
+        .point-list.collapsed {
+            width: auto;
+        }
+        .point-list.collapsed ol, .point-list.collapsed .point-list-header strong {
+            display: none;
+        }
+        .view-toggle label, .layer-toggles label {
+            display: inline-block;
+            margin: 5px 15px 5px 0;
+            cursor: pointer;
+        }
+        .print-control {
+            background: white;
+            padding: 15px;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            margin-bottom: 20px;
+        }
+        .print-control button {
+            cursor: pointer;
+        }
+        @page {
+            size: {{.Print.PageSize}};
+            margin: 10mm;
+        }
+        @media print {
+            body {
+                -webkit-print-color-adjust: exact;
+                print-color-adjust: exact;
+            }
+        }
+        body.printing {
+            padding: 0;
+            background: white;
+        }
+        body.printing .category-filters, body.printing .time-filter, body.printing .search-filter,
+        body.printing .playback, body.printing .measure-tool, body.printing .view-toggle,
+        body.printing .layer-toggles, body.printing .print-control, body.printing .point-list,
+        body.printing .page-nav, body.printing .downloads,
+        body.printing .leaflet-control-zoom, body.printing .leaflet-control-layers,
+        body.printing .maplibregl-ctrl-top-left, body.printing .maplibregl-ctrl-top-right,
+        body.printing .gm-bundled-control, body.printing .gm-fullscreen-control, body.printing .gm-style-mtc, body.printing .gm-svpc,
+        body.printing .cesium-viewer-toolbar, body.printing .cesium-viewer-animationContainer,
+        body.printing .cesium-viewer-timelineContainer, body.printing .cesium-viewer-fullscreenContainer {
+            display: none;
+        }
+        body.printing .map-with-sidebar {
+            display: block;
+        }
+        body.printing #map {
+            width: {{.Print.MapWidth}};
+            height: {{.Print.MapHeight}};
+            border: 1px solid #ccc;
+            border-radius: 0;
+            box-shadow: none;
+            break-inside: avoid;
+        }
+        body.printing .stats, body.printing .legend, body.printing .splits, body.printing .periods,
+        body.printing .geofence-events, body.printing .encounters, body.printing .elevation-profile {
+            border: 1px solid #ddd;
+            box-shadow: none;
+            break-inside: avoid;
+        }
+    </style>
+    {{end}}
+    {{if .Config.Output.Widget}}
+    <style>
+        html, body {
+            height: 100%;
+            margin: 0;
+            padding: 0;
+        }
+        #map {
+            height: 100%;
+            width: 100%;
+            border-radius: 0;
+            box-shadow: none;
+        }
     </style>
+    {{end}}
+    {{template "map-head" .}}
 </head>
 <body>
+    {{if not .Config.Output.Widget}}
+    {{block "header" .}}
     <div class="header">
         <h1>{{.Title}}</h1>
+        {{with .QRCode}}
+        <a class="qr-code" href="{{.URL}}" title="{{t "scan_to_open"}}">{{.SVG}}{{t "scan_to_open"}}</a>
+        {{end}}
+        {{with .Navigation}}
+        <nav class="page-nav">
+            {{with .Previous}}<a href="{{.URL}}">&larr; {{.Label}}</a>{{end}}
+            <a href="{{.Index.URL}}">{{.Index.Label}}</a>
+            {{with .Next}}<a href="{{.URL}}">{{.Label}} &rarr;</a>{{end}}
+        </nav>
+        {{end}}
     </div>
+    {{end}}
 
+    {{block "stats" .}}
     {{if .Points}}
     <div class="stats">
-        <span><strong>Total Points:</strong> {{len .Points}}</span>
-        <span><strong>Start:</strong> {{(.Points.First).Timestamp.Format "2006-01-02 15:04"}}</span>
-        <span><strong>End:</strong> {{(.Points.Last).Timestamp.Format "2006-01-02 15:04"}}</span>
+        <span><strong>{{t "total_points"}}:</strong> {{len .Points}}</span>
+        <span><strong>{{t "start"}}:</strong> {{datetime (.Points.First).Timestamp}}</span>
+        <span><strong>{{t "end"}}:</strong> {{datetime (.Points.Last).Timestamp}}</span>
+        {{if .Config.Statistics.ShowDuration}}
+        <span><strong>{{t "moving_time"}}:</strong> {{duration .Stats.MovingTime}}</span>
+        <span><strong>{{t "stopped_time"}}:</strong> {{duration .Stats.StoppedTime}}</span>
+        {{end}}
+        {{if .Config.Statistics.ShowSpeed}}
+        <span><strong>{{t "moving_avg_speed"}}:</strong> {{speed .Stats.MovingAvgSpeed .Config.Statistics.DistanceUnits}}</span>
+        <span><strong>{{t "max_speed"}}:</strong> {{speed .Stats.MaxSpeed .Config.Statistics.DistanceUnits}}</span>
+        <span><strong>{{t "pace"}}:</strong> {{pace .Stats.Pace .Config.Statistics.DistanceUnits}}</span>
+        {{end}}
+        {{if .Stats.HasBearing}}
+        <span><strong>{{t "initial_heading"}}:</strong> {{bearing .Stats.InitialBearing}}</span>
+        <span><strong>{{t "average_heading"}}:</strong> {{bearing .Stats.AverageBearing}}</span>
+        {{end}}
+        {{if .Stats.Loop}}
+        <span><strong>{{t "route"}}:</strong> {{loop .Stats}}</span>
+        {{end}}
+        {{with .Weather}}
+        <span><strong>{{t "weather"}}:</strong> {{if eq .Low .High}}{{printf "%.0f" .Low}}{{else}}{{printf "%.0f" .Low}}&ndash;{{printf "%.0f" .High}}{{end}} {{.Unit}}, {{.Conditions}}</span>
+        {{end}}
+        {{with .Stats.Deviation}}
+        <span><strong>{{t "max_off_route"}}:</strong> {{printf "%.0f m" .MaxDistance}}</span>
+        <span><strong>{{t "avg_off_route"}}:</strong> {{printf "%.0f m" .AvgDistance}}</span>
+        <span><strong>{{t "off_route"}} (&gt;{{printf "%.0f m" .Threshold}}):</strong> {{printf "%.0f%%" (percent .OffRouteFraction)}}</span>
+        {{end}}
     </div>
     {{end}}
+    {{end}}
 
-    <div id="map"></div>
+    {{if .Categories}}
+    <div class="category-filters">
+        <strong>{{t "categories"}}:</strong>
+        {{range .Categories}}
+        <label>
+            <input type="checkbox" class="category-toggle" value="{{.}}" checked onchange="toggleCategory(this.value, this.checked)">
+            <span class="legend-color" style="background-color: {{categoryColor $.Config.Markers.Categories .}};"></span>
+            {{.}}
+        </label>
+        {{end}}
+    </div>
+    {{end}}
 
+    {{if and .Heatmap .Trail}}
+    <div class="view-toggle">
+        <strong>{{t "view"}}:</strong>
+        <label><input type="radio" name="view" value="trail" checked onchange="showView(this.value)"> {{t "markers_and_path"}}</label>
+        <label><input type="radio" name="view" value="markers" onchange="showView(this.value)"> {{t "markers"}}</label>
+        {{if .Config.Path.Enabled}}
+        <label><input type="radio" name="view" value="path" onchange="showView(this.value)"> {{t "path"}}</label>
+        {{end}}
+        <label><input type="radio" name="view" value="heatmap" onchange="showView(this.value)"> {{t "heatmap"}}</label>
+    </div>
+    {{end}}
+
+    {{if .LayerToggles}}
+    <div class="layer-toggles">
+        <strong>{{t "layers"}}:</strong>
+        {{range .LayerToggles}}
+        <label>
+            <input type="checkbox" class="layer-toggle" value="{{.Layer}}" {{if not (and (eq .Layer "heatmap") $.Trail)}}checked{{end}} onchange="setLayerVisible(this.value, this.checked)">
+            {{.Label}}
+        </label>
+        {{end}}
+    </div>
+    {{end}}
+
+    {{if and .Config.Map.Controls.Search .Points .Trail}}
+    <div class="search-filter">
+        <label for="point-search"><strong>{{t "search"}}:</strong></label>
+        <input type="search" id="point-search" placeholder="{{t "search_placeholder"}}" oninput="searchPoints(this.value)">
+        <span id="search-count"></span>
+    </div>
+    {{end}}
+
+    {{if and .Config.Map.Controls.TimeSlider .Points .Trail}}
+    <div class="time-filter">
+        <strong>{{t "time"}}:</strong>
+        <label>{{t "from"}} <input type="range" id="time-start" step="1" oninput="filterTime()"></label>
+        <label>{{t "to"}} <input type="range" id="time-end" step="1" oninput="filterTime()"></label>
+        <span id="time-window"></span>
+    </div>
+    {{end}}
+
+    {{if .PlaybackMillis}}
+    <div class="playback">
+        <button type="button" id="playback-toggle" onclick="togglePlayback()">&#9654; {{t "play"}}</button>
+        <span id="playback-time"></span>
+    </div>
+    {{end}}
+
+    {{if .Config.Map.Controls.MeasureTool}}
+    <div class="measure-tool">
+        <button type="button" id="measure-toggle" onclick="toggleMeasure()">{{t "measure_distance"}}</button>
+        <button type="button" onclick="clearMeasure()">{{t "clear"}}</button>
+        <span id="measure-distance"></span>
+    </div>
+    {{end}}
+
+    {{if .Config.Map.Controls.Print}}
+    <div class="print-control">
+        <button type="button" onclick="printMap()">{{t "print"}}</button>
+    </div>
+    {{end}}
+    {{end}}
+
+    {{if .Sidebar}}
+    <div class="map-with-sidebar">
+        <aside class="point-list" id="point-list">
+            <div class="point-list-header">
+                <strong>{{t "points"}}</strong>
+                <button type="button" id="point-list-toggle" onclick="toggleSidebar()" title="{{t "hide_point_list"}}">&laquo;</button>
+            </div>
+            <ol id="point-list-items"></ol>
+        </aside>
+        <div id="map"></div>
+    </div>
+    {{else}}
+    <div id="map"></div>
+    {{end}}
+
+    {{if not .Config.Output.Widget}}
+
+    {{with .Profile}}
+    <div class="elevation-profile">
+        <h3>{{t "elevation_profile"}}</h3>
+        <svg id="elevation-chart" viewBox="0 0 1000 200" preserveAspectRatio="none" onpointermove="hoverProfile(event)" onpointerleave="hoverProfile(null)">
+            <polygon points="{{.Area}}" fill="{{$.Config.Path.Style.Color}}" fill-opacity="0.2"></polygon>
+            <polyline points="{{.Line}}" fill="none" stroke="{{$.Config.Path.Style.Color}}" stroke-width="2" vector-effect="non-scaling-stroke"></polyline>
+            <line id="elevation-cursor" y1="0" y2="200" stroke="#333" stroke-width="1" vector-effect="non-scaling-stroke" visibility="hidden"></line>
+        </svg>
+        <div class="elevation-axis">
+            <span>{{elevation .MinElevation $.Config.Statistics.DistanceUnits}} &ndash; {{elevation .MaxElevation $.Config.Statistics.DistanceUnits}}</span>
+            <span id="elevation-readout"></span>
+            <span>{{distance .Distance $.Config.Statistics.DistanceUnits}}</span>
+        </div>
+    </div>
+    {{end}}
+
+    {{if and .Config.Statistics.ShowSplits .Stats.Splits}}
+    <div class="splits">
+        <h3>{{t "splits"}}</h3>
+        <table>
+            <tr><th>#</th><th>{{t "distance"}}</th><th>{{t "time"}}</th><th>{{t "pace"}}</th></tr>
+            {{range .Stats.Splits}}
+            <tr>
+                <td>{{.Number}}</td>
+                <td>{{distance .Distance $.Config.Statistics.DistanceUnits}}</td>
+                <td>{{duration .Duration}}</td>
+                <td>{{pace .Pace $.Config.Statistics.DistanceUnits}}</td>
+            </tr>
+            {{end}}
+        </table>
+    </div>
+    {{end}}
+
+    {{if .Daily}}
+    <div class="periods">
+        <h3>{{t "daily_summary"}}</h3>
+        <table>
+            <tr><th>{{t "day"}}</th><th>{{t "points"}}</th><th>{{t "distance"}}</th><th>{{t "duration"}}</th><th>{{t "moving_time"}}</th></tr>
+            {{range .Daily}}
+            <tr>
+                <td>{{.Label}}</td>
+                <td>{{.Summary.Points}}</td>
+                <td>{{distance .Summary.Distance $.Config.Statistics.DistanceUnits}}</td>
+                <td>{{duration .Summary.Duration}}</td>
+                <td>{{duration .Summary.MovingTime}}</td>
+            </tr>
+            {{end}}
+        </table>
+    </div>
+    {{end}}
+
+    {{if .Weekly}}
+    <div class="periods">
+        <h3>{{t "weekly_summary"}}</h3>
+        <table>
+            <tr><th>{{t "week"}}</th><th>{{t "points"}}</th><th>{{t "distance"}}</th><th>{{t "duration"}}</th><th>{{t "moving_time"}}</th></tr>
+            {{range .Weekly}}
+            <tr>
+                <td>{{.Label}}</td>
+                <td>{{.Summary.Points}}</td>
+                <td>{{distance .Summary.Distance $.Config.Statistics.DistanceUnits}}</td>
+                <td>{{duration .Summary.Duration}}</td>
+                <td>{{duration .Summary.MovingTime}}</td>
+            </tr>
+            {{end}}
+        </table>
+    </div>
+    {{end}}
+
+    {{if .GeofenceEvents}}
+    <details class="geofence-events">
+        <summary>{{t "geofence_events"}} ({{len .GeofenceEvents}})</summary>
+        <table>
+            <tr><th>{{t "time"}}</th><th>{{t "fence"}}</th><th>{{t "event"}}</th></tr>
+            {{range .GeofenceEvents}}
+            <tr>
+                <td>{{timestamp .Timestamp}}</td>
+                <td>{{.Fence}}</td>
+                <td>{{if eq .Type "enter"}}{{t "entered"}}{{else}}{{t "exited"}}{{end}}</td>
+            </tr>
+            {{end}}
+        </table>
+    </details>
+    {{end}}
+
+    {{if .Encounters}}
+    <div class="encounters">
+        <h3>{{t "encounters"}}</h3>
+        <table>
+            <tr><th>{{t "start"}}</th><th>{{t "end"}}</th><th>{{t "users"}}</th><th>{{t "closest_approach"}}</th></tr>
+            {{range .Encounters}}
+            <tr>
+                <td>{{timestamp .Start}}</td>
+                <td>{{timestamp .End}}</td>
+                <td>{{.UserA}} &amp; {{.UserB}}</td>
+                <td>{{printf "%.0f m" .MinDistance}}</td>
+            </tr>
+            {{end}}
+        </table>
+    </div>
+    {{end}}
+
+    {{block "legend" .}}
     <div class="legend">
-        <h3>Legend</h3>
+        <h3>{{t "legend"}}</h3>
         <div class="legend-item">
             <span class="legend-color" style="background-color: #00FF00;"></span>
-            Start Point
+            {{t "start_point"}}
         </div>
         <div class="legend-item">
             <span class="legend-color" style="background-color: #FF0000;"></span>
-            End Point
+            {{t "end_point"}}
         </div>
         <div class="legend-item">
             <span class="legend-color" style="background-color: #0000FF;"></span>
-            Waypoints
+            {{t "waypoints"}}
+        </div>
+        {{if .Milestones}}
+        <div class="legend-item">
+            <span class="legend-color" style="background-color: #FFFFFF; border: 1px solid #000; border-radius: 6px;"></span>
+            {{t "distance_milestones"}}
+        </div>
+        {{end}}
+        {{if .Stops}}
+        <div class="legend-item">
+            <span class="legend-color" style="background-color: {{.StopColor}};"></span>
+            {{t "stops_by_time"}}
+        </div>
+        {{end}}
+        {{with .SpeedScale}}
+        <div class="legend-item">
+            {{speed .Min $.Config.Statistics.DistanceUnits}}
+            <span style="display: inline-block; width: 120px; height: 6px; background: linear-gradient(to right{{range .Gradient}}, {{.}}{{end}}); margin: 0 8px; vertical-align: middle;"></span>
+            {{speed .Max $.Config.Statistics.DistanceUnits}}
+        </div>
+        {{else}}{{with .DayScale}}
+        {{range .Days}}
+        <div class="legend-item">
+            <span style="display: inline-block; width: 30px; border-top: 3px {{or $.Config.Path.Style.StrokePattern "solid"}} {{.Color}}; margin-right: 8px; vertical-align: middle;"></span>
+            {{date .Date}}
+        </div>
+        {{end}}
+        {{else}}
+        {{if not .UserColors}}
+        <div class="legend-item">
+            <span style="display: inline-block; width: 30px; border-top: 3px {{or .Config.Path.Style.StrokePattern "solid"}} {{.Config.Path.Style.Color}}; margin-right: 8px; vertical-align: middle;"></span>
+            {{t "walking_trail"}}
         </div>
+        {{end}}
+        {{end}}{{end}}
+        {{if .Reference}}
         <div class="legend-item">
-            <span style="display: inline-block; width: 30px; height: 3px; background-color: {{.Config.Path.Style.Color}}; margin-right: 8px; vertical-align: middle;"></span>
-            Walking Trail
+            <span style="display: inline-block; width: 30px; border-top: 3px {{or .Config.Compare.StrokePattern "solid"}} {{.ReferenceColor}}; margin-right: 8px; vertical-align: middle;"></span>
+            {{t "reference_route"}}
         </div>
+        {{end}}
+        {{range .UserColors}}
+        <label class="legend-item legend-user">
+            <input type="checkbox" class="layer-toggle" value="user:{{.Name}}" checked onchange="setLayerVisible(this.value, this.checked)">
+            <span class="legend-color" style="background-color: {{.Color}};"></span>
+            {{.Name}}
+        </label>
+        {{end}}
     </div>
+    {{end}}
+
+    {{if .Downloads}}
+    <div class="downloads">
+        <strong>{{t "download_track"}}:</strong>
+        {{range .Downloads}}
+        <a href="{{.URL}}" download="{{.File}}">{{.Label}}</a>
+        {{end}}
+    </div>
+    {{end}}
+    {{end}}
+
+    {{block "footer" .}}
+    {{if .PrivacyStatement}}
+    <footer class="privacy-statement">
+        <strong>{{t "privacy"}}:</strong> {{.PrivacyStatement}}
+    </footer>
+    {{end}}
+    {{if not .GeneratedAt.IsZero}}
+    <footer class="generated-at">{{t "generated"}} {{datetime .GeneratedAt}} {{.GeneratedAt.Format "MST"}}</footer>
+    {{end}}
+    {{end}}
 
+    {{block "scripts" .}}
     <script>
         let map;
 
+        // Page text and formats of the configured language
+        const messages = {{.Messages}};
+        const decimalSeparator = {{.Locale.Decimal}};
+        const datePattern = {{.Locale.DatePattern}};
+
+        // message returns the page text of a key with its {name} placeholders filled in
+        function message(key, values) {
+            return messages[key].replace(/\{(\w+)\}/g, (match, name) => values && name in values ? values[name] : match);
+        }
+
+        // formatNumber writes a number with a fixed number of decimals and the page's
+        // decimal separator
+        function formatNumber(value, decimals) {
+            return value.toFixed(decimals).replace('.', decimalSeparator);
+        }
+
+        // formatTimestamp writes the date of a "YYYY-MM-DD HH:MM:SS" timestamp in the
+        // page's date order
+        function formatTimestamp(timestamp) {
+            const date = datePattern.replace('{y}', timestamp.slice(0, 4)).replace('{m}', timestamp.slice(5, 7)).replace('{d}', timestamp.slice(8, 10));
+            return date + timestamp.slice(10);
+        }
+
+        {{if .CompactPoints}}
+        // Expands the compact [lat, lng, timestamp, title, description, category, heading, elevation, user] rows
+        function expandPoints(rows) {
+            return rows.map((row, i) => ({
+                lat: row[0],
+                lng: row[1],
+                timestamp: row[2],
+                title: row[3] || message('point') + ' ' + (i + 1),
+                description: row[4] || '',
+                category: row[5] || '',
+                heading: row[6] || '',
+                elevation: row[7] || 0,
+                user: row[8] || '',
+                index: i
+            }));
+        }
+
+        const points = expandPoints({{.CompactPoints}});
+        {{else}}
         const points = [
             {{range $i, $point := .Points}}
             {
                 lat: {{$point.Latitude}},
                 lng: {{$point.Longitude}},
                 timestamp: "{{$point.Timestamp.Format "2006-01-02 15:04:05"}}",
-                title: "{{if $point.Title}}{{$point.Title}}{{else}}Point {{add $i 1}}{{end}}",
+                title: "{{if $point.Title}}{{$point.Title}}{{else}}{{t "point"}} {{add $i 1}}{{end}}",
                 description: "{{$point.Description}}",
+                category: "{{$point.Category}}",
+                heading: "{{index $.Headings $i}}",
+                elevation: {{$point.Elevation}},
+                user: "{{$point.User}}",
                 index: {{$i}}
             },
             {{end}}
         ];
+        {{end}}
 
-        function initMap() {
-            if (points.length === 0) {
-                document.getElementById('map').innerHTML = '<div style="text-align: center; padding: 50px; color: #666;">No GPS points to display</div>';
-                return;
-            }
+        const categoryColors = {{.Config.Markers.Categories}} || {};
 
-            // Initialize map
-            const center = {{if and .Config.Map.InitialView.Center.Latitude .Config.Map.InitialView.Center.Longitude}}{lat: {{.Config.Map.InitialView.Center.Latitude}}, lng: {{.Config.Map.InitialView.Center.Longitude}}}{{else}}calculateCenter(points){{end}};
-            
-            map = new google.maps.Map(document.getElementById("map"), {
-                zoom: {{if .Config.Map.InitialView.Zoom}}{{.Config.Map.InitialView.Zoom}}{{else}}13{{end}},
-                center: center,
-                mapTypeId: google.maps.MapTypeId.ROADMAP,
-                zoomControl: {{.Config.Map.Controls.ZoomControl}},
-                streetViewControl: {{.Config.Map.Controls.StreetViewControl}},
-                fullscreenControl: {{.Config.Map.Controls.FullscreenControl}},
-                mapTypeControl: {{.Config.Map.Controls.MapTypeControl}},
-                scaleControl: {{.Config.Map.Controls.ScaleControl}}
-            });
+        // Weather of the first point recorded in each hour, by point index
+        const pointWeather = {{.PointWeather}} || {};
 
-            // Add markers
-            addMarkers();
-            
-            // Add walking path
-            {{if .Config.Path.Enabled}}
-            addWalkingPath();
-            {{end}}
-            
-            // Fit map to show all points
-            fitMapToBounds();
+        // Track color of each user of a multi-user dataset
+        const userColors = Object.fromEntries(({{.UserColors}} || []).map(user => [user.name, user.color]));
+
+        // pointColor is the marker color of a regular point: its category color, or
+        // else the color of its user
+        function pointColor(point) {
+            const categoryColor = point.category && (categoryColors[point.category] || categoryColors['default']);
+            return categoryColor || userColors[point.user] || '#0000FF';
         }
 
-        function calculateCenter(points) {
-            let lat = 0, lng = 0;
-            points.forEach(point => {
-                lat += point.lat;
-                lng += point.lng;
-            });
+        // Custom marker images for the start, end, and other points; null keeps the built-in circle
+        const markerIcons = {{.MarkerIcons}};
+
+        function customIcon(index) {
+            if (index === 0) {
+                return markerIcons.start;
+            }
+            return index === points.length - 1 ? markerIcons.end : markerIcons.default;
+        }
+
+        // Label text of each point, and the label styles of the start, end, and other markers
+        const markerLabels = {{.MarkerLabels}};
+
+        // markerLabel returns the label of a point drawn on a marker of the given size,
+        // with its CSS font and the width of a marker wide enough for the text
+        function markerLabel(index, size) {
+            const style = index === 0 ? markerLabels.start : (index === points.length - 1 ? markerLabels.end : markerLabels.default);
+            const text = markerLabels.text[index] || '';
+            const fontSize = style.size || size / 3;
             return {
-                lat: lat / points.length,
-                lng: lng / points.length
+                text: text,
+                color: style.color,
+                font: style.weight + ' ' + fontSize + 'px ' + style.family,
+                width: Math.max(size, Math.ceil(text.length * fontSize * 0.6) + size / 2)
             };
         }
 
-        function addMarkers() {
+        function escapeHTML(text) {
+            return String(text).replace(/[&<>"']/g, c => '&#' + c.charCodeAt(0) + ';');
+        }
+
+        // Track markers by point index; the category and time filters decide which are
+        // shown, and each map provider defines setMarkerVisible and setPathPoints
+        const pointMarkers = [];
+        const markerVisible = [];
+        const hiddenCategories = new Set();
+        let timeWindow = null;
+
+        // Lowercase words that every shown marker contains, from the search box
+        let searchTerms = [];
+
+        // While playing back, only points up to playbackIndex are shown, and the path
+        // ends at the interpolated playbackPosition
+        let playbackIndex = null, playbackPosition = null;
+
+        // Whole layers (markers, path, arrows, milestones, heatmap, geofences, and
+        // user:<name> for each user's points) hidden by the view and layer controls
+        const hiddenLayers = new Set({{if and .Heatmap .Trail}}['heatmap']{{end}});
+
+        function pointVisible(index) {
+            const point = points[index];
+            if (hiddenLayers.has('markers') || (point.category && hiddenCategories.has(point.category))) {
+                return false;
+            }
+            return !userHidden(point) && matchesSearch(index) && inTimeWindow(index) && (playbackIndex === null || index <= playbackIndex);
+        }
+
+        function userHidden(point) {
+            return point.user !== '' && hiddenLayers.has('user:' + point.user);
+        }
+
+        {{if .Config.Map.Controls.Search}}
+        // Searchable text of each point
+        const searchText = points.map(point => [point.title, point.description, point.category].join(' ').toLowerCase());
+
+        // searchPoints shows only the markers containing every word of the query and
+        // reports how many match
+        function searchPoints(query) {
+            searchTerms = query.toLowerCase().split(/\s+/).filter(term => term);
+            applyFilters();
+            const matches = points.filter((point, index) => matchesSearch(index)).length;
+            document.getElementById('search-count').textContent = searchTerms.length ? message('search_count', { matches: matches, total: points.length }) : '';
+        }
+        {{end}}
+
+        function matchesSearch(index) {
+            return searchTerms.length === 0 || searchTerms.every(term => searchText[index].includes(term));
+        }
+
+        function inTimeWindow(index) {
+            return !timeWindow || (pointTimes[index] >= timeWindow[0] && pointTimes[index] <= timeWindow[1]);
+        }
+
+        function pathPoints() {
+            if (hiddenLayers.has('path')) {
+                return [];
+            }
+            const shown = points.filter((point, index) => !userHidden(point) && inTimeWindow(index) && (playbackIndex === null || index <= playbackIndex));
+            if (playbackPosition && inTimeWindow(playbackIndex)) {
+                shown.push(playbackPosition);
+            }
+            return shown;
+        }
+
+        function applyFilters() {
+            // Points replaced by a stop marker have no marker of their own
             points.forEach((point, index) => {
-                let icon, title = point.title;
-
-                // Customize marker icons
-                if (index === 0) {
-                    icon = createMarkerIcon('#00FF00', 'S', 32);
-                    title = "START - " + title;
-                } else if (index === points.length - 1) {
-                    icon = createMarkerIcon('#FF0000', 'E', 32);
-                    title = "END - " + title;
+                const visible = pointVisible(index);
+                if (markerVisible[index] !== visible) {
+                    markerVisible[index] = visible;
+                    if (pointMarkers[index]) {
+                        setMarkerVisible(pointMarkers[index], visible);
+                    }
+                    {{if .Sidebar}}
+                    if (pointListItems[index]) {
+                        pointListItems[index].hidden = !visible;
+                    }
+                    {{end}}
+                }
+            });
+            {{if .Config.Path.Enabled}}
+            if (typeof setPathPoints === 'function') {
+                setPathPoints(pathPoints());
+            }
+            {{end}}
+            {{if .Milestones}}
+            milestoneMarkers.forEach((marker, i) => {
+                setMarkerVisible(marker, !hiddenLayers.has('milestones') && !userHidden(milestones[i]));
+            });
+            {{end}}
+            {{if .Config.Map.Controls.Permalink}}
+            updatePermalink();
+            {{end}}
+        }
+
+        {{if or .SpeedScale .DayScale .UserColors}}
+        {{with .SpeedScale}}
+        // Path colors by speed, one per segment from point i to point i + 1
+        const speedColors = {{.Colors}};
+        {{end}}
+        {{with .DayScale}}
+        // Path colors by day, one per segment from point i to point i + 1
+        const dayColors = {{.Colors}};
+        {{end}}
+
+        // pathRuns splits a path into runs of consecutive segments sharing a color, so
+        // each run can be drawn as one line. Each user's points are joined only to
+        // their own; a segment takes the speed or day color of its first point when
+        // coloring by speed or day, and otherwise (or for points pushed live, which
+        // have no precomputed color) the color of its user.
+        function pathRuns(pathPoints) {
+            const runs = [], userRuns = new Map(), userPoints = new Map();
+            pathPoints.forEach(point => {
+                const user = point.user || '';
+                const previous = userPoints.get(user);
+                userPoints.set(user, point);
+                if (!previous) {
+                    return;
+                }
+                const color = {{if .SpeedScale}}speedColors[previous.index] || {{else if .DayScale}}dayColors[previous.index] || {{end}}userColors[user] || "{{.Config.Path.Style.Color}}";
+                const last = userRuns.get(user);
+                if (last && last.color === color) {
+                    last.points.push(point);
                 } else {
-                    icon = createMarkerIcon('#0000FF', (index + 1).toString(), 24);
+                    const run = { color: color, points: [previous, point] };
+                    runs.push(run);
+                    userRuns.set(user, run);
                 }
+            });
+            return runs;
+        }
+        {{end}}
+
+        function registerMarker(index, marker) {
+            pointMarkers[index] = marker;
+            markerVisible[index] = true;
+        }
+
+        function toggleCategory(category, visible) {
+            if (visible) {
+                hiddenCategories.delete(category);
+            } else {
+                hiddenCategories.add(category);
+            }
+            applyFilters();
+        }
+
+        {{if .Sidebar}}
+        // The sidebar lists every point by time and title; entries of hidden markers are
+        // hidden with them
+        const pointListItems = points.map((point, index) => {
+            const item = document.createElement('li');
+            const button = document.createElement('button');
+            button.type = 'button';
+            const time = document.createElement('span');
+            time.className = 'point-time';
+            time.textContent = formatTimestamp(point.timestamp.slice(0, 16));
+            button.append(time, point.title);
+            button.onclick = () => focusPoint(index);
+            item.appendChild(button);
+            return item;
+        });
+        document.getElementById('point-list-items').append(...pointListItems);
+
+        // focusPoint pans and zooms to a point and opens its info window; each map
+        // provider defines focusMarker
+        function focusPoint(index) {
+            {{if .Stops}}
+            // Points of a stop focus the stop marker
+            if (replacingStop[index]) {
+                index = replacingStop[index].anchor;
+            }
+            {{end}}
+            if (pointMarkers[index]) {
+                focusMarker(pointMarkers[index], points[index]);
+            }
+        }
+
+        function toggleSidebar() {
+            const sidebar = document.getElementById('point-list');
+            const collapsed = sidebar.classList.toggle('collapsed');
+            const toggle = document.getElementById('point-list-toggle');
+            toggle.textContent = collapsed ? '\u00BB' : '\u00AB';
+            toggle.title = message(collapsed ? 'show_point_list' : 'hide_point_list');
+
+            // The map widens or narrows with the sidebar
+            window.dispatchEvent(new Event('resize'));
+        }
+        {{end}}
+
+        {{if .Config.Map.Controls.MeasureTool}}
+        // The measure tool adds the points clicked on the map while measuring and sums the
+        // great-circle distances between them; each map provider passes map clicks to
+        // addMeasurePoint and defines drawMeasureLine
+        let measuring = false;
+        const measurePoints = [];
+
+        function toggleMeasure() {
+            measuring = !measuring;
+            document.getElementById('measure-toggle').textContent = message(measuring ? 'stop_measuring' : 'measure_distance');
+            showMeasuredDistance();
+        }
+
+        function addMeasurePoint(lat, lng) {
+            if (!measuring) {
+                return;
+            }
+            measurePoints.push({ lat: lat, lng: lng });
+            drawMeasureLine(measurePoints);
+            showMeasuredDistance();
+        }
+
+        function clearMeasure() {
+            measurePoints.length = 0;
+            drawMeasureLine(measurePoints);
+            showMeasuredDistance();
+        }
+
+        // measureDistance returns the haversine distance in meters between two points
+        function measureDistance(from, to) {
+            const rad = Math.PI / 180;
+            const dLat = (to.lat - from.lat) * rad, dLng = (to.lng - from.lng) * rad;
+            const a = Math.sin(dLat / 2) ** 2 + Math.cos(from.lat * rad) * Math.cos(to.lat * rad) * Math.sin(dLng / 2) ** 2;
+            return 2 * 6371000 * Math.asin(Math.min(1, Math.sqrt(a)));
+        }
+
+        function showMeasuredDistance() {
+            const readout = document.getElementById('measure-distance');
+            if (measurePoints.length < 2) {
+                readout.textContent = measuring ? message('measure_hint') : '';
+                return;
+            }
+            let meters = 0;
+            for (let i = 1; i < measurePoints.length; i++) {
+                meters += measureDistance(measurePoints[i - 1], measurePoints[i]);
+            }
+            {{if eq .Config.Statistics.DistanceUnits "imperial"}}
+            readout.textContent = meters < 1609.344 ? Math.round(meters * 3.28084) + ' ft' : formatNumber(meters / 1609.344, 2) + ' mi';
+            {{else}}
+            readout.textContent = meters < 1000 ? Math.round(meters) + ' m' : formatNumber(meters / 1000, 2) + ' km';
+            {{end}}
+        }
+        {{end}}
+
+        // Point times in seconds; timestamps are compared as written, without a time zone
+        const pointTimes = points.map(point => Date.parse(point.timestamp.replace(' ', 'T') + 'Z') / 1000);
 
-                const marker = new google.maps.Marker({
-                    position: { lat: point.lat, lng: point.lng },
-                    map: map,
-                    title: title,
-                    icon: icon
-                });
-
-                // Info window
-                {{if .Config.InfoWindows.Enabled}}
-                const infoWindow = new google.maps.InfoWindow({
-                    content: createInfoWindowContent(point, title, index),
-                    maxWidth: {{.Config.InfoWindows.MaxWidth}}
-                });
-
-                marker.addListener("click", () => {
-                    infoWindow.open(map, marker);
-                });
+        {{if .LiveURL}}
+        // addLivePoints appends points the server ingested after the page was rendered,
+        // drawing their markers and extending the path
+        function addLivePoints(rows) {
+            rows.forEach(row => {
+                const index = points.length;
+                const point = {
+                    lat: row.lat,
+                    lng: row.lng,
+                    timestamp: row.timestamp,
+                    title: row.title || message('point') + ' ' + (index + 1),
+                    description: row.description || '',
+                    category: row.category || '',
+                    heading: '',
+                    elevation: row.elevation || 0,
+                    user: row.user || '',
+                    index: index
+                };
+                points.push(point);
+                pointTimes.push(Date.parse(point.timestamp.replace(' ', 'T') + 'Z') / 1000);
+                {{if .Config.Map.Controls.Search}}
+                searchText.push([point.title, point.description, point.category].join(' ').toLowerCase());
                 {{end}}
+                addMarker(point, index);
             });
+            applyFilters();
         }
 
-        function createMarkerIcon(color, text, size) {
-            return {
-                url: 'data:image/svg+xml;charset=UTF-8,' + encodeURIComponent(
-                    '<svg xmlns="http://www.w3.org/2000/svg" width="' + size + '" height="' + size + '" viewBox="0 0 ' + size + ' ' + size + '">' +
-                    '<circle cx="' + (size/2) + '" cy="' + (size/2) + '" r="' + (size/2-2) + '" fill="' + color + '" stroke="#000" stroke-width="2"/>' +
-                    '<text x="' + (size/2) + '" y="' + (size/2+4) + '" text-anchor="middle" fill="white" font-family="Arial" font-size="' + (size/3) + '" font-weight="bold">' + text + '</text>' +
-                    '</svg>'
-                ),
-                scaledSize: new google.maps.Size(size, size),
-                anchor: new google.maps.Point(size/2, size/2)
-            };
+        // listenForPoints subscribes to the server's stream of newly ingested points;
+        // the browser reconnects on its own when the stream drops
+        function listenForPoints() {
+            new EventSource({{.LiveURL}}).addEventListener('points', event => addLivePoints(JSON.parse(event.data)));
         }
+        {{end}}
 
-        function createInfoWindowContent(point, title, index) {
-            return ` + "`" + `
-                <div style="font-family: Arial, sans-serif; min-width: 200px;">
-                    <h3 style="margin: 0 0 10px 0; color: #333;">${title}</h3>
-                    <p><strong>Time:</strong> ${point.timestamp}</p>
-                    <p><strong>Location:</strong> ${point.lat.toFixed(6)}, ${point.lng.toFixed(6)}</p>
-                    <p><strong>Sequence:</strong> ${index + 1} of ${points.length}</p>
-                    ${point.description ? '<p><strong>Description:</strong> ' + point.description + '</p>' : ''}
-                </div>
-            ` + "`" + `;
+        // setLayerVisible shows or hides a whole layer and keeps its layer control
+        // checkbox in step; map providers drawing arrows, geofences, or a heatmap define
+        // setArrowsVisible, setGeofencesVisible, and setHeatmapVisible
+        function setLayerVisible(layer, visible) {
+            if (visible) {
+                hiddenLayers.delete(layer);
+            } else {
+                hiddenLayers.add(layer);
+            }
+            document.querySelectorAll('.layer-toggle').forEach(toggle => {
+                if (toggle.value === layer) {
+                    toggle.checked = visible;
+                }
+            });
+
+            const setVisible = {
+                arrows: typeof setArrowsVisible === 'function' ? setArrowsVisible : null,
+                geofences: typeof setGeofencesVisible === 'function' ? setGeofencesVisible : null,
+                heatmap: typeof setHeatmapVisible === 'function' ? setHeatmapVisible : null
+            }[layer];
+            if (setVisible) {
+                setVisible(visible);
+            }
+            applyFilters();
         }
 
-        function addWalkingPath() {
-            const pathCoordinates = points.map(point => ({ lat: point.lat, lng: point.lng }));
+        {{if and .Heatmap .Trail}}
+        // showView switches between the trail, its markers or path alone, and the heatmap
+        function showView(view) {
+            ['markers', 'path', 'heatmap'].forEach(layer => {
+                setLayerVisible(layer, view === 'trail' ? layer !== 'heatmap' : layer === view);
+            });
+        }
+        {{end}}
+
+        {{if and .Config.Map.Controls.TimeSlider .Points .Trail}}
+        function formatTime(seconds) {
+            return formatTimestamp(new Date(seconds * 1000).toISOString().slice(0, 16).replace('T', ' '));
+        }
+
+        // Limit markers and the path to the time window between the two slider handles
+        function filterTime() {
+            const first = Number(document.getElementById('time-start').value);
+            const second = Number(document.getElementById('time-end').value);
+            const start = Math.min(first, second), end = Math.max(first, second);
+            const full = start === pointTimes[0] && end === pointTimes[pointTimes.length - 1];
+            timeWindow = full ? null : [start, end];
+            document.getElementById('time-window').textContent = formatTime(start) + ' \u2013 ' + formatTime(end);
+            applyFilters();
+        }
+
+        ['time-start', 'time-end'].forEach((id, i) => {
+            const slider = document.getElementById(id);
+            slider.min = pointTimes[0];
+            slider.max = pointTimes[pointTimes.length - 1];
+            slider.value = i === 0 ? slider.min : slider.max;
+        });
+        document.getElementById('time-window').textContent = formatTime(pointTimes[0]) + ' \u2013 ' + formatTime(pointTimes[pointTimes.length - 1]);
+        {{end}}
+
+        {{if .Config.Map.Controls.Permalink}}
+        // The URL hash keeps the map view, the hidden layers and categories, and the
+        // time window, so a copied link reopens the page as the viewer left it:
+        // #map=zoom/lat/lng&hide=path,user:bob&hide_categories=work&time=start,end
+        // Each map provider defines getMapView and setMapView, calls updatePermalink
+        // when the view moves, and calls restorePermalink once the map is ready.
+        let permalinkTimer = null;
 
-            const walkingPath = new google.maps.Polyline({
-                path: pathCoordinates,
-                geodesic: true,
-                strokeColor: "{{.Config.Path.Style.Color}}",
-                strokeOpacity: {{.Config.Path.Style.Opacity}},
-                strokeWeight: {{.Config.Path.Style.Weight}},
+        // updatePermalink rewrites the hash once the map settles; playback and slider
+        // drags change the filters many times a second
+        function updatePermalink() {
+            clearTimeout(permalinkTimer);
+            permalinkTimer = setTimeout(() => {
+                if (!map) {
+                    return;
+                }
+                // Commas separate values; colons, as in user:<name>, are kept readable
+                const encodeValues = values => [...values].map(value => encodeURIComponent(value).replace(/%3A/g, ':')).join(',');
+                const view = getMapView();
+                const parts = ['map=' + [Math.round(view.zoom * 100) / 100, view.lat.toFixed(5), view.lng.toFixed(5)].join('/')];
+                if (hiddenLayers.size) {
+                    parts.push('hide=' + encodeValues(hiddenLayers));
+                }
+                if (hiddenCategories.size) {
+                    parts.push('hide_categories=' + encodeValues(hiddenCategories));
+                }
+                if (timeWindow) {
+                    parts.push('time=' + timeWindow.join(','));
+                }
+                history.replaceState(null, '', '#' + parts.join('&'));
+            }, 250);
+        }
+
+        // permalinkParams reads the hash into lists of values by key
+        function permalinkParams() {
+            const params = {};
+            location.hash.slice(1).split('&').forEach(part => {
+                const separator = part.indexOf('=');
+                if (separator > 0) {
+                    params[part.slice(0, separator)] = part.slice(separator + 1).split(',').filter(value => value).map(decodeURIComponent);
+                }
             });
+            return params;
+        }
 
-            walkingPath.setMap(map);
+        // restorePermalink applies the state saved in the hash and reports whether it
+        // set the view; hashes without a map view are not permalinks and are ignored
+        function restorePermalink() {
+            let params;
+            try {
+                params = permalinkParams();
+            } catch (error) {
+                return false;
+            }
+            const view = (params.map || [''])[0].split('/').map(Number);
+            if (view.length !== 3 || !view.every(Number.isFinite)) {
+                return false;
+            }
 
-            // Add direction arrows
-            {{if .Config.Path.Animation.ShowDirectionArrows}}
-            const arrowSymbol = {
-                path: google.maps.SymbolPath.FORWARD_CLOSED_ARROW,
-                scale: 3,
-                strokeColor: "{{.Config.Path.Style.Color}}",
-                fillColor: "{{.Config.Path.Style.Color}}",
-                fillOpacity: 1
-            };
+            const hidden = new Set(params.hide || []);
+            new Set([...hiddenLayers, ...hidden]).forEach(layer => {
+                if (hidden.has(layer) !== hiddenLayers.has(layer)) {
+                    setLayerVisible(layer, !hidden.has(layer));
+                }
+            });
 
-            const arrowPath = new google.maps.Polyline({
-                path: pathCoordinates,
-                geodesic: true,
-                strokeOpacity: 0,
-                icons: [{
-                    icon: arrowSymbol,
-                    offset: '100%',
-                    repeat: '100px'
-                }],
+            hiddenCategories.clear();
+            (params.hide_categories || []).forEach(category => hiddenCategories.add(category));
+            document.querySelectorAll('.category-toggle').forEach(toggle => {
+                toggle.checked = !hiddenCategories.has(toggle.value);
             });
 
-            arrowPath.setMap(map);
+            {{if and .Config.Map.Controls.TimeSlider .Points .Trail}}
+            const times = (params.time || []).map(Number);
+            const range = times.length === 2 && times.every(Number.isFinite) ? times : [pointTimes[0], pointTimes[pointTimes.length - 1]];
+            document.getElementById('time-start').value = range[0];
+            document.getElementById('time-end').value = range[1];
+            filterTime();
+            {{else}}
+            applyFilters();
             {{end}}
+
+            setMapView(view[1], view[2], view[0]);
+            return true;
         }
 
-        function fitMapToBounds() {
-            {{if .Config.Map.AutoFitBounds}}
-            const bounds = new google.maps.LatLngBounds();
-            points.forEach(point => {
-                bounds.extend({ lat: point.lat, lng: point.lng });
-            });
-            map.fitBounds(bounds);
-            
-            // Ensure minimum zoom level
-            google.maps.event.addListenerOnce(map, 'bounds_changed', function() {
-                if (map.getZoom() > 15) {
-                    map.setZoom(15);
+        window.addEventListener('hashchange', restorePermalink);
+        {{end}}
+
+        // Printing switches the page to its print layout (see the printing styles), and
+        // each map provider's setPrintLayout resizes the map to it and shows a light
+        // basemap; the browser's own print command gets the same layout
+        function setPrinting(printing) {
+            if (document.body.classList.contains('printing') === printing) {
+                return;
+            }
+            document.body.classList.toggle('printing', printing);
+            if (map && typeof setPrintLayout === 'function') {
+                setPrintLayout(printing);
+            }
+        }
+
+        window.addEventListener('beforeprint', () => setPrinting(true));
+        window.addEventListener('afterprint', () => setPrinting(false));
+
+        {{if .Config.Map.Controls.Print}}
+        // printMap lays the page out for printing first, giving the map a moment to
+        // draw at its printed size before the print dialog opens
+        function printMap() {
+            setPrinting(true);
+            setTimeout(() => window.print(), 1000);
+        }
+        {{end}}
+
+        {{if .PlaybackMillis}}
+        // Playback moves a marker along the whole track in the configured time, revealing
+        // the markers and drawing the path behind it; each map provider defines
+        // setTrackMarker to place (or, given null, remove) the moving marker
+        let playbackFrame = null, playbackStart = 0, playbackProgress = 0;
+
+        function togglePlayback() {
+            if (playbackFrame !== null) {
+                cancelAnimationFrame(playbackFrame);
+                playbackFrame = null;
+                setPlaybackButton(false);
+                return;
+            }
+            if (playbackIndex === null) {
+                playbackProgress = 0;
+            }
+            playbackStart = performance.now() - playbackProgress * {{.PlaybackMillis}};
+            setPlaybackButton(true);
+            playbackFrame = requestAnimationFrame(playbackStep);
+        }
+
+        function playbackStep(now) {
+            playbackProgress = Math.min((now - playbackStart) / {{.PlaybackMillis}}, 1);
+            if (playbackProgress === 1) {
+                stopPlayback();
+                return;
+            }
+
+            // Interpolate between the two points around the current progress
+            const position = playbackProgress * (points.length - 1);
+            const index = Math.floor(position), fraction = position - index;
+            const from = points[index], to = points[index + 1];
+            playbackIndex = index;
+            playbackPosition = {
+                user: from.user,
+                lat: from.lat + (to.lat - from.lat) * fraction,
+                lng: from.lng + (to.lng - from.lng) * fraction,
+                elevation: from.elevation + (to.elevation - from.elevation) * fraction
+            };
+            setTrackMarker(playbackPosition);
+            document.getElementById('playback-time').textContent = formatTimestamp(from.timestamp);
+            applyFilters();
+            playbackFrame = requestAnimationFrame(playbackStep);
+        }
+
+        // stopPlayback ends the animation and shows the whole track again
+        function stopPlayback() {
+            playbackFrame = null;
+            playbackIndex = null;
+            playbackPosition = null;
+            setTrackMarker(null);
+            setPlaybackButton(false);
+            document.getElementById('playback-time').textContent = '';
+            applyFilters();
+        }
+
+        function setPlaybackButton(playing) {
+            document.getElementById('playback-toggle').innerHTML = playing ? '&#10074;&#10074; ' + message('pause') : '&#9654; ' + message('play');
+        }
+        {{end}}
+
+        {{with .Profile}}
+        // Distance in meters at each point, for finding the point under the profile cursor
+        const profileDistances = {{.Distances}};
+
+        // hoverProfile marks the point nearest to the cursor on the elevation profile
+        // and on the map; given null, it clears the mark
+        function hoverProfile(event) {
+            const cursor = document.getElementById('elevation-cursor');
+            const readout = document.getElementById('elevation-readout');
+            if (!event) {
+                cursor.setAttribute('visibility', 'hidden');
+                readout.textContent = '';
+                setTrackMarker(playbackPosition);
+                return;
+            }
+
+            const chart = document.getElementById('elevation-chart').getBoundingClientRect();
+            const total = profileDistances[profileDistances.length - 1];
+            const target = (event.clientX - chart.left) / chart.width * total;
+            let low = 0, high = profileDistances.length - 1;
+            while (low < high) {
+                const mid = (low + high) >> 1;
+                if (profileDistances[mid] < target) {
+                    low = mid + 1;
+                } else {
+                    high = mid;
+                }
+            }
+            const index = low > 0 && target - profileDistances[low - 1] < profileDistances[low] - target ? low - 1 : low;
+
+            const x = profileDistances[index] / total * 1000;
+            cursor.setAttribute('x1', x);
+            cursor.setAttribute('x2', x);
+            cursor.setAttribute('visibility', 'visible');
+            {{if eq $.Config.Statistics.DistanceUnits "imperial"}}
+            readout.textContent = formatNumber(profileDistances[index] / 1609.344, 2) + ' mi, ' + Math.round(points[index].elevation * 3.28084) + ' ft';
+            {{else}}
+            readout.textContent = formatNumber(profileDistances[index] / 1000, 2) + ' km, ' + Math.round(points[index].elevation) + ' m';
+            {{end}}
+            setTrackMarker(points[index]);
+        }
+        {{end}}
+
+        {{if .Heatmap}}
+        const heatmapCells = [
+            {{range .Heatmap}}[{{.Latitude}}, {{.Longitude}}, {{.Weight}}],
+            {{end}}
+        ];
+        {{end}}
+
+        {{if .Milestones}}
+        // Distance milestones along each user's path
+        const milestones = [
+            {{range .Milestones}}
+            { lat: {{.Latitude}}, lng: {{.Longitude}}, elevation: {{.Elevation}}, label: "{{.Label}}", user: "{{.User}}" },
+            {{end}}
+        ];
+
+        // Milestone markers, shown unless their layer or their user is hidden
+        const milestoneMarkers = [];
+
+        // milestoneLabel styles the distance on a milestone marker, a white pill wide
+        // enough for the text
+        function milestoneLabel(text) {
+            return { text: text, color: '#333333', font: 'bold 11px Arial', width: Math.ceil(text.length * 11 * 0.6) + 12 };
+        }
+        {{end}}
+
+        {{if .Stops}}
+        // Stops where the track stayed put, with the indexes of their points
+        const stops = [
+            {{range .Stops}}
+            {
+                user: "{{.User}}",
+                arrival: "{{timestamp .Arrival}}",
+                departure: "{{timestamp .Departure}}",
+                duration: "{{duration .Duration}}",
+                label: "{{.Label}}",
+                size: {{.Size}},
+                lat: {{.Location.Latitude}},
+                lng: {{.Location.Longitude}},
+                elevation: {{.Location.Elevation}},
+                points: {{.Points}}
+            },
+            {{end}}
+        ];
+
+        // A stop marker stands in for the markers of the stop's points, except the
+        // start and end markers, and is shown and hidden with the first point it replaces
+        const replacingStop = {};
+        stops.forEach(stop => {
+            stop.points.filter(index => index > 0 && index < points.length - 1).forEach(index => {
+                replacingStop[index] = stop;
+                if (stop.anchor === undefined) {
+                    stop.anchor = index;
                 }
             });
+        });
+
+        // stopInfoContent lists the arrival, departure, and time spent at a stop
+        function stopInfoContent(stop) {
+            return '<div style="font-family: Arial, sans-serif; min-width: 200px;">' +
+                '<h3 style="margin: 0 0 10px 0; color: #333;">' + message('stop') + (stop.user ? ' - ' + escapeHTML(stop.user) : '') + '</h3>' +
+                '<p><strong>' + message('arrived') + ':</strong> ' + stop.arrival + '</p>' +
+                '<p><strong>' + message('departed') + ':</strong> ' + stop.departure + '</p>' +
+                '<p><strong>' + message('duration') + ':</strong> ' + stop.duration + '</p>' +
+                '<p><strong>' + message('points') + ':</strong> ' + stop.points.length + '</p></div>';
+        }
+        {{end}}
+
+        {{if and .Config.Geofences.ShowBoundaries .Fences}}
+        const geofences = [
+            {{range .Fences}}
+            {
+                name: "{{.Name}}",
+                {{if .IsCircle}}
+                center: { lat: {{.Center.Latitude}}, lng: {{.Center.Longitude}} },
+                radius: {{.Radius}},
+                {{else}}
+                polygon: [{{range .Polygon}}{ lat: {{.Latitude}}, lng: {{.Longitude}} },{{end}}],
+                {{end}}
+                label: { lat: {{.LabelPoint.Latitude}}, lng: {{.LabelPoint.Longitude}} },
+            },
+            {{end}}
+        ];
+        {{end}}
+
+        {{if .Reference}}
+        const referenceRoute = [
+            {{range .Reference}}{ lat: {{.Latitude}}, lng: {{.Longitude}} },
+            {{end}}
+        ];
+        {{end}}
+
+        {{if .Encounters}}
+        const encounters = [
+            {{range .Encounters}}
+            {
+                users: "{{.UserA}} & {{.UserB}}",
+                start: "{{timestamp .Start}}",
+                end: "{{timestamp .End}}",
+                distance: {{.MinDistance}},
+                closest: { lat: {{.Closest.Latitude}}, lng: {{.Closest.Longitude}} },
+                paths: [
+                    [{{range .PathA}}{ lat: {{.Latitude}}, lng: {{.Longitude}} },{{end}}],
+                    [{{range .PathB}}{ lat: {{.Latitude}}, lng: {{.Longitude}} },{{end}}]
+                ]
+            },
             {{end}}
+        ];
+        {{end}}
+
+        {{if .Meeting}}
+        const meeting = {
+            time: "{{timestamp .Meeting.Time}}",
+            point: { lat: {{.Meeting.Point.Latitude}}, lng: {{.Meeting.Point.Longitude}} },
+            users: [
+                {{range .Meeting.Positions}}
+                { name: "{{.User}}", position: { lat: {{.Latitude}}, lng: {{.Longitude}} }, distance: {{.DistanceTo $.Meeting.Point}} },
+                {{end}}
+            ]
+        };
+        {{end}}
+
+        {{if .InfoWindows}}
+        // Info window content rendered from info_windows.template, one entry per point
+        const infoWindowContents = {{.InfoWindows}};
+
+        function createInfoWindowContent(point, title, index) {
+            return infoWindowContents[index];
         }
+        {{else}}
+        function createInfoWindowContent(point, title, index) {
+            const sequence = message('sequence_of', { index: index + 1, total: points.length });
+            return ` + "`" + `
+                <div style="font-family: Arial, sans-serif; min-width: 200px;">
+                    <h3 style="margin: 0 0 10px 0; color: #333;">${title}</h3>
+                    <p><strong>${messages.time}:</strong> ${formatTimestamp(point.timestamp)}</p>
+                    <p><strong>${messages.location}:</strong> ${point.lat.toFixed(6)}, ${point.lng.toFixed(6)}</p>
+                    <p><strong>${messages.sequence}:</strong> ${sequence}</p>
+                    ${point.heading ? '<p><strong>' + messages.heading + ':</strong> ' + point.heading + '</p>' : ''}
+                    ${pointWeather[index] ? '<p><strong>' + messages.weather + ':</strong> ' + escapeHTML(pointWeather[index]) + '</p>' : ''}
+                    ${point.description ? '<p><strong>' + messages.description + ':</strong> ' + point.description + '</p>' : ''}
+                </div>
+            ` + "`" + `;
+        }
+        {{end}}
 
-        // Helper function for template
-        window.initMap = initMap;
+        {{template "map-script" .}}
     </script>
-    <script async defer src="https://maps.googleapis.com/maps/api/js?key={{.APIKey}}&callback=initMap{{if .Config.GoogleMaps.Libraries}}&libraries={{join .Config.GoogleMaps.Libraries ","}}{{end}}"></script>
+    {{end}}
+    {{template "map-loader" .}}
 </body>
-</html>`
+</html>` + g.providerTemplate()
 }
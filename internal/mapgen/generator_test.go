@@ -5,6 +5,10 @@
 package mapgen
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,6 +17,7 @@ import (
 
 	"github.com/saratily/geo-chrono/internal/config"
 	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/weather"
 )
 
 func TestNewGenerator(t *testing.T) {
@@ -136,7 +141,7 @@ func TestGeneratorGenerate(t *testing.T) {
 			outputFile := filepath.Join(tmpDir, "test_map.html")
 
 			gen := NewGenerator(tt.config)
-			err := gen.Generate(tt.points, outputFile)
+			err := gen.Generate(context.Background(), tt.points, outputFile)
 
 			if tt.wantErr {
 				if err == nil {
@@ -261,7 +266,7 @@ func TestErrorHandling(t *testing.T) {
 			}
 
 			gen := NewGenerator(tt.config)
-			_ = gen.Generate(tt.points, "/tmp/test.html")
+			_ = gen.Generate(context.Background(), tt.points, "/tmp/test.html")
 
 			// Note: The current implementation doesn't validate empty API keys
 			// This is acceptable behavior for this test
@@ -309,7 +314,7 @@ func TestMarkerAndPathGeneration(t *testing.T) {
 	outputFile := filepath.Join(tmpDir, "test_map.html")
 
 	gen := NewGenerator(config)
-	err := gen.Generate(points, outputFile)
+	err := gen.Generate(context.Background(), points, outputFile)
 
 	if err != nil {
 		t.Errorf("Generate() error = %v", err)
@@ -376,7 +381,7 @@ func TestOutputFileWriting(t *testing.T) {
 	}
 
 	gen := NewGenerator(config)
-	err := gen.Generate(points, outputFile)
+	err := gen.Generate(context.Background(), points, outputFile)
 
 	if err != nil {
 		t.Errorf("Generate() error = %v", err)
@@ -406,3 +411,1845 @@ func TestOutputFileWriting(t *testing.T) {
 		t.Error("Output file does not contain expected point data")
 	}
 }
+
+func TestGenerateTo(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.7749, Longitude: -122.4194, Title: "Test Point"},
+	}
+	cfg := &config.Config{GoogleMaps: config.GoogleMapsConfig{APIKey: "test-api-key"}}
+	gen := NewGenerator(cfg)
+
+	var buf bytes.Buffer
+	if err := gen.GenerateTo(&buf, points); err != nil {
+		t.Fatalf("GenerateTo() error = %v", err)
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "map.html")
+	if err := gen.Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	if !bytes.Equal(buf.Bytes(), content) {
+		t.Error("GenerateTo() output differs from the file written by Generate()")
+	}
+	if !strings.Contains(buf.String(), "Test Point") {
+		t.Error("GenerateTo() output does not contain expected point data")
+	}
+}
+
+func TestGenerateToWritesNothingOnError(t *testing.T) {
+	points := gps.Points{{Latitude: 37.7749, Longitude: -122.4194}}
+	cfg := &config.Config{Map: config.MapConfig{Provider: "unknown"}}
+
+	var buf bytes.Buffer
+	if err := NewGenerator(cfg).GenerateTo(&buf, points); err == nil {
+		t.Fatal("GenerateTo() expected an error for an unknown provider")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("GenerateTo() wrote %d bytes despite failing", buf.Len())
+	}
+}
+
+func TestGenerateCancelled(t *testing.T) {
+	points := gps.Points{{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.7749, Longitude: -122.4194}}
+	cfg := &config.Config{GoogleMaps: config.GoogleMapsConfig{APIKey: "test-api-key"}}
+	dir := t.TempDir()
+	outputFile := filepath.Join(dir, "map.html")
+	if err := os.WriteFile(outputFile, []byte("previous map"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewGenerator(cfg).Generate(ctx, points, outputFile); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Generate() after cancel error = %v, want context.Canceled", err)
+	}
+	if content, _ := os.ReadFile(outputFile); string(content) != "previous map" {
+		t.Errorf("Generate() after cancel changed the output file to %q", content)
+	}
+
+	// A completed write replaces the file and leaves no temporary file behind
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Generate() left %d files in the output directory, want only the map", len(entries))
+	}
+}
+
+func TestStatsBarMovingTime(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 0, Longitude: 0},
+		{Timestamp: testTime.Add(10 * time.Minute), Latitude: 0.01, Longitude: 0},
+		{Timestamp: testTime.Add(30 * time.Minute), Latitude: 0.01, Longitude: 0},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Stats Test"},
+		Statistics: config.StatisticsConfig{
+			Enabled:               true,
+			ShowDuration:          true,
+			ShowSpeed:             true,
+			ShowSplits:            true,
+			StoppedSpeedThreshold: 1,
+		},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "stats.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		"<strong>Moving Time:</strong> 10m 00s",
+		"<strong>Stopped Time:</strong> 20m 00s",
+		"<strong>Moving Avg Speed:</strong> 6.7 km/h",
+		"<strong>Max Speed:</strong> 6.7 km/h",
+		`<div class="splits">`,
+		"<td>1.00 km</td>",
+	}
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Generated HTML missing %q", want)
+		}
+	}
+}
+
+func TestCategoryToggles(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41, Category: "food"},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.78, Longitude: -122.40, Category: "fuel stop"},
+		{Timestamp: testTime.Add(2 * time.Hour), Latitude: 37.79, Longitude: -122.39, Category: "food"},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Category Test"},
+		Markers: config.MarkersConfig{
+			Categories: map[string]string{"food": "yellow", "default": "#0000FF"},
+		},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "categories.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		`class="category-filters"`,
+		`value="food"`,
+		`value="fuel stop"`,
+		"background-color: yellow;",
+		"background-color: #0000FF;",
+		"function toggleCategory(",
+		`category: "food"`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Generated HTML missing %q", want)
+		}
+	}
+}
+
+func TestTimeSlider(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: testTime.Add(24 * time.Hour), Latitude: 37.78, Longitude: -122.40},
+	}
+
+	tests := []struct {
+		name       string
+		provider   string
+		renderMode string
+		slider     bool
+		want       bool
+	}{
+		{name: "google", provider: ProviderGoogle, slider: true, want: true},
+		{name: "leaflet", provider: ProviderLeaflet, slider: true, want: true},
+		{name: "maplibre", provider: ProviderMapLibre, slider: true, want: true},
+		{name: "cesium", provider: ProviderCesium, slider: true, want: true},
+		{name: "disabled", provider: ProviderGoogle},
+		{name: "heatmap has no markers to filter", provider: ProviderGoogle, renderMode: RenderModeHeatmap, slider: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map: config.MapConfig{
+					Title:      "Slider Test",
+					Provider:   tt.provider,
+					RenderMode: tt.renderMode,
+					Controls:   config.ControlsConfig{TimeSlider: tt.slider},
+				},
+				Path: config.PathConfig{Enabled: true},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			if got := strings.Contains(html, `<div class="time-filter">`); got != tt.want {
+				t.Errorf("time slider shown = %v, want %v", got, tt.want)
+			}
+			if tt.want {
+				for _, want := range []string{"function filterTime()", "function setMarkerVisible(", "function setPathPoints(", "registerMarker(index, marker)"} {
+					if !strings.Contains(html, want) {
+						t.Errorf("Generated HTML missing %q", want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestPlayback(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.78, Longitude: -122.40},
+	}
+
+	tests := []struct {
+		name       string
+		provider   string
+		renderMode string
+		enabled    bool
+		points     gps.Points
+		want       bool
+	}{
+		{name: "google", provider: ProviderGoogle, enabled: true, points: points, want: true},
+		{name: "leaflet", provider: ProviderLeaflet, enabled: true, points: points, want: true},
+		{name: "maplibre", provider: ProviderMapLibre, enabled: true, points: points, want: true},
+		{name: "cesium", provider: ProviderCesium, enabled: true, points: points, want: true},
+		{name: "disabled", provider: ProviderGoogle, points: points},
+		{name: "heatmap has no track", provider: ProviderGoogle, renderMode: RenderModeHeatmap, enabled: true, points: points},
+		{name: "single point", provider: ProviderGoogle, enabled: true, points: points[:1]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Playback Test", Provider: tt.provider, RenderMode: tt.renderMode},
+				Path: config.PathConfig{
+					Enabled:   true,
+					Animation: config.AnimationConfig{Enabled: tt.enabled, Speed: 10},
+				},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, tt.points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			if got := strings.Contains(html, `id="playback-toggle"`); got != tt.want {
+				t.Errorf("playback control shown = %v, want %v", got, tt.want)
+			}
+			if tt.want {
+				for _, want := range []string{"function togglePlayback()", "function setTrackMarker(", " 12000"} {
+					if !strings.Contains(html, want) {
+						t.Errorf("Generated HTML missing %q", want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCategoryColor(t *testing.T) {
+	colors := map[string]string{"work": "blue", "default": "gray"}
+
+	if got := categoryColor(colors, "work"); got != "blue" {
+		t.Errorf("categoryColor(work) = %q, want blue", got)
+	}
+	if got := categoryColor(colors, "unknown"); got != "gray" {
+		t.Errorf("categoryColor(unknown) = %q, want gray", got)
+	}
+	if got := categoryColor(nil, "work"); got != "#0000FF" {
+		t.Errorf("categoryColor(nil) = %q, want #0000FF", got)
+	}
+}
+
+func TestSpiderfyGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.77, Longitude: -122.41},
+	}
+
+	tests := []struct {
+		name     string
+		spiderfy bool
+	}{
+		{name: "enabled", spiderfy: true},
+		{name: "disabled", spiderfy: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps:  config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:         config.MapConfig{Title: "Spiderfy Test"},
+				Markers:     config.MarkersConfig{Spiderfy: tt.spiderfy},
+				InfoWindows: config.InfoWindowsConfig{Enabled: true, MaxWidth: 300},
+			}
+
+			outputFile := filepath.Join(t.TempDir(), "spiderfy.html")
+			if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			content, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("Failed to read output file: %v", err)
+			}
+			html := string(content)
+
+			if got := strings.Contains(html, "function spiderfy(marker)"); got != tt.spiderfy {
+				t.Errorf("spiderfy function present = %v, want %v", got, tt.spiderfy)
+			}
+			if tt.spiderfy && !strings.Contains(html, `const colocatedGroupOf = {"0":0,"1":0} || {};`) {
+				t.Error("Generated HTML missing the stacked marker groups")
+			}
+			if !strings.Contains(html, "infoWindow.open(map, marker)") {
+				t.Error("Generated HTML missing info window click handler")
+			}
+		})
+	}
+}
+
+func TestHeatmapRenderMode(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.7749, Longitude: -122.4194},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.7749, Longitude: -122.4194},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key", Libraries: []string{"geometry"}},
+		Map:        config.MapConfig{Title: "Heatmap Test", RenderMode: RenderModeHeatmap},
+		Heatmap:    config.HeatmapConfig{CellSize: 50, Radius: 30},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "heatmap.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		"google.maps.visualization.HeatmapLayer",
+		"libraries=geometry%2cvisualization",
+		"radius:  30 ,",
+		",  2 ],",
+	}
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Generated HTML missing %q", want)
+		}
+	}
+
+	if len(cfg.GoogleMaps.Libraries) != 1 {
+		t.Errorf("Generate() modified configured libraries: %v", cfg.GoogleMaps.Libraries)
+	}
+}
+
+func TestCustomMarkerIcons(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.78, Longitude: -122.40},
+	}
+	url := "icons/pin.png"
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Icon Test", Provider: provider},
+				Markers: config.MarkersConfig{
+					Default: config.MarkerStyleConfig{Icon: config.IconConfig{URL: &url, Size: config.SizeConfig{Width: 20, Height: 30}}},
+				},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			want := `{"default":{"url":"icons/pin.png","width":20,"height":30,"anchorX":10,"anchorY":30},"start":null,"end":null}`
+			for _, s := range []string{want, "const custom = customIcon(index);"} {
+				if !strings.Contains(html, s) {
+					t.Errorf("Generated HTML missing %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestSpeedColoredPath(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.78, Longitude: -122.40},
+		{Timestamp: testTime.Add(70 * time.Minute), Latitude: 37.79, Longitude: -122.39},
+	}
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Speed Test", Provider: provider},
+				Path: config.PathConfig{
+					Enabled: true,
+					Style:   config.PathStyleConfig{Color: "#FF0000", Opacity: 0.8, Weight: 3, ColorBy: PathColorBySpeed},
+				},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{`const speedColors = ["#0000FF","#FF0000"];`, "pathRuns(pathPoints)", "linear-gradient(to right, #0000FF, #FF0000)", "km/h"} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+			if strings.Contains(html, "Walking Trail") {
+				t.Error("Generated HTML shows the single color trail legend")
+			}
+		})
+	}
+}
+
+func TestElevationProfile(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41, Elevation: 10},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.78, Longitude: -122.40, Elevation: 60},
+	}
+	flat := gps.Points{points[0], points[1]}
+	flat[0].Elevation, flat[1].Elevation = 0, 0
+
+	tests := []struct {
+		name     string
+		provider string
+		points   gps.Points
+		widget   bool
+		want     bool
+	}{
+		{name: "google", provider: ProviderGoogle, points: points, want: true},
+		{name: "leaflet", provider: ProviderLeaflet, points: points, want: true},
+		{name: "maplibre", provider: ProviderMapLibre, points: points, want: true},
+		{name: "cesium", provider: ProviderCesium, points: points, want: true},
+		{name: "no elevation", provider: ProviderGoogle, points: flat},
+		{name: "widget", provider: ProviderGoogle, points: points, widget: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Profile Test", Provider: tt.provider},
+				Output:     config.OutputConfig{Widget: tt.widget},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, tt.points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			if got := strings.Contains(html, `<div class="elevation-profile">`); got != tt.want {
+				t.Errorf("elevation profile shown = %v, want %v", got, tt.want)
+			}
+			if tt.want {
+				for _, want := range []string{"function hoverProfile(event)", "function setTrackMarker(", "10 m &ndash; 60 m"} {
+					if !strings.Contains(html, want) {
+						t.Errorf("Generated HTML missing %q", want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCombinedRenderMode(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.78, Longitude: -122.40},
+	}
+
+	tests := []struct {
+		name       string
+		provider   string
+		renderMode string
+		want       bool
+	}{
+		{name: "google", provider: ProviderGoogle, renderMode: RenderModeCombined, want: true},
+		{name: "leaflet", provider: ProviderLeaflet, renderMode: RenderModeCombined, want: true},
+		{name: "maplibre", provider: ProviderMapLibre, renderMode: RenderModeCombined, want: true},
+		{name: "cesium", provider: ProviderCesium, renderMode: RenderModeCombined, want: true},
+		{name: "trail", provider: ProviderGoogle, renderMode: RenderModeTrail},
+		{name: "heatmap", provider: ProviderGoogle, renderMode: RenderModeHeatmap},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Combined Test", Provider: tt.provider, RenderMode: tt.renderMode},
+				Path:       config.PathConfig{Enabled: true},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			if got := strings.Contains(html, `<div class="view-toggle">`); got != tt.want {
+				t.Errorf("view control shown = %v, want %v", got, tt.want)
+			}
+			if tt.want {
+				for _, want := range []string{"addHeatmap();", "addMarkers();", "function showView(", "function setHeatmapVisible(", `new Set(['heatmap'])`} {
+					if !strings.Contains(html, want) {
+						t.Errorf("Generated HTML missing %q", want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestWithLibrary(t *testing.T) {
+	if got := withLibrary([]string{"geometry"}, "geometry"); len(got) != 1 {
+		t.Errorf("withLibrary() duplicated existing library: %v", got)
+	}
+	if got := withLibrary(nil, "visualization"); len(got) != 1 || got[0] != "visualization" {
+		t.Errorf("withLibrary(nil) = %v, want [visualization]", got)
+	}
+}
+
+func TestRestrictionFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		points     gps.Points
+		padding    float64
+		wantNorth  float64
+		wantWest   float64
+		wantStrict bool
+	}{
+		{
+			name: "city track with default padding",
+			points: gps.Points{
+				{Latitude: 37.70, Longitude: -122.50},
+				{Latitude: 37.80, Longitude: -122.40},
+			},
+			wantNorth:  37.85,
+			wantWest:   -122.55,
+			wantStrict: true,
+		},
+		{
+			name:       "single point uses minimum padding",
+			points:     gps.Points{{Latitude: 10, Longitude: 20}},
+			padding:    1,
+			wantNorth:  10.01,
+			wantWest:   19.99,
+			wantStrict: true,
+		},
+		{
+			name: "world-spanning track is clamped and not strict",
+			points: gps.Points{
+				{Latitude: -60, Longitude: -150},
+				{Latitude: 70, Longitude: 150},
+			},
+			wantNorth:  85,
+			wantWest:   -180,
+			wantStrict: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := restrictionFor(tt.points, tt.padding)
+			if math.Abs(r.North-tt.wantNorth) > 1e-9 {
+				t.Errorf("restrictionFor().North = %v, want %v", r.North, tt.wantNorth)
+			}
+			if math.Abs(r.West-tt.wantWest) > 1e-9 {
+				t.Errorf("restrictionFor().West = %v, want %v", r.West, tt.wantWest)
+			}
+			if r.StrictBounds != tt.wantStrict {
+				t.Errorf("restrictionFor().StrictBounds = %v, want %v", r.StrictBounds, tt.wantStrict)
+			}
+		})
+	}
+}
+
+func TestRestrictBoundsOption(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Now(), Latitude: 37.70, Longitude: -122.50},
+		{Timestamp: time.Now(), Latitude: 37.80, Longitude: -122.40},
+	}
+
+	for _, restrict := range []bool{true, false} {
+		cfg := &config.Config{
+			GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+			Map:        config.MapConfig{Title: "Restrict Test", RestrictBounds: restrict},
+		}
+
+		outputFile := filepath.Join(t.TempDir(), "restrict.html")
+		if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+
+		content, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("Failed to read output file: %v", err)
+		}
+
+		if got := strings.Contains(string(content), "latLngBounds"); got != restrict {
+			t.Errorf("restrict_bounds=%v: restriction emitted = %v", restrict, got)
+		}
+	}
+}
+
+func TestGeofenceGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 0.005, Longitude: 0},
+		{Timestamp: testTime.Add(10 * time.Minute), Latitude: 0, Longitude: 0},
+		{Timestamp: testTime.Add(20 * time.Minute), Latitude: 0.005, Longitude: 0},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Geofence Test"},
+		Geofences: config.GeofencesConfig{
+			ShowBoundaries: true,
+			Fences: []config.FenceConfig{
+				{Name: "Home", Center: &config.CoordinateConfig{}, Radius: 200},
+			},
+		},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "geofence.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		`<details class="geofence-events">`,
+		"<summary>Geofence Events (2)</summary>",
+		"<td>2025-10-28 10:10:00</td>",
+		"<td>Entered</td>",
+		"<td>Exited</td>",
+		"new google.maps.Circle(",
+		`name: "Home"`,
+		"label: { text: fence.name",
+	}
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Generated HTML missing %q", want)
+		}
+	}
+
+	// Invalid fences surface as a generation error
+	cfg.Geofences.Fences = []config.FenceConfig{{Name: "Broken"}}
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err == nil {
+		t.Error("Generate() with invalid geofence error = nil, want error")
+	}
+}
+
+func TestBearingGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 0, Longitude: 0},
+		{Timestamp: testTime.Add(10 * time.Minute), Latitude: 0.01, Longitude: 0.01},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Bearing Test"},
+		Path: config.PathConfig{
+			Animation: config.AnimationConfig{ShowDirectionArrows: true},
+		},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "bearing.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		"<strong>Initial Heading:</strong> 45° NE",
+		`heading: "NE"`,
+		"messages.heading + ':</strong> '",
+		"rotation: arrow.rotation",
+	}
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Generated HTML missing %q", want)
+		}
+	}
+}
+
+func TestInitialCenter(t *testing.T) {
+	points := gps.Points{
+		{Latitude: 0, Longitude: 179},
+		{Latitude: 0, Longitude: -179},
+	}
+	lat, lng := 12.5, 45.25
+
+	tests := []struct {
+		name    string
+		mapCfg  config.MapConfig
+		wantLng float64
+	}{
+		{"mean by default", config.MapConfig{}, 0},
+		{"spherical across the antimeridian", config.MapConfig{CenterMethod: gps.CenterSpherical}, 180},
+		{
+			name: "configured center wins",
+			mapCfg: config.MapConfig{
+				CenterMethod: gps.CenterSpherical,
+				InitialView:  config.InitialViewConfig{Center: config.CenterConfig{Latitude: &lat, Longitude: &lng}},
+			},
+			wantLng: 45.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewGenerator(&config.Config{Map: tt.mapCfg}).initialCenter(points)
+			if math.Abs(math.Abs(got.Longitude)-tt.wantLng) > 1e-9 {
+				t.Errorf("initialCenter() lng = %v, want %v", got.Longitude, tt.wantLng)
+			}
+		})
+	}
+}
+
+func TestEncounterGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.7749, Longitude: -122.4194, User: "alice"},
+		{Timestamp: testTime, Latitude: 37.7750, Longitude: -122.4194, User: "bob"},
+		{Timestamp: testTime.Add(time.Minute), Latitude: 37.7759, Longitude: -122.4194, User: "alice"},
+		{Timestamp: testTime.Add(time.Minute), Latitude: 37.7760, Longitude: -122.4194, User: "bob"},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Encounter Test"},
+		Proximity:  config.ProximityConfig{Enabled: true, Radius: 25},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "encounters.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		`<div class="encounters">`,
+		"<td>alice &amp; bob</td>",
+		"<td>11 m</td>",
+		"addEncounters();",
+		`strokeColor: "#FF9800"`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Generated HTML missing %q", want)
+		}
+	}
+
+	// Disabled detection leaves the page unchanged
+	cfg.Proximity.Enabled = false
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err = os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if strings.Contains(string(content), `<div class="encounters">`) {
+		t.Error("Generated HTML contains encounters with proximity disabled")
+	}
+}
+
+func TestMeetingPointGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.42, User: "alice"},
+		{Timestamp: testTime, Latitude: 37.79, Longitude: -122.40, User: "bob"},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Meeting Test"},
+		Proximity:  config.ProximityConfig{MeetingPoint: true},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "meeting.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		"addMeetingPoint();",
+		`time: "2025-10-28 10:00:00"`,
+		`{ name: "alice"`,
+		`{ name: "bob"`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Generated HTML missing %q", want)
+		}
+	}
+
+	cfg.Proximity.MeetingTime = "tomorrow"
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err == nil {
+		t.Error("Generate() with invalid meeting time error = nil, want error")
+	}
+}
+
+func TestReferenceRouteGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 0, Longitude: 0},
+		{Timestamp: testTime.Add(time.Minute), Latitude: 0.001, Longitude: 0.005},
+		{Timestamp: testTime.Add(2 * time.Minute), Latitude: 0, Longitude: 0.01},
+	}
+	planned := gps.Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 0.01},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Compare Test"},
+		Compare:    config.CompareConfig{Color: "#123456"},
+	}
+
+	generator := NewGenerator(cfg)
+	generator.SetReference(planned)
+	outputFile := filepath.Join(t.TempDir(), "compare.html")
+	if err := generator.Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		"<strong>Max Off-Route:</strong> 111 m",
+		"<strong>Avg Off-Route:</strong> 37 m",
+		"<strong>Off Route (&gt;50 m):</strong> 33%",
+		"Reference Route",
+		"addReferenceRoute();",
+		`strokeColor: "#123456"`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Generated HTML missing %q", want)
+		}
+	}
+
+	// Without a reference route nothing comparison-related is rendered
+	generator.SetReference(nil)
+	if err := generator.Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err = os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if strings.Contains(string(content), "Max Off-Route") {
+		t.Error("Generated HTML contains deviation statistics without a reference route")
+	}
+}
+
+func TestLoopGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 0, Longitude: 0},
+		{Timestamp: testTime.Add(5 * time.Minute), Latitude: 0, Longitude: 0.01},
+		{Timestamp: testTime.Add(10 * time.Minute), Latitude: 0.01, Longitude: 0.005},
+		{Timestamp: testTime.Add(15 * time.Minute), Latitude: 0, Longitude: 0},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Loop Test"},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "loop.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if !strings.Contains(string(content), "<strong>Route:</strong> Loop (counterclockwise)") {
+		t.Error("Generated HTML missing loop classification")
+	}
+}
+
+func TestPeriodSummaryGeneration(t *testing.T) {
+	day := time.Date(2025, 11, 1, 9, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: day, Latitude: 0, Longitude: 0},
+		{Timestamp: day.Add(time.Hour), Latitude: 0.01, Longitude: 0},
+		{Timestamp: day.AddDate(0, 0, 2), Latitude: 0, Longitude: 0},
+		{Timestamp: day.AddDate(0, 0, 2).Add(time.Hour), Latitude: 0.01, Longitude: 0},
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Period Test"},
+		Statistics: config.StatisticsConfig{ShowDaily: true, ShowWeekly: true},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "periods.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	html := string(content)
+
+	expected := []string{
+		"<h3>Daily Summary</h3>",
+		"<td>2025-11-01</td>",
+		"<td>2025-11-03</td>",
+		"<h3>Weekly Summary</h3>",
+		"<td>2025-W45</td>",
+	}
+	for _, want := range expected {
+		if !strings.Contains(html, want) {
+			t.Errorf("Generated HTML missing %q", want)
+		}
+	}
+
+	// A single-day track has nothing to break down
+	if err := NewGenerator(cfg).Generate(context.Background(), points[:2], outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err = os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if strings.Contains(string(content), "Daily Summary") {
+		t.Error("Generated HTML contains a daily summary for a single-day track")
+	}
+}
+
+func TestCustomMapStyles(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.77, Longitude: -122.41},
+	}
+
+	tests := []struct {
+		name      string
+		provider  string
+		styleJSON string
+		want      bool
+		wantErr   bool
+	}{
+		{name: "default style", provider: ProviderGoogle},
+		{name: "dark preset", provider: ProviderGoogle, styleJSON: "dark", want: true},
+		{name: "inline style", provider: ProviderGoogle, styleJSON: `[{"elementType": "geometry", "stylers": [{"color": "#242f3e"}]}]`, want: true},
+		{name: "invalid style", provider: ProviderGoogle, styleJSON: "[", wantErr: true},
+		{name: "ignored by leaflet", provider: ProviderLeaflet, styleJSON: "dark"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Style Test", Provider: tt.provider, StyleJSON: tt.styleJSON},
+			}
+
+			var buf bytes.Buffer
+			err := NewGenerator(cfg).GenerateTo(&buf, points)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateTo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			html := buf.String()
+
+			if got := strings.Contains(html, `styles: [{"elementType":"geometry","stylers":[{"color":"#242f3e"}]}`); got != tt.want {
+				t.Errorf("custom styles emitted = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInfoWindowTemplate(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.77, Longitude: -122.41, Title: "Ferry Building"},
+	}
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps:  config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:         config.MapConfig{Title: "Info Window Test", Provider: provider},
+				InfoWindows: config.InfoWindowsConfig{Enabled: true, Template: `<em>{{.Title}}</em> #{{.Number}}`},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{`const infoWindowContents = ["\u003cem\u003eFerry Building\u003c/em\u003e #1"]`, "return infoWindowContents[index];"} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+
+	cfg := &config.Config{
+		GoogleMaps:  config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:         config.MapConfig{Title: "Info Window Test"},
+		InfoWindows: config.InfoWindowsConfig{Enabled: true, Template: "{{if .Title}}"},
+	}
+	if err := NewGenerator(cfg).GenerateTo(&bytes.Buffer{}, points); err == nil {
+		t.Error("GenerateTo() with an invalid info window template should fail")
+	}
+}
+
+func TestMarkerLabels(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 9, 5, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.78, Longitude: -122.40},
+		{Timestamp: testTime.Add(2 * time.Hour), Latitude: 37.79, Longitude: -122.39},
+	}
+	clock := `{{.Timestamp.Format "15:04"}}`
+	start := "START"
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Label Test", Provider: provider},
+				Markers: config.MarkersConfig{
+					Default: config.MarkerStyleConfig{Label: config.LabelConfig{Text: &clock, Color: "yellow", Font: config.FontConfig{Size: "11px"}}},
+					Start:   config.MarkerStyleConfig{Label: config.LabelConfig{Text: &start}},
+				},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{
+				`"text":["START","10:05","E"]`,
+				`"default":{"color":"yellow","family":"Arial","size":11,"weight":"bold"}`,
+				"const label = markerLabel(index, ",
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestLayerTogglesControl(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41, User: "alice"},
+		{Timestamp: testTime.Add(time.Minute), Latitude: 37.78, Longitude: -122.40, User: "bob"},
+	}
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map: config.MapConfig{Title: "Layer Test", Provider: provider, RenderMode: RenderModeCombined,
+					Controls: config.ControlsConfig{LayerToggles: true}},
+				Path: config.PathConfig{Enabled: true},
+				Geofences: config.GeofencesConfig{ShowBoundaries: true, Fences: []config.FenceConfig{
+					{Name: "Home", Center: &config.CoordinateConfig{Latitude: 37.77, Longitude: -122.41}, Radius: 100},
+				}},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{
+				`<div class="layer-toggles">`,
+				`value="path" checked`,
+				`value="heatmap" onchange`,
+				`value="user:bob" checked`,
+				"function setLayerVisible(layer, visible)",
+				"function setGeofencesVisible(visible)",
+				`user: "alice"`,
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestSidebar(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.77, Longitude: -122.41, Title: "Ferry Building"},
+		{Timestamp: time.Date(2025, 10, 28, 11, 0, 0, 0, time.UTC), Latitude: 37.78, Longitude: -122.40},
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		mode     string
+		widget   bool
+		want     bool
+	}{
+		{name: "google", provider: ProviderGoogle, want: true},
+		{name: "leaflet", provider: ProviderLeaflet, want: true},
+		{name: "maplibre", provider: ProviderMapLibre, want: true},
+		{name: "cesium", provider: ProviderCesium, want: true},
+		{name: "heatmap mode", provider: ProviderGoogle, mode: RenderModeHeatmap},
+		{name: "widget", provider: ProviderGoogle, widget: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps:  config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:         config.MapConfig{Title: "Sidebar Test", Provider: tt.provider, RenderMode: tt.mode, Controls: config.ControlsConfig{Sidebar: true}},
+				InfoWindows: config.InfoWindowsConfig{Enabled: true},
+				Output:      config.OutputConfig{Widget: tt.widget},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			if got := strings.Contains(html, `<aside class="point-list" id="point-list">`); got != tt.want {
+				t.Errorf("sidebar shown = %v, want %v", got, tt.want)
+			}
+			if tt.want {
+				for _, want := range []string{"function focusPoint(index)", "function focusMarker(marker, point)", "function toggleSidebar()"} {
+					if !strings.Contains(html, want) {
+						t.Errorf("Generated HTML missing %q", want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSearchBox(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.77, Longitude: -122.41, Title: "Ferry Building"},
+	}
+
+	for _, search := range []bool{true, false} {
+		cfg := &config.Config{
+			GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+			Map:        config.MapConfig{Title: "Search Test", Provider: ProviderLeaflet, Controls: config.ControlsConfig{Search: search}},
+		}
+
+		var buf bytes.Buffer
+		if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+			t.Fatalf("GenerateTo() error = %v", err)
+		}
+		html := buf.String()
+
+		for _, want := range []string{`<input type="search" id="point-search"`, "function searchPoints(query)"} {
+			if got := strings.Contains(html, want); got != search {
+				t.Errorf("search %v: %q present = %v", search, want, got)
+			}
+		}
+		if !strings.Contains(html, "matchesSearch(index) && inTimeWindow(index)") {
+			t.Errorf("search %v: markers are not filtered by the search terms", search)
+		}
+	}
+}
+
+func TestMeasureTool(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.77, Longitude: -122.41},
+	}
+
+	tests := []struct {
+		provider string
+		units    string
+		want     []string
+	}{
+		{ProviderGoogle, "metric", []string{`map.addListener("click", event => addMeasurePoint(`, "formatNumber(meters / 1000, 2) + ' km'"}},
+		{ProviderLeaflet, "imperial", []string{"map.on('click', event => addMeasurePoint(", "formatNumber(meters / 1609.344, 2) + ' mi'"}},
+		{ProviderMapLibre, "metric", []string{"map.on('click', event => addMeasurePoint(", "map.addSource('measure'"}},
+		{ProviderCesium, "metric", []string{"Cesium.ScreenSpaceEventType.LEFT_CLICK", "PolylineDashMaterialProperty"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Measure Test", Provider: tt.provider, Controls: config.ControlsConfig{MeasureTool: true}},
+				Statistics: config.StatisticsConfig{DistanceUnits: tt.units},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range append([]string{`<div class="measure-tool">`, "function drawMeasureLine(measured)"}, tt.want...) {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestDownloads(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.77, Longitude: -122.41},
+	}
+	downloads := []Download{
+		{Label: "Download GPX", File: "trip.gpx", URL: "exports/trip.gpx"},
+		{Label: "Download KML", File: "trip.kml", URL: "data:application/vnd.google-earth.kml+xml;base64,PGttbC8+"},
+	}
+
+	tests := []struct {
+		name      string
+		downloads []Download
+		widget    bool
+		want      bool
+	}{
+		{name: "buttons", downloads: downloads, want: true},
+		{name: "none", downloads: nil, want: false},
+		{name: "widget", downloads: downloads, widget: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Downloads Test"},
+				Output:     config.OutputConfig{Widget: tt.widget},
+			}
+
+			generator := NewGenerator(cfg)
+			generator.SetDownloads(tt.downloads)
+			var buf bytes.Buffer
+			if err := generator.GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			if got := strings.Contains(html, `<div class="downloads">`); got != tt.want {
+				t.Fatalf("download buttons shown = %v, want %v", got, tt.want)
+			}
+			if !tt.want {
+				return
+			}
+			for _, want := range []string{
+				`<a href="exports/trip.gpx" download="trip.gpx">Download GPX</a>`,
+				`<a href="data:application/vnd.google-earth.kml&#43;xml;base64,PGttbC8&#43;" download="trip.kml">Download KML</a>`,
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestUserColors(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.77, Longitude: -122.41, User: "alice"},
+		{Timestamp: start.Add(time.Minute), Latitude: 37.78, Longitude: -122.42, User: "bob"},
+		{Timestamp: start.Add(2 * time.Minute), Latitude: 37.79, Longitude: -122.43, User: "alice"},
+	}
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Users Test", Provider: provider},
+				Path:       config.PathConfig{Enabled: true, Style: config.PathStyleConfig{Color: "#FF0000"}},
+				Users:      config.UsersConfig{Colors: map[string]string{"bob": "#123456"}},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{
+				`[{"name":"alice","color":"#E6194B"},{"name":"bob","color":"#123456"}]`,
+				"function pathRuns(pathPoints)",
+				`<input type="checkbox" class="layer-toggle" value="user:bob" checked onchange="setLayerVisible(this.value, this.checked)">`,
+				`<span class="legend-color" style="background-color: #123456;"></span>`,
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+			if strings.Contains(html, "Walking Trail") {
+				t.Error("Generated HTML has a single-color path legend entry")
+			}
+		})
+	}
+
+	// A single user keeps the configured path color
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Users Test"},
+		Path:       config.PathConfig{Enabled: true, Style: config.PathStyleConfig{Color: "#FF0000"}},
+	}
+	var buf bytes.Buffer
+	if err := NewGenerator(cfg).GenerateTo(&buf, points[:1]); err != nil {
+		t.Fatalf("GenerateTo() error = %v", err)
+	}
+	if html := buf.String(); strings.Contains(html, "function pathRuns") || !strings.Contains(html, "Walking Trail") {
+		t.Error("single-user track should draw one path in the path color")
+	}
+}
+
+func TestStrokePattern(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: start.Add(time.Minute), Latitude: 37.78, Longitude: -122.42},
+	}
+
+	tests := []struct {
+		provider string
+		want     []string
+	}{
+		{ProviderGoogle, []string{`...strokeStyle("dashed",`, `...strokeStyle("dotted",`, "google.maps.SymbolPath.CIRCLE"}},
+		{ProviderLeaflet, []string{`...dashStyle("dashed",`, `...dashStyle("dotted",`}},
+		{ProviderMapLibre, []string{`...dashPaint("dashed")`, `...dashPaint("dotted")`, "'line-dasharray'"}},
+		{ProviderCesium, []string{`lineMaterial("#FF0000",`, `, "dashed"),`, `lineMaterial("#00AA00", 0.7, "dotted")`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Stroke Test", Provider: tt.provider},
+				Path:       config.PathConfig{Enabled: true, Style: config.PathStyleConfig{Color: "#FF0000", Opacity: 0.8, Weight: 3, StrokePattern: "dashed"}},
+				Compare:    config.CompareConfig{Color: "#00AA00", StrokePattern: "dotted"},
+			}
+
+			generator := NewGenerator(cfg)
+			generator.SetReference(points)
+			var buf bytes.Buffer
+			if err := generator.GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range append([]string{"border-top: 3px dashed #FF0000", "border-top: 3px dotted #00AA00"}, tt.want...) {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestMapType(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.77, Longitude: -122.41},
+	}
+
+	tests := []struct {
+		mapType string
+		want    string
+	}{
+		{mapType: "", want: `mapTypeId: "roadmap"`},
+		{mapType: "satellite", want: `mapTypeId: "satellite"`},
+		{mapType: "hybrid", want: `mapTypeId: "hybrid"`},
+		{mapType: "terrain", want: `mapTypeId: "terrain"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Map Type Test", InitialView: config.InitialViewConfig{MapType: tt.mapType}},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			if html := buf.String(); !strings.Contains(html, tt.want) {
+				t.Errorf("Generated HTML missing %q", tt.want)
+			}
+		})
+	}
+}
+
+func TestGeofenceLabels(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 0.5, Longitude: 0.5},
+		{Timestamp: testTime.Add(time.Minute), Latitude: 3, Longitude: 3},
+	}
+
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{ProviderGoogle, "label: { text: fence.name"},
+		{ProviderLeaflet, `<div class="geofence-label"`},
+		{ProviderMapLibre, "element.className = 'geofence-label'"},
+		{ProviderCesium, "position: Cesium.Cartesian3.fromDegrees(fence.label.lng, fence.label.lat)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Geofence Labels", Provider: tt.provider},
+				Geofences: config.GeofencesConfig{ShowBoundaries: true, Fences: []config.FenceConfig{
+					{Name: "Square", Polygon: []config.CoordinateConfig{
+						{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 2},
+						{Latitude: 2, Longitude: 2}, {Latitude: 2, Longitude: 0},
+					}},
+				}},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{tt.want, "<summary>Geofence Events (2)</summary>"} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+			// The label sits at the centroid of the square
+			if !strings.Contains(strings.ReplaceAll(html, " ", ""), "label:{lat:1,lng:1}") {
+				t.Error("Generated HTML missing the fence label position")
+			}
+		})
+	}
+}
+
+func TestStops(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.770, Longitude: -122.41},
+		{Timestamp: testTime.Add(5 * time.Minute), Latitude: 37.780, Longitude: -122.41},
+		{Timestamp: testTime.Add(25 * time.Minute), Latitude: 37.7801, Longitude: -122.41},
+		{Timestamp: testTime.Add(30 * time.Minute), Latitude: 37.790, Longitude: -122.41},
+	}
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps:  config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:         config.MapConfig{Title: "Stop Test", Provider: provider},
+				InfoWindows: config.InfoWindowsConfig{Enabled: true, MaxWidth: 300},
+				Stops:       config.StopsConfig{Enabled: true},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{
+				`arrival: "2025-10-28 10:05:00"`,
+				`departure: "2025-10-28 10:25:00"`,
+				`duration: "20m 00s"`,
+				`label: "20m"`,
+				"function addStopMarker(stop)",
+				"registerMarker(index, addStopMarker(stop))",
+				"stopInfoContent(stop)",
+				"Stops (sized by time spent)",
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+
+	// Without stop detection, every point keeps its waypoint marker
+	cfg := &config.Config{GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"}, Map: config.MapConfig{Title: "Stop Test"}}
+	var buf bytes.Buffer
+	if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+		t.Fatalf("GenerateTo() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "replacingStop") {
+		t.Error("Generated HTML has stop markers although stops are disabled")
+	}
+}
+
+func TestMilestones(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.80, Longitude: -122.41},
+	}
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Milestone Test", Provider: provider},
+				Path:       config.PathConfig{Enabled: true, Milestones: config.MilestonesConfig{Enabled: true}},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			// The 3.3 km track passes three kilometer marks
+			for _, want := range []string{`label: "1 km"`, `label: "3 km"`, "function addMilestones()", "addMilestones();", "Distance Milestones"} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+			if strings.Contains(html, `label: "4 km"`) {
+				t.Error("Generated HTML has a milestone beyond the end of the track")
+			}
+		})
+	}
+
+	// Milestones follow the statistics distance units
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Milestone Test"},
+		Path:       config.PathConfig{Enabled: true, Milestones: config.MilestonesConfig{Enabled: true}},
+		Statistics: config.StatisticsConfig{DistanceUnits: "imperial"},
+	}
+	var buf bytes.Buffer
+	if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+		t.Fatalf("GenerateTo() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `label: "2 mi"`) {
+		t.Error("Generated HTML missing the imperial milestone \"2 mi\"")
+	}
+}
+
+func TestWeather(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime.Add(5 * time.Minute), Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: testTime.Add(70 * time.Minute), Latitude: 37.78, Longitude: -122.41},
+	}
+	report := &weather.Report{Unit: weather.Celsius, Hourly: []weather.Observation{
+		{Time: testTime, Temperature: 12, Code: 2},
+		{Time: testTime.Add(time.Hour), Temperature: 15, Code: 2},
+	}}
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps:  config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:         config.MapConfig{Title: "Weather Test", Provider: provider},
+				InfoWindows: config.InfoWindowsConfig{Enabled: true, MaxWidth: 300},
+			}
+
+			generator := NewGenerator(cfg)
+			generator.SetWeather(report)
+			var buf bytes.Buffer
+			if err := generator.GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{
+				"<strong>Weather:</strong> 12&ndash;15 °C, Partly cloudy",
+				`"0":"12 °C, Partly cloudy"`,
+				`"1":"15 °C, Partly cloudy"`,
+				"pointWeather[index]",
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+
+	// Without a weather report the stats bar has no weather
+	cfg := &config.Config{GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"}, Map: config.MapConfig{Title: "Weather Test"}}
+	var buf bytes.Buffer
+	if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+		t.Fatalf("GenerateTo() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "<span><strong>Weather:</strong>") {
+		t.Error("Generated HTML shows weather without a report")
+	}
+}
+
+func TestPermalink(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.77, Longitude: -122.41},
+		{Timestamp: time.Date(2025, 10, 28, 11, 0, 0, 0, time.UTC), Latitude: 37.78, Longitude: -122.40},
+	}
+
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{ProviderGoogle, `map.addListener("idle", updatePermalink)`},
+		{ProviderLeaflet, "map.on('moveend', updatePermalink)"},
+		{ProviderMapLibre, "map.on('moveend', updatePermalink)"},
+		{ProviderCesium, "map.camera.moveEnd.addEventListener(updatePermalink)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map: config.MapConfig{
+					Title:    "Permalink Test",
+					Provider: tt.provider,
+					Controls: config.ControlsConfig{Permalink: true, TimeSlider: true},
+				},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{
+				tt.want,
+				"function getMapView()",
+				"function setMapView(lat, lng, zoom)",
+				"if (restorePermalink()) {",
+				"history.replaceState(null, '', '#' + parts.join('&'))",
+				"parts.push('time=' + timeWindow.join(','))",
+				"document.getElementById('time-start').value = range[0];",
+				"window.addEventListener('hashchange', restorePermalink)",
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+
+			// Without the control the URL is left alone
+			cfg.Map.Controls.Permalink = false
+			buf.Reset()
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			if strings.Contains(buf.String(), "updatePermalink") {
+				t.Error("Generated HTML updates the permalink although it is disabled")
+			}
+		})
+	}
+}
+
+func TestPrintMode(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.77, Longitude: -122.41},
+	}
+
+	tests := []struct {
+		provider string
+		want     string
+	}{
+		{ProviderGoogle, "map.setOptions({ styles: null, mapTypeId: 'roadmap' })"},
+		{ProviderLeaflet, "map.invalidateSize();"},
+		{ProviderMapLibre, "map.resize();"},
+		{ProviderCesium, "map.resize();"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map: config.MapConfig{
+					Title:    "Print Test",
+					Provider: tt.provider,
+					Controls: config.ControlsConfig{Print: true},
+					Print:    config.PrintConfig{PaperSize: "letter", Orientation: "portrait"},
+				},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{
+				tt.want,
+				"function setPrintLayout(",
+				`<button type="button" onclick="printMap()">Print</button>`,
+				"size: letter portrait;",
+				"width: 195.9mm;",
+				"body.printing .layer-toggles",
+				"window.addEventListener('beforeprint', () => setPrinting(true));",
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+
+	// The print layout is always there for the browser's print command; only the
+	// button is optional
+	cfg := &config.Config{GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"}, Map: config.MapConfig{Title: "Print Test"}}
+	var buf bytes.Buffer
+	if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+		t.Fatalf("GenerateTo() error = %v", err)
+	}
+	html := buf.String()
+	if !strings.Contains(html, "size: A4 landscape;") {
+		t.Error("Generated HTML missing the default A4 landscape print layout")
+	}
+	if strings.Contains(html, "printMap()") {
+		t.Error("Generated HTML has a print button although it is disabled")
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	points := gps.Points{
+		{Timestamp: time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC), Latitude: 37.77, Longitude: -122.41, Elevation: 10},
+		{Timestamp: time.Date(2025, 10, 28, 11, 0, 0, 0, time.UTC), Latitude: 37.78, Longitude: -122.41, Elevation: 20},
+	}
+
+	tests := []struct {
+		language string
+		want     []string
+	}{
+		{"", []string{
+			`<html lang="en">`,
+			"<strong>Total Points:</strong> 2",
+			"<strong>Start:</strong> 2025-10-28 10:00",
+			"<h3>Legend</h3>",
+			"1.11 km",
+			`const datePattern = "{y}-{m}-{d}";`,
+		}},
+		{"de", []string{
+			`<html lang="de">`,
+			"<strong>Gesamtpunkte:</strong> 2",
+			"<strong>Start:</strong> 28.10.2025 10:00",
+			"<h3>Legende</h3>",
+			"1,11 km",
+			`const decimalSeparator = ",";`,
+			`const datePattern = "{d}.{m}.{y}";`,
+			`"no_points":"Keine GPS-Punkte vorhanden"`,
+		}},
+		{"es", []string{"<strong>Puntos totales:</strong> 2", "<strong>Inicio:</strong> 28/10/2025 10:00", "<h3>Leyenda</h3>"}},
+		{"fr", []string{"<strong>Nombre de points:</strong> 2", "<h3>Légende</h3>", "Point de départ"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Language Test", Provider: ProviderLeaflet, Language: tt.language},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range tt.want {
+				if !strings.Contains(html, want) {
+					t.Errorf("Generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+
+	cfg := &config.Config{GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"}, Map: config.MapConfig{Language: "xx"}}
+	if err := NewGenerator(cfg).GenerateTo(&bytes.Buffer{}, points); err == nil {
+		t.Error("GenerateTo() error = nil for an unknown language")
+	}
+}
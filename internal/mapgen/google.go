@@ -0,0 +1,740 @@
+package mapgen
+
+// ProviderGoogle renders maps with the Google Maps JavaScript API. It is the default
+// provider and requires an API key.
+const ProviderGoogle = "google"
+
+// googleTemplate defines the Google Maps parts of the page: the map-head, map-script,
+// and map-loader templates invoked by the shared page template. The script draws the
+// track with the data constants declared by the page and, when configured, switches
+// to the Leaflet fallback if the API fails to load.
+const googleTemplate = `{{define "map-head"}}
+    {{if and .Fallback .Fallback.InlineStyle}}
+    <style>{{.Fallback.InlineStyle}}</style>
+    {{end}}
+{{end}}
+
+{{define "map-script"}}
+        function initMap() {
+            if (points.length === 0) {
+                document.getElementById('map').innerHTML = '<div style="text-align: center; padding: 50px; color: #666;">' + message('no_points') + '</div>';
+                return;
+            }
+
+            // Initialize map
+            const center = { lat: {{.Center.Latitude}}, lng: {{.Center.Longitude}} };
+            
+            map = new google.maps.Map(document.getElementById("map"), {
+                zoom: {{.Zoom}},
+                center: center,
+                mapTypeId: "{{or .Config.Map.InitialView.MapType "roadmap"}}",
+                {{if .MapStyles}}
+                styles: {{.MapStyles}},
+                {{end}}
+                {{if .Restriction}}
+                restriction: {
+                    latLngBounds: { north: {{.Restriction.North}}, south: {{.Restriction.South}}, east: {{.Restriction.East}}, west: {{.Restriction.West}} },
+                    strictBounds: {{.Restriction.StrictBounds}}
+                },
+                {{end}}
+                zoomControl: {{.Config.Map.Controls.ZoomControl}},
+                streetViewControl: {{.Config.Map.Controls.StreetViewControl}},
+                fullscreenControl: {{.Config.Map.Controls.FullscreenControl}},
+                mapTypeControl: {{.Config.Map.Controls.MapTypeControl}},
+                scaleControl: {{.Config.Map.Controls.ScaleControl}}
+            });
+
+            {{if .Heatmap}}
+            // Render point density, instead of or next to individual markers
+            addHeatmap();
+            {{end}}
+
+            {{if .Trail}}
+            // Add markers
+            addMarkers();
+            {{if .LiveURL}}
+            listenForPoints();
+            {{end}}
+            
+            // Add walking path
+            {{if .Config.Path.Enabled}}
+            addWalkingPath();
+            {{end}}
+
+            {{if .Milestones}}
+            // Mark every interval of distance along the path
+            addMilestones();
+            {{end}}
+            {{end}}
+            
+            {{if and .Config.Geofences.ShowBoundaries .Fences}}
+            // Draw configured geofence boundaries
+            addGeofences();
+            {{end}}
+
+            {{if .Reference}}
+            // Draw the reference route for comparison
+            addReferenceRoute();
+            {{end}}
+
+            {{if .Encounters}}
+            // Highlight where users were together
+            addEncounters();
+            {{end}}
+
+            {{if .Meeting}}
+            // Mark the suggested meeting point
+            addMeetingPoint();
+            {{end}}
+
+            {{if .Config.Map.Controls.MeasureTool}}
+            // Clicks on the map add points to the measure tool while it is on
+            map.addListener("click", event => addMeasurePoint(event.latLng.lat(), event.latLng.lng()));
+            {{end}}
+
+            {{if .Config.Map.Controls.Permalink}}
+            // Keep the link in step with the view, and reopen the view saved in it
+            map.addListener("idle", updatePermalink);
+            if (restorePermalink()) {
+                return;
+            }
+            {{end}}
+
+            // Fit map to show all points
+            fitMapToBounds();
+        }
+
+        {{if .Config.Map.Controls.MeasureTool}}
+        let measureLine;
+
+        function drawMeasureLine(measured) {
+            if (!measureLine) {
+                measureLine = new google.maps.Polyline({
+                    strokeOpacity: 0,
+                    icons: [{ icon: { path: "M 0,-1 0,1", strokeOpacity: 1, strokeColor: "#000", scale: 3 }, offset: "0", repeat: "12px" }],
+                    clickable: false,
+                    zIndex: {{index .ZIndex "markers"}} + 3,
+                    map: map
+                });
+            }
+            measureLine.setPath(measured);
+        }
+        {{end}}
+
+        {{if .Heatmap}}
+        let heatmap;
+
+        function addHeatmap() {
+            heatmap = new google.maps.visualization.HeatmapLayer({
+                data: heatmapCells.map(cell => ({ location: new google.maps.LatLng(cell[0], cell[1]), weight: cell[2] })),
+                radius: {{if .Config.Heatmap.Radius}}{{.Config.Heatmap.Radius}}{{else}}20{{end}},
+                opacity: {{if .Config.Heatmap.Opacity}}{{.Config.Heatmap.Opacity}}{{else}}0.6{{end}}
+            });
+            setHeatmapVisible(!hiddenLayers.has('heatmap'));
+        }
+
+        function setHeatmapVisible(visible) {
+            if (heatmap) {
+                heatmap.setMap(visible ? map : null);
+            }
+        }
+        {{end}}
+
+        {{if and .Config.Geofences.ShowBoundaries .Fences}}
+        function addGeofences() {
+            const style = {
+                strokeColor: "#FF8800",
+                strokeOpacity: 0.9,
+                strokeWeight: 2,
+                fillColor: "#FF8800",
+                fillOpacity: 0.15,
+                zIndex: {{index .ZIndex "geofences"}},
+                map: map
+            };
+            geofences.forEach(fence => {
+                geofenceShapes.push(fence.center
+                    ? new google.maps.Circle(Object.assign({ center: fence.center, radius: fence.radius }, style))
+                    : new google.maps.Polygon(Object.assign({ paths: fence.polygon }, style)));
+
+                // An invisible marker carries the fence name as its label
+                geofenceShapes.push(new google.maps.Marker({
+                    position: fence.label,
+                    map: map,
+                    clickable: false,
+                    zIndex: {{index .ZIndex "geofences"}},
+                    icon: { path: google.maps.SymbolPath.CIRCLE, scale: 0 },
+                    label: { text: fence.name, color: '#A34E00', fontSize: '12px', fontWeight: 'bold' }
+                }));
+            });
+        }
+
+        const geofenceShapes = [];
+
+        function setGeofencesVisible(visible) {
+            geofenceShapes.forEach(shape => shape.setVisible(visible));
+        }
+        {{end}}
+
+        // strokeStyle returns the polyline options drawing a solid, dashed, or dotted
+        // line. Patterns are symbols repeated along an invisible line, which take
+        // the line's color.
+        function strokeStyle(pattern, opacity, weight) {
+            if (pattern === 'dashed') {
+                return {
+                    strokeOpacity: 0,
+                    icons: [{ icon: { path: 'M 0,-1 0,1', strokeOpacity: opacity, scale: weight }, offset: '0', repeat: (weight * 4) + 'px' }]
+                };
+            }
+            if (pattern === 'dotted') {
+                return {
+                    strokeOpacity: 0,
+                    icons: [{ icon: { path: google.maps.SymbolPath.CIRCLE, strokeOpacity: 0, fillOpacity: opacity, scale: weight / 2 }, offset: '0', repeat: (weight * 2) + 'px' }]
+                };
+            }
+            return { strokeOpacity: opacity, icons: [] };
+        }
+
+        {{if .Reference}}
+        function addReferenceRoute() {
+            new google.maps.Polyline({
+                path: referenceRoute,
+                geodesic: true,
+                strokeColor: "{{.ReferenceColor}}",
+                strokeWeight: {{.Config.Path.Style.Weight}} + 2,
+                zIndex: {{index .ZIndex "reference"}},
+                map: map,
+                ...strokeStyle("{{.Config.Compare.StrokePattern}}", 0.7, {{.Config.Path.Style.Weight}} + 2)
+            });
+        }
+        {{end}}
+
+        {{if .Encounters}}
+        function addEncounters() {
+            encounters.forEach(encounter => {
+                encounter.paths.forEach(path => {
+                    new google.maps.Polyline({
+                        path: path,
+                        strokeColor: "{{.EncounterColor}}",
+                        strokeOpacity: 0.9,
+                        strokeWeight: {{.Config.Path.Style.Weight}} + 4,
+                        zIndex: {{index .ZIndex "path"}} + 1,
+                        map: map
+                    });
+                });
+
+                const marker = new google.maps.Marker({
+                    position: encounter.closest,
+                    map: map,
+                    title: encounter.users,
+                    icon: createMarkerIcon("{{.EncounterColor}}", "&", 28),
+                    zIndex: {{index .ZIndex "markers"}} + 1
+                });
+                const infoWindow = new google.maps.InfoWindow({
+                    content: "<div style=\"font-family: Arial, sans-serif;\"><h3 style=\"margin: 0 0 10px 0;\">" + encounter.users + "</h3>" +
+                        "<p><strong>" + message('from') + ":</strong> " + encounter.start + "</p>" +
+                        "<p><strong>" + message('to') + ":</strong> " + encounter.end + "</p>" +
+                        "<p><strong>" + message('closest') + ":</strong> " + encounter.distance.toFixed(0) + " m</p></div>"
+                });
+                marker.addListener("click", () => infoWindow.open(map, marker));
+            });
+        }
+        {{end}}
+
+        {{if .Meeting}}
+        function addMeetingPoint() {
+            // Dashed lines from each user's position to the meeting point
+            meeting.users.forEach(user => {
+                new google.maps.Polyline({
+                    path: [user.position, meeting.point],
+                    strokeOpacity: 0,
+                    icons: [{ icon: { path: "M 0,-1 0,1", strokeOpacity: 0.8, strokeColor: "#6A1B9A", scale: 2 }, offset: "0", repeat: "10px" }],
+                    zIndex: {{index .ZIndex "path"}} + 1,
+                    map: map
+                });
+            });
+
+            const marker = new google.maps.Marker({
+                position: meeting.point,
+                map: map,
+                title: message('meeting_point'),
+                icon: createMarkerIcon("#6A1B9A", "M", 36),
+                zIndex: {{index .ZIndex "markers"}} + 2
+            });
+            const infoWindow = new google.maps.InfoWindow({
+                content: "<div style=\"font-family: Arial, sans-serif;\"><h3 style=\"margin: 0 0 10px 0;\">" + message('meeting_point') + "</h3>" +
+                    "<p><strong>" + message('based_on_positions') + ":</strong> " + meeting.time + "</p>" +
+                    meeting.users.map(user => "<p><strong>" + user.name + ":</strong> " + message('distance_away', { distance: formatNumber(user.distance / 1000, 2) }) + "</p>").join("") +
+                    "</div>"
+            });
+            marker.addListener("click", () => infoWindow.open(map, marker));
+        }
+        {{end}}
+
+        function addMarkers() {
+            points.forEach(addMarker);
+
+            {{if .Config.Markers.Spiderfy}}
+            // Collapse expanded markers when the user clicks elsewhere or zooms
+            map.addListener("click", unspiderfy);
+            map.addListener("zoom_changed", unspiderfy);
+            {{end}}
+        }
+
+        // addMarker draws the marker of one point, also for points pushed by a live server
+        function addMarker(point, index) {
+            {{if .Stops}}
+            // Points of a stop are drawn as one stop marker
+            const stop = replacingStop[index];
+            if (stop) {
+                if (stop.anchor === index) {
+                    registerMarker(index, addStopMarker(stop));
+                }
+                return;
+            }
+            {{end}}
+
+            let icon, title = point.title;
+
+            // Customize marker icons
+            const size = index === 0 || index === points.length - 1 ? 32 : 24;
+            const label = markerLabel(index, size);
+            if (index === 0) {
+                icon = createMarkerIcon('#00FF00', label.text, size, label);
+                title = "START - " + title;
+            } else if (index === points.length - 1) {
+                icon = createMarkerIcon('#FF0000', label.text, size, label);
+                title = "END - " + title;
+            } else {
+                const color = pointColor(point);
+                icon = createMarkerIcon(color, label.text, size, label);
+            }
+
+            const custom = customIcon(index);
+            if (custom) {
+                icon = {
+                    url: custom.url,
+                    scaledSize: new google.maps.Size(custom.width, custom.height),
+                    anchor: new google.maps.Point(custom.anchorX, custom.anchorY)
+                };
+            }
+
+            const marker = new google.maps.Marker({
+                position: { lat: point.lat, lng: point.lng },
+                map: map,
+                title: title,
+                icon: icon,
+                zIndex: {{index .ZIndex "markers"}}
+            });
+
+            // Track markers so the category and time filters can show and hide them
+            registerMarker(index, marker);
+
+            {{if .Config.Markers.Spiderfy}}
+            // Group stacked markers so they can be expanded on click
+            registerColocated(marker, point, index);
+            {{end}}
+
+            // Info window
+            {{if .Config.InfoWindows.Enabled}}
+            const infoWindow = new google.maps.InfoWindow({
+                content: createInfoWindowContent(point, title, index),
+                maxWidth: {{.Config.InfoWindows.MaxWidth}}
+            });
+            {{end}}
+
+            marker.addListener("click", () => {
+                {{if .Config.Markers.Spiderfy}}
+                // Expand a stack of co-located markers before opening any info window
+                if (spiderfy(marker)) {
+                    return;
+                }
+                {{end}}
+                {{if .Config.InfoWindows.Enabled}}
+                infoWindow.open(map, marker);
+                {{end}}
+            });
+        }
+
+        {{if .Stops}}
+        function addStopMarker(stop) {
+            const marker = new google.maps.Marker({
+                position: { lat: stop.lat, lng: stop.lng },
+                map: map,
+                title: message('stop') + ' (' + stop.duration + ')',
+                icon: createMarkerIcon("{{.StopColor}}", stop.label, stop.size),
+                zIndex: {{index .ZIndex "markers"}}
+            });
+            {{if .Config.InfoWindows.Enabled}}
+            const infoWindow = new google.maps.InfoWindow({ content: stopInfoContent(stop), maxWidth: {{.Config.InfoWindows.MaxWidth}} });
+            marker.addListener("click", () => infoWindow.open(map, marker));
+            {{end}}
+            return marker;
+        }
+        {{end}}
+
+        {{if .Config.Markers.Spiderfy}}
+        const colocatedGroups = {};
+        const colocatedGroupOf = {{.Colocated}} || {};
+        let spiderfied = null;
+
+        // Groups found at generation time; points pushed later by a live server are
+        // grouped by their exact coordinates
+        function colocatedKey(point, index) {
+            if (index in colocatedGroupOf) {
+                return "group" + colocatedGroupOf[index];
+            }
+            return point.lat.toFixed(6) + "," + point.lng.toFixed(6);
+        }
+
+        function registerColocated(marker, point, index) {
+            const key = colocatedKey(point, index);
+            marker.colocatedKey = key;
+            (colocatedGroups[key] = colocatedGroups[key] || []).push({ marker: marker, point: point });
+        }
+
+        function spiderfy(marker) {
+            const group = colocatedGroups[marker.colocatedKey] || [];
+            if (group.length < 2 || (spiderfied && spiderfied.key === marker.colocatedKey)) {
+                return false;
+            }
+
+            unspiderfy();
+
+            // Spread the markers on a circle roughly 40 pixels wide at the current zoom
+            const center = group[0].point;
+            const radius = 40 * 360 / (256 * Math.pow(2, map.getZoom()));
+            const legs = group.map((entry, i) => {
+                const angle = 2 * Math.PI * i / group.length;
+                const position = {
+                    lat: center.lat + radius * Math.sin(angle),
+                    lng: center.lng + radius * Math.cos(angle) / Math.cos(center.lat * Math.PI / 180)
+                };
+                entry.marker.setPosition(position);
+                return new google.maps.Polyline({
+                    path: [{ lat: center.lat, lng: center.lng }, position],
+                    strokeColor: "#444",
+                    strokeOpacity: 0.7,
+                    strokeWeight: 1,
+                    zIndex: {{index .ZIndex "markers"}},
+                    map: map
+                });
+            });
+
+            spiderfied = { key: marker.colocatedKey, legs: legs };
+            return true;
+        }
+
+        function unspiderfy() {
+            if (!spiderfied) {
+                return;
+            }
+            colocatedGroups[spiderfied.key].forEach(entry => {
+                entry.marker.setPosition({ lat: entry.point.lat, lng: entry.point.lng });
+            });
+            spiderfied.legs.forEach(leg => leg.setMap(null));
+            spiderfied = null;
+        }
+        {{end}}
+
+        function setMarkerVisible(marker, visible) {
+            marker.setVisible(visible);
+        }
+
+        {{if .Sidebar}}
+        // focusMarker centers a marker, zooming in to street level, and clicks it to open its info window
+        function focusMarker(marker, point) {
+            map.panTo({ lat: point.lat, lng: point.lng });
+            map.setZoom(Math.max(map.getZoom(), 16));
+            google.maps.event.trigger(marker, 'click');
+        }
+        {{end}}
+
+        // createMarkerIcon draws a circle with the given text, styled by a point label
+        // from markerLabel if given, which stretches the circle into a pill for wide text
+        function createMarkerIcon(color, text, size, label) {
+            label = label || { color: 'white', font: 'bold ' + (size/3) + 'px Arial', width: size };
+            const width = label.width;
+            return {
+                url: 'data:image/svg+xml;charset=UTF-8,' + encodeURIComponent(
+                    '<svg xmlns="http://www.w3.org/2000/svg" width="' + width + '" height="' + size + '" viewBox="0 0 ' + width + ' ' + size + '">' +
+                    '<rect x="2" y="2" width="' + (width-4) + '" height="' + (size-4) + '" rx="' + (size/2-2) + '" fill="' + color + '" stroke="#000" stroke-width="2"/>' +
+                    '<text x="' + (width/2) + '" y="' + (size/2) + '" text-anchor="middle" dominant-baseline="central" fill="' + escapeHTML(label.color) + '" style="font: ' + escapeHTML(label.font) + '">' + escapeHTML(text) + '</text>' +
+                    '</svg>'
+                ),
+                scaledSize: new google.maps.Size(width, size),
+                anchor: new google.maps.Point(width/2, size/2)
+            };
+        }
+
+        let walkingPath;
+
+        function addWalkingPath() {
+            {{if or .SpeedScale .DayScale .UserColors}}
+            setPathPoints(points);
+            {{else}}
+            const pathCoordinates = points.map(point => ({ lat: point.lat, lng: point.lng }));
+
+            walkingPath = new google.maps.Polyline({
+                path: pathCoordinates,
+                geodesic: true,
+                strokeColor: "{{.Config.Path.Style.Color}}",
+                strokeWeight: {{.Config.Path.Style.Weight}},
+                ...strokeStyle("{{.Config.Path.Style.StrokePattern}}", {{.Config.Path.Style.Opacity}}, {{.Config.Path.Style.Weight}}),
+                zIndex: {{index .ZIndex "path"}}
+            });
+
+            walkingPath.setMap(map);
+            {{end}}
+
+            // Add direction arrows rotated to the bearing at each segment midpoint
+            {{if .Arrows}}
+            const arrows = [
+                {{range .Arrows}}
+                { lat: {{.Latitude}}, lng: {{.Longitude}}, rotation: {{.Rotation}} },
+                {{end}}
+            ];
+
+            arrows.forEach(arrow => {
+                arrowMarkers.push(new google.maps.Marker({
+                    position: { lat: arrow.lat, lng: arrow.lng },
+                    map: map,
+                    clickable: false,
+                    icon: {
+                        path: google.maps.SymbolPath.FORWARD_CLOSED_ARROW,
+                        scale: 3,
+                        rotation: arrow.rotation,
+                        strokeColor: "{{.Config.Path.Style.Color}}",
+                        fillColor: "{{.Config.Path.Style.Color}}",
+                        fillOpacity: 1
+                    },
+                    zIndex: {{index .ZIndex "arrows"}}
+                }));
+            });
+            {{end}}
+        }
+
+        {{if .Milestones}}
+        function addMilestones() {
+            milestones.forEach(milestone => {
+                milestoneMarkers.push(new google.maps.Marker({
+                    position: { lat: milestone.lat, lng: milestone.lng },
+                    map: map,
+                    title: milestone.label,
+                    clickable: false,
+                    icon: createMarkerIcon('#FFFFFF', milestone.label, 20, milestoneLabel(milestone.label)),
+                    zIndex: {{index .ZIndex "arrows"}} + 1
+                }));
+            });
+        }
+        {{end}}
+
+        {{if .Arrows}}
+        const arrowMarkers = [];
+
+        function setArrowsVisible(visible) {
+            arrowMarkers.forEach(marker => marker.setVisible(visible));
+        }
+        {{end}}
+
+        {{if or .SpeedScale .DayScale .UserColors}}
+        // Speed and user colored paths are drawn as one polyline per color run,
+        // reused as the filters change the path
+        const pathLines = [];
+
+        function setPathPoints(pathPoints) {
+            const runs = pathRuns(pathPoints);
+            runs.forEach((run, i) => {
+                if (!pathLines[i]) {
+                    pathLines[i] = new google.maps.Polyline({
+                        geodesic: true,
+                        strokeWeight: {{.Config.Path.Style.Weight}},
+                        zIndex: {{index .ZIndex "path"}},
+                        ...strokeStyle("{{.Config.Path.Style.StrokePattern}}", {{.Config.Path.Style.Opacity}}, {{.Config.Path.Style.Weight}})
+                    });
+                }
+                pathLines[i].setOptions({
+                    path: run.points.map(point => ({ lat: point.lat, lng: point.lng })),
+                    strokeColor: run.color,
+                    map: map
+                });
+            });
+            pathLines.slice(runs.length).forEach(line => line.setMap(null));
+        }
+        {{else}}
+        function setPathPoints(pathPoints) {
+            if (walkingPath) {
+                walkingPath.setPath(pathPoints.map(point => ({ lat: point.lat, lng: point.lng })));
+            }
+        }
+        {{end}}
+
+        {{if or .PlaybackMillis .Profile}}
+        // The track marker follows playback and the elevation profile cursor
+        let trackMarker = null;
+
+        function setTrackMarker(position) {
+            if (!position) {
+                if (trackMarker) {
+                    trackMarker.setMap(null);
+                    trackMarker = null;
+                }
+                return;
+            }
+            if (!trackMarker) {
+                trackMarker = new google.maps.Marker({
+                    map: map,
+                    clickable: false,
+                    icon: {
+                        path: google.maps.SymbolPath.CIRCLE,
+                        scale: 8,
+                        fillColor: "{{.Config.Path.Style.Color}}",
+                        fillOpacity: 1,
+                        strokeColor: "#FFFFFF",
+                        strokeWeight: 3
+                    },
+                    zIndex: {{index .ZIndex "markers"}} + 3
+                });
+            }
+            trackMarker.setPosition({ lat: position.lat, lng: position.lng });
+        }
+        {{end}}
+
+        function fitMapToBounds() {
+            {{if .Config.Map.AutoFitBounds}}
+            const bounds = new google.maps.LatLngBounds();
+            points.forEach(point => {
+                bounds.extend({ lat: point.lat, lng: point.lng });
+            });
+            {{if .Reference}}
+            referenceRoute.forEach(position => bounds.extend(position));
+            {{end}}
+            map.fitBounds(bounds);
+            
+            // Ensure minimum zoom level
+            google.maps.event.addListenerOnce(map, 'bounds_changed', function() {
+                if (map.getZoom() > 15) {
+                    map.setZoom(15);
+                }
+            });
+            {{end}}
+        }
+
+        // Printing uses the plain road map, whatever the style and map type on screen;
+        // the map resizes itself to the print layout
+        let screenMapOptions = null;
+
+        function setPrintLayout(printing) {
+            if (printing) {
+                screenMapOptions = { styles: map.get('styles') || null, mapTypeId: map.getMapTypeId() };
+                map.setOptions({ styles: null, mapTypeId: 'roadmap' });
+            } else if (screenMapOptions) {
+                map.setOptions(screenMapOptions);
+                screenMapOptions = null;
+            }
+        }
+
+        {{if .Config.Map.Controls.Permalink}}
+        function getMapView() {
+            const center = map.getCenter();
+            return { lat: center.lat(), lng: center.lng(), zoom: map.getZoom() };
+        }
+
+        function setMapView(lat, lng, zoom) {
+            map.setCenter({ lat: lat, lng: lng });
+            map.setZoom(Math.round(zoom));
+        }
+        {{end}}
+
+        // Helper function for template
+        window.initMap = initMap;
+
+        {{if .Fallback}}
+        // Render the track with Leaflet if Google Maps fails to load
+        let fallbackActive = false;
+
+        function activateFallback(reason) {
+            if (fallbackActive) {
+                return;
+            }
+            fallbackActive = true;
+            console.warn('Google Maps unavailable (' + reason + '), switching to fallback map');
+
+            {{if .Fallback.InlineScript}}
+            initFallbackMap();
+            {{else}}
+            const style = document.createElement('link');
+            style.rel = 'stylesheet';
+            style.href = "{{.Fallback.StyleURL}}";
+            document.head.appendChild(style);
+
+            const script = document.createElement('script');
+            script.src = "{{.Fallback.ScriptURL}}";
+            script.onload = initFallbackMap;
+            document.head.appendChild(script);
+            {{end}}
+        }
+
+        function initFallbackMap() {
+            const container = document.getElementById('map');
+            container.innerHTML = '';
+            if (points.length === 0) {
+                container.innerHTML = '<div style="text-align: center; padding: 50px; color: #666;">' + message('no_points') + '</div>';
+                return;
+            }
+
+            const fallbackMap = L.map(container).setView([{{.Center.Latitude}}, {{.Center.Longitude}}], {{.Zoom}});
+            L.tileLayer("{{.Fallback.TileURL}}", {
+                attribution: "{{.Fallback.Attribution}}",
+                maxZoom: 19
+            }).addTo(fallbackMap);
+
+            const latLngs = points.map(point => [point.lat, point.lng]);
+            {{if .Config.Path.Enabled}}
+            L.polyline(latLngs, {
+                color: "{{.Config.Path.Style.Color}}",
+                opacity: {{.Config.Path.Style.Opacity}},
+                weight: {{.Config.Path.Style.Weight}}
+            }).addTo(fallbackMap);
+            {{end}}
+
+            points.forEach((point, index) => {
+                const isEnd = index === points.length - 1;
+                let color = pointColor(point);
+                if (index === 0) {
+                    color = '#00FF00';
+                } else if (isEnd) {
+                    color = '#FF0000';
+                }
+
+                L.circleMarker([point.lat, point.lng], {
+                    radius: index === 0 || isEnd ? 8 : 5,
+                    color: '#000',
+                    weight: 1,
+                    fillColor: color,
+                    fillOpacity: 1
+                }).bindPopup(createInfoWindowContent(point, point.title, index)).addTo(fallbackMap);
+            });
+
+            {{if .Config.Map.AutoFitBounds}}
+            fallbackMap.fitBounds(latLngs);
+            {{end}}
+        }
+
+        // Google Maps calls this hook when the API key is rejected or over quota
+        window.gm_authFailure = () => activateFallback('authentication failed');
+
+        // Fall back if the API script never finishes loading (blocked or offline)
+        setTimeout(() => {
+            if (!window.google || !window.google.maps) {
+                activateFallback('load timeout');
+            }
+        }, {{.Fallback.TimeoutMillis}});
+        {{end}}
+{{end}}
+
+{{define "map-loader"}}
+    {{if and .Fallback .Fallback.InlineScript}}
+    <script>{{.Fallback.InlineScript}}</script>
+    {{end}}
+    <script async defer src="https://maps.googleapis.com/maps/api/js?key={{.APIKey}}&callback=initMap{{if .Libraries}}&libraries={{join .Libraries ","}}{{end}}"{{if .Fallback}} onerror="activateFallback('script error')"{{end}}></script>
+{{end}}`
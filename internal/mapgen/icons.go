@@ -0,0 +1,122 @@
+package mapgen
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// DefaultIconSize is the width and height in pixels of a custom marker icon
+// without a configured size.
+const DefaultIconSize = 32
+
+// MarkerIcon is a custom marker image from markers.default, markers.start, or
+// markers.end, with its size and anchor resolved.
+//
+// @struct MarkerIcon
+// @description Custom marker image drawn instead of the built-in circle
+// @property URL string Image URL (a data URI for local files in self-contained pages)
+// @property Width int Displayed width in pixels
+// @property Height int Displayed height in pixels
+// @property AnchorX int Horizontal offset of the point placed on the location, from the left edge
+// @property AnchorY int Vertical offset of the point placed on the location, from the top edge
+type MarkerIcon struct {
+	URL     string `json:"url"`     // @field URL Image URL or data URI
+	Width   int    `json:"width"`   // @field Width Displayed width in pixels
+	Height  int    `json:"height"`  // @field Height Displayed height in pixels
+	AnchorX int    `json:"anchorX"` // @field AnchorX Anchor offset from the left edge in pixels
+	AnchorY int    `json:"anchorY"` // @field AnchorY Anchor offset from the top edge in pixels
+}
+
+// MarkerIcons holds the custom icons of the configurable markers. A nil icon keeps
+// the built-in numbered circle.
+type MarkerIcons struct {
+	Default *MarkerIcon `json:"default"` // Regular points
+	Start   *MarkerIcon `json:"start"`   // First point
+	End     *MarkerIcon `json:"end"`     // Last point
+}
+
+// markerIconsFor resolves the custom marker icons. Self-contained pages embed local
+// icon files as data URIs so they need no files next to the page.
+func markerIconsFor(cfg *config.Config) (MarkerIcons, error) {
+	var icons MarkerIcons
+	for _, marker := range []struct {
+		name  string
+		style config.IconConfig
+		icon  **MarkerIcon
+	}{
+		{"default", cfg.Markers.Default.Icon, &icons.Default},
+		{"start", cfg.Markers.Start.Icon, &icons.Start},
+		{"end", cfg.Markers.End.Icon, &icons.End},
+	} {
+		icon := markerIconFor(marker.style)
+		if icon != nil && cfg.Output.SelfContained && isLocalIcon(icon.URL) {
+			uri, err := iconDataURI(icon.URL)
+			if err != nil {
+				return icons, fmt.Errorf("cannot read %s marker icon: %w", marker.name, err)
+			}
+			icon.URL = uri
+		}
+		*marker.icon = icon
+	}
+	return icons, nil
+}
+
+// markerIconFor returns the custom icon configured by icon.url, or nil without one.
+// A missing width or height copies the other dimension, falling back to
+// DefaultIconSize, and an unset anchor places the bottom center of the image on
+// the location, like a pin.
+func markerIconFor(icon config.IconConfig) *MarkerIcon {
+	if icon.URL == nil || strings.TrimSpace(*icon.URL) == "" {
+		return nil
+	}
+
+	width, height := icon.Size.Width, icon.Size.Height
+	switch {
+	case width <= 0 && height <= 0:
+		width, height = DefaultIconSize, DefaultIconSize
+	case width <= 0:
+		width = height
+	case height <= 0:
+		height = width
+	}
+
+	anchorX, anchorY := icon.Anchor.X, icon.Anchor.Y
+	if anchorX == 0 && anchorY == 0 {
+		anchorX, anchorY = width/2, height
+	}
+
+	return &MarkerIcon{
+		URL:     strings.TrimSpace(*icon.URL),
+		Width:   width,
+		Height:  height,
+		AnchorX: anchorX,
+		AnchorY: anchorY,
+	}
+}
+
+// isLocalIcon reports whether an icon URL refers to a local file rather than a
+// network resource or inline data.
+func isLocalIcon(url string) bool {
+	return !strings.Contains(url, "://") && !strings.HasPrefix(url, "data:")
+}
+
+// iconDataURI reads a local image and encodes it as a data URI, typed by its file
+// extension or, failing that, its content.
+func iconDataURI(file string) (string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	mediaType := mime.TypeByExtension(strings.ToLower(filepath.Ext(file)))
+	if mediaType == "" {
+		mediaType = http.DetectContentType(content)
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(content), nil
+}
@@ -0,0 +1,86 @@
+package mapgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+func TestMarkerIconFor(t *testing.T) {
+	url := "https://example.com/pin.png"
+	blank := " "
+
+	tests := []struct {
+		name string
+		icon config.IconConfig
+		want *MarkerIcon
+	}{
+		{name: "no url", icon: config.IconConfig{Color: "red"}, want: nil},
+		{name: "blank url", icon: config.IconConfig{URL: &blank}, want: nil},
+		{
+			name: "default size and anchor",
+			icon: config.IconConfig{URL: &url},
+			want: &MarkerIcon{URL: url, Width: 32, Height: 32, AnchorX: 16, AnchorY: 32},
+		},
+		{
+			name: "width only",
+			icon: config.IconConfig{URL: &url, Size: config.SizeConfig{Width: 24}},
+			want: &MarkerIcon{URL: url, Width: 24, Height: 24, AnchorX: 12, AnchorY: 24},
+		},
+		{
+			name: "configured size and anchor",
+			icon: config.IconConfig{URL: &url, Size: config.SizeConfig{Width: 20, Height: 40}, Anchor: config.PointConfig{X: 10, Y: 20}},
+			want: &MarkerIcon{URL: url, Width: 20, Height: 40, AnchorX: 10, AnchorY: 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := markerIconFor(tt.icon)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("markerIconFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkerIconsSelfContained(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "pin.svg")
+	if err := os.WriteFile(file, []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	remote := "https://example.com/end.png"
+	missing := filepath.Join(t.TempDir(), "missing.png")
+
+	cfg := &config.Config{Markers: config.MarkersConfig{
+		Start: config.MarkerStyleConfig{Icon: config.IconConfig{URL: &file}},
+		End:   config.MarkerStyleConfig{Icon: config.IconConfig{URL: &remote}},
+	}}
+
+	icons, err := markerIconsFor(cfg)
+	if err != nil {
+		t.Fatalf("markerIconsFor() error = %v", err)
+	}
+	if icons.Default != nil || icons.Start.URL != file {
+		t.Errorf("markerIconsFor() = %+v, want the start icon file kept as a URL", icons)
+	}
+
+	cfg.Output.SelfContained = true
+	if icons, err = markerIconsFor(cfg); err != nil {
+		t.Fatalf("markerIconsFor() self-contained error = %v", err)
+	}
+	if !strings.HasPrefix(icons.Start.URL, "data:image/svg+xml;base64,") {
+		t.Errorf("start icon URL = %q, want an embedded SVG", icons.Start.URL)
+	}
+	if icons.End.URL != remote {
+		t.Errorf("end icon URL = %q, want remote URL unchanged", icons.End.URL)
+	}
+
+	cfg.Markers.Default.Icon.URL = &missing
+	if _, err := markerIconsFor(cfg); err == nil || !strings.Contains(err.Error(), "default marker icon") {
+		t.Errorf("markerIconsFor() with a missing file error = %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package mapgen
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/i18n"
+)
+
+// InfoWindowPoint is the data an info_windows.template is executed with for each
+// point. All Point fields are promoted, so the template can use {{.Title}},
+// {{.Timestamp}}, {{.Latitude}}, {{.Description}}, {{.Category}}, {{.User}}, and
+// {{.Elevation}} directly.
+//
+// @struct InfoWindowPoint
+// @description Point fields and track metadata for a custom info window
+// @property Point gps.Point GPS point, with an empty title replaced by "Point N" in the page language
+// @property Number int Position of the point in the track, starting at 1
+// @property Total int Number of points in the track
+// @property Heading string Compass direction of travel at the point (empty when stationary)
+// @property Weather string Weather of the hour, on the first point of each hour (empty otherwise)
+type InfoWindowPoint struct {
+	gps.Point        // @field Point GPS point shown in the info window
+	Number    int    // @field Number One-based position of the point in the track
+	Total     int    // @field Total Number of points in the track
+	Heading   string // @field Heading Compass direction of travel at the point
+	Weather   string // @field Weather Hourly weather annotation, such as "14 °C, Light rain"
+}
+
+// infoWindowContents renders the configured info window template for every point.
+// The template is an html/template, so point text is escaped as in the rest of the
+// page. It returns nil when no template is configured, keeping the built-in content.
+//
+// @function infoWindowContents
+// @description Renders a custom info window for each GPS point
+// @param source string info_windows.template text
+// @param points gps.Points GPS points in track order
+// @param headings []string Compass direction of travel at each point
+// @param weather map[int]string Hourly weather annotations by point index (nil without weather)
+// @param locale *i18n.Locale Page language naming untitled points
+// @return []template.HTML Info window HTML in point order (nil without a template)
+// @return error Error if the template cannot be parsed or executed
+// @internal true
+func infoWindowContents(source string, points gps.Points, headings []string, weather map[int]string, locale *i18n.Locale) ([]template.HTML, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New("info_window").Parse(source)
+	if err != nil {
+		return nil, configErrorf("invalid info window template: %w", err)
+	}
+
+	contents := make([]template.HTML, len(points))
+	var b strings.Builder
+	for i, point := range points {
+		if point.Title == "" {
+			point.Title = fmt.Sprintf("%s %d", locale.T("point"), i+1)
+		}
+		data := InfoWindowPoint{Point: point, Number: i + 1, Total: len(points), Heading: headings[i], Weather: weather[i]}
+
+		b.Reset()
+		if err := tmpl.Execute(&b, data); err != nil {
+			return nil, fmt.Errorf("cannot render info window for point %d: %w", i+1, err)
+		}
+		contents[i] = template.HTML(strings.TrimSpace(b.String()))
+	}
+	return contents, nil
+}
@@ -0,0 +1,109 @@
+package mapgen
+
+import (
+	"bytes"
+	"html/template"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/i18n"
+)
+
+func TestInfoWindowContents(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41, Title: "Start", Category: "park"},
+		{Timestamp: testTime.Add(time.Minute), Latitude: 37.78, Longitude: -122.40, Description: "<b>Lunch</b>", User: "alice"},
+	}
+	headings := []string{"NE", ""}
+	weather := map[int]string{1: "12 °C, Overcast"}
+	english, _ := i18n.Lookup("")
+
+	tests := []struct {
+		name     string
+		template string
+		want     []template.HTML
+		wantErr  bool
+	}{
+		{name: "no template", template: "  \n", want: nil},
+		{
+			name:     "point fields",
+			template: `<h3>{{.Title}}</h3> {{.Timestamp.Format "15:04"}} {{.Category}}`,
+			want:     []template.HTML{"<h3>Start</h3> 10:00 park", "<h3>Point 2</h3> 10:01"},
+		},
+		{
+			name:     "metadata",
+			template: "{{.Number}}/{{.Total}} {{.Heading}} {{.User}}\n",
+			want:     []template.HTML{"1/2 NE", "2/2  alice"},
+		},
+		{
+			name:     "weather",
+			template: "{{with .Weather}}Weather: {{.}}{{end}}",
+			want:     []template.HTML{"", "Weather: 12 °C, Overcast"},
+		},
+		{
+			name:     "escapes point text",
+			template: "{{.Description}}",
+			want:     []template.HTML{"", "&lt;b&gt;Lunch&lt;/b&gt;"},
+		},
+		{name: "parse error", template: "{{.Title", wantErr: true},
+		{name: "unknown field", template: "{{.Speed}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := infoWindowContents(tt.template, points, headings, weather, english)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("infoWindowContents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("infoWindowContents() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("infoWindowContents()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	// Untitled points are named in the page language
+	german, _ := i18n.Lookup("de")
+	got, err := infoWindowContents("{{.Title}}", points, headings, weather, german)
+	if err != nil || got[1] != "Punkt 2" {
+		t.Errorf("infoWindowContents(de) = %q, %v, want Punkt 2", got, err)
+	}
+}
+
+func TestExampleConfigLocalizedInfoWindows(t *testing.T) {
+	cfg, err := config.Load(filepath.Join("..", "..", "config.yaml"))
+	if err != nil {
+		t.Fatalf("Load(config.yaml) error = %v", err)
+	}
+	cfg.Map.Language = "de"
+
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41, Title: "Start"},
+		{Timestamp: testTime.Add(time.Minute), Latitude: 37.78, Longitude: -122.40},
+	}
+	var buf bytes.Buffer
+	if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+		t.Fatalf("GenerateTo() error = %v", err)
+	}
+	html := buf.String()
+
+	// The built-in popup is used, with its labels from the German messages
+	if strings.Contains(html, "const infoWindowContents") {
+		t.Error("example config renders info windows from a template, want the built-in content")
+	}
+	for _, want := range []string{`${messages.time}`, `"time":"Zeit"`, `"location":"Standort"`, `"sequence":"Reihenfolge"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("German page missing %s", want)
+		}
+	}
+}
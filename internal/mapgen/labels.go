@@ -0,0 +1,152 @@
+package mapgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/i18n"
+)
+
+// Built-in label style, used for settings a marker label leaves empty.
+const (
+	DefaultLabelColor  = "white"
+	DefaultLabelFamily = "Arial"
+	DefaultLabelWeight = "bold"
+)
+
+// LabelStyle is the resolved text style of a marker label.
+//
+// @struct LabelStyle
+// @description Color and font of the text drawn on a marker
+// @property Color string CSS text color
+// @property Family string CSS font family
+// @property Size float64 Font size in pixels (0 scales with the marker size)
+// @property Weight string CSS font weight
+type LabelStyle struct {
+	Color  string  `json:"color"`  // @field Color CSS text color
+	Family string  `json:"family"` // @field Family CSS font family
+	Size   float64 `json:"size"`   // @field Size Font size in pixels (0 for a third of the marker size)
+	Weight string  `json:"weight"` // @field Weight CSS font weight
+}
+
+// MarkerLabels holds the label text of every point and the label styles of the
+// start, end, and other markers.
+type MarkerLabels struct {
+	Text    []string   `json:"text"`    // Label of each point in track order ("" for none)
+	Default LabelStyle `json:"default"` // Regular points
+	Start   LabelStyle `json:"start"`   // First point
+	End     LabelStyle `json:"end"`     // Last point
+}
+
+// markerLabelsFor resolves the label of each marker from markers.default.label,
+// markers.start.label, and markers.end.label. A label's text setting is a Go
+// template executed with the same point data as info window templates, so
+// "{{.Timestamp.Format \"15:04\"}}" labels markers with the time of day. Without
+// text, show_sequence labels the point with its position in the track. A label
+// with no settings at all keeps the built-in S, sequence number, and E labels.
+//
+// @function markerLabelsFor
+// @description Renders the label text and style of each marker
+// @param cfg *config.MarkersConfig Marker label settings
+// @param points gps.Points GPS points in track order
+// @param headings []string Compass direction of travel at each point
+// @param locale *i18n.Locale Page language naming untitled points
+// @return MarkerLabels Label text per point and styles per marker kind
+// @return error Error if a label template or font size is invalid
+// @internal true
+func markerLabelsFor(cfg *config.MarkersConfig, points gps.Points, headings []string, locale *i18n.Locale) (MarkerLabels, error) {
+	labels := MarkerLabels{Text: make([]string, len(points))}
+	kinds := []struct {
+		name    string
+		label   config.LabelConfig
+		builtIn func(number int) string
+		style   *LabelStyle
+		text    func(InfoWindowPoint) (string, error)
+	}{
+		{name: "default", label: cfg.Default.Label, builtIn: strconv.Itoa, style: &labels.Default},
+		{name: "start", label: cfg.Start.Label, builtIn: func(int) string { return "S" }, style: &labels.Start},
+		{name: "end", label: cfg.End.Label, builtIn: func(int) string { return "E" }, style: &labels.End},
+	}
+	for i := range kinds {
+		kind := &kinds[i]
+		style, err := labelStyleFor(kind.label.Font, kind.label.Color)
+		if err != nil {
+			return labels, configErrorf("invalid %s marker label: %w", kind.name, err)
+		}
+		*kind.style = style
+		if kind.text, err = labelTextFunc(kind.label, kind.builtIn); err != nil {
+			return labels, configErrorf("invalid %s marker label: %w", kind.name, err)
+		}
+	}
+
+	for i, point := range points {
+		// A single point is the start of the track, matching customIcon in the page
+		kind := kinds[0]
+		if i == 0 {
+			kind = kinds[1]
+		} else if i == len(points)-1 {
+			kind = kinds[2]
+		}
+		if point.Title == "" {
+			point.Title = fmt.Sprintf("%s %d", locale.T("point"), i+1)
+		}
+
+		text, err := kind.text(InfoWindowPoint{Point: point, Number: i + 1, Total: len(points), Heading: headings[i]})
+		if err != nil {
+			return labels, fmt.Errorf("cannot render %s marker label for point %d: %w", kind.name, i+1, err)
+		}
+		labels.Text[i] = text
+	}
+	return labels, nil
+}
+
+// labelTextFunc returns the function producing the label text of a point: the
+// executed text template, the sequence number, nothing, or for an unconfigured
+// label the built-in text.
+func labelTextFunc(label config.LabelConfig, builtIn func(number int) string) (func(InfoWindowPoint) (string, error), error) {
+	switch {
+	case label.Text != nil:
+		tmpl, err := template.New("label").Parse(*label.Text)
+		if err != nil {
+			return nil, err
+		}
+		return func(point InfoWindowPoint) (string, error) {
+			var b strings.Builder
+			err := tmpl.Execute(&b, point)
+			return strings.TrimSpace(b.String()), err
+		}, nil
+	case label.ShowSequence:
+		return func(point InfoWindowPoint) (string, error) { return strconv.Itoa(point.Number), nil }, nil
+	case label == config.LabelConfig{}:
+		return func(point InfoWindowPoint) (string, error) { return builtIn(point.Number), nil }, nil
+	default:
+		return func(InfoWindowPoint) (string, error) { return "", nil }, nil
+	}
+}
+
+// labelStyleFor fills in the built-in style for empty font and color settings. Font
+// sizes are pixels, such as "12px" or "12", since the markers are sized in pixels.
+func labelStyleFor(font config.FontConfig, color string) (LabelStyle, error) {
+	style := LabelStyle{Color: color, Family: font.Family, Weight: font.Weight}
+	if style.Color == "" {
+		style.Color = DefaultLabelColor
+	}
+	if style.Family == "" {
+		style.Family = DefaultLabelFamily
+	}
+	if style.Weight == "" {
+		style.Weight = DefaultLabelWeight
+	}
+	if size := strings.TrimSpace(font.Size); size != "" {
+		pixels, err := strconv.ParseFloat(strings.TrimSuffix(size, "px"), 64)
+		if err != nil || pixels <= 0 {
+			return style, configErrorf("font size %q is not a pixel size such as 12px", font.Size)
+		}
+		style.Size = pixels
+	}
+	return style, nil
+}
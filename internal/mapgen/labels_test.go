@@ -0,0 +1,134 @@
+package mapgen
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/i18n"
+)
+
+func TestMarkerLabelsFor(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 9, 5, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Title: "Home"},
+		{Timestamp: testTime.Add(30 * time.Minute)},
+		{Timestamp: testTime.Add(time.Hour), Title: "Cafe"},
+		{Timestamp: testTime.Add(2 * time.Hour), Title: "Office"},
+	}
+	headings := []string{"N", "N", "E", ""}
+	english, _ := i18n.Lookup("")
+	clock := `{{.Timestamp.Format "15:04"}}`
+	title := "{{.Title}}"
+	start := "START"
+	empty := ""
+
+	tests := []struct {
+		name    string
+		markers config.MarkersConfig
+		want    []string
+	}{
+		{name: "built-in labels", want: []string{"S", "2", "3", "E"}},
+		{
+			name: "sequence numbers",
+			markers: config.MarkersConfig{
+				Default: config.MarkerStyleConfig{Label: config.LabelConfig{ShowSequence: true}},
+				Start:   config.MarkerStyleConfig{Label: config.LabelConfig{Text: &start}},
+				End:     config.MarkerStyleConfig{Label: config.LabelConfig{ShowSequence: true}},
+			},
+			want: []string{"START", "2", "3", "4"},
+		},
+		{
+			name: "time of day template",
+			markers: config.MarkersConfig{
+				Default: config.MarkerStyleConfig{Label: config.LabelConfig{Text: &clock}},
+				End:     config.MarkerStyleConfig{Label: config.LabelConfig{Text: &title}},
+			},
+			want: []string{"S", "09:35", "10:05", "Office"},
+		},
+		{
+			name: "point fallback title",
+			markers: config.MarkersConfig{
+				Default: config.MarkerStyleConfig{Label: config.LabelConfig{Text: &title}},
+			},
+			want: []string{"S", "Point 2", "Cafe", "E"},
+		},
+		{
+			name: "labels turned off",
+			markers: config.MarkersConfig{
+				Default: config.MarkerStyleConfig{Label: config.LabelConfig{Color: "white"}},
+				Start:   config.MarkerStyleConfig{Label: config.LabelConfig{Text: &empty}},
+			},
+			want: []string{"", "", "", "E"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, err := markerLabelsFor(&tt.markers, points, headings, english)
+			if err != nil {
+				t.Fatalf("markerLabelsFor() error = %v", err)
+			}
+			if !reflect.DeepEqual(labels.Text, tt.want) {
+				t.Errorf("markerLabelsFor() text = %q, want %q", labels.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkerLabelsForErrors(t *testing.T) {
+	english, _ := i18n.Lookup("")
+	points := gps.Points{{Timestamp: time.Date(2025, 10, 28, 9, 0, 0, 0, time.UTC)}}
+	unclosed := "{{.Title"
+	unknown := "{{.Speed}}"
+
+	tests := []struct {
+		name  string
+		label config.LabelConfig
+	}{
+		{name: "template syntax", label: config.LabelConfig{Text: &unclosed}},
+		{name: "unknown field", label: config.LabelConfig{Text: &unknown}},
+		{name: "font size units", label: config.LabelConfig{Font: config.FontConfig{Size: "1em"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markers := config.MarkersConfig{Start: config.MarkerStyleConfig{Label: tt.label}}
+			if _, err := markerLabelsFor(&markers, points, []string{""}, english); err == nil {
+				t.Error("markerLabelsFor() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestLabelStyleFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		font  config.FontConfig
+		color string
+		want  LabelStyle
+	}{
+		{name: "built-in style", want: LabelStyle{Color: "white", Family: "Arial", Weight: "bold"}},
+		{
+			name:  "configured style",
+			font:  config.FontConfig{Family: "Georgia, serif", Size: "14px", Weight: "normal"},
+			color: "#333",
+			want:  LabelStyle{Color: "#333", Family: "Georgia, serif", Size: 14, Weight: "normal"},
+		},
+		{name: "unitless size", font: config.FontConfig{Size: "9.5"}, want: LabelStyle{Color: "white", Family: "Arial", Size: 9.5, Weight: "bold"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := labelStyleFor(tt.font, tt.color)
+			if err != nil {
+				t.Fatalf("labelStyleFor() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("labelStyleFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
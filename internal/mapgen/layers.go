@@ -0,0 +1,135 @@
+package mapgen
+
+import ()
+
+// Layer names accepted in map.layer_order.
+const (
+	LayerGeofences = "geofences" // Geofence boundary circles and polygons
+	LayerReference = "reference" // Reference routes drawn for comparison
+	LayerTracks    = "tracks"    // Secondary tracks (other users, other files)
+	LayerPath      = "path"      // Primary track path
+	LayerArrows    = "arrows"    // Direction arrows along the primary path
+	LayerMarkers   = "markers"   // GPS point markers
+)
+
+// DefaultLayerOrder lists map layers from bottom to top. The primary path is drawn
+// above reference routes and secondary tracks so it is never hidden by them.
+var DefaultLayerOrder = []string{
+	LayerGeofences,
+	LayerReference,
+	LayerTracks,
+	LayerPath,
+	LayerArrows,
+	LayerMarkers,
+}
+
+// layerZIndexStep leaves room between layers for per-feature offsets.
+const layerZIndexStep = 10
+
+// layerZIndices assigns a z-index to every known layer from a bottom-to-top order.
+//
+// @function layerZIndices
+// @description Converts configured layer ordering into Google Maps zIndex values
+// @param order []string Layer names from bottom to top (empty uses DefaultLayerOrder)
+// @return map[string]int Z-index for every known layer
+// @return error Error if a layer name is unknown or repeated
+// @internal true
+// @logic Layers omitted from the configured order keep their default relative order below the listed ones
+func layerZIndices(order []string) (map[string]int, error) {
+	known := make(map[string]bool, len(DefaultLayerOrder))
+	for _, name := range DefaultLayerOrder {
+		known[name] = true
+	}
+
+	// Validate the configured order before assigning indices
+	listed := make(map[string]bool, len(order))
+	for _, name := range order {
+		if !known[name] {
+			return nil, configErrorf("unknown map layer %q in layer_order", name)
+		}
+		if listed[name] {
+			return nil, configErrorf("map layer %q listed more than once in layer_order", name)
+		}
+		listed[name] = true
+	}
+
+	// Unlisted layers go underneath, followed by the configured order
+	var resolved []string
+	for _, name := range DefaultLayerOrder {
+		if !listed[name] {
+			resolved = append(resolved, name)
+		}
+	}
+	resolved = append(resolved, order...)
+
+	indices := make(map[string]int, len(resolved))
+	for i, name := range resolved {
+		indices[name] = (i + 1) * layerZIndexStep
+	}
+	return indices, nil
+}
+
+// LayerHeatmap names the density heatmap in the layer control. The heatmap has no
+// place in map.layer_order since each provider draws it in its own overlay.
+const LayerHeatmap = "heatmap"
+
+// LayerMilestones names the distance milestone markers in the layer control. They
+// are drawn with the path rather than as a layer of map.layer_order.
+const LayerMilestones = "milestones"
+
+// userLayerPrefix starts the layer control name of each user's points, such as
+// "user:alice", so users share the hidden layer set with the other layers.
+const userLayerPrefix = "user:"
+
+// LayerToggle is a checkbox of the layer control showing or hiding one layer.
+//
+// @struct LayerToggle
+// @description Layer control entry for a drawn layer or a user's points
+// @property Layer string Layer name: markers, path, arrows, milestones, heatmap, geofences, or user:<name>
+// @property Label string Checkbox text
+type LayerToggle struct {
+	Layer string // @field Layer Layer name toggled by the checkbox
+	Label string // @field Label Checkbox text
+}
+
+// layerToggles lists the layers drawn on the page in the layer control, followed by
+// one entry per user of a shared multi-user track, labelled in the page language.
+// Direction arrows are left out for Cesium, which does not draw them.
+//
+// @function layerToggles
+// @description Builds the layer control from the enabled map features
+// @param data *MapData Prepared page data deciding which layers are drawn, with the page locale
+// @return []LayerToggle Layer checkboxes in display order (nil when control is off)
+// @internal true
+func layerToggles(data *MapData) []LayerToggle {
+	cfg := data.Config
+	if !cfg.Map.Controls.LayerToggles {
+		return nil
+	}
+
+	var toggles []LayerToggle
+	if data.Trail {
+		toggles = append(toggles, LayerToggle{LayerMarkers, data.Locale.T("markers")})
+		if cfg.Path.Enabled {
+			toggles = append(toggles, LayerToggle{LayerPath, data.Locale.T("path")})
+			if data.Arrows != nil && data.Cesium == nil {
+				toggles = append(toggles, LayerToggle{LayerArrows, data.Locale.T("direction_arrows")})
+			}
+			if data.Milestones != nil {
+				toggles = append(toggles, LayerToggle{LayerMilestones, data.Locale.T("milestones")})
+			}
+		}
+	}
+	if data.Heatmap != nil {
+		toggles = append(toggles, LayerToggle{LayerHeatmap, data.Locale.T("heatmap")})
+	}
+	if cfg.Geofences.ShowBoundaries && data.Fences != nil {
+		toggles = append(toggles, LayerToggle{LayerGeofences, data.Locale.T("geofences")})
+	}
+	if users := data.Points.Users(); data.Trail && len(users) > 1 {
+		for _, user := range users {
+			toggles = append(toggles, LayerToggle{userLayerPrefix + user, user})
+		}
+	}
+	return toggles
+}
@@ -0,0 +1,158 @@
+package mapgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/geofence"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/i18n"
+)
+
+func TestLayerZIndices(t *testing.T) {
+	tests := []struct {
+		name    string
+		order   []string
+		below   string
+		above   string
+		wantErr bool
+	}{
+		{
+			name:  "default order keeps path above secondary tracks",
+			order: nil,
+			below: LayerTracks,
+			above: LayerPath,
+		},
+		{
+			name:  "custom order puts geofences on top",
+			order: []string{LayerPath, LayerGeofences},
+			below: LayerPath,
+			above: LayerGeofences,
+		},
+		{
+			name:  "unlisted layers stay beneath listed ones",
+			order: []string{LayerReference},
+			below: LayerMarkers,
+			above: LayerReference,
+		},
+		{
+			name:    "unknown layer",
+			order:   []string{"clouds"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate layer",
+			order:   []string{LayerPath, LayerPath},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indices, err := layerZIndices(tt.order)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("layerZIndices() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(indices) != len(DefaultLayerOrder) {
+				t.Errorf("layerZIndices() returned %d layers, want %d", len(indices), len(DefaultLayerOrder))
+			}
+			if indices[tt.below] >= indices[tt.above] {
+				t.Errorf("layer %s (%d) should be below %s (%d)", tt.below, indices[tt.below], tt.above, indices[tt.above])
+			}
+		})
+	}
+}
+
+func TestGenerateLayerOrder(t *testing.T) {
+	points := gps.Points{{Timestamp: time.Now(), Latitude: 37.77, Longitude: -122.41}}
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{LayerOrder: []string{LayerMarkers, LayerPath}},
+		Path:       config.PathConfig{Enabled: true},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "layers.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	// Path is last in the configured order, so it receives the highest z-index
+	if !strings.Contains(string(content), "zIndex:  60") {
+		t.Error("Generated HTML missing top z-index for the path layer")
+	}
+
+	cfg.Map.LayerOrder = []string{"unknown"}
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err == nil {
+		t.Error("Generate() with unknown layer error = nil, want error")
+	}
+}
+
+func TestLayerToggles(t *testing.T) {
+	points := gps.Points{{User: "bob"}, {User: "alice"}, {User: "bob"}}
+	fences := []geofence.Fence{{Name: "Home"}}
+
+	tests := []struct {
+		name string
+		data MapData
+		want []string
+	}{
+		{name: "control off", data: MapData{Trail: true, Config: &config.Config{}}, want: nil},
+		{
+			name: "trail layers",
+			data: MapData{Trail: true, Arrows: []Arrow{{}}, Milestones: []Milestone{{}}, Config: &config.Config{
+				Map:  config.MapConfig{Controls: config.ControlsConfig{LayerToggles: true}},
+				Path: config.PathConfig{Enabled: true},
+			}},
+			want: []string{LayerMarkers, LayerPath, LayerArrows, LayerMilestones},
+		},
+		{
+			name: "cesium draws no arrows",
+			data: MapData{Trail: true, Arrows: []Arrow{{}}, Cesium: &Cesium{}, Config: &config.Config{
+				Map:  config.MapConfig{Controls: config.ControlsConfig{LayerToggles: true}},
+				Path: config.PathConfig{Enabled: true},
+			}},
+			want: []string{LayerMarkers, LayerPath},
+		},
+		{
+			name: "heatmap geofences and users",
+			data: MapData{Trail: true, Points: points, Heatmap: points.Density(0), Fences: fences, Config: &config.Config{
+				Map:       config.MapConfig{Controls: config.ControlsConfig{LayerToggles: true}},
+				Geofences: config.GeofencesConfig{ShowBoundaries: true},
+			}},
+			want: []string{LayerMarkers, LayerHeatmap, LayerGeofences, "user:alice", "user:bob"},
+		},
+		{
+			name: "heatmap only",
+			data: MapData{Points: points, Heatmap: points.Density(0), Fences: fences, Config: &config.Config{
+				Map: config.MapConfig{Controls: config.ControlsConfig{LayerToggles: true}},
+			}},
+			want: []string{LayerHeatmap},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.data.Locale, _ = i18n.Lookup("")
+			var got []string
+			for _, toggle := range layerToggles(&tt.data) {
+				got = append(got, toggle.Layer)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("layerToggles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
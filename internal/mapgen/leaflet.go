@@ -0,0 +1,609 @@
+package mapgen
+
+import "github.com/saratily/geo-chrono/internal/tiles"
+
+// ProviderLeaflet renders maps with Leaflet and raster tiles (OpenStreetMap by
+// default). It needs no API key.
+const ProviderLeaflet = "leaflet"
+
+// Defaults for Leaflet maps, used by the leaflet provider and the Google fallback.
+const (
+	DefaultTileURL     = "https://tile.openstreetmap.org/{z}/{x}/{y}.png"
+	DefaultAttribution = "&copy; OpenStreetMap contributors"
+
+	// NoTiles as the tile URL draws the track on a blank background, so a
+	// self-contained page makes no network requests at all.
+	NoTiles = "none"
+
+	leafletScriptURL = "https://unpkg.com/leaflet@1.9.4/dist/leaflet.js"
+	leafletStyleURL  = "https://unpkg.com/leaflet@1.9.4/dist/leaflet.css"
+)
+
+// Leaflet holds the resolved tile source and library assets for a Leaflet map.
+//
+// @struct Leaflet
+// @description Tile layer and Leaflet library settings
+// @property TileURL string Tile URL template with {z}, {x}, {y} placeholders (empty for no tiles)
+// @property Attribution string Attribution shown in the map corner
+// @property Assets Assets Leaflet library script and stylesheet
+// @property Offline *tiles.Bundle Tiles embedded in the page instead of loaded from TileURL (nil when online)
+type Leaflet struct {
+	TileURL     string        // @field TileURL Tile URL template (empty for a blank background)
+	Attribution string        // @field Attribution Tile provider attribution
+	Assets                    // @field Assets Leaflet library script and stylesheet
+	Offline     *tiles.Bundle // @field Offline Embedded tiles for maps without network access
+}
+
+// leafletFor resolves a Leaflet tile source, applying the OpenStreetMap defaults.
+// A tile URL of NoTiles disables the tile layer.
+func leafletFor(tileURL, attribution string) *Leaflet {
+	leaflet := &Leaflet{Assets: Assets{ScriptURL: leafletScriptURL, StyleURL: leafletStyleURL}}
+	leaflet.TileURL, leaflet.Attribution = tileSource(tileURL, attribution)
+	return leaflet
+}
+
+// tileSource applies the OpenStreetMap defaults to a raster tile URL template and
+// its attribution. A tile URL of NoTiles returns empty strings.
+func tileSource(tileURL, attribution string) (string, string) {
+	switch tileURL {
+	case "":
+		tileURL = DefaultTileURL
+	case NoTiles:
+		return "", ""
+	}
+	if attribution == "" {
+		attribution = DefaultAttribution
+	}
+	return tileURL, attribution
+}
+
+// SetTiles sets the tiles embedded in leaflet maps, so they need no tile server
+// once generated. Pass nil to load tiles from map.tile_url.
+func (g *Generator) SetTiles(bundle *tiles.Bundle) {
+	g.tiles = bundle
+}
+
+// onlineTileURL returns the tile URL the map loads tiles from, which is empty
+// when the tiles are embedded.
+func (l *Leaflet) onlineTileURL() string {
+	if l.Offline != nil {
+		return ""
+	}
+	return l.TileURL
+}
+
+// hosts lists the remote hosts the Leaflet map loads resources from.
+func (l *Leaflet) hosts() []string {
+	return ExternalHosts([]byte(l.onlineTileURL() + " " + l.ScriptURL + " " + l.StyleURL))
+}
+
+// tileHosts lists the remote hosts serving map tiles, which cannot be embedded.
+func (l *Leaflet) tileHosts() []string {
+	return ExternalHosts([]byte(l.onlineTileURL()))
+}
+
+// providerTemplate returns the map-head, map-script, and map-loader definitions for
+// the configured map provider, appended to the shared page template.
+func (g *Generator) providerTemplate() string {
+	if g.config == nil {
+		return googleTemplate
+	}
+	switch g.config.Map.Provider {
+	case ProviderLeaflet:
+		return leafletTemplate
+	case ProviderMapLibre:
+		return mapLibreTemplate
+	case ProviderCesium:
+		return cesiumTemplate
+	default:
+		return googleTemplate
+	}
+}
+
+// leafletTemplate defines the Leaflet parts of the page: the map-head, map-script,
+// and map-loader templates invoked by the shared page template. It draws the same
+// overlays as the Google Maps page with Leaflet equivalents.
+const leafletTemplate = `{{define "map-head"}}
+    {{if .Leaflet.InlineStyle}}
+    <style>{{.Leaflet.InlineStyle}}</style>
+    {{else}}
+    <link rel="stylesheet" href="{{.Leaflet.StyleURL}}">
+    {{end}}
+    <style>
+        .marker-icon {
+            border: 2px solid #000;
+            border-radius: 9999px;
+            box-sizing: border-box;
+            color: white;
+            font-weight: bold;
+            text-align: center;
+        }
+        .direction-arrow {
+            font-size: 14px;
+            line-height: 14px;
+            text-align: center;
+        }
+        .geofence-label {
+            color: #A34E00;
+            font: bold 12px Arial, sans-serif;
+            text-shadow: 0 0 3px white, 0 0 3px white;
+            white-space: nowrap;
+            pointer-events: none;
+        }
+    </style>
+{{end}}
+
+{{define "map-script"}}
+        function initMap() {
+            if (points.length === 0) {
+                document.getElementById('map').innerHTML = '<div style="text-align: center; padding: 50px; color: #666;">' + message('no_points') + '</div>';
+                return;
+            }
+
+            map = L.map('map', {
+                zoomControl: {{.Config.Map.Controls.ZoomControl}},
+                {{if .Restriction}}
+                maxBounds: [[{{.Restriction.South}}, {{.Restriction.West}}], [{{.Restriction.North}}, {{.Restriction.East}}]],
+                maxBoundsViscosity: {{if .Restriction.StrictBounds}}1.0{{else}}0.5{{end}},
+                {{end}}
+            }).setView([{{.Center.Latitude}}, {{.Center.Longitude}}], {{.Zoom}});
+
+            {{if .Leaflet.Offline}}
+            // Tiles embedded in the page by "z/x/y" key; tiles outside the bundle are blank
+            const offlineTiles = {{.Leaflet.Offline.Tiles}};
+            const OfflineTileLayer = L.TileLayer.extend({
+                getTileUrl: coords => offlineTiles[coords.z + '/' + coords.x + '/' + coords.y] || L.Util.emptyImageUrl
+            });
+            new OfflineTileLayer('', {
+                attribution: "{{.Leaflet.Attribution}}",
+                minNativeZoom: {{.Leaflet.Offline.MinZoom}},
+                maxNativeZoom: {{.Leaflet.Offline.MaxZoom}},
+                maxZoom: 19
+            }).addTo(map);
+            {{else if .Leaflet.TileURL}}
+            L.tileLayer("{{.Leaflet.TileURL}}", {
+                attribution: "{{.Leaflet.Attribution}}",
+                maxZoom: 19
+            }).addTo(map);
+            {{end}}
+
+            {{if .Config.Map.Controls.ScaleControl}}
+            L.control.scale().addTo(map);
+            {{end}}
+
+            // Stack overlays in the configured layer order
+            Object.entries({{.ZIndex}}).forEach(([layer, zIndex]) => {
+                map.createPane(layer).style.zIndex = 400 + zIndex;
+            });
+
+            {{if .Heatmap}}
+            // Render point density, instead of or next to individual markers
+            addHeatmap();
+            {{end}}
+
+            {{if .Trail}}
+            addMarkers();
+            {{if .LiveURL}}
+            listenForPoints();
+            {{end}}
+            {{if .Config.Path.Enabled}}
+            addWalkingPath();
+            {{end}}
+            {{if .Milestones}}
+            addMilestones();
+            {{end}}
+            {{end}}
+
+            {{if and .Config.Geofences.ShowBoundaries .Fences}}
+            addGeofences();
+            {{end}}
+
+            {{if .Reference}}
+            addReferenceRoute();
+            {{end}}
+
+            {{if .Encounters}}
+            addEncounters();
+            {{end}}
+
+            {{if .Meeting}}
+            addMeetingPoint();
+            {{end}}
+
+            {{if .Config.Map.Controls.MeasureTool}}
+            // Clicks on the map add points to the measure tool while it is on
+            map.on('click', event => addMeasurePoint(event.latlng.lat, event.latlng.lng));
+            {{end}}
+
+            {{if .Config.Map.Controls.Permalink}}
+            // Keep the link in step with the view, and reopen the view saved in it
+            map.on('moveend', updatePermalink);
+            if (restorePermalink()) {
+                return;
+            }
+            {{end}}
+
+            fitMapToBounds();
+        }
+
+        {{if .Config.Map.Controls.MeasureTool}}
+        let measureLine;
+
+        function drawMeasureLine(measured) {
+            if (!measureLine) {
+                measureLine = L.polyline([], { color: '#000', weight: 3, dashArray: '6 6', interactive: false }).addTo(map);
+            }
+            measureLine.setLatLngs(measured.map(point => [point.lat, point.lng]));
+        }
+        {{end}}
+
+        {{if .Heatmap}}
+        let heatmap;
+
+        function addHeatmap() {
+            // Leaflet has no built-in heatmap; draw each density cell as a translucent
+            // circle whose opacity grows with the number of points in it
+            const maxWeight = Math.max(...heatmapCells.map(cell => cell[2]));
+            heatmap = L.layerGroup(heatmapCells.map(cell => L.circleMarker([cell[0], cell[1]], {
+                pane: 'path',
+                radius: {{if .Config.Heatmap.Radius}}{{.Config.Heatmap.Radius}}{{else}}20{{end}} / 2,
+                stroke: false,
+                fillColor: '#FF0000',
+                fillOpacity: {{if .Config.Heatmap.Opacity}}{{.Config.Heatmap.Opacity}}{{else}}0.6{{end}} * cell[2] / maxWeight
+            })));
+            setHeatmapVisible(!hiddenLayers.has('heatmap'));
+        }
+
+        function setHeatmapVisible(visible) {
+            if (heatmap && visible) {
+                heatmap.addTo(map);
+            } else if (heatmap) {
+                heatmap.remove();
+            }
+        }
+        {{end}}
+
+        {{if and .Config.Geofences.ShowBoundaries .Fences}}
+        function addGeofences() {
+            const style = { pane: 'geofences', color: '#FF8800', opacity: 0.9, weight: 2, fillColor: '#FF8800', fillOpacity: 0.15 };
+            geofences.forEach(fence => {
+                const shape = fence.center
+                    ? L.circle(fence.center, Object.assign({ radius: fence.radius }, style))
+                    : L.polygon(fence.polygon, style);
+                shape.bindTooltip(fence.name).addTo(map);
+
+                // Zero-sized icon whose text is centered on the label point
+                L.marker(fence.label, {
+                    pane: 'geofences',
+                    interactive: false,
+                    icon: L.divIcon({
+                        className: '',
+                        html: '<div class="geofence-label" style="transform: translate(-50%, -50%);">' + escapeHTML(fence.name) + '</div>',
+                        iconSize: [0, 0]
+                    })
+                }).addTo(map);
+            });
+        }
+
+        function setGeofencesVisible(visible) {
+            map.getPane('geofences').style.display = visible ? '' : 'none';
+        }
+        {{end}}
+
+        // dashStyle returns the polyline options drawing a solid, dashed, or dotted
+        // line; dots are dashes of one pixel with round ends
+        function dashStyle(pattern, weight) {
+            if (pattern === 'dashed') {
+                return { dashArray: (weight * 3) + ' ' + (weight * 2) };
+            }
+            if (pattern === 'dotted') {
+                return { dashArray: '1 ' + (weight * 2), lineCap: 'round' };
+            }
+            return {};
+        }
+
+        {{if .Reference}}
+        function addReferenceRoute() {
+            L.polyline(referenceRoute, {
+                pane: 'reference',
+                color: "{{.ReferenceColor}}",
+                opacity: 0.7,
+                weight: {{.Config.Path.Style.Weight}} + 2,
+                ...dashStyle("{{.Config.Compare.StrokePattern}}", {{.Config.Path.Style.Weight}} + 2)
+            }).addTo(map);
+        }
+        {{end}}
+
+        {{if .Encounters}}
+        function addEncounters() {
+            encounters.forEach(encounter => {
+                encounter.paths.forEach(path => {
+                    L.polyline(path, {
+                        pane: 'path',
+                        color: "{{.EncounterColor}}",
+                        opacity: 0.9,
+                        weight: {{.Config.Path.Style.Weight}} + 4
+                    }).addTo(map);
+                });
+
+                L.marker(encounter.closest, {
+                    pane: 'markers',
+                    title: encounter.users,
+                    icon: createMarkerIcon("{{.EncounterColor}}", "&", 28),
+                    zIndexOffset: 1000
+                }).bindPopup("<div style=\"font-family: Arial, sans-serif;\"><h3 style=\"margin: 0 0 10px 0;\">" + encounter.users + "</h3>" +
+                    "<p><strong>" + message('from') + ":</strong> " + encounter.start + "</p>" +
+                    "<p><strong>" + message('to') + ":</strong> " + encounter.end + "</p>" +
+                    "<p><strong>" + message('closest') + ":</strong> " + encounter.distance.toFixed(0) + " m</p></div>").addTo(map);
+            });
+        }
+        {{end}}
+
+        {{if .Meeting}}
+        function addMeetingPoint() {
+            // Dashed lines from each user's position to the meeting point
+            meeting.users.forEach(user => {
+                L.polyline([user.position, meeting.point], {
+                    pane: 'path',
+                    color: '#6A1B9A',
+                    opacity: 0.8,
+                    weight: 2,
+                    dashArray: '4 6'
+                }).addTo(map);
+            });
+
+            L.marker(meeting.point, {
+                pane: 'markers',
+                title: message('meeting_point'),
+                icon: createMarkerIcon('#6A1B9A', 'M', 36),
+                zIndexOffset: 2000
+            }).bindPopup("<div style=\"font-family: Arial, sans-serif;\"><h3 style=\"margin: 0 0 10px 0;\">" + message('meeting_point') + "</h3>" +
+                "<p><strong>" + message('based_on_positions') + ":</strong> " + meeting.time + "</p>" +
+                meeting.users.map(user => "<p><strong>" + user.name + ":</strong> " + message('distance_away', { distance: formatNumber(user.distance / 1000, 2) }) + "</p>").join("") +
+                "</div>").addTo(map);
+        }
+        {{end}}
+
+        function addMarkers() {
+            points.forEach(addMarker);
+        }
+
+        // addMarker draws the marker of one point, also for points pushed by a live server
+        function addMarker(point, index) {
+            {{if .Stops}}
+            // Points of a stop are drawn as one stop marker
+            const stop = replacingStop[index];
+            if (stop) {
+                if (stop.anchor === index) {
+                    registerMarker(index, addStopMarker(stop));
+                }
+                return;
+            }
+            {{end}}
+
+            let icon, title = point.title;
+            const size = index === 0 || index === points.length - 1 ? 32 : 24;
+            const label = markerLabel(index, size);
+            if (index === 0) {
+                icon = createMarkerIcon('#00FF00', label.text, size, label);
+                title = "START - " + title;
+            } else if (index === points.length - 1) {
+                icon = createMarkerIcon('#FF0000', label.text, size, label);
+                title = "END - " + title;
+            } else {
+                const color = pointColor(point);
+                icon = createMarkerIcon(color, label.text, size, label);
+            }
+
+            // Popups of custom icons open above the top center of the image
+            const custom = customIcon(index);
+            if (custom) {
+                icon = L.icon({
+                    iconUrl: custom.url,
+                    iconSize: [custom.width, custom.height],
+                    iconAnchor: [custom.anchorX, custom.anchorY],
+                    popupAnchor: [custom.width / 2 - custom.anchorX, -custom.anchorY]
+                });
+            }
+
+            const marker = L.marker([point.lat, point.lng], { pane: 'markers', title: title, icon: icon }).addTo(map);
+            {{if .Config.InfoWindows.Enabled}}
+            marker.bindPopup(createInfoWindowContent(point, title, index), { maxWidth: {{.Config.InfoWindows.MaxWidth}} });
+            {{end}}
+
+            // Track markers so the category and time filters can show and hide them
+            registerMarker(index, marker);
+        }
+
+        {{if .Milestones}}
+        function addMilestones() {
+            milestones.forEach(milestone => {
+                milestoneMarkers.push(L.marker([milestone.lat, milestone.lng], {
+                    pane: 'markers',
+                    title: milestone.label,
+                    interactive: false,
+                    icon: createMarkerIcon('#FFFFFF', milestone.label, 20, milestoneLabel(milestone.label))
+                }).addTo(map));
+            });
+        }
+        {{end}}
+
+        {{if .Stops}}
+        function addStopMarker(stop) {
+            const marker = L.marker([stop.lat, stop.lng], {
+                pane: 'markers',
+                title: message('stop') + ' (' + stop.duration + ')',
+                icon: createMarkerIcon("{{.StopColor}}", stop.label, stop.size)
+            }).addTo(map);
+            {{if .Config.InfoWindows.Enabled}}
+            marker.bindPopup(stopInfoContent(stop), { maxWidth: {{.Config.InfoWindows.MaxWidth}} });
+            {{end}}
+            return marker;
+        }
+        {{end}}
+
+        function setMarkerVisible(marker, visible) {
+            if (visible) {
+                marker.addTo(map);
+            } else {
+                marker.remove();
+            }
+        }
+
+        {{if .Sidebar}}
+        // focusMarker centers a marker, zooming in to street level, and opens its popup
+        function focusMarker(marker, point) {
+            map.setView([point.lat, point.lng], Math.max(map.getZoom(), 16));
+            if (map.hasLayer(marker)) {
+                marker.openPopup();
+            }
+        }
+        {{end}}
+
+        // createMarkerIcon draws a circle with the given text, styled by a point label
+        // from markerLabel if given, which stretches the circle into a pill for wide text
+        function createMarkerIcon(color, text, size, label) {
+            label = label || { color: 'white', font: 'bold ' + (size / 3) + 'px Arial', width: size };
+            return L.divIcon({
+                className: '',
+                html: '<div class="marker-icon" style="width: ' + label.width + 'px; height: ' + size + 'px; line-height: ' + (size - 4) + 'px; ' +
+                    'font: ' + escapeHTML(label.font) + '; color: ' + escapeHTML(label.color) + '; background: ' + color + ';">' + escapeHTML(text) + '</div>',
+                iconSize: [label.width, size],
+                iconAnchor: [label.width / 2, size / 2]
+            });
+        }
+
+        let walkingPath;
+
+        function addWalkingPath() {
+            {{if or .SpeedScale .DayScale .UserColors}}
+            setPathPoints(points);
+            {{else}}
+            walkingPath = L.polyline(points.map(point => [point.lat, point.lng]), {
+                pane: 'path',
+                color: "{{.Config.Path.Style.Color}}",
+                opacity: {{.Config.Path.Style.Opacity}},
+                weight: {{.Config.Path.Style.Weight}},
+                ...dashStyle("{{.Config.Path.Style.StrokePattern}}", {{.Config.Path.Style.Weight}})
+            }).addTo(map);
+            {{end}}
+
+            {{if .Arrows}}
+            // Direction arrows rotated to the bearing at each segment midpoint
+            const arrows = [
+                {{range .Arrows}}
+                { lat: {{.Latitude}}, lng: {{.Longitude}}, rotation: {{.Rotation}} },
+                {{end}}
+            ];
+            arrows.forEach(arrow => {
+                L.marker([arrow.lat, arrow.lng], {
+                    pane: 'arrows',
+                    interactive: false,
+                    icon: L.divIcon({
+                        className: '',
+                        html: '<div class="direction-arrow" style="color: {{.Config.Path.Style.Color}}; transform: rotate(' + arrow.rotation + 'deg);">&#9650;</div>',
+                        iconSize: [14, 14],
+                        iconAnchor: [7, 7]
+                    })
+                }).addTo(map);
+            });
+            {{end}}
+        }
+
+        {{if .Arrows}}
+        function setArrowsVisible(visible) {
+            map.getPane('arrows').style.display = visible ? '' : 'none';
+        }
+        {{end}}
+
+        {{if or .SpeedScale .DayScale .UserColors}}
+        // Speed and user colored paths are drawn as one polyline per color run,
+        // reused as the filters change the path
+        const pathLines = [];
+
+        function setPathPoints(pathPoints) {
+            const runs = pathRuns(pathPoints);
+            runs.forEach((run, i) => {
+                const latLngs = run.points.map(point => [point.lat, point.lng]);
+                if (!pathLines[i]) {
+                    pathLines[i] = L.polyline(latLngs, {
+                        pane: 'path',
+                        opacity: {{.Config.Path.Style.Opacity}},
+                        weight: {{.Config.Path.Style.Weight}},
+                        ...dashStyle("{{.Config.Path.Style.StrokePattern}}", {{.Config.Path.Style.Weight}})
+                    });
+                }
+                pathLines[i].setLatLngs(latLngs).setStyle({ color: run.color }).addTo(map);
+            });
+            pathLines.slice(runs.length).forEach(line => line.remove());
+        }
+        {{else}}
+        function setPathPoints(pathPoints) {
+            if (walkingPath) {
+                walkingPath.setLatLngs(pathPoints.map(point => [point.lat, point.lng]));
+            }
+        }
+        {{end}}
+
+        {{if or .PlaybackMillis .Profile}}
+        // The track marker follows playback and the elevation profile cursor
+        let trackMarker = null;
+
+        function setTrackMarker(position) {
+            if (!position) {
+                if (trackMarker) {
+                    trackMarker.remove();
+                    trackMarker = null;
+                }
+                return;
+            }
+            if (!trackMarker) {
+                trackMarker = L.circleMarker([position.lat, position.lng], {
+                    pane: 'markers',
+                    interactive: false,
+                    radius: 8,
+                    color: '#FFFFFF',
+                    weight: 3,
+                    fillColor: "{{.Config.Path.Style.Color}}",
+                    fillOpacity: 1
+                }).addTo(map);
+            }
+            trackMarker.setLatLng([position.lat, position.lng]);
+        }
+        {{end}}
+
+        function fitMapToBounds() {
+            {{if .Config.Map.AutoFitBounds}}
+            const bounds = L.latLngBounds(points.map(point => [point.lat, point.lng]));
+            {{if .Reference}}
+            referenceRoute.forEach(position => bounds.extend(position));
+            {{end}}
+            map.fitBounds(bounds, { maxZoom: 15, padding: [20, 20] });
+            {{end}}
+        }
+
+        // Leaflet lays out its tiles again for the printed map size
+        function setPrintLayout() {
+            map.invalidateSize();
+        }
+
+        {{if .Config.Map.Controls.Permalink}}
+        function getMapView() {
+            const center = map.getCenter();
+            return { lat: center.lat, lng: center.lng, zoom: map.getZoom() };
+        }
+
+        function setMapView(lat, lng, zoom) {
+            map.setView([lat, lng], zoom);
+        }
+        {{end}}
+{{end}}
+
+{{define "map-loader"}}
+    {{if .Leaflet.InlineScript}}
+    <script>{{.Leaflet.InlineScript}}</script>
+    {{else}}
+    <script src="{{.Leaflet.ScriptURL}}"></script>
+    {{end}}
+    <script>initMap();</script>
+{{end}}`
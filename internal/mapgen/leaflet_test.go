@@ -0,0 +1,150 @@
+package mapgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/tiles"
+)
+
+func TestLeafletFor(t *testing.T) {
+	tests := []struct {
+		name            string
+		tileURL         string
+		attribution     string
+		wantTileURL     string
+		wantAttribution string
+	}{
+		{name: "defaults", wantTileURL: DefaultTileURL, wantAttribution: DefaultAttribution},
+		{name: "custom tiles", tileURL: "https://tiles.example.org/{z}/{x}/{y}.png", attribution: "Example", wantTileURL: "https://tiles.example.org/{z}/{x}/{y}.png", wantAttribution: "Example"},
+		{name: "no tiles", tileURL: NoTiles, attribution: "ignored"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := leafletFor(tt.tileURL, tt.attribution)
+			if got.TileURL != tt.wantTileURL || got.Attribution != tt.wantAttribution {
+				t.Errorf("leafletFor() = %q, %q, want %q, %q", got.TileURL, got.Attribution, tt.wantTileURL, tt.wantAttribution)
+			}
+			if got.ScriptURL != leafletScriptURL || got.StyleURL != leafletStyleURL {
+				t.Errorf("leafletFor() assets = %q, %q", got.ScriptURL, got.StyleURL)
+			}
+		})
+	}
+}
+
+func TestLeafletGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.7749, Longitude: -122.4194, Category: "stop"},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.8044, Longitude: -122.2711},
+	}
+
+	cfg := &config.Config{
+		Map: config.MapConfig{
+			Provider:      ProviderLeaflet,
+			Title:         "Leaflet Test",
+			AutoFitBounds: true,
+			Fallback:      config.FallbackConfig{Provider: FallbackLeaflet},
+		},
+		Path: config.PathConfig{
+			Enabled: true,
+			Style:   config.PathStyleConfig{Color: "#FF0000", Opacity: 0.8, Weight: 3},
+		},
+		InfoWindows: config.InfoWindowsConfig{Enabled: true, MaxWidth: 300},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "map.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+
+	for _, want := range []string{
+		`<link rel="stylesheet" href="https://unpkg.com/leaflet@1.9.4/dist/leaflet.css">`,
+		`<script src="https://unpkg.com/leaflet@1.9.4/dist/leaflet.js"></script>`,
+		"L.map('map'",
+		`L.tileLayer("https:\/\/tile.openstreetmap.org\/{z}\/{x}\/{y}.png"`,
+		"addMarkers();",
+		"addWalkingPath();",
+		"marker.bindPopup(createInfoWindowContent(point, title, index)",
+		"map.fitBounds(bounds",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("Leaflet HTML missing %q", want)
+		}
+	}
+
+	// The Leaflet page needs no Google Maps API key or script, and ignores the fallback
+	for _, unwanted := range []string{"maps.googleapis.com", "google.maps", "activateFallback"} {
+		if strings.Contains(html, unwanted) {
+			t.Errorf("Leaflet HTML contains %q", unwanted)
+		}
+	}
+}
+
+func TestLeafletWithoutTiles(t *testing.T) {
+	cfg := &config.Config{Map: config.MapConfig{Provider: ProviderLeaflet, TileURL: NoTiles}}
+	outputFile := filepath.Join(t.TempDir(), "map.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), gps.Points{{Latitude: 1, Longitude: 2}}, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, _ := os.ReadFile(outputFile)
+	if strings.Contains(string(content), "L.tileLayer") {
+		t.Error("Leaflet HTML adds a tile layer although tile_url is none")
+	}
+}
+
+func TestLeafletOfflineTiles(t *testing.T) {
+	cfg := &config.Config{Map: config.MapConfig{Provider: ProviderLeaflet, TileURL: "https://tiles.example.org/{z}/{x}/{y}.png"}}
+	generator := NewGenerator(cfg)
+	generator.SetTiles(&tiles.Bundle{
+		Tiles:   map[string]string{"12/655/1583": "data:image/png;base64,iVBORw0KGgo="},
+		MinZoom: 12,
+		MaxZoom: 14,
+	})
+	outputFile := filepath.Join(t.TempDir(), "map.html")
+	if err := generator.Generate(context.Background(), gps.Points{{Latitude: 37.7749, Longitude: -122.4194}}, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, _ := os.ReadFile(outputFile)
+	html := string(content)
+
+	for _, want := range []string{
+		`"12/655/1583":"data:image/png;base64,iVBORw0KGgo="`,
+		"L.TileLayer.extend",
+		"minNativeZoom:  12 ,",
+		"maxNativeZoom:  14 ,",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("offline Leaflet HTML missing %q", want)
+		}
+	}
+	if strings.Contains(html, "tiles.example.org") {
+		t.Error("offline Leaflet HTML still loads tiles from the tile server")
+	}
+
+	leaflet := leafletFor(cfg.Map.TileURL, "")
+	leaflet.Offline = &tiles.Bundle{}
+	if hosts := leaflet.tileHosts(); len(hosts) != 0 {
+		t.Errorf("tileHosts() with embedded tiles = %v, want none", hosts)
+	}
+}
+
+func TestUnknownProvider(t *testing.T) {
+	cfg := &config.Config{Map: config.MapConfig{Provider: "bing"}}
+	err := NewGenerator(cfg).Generate(context.Background(), gps.Points{{Latitude: 1, Longitude: 2}}, filepath.Join(t.TempDir(), "map.html"))
+	if err == nil || !strings.Contains(err.Error(), "bing") {
+		t.Errorf("Generate() error = %v, want unknown provider error", err)
+	}
+}
@@ -0,0 +1,10 @@
+package mapgen
+
+// SetLive makes the generated page listen to a server-sent event stream at url
+// and append the points of each "points" event to the track, drawing their
+// markers and extending the path. Each event carries a JSON array of objects
+// with lat, lng, and timestamp, and optionally title, description, category,
+// elevation, and user. Pass "" for a static page.
+func (g *Generator) SetLive(url string) {
+	g.live = url
+}
@@ -0,0 +1,69 @@
+package mapgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestLiveUpdates(t *testing.T) {
+	points := gps.Points{{Latitude: 37.77, Longitude: -122.41}, {Latitude: 37.78, Longitude: -122.41}}
+
+	for _, provider := range []string{ProviderGoogle, ProviderLeaflet, ProviderMapLibre, ProviderCesium} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Live Test", Provider: provider},
+			}
+			generator := NewGenerator(cfg)
+			generator.SetLive("./events")
+
+			var buf bytes.Buffer
+			if err := generator.GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+			for _, want := range []string{
+				"function addLivePoints(rows) {",
+				`new EventSource("./events")`,
+				"listenForPoints();",
+				"function addMarker(point, index) {",
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+
+	// Static pages and heatmap-only pages, which have no markers to append to, do not listen
+	tests := []struct {
+		name string
+		live string
+		mode string
+	}{
+		{name: "static"},
+		{name: "heatmap", live: "./events", mode: RenderModeHeatmap},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Live Test", RenderMode: tt.mode},
+			}
+			generator := NewGenerator(cfg)
+			generator.SetLive(tt.live)
+
+			var buf bytes.Buffer
+			if err := generator.GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			if strings.Contains(buf.String(), "EventSource") {
+				t.Error("generated HTML listens for live points")
+			}
+		})
+	}
+}
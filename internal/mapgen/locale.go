@@ -0,0 +1,38 @@
+package mapgen
+
+import (
+	"github.com/saratily/geo-chrono/internal/i18n"
+	"github.com/saratily/geo-chrono/internal/stats"
+)
+
+// scriptMessages lists the message keys shown by the page scripts. Only these are
+// embedded in the page; the template looks up the rest with the t function.
+var scriptMessages = []string{
+	"arrived", "based_on_positions", "closest", "departed", "description", "distance_away",
+	"duration", "from", "heading", "hide_point_list", "location", "measure_distance",
+	"measure_hint", "measured_distance", "meeting_point", "no_points", "path", "pause",
+	"play", "point", "points", "position", "reference_route", "search_count", "sequence",
+	"sequence_of", "show_point_list", "stop", "stop_measuring", "time", "to", "weather",
+}
+
+// scriptMessagesFor returns the page script text of a locale by message key.
+func scriptMessagesFor(locale *i18n.Locale) map[string]string {
+	messages := make(map[string]string, len(scriptMessages))
+	for _, key := range scriptMessages {
+		messages[key] = locale.T(key)
+	}
+	return messages
+}
+
+// loopMessage returns the message key describing the shape of a route, the
+// translatable counterpart of stats.FormatLoop.
+func loopMessage(summary *stats.Summary) string {
+	switch {
+	case !summary.Loop:
+		return "one_way"
+	case summary.LoopDirection == "":
+		return "loop_out_and_back"
+	default:
+		return "loop_" + summary.LoopDirection
+	}
+}
@@ -0,0 +1,713 @@
+package mapgen
+
+import (
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// ProviderMapLibre renders maps with MapLibre GL and vector tiles. It needs no API
+// key unless the style is hosted by Mapbox.
+const ProviderMapLibre = "maplibre"
+
+// Defaults for MapLibre maps.
+const (
+	// DefaultMapLibreStyle is the OpenFreeMap "liberty" style, which is free to use
+	// without an account or access token.
+	DefaultMapLibreStyle = "https://tiles.openfreemap.org/styles/liberty"
+
+	mapLibreScriptURL = "https://unpkg.com/maplibre-gl@4.7.1/dist/maplibre-gl.js"
+	mapLibreStyleURL  = "https://unpkg.com/maplibre-gl@4.7.1/dist/maplibre-gl.css"
+
+	// mapboxScheme prefixes Mapbox-hosted styles, tilesets, sprites, and fonts.
+	mapboxScheme = "mapbox://"
+	mapboxHost   = "api.mapbox.com"
+)
+
+// MapLibre holds the resolved style, camera, and library assets for a MapLibre GL map.
+//
+// @struct MapLibre
+// @description Vector map style and MapLibre GL library settings
+// @property Style string Style JSON URL (https:// or mapbox://)
+// @property AccessToken string Mapbox access token added to Mapbox requests
+// @property Pitch float64 Initial camera tilt in degrees
+// @property Bearing float64 Initial camera rotation in degrees
+// @property Assets Assets MapLibre GL library script and stylesheet
+type MapLibre struct {
+	Style       string  // @field Style Style JSON URL
+	AccessToken string  // @field AccessToken Mapbox access token (empty for other styles)
+	Pitch       float64 // @field Pitch Initial camera tilt in degrees
+	Bearing     float64 // @field Bearing Initial camera rotation in degrees
+	Assets              // @field Assets MapLibre GL library script and stylesheet
+}
+
+// mapLibreFor resolves the MapLibre style, applying the OpenFreeMap default.
+// Mapbox styles cannot be loaded without an access token.
+func mapLibreFor(cfg *config.MapLibreConfig) (*MapLibre, error) {
+	mapLibre := &MapLibre{
+		Style:       cfg.Style,
+		AccessToken: cfg.AccessToken,
+		Pitch:       cfg.Pitch,
+		Bearing:     cfg.Bearing,
+		Assets:      Assets{ScriptURL: mapLibreScriptURL, StyleURL: mapLibreStyleURL},
+	}
+	if mapLibre.Style == "" {
+		mapLibre.Style = DefaultMapLibreStyle
+	}
+	if mapLibre.usesMapbox() && mapLibre.AccessToken == "" {
+		return nil, configErrorf("MapLibre style %s needs a Mapbox access token (set map.maplibre.access_token)", mapLibre.Style)
+	}
+	return mapLibre, nil
+}
+
+// usesMapbox reports whether the map loads anything from Mapbox.
+func (m *MapLibre) usesMapbox() bool {
+	return strings.HasPrefix(m.Style, mapboxScheme) || m.AccessToken != ""
+}
+
+// hosts lists the remote hosts the MapLibre map loads resources from.
+func (m *MapLibre) hosts() []string {
+	hosts := ExternalHosts([]byte(m.Style + " " + m.ScriptURL + " " + m.StyleURL))
+	if m.usesMapbox() {
+		hosts = append(hosts, mapboxHost)
+	}
+	return hosts
+}
+
+// tileHosts lists the remote hosts serving the style and its tiles, which cannot
+// be embedded.
+func (m *MapLibre) tileHosts() []string {
+	hosts := ExternalHosts([]byte(m.Style))
+	if m.usesMapbox() {
+		hosts = append(hosts, mapboxHost)
+	}
+	return hosts
+}
+
+// mapLibreTemplate defines the MapLibre GL parts of the page: the map-head,
+// map-script, and map-loader templates invoked by the shared page template. Lines and
+// areas are GeoJSON layers stacked in the configured layer order; markers and arrows
+// are HTML elements and always stay above them.
+const mapLibreTemplate = `{{define "map-head"}}
+    {{if .MapLibre.InlineStyle}}
+    <style>{{.MapLibre.InlineStyle}}</style>
+    {{else}}
+    <link rel="stylesheet" href="{{.MapLibre.StyleURL}}">
+    {{end}}
+    <style>
+        .marker-icon {
+            border: 2px solid #000;
+            border-radius: 9999px;
+            box-sizing: border-box;
+            color: white;
+            cursor: pointer;
+            font-family: Arial, sans-serif;
+            font-weight: bold;
+            text-align: center;
+        }
+        .direction-arrow {
+            font-size: 14px;
+            line-height: 14px;
+            text-align: center;
+        }
+        .geofence-label {
+            color: #A34E00;
+            font: bold 12px Arial, sans-serif;
+            text-shadow: 0 0 3px white, 0 0 3px white;
+            white-space: nowrap;
+            pointer-events: none;
+        }
+        .track-marker {
+            width: 16px;
+            height: 16px;
+            border: 3px solid #FFFFFF;
+            border-radius: 50%;
+            box-shadow: 0 0 3px rgba(0,0,0,0.5);
+        }
+    </style>
+{{end}}
+
+{{define "map-script"}}
+        // Map layers waiting to be added in z-index order once the style has loaded
+        const lineLayers = [];
+
+        function initMap() {
+            if (points.length === 0) {
+                document.getElementById('map').innerHTML = '<div style="text-align: center; padding: 50px; color: #666;">' + message('no_points') + '</div>';
+                return;
+            }
+
+            // MapLibre uses 512px tiles, so its zoom levels are one below Google's
+            map = new maplibregl.Map({
+                container: 'map',
+                style: "{{.MapLibre.Style}}",
+                center: [{{.Center.Longitude}}, {{.Center.Latitude}}],
+                zoom: Math.max({{.Zoom}} - 1, 0),
+                pitch: {{.MapLibre.Pitch}},
+                bearing: {{.MapLibre.Bearing}},
+                maxPitch: 85,
+                {{if .Restriction}}
+                maxBounds: [[{{.Restriction.West}}, {{.Restriction.South}}], [{{.Restriction.East}}, {{.Restriction.North}}]],
+                {{end}}
+                {{if .MapLibre.AccessToken}}
+                transformRequest: mapboxRequest,
+                {{end}}
+            });
+
+            {{if .Config.Map.Controls.ZoomControl}}
+            map.addControl(new maplibregl.NavigationControl({ visualizePitch: true }));
+            {{end}}
+            {{if .Config.Map.Controls.FullscreenControl}}
+            map.addControl(new maplibregl.FullscreenControl());
+            {{end}}
+            {{if .Config.Map.Controls.ScaleControl}}
+            map.addControl(new maplibregl.ScaleControl());
+            {{end}}
+
+            map.on('load', () => {
+                {{if .Heatmap}}
+                // Render point density, instead of or next to individual markers
+                addHeatmap();
+                {{end}}
+
+                {{if and .Trail .Config.Path.Enabled}}
+                addWalkingPath();
+                {{end}}
+
+                {{if .Milestones}}
+                addMilestones();
+                {{end}}
+
+                {{if and .Config.Geofences.ShowBoundaries .Fences}}
+                addGeofences();
+                {{end}}
+
+                {{if .Reference}}
+                addReferenceRoute();
+                {{end}}
+
+                {{if .Encounters}}
+                addEncounterPaths();
+                {{end}}
+
+                {{if .Meeting}}
+                addMeetingLines();
+                {{end}}
+
+                lineLayers.sort((a, b) => a.zIndex - b.zIndex).forEach(entry => map.addLayer(entry.layer));
+
+                {{if .Config.Map.Controls.Permalink}}
+                // Reopen the view and layers saved in the link once the layers exist;
+                // this also applies the filters
+                if (restorePermalink()) {
+                    return;
+                }
+                {{end}}
+
+                // Apply any filter chosen while the style was loading to the path
+                applyFilters();
+            });
+
+            // Markers are HTML elements and can be placed before the style loads
+            {{if .Trail}}
+            addMarkers();
+            {{if .LiveURL}}
+            listenForPoints();
+            {{end}}
+            {{if and .Config.Path.Enabled .Arrows}}
+            addDirectionArrows();
+            {{end}}
+            {{end}}
+
+            {{if .Encounters}}
+            addEncounterMarkers();
+            {{end}}
+
+            {{if .Meeting}}
+            addMeetingMarker();
+            {{end}}
+
+            {{if .Config.Map.Controls.MeasureTool}}
+            // Clicks on the map add points to the measure tool while it is on
+            map.on('click', event => addMeasurePoint(event.lngLat.lat, event.lngLat.lng));
+            {{end}}
+
+            {{if .Config.Map.Controls.Permalink}}
+            // Keep the link in step with the view
+            map.on('moveend', updatePermalink);
+            {{end}}
+
+            fitMapToBounds();
+        }
+
+        {{if .Config.Map.Controls.MeasureTool}}
+        // The measured line is added on first use, above every other line layer
+        function drawMeasureLine(measured) {
+            const data = { type: 'Feature', properties: {}, geometry: { type: 'LineString', coordinates: measured.map(lngLat) } };
+            const source = map.getSource('measure');
+            if (source) {
+                source.setData(data);
+                return;
+            }
+            map.addSource('measure', { type: 'geojson', data: data });
+            map.addLayer({
+                id: 'measure',
+                type: 'line',
+                source: 'measure',
+                paint: { 'line-color': '#000', 'line-width': 3, 'line-dasharray': [2, 2] }
+            });
+        }
+        {{end}}
+
+        {{if .MapLibre.AccessToken}}
+        // Resolve mapbox:// style, tileset, sprite, and font URLs and sign every
+        // Mapbox request with the access token
+        function mapboxRequest(url) {
+            if (url.startsWith('mapbox://')) {
+                const path = url.slice('mapbox://'.length);
+                if (path.startsWith('styles/')) {
+                    url = 'https://api.mapbox.com/styles/v1/' + path.slice('styles/'.length);
+                } else if (path.startsWith('sprites/')) {
+                    const sprite = path.slice('sprites/'.length).match(/^([^/]+\/[^/@.]+)(.*)$/);
+                    url = 'https://api.mapbox.com/styles/v1/' + sprite[1] + '/sprite' + sprite[2];
+                } else if (path.startsWith('fonts/')) {
+                    url = 'https://api.mapbox.com/fonts/v1/' + path.slice('fonts/'.length);
+                } else {
+                    url = 'https://api.mapbox.com/v4/' + path + '.json?secure';
+                }
+            }
+            if (url.startsWith('https://api.mapbox.com/')) {
+                url += (url.includes('?') ? '&' : '?') + 'access_token=' + encodeURIComponent("{{.MapLibre.AccessToken}}");
+            }
+            return { url: url };
+        }
+        {{end}}
+
+        function lngLat(position) {
+            return [position.lng, position.lat];
+        }
+
+        // addLines adds a GeoJSON source of line strings and queues a line layer for it
+        function addLines(id, group, lines, paint) {
+            map.addSource(id, {
+                type: 'geojson',
+                data: {
+                    type: 'FeatureCollection',
+                    features: lines.map(line => ({ type: 'Feature', properties: {}, geometry: { type: 'LineString', coordinates: line } }))
+                }
+            });
+            lineLayers.push({
+                zIndex: {{.ZIndex}}[group] || 0,
+                layer: { id: id, type: 'line', source: id, layout: { 'line-join': 'round', 'line-cap': 'round' }, paint: paint }
+            });
+        }
+
+        // dashPaint returns the line-dasharray paint of a solid, dashed, or dotted line,
+        // measured in line widths; the round line caps turn empty dashes into dots
+        function dashPaint(pattern) {
+            if (pattern === 'dashed') {
+                return { 'line-dasharray': [2, 2.5] };
+            }
+            if (pattern === 'dotted') {
+                return { 'line-dasharray': [0, 2] };
+            }
+            return {};
+        }
+
+        {{if .Heatmap}}
+        function addHeatmap() {
+            const maxWeight = Math.max(...heatmapCells.map(cell => cell[2]));
+            map.addSource('heatmap', {
+                type: 'geojson',
+                data: {
+                    type: 'FeatureCollection',
+                    features: heatmapCells.map(cell => ({
+                        type: 'Feature',
+                        properties: { weight: cell[2] / maxWeight },
+                        geometry: { type: 'Point', coordinates: [cell[1], cell[0]] }
+                    }))
+                }
+            });
+            lineLayers.push({
+                zIndex: {{index .ZIndex "path"}},
+                layer: {
+                    id: 'heatmap',
+                    type: 'heatmap',
+                    source: 'heatmap',
+                    layout: { visibility: hiddenLayers.has('heatmap') ? 'none' : 'visible' },
+                    paint: {
+                        'heatmap-weight': ['get', 'weight'],
+                        'heatmap-radius': {{if .Config.Heatmap.Radius}}{{.Config.Heatmap.Radius}}{{else}}20{{end}},
+                        'heatmap-opacity': {{if .Config.Heatmap.Opacity}}{{.Config.Heatmap.Opacity}}{{else}}0.6{{end}}
+                    }
+                }
+            });
+        }
+
+        // The heatmap layer is added once the style loads and picks up the view chosen before
+        function setHeatmapVisible(visible) {
+            if (map.getLayer('heatmap')) {
+                map.setLayoutProperty('heatmap', 'visibility', visible ? 'visible' : 'none');
+            }
+        }
+        {{end}}
+
+        {{if and .Config.Geofences.ShowBoundaries .Fences}}
+        // circleRing approximates a circular fence with a 64-sided polygon
+        function circleRing(center, radius) {
+            const ring = [];
+            for (let i = 0; i <= 64; i++) {
+                const angle = 2 * Math.PI * i / 64;
+                ring.push([
+                    center.lng + radius * Math.sin(angle) / (111320 * Math.cos(center.lat * Math.PI / 180)),
+                    center.lat + radius * Math.cos(angle) / 111320
+                ]);
+            }
+            return ring;
+        }
+
+        function addGeofences() {
+            const rings = geofences.map(fence => fence.center
+                ? circleRing(fence.center, fence.radius)
+                : fence.polygon.concat([fence.polygon[0]]).map(lngLat));
+            map.addSource('geofences', {
+                type: 'geojson',
+                data: {
+                    type: 'FeatureCollection',
+                    features: geofences.map((fence, i) => ({
+                        type: 'Feature',
+                        properties: { name: fence.name },
+                        geometry: { type: 'Polygon', coordinates: [rings[i]] }
+                    }))
+                }
+            });
+            lineLayers.push({
+                zIndex: {{index .ZIndex "geofences"}},
+                layer: { id: 'geofences-fill', type: 'fill', source: 'geofences', paint: { 'fill-color': '#FF8800', 'fill-opacity': 0.15 } }
+            });
+            lineLayers.push({
+                zIndex: {{index .ZIndex "geofences"}},
+                layer: { id: 'geofences-line', type: 'line', source: 'geofences', paint: { 'line-color': '#FF8800', 'line-opacity': 0.9, 'line-width': 2 } }
+            });
+
+            map.on('click', 'geofences-fill', event => {
+                new maplibregl.Popup().setLngLat(event.lngLat).setText(event.features[0].properties.name).addTo(map);
+            });
+
+            // Names are HTML markers, since raster styles have no glyphs for symbol layers
+            geofences.forEach(fence => {
+                const element = document.createElement('div');
+                element.className = 'geofence-label';
+                element.textContent = fence.name;
+                geofenceLabels.push(new maplibregl.Marker({ element: element }).setLngLat(lngLat(fence.label)).addTo(map));
+            });
+        }
+
+        const geofenceLabels = [];
+
+        function setGeofencesVisible(visible) {
+            ['geofences-fill', 'geofences-line'].forEach(id => {
+                if (map.getLayer(id)) {
+                    map.setLayoutProperty(id, 'visibility', visible ? 'visible' : 'none');
+                }
+            });
+            geofenceLabels.forEach(marker => {
+                marker.getElement().style.visibility = visible ? '' : 'hidden';
+            });
+        }
+        {{end}}
+
+        {{if .Reference}}
+        function addReferenceRoute() {
+            addLines('reference', 'reference', [referenceRoute.map(lngLat)], {
+                'line-color': "{{.ReferenceColor}}",
+                'line-opacity': 0.7,
+                'line-width': {{.Config.Path.Style.Weight}} + 2,
+                ...dashPaint("{{.Config.Compare.StrokePattern}}")
+            });
+        }
+        {{end}}
+
+        {{if .Encounters}}
+        function addEncounterPaths() {
+            const paths = [];
+            encounters.forEach(encounter => encounter.paths.forEach(path => paths.push(path.map(lngLat))));
+            addLines('encounters', 'path', paths, {
+                'line-color': "{{.EncounterColor}}",
+                'line-opacity': 0.9,
+                'line-width': {{.Config.Path.Style.Weight}} + 4
+            });
+        }
+
+        function addEncounterMarkers() {
+            encounters.forEach(encounter => {
+                new maplibregl.Marker({ element: createMarkerElement("{{.EncounterColor}}", "&", 28, encounter.users) })
+                    .setLngLat(lngLat(encounter.closest))
+                    .setPopup(new maplibregl.Popup().setHTML("<div style=\"font-family: Arial, sans-serif;\"><h3 style=\"margin: 0 0 10px 0;\">" + encounter.users + "</h3>" +
+                        "<p><strong>" + message('from') + ":</strong> " + encounter.start + "</p>" +
+                        "<p><strong>" + message('to') + ":</strong> " + encounter.end + "</p>" +
+                        "<p><strong>" + message('closest') + ":</strong> " + encounter.distance.toFixed(0) + " m</p></div>"))
+                    .addTo(map);
+            });
+        }
+        {{end}}
+
+        {{if .Meeting}}
+        function addMeetingLines() {
+            // Dashed lines from each user's position to the meeting point
+            addLines('meeting', 'path', meeting.users.map(user => [lngLat(user.position), lngLat(meeting.point)]), {
+                'line-color': '#6A1B9A',
+                'line-opacity': 0.8,
+                'line-width': 2,
+                'line-dasharray': [2, 3]
+            });
+        }
+
+        function addMeetingMarker() {
+            new maplibregl.Marker({ element: createMarkerElement('#6A1B9A', 'M', 36, message('meeting_point')) })
+                .setLngLat(lngLat(meeting.point))
+                .setPopup(new maplibregl.Popup().setHTML("<div style=\"font-family: Arial, sans-serif;\"><h3 style=\"margin: 0 0 10px 0;\">" + message('meeting_point') + "</h3>" +
+                    "<p><strong>" + message('based_on_positions') + ":</strong> " + meeting.time + "</p>" +
+                    meeting.users.map(user => "<p><strong>" + user.name + ":</strong> " + message('distance_away', { distance: formatNumber(user.distance / 1000, 2) }) + "</p>").join("") +
+                    "</div>"))
+                .addTo(map);
+        }
+        {{end}}
+
+        function addMarkers() {
+            points.forEach(addMarker);
+        }
+
+        // addMarker draws the marker of one point, also for points pushed by a live server
+        function addMarker(point, index) {
+            {{if .Stops}}
+            // Points of a stop are drawn as one stop marker
+            const stop = replacingStop[index];
+            if (stop) {
+                if (stop.anchor === index) {
+                    registerMarker(index, addStopMarker(stop));
+                }
+                return;
+            }
+            {{end}}
+
+            let element, title = point.title;
+            const size = index === 0 || index === points.length - 1 ? 32 : 24;
+            const label = markerLabel(index, size);
+            if (index === 0) {
+                title = "START - " + title;
+                element = createMarkerElement('#00FF00', label.text, size, title, label);
+            } else if (index === points.length - 1) {
+                title = "END - " + title;
+                element = createMarkerElement('#FF0000', label.text, size, title, label);
+            } else {
+                const color = pointColor(point);
+                element = createMarkerElement(color, label.text, size, title, label);
+            }
+
+            // Custom icons are positioned from their top left corner by the anchor
+            const options = { element: element };
+            const custom = customIcon(index);
+            if (custom) {
+                options.element = createIconElement(custom, title);
+                options.anchor = 'top-left';
+                options.offset = [-custom.anchorX, -custom.anchorY];
+            }
+
+            const marker = new maplibregl.Marker(options).setLngLat(lngLat(point)).addTo(map);
+            {{if .Config.InfoWindows.Enabled}}
+            marker.setPopup(new maplibregl.Popup({ maxWidth: '{{.Config.InfoWindows.MaxWidth}}px' }).setHTML(createInfoWindowContent(point, title, index)));
+            {{end}}
+
+            // Track markers so the category and time filters can show and hide them
+            registerMarker(index, marker);
+        }
+
+        {{if .Milestones}}
+        function addMilestones() {
+            milestones.forEach(milestone => {
+                const element = createMarkerElement('#FFFFFF', milestone.label, 20, milestone.label, milestoneLabel(milestone.label));
+                element.style.cursor = 'default';
+                milestoneMarkers.push(new maplibregl.Marker({ element: element }).setLngLat(lngLat(milestone)).addTo(map));
+            });
+        }
+        {{end}}
+
+        {{if .Stops}}
+        function addStopMarker(stop) {
+            const element = createMarkerElement("{{.StopColor}}", stop.label, stop.size, message('stop') + ' (' + stop.duration + ')');
+            const marker = new maplibregl.Marker({ element: element }).setLngLat(lngLat(stop)).addTo(map);
+            {{if .Config.InfoWindows.Enabled}}
+            marker.setPopup(new maplibregl.Popup({ maxWidth: '{{.Config.InfoWindows.MaxWidth}}px' }).setHTML(stopInfoContent(stop)));
+            {{end}}
+            return marker;
+        }
+        {{end}}
+
+        function setMarkerVisible(marker, visible) {
+            if (visible) {
+                marker.addTo(map);
+            } else {
+                marker.remove();
+            }
+        }
+
+        {{if .Sidebar}}
+        // focusMarker flies to a marker, zooming in to street level, and opens its popup
+        function focusMarker(marker, point) {
+            map.flyTo({ center: lngLat(point), zoom: Math.max(map.getZoom(), 16) });
+            const popup = marker.getPopup();
+            if (popup && !popup.isOpen() && markerVisible[point.index]) {
+                marker.togglePopup();
+            }
+        }
+        {{end}}
+
+        // createMarkerElement draws a circle with the given text, styled by a point label
+        // from markerLabel if given, which stretches the circle into a pill for wide text
+        function createMarkerElement(color, text, size, title, label) {
+            const element = document.createElement('div');
+            element.className = 'marker-icon';
+            element.title = title;
+            element.textContent = text;
+            element.style.width = (label ? label.width : size) + 'px';
+            element.style.height = size + 'px';
+            if (label) {
+                element.style.font = label.font;
+                element.style.color = label.color;
+            } else {
+                element.style.fontSize = (size / 3) + 'px';
+            }
+            element.style.lineHeight = (size - 4) + 'px';
+            element.style.background = color;
+            return element;
+        }
+
+        function createIconElement(icon, title) {
+            const element = document.createElement('img');
+            element.src = icon.url;
+            element.alt = element.title = title;
+            element.width = icon.width;
+            element.height = icon.height;
+            element.style.cursor = 'pointer';
+            return element;
+        }
+
+        {{if .Config.Path.Enabled}}
+        function addWalkingPath() {
+            addLines('path', 'path', [points.map(lngLat)], {
+                'line-color': {{if or .SpeedScale .DayScale .UserColors}}['get', 'color']{{else}}"{{.Config.Path.Style.Color}}"{{end}},
+                'line-opacity': {{.Config.Path.Style.Opacity}},
+                'line-width': {{.Config.Path.Style.Weight}},
+                ...dashPaint("{{.Config.Path.Style.StrokePattern}}")
+            });
+            {{if or .SpeedScale .DayScale .UserColors}}
+            setPathPoints(points);
+            {{end}}
+        }
+
+        function setPathPoints(pathPoints) {
+            const source = map.getSource('path');
+            if (source) {
+                {{if or .SpeedScale .DayScale .UserColors}}
+                // Each run of segments sharing a color is a feature carrying its color
+                const features = pathRuns(pathPoints).map(run => ({
+                    type: 'Feature',
+                    properties: { color: run.color },
+                    geometry: { type: 'LineString', coordinates: run.points.map(lngLat) }
+                }));
+                {{else}}
+                const features = [{ type: 'Feature', properties: {}, geometry: { type: 'LineString', coordinates: pathPoints.map(lngLat) } }];
+                {{end}}
+                source.setData({ type: 'FeatureCollection', features: features });
+            }
+        }
+        {{end}}
+
+        {{if .Arrows}}
+        // Direction arrows rotated to the bearing at each segment midpoint; aligning
+        // them with the map keeps them pointing along the path when it is rotated
+        function addDirectionArrows() {
+            const arrows = [
+                {{range .Arrows}}
+                { lat: {{.Latitude}}, lng: {{.Longitude}}, rotation: {{.Rotation}} },
+                {{end}}
+            ];
+            arrows.forEach(arrow => {
+                const element = document.createElement('div');
+                element.className = 'direction-arrow';
+                element.style.color = "{{.Config.Path.Style.Color}}";
+                element.textContent = '▲';
+                arrowMarkers.push(new maplibregl.Marker({ element: element, rotation: arrow.rotation, rotationAlignment: 'map' })
+                    .setLngLat(lngLat(arrow))
+                    .addTo(map));
+            });
+        }
+
+        const arrowMarkers = [];
+
+        function setArrowsVisible(visible) {
+            arrowMarkers.forEach(marker => {
+                marker.getElement().style.visibility = visible ? '' : 'hidden';
+            });
+        }
+        {{end}}
+
+        {{if or .PlaybackMillis .Profile}}
+        // The track marker follows playback and the elevation profile cursor
+        let trackMarker = null;
+
+        function setTrackMarker(position) {
+            if (!position) {
+                if (trackMarker) {
+                    trackMarker.remove();
+                    trackMarker = null;
+                }
+                return;
+            }
+            if (!trackMarker) {
+                const element = document.createElement('div');
+                element.className = 'track-marker';
+                element.style.background = "{{.Config.Path.Style.Color}}";
+                trackMarker = new maplibregl.Marker({ element: element }).setLngLat(lngLat(position)).addTo(map);
+            }
+            trackMarker.setLngLat(lngLat(position));
+        }
+        {{end}}
+
+        function fitMapToBounds() {
+            {{if .Config.Map.AutoFitBounds}}
+            const bounds = new maplibregl.LngLatBounds();
+            points.forEach(point => bounds.extend(lngLat(point)));
+            {{if .Reference}}
+            referenceRoute.forEach(position => bounds.extend(lngLat(position)));
+            {{end}}
+            map.fitBounds(bounds, { maxZoom: 14, padding: 20, duration: 0 });
+            {{end}}
+        }
+
+        // The canvas is redrawn at the printed map size
+        function setPrintLayout() {
+            map.resize();
+        }
+
+        {{if .Config.Map.Controls.Permalink}}
+        function getMapView() {
+            const center = map.getCenter();
+            return { lat: center.lat, lng: center.lng, zoom: map.getZoom() };
+        }
+
+        function setMapView(lat, lng, zoom) {
+            map.jumpTo({ center: [lng, lat], zoom: zoom });
+        }
+        {{end}}
+{{end}}
+
+{{define "map-loader"}}
+    {{if .MapLibre.InlineScript}}
+    <script>{{.MapLibre.InlineScript}}</script>
+    {{else}}
+    <script src="{{.MapLibre.ScriptURL}}"></script>
+    {{end}}
+    <script>initMap();</script>
+{{end}}`
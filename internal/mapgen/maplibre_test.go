@@ -0,0 +1,119 @@
+package mapgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestMapLibreFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       config.MapLibreConfig
+		wantStyle string
+		wantHosts []string
+		wantErr   bool
+	}{
+		{
+			name:      "default style",
+			wantStyle: DefaultMapLibreStyle,
+			wantHosts: []string{"tiles.openfreemap.org"},
+		},
+		{
+			name:      "custom style",
+			cfg:       config.MapLibreConfig{Style: "https://maps.example.org/style.json"},
+			wantStyle: "https://maps.example.org/style.json",
+			wantHosts: []string{"maps.example.org"},
+		},
+		{
+			name:      "mapbox style with token",
+			cfg:       config.MapLibreConfig{Style: "mapbox://styles/mapbox/outdoors-v12", AccessToken: "pk.test"},
+			wantStyle: "mapbox://styles/mapbox/outdoors-v12",
+			wantHosts: []string{"api.mapbox.com"},
+		},
+		{
+			name:    "mapbox style without token",
+			cfg:     config.MapLibreConfig{Style: "mapbox://styles/mapbox/outdoors-v12"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mapLibreFor(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mapLibreFor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Style != tt.wantStyle {
+				t.Errorf("mapLibreFor() style = %q, want %q", got.Style, tt.wantStyle)
+			}
+			hosts := got.tileHosts()
+			if strings.Join(hosts, ",") != strings.Join(tt.wantHosts, ",") {
+				t.Errorf("tileHosts() = %v, want %v", hosts, tt.wantHosts)
+			}
+		})
+	}
+}
+
+func TestMapLibreGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.7749, Longitude: -122.4194, Category: "stop"},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.8044, Longitude: -122.2711},
+	}
+
+	cfg := &config.Config{
+		Map: config.MapConfig{
+			Provider:      ProviderMapLibre,
+			AutoFitBounds: true,
+			Controls:      config.ControlsConfig{ZoomControl: true},
+			MapLibre:      config.MapLibreConfig{Pitch: 60, Bearing: 30},
+		},
+		Path: config.PathConfig{
+			Enabled: true,
+			Style:   config.PathStyleConfig{Color: "#FF0000", Opacity: 0.8, Weight: 3},
+		},
+		InfoWindows: config.InfoWindowsConfig{Enabled: true, MaxWidth: 300},
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "map.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+
+	for _, want := range []string{
+		`<link rel="stylesheet" href="https://unpkg.com/maplibre-gl@4.7.1/dist/maplibre-gl.css">`,
+		`<script src="https://unpkg.com/maplibre-gl@4.7.1/dist/maplibre-gl.js"></script>`,
+		"new maplibregl.Map({",
+		`style: "https:\/\/tiles.openfreemap.org\/styles\/liberty"`,
+		"pitch:  60",
+		"bearing:  30",
+		"new maplibregl.NavigationControl({ visualizePitch: true })",
+		"addWalkingPath();",
+		"marker.setPopup(",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("MapLibre HTML missing %q", want)
+		}
+	}
+
+	for _, unwanted := range []string{"maps.googleapis.com", "google.maps", "L.map(", "api.mapbox.com"} {
+		if strings.Contains(html, unwanted) {
+			t.Errorf("MapLibre HTML contains %q", unwanted)
+		}
+	}
+}
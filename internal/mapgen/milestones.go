@@ -0,0 +1,88 @@
+package mapgen
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// maxMilestones caps the number of milestone markers of each track, so a short
+// interval on a long track cannot flood the map.
+const maxMilestones = 500
+
+// Milestone is a distance marker placed on the path, such as "5 km".
+//
+// @struct Milestone
+// @description Marker at a whole multiple of the milestone interval along a track
+// @property Latitude float64 Latitude of the milestone on the path
+// @property Longitude float64 Longitude of the milestone on the path
+// @property Elevation float64 Elevation interpolated between the neighbouring points
+// @property Label string Distance travelled with its unit, such as "2 km"
+// @property User string User whose track the milestone is on (empty for single-user tracks)
+type Milestone struct {
+	Latitude  float64 // @field Latitude Latitude of the milestone on the path
+	Longitude float64 // @field Longitude Longitude of the milestone on the path
+	Elevation float64 // @field Elevation Interpolated elevation of the milestone
+	Label     string  // @field Label Distance travelled with its unit
+	User      string  // @field User User whose track the milestone is on
+}
+
+// milestonesFor places a milestone every interval of distance along the path,
+// measured with the statistics distance method in the statistics distance units.
+// Each user of a shared track has their own milestones, since their paths are
+// drawn separately.
+//
+// @function milestonesFor
+// @description Computes the distance markers along each track
+// @param points gps.Points Chronologically sorted GPS points
+// @param milestones *config.MilestonesConfig Milestone interval
+// @param statistics *config.StatisticsConfig Distance method and units
+// @return []Milestone Milestones in track order, user by user
+// @logic Positions are interpolated linearly within the segment that crosses each milestone
+// @internal true
+func milestonesFor(points gps.Points, milestones *config.MilestonesConfig, statistics *config.StatisticsConfig) []Milestone {
+	unit, suffix := 1000.0, "km"
+	if statistics.DistanceUnits == "imperial" {
+		unit, suffix = 1609.344, "mi"
+	}
+	interval := milestones.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+	distanceFn, _ := gps.DistanceFuncFor(statistics.DistanceMethod)
+
+	tracks := points.ByUser()
+	users := append([]string{""}, points.Users()...)
+
+	var placed []Milestone
+	for _, user := range users {
+		track := tracks[user]
+		travelled, next := 0.0, 1
+		for i := 1; i < len(track) && next <= maxMilestones; i++ {
+			segment := distanceFn(track[i-1], track[i])
+			for next <= maxMilestones && travelled+segment >= float64(next)*interval*unit {
+				fraction := (float64(next)*interval*unit - travelled) / segment
+				from, to := track[i-1], track[i]
+				placed = append(placed, Milestone{
+					Latitude:  from.Latitude + (to.Latitude-from.Latitude)*fraction,
+					Longitude: from.Longitude + (to.Longitude-from.Longitude)*fraction,
+					Elevation: from.Elevation + (to.Elevation-from.Elevation)*fraction,
+					Label:     milestoneLabel(float64(next)*interval, suffix),
+					User:      user,
+				})
+				next++
+			}
+			travelled += segment
+		}
+	}
+	return placed
+}
+
+// milestoneLabel formats a milestone distance without trailing zeros, rounded to
+// hide floating point error in fractional intervals: "2 km", "1.5 mi".
+func milestoneLabel(distance float64, suffix string) string {
+	rounded := math.Round(distance*1000) / 1000
+	return strconv.FormatFloat(rounded, 'f', -1, 64) + " " + suffix
+}
@@ -0,0 +1,65 @@
+package mapgen
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestMilestonesFor(t *testing.T) {
+	// One degree of latitude along a meridian is about 111.2 km
+	line := gps.Points{{Latitude: 0}, {Latitude: 0.01}, {Latitude: 0.03}}
+
+	labels := func(milestones []Milestone) []string {
+		var got []string
+		for _, milestone := range milestones {
+			got = append(got, milestone.Label)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name       string
+		points     gps.Points
+		milestones config.MilestonesConfig
+		units      string
+		want       []string
+	}{
+		{name: "every kilometer", points: line, want: []string{"1 km", "2 km", "3 km"}},
+		{name: "half kilometers", points: line, milestones: config.MilestonesConfig{Interval: 0.5}, want: []string{"0.5 km", "1 km", "1.5 km", "2 km", "2.5 km", "3 km"}},
+		{name: "miles", points: line, units: "imperial", want: []string{"1 mi", "2 mi"}},
+		{name: "shorter than the interval", points: line, milestones: config.MilestonesConfig{Interval: 5}},
+		{
+			name: "each user counts their own distance",
+			points: gps.Points{
+				{Latitude: 0, User: "bob"}, {Latitude: 1, Longitude: 1, User: "alice"},
+				{Latitude: 0.015, User: "bob"}, {Latitude: 1.01, Longitude: 1, User: "alice"},
+			},
+			want: []string{"1 km", "1 km"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := milestonesFor(tt.points, &tt.milestones, &config.StatisticsConfig{DistanceUnits: tt.units})
+			if !reflect.DeepEqual(labels(got), tt.want) {
+				t.Errorf("milestonesFor() labels = %v, want %v", labels(got), tt.want)
+			}
+		})
+	}
+
+	// The first kilometer is 90% of the way along the first 1.11 km segment
+	got := milestonesFor(line, &config.MilestonesConfig{}, &config.StatisticsConfig{})
+	if want := 1000 / 111195.0; math.Abs(got[0].Latitude-want) > 1e-6 {
+		t.Errorf("first milestone latitude = %v, want %v", got[0].Latitude, want)
+	}
+
+	// Users are visited in name order
+	shared := milestonesFor(tests[4].points, &config.MilestonesConfig{}, &config.StatisticsConfig{})
+	if shared[0].User != "alice" || shared[1].User != "bob" {
+		t.Errorf("milestone users = %q, %q, want alice, bob", shared[0].User, shared[1].User)
+	}
+}
@@ -0,0 +1,88 @@
+package mapgen
+
+import (
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// minifyTemplate strips indentation, blank lines, and whole-line // comments from
+// template source. Lines holding only template actions are joined to the next
+// line, so conditionals leave no empty lines behind, while every other line keeps
+// its newline and JavaScript statement boundaries are preserved. Minifying the
+// template rather than the rendered page leaves inlined libraries and data untouched;
+// html/template already removes the remaining HTML, CSS, and JavaScript comments.
+func minifyTemplate(text string) string {
+	var b strings.Builder
+	b.Grow(len(text) / 2)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		b.WriteString(line)
+		if !isActionLine(line) {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// isActionLine reports whether a trimmed line consists only of template actions,
+// such as "{{end}}" or "{{if .Heatmap}}{{range .Heatmap}}".
+func isActionLine(line string) bool {
+	for line != "" {
+		if !strings.HasPrefix(line, "{{") {
+			return false
+		}
+		end := strings.Index(line, "}}")
+		if end < 0 {
+			return false
+		}
+		line = line[end+2:]
+	}
+	return true
+}
+
+// compactPoints encodes the points as a JSON array of rows for the minified page,
+// which expands them with expandPoints. Each row is
+// [lat, lng, timestamp, title, description, category, heading, elevation, user], with
+// trailing empty values omitted; an empty title falls back to "Point N" as on the
+// regular page. Coordinates keep their full precision.
+func compactPoints(points gps.Points, headings []string) template.JS {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, point := range points {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		row := []string{
+			formatNumber(point.Latitude),
+			formatNumber(point.Longitude),
+			jsString(point.Timestamp.Format("2006-01-02 15:04:05")),
+			jsString(point.Title),
+			jsString(point.Description),
+			jsString(point.Category),
+			jsString(headings[i]),
+			formatNumber(point.Elevation),
+			jsString(point.User),
+		}
+		for len(row) > 3 && (row[len(row)-1] == `""` || row[len(row)-1] == "0") {
+			row = row[:len(row)-1]
+		}
+
+		b.WriteByte('[')
+		b.WriteString(strings.Join(row, ","))
+		b.WriteByte(']')
+	}
+	b.WriteByte(']')
+	return template.JS(b.String())
+}
+
+// formatNumber formats f in the shortest form that parses back to the same value.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
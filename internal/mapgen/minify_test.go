@@ -0,0 +1,108 @@
+package mapgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestMinifyTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "indentation and blank lines",
+			text: "<div>\n    <span>a</span>\n\n    \n</div>\n",
+			want: "<div>\n<span>a</span>\n</div>\n",
+		},
+		{
+			name: "line comments",
+			text: "    // Draw the path\n    drawPath();\n",
+			want: "drawPath();\n",
+		},
+		{
+			name: "action lines joined",
+			text: "    {{if .Heatmap}}\n    const cells = [];\n    {{end}}{{end}}\n    draw();\n",
+			want: "{{if .Heatmap}}const cells = [];\n{{end}}{{end}}draw();\n",
+		},
+		{
+			name: "statements keep their newline",
+			text: "let a = 1\n{{.X}} + 2\nlet b = 3\n",
+			want: "let a = 1\n{{.X}} + 2\nlet b = 3\n",
+		},
+		{
+			name: "urls in strings",
+			text: `    src="https://example.com/map.js"`,
+			want: "src=\"https://example.com/map.js\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := minifyTemplate(tt.text); got != tt.want {
+				t.Errorf("minifyTemplate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompactPoints(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.7749, Longitude: -122.4194, Title: "Cafe </script>", Elevation: 12.5},
+		{Timestamp: testTime.Add(time.Minute), Latitude: 37.775, Longitude: -122.42, Category: "work"},
+		{Latitude: 1, Longitude: 2},
+	}
+
+	want := `[[37.7749,-122.4194,"2025-10-28 10:00:00","Cafe \u003c/script\u003e","","","N",12.5],` +
+		`[37.775,-122.42,"2025-10-28 10:01:00","","","work","N"],` +
+		`[1,2,"0001-01-01 00:00:00"]]`
+	if got := string(compactPoints(points, []string{"N", "N", ""})); got != want {
+		t.Errorf("compactPoints() = %s, want %s", got, want)
+	}
+}
+
+func TestMinifiedOutput(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	var points gps.Points
+	for i := 0; i < 100; i++ {
+		points = append(points, gps.Point{Timestamp: testTime.Add(time.Duration(i) * time.Minute), Latitude: 37.77 + float64(i)*0.001, Longitude: -122.41})
+	}
+
+	for _, provider := range []string{"google", "leaflet", "maplibre", "cesium"} {
+		t.Run(provider, func(t *testing.T) {
+			generate := func(minify bool) string {
+				cfg := &config.Config{
+					GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+					Map:        config.MapConfig{Title: "Minified", Provider: provider},
+					Path:       config.PathConfig{Enabled: true},
+					Output:     config.OutputConfig{Minify: minify},
+				}
+				var buf bytes.Buffer
+				if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+					t.Fatalf("GenerateTo() error = %v", err)
+				}
+				return buf.String()
+			}
+
+			full, minified := generate(false), generate(true)
+			if len(minified) >= len(full)/2 {
+				t.Errorf("minified page is %d bytes, want less than half of %d", len(minified), len(full))
+			}
+			if !strings.Contains(minified, "const points = expandPoints(") || !strings.Contains(minified, `[[37.77,-122.41,"2025-10-28 10:00:00","","","","N"],`) {
+				t.Error("minified page missing compact points")
+			}
+			for _, line := range strings.Split(minified, "\n") {
+				if line != strings.TrimLeft(line, " \t") {
+					t.Fatalf("minified page has indented line %q", line)
+				}
+			}
+		})
+	}
+}
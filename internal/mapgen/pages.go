@@ -0,0 +1,225 @@
+package mapgen
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/aggregate"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/i18n"
+	"github.com/saratily/geo-chrono/internal/staticmap"
+	"github.com/saratily/geo-chrono/internal/stats"
+)
+
+// IndexPage is the file name of the index written by GeneratePages.
+const IndexPage = "index.html"
+
+// pageThumbnailSize is the width and height in pixels of the track previews on the index page.
+const pageThumbnailSize = 96
+
+// Link is a labelled link to another page of a multi-page output.
+type Link struct {
+	Label string // Link text
+	URL   string // Relative page URL
+}
+
+// Navigation holds the links shown in the header of a per-day page.
+//
+// @struct Navigation
+// @description Links between the pages of a multi-page output
+// @property Index Link Index page listing every day
+// @property Previous *Link Previous day (nil on the first day)
+// @property Next *Link Next day (nil on the last day)
+type Navigation struct {
+	Index    Link  // @field Index Index page listing every day
+	Previous *Link // @field Previous Previous day (nil on the first day)
+	Next     *Link // @field Next Next day (nil on the last day)
+}
+
+// pageDay is one row of the index page.
+type pageDay struct {
+	aggregate.Period
+	File      string       // Page file name
+	Thumbnail template.URL // PNG data URI of the day's track ("" when it cannot be drawn)
+}
+
+// GeneratePages splits a multi-day track into one map page per calendar day, named
+// after the day (2025-10-28.html), plus an index.html linking every day with its
+// summary. Day boundaries use processing.timezone, and each day page links to the
+// index and its neighbouring days.
+//
+// @method GeneratePages
+// @description Writes per-day map pages and an index for trip journals
+// @param ctx context.Context Stops before the next page when cancelled
+// @param points gps.Points Chronologically sorted GPS points
+// @param dir string Directory for the pages, created if missing
+// @return []string Paths of the written files, index last
+// @return error Error if the timezone is invalid or a page cannot be written
+// @example files, err := generator.GeneratePages(ctx, points, "trip")
+func (g *Generator) GeneratePages(ctx context.Context, points gps.Points, dir string) ([]string, error) {
+	loc, err := time.LoadLocation(g.config.Processing.Timezone)
+	if err != nil {
+		return nil, configErrorf("invalid processing timezone: %w", err)
+	}
+	locale, ok := i18n.Lookup(g.config.Map.Language)
+	if !ok {
+		return nil, configErrorf("unknown map language %q (use %s)", g.config.Map.Language, strings.Join(i18n.Languages(), ", "))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create pages directory: %w", err)
+	}
+
+	periods := aggregate.Daily(points, &g.config.Statistics, loc)
+	days := make([]pageDay, len(periods))
+	for i, period := range periods {
+		days[i] = pageDay{Period: period, File: period.Label + ".html"}
+		if png, err := staticmap.Thumbnail(period.Points, pageThumbnailSize); err == nil {
+			days[i].Thumbnail = template.URL(staticmap.DataURI(png))
+		}
+	}
+
+	var files []string
+	for i, day := range days {
+		// Each day page is a regular map titled after its day, without the daily tables
+		cfg := *g.config
+		cfg.Map.Title = fmt.Sprintf("%s: %s", g.config.Map.Title, day.Label)
+		cfg.Statistics.ShowDaily = false
+		cfg.Statistics.ShowWeekly = false
+
+		navigation := &Navigation{Index: Link{Label: locale.T("all_days"), URL: IndexPage}}
+		if i > 0 {
+			navigation.Previous = &Link{Label: days[i-1].Label, URL: days[i-1].File}
+		}
+		if i < len(days)-1 {
+			navigation.Next = &Link{Label: days[i+1].Label, URL: days[i+1].File}
+		}
+
+		page := &Generator{config: &cfg, reference: g.reference, weather: g.weather, tiles: g.tiles, navigation: navigation}
+		file := filepath.Join(dir, day.File)
+		if err := page.Generate(ctx, day.Points, file); err != nil {
+			return files, fmt.Errorf("cannot write page for %s: %w", day.Label, err)
+		}
+		files = append(files, file)
+	}
+
+	index := filepath.Join(dir, IndexPage)
+	if err := g.writeIndex(index, points, days, locale); err != nil {
+		return files, err
+	}
+	return append(files, index), nil
+}
+
+// writeIndex renders the index page with the overall summary and one row per day,
+// in the page language.
+func (g *Generator) writeIndex(filename string, points gps.Points, days []pageDay, locale *i18n.Locale) error {
+	units := g.config.Statistics.DistanceUnits
+	t, err := template.New("index").Funcs(template.FuncMap{
+		"duration": stats.FormatDuration,
+		"distance": func(meters float64) string { return locale.Number(stats.FormatDistance(meters, units)) },
+		"t":        locale.T,
+	}).Parse(indexTemplate)
+	if err != nil {
+		return configErrorf("error parsing index template: %w", err)
+	}
+
+	data := struct {
+		Title    string
+		Language string
+		Stats    *stats.Summary
+		Days     []pageDay
+	}{g.config.Map.Title, locale.Code, stats.Compute(points, &g.config.Statistics), days}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error executing index template: %w", err)
+	}
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error creating index page: %w", err)
+	}
+	return nil
+}
+
+// indexTemplate is the index page of a multi-page output. It needs no scripts or
+// network access; the day previews are embedded images.
+const indexTemplate = `<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+    <title>{{.Title}}</title>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <style>
+        body {
+            font-family: Arial, sans-serif;
+            margin: 0;
+            padding: 20px;
+            background-color: #f5f5f5;
+        }
+        h1 {
+            color: #333;
+            text-align: center;
+        }
+        .stats, .days {
+            background: white;
+            padding: 15px;
+            border-radius: 8px;
+            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
+            margin-bottom: 20px;
+        }
+        .stats {
+            text-align: center;
+        }
+        .stats span {
+            display: inline-block;
+            margin: 0 20px;
+            color: #666;
+        }
+        .days table {
+            border-collapse: collapse;
+            width: 100%;
+        }
+        .days th, .days td {
+            padding: 8px;
+            border-bottom: 1px solid #eee;
+            text-align: left;
+            vertical-align: middle;
+        }
+        .days img {
+            display: block;
+            border-radius: 4px;
+        }
+    </style>
+</head>
+<body>
+    <h1>{{.Title}}</h1>
+
+    <div class="stats">
+        <span><strong>{{t "days"}}:</strong> {{len .Days}}</span>
+        <span><strong>{{t "total_points"}}:</strong> {{.Stats.Points}}</span>
+        <span><strong>{{t "distance"}}:</strong> {{distance .Stats.Distance}}</span>
+        <span><strong>{{t "moving_time"}}:</strong> {{duration .Stats.MovingTime}}</span>
+    </div>
+
+    <div class="days">
+        <table>
+            <tr><th></th><th>{{t "day"}}</th><th>{{t "points"}}</th><th>{{t "distance"}}</th><th>{{t "duration"}}</th><th>{{t "moving_time"}}</th></tr>
+            {{range .Days}}
+            <tr>
+                <td>{{if .Thumbnail}}<a href="{{.File}}"><img src="{{.Thumbnail}}" width="96" height="96" alt="{{t "track_on"}} {{.Label}}"></a>{{end}}</td>
+                <td><a href="{{.File}}">{{.Label}}</a></td>
+                <td>{{.Summary.Points}}</td>
+                <td>{{distance .Summary.Distance}}</td>
+                <td>{{duration .Summary.Duration}}</td>
+                <td>{{duration .Summary.MovingTime}}</td>
+            </tr>
+            {{end}}
+        </table>
+    </div>
+</body>
+</html>
+`
@@ -0,0 +1,93 @@
+package mapgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestGeneratePages(t *testing.T) {
+	start := time.Date(2025, 10, 28, 9, 0, 0, 0, time.UTC)
+	var points gps.Points
+	for day := 0; day < 3; day++ {
+		for i := 0; i < 3; i++ {
+			points = append(points, gps.Point{
+				Timestamp: start.AddDate(0, 0, day).Add(time.Duration(i) * 10 * time.Minute),
+				Latitude:  37.77 + float64(day)*0.1 + float64(i)*0.01,
+				Longitude: -122.42,
+			})
+		}
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Road Trip"},
+		Statistics: config.StatisticsConfig{ShowDaily: true},
+	}
+	dir := filepath.Join(t.TempDir(), "trip")
+	files, err := NewGenerator(cfg).GeneratePages(context.Background(), points, dir)
+	if err != nil {
+		t.Fatalf("GeneratePages() error = %v", err)
+	}
+
+	want := []string{"2025-10-28.html", "2025-10-29.html", "2025-10-30.html", IndexPage}
+	if len(files) != len(want) {
+		t.Fatalf("GeneratePages() wrote %v, want %v", files, want)
+	}
+	for i, name := range want {
+		if files[i] != filepath.Join(dir, name) {
+			t.Errorf("files[%d] = %s, want %s", i, files[i], filepath.Join(dir, name))
+		}
+	}
+
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	middle := read("2025-10-29.html")
+	for _, want := range []string{
+		"<h1>Road Trip: 2025-10-29</h1>",
+		`<a href="2025-10-28.html">&larr; 2025-10-28</a>`,
+		`<a href="index.html">All days</a>`,
+		`<a href="2025-10-30.html">2025-10-30 &rarr;</a>`,
+	} {
+		if !strings.Contains(middle, want) {
+			t.Errorf("day page missing %q", want)
+		}
+	}
+	if strings.Contains(middle, "Daily Summary") || !strings.Contains(middle, "37.88") || strings.Contains(middle, "37.78") || strings.Contains(middle, "37.98") {
+		t.Error("day page should show only its own three points without the daily table")
+	}
+	if first := read("2025-10-28.html"); strings.Contains(first, "&larr;") {
+		t.Error("first day page links to a previous day")
+	}
+
+	index := read(IndexPage)
+	for _, want := range []string{
+		"<title>Road Trip</title>",
+		"<strong>Days:</strong> 3",
+		`<a href="2025-10-30.html">2025-10-30</a>`,
+		`src="data:image/png;base64,`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index page missing %q", want)
+		}
+	}
+}
+
+func TestGeneratePagesInvalidTimezone(t *testing.T) {
+	cfg := &config.Config{Processing: config.ProcessingConfig{Timezone: "Mars/Olympus"}}
+	if _, err := NewGenerator(cfg).GeneratePages(context.Background(), gps.Points{{Latitude: 1, Longitude: 2}}, t.TempDir()); err == nil {
+		t.Error("GeneratePages() expected an error for an invalid timezone")
+	}
+}
@@ -0,0 +1,31 @@
+package mapgen
+
+import (
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// Playback speeds for path.animation.speed, from the slowest to the fastest.
+const (
+	MinPlaybackSpeed     = 1
+	MaxPlaybackSpeed     = 10
+	DefaultPlaybackSpeed = 5
+)
+
+// playbackBaseMillis is how long playing the whole track takes at speed 1; each
+// speed step divides it, so speed 10 plays the track in a tenth of the time.
+const playbackBaseMillis = 120000
+
+// playbackMillis returns how long the playback animation of the whole track lasts,
+// or 0 when animation is disabled. Speeds outside 1-10 are clamped and an unset
+// speed uses DefaultPlaybackSpeed.
+func playbackMillis(cfg *config.AnimationConfig) int {
+	if !cfg.Enabled {
+		return 0
+	}
+	speed := cfg.Speed
+	if speed == 0 {
+		speed = DefaultPlaybackSpeed
+	}
+	speed = max(MinPlaybackSpeed, min(speed, MaxPlaybackSpeed))
+	return playbackBaseMillis / speed
+}
@@ -0,0 +1,30 @@
+package mapgen
+
+import (
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+func TestPlaybackMillis(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.AnimationConfig
+		want int
+	}{
+		{name: "disabled", cfg: config.AnimationConfig{Speed: 5}, want: 0},
+		{name: "unset speed uses default", cfg: config.AnimationConfig{Enabled: true}, want: 24000},
+		{name: "slowest", cfg: config.AnimationConfig{Enabled: true, Speed: 1}, want: 120000},
+		{name: "fastest", cfg: config.AnimationConfig{Enabled: true, Speed: 10}, want: 12000},
+		{name: "too fast is clamped", cfg: config.AnimationConfig{Enabled: true, Speed: 1000}, want: 12000},
+		{name: "negative is clamped", cfg: config.AnimationConfig{Enabled: true, Speed: -3}, want: 120000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := playbackMillis(&tt.cfg); got != tt.want {
+				t.Errorf("playbackMillis() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package mapgen
+
+import (
+	"fmt"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// printMargin is the page margin of printed maps in millimeters.
+const printMargin = 10.0
+
+// printHeaderSpace is the height in millimeters left above a landscape map for the
+// title and the stats bar, so both fit on the first page with the map.
+const printHeaderSpace = 50.0
+
+// paperSizes holds the portrait width and height in millimeters of each paper size.
+var paperSizes = map[string][2]float64{
+	"a4":     {210, 297},
+	"letter": {215.9, 279.4},
+}
+
+// PrintLayout sizes the printed page and the map on it.
+//
+// @struct PrintLayout
+// @description Paper and map dimensions of the print stylesheet
+// @property PageSize string CSS @page size, such as "A4 landscape"
+// @property MapWidth string Printed map width in millimeters
+// @property MapHeight string Printed map height in millimeters
+type PrintLayout struct {
+	PageSize  string // @field PageSize CSS @page size
+	MapWidth  string // @field MapWidth Printed map width
+	MapHeight string // @field MapHeight Printed map height
+}
+
+// printLayoutFor fits the map to the printable area of the configured paper. A
+// landscape map spans the page below the header; a portrait map is square, leaving
+// the rest of the page to the stats and legend.
+//
+// @function printLayoutFor
+// @description Computes the print layout of the configured paper size and orientation
+// @param cfg *config.PrintConfig Paper size and orientation (A4 landscape by default)
+// @return PrintLayout Page size and map dimensions for the print stylesheet
+// @internal true
+func printLayoutFor(cfg *config.PrintConfig) PrintLayout {
+	paper, name := cfg.PaperSize, "A4"
+	if paper == "letter" {
+		name = "letter"
+	} else {
+		paper = "a4"
+	}
+	orientation := cfg.Orientation
+	if orientation == "" {
+		orientation = "landscape"
+	}
+
+	size := paperSizes[paper]
+	width, height := size[0]-2*printMargin, size[0]-2*printMargin
+	if orientation == "landscape" {
+		width, height = size[1]-2*printMargin, size[0]-2*printMargin-printHeaderSpace
+	}
+	return PrintLayout{
+		PageSize:  name + " " + orientation,
+		MapWidth:  millimeters(width),
+		MapHeight: millimeters(height),
+	}
+}
+
+// millimeters formats a length as a CSS millimeter value: "277mm", "195.9mm".
+func millimeters(length float64) string {
+	return fmt.Sprintf("%gmm", length)
+}
@@ -0,0 +1,28 @@
+package mapgen
+
+import (
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+func TestPrintLayoutFor(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.PrintConfig
+		want PrintLayout
+	}{
+		{"default", config.PrintConfig{}, PrintLayout{"A4 landscape", "277mm", "140mm"}},
+		{"a4 portrait", config.PrintConfig{PaperSize: "a4", Orientation: "portrait"}, PrintLayout{"A4 portrait", "190mm", "190mm"}},
+		{"letter landscape", config.PrintConfig{PaperSize: "letter"}, PrintLayout{"letter landscape", "259.4mm", "145.9mm"}},
+		{"letter portrait", config.PrintConfig{PaperSize: "letter", Orientation: "portrait"}, PrintLayout{"letter portrait", "195.9mm", "195.9mm"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := printLayoutFor(&tt.cfg); got != tt.want {
+				t.Errorf("printLayoutFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
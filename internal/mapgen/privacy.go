@@ -0,0 +1,131 @@
+package mapgen
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// googleHosts lists the hosts of the Google Maps JavaScript API, without which a
+// Google map cannot render.
+var googleHosts = []string{
+	"maps.googleapis.com",
+	"maps.gstatic.com",
+}
+
+// externalURLPattern matches absolute and protocol-relative URLs and captures the host.
+// Template placeholders such as {s} in tile URLs are kept as part of the host.
+var externalURLPattern = regexp.MustCompile(`(?i)(?:https?:|wss?:|["'(=\s])//([a-z0-9{}-]+(?:\.[a-z0-9{}-]+)+)`)
+
+// xmlnsPattern matches XML namespace declarations, which are identifiers and never fetched.
+var xmlnsPattern = regexp.MustCompile(`(?i)xmlns(?::[a-z]+)?\s*=\s*\\?["'][^"'\\]*\\?["']`)
+
+// privacyEnabled reports whether strict privacy mode applies, either because the
+// binary was built with the "privacy" tag or because the config enables it.
+func privacyEnabled(cfg *config.Config) bool {
+	return strictPrivacyBuild || cfg.Privacy.Strict
+}
+
+// privacyStatement returns the configured privacy statement, or by default one
+// naming the hosts the page loads the map from.
+func privacyStatement(cfg *config.Config, hosts []string) string {
+	if cfg.Privacy.Statement != "" {
+		return cfg.Privacy.Statement
+	}
+	switch len(hosts) {
+	case 0:
+		return "This page loads no fonts, scripts, analytics, map tiles, or other resources from third parties."
+	case 1:
+		return "This page loads no fonts, scripts, analytics, or other resources from third parties " +
+			"other than " + hosts[0] + ", which is required to display the map."
+	default:
+		return "This page loads no fonts, scripts, analytics, or other resources from third parties " +
+			"other than " + strings.Join(hosts[:len(hosts)-1], ", ") + " and " + hosts[len(hosts)-1] +
+			", which are required to display the map."
+	}
+}
+
+// ExternalHosts lists the distinct hosts referenced by URLs in a generated page.
+//
+// @function ExternalHosts
+// @description Finds every remote host the page could contact when opened in a browser
+// @param html []byte Generated HTML document
+// @return []string Sorted, lowercased host names
+// @logic JavaScript-escaped slashes are normalized and XML namespaces are ignored
+// @example hosts := mapgen.ExternalHosts(content)
+func ExternalHosts(html []byte) []string {
+	content := strings.ReplaceAll(string(html), `\/`, "/")
+	content = xmlnsPattern.ReplaceAllString(content, "")
+
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, match := range externalURLPattern.FindAllStringSubmatch(content, -1) {
+		host := strings.ToLower(match[1])
+		if !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// mapHosts lists the sorted, distinct hosts the page loads the map from: the Google
+// Maps API and the fallback provider's tiles, or the tiles, style, and library of the
+// configured provider. Library assets embedded in the page add no hosts.
+func mapHosts(data MapData) []string {
+	var hosts []string
+	switch {
+	case data.Leaflet != nil:
+		hosts = data.Leaflet.hosts()
+	case data.MapLibre != nil:
+		hosts = data.MapLibre.hosts()
+	case data.Cesium != nil:
+		hosts = data.Cesium.hosts()
+	default:
+		hosts = append(hosts, googleHosts...)
+		if data.Fallback != nil {
+			hosts = append(hosts, data.Fallback.hosts()...)
+		}
+	}
+	sort.Strings(hosts)
+	return slices.Compact(hosts)
+}
+
+// allowedHosts lists the hosts a strict privacy page may reference: those it loads
+// the map from and the host of the QR code link.
+func allowedHosts(data MapData) []string {
+	hosts := mapHosts(data)
+	if data.QRCode != nil {
+		hosts = append(hosts, data.QRCode.hosts()...)
+	}
+	return hosts
+}
+
+// auditExternalRequests returns an error naming every host outside the allowed list.
+func auditExternalRequests(html []byte, allowed []string) error {
+	var disallowed []string
+	for _, host := range ExternalHosts(html) {
+		if !containsHost(allowed, host) {
+			disallowed = append(disallowed, host)
+		}
+	}
+	if len(disallowed) > 0 {
+		return fmt.Errorf("page references third-party hosts %s", strings.Join(disallowed, ", "))
+	}
+	return nil
+}
+
+// containsHost reports whether the host appears in the list.
+func containsHost(hosts []string, host string) bool {
+	for _, allowed := range hosts {
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
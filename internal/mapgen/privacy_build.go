@@ -0,0 +1,7 @@
+//go:build privacy
+
+package mapgen
+
+// strictPrivacyBuild forces strict privacy mode for binaries built with -tags privacy,
+// regardless of the privacy.strict setting in the configuration file.
+const strictPrivacyBuild = true
@@ -0,0 +1,6 @@
+//go:build !privacy
+
+package mapgen
+
+// strictPrivacyBuild is false for regular builds; privacy.strict enables the mode instead.
+const strictPrivacyBuild = false
@@ -0,0 +1,194 @@
+package mapgen
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestExternalHosts(t *testing.T) {
+	html := []byte(`<link href="https://fonts.googleapis.com/css?family=Roboto">
+<script src="//cdn.example.com/lib.js"></script>
+<svg xmlns="http://www.w3.org/2000/svg"></svg>
+<div style="background: url('https://Tiles.Example.org/bg.png')"></div>
+<script>
+    // Add direction arrows
+    const icon = "https:\/\/cdn.example.com\/icon.png";
+</script>
+<script src="https://maps.googleapis.com/maps/api/js?key=abc"></script>`)
+
+	want := []string{"cdn.example.com", "fonts.googleapis.com", "maps.googleapis.com", "tiles.example.org"}
+	if got := ExternalHosts(html); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExternalHosts() = %v, want %v", got, want)
+	}
+}
+
+func TestAuditExternalRequests(t *testing.T) {
+	if err := auditExternalRequests([]byte(`<script src="https://maps.googleapis.com/maps/api/js"></script>`), googleHosts); err != nil {
+		t.Errorf("auditExternalRequests() with provider only error = %v", err)
+	}
+
+	err := auditExternalRequests([]byte(`<link href="https://fonts.googleapis.com/css">`), googleHosts)
+	if err == nil || !strings.Contains(err.Error(), "fonts.googleapis.com") {
+		t.Errorf("auditExternalRequests() error = %v, want error naming fonts.googleapis.com", err)
+	}
+}
+
+// TestStrictPrivacyAudit generates a page with every optional feature enabled and
+// checks that it references nothing beyond the map provider.
+func TestStrictPrivacyAudit(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 0, Longitude: 0, Category: "home"},
+		{Timestamp: testTime.Add(10 * time.Minute), Latitude: 0.01, Longitude: 0, Category: "work"},
+		{Timestamp: testTime.Add(20 * time.Minute), Latitude: 0.01, Longitude: 0, Category: "work"},
+	}
+
+	for _, mode := range []string{RenderModeTrail, RenderModeHeatmap} {
+		t.Run(mode, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key", Libraries: []string{"geometry"}},
+				Map:        config.MapConfig{Title: "Privacy Test", RenderMode: mode, RestrictBounds: true},
+				Markers:    config.MarkersConfig{Spiderfy: true},
+				Path: config.PathConfig{
+					Enabled:   true,
+					Animation: config.AnimationConfig{ShowDirectionArrows: true},
+				},
+				Geofences: config.GeofencesConfig{
+					ShowBoundaries: true,
+					Fences:         []config.FenceConfig{{Name: "Home", Center: &config.CoordinateConfig{}, Radius: 100}},
+				},
+				Statistics: config.StatisticsConfig{ShowDuration: true, ShowSpeed: true, ShowSplits: true},
+				Privacy:    config.PrivacyConfig{Strict: true},
+			}
+
+			outputFile := filepath.Join(t.TempDir(), "privacy.html")
+			if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			content, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("Failed to read output file: %v", err)
+			}
+			for _, host := range ExternalHosts(content) {
+				if !containsHost(googleHosts, host) {
+					t.Errorf("generated page references third-party host %q", host)
+				}
+			}
+			if !strings.Contains(string(content), `<footer class="privacy-statement">`) {
+				t.Error("generated page missing privacy statement")
+			}
+		})
+	}
+}
+
+func TestPrivacyStatement(t *testing.T) {
+	tests := []struct {
+		name  string
+		hosts []string
+		want  string
+	}{
+		{name: "google", hosts: googleHosts, want: "other than maps.googleapis.com and maps.gstatic.com, which are required to display the map."},
+		{name: "one host", hosts: []string{"tile.openstreetmap.org"}, want: "other than tile.openstreetmap.org, which is required to display the map."},
+		{name: "three hosts", hosts: []string{"a.example.org", "b.example.org", "c.example.org"}, want: "other than a.example.org, b.example.org and c.example.org, which"},
+		{name: "no hosts", want: "loads no fonts, scripts, analytics, map tiles, or other resources from third parties."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := privacyStatement(&config.Config{}, tt.hosts); !strings.Contains(got, tt.want) {
+				t.Errorf("privacyStatement(%v) = %q, want it to contain %q", tt.hosts, got, tt.want)
+			}
+		})
+	}
+
+	cfg := &config.Config{Privacy: config.PrivacyConfig{Statement: "No tracking here."}}
+	if got := privacyStatement(cfg, googleHosts); got != "No tracking here." {
+		t.Errorf("privacyStatement() = %q, want custom statement", got)
+	}
+
+	if got := privacyEnabled(&config.Config{}); got != strictPrivacyBuild {
+		t.Errorf("privacyEnabled() without config = %v, want %v", got, strictPrivacyBuild)
+	}
+}
+
+// TestStrictPrivacyProviders checks that strict privacy pages of each provider embed
+// the library instead of loading it from a CDN, allow only the hosts of the active
+// provider, and name those hosts in the footer.
+func TestStrictPrivacyProviders(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.7749, Longitude: -122.4194},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.8044, Longitude: -122.2711},
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "library.js")
+	style := filepath.Join(dir, "library.css")
+	if err := os.WriteFile(script, []byte("window.library = {};"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(style, []byte(".map { overflow: hidden; }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	local := config.OutputConfig{LeafletScript: script, LeafletStyle: style, MapLibreScript: script, MapLibreStyle: style}
+
+	tests := []struct {
+		name      string
+		mapCfg    config.MapConfig
+		output    config.OutputConfig
+		wantHosts []string
+		wantErr   string
+	}{
+		{name: "google", wantHosts: googleHosts},
+		{name: "google with leaflet fallback", mapCfg: config.MapConfig{Fallback: config.FallbackConfig{Provider: FallbackLeaflet}}, output: local, wantHosts: []string{"maps.googleapis.com", "maps.gstatic.com", "tile.openstreetmap.org"}},
+		{name: "leaflet", mapCfg: config.MapConfig{Provider: ProviderLeaflet}, output: local, wantHosts: []string{"tile.openstreetmap.org"}},
+		{name: "leaflet without tiles", mapCfg: config.MapConfig{Provider: ProviderLeaflet, TileURL: NoTiles}, output: local},
+		{name: "maplibre", mapCfg: config.MapConfig{Provider: ProviderMapLibre}, output: local, wantHosts: []string{"tiles.openfreemap.org"}},
+		{name: "leaflet from the CDN", mapCfg: config.MapConfig{Provider: ProviderLeaflet}, wantErr: "output.leaflet_script"},
+		{name: "leaflet fallback from the CDN", mapCfg: config.MapConfig{Fallback: config.FallbackConfig{Provider: FallbackLeaflet}}, wantErr: "output.leaflet_script"},
+		{name: "maplibre from the CDN", mapCfg: config.MapConfig{Provider: ProviderMapLibre}, wantErr: "output.maplibre_script"},
+		{name: "cesium", mapCfg: config.MapConfig{Provider: ProviderCesium}, output: local, wantErr: "cesium.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        tt.mapCfg,
+				Output:     tt.output,
+				Privacy:    config.PrivacyConfig{Strict: true},
+			}
+			var buf bytes.Buffer
+			err := NewGenerator(cfg).GenerateTo(&buf, points)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("GenerateTo() error = %v, want mention of %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+
+			html := buf.String()
+			for _, host := range ExternalHosts(buf.Bytes()) {
+				if !containsHost(tt.wantHosts, host) {
+					t.Errorf("page references %s, want only %v", host, tt.wantHosts)
+				}
+			}
+			if !strings.Contains(html, "<script>window.library = {};</script>") && tt.name != "google" {
+				t.Error("page does not embed the map library")
+			}
+			if want := privacyStatement(cfg, tt.wantHosts); !strings.Contains(html, want) {
+				t.Errorf("page footer does not state %q", want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package mapgen
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// Size of the elevation profile drawing in SVG units, matching the viewBox of the
+// chart in the page template. The chart stretches to the page width, and a margin
+// keeps the line off its top and bottom edges.
+const (
+	profileWidth  = 1000
+	profileHeight = 200
+	profileMargin = 10
+)
+
+// Profile is the elevation profile chart drawn under the map for tracks with
+// elevation data.
+//
+// @struct Profile
+// @description Distance vs elevation chart with hover positions
+// @property Distances []float64 Cumulative distance in meters at each point
+// @property Line string SVG polyline points of the profile
+// @property Area string SVG polygon points filling the area under the profile
+// @property Distance float64 Total track distance in meters
+// @property MinElevation float64 Lowest elevation in meters
+// @property MaxElevation float64 Highest elevation in meters
+type Profile struct {
+	Distances    []float64 // @field Distances Cumulative distance in meters at each point
+	Line         string    // @field Line SVG polyline points of the profile
+	Area         string    // @field Area SVG polygon points of the area under the profile
+	Distance     float64   // @field Distance Total track distance in meters
+	MinElevation float64   // @field MinElevation Lowest elevation in meters
+	MaxElevation float64   // @field MaxElevation Highest elevation in meters
+}
+
+// profileFor plots elevation against the distance travelled, measured with the
+// statistics distance method. It returns nil for tracks without elevation data or
+// without any distance to plot.
+//
+// @function profileFor
+// @description Builds the elevation profile chart of a track
+// @param points gps.Points Chronologically sorted GPS points
+// @param cfg *config.StatisticsConfig Distance method for the horizontal axis
+// @return *Profile Chart geometry and ranges (nil without elevation data)
+// @internal true
+func profileFor(points gps.Points, cfg *config.StatisticsConfig) *Profile {
+	if len(points) < 2 || !points.HasElevation() {
+		return nil
+	}
+
+	distanceFn, _ := gps.DistanceFuncFor(cfg.DistanceMethod)
+	profile := &Profile{
+		Distances:    make([]float64, len(points)),
+		MinElevation: points[0].Elevation,
+		MaxElevation: points[0].Elevation,
+	}
+	travelled := make([]float64, len(points))
+	for i, point := range points {
+		if i > 0 {
+			profile.Distance += distanceFn(points[i-1], point)
+		}
+		// Meter precision keeps the embedded distances short
+		travelled[i] = profile.Distance
+		profile.Distances[i] = math.Round(profile.Distance)
+		profile.MinElevation = min(profile.MinElevation, point.Elevation)
+		profile.MaxElevation = max(profile.MaxElevation, point.Elevation)
+	}
+	if profile.Distance == 0 {
+		return nil
+	}
+
+	// Flat tracks are drawn along the middle of the chart
+	rise := profile.MaxElevation - profile.MinElevation
+	coordinates := make([]string, len(points))
+	for i, point := range points {
+		y := float64(profileHeight) / 2
+		if rise > 0 {
+			y = profileHeight - profileMargin - (point.Elevation-profile.MinElevation)/rise*(profileHeight-2*profileMargin)
+		}
+		coordinates[i] = fmt.Sprintf("%.1f,%.1f", travelled[i]/profile.Distance*profileWidth, y)
+	}
+	profile.Line = strings.Join(coordinates, " ")
+	profile.Area = fmt.Sprintf("0,%d %s %d,%d", profileHeight, profile.Line, profileWidth, profileHeight)
+	return profile
+}
+
+// formatElevation renders an elevation given in meters using the configured
+// distance units. Imperial units are converted to feet.
+func formatElevation(meters float64, units string) string {
+	if units == "imperial" {
+		return fmt.Sprintf("%.0f ft", meters*3.28084)
+	}
+	return fmt.Sprintf("%.0f m", meters)
+}
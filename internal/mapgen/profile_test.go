@@ -0,0 +1,67 @@
+package mapgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestProfileFor(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	// Two segments of about 111 m, climbing 100 m and then descending 50 m
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0, Elevation: 100},
+		{Timestamp: start.Add(time.Minute), Latitude: 0.001, Longitude: 0, Elevation: 200},
+		{Timestamp: start.Add(2 * time.Minute), Latitude: 0.002, Longitude: 0, Elevation: 150},
+	}
+
+	profile := profileFor(points, &config.StatisticsConfig{})
+	if profile == nil {
+		t.Fatal("profileFor() = nil, want a profile")
+	}
+	if got := profile.Distances; len(got) != 3 || got[0] != 0 || got[1] != 111 || got[2] != 222 {
+		t.Errorf("Distances = %v, want [0 111 222]", got)
+	}
+	if profile.MinElevation != 100 || profile.MaxElevation != 200 {
+		t.Errorf("elevation range = %v-%v, want 100-200", profile.MinElevation, profile.MaxElevation)
+	}
+	if want := "0.0,190.0 500.0,10.0 1000.0,100.0"; profile.Line != want {
+		t.Errorf("Line = %q, want %q", profile.Line, want)
+	}
+	if want := "0,200 0.0,190.0 500.0,10.0 1000.0,100.0 1000,200"; profile.Area != want {
+		t.Errorf("Area = %q, want %q", profile.Area, want)
+	}
+
+	flat := gps.Points{points[0], points[1]}
+	flat[1].Elevation = 100
+	if got := profileFor(flat, &config.StatisticsConfig{}).Line; got != "0.0,100.0 1000.0,100.0" {
+		t.Errorf("flat Line = %q, want the middle of the chart", got)
+	}
+
+	tests := []struct {
+		name   string
+		points gps.Points
+	}{
+		{name: "single point", points: points[:1]},
+		{name: "no elevation", points: gps.Points{{Latitude: 0, Longitude: 0}, {Latitude: 0.001, Longitude: 0}}},
+		{name: "no distance", points: gps.Points{points[0], points[0]}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileFor(tt.points, &config.StatisticsConfig{}); got != nil {
+				t.Errorf("profileFor() = %+v, want nil", got)
+			}
+		})
+	}
+}
+
+func TestFormatElevation(t *testing.T) {
+	if got := formatElevation(1000, "metric"); got != "1000 m" {
+		t.Errorf("formatElevation(metric) = %q, want 1000 m", got)
+	}
+	if got := formatElevation(1000, "imperial"); got != "3281 ft" {
+		t.Errorf("formatElevation(imperial) = %q, want 3281 ft", got)
+	}
+}
@@ -0,0 +1,55 @@
+package mapgen
+
+import (
+	"html/template"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/qrcode"
+)
+
+// DefaultQRCodeSize is the width and height in pixels of the header QR code.
+const DefaultQRCodeSize = 96
+
+// QRCode is the header QR code linking a printed page to the hosted map.
+//
+// @struct QRCode
+// @description Scannable link to the interactive version of the map
+// @property URL string Hosted map URL encoded in the code
+// @property SVG template.HTML Inline SVG image of the code
+// @property Size int Width and height of the image in pixels
+type QRCode struct {
+	URL  string        // @field URL Hosted map URL encoded in the code
+	SVG  template.HTML // @field SVG Inline SVG image of the code
+	Size int           // @field Size Width and height of the image in pixels
+}
+
+// qrCodeFor encodes the configured hosted map URL as a QR code, or returns nil
+// when the QR code is disabled.
+//
+// @function qrCodeFor
+// @description Builds the header QR code of the page
+// @param cfg *config.QRCodeConfig QR code URL and size
+// @return *QRCode QR code image and link (nil when disabled)
+// @return error Error if the URL is too long for a QR code
+// @internal true
+func qrCodeFor(cfg *config.QRCodeConfig) (*QRCode, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	code, err := qrcode.Encode(cfg.URL)
+	if err != nil {
+		return nil, configErrorf("cannot encode map.qr_code.url: %w", err)
+	}
+	size := cfg.Size
+	if size == 0 {
+		size = DefaultQRCodeSize
+	}
+	// The SVG is generated from module positions only, so it is safe to embed as is
+	return &QRCode{URL: cfg.URL, SVG: template.HTML(code.SVG(size)), Size: size}, nil
+}
+
+// hosts lists the host of the hosted map URL. The page only links to it, so it is
+// allowed in strict privacy and self-contained pages.
+func (q *QRCode) hosts() []string {
+	return ExternalHosts([]byte(q.URL))
+}
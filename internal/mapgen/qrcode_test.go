@@ -0,0 +1,76 @@
+package mapgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestQRCodeFor(t *testing.T) {
+	if code, err := qrCodeFor(&config.QRCodeConfig{URL: "https://maps.example.org/"}); code != nil || err != nil {
+		t.Errorf("qrCodeFor(disabled) = %v, %v, want nil", code, err)
+	}
+
+	code, err := qrCodeFor(&config.QRCodeConfig{Enabled: true, URL: "https://maps.example.org/trip.html"})
+	if err != nil {
+		t.Fatalf("qrCodeFor() error = %v", err)
+	}
+	if code.Size != DefaultQRCodeSize || !strings.HasPrefix(string(code.SVG), `<svg xmlns="http://www.w3.org/2000/svg" width="96" height="96"`) {
+		t.Errorf("qrCodeFor() = size %d, %.80s", code.Size, code.SVG)
+	}
+
+	if _, err := qrCodeFor(&config.QRCodeConfig{Enabled: true, URL: strings.Repeat("x", 3000)}); err == nil {
+		t.Error("qrCodeFor() accepted a URL too long for a QR code")
+	}
+}
+
+func TestQRCodeHeader(t *testing.T) {
+	points := gps.Points{{Latitude: 37.77, Longitude: -122.41}, {Latitude: 37.78, Longitude: -122.41}}
+
+	tests := []struct {
+		name string
+		cfg  config.Config
+	}{
+		{name: "google", cfg: config.Config{GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"}}},
+		{name: "strict privacy", cfg: config.Config{GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"}, Privacy: config.PrivacyConfig{Strict: true}}},
+		{name: "self-contained", cfg: config.Config{GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"}, Output: config.OutputConfig{SelfContained: true}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.Map = config.MapConfig{
+				Title:  "QR Test",
+				QRCode: config.QRCodeConfig{Enabled: true, URL: "https://maps.example.org/trip.html", Size: 120},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(&cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			html := buf.String()
+
+			for _, want := range []string{
+				`<a class="qr-code" href="https://maps.example.org/trip.html" title="Scan to open the interactive map"><svg`,
+				`width="120" height="120"`,
+				"min-height: 120px;",
+			} {
+				if !strings.Contains(html, want) {
+					t.Errorf("generated HTML missing %q", want)
+				}
+			}
+		})
+	}
+
+	// Without a QR code the header keeps its layout
+	cfg := &config.Config{GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"}, Map: config.MapConfig{Title: "QR Test"}}
+	var buf bytes.Buffer
+	if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+		t.Fatalf("GenerateTo() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "qr-code") {
+		t.Error("generated HTML has a QR code although it is disabled")
+	}
+}
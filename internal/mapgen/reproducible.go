@@ -0,0 +1,33 @@
+package mapgen
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// SourceDateEpochEnv names the environment variable that fixes the generation time
+// for reproducible builds (see https://reproducible-builds.org/specs/source-date-epoch/).
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// GenerationTime returns the time shown as the generation time of a page: the Unix
+// timestamp in SOURCE_DATE_EPOCH when set, so CI pipelines can rebuild identical
+// files, otherwise the current time. The result is in UTC.
+//
+// @function GenerationTime
+// @description Returns the reproducible generation timestamp
+// @return time.Time Generation time in UTC
+// @return error Error if SOURCE_DATE_EPOCH is not a Unix timestamp
+// @example generatedAt, err := mapgen.GenerationTime()
+func GenerationTime() (time.Time, error) {
+	value := os.Getenv(SourceDateEpochEnv)
+	if value == "" {
+		return time.Now().UTC(), nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: must be a Unix timestamp", SourceDateEpochEnv, value)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
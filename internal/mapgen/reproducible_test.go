@@ -0,0 +1,98 @@
+package mapgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// TestReproducibleOutput generates feature-rich pages twice and checks the bytes match.
+func TestReproducibleOutput(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.77, Longitude: -122.41, Category: "home", User: "alice"},
+		{Timestamp: testTime.Add(10 * time.Minute), Latitude: 37.78, Longitude: -122.42, Category: "work", User: "bob"},
+		{Timestamp: testTime.Add(20 * time.Minute), Latitude: 37.79, Longitude: -122.43, Category: "cafe", User: "carol"},
+		{Timestamp: testTime.Add(30 * time.Minute), Latitude: 37.80, Longitude: -122.44, Category: "work", User: "alice"},
+	}
+
+	for _, provider := range []string{"google", "leaflet", "maplibre", "cesium"} {
+		t.Run(provider, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Reproducible", Provider: provider},
+				Path:       config.PathConfig{Enabled: true},
+				Geofences: config.GeofencesConfig{
+					ShowBoundaries: true,
+					Fences: []config.FenceConfig{
+						{Name: "Office", Center: &config.CoordinateConfig{Latitude: 37.78, Longitude: -122.42}, Radius: 200},
+						{Name: "Home", Center: &config.CoordinateConfig{Latitude: 37.77, Longitude: -122.41}, Radius: 100},
+					},
+				},
+				Statistics: config.StatisticsConfig{ShowDuration: true, ShowSpeed: true},
+			}
+
+			generate := func() []byte {
+				var buf bytes.Buffer
+				if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+					t.Fatalf("GenerateTo() error = %v", err)
+				}
+				return buf.Bytes()
+			}
+
+			first := generate()
+			for i := 0; i < 5; i++ {
+				if !bytes.Equal(first, generate()) {
+					t.Fatal("repeated generation produced different output")
+				}
+			}
+			if bytes.Contains(first, []byte(`class="generated-at"`)) {
+				t.Error("output contains a generation timestamp without output.timestamp")
+			}
+		})
+	}
+}
+
+func TestGenerationTimestamp(t *testing.T) {
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Trip"},
+		Output:     config.OutputConfig{Timestamp: true},
+	}
+	points := gps.Points{{Latitude: 1, Longitude: 2}, {Latitude: 1.1, Longitude: 2.1}}
+
+	t.Setenv(SourceDateEpochEnv, "1700000000")
+	var buf bytes.Buffer
+	if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+		t.Fatalf("GenerateTo() error = %v", err)
+	}
+	if want := "Generated 2023-11-14 22:13 UTC"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q", want)
+	}
+
+	t.Setenv(SourceDateEpochEnv, "yesterday")
+	if err := NewGenerator(cfg).GenerateTo(&bytes.Buffer{}, points); err == nil || !strings.Contains(err.Error(), SourceDateEpochEnv) {
+		t.Errorf("GenerateTo() with invalid %s error = %v", SourceDateEpochEnv, err)
+	}
+}
+
+func TestGenerationTime(t *testing.T) {
+	t.Setenv(SourceDateEpochEnv, "")
+	before := time.Now()
+	got, err := GenerationTime()
+	if err != nil {
+		t.Fatalf("GenerationTime() error = %v", err)
+	}
+	if got.Before(before.Truncate(time.Second)) || got.Location() != time.UTC {
+		t.Errorf("GenerationTime() = %v, want current UTC time", got)
+	}
+
+	t.Setenv(SourceDateEpochEnv, "0")
+	if got, err := GenerationTime(); err != nil || !got.Equal(time.Unix(0, 0)) {
+		t.Errorf("GenerationTime() = %v, %v, want Unix epoch", got, err)
+	}
+}
@@ -0,0 +1,93 @@
+package mapgen
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// Assets holds the script and stylesheet of a JavaScript mapping library, loaded
+// from a CDN or embedded in self-contained and strict privacy pages.
+//
+// @struct Assets
+// @description Library files referenced or embedded by the page
+// @property ScriptURL string JavaScript library URL
+// @property StyleURL string Library stylesheet URL
+// @property InlineScript template.JS JavaScript embedded in self-contained and strict privacy pages
+// @property InlineStyle template.CSS Stylesheet embedded in self-contained and strict privacy pages
+type Assets struct {
+	ScriptURL    string       // @field ScriptURL JavaScript URL (empty when inlined)
+	StyleURL     string       // @field StyleURL Stylesheet URL (empty when inlined)
+	InlineScript template.JS  // @field InlineScript Embedded JavaScript (self-contained or strict privacy only)
+	InlineStyle  template.CSS // @field InlineStyle Embedded stylesheet (self-contained or strict privacy only)
+}
+
+// inlineReason returns why the page must embed library assets instead of loading
+// them from a CDN, or "" when it may load them.
+func inlineReason(cfg *config.Config) string {
+	switch {
+	case cfg.Output.SelfContained:
+		return "self-contained output"
+	case privacyEnabled(cfg):
+		return "strict privacy mode"
+	default:
+		return ""
+	}
+}
+
+// inline reads local copies of the library script and stylesheet and embeds them,
+// replacing the CDN URLs. The reason, library name, and option names appear in errors.
+func (a *Assets) inline(reason, library, scriptFile, styleFile, scriptOption, styleOption string) error {
+	if scriptFile == "" || styleFile == "" {
+		return configErrorf("%s with a %s map needs local copies of its script "+
+			"and stylesheet (set %s and %s)", reason, library, scriptOption, styleOption)
+	}
+
+	script, err := os.ReadFile(scriptFile)
+	if err != nil {
+		return fmt.Errorf("cannot read %s script: %w", library, err)
+	}
+	style, err := os.ReadFile(styleFile)
+	if err != nil {
+		return fmt.Errorf("cannot read %s stylesheet: %w", library, err)
+	}
+
+	a.InlineScript = template.JS(script)
+	a.InlineStyle = template.CSS(style)
+	a.ScriptURL, a.StyleURL = "", ""
+	return nil
+}
+
+// inlineLeaflet embeds the local Leaflet files configured for self-contained output.
+func inlineLeaflet(leaflet *Leaflet, reason string, cfg *config.OutputConfig) error {
+	return leaflet.inline(reason, "Leaflet", cfg.LeafletScript, cfg.LeafletStyle, "output.leaflet_script", "output.leaflet_style")
+}
+
+// inlineMapLibre embeds the local MapLibre GL files configured for self-contained output.
+func inlineMapLibre(mapLibre *MapLibre, reason string, cfg *config.OutputConfig) error {
+	return mapLibre.inline(reason, "MapLibre", cfg.MapLibreScript, cfg.MapLibreStyle, "output.maplibre_script", "output.maplibre_style")
+}
+
+// selfContainedHosts lists the hosts a self-contained page may still reference: the
+// map provider's API and, for a Leaflet or MapLibre map, its tile server or style. Map imagery is
+// only embedded as Leaflet offline tiles, so these are the only network requests the archived page makes.
+func selfContainedHosts(data MapData) []string {
+	var hosts []string
+	switch {
+	case data.Leaflet != nil:
+		hosts = data.Leaflet.tileHosts()
+	case data.MapLibre != nil:
+		hosts = data.MapLibre.tileHosts()
+	default:
+		hosts = append(hosts, googleHosts...)
+		if data.Fallback != nil {
+			hosts = append(hosts, data.Fallback.tileHosts()...)
+		}
+	}
+	if data.QRCode != nil {
+		hosts = append(hosts, data.QRCode.hosts()...)
+	}
+	return hosts
+}
@@ -0,0 +1,112 @@
+package mapgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestSelfContainedGeneration(t *testing.T) {
+	testTime := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: testTime, Latitude: 37.7749, Longitude: -122.4194},
+		{Timestamp: testTime.Add(time.Hour), Latitude: 37.8044, Longitude: -122.2711},
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "leaflet.js")
+	style := filepath.Join(dir, "leaflet.css")
+	if err := os.WriteFile(script, []byte("window.L = {version: 'test'};"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(style, []byte(".leaflet-container { overflow: hidden; }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map: config.MapConfig{
+			Title:    "Archive",
+			Fallback: config.FallbackConfig{Provider: FallbackLeaflet},
+		},
+		Output: config.OutputConfig{SelfContained: true, LeafletScript: script, LeafletStyle: style},
+	}
+
+	outputFile := filepath.Join(dir, "map.html")
+	if err := NewGenerator(cfg).Generate(context.Background(), points, outputFile); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+
+	for _, want := range []string{
+		"<script>window.L = {version: 'test'};</script>",
+		"<style>.leaflet-container { overflow: hidden; }</style>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("generated HTML missing inlined asset %q", want)
+		}
+	}
+	if strings.Contains(html, "unpkg.com") {
+		t.Error("self-contained HTML still loads Leaflet from the CDN")
+	}
+	for _, host := range ExternalHosts(content) {
+		if !containsHost([]string{"maps.googleapis.com", "tile.openstreetmap.org"}, host) {
+			t.Errorf("self-contained HTML references %s", host)
+		}
+	}
+}
+
+func TestSelfContainedErrors(t *testing.T) {
+	points := gps.Points{{Latitude: 37.7749, Longitude: -122.4194}}
+	outputFile := filepath.Join(t.TempDir(), "map.html")
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want string
+	}{
+		{
+			name: "leaflet fallback without local copies",
+			cfg: &config.Config{
+				Map:    config.MapConfig{Fallback: config.FallbackConfig{Provider: FallbackLeaflet}},
+				Output: config.OutputConfig{SelfContained: true},
+			},
+			want: "output.leaflet_script",
+		},
+		{
+			name: "maplibre without local copies",
+			cfg: &config.Config{
+				Map:    config.MapConfig{Provider: ProviderMapLibre},
+				Output: config.OutputConfig{SelfContained: true},
+			},
+			want: "output.maplibre_script",
+		},
+		{
+			name: "external reference in page content",
+			cfg: &config.Config{
+				Map:    config.MapConfig{Title: "Photos at https://photos.example.com/album"},
+				Output: config.OutputConfig{SelfContained: true},
+			},
+			want: "photos.example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewGenerator(tt.cfg).Generate(context.Background(), points, outputFile)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Generate() error = %v, want mention of %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package mapgen
+
+import (
+	"fmt"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// PathColorBySpeed colors each path segment by its speed (path.style.color_by).
+const PathColorBySpeed = "speed"
+
+// DefaultSpeedGradient runs from blue for the slowest segments to red for the fastest.
+var DefaultSpeedGradient = []string{"#0000FF", "#FF0000"}
+
+// speedColorSteps is the number of distinct colors taken from the gradient. Segments
+// with the same color are drawn as one line, so a few steps keep long tracks fast.
+const speedColorSteps = 10
+
+// SpeedScale maps the speed of each path segment to a color of the gradient.
+//
+// @struct SpeedScale
+// @description Speed coloring of the path with the range shown in the legend
+// @property Colors []string Hex color of each segment, from point i to point i+1
+// @property Gradient []string Gradient stops from slow to fast
+// @property Min float64 Slowest segment speed in km/h
+// @property Max float64 Fastest segment speed in km/h
+type SpeedScale struct {
+	Colors   []string // @field Colors Hex color of each segment in path order
+	Gradient []string // @field Gradient Gradient stops from slow to fast
+	Min      float64  // @field Min Slowest segment speed in km/h
+	Max      float64  // @field Max Fastest segment speed in km/h
+}
+
+// speedScaleFor colors the segments between consecutive points by speed, from the
+// first gradient color at the slowest segment to the last at the fastest. Segments
+// without elapsed time take the color of the segment before them. It returns nil
+// unless path.style.color_by is speed and the track has a segment.
+//
+// @function speedScaleFor
+// @description Computes per-segment speed colors for the path
+// @param points gps.Points Chronologically sorted GPS points
+// @param cfg *config.Config Path style and statistics distance method
+// @return *SpeedScale Segment colors and speed range (nil when not coloring by speed)
+// @return error Error if a gradient color is not a hex color
+// @internal true
+func speedScaleFor(points gps.Points, cfg *config.Config) (*SpeedScale, error) {
+	if cfg.Path.Style.ColorBy != PathColorBySpeed || len(points) < 2 {
+		return nil, nil
+	}
+
+	gradient := cfg.Path.Style.Gradient
+	if len(gradient) == 0 {
+		gradient = DefaultSpeedGradient
+	}
+	stops := make([][3]float64, len(gradient))
+	for i, color := range gradient {
+		rgb, err := config.ParseHexColor(color)
+		if err != nil {
+			return nil, configErrorf("invalid path gradient: %w", err)
+		}
+		stops[i] = rgb
+	}
+
+	distanceFn, _ := gps.DistanceFuncFor(cfg.Statistics.DistanceMethod)
+	speeds := make([]float64, len(points)-1)
+	known := make([]bool, len(speeds))
+	scale := &SpeedScale{Gradient: gradient, Colors: make([]string, len(speeds))}
+	found := false
+	for i := range speeds {
+		elapsed := points[i+1].Timestamp.Sub(points[i].Timestamp)
+		if elapsed <= 0 {
+			continue
+		}
+		speeds[i] = distanceFn(points[i], points[i+1]) / elapsed.Seconds() * 3.6
+		if !found {
+			scale.Min, scale.Max, found = speeds[i], speeds[i], true
+		}
+		scale.Min, scale.Max = min(scale.Min, speeds[i]), max(scale.Max, speeds[i])
+		known[i] = true
+	}
+
+	color := interpolateColor(stops, 0)
+	for i, speed := range speeds {
+		if known[i] {
+			fraction := 0.0
+			if scale.Max > scale.Min {
+				fraction = (speed - scale.Min) / (scale.Max - scale.Min)
+			}
+			step := float64(int(fraction*(speedColorSteps-1)+0.5)) / (speedColorSteps - 1)
+			color = interpolateColor(stops, step)
+		}
+		scale.Colors[i] = color
+	}
+	return scale, nil
+}
+
+// interpolateColor returns the hex color at fraction (0-1) along the gradient stops.
+func interpolateColor(stops [][3]float64, fraction float64) string {
+	if len(stops) == 1 {
+		return formatHexColor(stops[0])
+	}
+	position := fraction * float64(len(stops)-1)
+	i := min(int(position), len(stops)-2)
+	t := position - float64(i)
+
+	var rgb [3]float64
+	for c := range rgb {
+		rgb[c] = stops[i][c] + (stops[i+1][c]-stops[i][c])*t
+	}
+	return formatHexColor(rgb)
+}
+
+// formatHexColor formats red, green, and blue values as #RRGGBB.
+func formatHexColor(rgb [3]float64) string {
+	return fmt.Sprintf("#%02X%02X%02X", int(rgb[0]+0.5), int(rgb[1]+0.5), int(rgb[2]+0.5))
+}
@@ -0,0 +1,94 @@
+package mapgen
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestSpeedScaleFor(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	// Segments of about 111 m in 100 s (slow), 111 m in 10 s (fast), and no elapsed time
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0},
+		{Timestamp: start.Add(100 * time.Second), Latitude: 0.001, Longitude: 0},
+		{Timestamp: start.Add(110 * time.Second), Latitude: 0.002, Longitude: 0},
+		{Timestamp: start.Add(110 * time.Second), Latitude: 0.002, Longitude: 0.001},
+	}
+
+	tests := []struct {
+		name     string
+		style    config.PathStyleConfig
+		points   gps.Points
+		want     []string
+		wantNil  bool
+		wantFail bool
+	}{
+		{name: "single color", style: config.PathStyleConfig{}, points: points, wantNil: true},
+		{name: "one point", style: config.PathStyleConfig{ColorBy: PathColorBySpeed}, points: points[:1], wantNil: true},
+		{
+			name:   "default gradient",
+			style:  config.PathStyleConfig{ColorBy: PathColorBySpeed},
+			points: points,
+			want:   []string{"#0000FF", "#FF0000", "#FF0000"},
+		},
+		{
+			name:   "three stops",
+			style:  config.PathStyleConfig{ColorBy: PathColorBySpeed, Gradient: []string{"#00F", "#0F0", "#F00"}},
+			points: points,
+			want:   []string{"#0000FF", "#FF0000", "#FF0000"},
+		},
+		{
+			name:     "named color",
+			style:    config.PathStyleConfig{ColorBy: PathColorBySpeed, Gradient: []string{"blue", "red"}},
+			points:   points,
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Path: config.PathConfig{Style: tt.style}}
+			scale, err := speedScaleFor(tt.points, cfg)
+			if (err != nil) != tt.wantFail {
+				t.Fatalf("speedScaleFor() error = %v, want error %v", err, tt.wantFail)
+			}
+			if tt.wantFail {
+				return
+			}
+			if (scale == nil) != tt.wantNil {
+				t.Fatalf("speedScaleFor() = %+v, want nil %v", scale, tt.wantNil)
+			}
+			if scale == nil {
+				return
+			}
+			if !slices.Equal(scale.Colors, tt.want) {
+				t.Errorf("Colors = %v, want %v", scale.Colors, tt.want)
+			}
+			if scale.Min < 3.9 || scale.Min > 4.1 || scale.Max < 39 || scale.Max > 41 {
+				t.Errorf("speed range = %.1f-%.1f km/h, want about 4-40", scale.Min, scale.Max)
+			}
+		})
+	}
+}
+
+func TestInterpolateColor(t *testing.T) {
+	stops := [][3]float64{{0, 0, 255}, {0, 255, 0}, {255, 0, 0}}
+	tests := []struct {
+		fraction float64
+		want     string
+	}{
+		{0, "#0000FF"},
+		{0.25, "#008080"},
+		{0.5, "#00FF00"},
+		{1, "#FF0000"},
+	}
+	for _, tt := range tests {
+		if got := interpolateColor(stops, tt.fraction); got != tt.want {
+			t.Errorf("interpolateColor(%v) = %s, want %s", tt.fraction, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package mapgen
+
+import (
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// colocatedRadius is the distance in meters within which markers are stacked
+// and spread apart by spiderfying, closer than GPS fixes can be told apart.
+const colocatedRadius = 1.0
+
+// colocatedGroups finds the markers stacked on top of each other, looking up the
+// neighbors of each point in a spatial index rather than comparing every pair.
+//
+// @function colocatedGroups
+// @description Groups points within colocatedRadius of each other for spiderfying
+// @param points gps.Points GPS points in marker order
+// @return map[int]int Group of each stacked point, named by the index of its first point
+// (points without a neighbor are left out)
+// @internal true
+func colocatedGroups(points gps.Points) map[int]int {
+	index := gps.NewIndex(points)
+	groups := make(map[int]int)
+	for i, p := range points {
+		if _, ok := groups[i]; ok {
+			continue
+		}
+		neighbors := index.Within(p.Latitude, p.Longitude, colocatedRadius)
+		if len(neighbors) < 2 {
+			continue
+		}
+		for _, n := range neighbors {
+			if _, ok := groups[n.Index]; !ok {
+				groups[n.Index] = i
+			}
+		}
+	}
+	return groups
+}
@@ -0,0 +1,41 @@
+package mapgen
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestColocatedGroups(t *testing.T) {
+	points := gps.Points{
+		{Latitude: 37.77, Longitude: -122.41},
+		{Latitude: 37.78, Longitude: -122.41},
+		{Latitude: 37.770001, Longitude: -122.41}, // 11 cm from the first point
+		{Latitude: 37.78, Longitude: -122.41},
+		{Latitude: 37.79, Longitude: -122.41},
+		{Latitude: 37.77, Longitude: -122.41},
+	}
+
+	want := map[int]int{0: 0, 2: 0, 5: 0, 1: 1, 3: 1}
+	if got := colocatedGroups(points); !reflect.DeepEqual(got, want) {
+		t.Errorf("colocatedGroups() = %v, want %v", got, want)
+	}
+	if got := colocatedGroups(points[4:5]); len(got) != 0 {
+		t.Errorf("colocatedGroups() of a single point = %v, want none", got)
+	}
+}
+
+func BenchmarkColocatedGroups(b *testing.B) {
+	// A month of hourly check-ins, often from the same few places
+	rng := rand.New(rand.NewSource(1))
+	points := make(gps.Points, 20000)
+	for i := range points {
+		points[i] = gps.Point{Latitude: 37.7 + float64(rng.Intn(500))*0.0001, Longitude: -122.4 + float64(rng.Intn(500))*0.0001}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		colocatedGroups(points)
+	}
+}
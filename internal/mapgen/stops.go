@@ -0,0 +1,78 @@
+package mapgen
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/stops"
+)
+
+// Diameter of stop markers in pixels: the smallest is drawn for a stop of the
+// minimum duration, growing with each doubling of the dwell time up to the largest.
+const (
+	minStopMarkerSize  = 24
+	maxStopMarkerSize  = 48
+	stopMarkerSizeStep = 6
+)
+
+// StopMarker is a detected stop with the size and label of its marker.
+//
+// @struct StopMarker
+// @description Stop drawn as a marker sized by its dwell time
+// @property Stop stops.Stop Arrival, departure, location, and points of the stop
+// @property Size int Marker diameter in pixels
+// @property Label string Short dwell time shown on the marker, such as "25m" or "2h10"
+type StopMarker struct {
+	stops.Stop        // @field Stop Detected stop
+	Size       int    // @field Size Marker diameter in pixels
+	Label      string // @field Label Short dwell time shown on the marker
+}
+
+// stopMarkersFor detects the stops of the track and sizes their markers by dwell
+// time, so longer stays stand out on the map.
+//
+// @function stopMarkersFor
+// @description Detects stops and sizes a marker for each
+// @param points gps.Points Chronologically sorted GPS points
+// @param cfg *config.StopsConfig Stop radius and minimum duration
+// @return []StopMarker Stop markers in arrival order (nil without stops)
+// @internal true
+func stopMarkersFor(points gps.Points, cfg *config.StopsConfig) []StopMarker {
+	minDuration := time.Duration(cfg.MinDurationSeconds) * time.Second
+	if minDuration <= 0 {
+		minDuration = stops.DefaultMinDuration
+	}
+
+	var markers []StopMarker
+	for _, stop := range stops.DetectPoints(points, cfg) {
+		markers = append(markers, StopMarker{
+			Stop:  stop,
+			Size:  stopMarkerSize(stop.Duration(), minDuration),
+			Label: stopLabel(stop.Duration()),
+		})
+	}
+	return markers
+}
+
+// stopMarkerSize grows the marker by a step for every doubling of the dwell time
+// beyond the minimum duration.
+func stopMarkerSize(dwell, minDuration time.Duration) int {
+	doublings := math.Log2(float64(dwell) / float64(minDuration))
+	size := minStopMarkerSize + int(math.Round(max(doublings, 0)*stopMarkerSizeStep))
+	return min(size, maxStopMarkerSize)
+}
+
+// stopLabel abbreviates a dwell time to fit inside a marker.
+func stopLabel(dwell time.Duration) string {
+	minutes := int(dwell.Round(time.Minute).Minutes())
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("%dh", minutes/60)
+	}
+	return fmt.Sprintf("%dh%02d", minutes/60, minutes%60)
+}
@@ -0,0 +1,62 @@
+package mapgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestStopMarkerSize(t *testing.T) {
+	tests := []struct {
+		dwell time.Duration
+		want  int
+	}{
+		{5 * time.Minute, 24},
+		{10 * time.Minute, 30},
+		{20 * time.Minute, 36},
+		{2 * time.Hour, 48},
+		{24 * time.Hour, 48},
+	}
+	for _, tt := range tests {
+		if got := stopMarkerSize(tt.dwell, 5*time.Minute); got != tt.want {
+			t.Errorf("stopMarkerSize(%v) = %d, want %d", tt.dwell, got, tt.want)
+		}
+	}
+}
+
+func TestStopLabel(t *testing.T) {
+	tests := []struct {
+		dwell time.Duration
+		want  string
+	}{
+		{5*time.Minute + 20*time.Second, "5m"},
+		{59 * time.Minute, "59m"},
+		{2 * time.Hour, "2h"},
+		{2*time.Hour + 5*time.Minute, "2h05"},
+	}
+	for _, tt := range tests {
+		if got := stopLabel(tt.dwell); got != tt.want {
+			t.Errorf("stopLabel(%v) = %q, want %q", tt.dwell, got, tt.want)
+		}
+	}
+}
+
+func TestStopMarkersFor(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.770, Longitude: -122.41},
+		{Timestamp: start.Add(5 * time.Minute), Latitude: 37.780, Longitude: -122.41},
+		{Timestamp: start.Add(25 * time.Minute), Latitude: 37.7801, Longitude: -122.41},
+		{Timestamp: start.Add(30 * time.Minute), Latitude: 37.790, Longitude: -122.41},
+	}
+
+	markers := stopMarkersFor(points, &config.StopsConfig{Enabled: true})
+	if len(markers) != 1 {
+		t.Fatalf("stopMarkersFor() returned %d markers, want 1", len(markers))
+	}
+	if markers[0].Size != 36 || markers[0].Label != "20m" {
+		t.Errorf("stop marker size and label = %d, %q, want 36, \"20m\"", markers[0].Size, markers[0].Label)
+	}
+}
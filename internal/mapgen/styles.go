@@ -0,0 +1,74 @@
+package mapgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+)
+
+// StylePresetDark is the map.style_json value selecting the built-in dark style.
+const StylePresetDark = "dark"
+
+// darkStyle is a night theme for Google Maps: dark land and water with muted labels.
+const darkStyle = `[
+  {"elementType": "geometry", "stylers": [{"color": "#242f3e"}]},
+  {"elementType": "labels.text.stroke", "stylers": [{"color": "#242f3e"}]},
+  {"elementType": "labels.text.fill", "stylers": [{"color": "#746855"}]},
+  {"featureType": "administrative.locality", "elementType": "labels.text.fill", "stylers": [{"color": "#d59563"}]},
+  {"featureType": "poi", "elementType": "labels.text.fill", "stylers": [{"color": "#d59563"}]},
+  {"featureType": "poi.park", "elementType": "geometry", "stylers": [{"color": "#263c3f"}]},
+  {"featureType": "poi.park", "elementType": "labels.text.fill", "stylers": [{"color": "#6b9a76"}]},
+  {"featureType": "road", "elementType": "geometry", "stylers": [{"color": "#38414e"}]},
+  {"featureType": "road", "elementType": "geometry.stroke", "stylers": [{"color": "#212a37"}]},
+  {"featureType": "road", "elementType": "labels.text.fill", "stylers": [{"color": "#9ca5b3"}]},
+  {"featureType": "road.highway", "elementType": "geometry", "stylers": [{"color": "#746855"}]},
+  {"featureType": "road.highway", "elementType": "geometry.stroke", "stylers": [{"color": "#1f2835"}]},
+  {"featureType": "road.highway", "elementType": "labels.text.fill", "stylers": [{"color": "#f3d19c"}]},
+  {"featureType": "transit", "elementType": "geometry", "stylers": [{"color": "#2f3948"}]},
+  {"featureType": "transit.station", "elementType": "labels.text.fill", "stylers": [{"color": "#d59563"}]},
+  {"featureType": "water", "elementType": "geometry", "stylers": [{"color": "#17263c"}]},
+  {"featureType": "water", "elementType": "labels.text.fill", "stylers": [{"color": "#515c6d"}]},
+  {"featureType": "water", "elementType": "labels.text.stroke", "stylers": [{"color": "#17263c"}]}
+]`
+
+// googleStylesFor resolves map.style_json into the styles option of a Google map:
+// the built-in dark preset, an inline JSON array, or the path of a file holding one,
+// such as a style exported from the Google Maps styling wizard. The JSON is checked
+// and compacted; an empty value leaves the default style.
+//
+// @function googleStylesFor
+// @description Loads a custom Google Maps style or the dark preset
+// @param value string map.style_json setting
+// @return template.JS Style array for the map options (empty for the default style)
+// @return error Error if the file cannot be read or is not a JSON array
+// @internal true
+func googleStylesFor(value string) (template.JS, error) {
+	value = strings.TrimSpace(value)
+	source := "map.style_json"
+	switch {
+	case value == "":
+		return "", nil
+	case value == StylePresetDark:
+		value = darkStyle
+	case !strings.HasPrefix(value, "["):
+		content, err := os.ReadFile(value)
+		if err != nil {
+			return "", fmt.Errorf("cannot read map style: %w", err)
+		}
+		source, value = value, string(content)
+	}
+
+	// Unmarshalling into a slice rejects anything but an array of style rules
+	var rules []map[string]any
+	if err := json.Unmarshal([]byte(value), &rules); err != nil {
+		return "", configErrorf("invalid map style in %s (want a JSON array of style rules): %w", source, err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(value)); err != nil {
+		return "", err
+	}
+	return template.JS(compact.String()), nil
+}
@@ -0,0 +1,47 @@
+package mapgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoogleStylesFor(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "brand.json")
+	if err := os.WriteFile(file, []byte("[\n  {\"featureType\": \"water\", \"stylers\": [{\"color\": \"#003366\"}]}\n]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"styles": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "default style", value: "", want: ""},
+		{name: "dark preset", value: "dark", want: `[{"elementType":"geometry","stylers":[{"color":"#242f3e"}]}`},
+		{name: "inline", value: ` [{"featureType": "poi", "stylers": [{"visibility": "off"}]}] `, want: `[{"featureType":"poi","stylers":[{"visibility":"off"}]}]`},
+		{name: "file", value: file, want: `[{"featureType":"water","stylers":[{"color":"#003366"}]}]`},
+		{name: "missing file", value: filepath.Join(dir, "missing.json"), wantErr: true},
+		{name: "not an array", value: invalid, wantErr: true},
+		{name: "malformed inline", value: `[{"featureType": }]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := googleStylesFor(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("googleStylesFor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.HasPrefix(string(got), tt.want) || (tt.want == "" && got != "") {
+				t.Errorf("googleStylesFor() = %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package mapgen
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// TemplatePage is the page template loaded from a template directory. The other
+// .html files in the directory are parsed as named templates, so the page can
+// include them with {{template "header.html" .}}.
+const TemplatePage = "map.html"
+
+// Partials lists the named blocks of the built-in page that output.partials can
+// replace from files, in page order.
+var Partials = []string{"head", "header", "stats", "legend", "footer", "scripts"}
+
+// parseTemplate parses the page template together with the provider's map-head,
+// map-script, and map-loader definitions. The page comes from output.template: a
+// template file, or the map.html page of a template directory. The built-in page
+// is used when no template is configured or the directory has no map.html.
+// Partials configured in output.partials then replace individual blocks.
+//
+// @method parseTemplate
+// @description Loads the custom or built-in page template
+// @param funcMap template.FuncMap Functions available to every template
+// @return *template.Template Parsed template set ready for execution
+// @return error Error if a template file cannot be read or parsed
+// @internal true
+func (g *Generator) parseTemplate(funcMap template.FuncMap) (*template.Template, error) {
+	page := g.getHTMLTemplate()
+	var helpers []string
+
+	if path := g.templatePath(); path != "" {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read template: %w", err)
+		}
+
+		files := []string{path}
+		if info.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(path, "*.html")); err != nil {
+				return nil, fmt.Errorf("cannot list templates in %s: %w", path, err)
+			}
+			sort.Strings(files)
+		}
+
+		for _, file := range files {
+			if info.IsDir() && filepath.Base(file) != TemplatePage {
+				helpers = append(helpers, file)
+				continue
+			}
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("cannot read template: %w", err)
+			}
+			page = string(content) + g.providerTemplate()
+		}
+	}
+
+	t, err := template.New("map").Funcs(funcMap).Parse(g.minifyText(page))
+	if err != nil {
+		return nil, configErrorf("error parsing template: %w", err)
+	}
+
+	// Helper templates are parsed last so they can also redefine provider templates
+	for _, file := range helpers {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read template: %w", err)
+		}
+		if _, err := t.New(filepath.Base(file)).Parse(g.minifyText(string(content))); err != nil {
+			return nil, configErrorf("error parsing template %s: %w", file, err)
+		}
+	}
+
+	if err := g.parsePartials(t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// parsePartials replaces blocks of the page with the configured partial files,
+// so a custom header or legend does not require replacing the whole template.
+func (g *Generator) parsePartials(t *template.Template) error {
+	if g.config == nil {
+		return nil
+	}
+
+	// Names are checked in sorted order so the same configuration reports the same error
+	names := make([]string, 0, len(g.config.Output.Partials))
+	for name := range g.config.Output.Partials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !slices.Contains(Partials, name) {
+			return configErrorf("unknown template partial %q (available: %s)", name, strings.Join(Partials, ", "))
+		}
+	}
+
+	for _, name := range Partials {
+		file := g.config.Output.Partials[name]
+		if file == "" {
+			continue
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("cannot read %s partial: %w", name, err)
+		}
+		// html/template never replaces a template with an empty one, so an empty
+		// partial removes the block through an action that prints nothing
+		body := string(content)
+		if strings.TrimSpace(body) == "" {
+			body = `{{""}}`
+		}
+		if _, err := t.New(name).Parse(g.minifyText(body)); err != nil {
+			return configErrorf("error parsing %s partial %s: %w", name, file, err)
+		}
+	}
+	return nil
+}
+
+// minifyText minifies template source when output.minify is set.
+func (g *Generator) minifyText(text string) string {
+	if g.config == nil || !g.config.Output.Minify {
+		return text
+	}
+	return minifyTemplate(text)
+}
+
+// templatePath returns the configured custom template file or directory.
+func (g *Generator) templatePath() string {
+	if g.config == nil {
+		return ""
+	}
+	return g.config.Output.Template
+}
@@ -0,0 +1,194 @@
+package mapgen
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestCustomTemplate(t *testing.T) {
+	writeFile := func(t *testing.T, path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page := `<html><head>{{template "map-head" .}}</head><body><h1>Acme {{.Title}}</h1>{{len .Points}} points<script>{{template "map-script" .}}</script>{{template "map-loader" .}}</body></html>`
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string) string
+		want  []string
+	}{
+		{
+			name: "template file",
+			setup: func(t *testing.T, dir string) string {
+				path := filepath.Join(dir, "page.html")
+				writeFile(t, path, page)
+				return path
+			},
+			want: []string{"<h1>Acme Trip</h1>", "2 points", "maps.googleapis.com"},
+		},
+		{
+			name: "template directory with helper",
+			setup: func(t *testing.T, dir string) string {
+				writeFile(t, filepath.Join(dir, TemplatePage), `<html><body>{{template "header.html" .}}<script>{{template "map-script" .}}</script></body></html>`)
+				writeFile(t, filepath.Join(dir, "header.html"), `<header>Acme Corp: {{.Title}}</header>`)
+				return dir
+			},
+			want: []string{"<header>Acme Corp: Trip</header>"},
+		},
+		{
+			name: "directory without page uses built-in",
+			setup: func(t *testing.T, dir string) string {
+				writeFile(t, filepath.Join(dir, "notes.html"), `unused`)
+				return dir
+			},
+			want: []string{`<div class="stats">`, "<h1>Trip</h1>"},
+		},
+		{
+			name:  "no template uses built-in",
+			setup: func(t *testing.T, dir string) string { return "" },
+			want:  []string{`<div class="legend">`},
+		},
+	}
+
+	points := gps.Points{{Latitude: 1, Longitude: 2}, {Latitude: 1.1, Longitude: 2.1}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Trip"},
+				Output:     config.OutputConfig{Template: tt.setup(t, t.TempDir())},
+			}
+
+			var buf bytes.Buffer
+			if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomTemplateErrors(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.html")
+	if err := os.WriteFile(broken, []byte(`{{if .Title}}unterminated`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		want   string
+		config bool // Whether the error is marked as a configuration error
+	}{
+		{"missing file", filepath.Join(dir, "missing.html"), "cannot read template", false},
+		{"parse error", broken, "error parsing template", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Output: config.OutputConfig{Template: tt.path}}
+			var buf bytes.Buffer
+			err := NewGenerator(cfg).GenerateTo(&buf, gps.Points{{Latitude: 1, Longitude: 2}})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("GenerateTo() error = %v, want %q", err, tt.want)
+			}
+			if errors.Is(err, ErrConfig) != tt.config {
+				t.Errorf("errors.Is(err, ErrConfig) = %v, want %v", !tt.config, tt.config)
+			}
+			if buf.Len() != 0 {
+				t.Error("GenerateTo() wrote output despite failing")
+			}
+		})
+	}
+}
+
+func TestTemplatePartials(t *testing.T) {
+	dir := t.TempDir()
+	header := filepath.Join(dir, "header.html")
+	if err := os.WriteFile(header, []byte(`<header class="brand">Acme: {{.Title}}</header>`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	empty := filepath.Join(dir, "empty.html")
+	if err := os.WriteFile(empty, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		partials map[string]string
+		want     []string
+		notWant  []string
+		wantErr  string
+	}{
+		{
+			name:     "custom header",
+			partials: map[string]string{"header": header},
+			want:     []string{`<header class="brand">Acme: Trip</header>`, `<div class="stats">`, `<div class="legend">`},
+			notWant:  []string{`<div class="header">`},
+		},
+		{
+			name:     "removed legend",
+			partials: map[string]string{"legend": empty},
+			want:     []string{`<div class="header">`},
+			notWant:  []string{`<div class="legend">`},
+		},
+		{
+			name:     "unknown partial",
+			partials: map[string]string{"sidebar": header},
+			wantErr:  `unknown template partial "sidebar"`,
+		},
+		{
+			name:     "missing file",
+			partials: map[string]string{"stats": filepath.Join(dir, "missing.html")},
+			wantErr:  "cannot read stats partial",
+		},
+	}
+
+	points := gps.Points{{Latitude: 1, Longitude: 2}, {Latitude: 1.1, Longitude: 2.1}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+				Map:        config.MapConfig{Title: "Trip"},
+				Output:     config.OutputConfig{Partials: tt.partials},
+			}
+
+			var buf bytes.Buffer
+			err := NewGenerator(cfg).GenerateTo(&buf, points)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GenerateTo() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateTo() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q", want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(buf.String(), notWant) {
+					t.Errorf("output unexpectedly contains %q", notWant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package mapgen
+
+import (
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// DefaultUserColors is the palette given, in order, to users without a color in
+// users.colors. It avoids the green, red, and blue of the start, end, and regular
+// markers so users stay distinguishable from them.
+var DefaultUserColors = []string{
+	"#E6194B", "#3CB44B", "#4363D8", "#F58231", "#911EB4",
+	"#42D4F4", "#F032E6", "#9A6324", "#469990", "#808000",
+}
+
+// UserColor is the color a user's path and markers are drawn in.
+//
+// @struct UserColor
+// @description Track color of one user of a shared multi-user dataset
+// @property Name string User name from the input's user column
+// @property Color string Hex color of the user's path and markers
+type UserColor struct {
+	Name  string `json:"name"`  // @field Name User name
+	Color string `json:"color"` // @field Color Hex color of the user's track
+}
+
+// userColorsFor gives every user of a multi-user track its color from users.colors,
+// or the next unused color of DefaultUserColors, in the sorted order of the users.
+// The palette repeats when there are more users than colors. It returns nil for
+// tracks with fewer than two users, which keep the configured path color.
+//
+// @function userColorsFor
+// @description Assigns a distinct track color to each user
+// @param points gps.Points GPS points with their users
+// @param colors map[string]string Configured colors by user name
+// @return []UserColor Color of each user in sorted order (nil for single-user tracks)
+// @return error Error if a configured color is not a hex color
+// @internal true
+func userColorsFor(points gps.Points, colors map[string]string) ([]UserColor, error) {
+	users := points.Users()
+	if len(users) < 2 {
+		return nil, nil
+	}
+
+	// Skip palette colors a configured user already has
+	taken := make(map[string]bool, len(colors))
+	for user, color := range colors {
+		rgb, err := config.ParseHexColor(color)
+		if err != nil {
+			return nil, configErrorf("invalid color for user %s: %w", user, err)
+		}
+		taken[formatHexColor(rgb)] = true
+	}
+	var palette []string
+	for _, color := range DefaultUserColors {
+		if !taken[color] {
+			palette = append(palette, color)
+		}
+	}
+	if len(palette) == 0 {
+		palette = DefaultUserColors
+	}
+
+	result := make([]UserColor, len(users))
+	next := 0
+	for i, user := range users {
+		color, ok := colors[user]
+		if !ok {
+			color = palette[next%len(palette)]
+			next++
+		}
+		result[i] = UserColor{Name: user, Color: color}
+	}
+	return result, nil
+}
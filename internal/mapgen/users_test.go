@@ -0,0 +1,52 @@
+package mapgen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestUserColorsFor(t *testing.T) {
+	points := gps.Points{{User: "carol"}, {User: "alice"}, {User: "bob"}, {User: "alice"}}
+
+	tests := []struct {
+		name     string
+		points   gps.Points
+		colors   map[string]string
+		want     []UserColor
+		wantFail bool
+	}{
+		{name: "single user", points: gps.Points{{User: "alice"}, {User: "alice"}}, want: nil},
+		{name: "no users", points: gps.Points{{}, {}}, want: nil},
+		{
+			name:   "palette in user order",
+			points: points,
+			want:   []UserColor{{"alice", "#E6194B"}, {"bob", "#3CB44B"}, {"carol", "#4363D8"}},
+		},
+		{
+			name:   "configured colors skip their palette entry",
+			points: points,
+			colors: map[string]string{"bob": "#e6194b", "dave": "#000"},
+			want:   []UserColor{{"alice", "#3CB44B"}, {"bob", "#e6194b"}, {"carol", "#4363D8"}},
+		},
+		{
+			name:     "named color",
+			points:   points,
+			colors:   map[string]string{"alice": "red"},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := userColorsFor(tt.points, tt.colors)
+			if (err != nil) != tt.wantFail {
+				t.Fatalf("userColorsFor() error = %v, want error %v", err, tt.wantFail)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userColorsFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
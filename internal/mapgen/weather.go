@@ -0,0 +1,109 @@
+package mapgen
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/weather"
+)
+
+// WeatherSummary is the weather during the track shown in the stats bar.
+//
+// @struct WeatherSummary
+// @description Temperature range and prevailing conditions of a track
+// @property Low float64 Lowest hourly temperature during the track
+// @property High float64 Highest hourly temperature during the track
+// @property Unit string Temperature unit (°C or °F)
+// @property Conditions string Most frequent conditions, such as "Partly cloudy"
+type WeatherSummary struct {
+	Low        float64 // @field Low Lowest hourly temperature
+	High       float64 // @field High Highest hourly temperature
+	Unit       string  // @field Unit Temperature unit
+	Conditions string  // @field Conditions Most frequent conditions
+}
+
+// SetWeather sets the historical weather during the track, shown in the stats bar and
+// in the info windows of the first point recorded in each hour. Pass nil to leave
+// the weather out.
+func (g *Generator) SetWeather(report *weather.Report) {
+	g.weather = report
+}
+
+// weatherSummaryFor summarizes the hourly weather between the first and last timed
+// points. Ties between conditions go to the more severe, higher weather code.
+//
+// @function weatherSummaryFor
+// @description Computes the stats bar weather of a track
+// @param report *weather.Report Hourly weather of the track's days
+// @param points gps.Points Chronologically sorted GPS points
+// @return *WeatherSummary Weather during the track (nil without observations)
+// @internal true
+func weatherSummaryFor(report *weather.Report, points gps.Points) *WeatherSummary {
+	from, to, ok := timeSpan(points)
+	if report == nil || !ok {
+		return nil
+	}
+	hourly := report.Between(from, to)
+	if len(hourly) == 0 {
+		return nil
+	}
+
+	summary := &WeatherSummary{Low: hourly[0].Temperature, High: hourly[0].Temperature, Unit: report.Unit}
+	counts := make(map[int]int)
+	prevailing := hourly[0].Code
+	for _, observation := range hourly {
+		summary.Low = min(summary.Low, observation.Temperature)
+		summary.High = max(summary.High, observation.Temperature)
+		counts[observation.Code]++
+		if count := counts[observation.Code]; count > counts[prevailing] || (count == counts[prevailing] && observation.Code > prevailing) {
+			prevailing = observation.Code
+		}
+	}
+	summary.Conditions = weather.Conditions(prevailing)
+	return summary
+}
+
+// hourlyWeather annotates the first point each user recorded in every hour with the
+// weather of that hour, keyed by point index.
+func hourlyWeather(report *weather.Report, points gps.Points) map[int]string {
+	if report == nil {
+		return nil
+	}
+	annotated := make(map[int]string)
+	lastHour := make(map[string]time.Time)
+	for i, point := range points {
+		if point.Timestamp.IsZero() {
+			continue
+		}
+		hour := point.Timestamp.UTC().Truncate(time.Hour)
+		if last, ok := lastHour[point.User]; ok && last.Equal(hour) {
+			continue
+		}
+		lastHour[point.User] = hour
+		if observation, ok := report.At(hour); ok {
+			annotated[i] = fmt.Sprintf("%.0f %s, %s", observation.Temperature, report.Unit, observation.Conditions())
+		}
+	}
+	if len(annotated) == 0 {
+		return nil
+	}
+	return annotated
+}
+
+// timeSpan returns the earliest and latest timestamps of the timed points.
+func timeSpan(points gps.Points) (from, to time.Time, ok bool) {
+	for _, point := range points {
+		if point.Timestamp.IsZero() {
+			continue
+		}
+		if !ok || point.Timestamp.Before(from) {
+			from = point.Timestamp
+		}
+		if !ok || point.Timestamp.After(to) {
+			to = point.Timestamp
+		}
+		ok = true
+	}
+	return from, to, ok
+}
@@ -0,0 +1,74 @@
+package mapgen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/weather"
+)
+
+func testWeatherReport(start time.Time) *weather.Report {
+	return &weather.Report{Unit: weather.Celsius, Hourly: []weather.Observation{
+		{Time: start.Add(-time.Hour), Temperature: 9, Code: 0},
+		{Time: start, Temperature: 12, Code: 3},
+		{Time: start.Add(time.Hour), Temperature: 14.4, Code: 61},
+		{Time: start.Add(2 * time.Hour), Temperature: 13, Code: 3},
+	}}
+}
+
+func TestWeatherSummaryFor(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	report := testWeatherReport(start)
+	points := gps.Points{
+		{Timestamp: start.Add(10 * time.Minute)},
+		{Timestamp: start.Add(2*time.Hour + 5*time.Minute)},
+	}
+
+	got := weatherSummaryFor(report, points)
+	if got == nil || got.Low != 12 || got.High != 14.4 || got.Conditions != "Overcast" || got.Unit != weather.Celsius {
+		t.Errorf("weatherSummaryFor() = %+v, want 12 to 14.4 °C, Overcast", got)
+	}
+
+	// A tie goes to the more severe conditions
+	if got := weatherSummaryFor(report, points[:1]); got == nil || got.Conditions != "Overcast" {
+		t.Errorf("weatherSummaryFor(one hour) = %+v", got)
+	}
+	if got := weatherSummaryFor(report, gps.Points{{Timestamp: start.Add(time.Hour)}, {Timestamp: start.Add(150 * time.Minute)}}); got == nil || got.Conditions != "Light rain" {
+		t.Errorf("weatherSummaryFor(tie) = %+v, want Light rain", got)
+	}
+
+	if got := weatherSummaryFor(nil, points); got != nil {
+		t.Errorf("weatherSummaryFor(nil) = %+v, want nil", got)
+	}
+	if got := weatherSummaryFor(report, gps.Points{{Timestamp: start.Add(24 * time.Hour)}}); got != nil {
+		t.Errorf("weatherSummaryFor(no observations) = %+v, want nil", got)
+	}
+}
+
+func TestHourlyWeather(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start.Add(5 * time.Minute), User: "alice"},
+		{Timestamp: start.Add(10 * time.Minute), User: "bob"},
+		{Timestamp: start.Add(20 * time.Minute), User: "alice"},
+		{Timestamp: start.Add(65 * time.Minute), User: "alice"},
+		{User: "alice"},
+		{Timestamp: start.Add(5 * time.Hour), User: "alice"},
+	}
+
+	got := hourlyWeather(testWeatherReport(start), points)
+	want := map[int]string{0: "12 °C, Overcast", 1: "12 °C, Overcast", 3: "14 °C, Light rain"}
+	if len(got) != len(want) {
+		t.Fatalf("hourlyWeather() = %v, want %v", got, want)
+	}
+	for index, text := range want {
+		if got[index] != text {
+			t.Errorf("hourlyWeather()[%d] = %q, want %q", index, got[index], text)
+		}
+	}
+
+	if got := hourlyWeather(nil, points); got != nil {
+		t.Errorf("hourlyWeather(nil) = %v, want nil", got)
+	}
+}
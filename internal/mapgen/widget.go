@@ -0,0 +1,79 @@
+package mapgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/config"
+)
+
+// Default iframe size of the embed snippet when the map size is not configured.
+const (
+	DefaultWidgetWidth  = "100%"
+	DefaultWidgetHeight = "500px"
+)
+
+// embedSnippet is the init script embedding a widget page. It replaces its own
+// script tag (or fills the element named by data-target) with an iframe of the page,
+// resolved relative to the script's URL so both files can be hosted anywhere together.
+const embedSnippet = `// Embeds the %[1]s map. Copy this file next to %[2]s and add to any page:
+//   <script src="%[3]s" data-width="100%%" data-height="400px"></script>
+// or place the map in an existing element with data-target="#selector".
+(function () {
+    var script = document.currentScript;
+    var iframe = document.createElement('iframe');
+    iframe.src = new URL(%[4]s, script.src).href;
+    iframe.title = %[5]s;
+    iframe.loading = 'lazy';
+    iframe.allowFullscreen = true;
+    iframe.style.border = '0';
+    iframe.style.width = script.getAttribute('data-width') || %[6]s;
+    iframe.style.height = script.getAttribute('data-height') || %[7]s;
+
+    var target = script.getAttribute('data-target');
+    var container = target && document.querySelector(target);
+    if (container) {
+        container.appendChild(iframe);
+    } else {
+        script.parentNode.insertBefore(iframe, script);
+    }
+})();
+`
+
+// WriteEmbed writes the JavaScript snippet that embeds a widget page in another
+// website through an iframe. The page is referenced by its file name, relative to
+// the snippet, and the iframe defaults to the configured map size.
+//
+// @function WriteEmbed
+// @description Writes the init script embedding a widget map page
+// @param w io.Writer Destination for the script
+// @param page string Path of the widget HTML page
+// @param snippet string Path the script is written to, used in its usage comment
+// @param cfg *config.Config Configuration providing the title and map size
+// @return error Error if writing fails
+// @example err := mapgen.WriteEmbed(file, "map.html", "map.embed.js", cfg)
+func WriteEmbed(w io.Writer, page, snippet string, cfg *config.Config) error {
+	width, height := cfg.Map.Width, cfg.Map.Height
+	if width == "" {
+		width = DefaultWidgetWidth
+	}
+	if height == "" {
+		height = DefaultWidgetHeight
+	}
+
+	pageName := filepath.Base(page)
+	// The title also appears in a line comment, so it is kept on one line
+	_, err := fmt.Fprintf(w, embedSnippet,
+		strings.Join(strings.Fields(cfg.Map.Title), " "), pageName, filepath.Base(snippet),
+		jsString(pageName), jsString(cfg.Map.Title), jsString(width), jsString(height))
+	return err
+}
+
+// jsString quotes s as a JavaScript string literal.
+func jsString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
@@ -0,0 +1,69 @@
+package mapgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestWidgetPage(t *testing.T) {
+	points := gps.Points{{Latitude: 1, Longitude: 2, Title: "Cafe"}, {Latitude: 1.1, Longitude: 2.1}}
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Map:        config.MapConfig{Title: "Trip", Width: "800px", Height: "600px"},
+		Output:     config.OutputConfig{Widget: true},
+		Privacy:    config.PrivacyConfig{Strict: true},
+	}
+
+	var buf bytes.Buffer
+	if err := NewGenerator(cfg).GenerateTo(&buf, points); err != nil {
+		t.Fatalf("GenerateTo() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{`<div id="map"></div>`, "height: 100%;", "Cafe", `class="privacy-statement"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("widget page missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{`<div class="header">`, `<div class="stats">`, `<div class="legend">`} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("widget page contains %q", unwanted)
+		}
+	}
+}
+
+func TestWriteEmbed(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.MapConfig
+		want []string
+	}{
+		{
+			name: "configured size",
+			cfg:  config.MapConfig{Title: "Trip \"2025\"\nday 1", Width: "800px", Height: "600px"},
+			want: []string{`new URL("trip.html", script.src)`, `iframe.title = "Trip \"2025\"\nday 1";`, `|| "800px";`, `|| "600px";`, "// Embeds the Trip \"2025\" day 1 map.", `<script src="trip.embed.js"`},
+		},
+		{
+			name: "default size",
+			want: []string{`|| "100%";`, `|| "500px";`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteEmbed(&buf, "out/trip.html", "out/trip.embed.js", &config.Config{Map: tt.cfg}); err != nil {
+				t.Fatalf("WriteEmbed() error = %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("WriteEmbed() missing %q\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package mapgen
+
+import (
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// DefaultZoom is the initial zoom level used when auto-fit handles the viewport.
+const DefaultZoom = 13
+
+// zoomPresets maps the maximum track diagonal (meters) to a Google Maps zoom level,
+// ordered from the most compact extent to the widest.
+var zoomPresets = []struct {
+	maxDiagonal float64
+	zoom        int
+}{
+	{1000, 16},   // Street: a short walk
+	{5000, 14},   // Neighborhood
+	{20000, 12},  // City
+	{100000, 10}, // Metro area
+	{500000, 8},  // Region
+	{2000000, 6}, // Country
+	{8000000, 4}, // Continent
+}
+
+// worldZoom is used for tracks wider than every preset.
+const worldZoom = 2
+
+// zoomPreset estimates a zoom level from the diagonal distance of the track's bounding box.
+//
+// @function zoomPreset
+// @description Picks a street/city/region/country zoom preset from the track extent
+// @param points gps.Points GPS points to frame
+// @return int Google Maps zoom level (2-16)
+// @internal true
+func zoomPreset(points gps.Points) int {
+	minLat, maxLat, minLng, maxLng := points.Bounds()
+	diagonal := gps.Point{Latitude: minLat, Longitude: minLng}.DistanceTo(gps.Point{Latitude: maxLat, Longitude: maxLng})
+
+	for _, preset := range zoomPresets {
+		if diagonal < preset.maxDiagonal {
+			return preset.zoom
+		}
+	}
+	return worldZoom
+}
+
+// initialZoom resolves the zoom level written into the page. A configured zoom always
+// wins; otherwise the extent-based preset is used when auto-fit is disabled, since
+// fitBounds would replace it anyway.
+func (g *Generator) initialZoom(points gps.Points) int {
+	if g.config.Map.InitialView.Zoom != nil {
+		return *g.config.Map.InitialView.Zoom
+	}
+	if !g.config.Map.AutoFitBounds && !points.IsEmpty() {
+		return zoomPreset(points)
+	}
+	return DefaultZoom
+}
@@ -0,0 +1,101 @@
+package mapgen
+
+import (
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestZoomPreset(t *testing.T) {
+	tests := []struct {
+		name   string
+		points gps.Points
+		want   int
+	}{
+		{
+			name:   "single point",
+			points: gps.Points{{Latitude: 37.77, Longitude: -122.41}},
+			want:   16,
+		},
+		{
+			name: "city walk (~3 km)",
+			points: gps.Points{
+				{Latitude: 37.770, Longitude: -122.420},
+				{Latitude: 37.790, Longitude: -122.400},
+			},
+			want: 14,
+		},
+		{
+			name: "regional drive (~130 km)",
+			points: gps.Points{
+				{Latitude: 37.77, Longitude: -122.41},
+				{Latitude: 38.58, Longitude: -121.49},
+			},
+			want: 8,
+		},
+		{
+			name: "cross-country flight",
+			points: gps.Points{
+				{Latitude: 37.77, Longitude: -122.41},
+				{Latitude: 40.71, Longitude: -74.00},
+			},
+			want: 4,
+		},
+		{
+			name: "around the world",
+			points: gps.Points{
+				{Latitude: -60, Longitude: -170},
+				{Latitude: 60, Longitude: 10},
+			},
+			want: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := zoomPreset(tt.points); got != tt.want {
+				t.Errorf("zoomPreset() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitialZoom(t *testing.T) {
+	points := gps.Points{
+		{Latitude: 37.770, Longitude: -122.420},
+		{Latitude: 37.790, Longitude: -122.400},
+	}
+	configured := 9
+
+	tests := []struct {
+		name string
+		cfg  config.MapConfig
+		want int
+	}{
+		{
+			name: "configured zoom wins",
+			cfg:  config.MapConfig{InitialView: config.InitialViewConfig{Zoom: &configured}},
+			want: 9,
+		},
+		{
+			name: "auto-fit uses default",
+			cfg:  config.MapConfig{AutoFitBounds: true},
+			want: DefaultZoom,
+		},
+		{
+			name: "no auto-fit uses preset",
+			cfg:  config.MapConfig{AutoFitBounds: false},
+			want: 14,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen := NewGenerator(&config.Config{Map: tt.cfg})
+			if got := gen.initialZoom(points); got != tt.want {
+				t.Errorf("initialZoom() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+// Package metrics counts the work of serve mode for Prometheus.
+//
+// @title Metrics Package
+// @version 1.0
+// @description Counters and histograms exposed in the Prometheus text format
+// @description Written without the Prometheus client library to stay free of dependencies
+//
+// Features:
+// - Counters, labeled counters, and histograms safe for concurrent use
+// - Tracks processed, points ingested, rows skipped, map render time, and API requests
+// - HTTP handler serving every metric of a registry at /metrics
+// - HTTP transport counting requests to external services by host and status
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ContentType is the media type of the Prometheus text format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the render time histogram.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Default is the registry of the metrics below, served by Handler.
+var Default = NewRegistry()
+
+// Metrics of serve mode. They count in every mode, but only serve mode exposes them.
+var (
+	TracksProcessed   = Default.NewCounter("geochrono_tracks_processed_total", "Tracks read and processed, including reloads of the input file and uploads.")
+	PointsIngested    = Default.NewCounter("geochrono_points_ingested_total", "GPS points read from processed tracks, before filtering.")
+	ParseErrors       = Default.NewCounter("geochrono_parse_errors_total", "Input rows skipped because they could not be parsed.")
+	GenerationSeconds = Default.NewHistogram("geochrono_generation_duration_seconds", "Time taken to render a map.", DefaultBuckets)
+	APIRequests       = Default.NewCounterVec("geochrono_api_requests_total", "Requests to road snapping, weather, tile, and static map services.", "host", "code")
+)
+
+// metric is one registered metric family.
+type metric interface {
+	write(w io.Writer, name string)
+}
+
+// family is a registered metric with its help text and type.
+type family struct {
+	name   string // Metric name, such as geochrono_tracks_processed_total
+	help   string // Description on the HELP line
+	kind   string // counter or histogram
+	metric metric // Current values
+}
+
+// Registry holds metrics to expose together.
+type Registry struct {
+	mu       sync.Mutex
+	families []family
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// register adds a metric family, keeping the families sorted by name.
+func (r *Registry) register(name, help, kind string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.families = append(r.families, family{name: name, help: help, kind: kind, metric: m})
+	sort.Slice(r.families, func(i, j int) bool { return r.families[i].name < r.families[j].name })
+}
+
+// WriteTo writes every metric of the registry in the Prometheus text format.
+//
+// @method WriteTo
+// @description Renders the registry for a Prometheus scrape
+// @param w io.Writer Destination, such as an HTTP response
+// @return int64 Bytes written
+// @return error Error if writing fails
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	families := append([]family(nil), r.families...)
+	r.mu.Unlock()
+
+	counter := &countingWriter{w: w}
+	buf := bufio.NewWriter(counter)
+	for _, f := range families {
+		fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s %s\n", f.name, escape(f.help, false), f.name, f.kind)
+		f.metric.write(buf, f.name)
+	}
+	err := buf.Flush()
+	return counter.n, err
+}
+
+// ServeHTTP serves the registry to Prometheus.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", ContentType)
+	r.WriteTo(w)
+}
+
+// Handler returns the HTTP handler serving the default registry.
+//
+// @function Handler
+// @description Serves the metrics of serve mode
+// @return http.Handler Handler for /metrics
+// @example mux.Handle("/metrics", metrics.Handler())
+func Handler() http.Handler {
+	return Default
+}
+
+// Counter is a count that only goes up.
+type Counter struct {
+	value atomic.Uint64
+}
+
+// NewCounter registers a counter.
+//
+// @method NewCounter
+// @description Creates and registers a counter
+// @param name string Metric name, ending in _total by convention
+// @param help string Description of what is counted
+// @return *Counter Counter starting at zero
+// @example uploads := registry.NewCounter("geochrono_uploads_total", "Tracks uploaded.")
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{}
+	r.register(name, help, "counter", c)
+	return c
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add adds n to the counter; negative values are ignored.
+func (c *Counter) Add(n int) {
+	if n > 0 {
+		c.value.Add(uint64(n))
+	}
+}
+
+// Value returns the current count.
+func (c *Counter) Value() uint64 {
+	return c.value.Load()
+}
+
+func (c *Counter) write(w io.Writer, name string) {
+	fmt.Fprintf(w, "%s %d\n", name, c.Value())
+}
+
+// CounterVec is a family of counters told apart by label values.
+type CounterVec struct {
+	labels   []string            // Label names
+	mu       sync.Mutex          // Guards counters
+	counters map[string]*Counter // Counters by label values joined with labelSeparator
+}
+
+// labelSeparator joins label values into map keys; it cannot occur in UTF-8 text.
+const labelSeparator = "\xff"
+
+// NewCounterVec registers a family of counters with the given label names.
+//
+// @method NewCounterVec
+// @description Creates and registers labeled counters
+// @param name string Metric name, ending in _total by convention
+// @param help string Description of what is counted
+// @param labels ...string Label names, such as "host" and "code"
+// @return *CounterVec Family without any counters yet
+// @example requests := registry.NewCounterVec("geochrono_api_requests_total", "Requests.", "host", "code")
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	v := &CounterVec{labels: labels, counters: map[string]*Counter{}}
+	r.register(name, help, "counter", v)
+	return v
+}
+
+// With returns the counter for the label values, given in the order of the label
+// names, creating it at zero the first time.
+func (v *CounterVec) With(values ...string) *Counter {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %d label values for %d labels", len(values), len(v.labels)))
+	}
+	key := strings.Join(values, labelSeparator)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	c, ok := v.counters[key]
+	if !ok {
+		c = &Counter{}
+		v.counters[key] = c
+	}
+	return c
+}
+
+func (v *CounterVec) write(w io.Writer, name string) {
+	v.mu.Lock()
+	keys := make([]string, 0, len(v.counters))
+	for key := range v.counters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	counters := make([]*Counter, len(keys))
+	for i, key := range keys {
+		counters[i] = v.counters[key]
+	}
+	v.mu.Unlock()
+
+	for i, key := range keys {
+		pairs := make([]string, len(v.labels))
+		for j, value := range strings.Split(key, labelSeparator) {
+			pairs[j] = v.labels[j] + `="` + escape(value, true) + `"`
+		}
+		fmt.Fprintf(w, "%s{%s} %d\n", name, strings.Join(pairs, ","), counters[i].Value())
+	}
+}
+
+// Histogram counts observations, such as durations, in cumulative buckets.
+type Histogram struct {
+	bounds []float64 // Upper bounds of the buckets, ascending
+	mu     sync.Mutex
+	counts []uint64 // Observations per bucket, not cumulative
+	sum    float64  // Sum of all observations
+	count  uint64   // Number of observations
+}
+
+// NewHistogram registers a histogram with the given ascending bucket bounds.
+//
+// @method NewHistogram
+// @description Creates and registers a histogram
+// @param name string Metric name, with the unit as suffix, such as _seconds
+// @param help string Description of what is observed
+// @param buckets []float64 Ascending upper bounds; a +Inf bucket is always added
+// @return *Histogram Histogram without observations
+// @example latency := registry.NewHistogram("geochrono_upload_duration_seconds", "Upload time.", metrics.DefaultBuckets)
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	h := &Histogram{bounds: buckets, counts: make([]uint64, len(buckets)+1)}
+	r.register(name, help, "histogram", h)
+	return h
+}
+
+// Observe records one value.
+func (h *Histogram) Observe(value float64) {
+	i := sort.SearchFloat64s(h.bounds, value)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[i]++
+	h.sum += value
+	h.count++
+}
+
+// ObserveSince records the seconds elapsed since start.
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+func (h *Histogram) write(w io.Writer, name string) {
+	h.mu.Lock()
+	counts := append([]uint64(nil), h.counts...)
+	sum, count := h.sum, h.count
+	h.mu.Unlock()
+
+	var cumulative uint64
+	for i, bound := range append(h.bounds, math.Inf(1)) {
+		cumulative += counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(bound), cumulative)
+	}
+	fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n", name, formatFloat(sum), name, count)
+}
+
+// Transport is an HTTP transport counting each request in APIRequests by host
+// and status code, or "error" when no response arrived.
+//
+// @struct Transport
+// @description http.RoundTripper counting requests to external services
+// @property Base http.RoundTripper Transport making the requests (nil for http.DefaultTransport)
+// @example http.DefaultTransport = &metrics.Transport{Base: http.DefaultTransport}
+type Transport struct {
+	Base http.RoundTripper // @field Base Transport making the requests
+}
+
+// RoundTrip makes the request with the base transport and counts it.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	APIRequests.With(req.URL.Host, code).Inc()
+	return resp, err
+}
+
+// formatFloat formats a value as Prometheus expects, with +Inf for infinity.
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escape escapes backslashes and line breaks, and double quotes in label values.
+func escape(s string, quotes bool) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	if quotes {
+		s = strings.ReplaceAll(s, `"`, `\"`)
+	}
+	return s
+}
+
+// countingWriter counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
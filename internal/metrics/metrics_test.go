@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	registry := NewRegistry()
+	uploads := registry.NewCounter("test_uploads_total", "Tracks uploaded.")
+	requests := registry.NewCounterVec("test_requests_total", "Requests.", "host", "code")
+	latency := registry.NewHistogram("test_duration_seconds", "Render time.", []float64{0.1, 1})
+
+	uploads.Inc()
+	uploads.Add(2)
+	uploads.Add(-5)
+	requests.With("roads.example.com", "200").Add(3)
+	requests.With("roads.example.com", "error").Inc()
+	requests.With(`odd"host`, "200").Inc()
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(0.1)
+	latency.Observe(30)
+
+	var out bytes.Buffer
+	n, err := registry.WriteTo(&out)
+	if err != nil || n != int64(out.Len()) {
+		t.Fatalf("WriteTo() = %d, %v, want %d bytes", n, err, out.Len())
+	}
+
+	want := `# HELP test_duration_seconds Render time.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{le="0.1"} 2
+test_duration_seconds_bucket{le="1"} 3
+test_duration_seconds_bucket{le="+Inf"} 4
+test_duration_seconds_sum 30.65
+test_duration_seconds_count 4
+# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{host="odd\"host",code="200"} 1
+test_requests_total{host="roads.example.com",code="200"} 3
+test_requests_total{host="roads.example.com",code="error"} 1
+# HELP test_uploads_total Tracks uploaded.
+# TYPE test_uploads_total counter
+test_uploads_total 3
+`
+	if out.String() != want {
+		t.Errorf("WriteTo() wrote\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != ContentType {
+		t.Fatalf("GET /metrics = %d, %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for _, name := range []string{
+		"geochrono_tracks_processed_total",
+		"geochrono_points_ingested_total",
+		"geochrono_parse_errors_total",
+		"geochrono_generation_duration_seconds",
+		"geochrono_api_requests_total",
+	} {
+		if !strings.Contains(rec.Body.String(), "# TYPE "+name+" ") {
+			t.Errorf("GET /metrics missing %s", name)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /metrics = %d, want 405", rec.Code)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	before := APIRequests.With(host, "429").Value()
+
+	client := &http.Client{Transport: &Transport{}}
+	resp, err := client.Get(server.URL + "/v1/snap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got := APIRequests.With(host, "429").Value(); got != before+1 {
+		t.Errorf("requests to %s with 429 = %d, want %d", host, got, before+1)
+	}
+
+	// Requests without a response are counted as errors
+	server.Close()
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("request to a closed server succeeded")
+	}
+	if got := APIRequests.With(host, "error").Value(); got != 1 {
+		t.Errorf("failed requests to %s = %d, want 1", host, got)
+	}
+}
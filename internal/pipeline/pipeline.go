@@ -0,0 +1,180 @@
+// Package pipeline provides the ordered filter stages applied to GPS points after loading.
+//
+// @title Processing Pipeline Package
+// @version 1.0
+// @description Runs deduplication, speed and distance filters, smoothing, and simplification
+// @description in a configurable order, reporting how many points each stage removed
+//
+// Features:
+// - Ordered stages from the processing.pipeline configuration list
+// - Default order built from the individual processing settings
+// - Per-stage point counts for logging
+// - Custom stages for library users
+package pipeline
+
+import (
+	"fmt"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// Stage names accepted in the processing.pipeline configuration list.
+const (
+	Dedupe      = "dedupe"
+	MaxSpeed    = "max_speed"
+	MinDistance = "min_distance"
+	Smooth      = "smooth"
+	Simplify    = "simplify"
+)
+
+// DefaultOrder is the order stages run in when no pipeline is configured.
+// Speed filtering comes before thinning so bad fixes cannot become the reference
+// point that later points are measured from.
+var DefaultOrder = []string{Dedupe, MaxSpeed, MinDistance, Smooth, Simplify}
+
+// Defaults used when a stage is listed explicitly but its setting is zero.
+const (
+	DefaultMaxSpeed          = 300.0 // km/h
+	DefaultMinDistance       = 10.0  // meters
+	DefaultSmoothWindow      = 5     // points
+	DefaultSimplifyTolerance = 5.0   // meters
+)
+
+// Stage is a single named step of the pipeline.
+//
+// @struct Stage
+// @description Named transformation of a GPS point collection
+// @property Name string Stage name reported in results
+// @property Apply func(gps.Points) gps.Points Returns the processed points
+type Stage struct {
+	Name  string                      // @field Name Stage name reported in results
+	Apply func(gps.Points) gps.Points // @field Apply Returns the processed points without modifying its input
+}
+
+// Result records the point counts before and after one stage ran.
+//
+// @struct Result
+// @description Per-stage point counts for logging
+// @property Stage string Stage name
+// @property Before int Points passed into the stage
+// @property After int Points returned by the stage
+type Result struct {
+	Stage  string // @field Stage Stage name
+	Before int    // @field Before Points passed into the stage
+	After  int    // @field After Points returned by the stage
+}
+
+// Removed returns the number of points the stage dropped.
+func (r Result) Removed() int {
+	return r.Before - r.After
+}
+
+// Processor runs pipeline stages in order.
+//
+// @struct Processor
+// @description Ordered list of processing stages
+// @property Stages []Stage Stages in execution order
+type Processor struct {
+	Stages []Stage // @field Stages Stages in execution order
+}
+
+// New builds the processor described by the processing configuration.
+// When cfg.Pipeline is empty, the stages enabled by the individual settings run in
+// DefaultOrder. Stages listed in cfg.Pipeline always run, falling back to the package
+// defaults for unset settings.
+//
+// @function New
+// @description Creates the configured processing pipeline
+// @param cfg *config.ProcessingConfig Processing settings and optional stage order
+// @return *Processor Processor ready to run; it may have no stages
+// @return error Error naming an unknown stage
+// @example processor, err := pipeline.New(&cfg.Processing)
+func New(cfg *config.ProcessingConfig) (*Processor, error) {
+	names := cfg.Pipeline
+	explicit := len(names) > 0
+	if !explicit {
+		names = enabledStages(cfg)
+	}
+
+	processor := &Processor{}
+	for _, name := range names {
+		stage, err := newStage(name, cfg, explicit)
+		if err != nil {
+			return nil, err
+		}
+		processor.Stages = append(processor.Stages, stage)
+	}
+	return processor, nil
+}
+
+// Run passes the points through every stage in order. Points should already be
+// sorted chronologically, as the speed filter and smoothing depend on track order.
+//
+// @method Run
+// @description Applies all stages and reports per-stage counts
+// @param points gps.Points Chronologically sorted GPS points
+// @return gps.Points Processed points
+// @return []Result Point counts for each stage in execution order
+// @example points, results := processor.Run(points)
+func (p *Processor) Run(points gps.Points) (gps.Points, []Result) {
+	results := make([]Result, 0, len(p.Stages))
+	for _, stage := range p.Stages {
+		before := len(points)
+		points = stage.Apply(points)
+		results = append(results, Result{Stage: stage.Name, Before: before, After: len(points)})
+	}
+	return points, results
+}
+
+// enabledStages lists the stages switched on by the individual processing settings,
+// in DefaultOrder.
+func enabledStages(cfg *config.ProcessingConfig) []string {
+	enabled := map[string]bool{
+		Dedupe:      cfg.RemoveDuplicates,
+		MaxSpeed:    cfg.MaxSpeedFilter > 0,
+		MinDistance: cfg.MinDistanceFilter > 0,
+		Smooth:      cfg.SmoothPath,
+		Simplify:    cfg.SimplifyTolerance > 0,
+	}
+
+	var names []string
+	for _, name := range DefaultOrder {
+		if enabled[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// newStage creates the named stage from the processing settings. When useDefaults is
+// set, zero settings are replaced by the package defaults.
+func newStage(name string, cfg *config.ProcessingConfig, useDefaults bool) (Stage, error) {
+	orDefault := func(value, fallback float64) float64 {
+		if value <= 0 && useDefaults {
+			return fallback
+		}
+		return value
+	}
+
+	switch name {
+	case Dedupe:
+		return Stage{Name: name, Apply: gps.Points.RemoveDuplicates}, nil
+	case MaxSpeed:
+		limit := orDefault(cfg.MaxSpeedFilter, DefaultMaxSpeed)
+		return Stage{Name: name, Apply: func(p gps.Points) gps.Points { return p.DropSpeeding(limit) }}, nil
+	case MinDistance:
+		spacing := orDefault(cfg.MinDistanceFilter, DefaultMinDistance)
+		return Stage{Name: name, Apply: func(p gps.Points) gps.Points { return p.Thin(spacing) }}, nil
+	case Smooth:
+		window := cfg.SmoothWindow
+		if window <= 0 {
+			window = DefaultSmoothWindow
+		}
+		return Stage{Name: name, Apply: func(p gps.Points) gps.Points { return p.Smooth(window) }}, nil
+	case Simplify:
+		tolerance := orDefault(cfg.SimplifyTolerance, DefaultSimplifyTolerance)
+		return Stage{Name: name, Apply: func(p gps.Points) gps.Points { return p.Simplify(tolerance) }}, nil
+	}
+	return Stage{}, fmt.Errorf("unknown processing pipeline stage %q", name)
+}
@@ -0,0 +1,115 @@
+// Package pipeline_test provides unit tests for the processing pipeline
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func stageNames(p *Processor) []string {
+	var names []string
+	for _, stage := range p.Stages {
+		names = append(names, stage.Name)
+	}
+	return names
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ProcessingConfig
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "nothing enabled",
+			cfg:  config.ProcessingConfig{},
+		},
+		{
+			name: "default order from settings",
+			cfg: config.ProcessingConfig{
+				RemoveDuplicates:  true,
+				MinDistanceFilter: 10,
+				MaxSpeedFilter:    300,
+				SmoothPath:        true,
+			},
+			want: []string{Dedupe, MaxSpeed, MinDistance, Smooth},
+		},
+		{
+			name: "explicit order",
+			cfg:  config.ProcessingConfig{Pipeline: []string{Simplify, Dedupe}},
+			want: []string{Simplify, Dedupe},
+		},
+		{
+			name:    "unknown stage",
+			cfg:     config.ProcessingConfig{Pipeline: []string{"kalman"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := New(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := stageNames(processor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("New() stages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessorRun(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0},
+		{Timestamp: start.Add(time.Minute), Latitude: 0},           // duplicate
+		{Timestamp: start.Add(2 * time.Minute), Latitude: 0.00001}, // ~1 m away
+		{Timestamp: start.Add(3 * time.Minute), Latitude: 1},       // impossible jump
+		{Timestamp: start.Add(4 * time.Minute), Latitude: 0.001},   // ~110 m away
+	}
+
+	processor, err := New(&config.ProcessingConfig{Pipeline: []string{Dedupe, MaxSpeed, MinDistance}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	got, results := processor.Run(points)
+	if len(got) != 2 || got[1].Latitude != 0.001 {
+		t.Errorf("Run() = %+v, want the first and last points", got)
+	}
+
+	want := []Result{
+		{Stage: Dedupe, Before: 5, After: 4},
+		{Stage: MaxSpeed, Before: 4, After: 3},
+		{Stage: MinDistance, Before: 3, After: 2},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Run() results = %+v, want %+v", results, want)
+	}
+	if results[0].Removed() != 1 {
+		t.Errorf("Result.Removed() = %d, want 1", results[0].Removed())
+	}
+}
+
+func TestProcessorRunCustomStage(t *testing.T) {
+	processor := &Processor{Stages: []Stage{{
+		Name: "stops only",
+		Apply: func(p gps.Points) gps.Points {
+			return p.Filter(func(point gps.Point) bool { return point.Category == "stop" })
+		},
+	}}}
+
+	got, results := processor.Run(gps.Points{{Category: "stop"}, {Category: "moving"}})
+	if len(got) != 1 || results[0].Removed() != 1 {
+		t.Errorf("Run() = %+v, %+v, want one stop kept", got, results)
+	}
+}
@@ -0,0 +1,193 @@
+// Package progress provides progress bars for long-running operations.
+//
+// @title Progress Reporting Package
+// @version 1.0
+// @description Draws a self-updating status line, such as on a terminal's stderr
+// @description Shows counts, percentages of a known total, or transferred bytes
+//
+// Features:
+// - Row and point counts for operations of unknown length
+// - Percentage bars when the total is known up front
+// - Byte counts in KiB, MiB, and GiB for downloads
+// - Redraws limited to a few per second, and nothing at all for quick operations
+// - A nil Bar is valid and reports nothing, so callers need no checks
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bytes is the unit of bars counting transferred data, shown in KiB, MiB, or GiB.
+const Bytes = "bytes"
+
+// Default timing of bar redraws.
+const (
+	DefaultDelay    = 500 * time.Millisecond // Time before the first draw
+	DefaultInterval = 100 * time.Millisecond // Shortest time between redraws
+)
+
+// barWidth is the number of cells in a percentage bar.
+const barWidth = 30
+
+// Bar reports the progress of one operation on a single line, redrawn in place.
+// Operations that finish within the delay draw nothing, so quick runs stay quiet.
+// All methods may be called from several goroutines, and on a nil Bar, which
+// does nothing.
+//
+// @struct Bar
+// @description Progress line for a counted operation
+// @property Delay time.Duration Time before the first draw
+// @property Interval time.Duration Shortest time between redraws
+type Bar struct {
+	Delay    time.Duration // @field Delay Time before the first draw
+	Interval time.Duration // @field Interval Shortest time between redraws
+
+	mu      sync.Mutex
+	w       io.Writer // Destination, such as os.Stderr
+	label   string    // Operation shown before the progress
+	unit    string    // Unit of the count, or Bytes
+	total   int64     // Expected count, 0 when unknown
+	current int64     // Count so far
+	start   time.Time // When the operation began
+	drawn   time.Time // When the line was last drawn, zero before the first draw
+	width   int       // Length of the last line drawn, to blank leftovers
+	done    bool      // Finish was called
+}
+
+// New starts a progress bar for an operation. A positive total shows the
+// progress as a percentage bar; otherwise the count alone is shown.
+//
+// @function New
+// @description Starts a progress bar writing to w
+// @param w io.Writer Destination, such as os.Stderr
+// @param label string Operation, such as "Reading CSV"
+// @param total int64 Expected count, or 0 when unknown
+// @param unit string Unit of the count, such as "rows", or Bytes
+// @return *Bar Bar to report progress to
+// @example bar := progress.New(os.Stderr, "Snapping to roads", int64(len(points)), "points")
+func New(w io.Writer, label string, total int64, unit string) *Bar {
+	return &Bar{
+		Delay:    DefaultDelay,
+		Interval: DefaultInterval,
+		w:        w,
+		label:    label,
+		unit:     unit,
+		total:    total,
+		start:    time.Now(),
+	}
+}
+
+// Add advances the bar by n.
+func (b *Bar) Add(n int64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current += n
+	b.maybeDraw()
+}
+
+// Set moves the bar to n.
+func (b *Bar) Set(n int64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.current = n
+	b.maybeDraw()
+}
+
+// Finish draws the final state and ends the line, if the bar was drawn at all.
+// Later calls do nothing.
+func (b *Bar) Finish() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		return
+	}
+	b.done = true
+	if !b.drawn.IsZero() {
+		b.draw(time.Now())
+		fmt.Fprintln(b.w)
+	}
+}
+
+// maybeDraw redraws the line once the delay has passed, at most once per interval.
+func (b *Bar) maybeDraw() {
+	if b.done {
+		return
+	}
+	now := time.Now()
+	if now.Sub(b.start) < b.Delay || (!b.drawn.IsZero() && now.Sub(b.drawn) < b.Interval) {
+		return
+	}
+	b.draw(now)
+}
+
+// draw writes the line over the previous one.
+func (b *Bar) draw(now time.Time) {
+	line := b.String()
+	padding := ""
+	if len(line) < b.width {
+		padding = strings.Repeat(" ", b.width-len(line))
+	}
+	fmt.Fprintf(b.w, "\r%s%s", line, padding)
+	b.width = len(line)
+	b.drawn = now
+}
+
+// String returns the progress line, such as
+// "Snapping to roads [=========>     ] 60% (600/1000 points)".
+func (b *Bar) String() string {
+	if b.total <= 0 {
+		return fmt.Sprintf("%s: %s", b.label, b.amount(b.current))
+	}
+
+	fraction := float64(b.current) / float64(b.total)
+	fraction = min(max(fraction, 0), 1)
+	filled := int(fraction * barWidth)
+	bar := strings.Repeat("=", filled)
+	if filled < barWidth {
+		bar += ">" + strings.Repeat(" ", barWidth-filled-1)
+	}
+	if b.unit == Bytes {
+		return fmt.Sprintf("%s [%s] %3.0f%% (%s of %s)", b.label, bar, fraction*100, FormatBytes(b.current), FormatBytes(b.total))
+	}
+	return fmt.Sprintf("%s [%s] %3.0f%% (%d/%d %s)", b.label, bar, fraction*100, b.current, b.total, b.unit)
+}
+
+// amount formats a count in the bar's unit.
+func (b *Bar) amount(n int64) string {
+	if b.unit == Bytes {
+		return FormatBytes(n)
+	}
+	return fmt.Sprintf("%d %s", n, b.unit)
+}
+
+// FormatBytes formats a byte count in B, KiB, MiB, or GiB.
+//
+// @function FormatBytes
+// @description Formats a byte count for people
+// @param n int64 Number of bytes
+// @return string Size such as "512 B" or "3.4 MiB"
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, prefix := float64(n)/unit, 0
+	for value >= unit && prefix < 2 {
+		value /= unit
+		prefix++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMG"[prefix])
+}
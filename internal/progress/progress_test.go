@@ -0,0 +1,101 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBarString(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		unit    string
+		current int64
+		want    string
+	}{
+		{name: "count", unit: "rows", current: 12345, want: "Reading: 12345 rows"},
+		{name: "bytes", unit: Bytes, current: 3 << 20, want: "Reading: 3.0 MiB"},
+		{name: "start", total: 200, unit: "points", want: "Reading [>                             ]   0% (0/200 points)"},
+		{name: "half", total: 200, unit: "points", current: 100, want: "Reading [===============>              ]  50% (100/200 points)"},
+		{name: "done", total: 200, unit: "points", current: 200, want: "Reading [==============================] 100% (200/200 points)"},
+		{name: "overrun", total: 200, unit: "points", current: 300, want: "Reading [==============================] 100% (300/200 points)"},
+		{name: "bytes of total", total: 2048, unit: Bytes, current: 1024, want: "Reading [===============>              ]  50% (1.0 KiB of 2.0 KiB)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bar := New(&bytes.Buffer{}, "Reading", tt.total, tt.unit)
+			bar.current = tt.current
+			if got := bar.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBarDrawing(t *testing.T) {
+	var out bytes.Buffer
+	bar := New(&out, "Reading", 0, "rows")
+	bar.Delay, bar.Interval = 0, 0
+	bar.Add(5)
+	bar.Set(1000)
+	bar.Add(1)
+	bar.Finish()
+	bar.Finish()
+	bar.Add(1)
+
+	want := "\rReading: 5 rows\rReading: 1000 rows\rReading: 1001 rows\rReading: 1001 rows\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestBarQuickOperation(t *testing.T) {
+	// Operations finished within the delay leave no trace
+	var out bytes.Buffer
+	bar := New(&out, "Reading", 10, "rows")
+	bar.Delay = time.Hour
+	bar.Add(10)
+	bar.Finish()
+	if out.Len() != 0 {
+		t.Errorf("output = %q, want nothing", out.String())
+	}
+}
+
+func TestBarBlanksLongerLine(t *testing.T) {
+	var out bytes.Buffer
+	bar := New(&out, "Reading", 0, Bytes)
+	bar.Delay, bar.Interval = 0, 0
+	bar.Set(2000)
+	bar.Set(10)
+	if !strings.HasSuffix(out.String(), "\rReading: 10 B   ") {
+		t.Errorf("output = %q, want the shorter line padded", out.String())
+	}
+}
+
+func TestNilBar(t *testing.T) {
+	var bar *Bar
+	bar.Add(1)
+	bar.Set(2)
+	bar.Finish()
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5.0 MiB"},
+		{3 << 30, "3.0 GiB"},
+		{2048 << 30, "2048.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package proximity
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// Meeting represents a suggested place for a group of users to meet.
+//
+// @struct Meeting
+// @description Best meeting location for a group based on where everyone was at a given time
+// @property Time time.Time Moment the user positions were taken from
+// @property Point gps.Point Suggested meeting location (geographic median of the positions)
+// @property Positions gps.Points Each user's position at Time, sorted by user
+type Meeting struct {
+	Time      time.Time  // @field Time Moment the user positions were taken from
+	Point     gps.Point  // @field Point Suggested meeting location
+	Positions gps.Points // @field Positions Each user's position at Time, sorted by user
+}
+
+// MaxDistance returns the farthest any user is from the meeting point, in meters.
+func (m Meeting) MaxDistance() float64 {
+	var farthest float64
+	for _, position := range m.Positions {
+		if d := position.DistanceTo(m.Point); d > farthest {
+			farthest = d
+		}
+	}
+	return farthest
+}
+
+// SuggestMeeting computes the best place for users to meet given where each of them
+// was at a moment in time.
+//
+// @function SuggestMeeting
+// @description Finds the location minimizing the total distance the users must travel
+// @param tracks map[string]gps.Points Chronologically sorted track for each user
+// @param at time.Time Moment to take positions from (zero means the latest recorded time)
+// @return Meeting Suggested meeting point and the user positions it was computed from
+// @return bool False when fewer than two users have a known position at that time
+// @logic Each user's position is interpolated from their track, or their last fix if
+// @logic their track ended earlier; users whose track starts after the moment are left out.
+// @logic The meeting point is the geographic median, so no user has to travel disproportionately
+// @example meeting, ok := SuggestMeeting(points.ByUser(), time.Time{})
+func SuggestMeeting(tracks map[string]gps.Points, at time.Time) (Meeting, bool) {
+	if at.IsZero() {
+		for _, track := range tracks {
+			if len(track) > 0 && track[len(track)-1].Timestamp.After(at) {
+				at = track[len(track)-1].Timestamp
+			}
+		}
+	}
+
+	var positions gps.Points
+	for user, track := range tracks {
+		if position, ok := lastKnownPosition(track, at); ok {
+			position.User = user
+			positions = append(positions, position)
+		}
+	}
+	if len(positions) < 2 {
+		return Meeting{}, false
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i].User < positions[j].User })
+
+	lat, lng := positions.GeographicMedian()
+	return Meeting{
+		Time:      at,
+		Point:     gps.Point{Timestamp: at, Latitude: lat, Longitude: lng, Title: "Meeting point"},
+		Positions: positions,
+	}, true
+}
+
+// SuggestMeetingPoints splits multi-user points by their User field and suggests a
+// meeting point at the configured time. Returns nil when no suggestion is possible.
+func SuggestMeetingPoints(points gps.Points, cfg *config.ProximityConfig) (*Meeting, error) {
+	var at time.Time
+	if cfg.MeetingTime != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, cfg.MeetingTime); err != nil {
+			return nil, fmt.Errorf("invalid proximity meeting_time %q: %w", cfg.MeetingTime, err)
+		}
+	}
+
+	meeting, ok := SuggestMeeting(userTracks(points), at)
+	if !ok {
+		return nil, nil
+	}
+	return &meeting, nil
+}
+
+// lastKnownPosition returns where a track was at a moment: interpolated while the
+// track is running, or its final fix once it has ended.
+func lastKnownPosition(track gps.Points, at time.Time) (gps.Point, bool) {
+	if len(track) == 0 || at.Before(track[0].Timestamp) {
+		return gps.Point{}, false
+	}
+	if last := track[len(track)-1]; at.After(last.Timestamp) {
+		return last, true
+	}
+	return track.PositionAt(at, 0)
+}
@@ -0,0 +1,101 @@
+package proximity
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestSuggestMeeting(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	tracks := map[string]gps.Points{
+		"alice": {
+			{Timestamp: start, Latitude: 0, Longitude: 0},
+			{Timestamp: start.Add(time.Hour), Latitude: 0, Longitude: 0.02},
+		},
+		"bob": {
+			{Timestamp: start, Latitude: 0, Longitude: 0.1},
+		},
+		"carol": {
+			{Timestamp: start.Add(2 * time.Hour), Latitude: 5, Longitude: 5},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		wantOK    bool
+		wantUsers int
+		wantLng   float64
+	}{
+		{name: "interpolated and ended tracks", at: start.Add(30 * time.Minute), wantOK: true, wantUsers: 2, wantLng: 0.055},
+		{name: "before second user started", at: start.Add(-time.Minute)},
+		{name: "latest fix by default", wantOK: true, wantUsers: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := SuggestMeeting(tracks, tt.at)
+			if ok != tt.wantOK {
+				t.Fatalf("SuggestMeeting() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if len(got.Positions) != tt.wantUsers {
+				t.Fatalf("SuggestMeeting() used %d positions, want %d", len(got.Positions), tt.wantUsers)
+			}
+			if got.Positions[0].User != "alice" || got.Positions[1].User != "bob" {
+				t.Errorf("SuggestMeeting() positions not sorted by user: %+v", got.Positions)
+			}
+			if tt.wantLng != 0 && math.Abs(got.Point.Longitude-tt.wantLng) > 1e-6 {
+				t.Errorf("SuggestMeeting() lng = %v, want %v", got.Point.Longitude, tt.wantLng)
+			}
+			if tt.at.IsZero() && !got.Time.Equal(start.Add(2*time.Hour)) {
+				t.Errorf("SuggestMeeting() time = %v, want latest fix %v", got.Time, start.Add(2*time.Hour))
+			}
+		})
+	}
+}
+
+func TestMeetingMaxDistance(t *testing.T) {
+	meeting := Meeting{
+		Point: gps.Point{Latitude: 0, Longitude: 0},
+		Positions: gps.Points{
+			{Latitude: 0, Longitude: 0.001},
+			{Latitude: 0.002, Longitude: 0},
+		},
+	}
+
+	if got := meeting.MaxDistance(); math.Abs(got-222.4) > 1 {
+		t.Errorf("Meeting.MaxDistance() = %v, want ~222.4", got)
+	}
+}
+
+func TestSuggestMeetingPoints(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0, User: "alice"},
+		{Timestamp: start, Latitude: 0, Longitude: 0.01, User: "bob"},
+	}
+
+	got, err := SuggestMeetingPoints(points, &config.ProximityConfig{})
+	if err != nil || got == nil {
+		t.Fatalf("SuggestMeetingPoints() = %v, %v, want a meeting", got, err)
+	}
+	if math.Abs(got.Point.Longitude-0.005) > 1e-6 {
+		t.Errorf("SuggestMeetingPoints() lng = %v, want 0.005", got.Point.Longitude)
+	}
+
+	if _, err := SuggestMeetingPoints(points, &config.ProximityConfig{MeetingTime: "noon"}); err == nil {
+		t.Error("SuggestMeetingPoints() with invalid time error = nil, want error")
+	}
+
+	got, err = SuggestMeetingPoints(points[:1], &config.ProximityConfig{})
+	if err != nil || got != nil {
+		t.Errorf("SuggestMeetingPoints() with one user = %v, %v, want nil, nil", got, err)
+	}
+}
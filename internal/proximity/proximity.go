@@ -0,0 +1,268 @@
+// Package proximity provides detection of encounters between users' GPS tracks.
+//
+// @title Proximity Detection Package
+// @version 1.0
+// @description Finds the intervals when two people were within a given distance of each other
+// @description Compares tracks at every recorded timestamp, interpolating the other track in between
+//
+// Features:
+// - Pairwise encounter detection across any number of users
+// - Interpolated positions so tracks need not share timestamps
+// - Configurable radius and maximum gap between GPS fixes
+// - Closest approach and highlighted path for each encounter
+// - Meeting point suggestions from the users' positions at a given time
+package proximity
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// DefaultRadius is the encounter distance in meters used when none is configured.
+const DefaultRadius = 50.0
+
+// DefaultMaxGap is the longest gap between GPS fixes that is interpolated across
+// when no maximum is configured. Longer gaps end any ongoing encounter.
+const DefaultMaxGap = 5 * time.Minute
+
+// DefaultColor is the highlight color for encounters when none is configured.
+const DefaultColor = "#FF9800"
+
+// Encounter represents an interval during which two users were close together.
+//
+// @struct Encounter
+// @description Time interval when two users were within the encounter radius
+// @property UserA string First user (alphabetically)
+// @property UserB string Second user (alphabetically)
+// @property Start time.Time First moment the users were within the radius
+// @property End time.Time Last moment the users were within the radius
+// @property MinDistance float64 Closest approach in meters
+// @property Closest gps.Point Midpoint between the users at their closest approach
+// @property PathA gps.Points Positions of UserA during the encounter
+// @property PathB gps.Points Positions of UserB during the encounter
+type Encounter struct {
+	UserA       string     // @field UserA First user (alphabetically)
+	UserB       string     // @field UserB Second user (alphabetically)
+	Start       time.Time  // @field Start First moment the users were within the radius
+	End         time.Time  // @field End Last moment the users were within the radius
+	MinDistance float64    // @field MinDistance Closest approach in meters
+	Closest     gps.Point  // @field Closest Midpoint between the users at their closest approach
+	PathA       gps.Points // @field PathA Positions of UserA during the encounter
+	PathB       gps.Points // @field PathB Positions of UserB during the encounter
+}
+
+// Duration returns how long the encounter lasted.
+func (e Encounter) Duration() time.Duration {
+	return e.End.Sub(e.Start)
+}
+
+// Detect finds every encounter between each pair of users in the given tracks.
+//
+// @function Detect
+// @description Computes pairwise encounter intervals between users' tracks
+// @param tracks map[string]gps.Points Chronologically sorted track for each user
+// @param radius float64 Maximum distance in meters for users to count as together
+// @param maxGap time.Duration Longest gap between fixes to interpolate across (0 means unlimited)
+// @return []Encounter Encounters ordered by start time, then by user names
+// @logic Samples both tracks at the union of their timestamps within the shared time range,
+// @logic interpolating each track between its fixes, and groups consecutive close samples;
+// @logic a spatial index of each track skips pairs whose fixes never come within reach
+// @example encounters := Detect(points.ByUser(), 50, 5*time.Minute)
+func Detect(tracks map[string]gps.Points, radius float64, maxGap time.Duration) []Encounter {
+	users := make([]string, 0, len(tracks))
+	for user := range tracks {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+
+	// Index each track once, so pairs that never come within reach of each other
+	// are skipped without sampling their tracks
+	indexes := make(map[string]*gps.Index, len(users))
+	reaches := make(map[string]float64, len(users))
+	for _, user := range users {
+		indexes[user] = gps.NewIndex(tracks[user])
+		reaches[user] = reach(tracks[user], maxGap)
+	}
+
+	var encounters []Encounter
+	for i := range users {
+		for j := i + 1; j < len(users); j++ {
+			a, b := users[i], users[j]
+			if !near(tracks[a], tracks[b], indexes[b], radius+reaches[a]+reaches[b]) {
+				continue
+			}
+			found := detectPair(a, b, tracks[a], tracks[b], radius, maxGap)
+			encounters = append(encounters, found...)
+		}
+	}
+
+	sort.SliceStable(encounters, func(i, j int) bool {
+		return encounters[i].Start.Before(encounters[j].Start)
+	})
+	return encounters
+}
+
+// DetectPoints splits multi-user points by their User field and detects encounters
+// using the configured radius and gap, falling back to the package defaults.
+// Points without a user are ignored.
+func DetectPoints(points gps.Points, cfg *config.ProximityConfig) []Encounter {
+	radius := cfg.Radius
+	if radius <= 0 {
+		radius = DefaultRadius
+	}
+	maxGap := time.Duration(cfg.MaxGapSeconds) * time.Second
+	if maxGap <= 0 {
+		maxGap = DefaultMaxGap
+	}
+
+	return Detect(userTracks(points), radius, maxGap)
+}
+
+// userTracks splits points into chronologically sorted per-user tracks, dropping
+// points that have no user.
+func userTracks(points gps.Points) map[string]gps.Points {
+	tracks := points.ByUser()
+	delete(tracks, "")
+	for _, track := range tracks {
+		track.SortByTimestamp()
+	}
+	return tracks
+}
+
+// reach returns how far an interpolated position of the track can be from the
+// nearest of its fixes: half the longest step between fixes close enough in time
+// to be interpolated across.
+func reach(track gps.Points, maxGap time.Duration) float64 {
+	longest := 0.0
+	for i := 1; i < len(track); i++ {
+		if maxGap > 0 && track[i].Timestamp.Sub(track[i-1].Timestamp) > maxGap {
+			continue
+		}
+		longest = math.Max(longest, track[i-1].DistanceTo(track[i]))
+	}
+	return longest / 2
+}
+
+// near reports whether any fix of track from the time the other track was
+// recording lies within distance meters of one of its fixes. Tracks that are not
+// near each other cannot have encounters within radius when distance is the
+// radius plus the reach of both tracks.
+func near(track, other gps.Points, index *gps.Index, distance float64) bool {
+	if len(track) == 0 || len(other) == 0 || apart(track, other, distance) {
+		return false
+	}
+	// Fixes next to a step that overlaps the other track's time range bound the
+	// interpolated positions it is compared with
+	start, end := other[0].Timestamp, other[len(other)-1].Timestamp
+	for i, p := range track {
+		if i+1 < len(track) && track[i+1].Timestamp.Before(start) {
+			continue
+		}
+		if i > 0 && track[i-1].Timestamp.After(end) {
+			break
+		}
+		if len(index.Within(p.Latitude, p.Longitude, distance)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// apart reports whether the bounding boxes of two tracks are more than distance
+// meters apart, from a lower bound of the Haversine distance between them.
+func apart(a, b gps.Points, distance float64) bool {
+	minLatA, maxLatA, minLngA, maxLngA := a.Bounds()
+	minLatB, maxLatB, minLngB, maxLngB := b.Bounds()
+
+	latGap := math.Max(0, math.Max(minLatB-maxLatA, minLatA-maxLatB))
+	lngGap := math.Max(0, math.Max(minLngB-maxLngA, minLngA-maxLngB))
+	lngGap = math.Min(lngGap, 360-(math.Max(maxLngA, maxLngB)-math.Min(minLngA, minLngB)))
+	widest := math.Max(math.Max(math.Abs(minLatA), math.Abs(maxLatA)), math.Max(math.Abs(minLatB), math.Abs(maxLatB)))
+
+	// hav(d) = hav(Δlat) + cos(lat1)·cos(lat2)·hav(Δlng), and both cosines are at
+	// least cos(widest)
+	toRadians := math.Pi / 180
+	sinLat := math.Sin(latGap * toRadians / 2)
+	sinLng := math.Cos(widest*toRadians) * math.Sin(math.Max(lngGap, 0)*toRadians/2)
+	lower := 2 * gps.EarthRadius * math.Asin(math.Min(1, math.Sqrt(sinLat*sinLat+sinLng*sinLng)))
+	return lower > distance
+}
+
+// detectPair finds the encounters between two users' tracks.
+func detectPair(userA, userB string, a, b gps.Points, radius float64, maxGap time.Duration) []Encounter {
+	var encounters []Encounter
+	var current *Encounter
+
+	for _, t := range sampleTimes(a, b) {
+		posA, okA := a.PositionAt(t, maxGap)
+		posB, okB := b.PositionAt(t, maxGap)
+
+		distance := 0.0
+		if okA && okB {
+			distance = posA.DistanceTo(posB)
+		}
+		if !okA || !okB || distance > radius {
+			current = nil
+			continue
+		}
+
+		if current == nil {
+			encounters = append(encounters, Encounter{
+				UserA:       userA,
+				UserB:       userB,
+				Start:       t,
+				MinDistance: distance,
+				Closest:     posA.MidpointTo(posB),
+			})
+			current = &encounters[len(encounters)-1]
+		}
+
+		current.End = t
+		current.PathA = append(current.PathA, posA)
+		current.PathB = append(current.PathB, posB)
+		if distance < current.MinDistance {
+			current.MinDistance = distance
+			current.Closest = posA.MidpointTo(posB)
+		}
+	}
+
+	return encounters
+}
+
+// sampleTimes returns the sorted, de-duplicated timestamps of both tracks that fall
+// within the time range the tracks have in common.
+func sampleTimes(a, b gps.Points) []time.Time {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+
+	start, end := a[0].Timestamp, a[len(a)-1].Timestamp
+	if b[0].Timestamp.After(start) {
+		start = b[0].Timestamp
+	}
+	if b[len(b)-1].Timestamp.Before(end) {
+		end = b[len(b)-1].Timestamp
+	}
+
+	var times []time.Time
+	for _, track := range []gps.Points{a, b} {
+		for _, point := range track {
+			if !point.Timestamp.Before(start) && !point.Timestamp.After(end) {
+				times = append(times, point.Timestamp)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	unique := times[:0]
+	for i, t := range times {
+		if i == 0 || !t.Equal(times[i-1]) {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,222 @@
+// Package proximity_test provides unit tests for multi-user encounter detection.
+// It tests interpolation between unaligned tracks, gap handling, and the grouping
+// of close samples into encounter intervals.
+package proximity
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// metersPerDegree converts meters of latitude into degrees when building test tracks
+const metersPerDegree = 111195.0
+
+func track(user string, start time.Time, step time.Duration, lats ...float64) gps.Points {
+	points := make(gps.Points, len(lats))
+	for i, lat := range lats {
+		points[i] = gps.Point{Timestamp: start.Add(time.Duration(i) * step), Latitude: lat, User: user}
+	}
+	return points
+}
+
+func TestDetect(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	near := 20 / metersPerDegree
+	far := 500 / metersPerDegree
+
+	tests := []struct {
+		name      string
+		tracks    map[string]gps.Points
+		maxGap    time.Duration
+		want      int
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name: "walking together",
+			tracks: map[string]gps.Points{
+				"alice": track("alice", start, time.Minute, 0, 0, 0),
+				"bob":   track("bob", start, time.Minute, near, near, near),
+			},
+			want:      1,
+			wantStart: start,
+			wantEnd:   start.Add(2 * time.Minute),
+		},
+		{
+			name: "split up and rejoin",
+			tracks: map[string]gps.Points{
+				"alice": track("alice", start, time.Minute, 0, 0, 0, 0),
+				"bob":   track("bob", start, time.Minute, near, far, far, near),
+			},
+			want:      2,
+			wantStart: start,
+			wantEnd:   start,
+		},
+		{
+			name: "unaligned timestamps are interpolated",
+			tracks: map[string]gps.Points{
+				"alice": track("alice", start, time.Minute, 0, 0),
+				"bob":   track("bob", start.Add(30*time.Second), time.Minute, near),
+			},
+			want:      1,
+			wantStart: start.Add(30 * time.Second),
+			wantEnd:   start.Add(30 * time.Second),
+		},
+		{
+			name: "never close",
+			tracks: map[string]gps.Points{
+				"alice": track("alice", start, time.Minute, 0, 0),
+				"bob":   track("bob", start, time.Minute, far, far),
+			},
+		},
+		{
+			name: "no overlapping time",
+			tracks: map[string]gps.Points{
+				"alice": track("alice", start, time.Minute, 0, 0),
+				"bob":   track("bob", start.Add(time.Hour), time.Minute, 0, 0),
+			},
+		},
+		{
+			name: "gap longer than limit",
+			tracks: map[string]gps.Points{
+				"alice": track("alice", start, time.Hour, 0, 0),
+				"bob":   track("bob", start.Add(30*time.Minute), time.Minute, near),
+			},
+			maxGap: 10 * time.Minute,
+		},
+		{
+			name: "single user",
+			tracks: map[string]gps.Points{
+				"alice": track("alice", start, time.Minute, 0, 0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Detect(tt.tracks, DefaultRadius, tt.maxGap)
+			if len(got) != tt.want {
+				t.Fatalf("Detect() returned %d encounters, want %d: %+v", len(got), tt.want, got)
+			}
+			if tt.want == 0 {
+				return
+			}
+			if got[0].UserA != "alice" || got[0].UserB != "bob" {
+				t.Errorf("Detect() users = %q, %q, want alice, bob", got[0].UserA, got[0].UserB)
+			}
+			if !got[0].Start.Equal(tt.wantStart) || !got[0].End.Equal(tt.wantEnd) {
+				t.Errorf("Detect() interval = %v to %v, want %v to %v", got[0].Start, got[0].End, tt.wantStart, tt.wantEnd)
+			}
+			if got[0].MinDistance > DefaultRadius {
+				t.Errorf("Detect() MinDistance = %v, want at most %v", got[0].MinDistance, DefaultRadius)
+			}
+			if len(got[0].PathA) != len(got[0].PathB) || len(got[0].PathA) == 0 {
+				t.Errorf("Detect() paths have %d and %d points, want equal and non-empty", len(got[0].PathA), len(got[0].PathB))
+			}
+		})
+	}
+}
+
+func TestDetectClosestApproach(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	tracks := map[string]gps.Points{
+		"alice": track("alice", start, time.Minute, 0, 0, 0),
+		"bob":   track("bob", start, time.Minute, 40/metersPerDegree, 10/metersPerDegree, 30/metersPerDegree),
+	}
+
+	got := Detect(tracks, DefaultRadius, 0)
+	if len(got) != 1 {
+		t.Fatalf("Detect() returned %d encounters, want 1", len(got))
+	}
+	if got[0].MinDistance < 9 || got[0].MinDistance > 11 {
+		t.Errorf("Detect() MinDistance = %v, want ~10", got[0].MinDistance)
+	}
+	if !got[0].Closest.Timestamp.Equal(start.Add(time.Minute)) {
+		t.Errorf("Detect() closest approach at %v, want %v", got[0].Closest.Timestamp, start.Add(time.Minute))
+	}
+	if got[0].Duration() != 2*time.Minute {
+		t.Errorf("Encounter.Duration() = %v, want %v", got[0].Duration(), 2*time.Minute)
+	}
+}
+
+func TestDetectPoints(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	near := 20 / metersPerDegree
+
+	// Interleaved, unsorted multi-user input with an anonymous point
+	points := gps.Points{
+		{Timestamp: start.Add(time.Minute), Latitude: near, User: "bob"},
+		{Timestamp: start, Latitude: 0, User: "alice"},
+		{Timestamp: start, Latitude: near, User: "bob"},
+		{Timestamp: start.Add(time.Minute), Latitude: 0, User: "alice"},
+		{Timestamp: start, Latitude: 0},
+	}
+
+	got := DetectPoints(points, &config.ProximityConfig{})
+	if len(got) != 1 {
+		t.Fatalf("DetectPoints() returned %d encounters, want 1", len(got))
+	}
+	if got[0].Duration() != time.Minute {
+		t.Errorf("DetectPoints() encounter lasted %v, want %v", got[0].Duration(), time.Minute)
+	}
+
+	if got := DetectPoints(points, &config.ProximityConfig{Radius: 5}); len(got) != 0 {
+		t.Errorf("DetectPoints() with 5 m radius returned %d encounters, want 0", len(got))
+	}
+}
+
+// benchmarkTracks returns reproducible random walks of the given number of users,
+// one fix every ten seconds, starting spacing degrees apart on a grid. A spacing of
+// 0.01 (about a kilometer) lets only a few users meet, as in a city-wide group of
+// runners.
+func benchmarkTracks(users, fixes int, spacing float64) map[string]gps.Points {
+	rng := rand.New(rand.NewSource(1))
+	start := time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC)
+	tracks := make(map[string]gps.Points, users)
+	for u := 0; u < users; u++ {
+		user := fmt.Sprintf("user%02d", u)
+		lat, lng := 37.70+float64(u/5)*spacing, -122.50+float64(u%5)*spacing
+		points := make(gps.Points, fixes)
+		for i := range points {
+			lat += (rng.Float64() - 0.5) * 0.0002
+			lng += (rng.Float64() - 0.5) * 0.0002
+			points[i] = gps.Point{Timestamp: start.Add(time.Duration(i) * 10 * time.Second), Latitude: lat, Longitude: lng, User: user}
+		}
+		tracks[user] = points
+	}
+	return tracks
+}
+
+func TestDetectSkipsOnlyDistantPairs(t *testing.T) {
+	for _, spacing := range []float64{0.0005, 0.002, 0.01} {
+		tracks := benchmarkTracks(10, 1000, spacing)
+
+		// Every pair sampled in full, as without the spatial index
+		var want []Encounter
+		for a := range tracks {
+			for b := range tracks {
+				if a < b {
+					want = append(want, detectPair(a, b, tracks[a], tracks[b], DefaultRadius, DefaultMaxGap)...)
+				}
+			}
+		}
+
+		got := Detect(tracks, DefaultRadius, DefaultMaxGap)
+		if len(got) != len(want) {
+			t.Errorf("spacing %v: Detect() found %d encounters, all pairs = %d", spacing, len(got), len(want))
+		}
+	}
+}
+
+func BenchmarkDetect(b *testing.B) {
+	tracks := benchmarkTracks(20, 5000, 0.01)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Detect(tracks, DefaultRadius, DefaultMaxGap)
+	}
+}
@@ -0,0 +1,541 @@
+// Package qrcode encodes short texts such as URLs as QR codes.
+//
+// @title QR Code Package
+// @version 1.0
+// @description Encodes text as a QR code symbol and draws it as SVG
+// @description Implements ISO/IEC 18004 byte mode without external services or libraries
+//
+// Features:
+// - Byte mode encoding at error correction level M (15% of the symbol recoverable)
+// - The smallest of versions 1-40 that fits the text
+// - Mask selection by the standard penalty rules
+// - Scalable SVG output with the required quiet zone
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxLength is the longest text in bytes that fits a version 40 symbol at level M.
+const MaxLength = 2331
+
+// quietZone is the width in modules of the light border around the symbol.
+const quietZone = 4
+
+// formatLevelM is the error correction level M in the format information.
+const formatLevelM = 0
+
+// eccPerBlock holds the error correction codewords per block at level M, by version.
+var eccPerBlock = [41]int{
+	0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26,
+	26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+}
+
+// blockCount holds the number of error correction blocks at level M, by version.
+var blockCount = [41]int{
+	0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
+	17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49,
+}
+
+// Code is a QR code symbol.
+//
+// @struct Code
+// @description Square grid of dark and light modules
+// @property Version int Symbol version from 1 (21x21 modules) to 40 (177x177)
+// @property Size int Modules per side, without the quiet zone
+type Code struct {
+	Version int // @field Version Symbol version (1-40)
+	Size    int // @field Size Modules per side without the quiet zone
+
+	modules  [][]bool // Dark modules by row and column
+	function [][]bool // Modules of finder, timing, alignment, format, and version patterns
+}
+
+// Encode encodes text as a QR code in byte mode at error correction level M,
+// using the smallest version the text fits.
+//
+// @function Encode
+// @description Builds the QR code symbol of a text
+// @param text string Text to encode, such as a URL
+// @return *Code QR code symbol
+// @return error Error if the text is empty or longer than MaxLength bytes
+// @example code, err := qrcode.Encode("https://example.org/trip.html")
+func Encode(text string) (*Code, error) {
+	if text == "" {
+		return nil, fmt.Errorf("nothing to encode")
+	}
+	data := []byte(text)
+	version := 1
+	for ; version <= 40; version++ {
+		if 4+countBits(version)+8*len(data) <= 8*dataCodewords(version) {
+			break
+		}
+	}
+	if version > 40 {
+		return nil, fmt.Errorf("text of %d bytes is longer than the %d a QR code holds", len(data), MaxLength)
+	}
+
+	code := &Code{Version: version, Size: 4*version + 17}
+	code.modules = grid(code.Size)
+	code.function = grid(code.Size)
+	code.drawFunctionPatterns()
+	code.drawCodewords(addErrorCorrection(encodeData(data, version), version))
+
+	// Keep the mask that least resembles the finder patterns and balances dark and light
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		code.applyMask(mask)
+		code.drawFormatBits(mask)
+		if penalty := code.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		code.applyMask(mask)
+	}
+	code.applyMask(best)
+	code.drawFormatBits(best)
+	return code, nil
+}
+
+// Dark reports whether the module in column x and row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// SVG draws the symbol with its quiet zone as an SVG image of the given width and
+// height in pixels. The image scales without blurring, for screens and print.
+//
+// @method SVG
+// @description Renders the symbol as an inline SVG element
+// @param pixels int Width and height of the image
+// @return string SVG element with one path for all dark modules
+func (c *Code) SVG(pixels int) string {
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				fmt.Fprintf(&path, "M%d %dh1v1h-1z", x+quietZone, y+quietZone)
+			}
+		}
+	}
+	side := c.Size + 2*quietZone
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="%d" height="%d" fill="#FFFFFF"/><path d="%s" fill="#000000"/></svg>`,
+		pixels, pixels, side, side, side, side, path.String())
+}
+
+// grid returns a size x size grid of light modules.
+func grid(size int) [][]bool {
+	rows := make([][]bool, size)
+	for y := range rows {
+		rows[y] = make([]bool, size)
+	}
+	return rows
+}
+
+// countBits returns the length of the byte mode character count of a version.
+func countBits(version int) int {
+	if version <= 9 {
+		return 8
+	}
+	return 16
+}
+
+// rawCodewords returns the number of codewords, data and error correction, that a
+// version holds once the function patterns are placed.
+func rawCodewords(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		alignments := version/7 + 2
+		modules -= (25*alignments-10)*alignments - 55
+		if version >= 7 {
+			modules -= 36
+		}
+	}
+	return modules / 8
+}
+
+// dataCodewords returns the number of data codewords of a version at level M.
+func dataCodewords(version int) int {
+	return rawCodewords(version) - eccPerBlock[version]*blockCount[version]
+}
+
+// encodeData builds the data codewords: the byte mode indicator, the character
+// count, the text, a terminator, and the alternating pad bytes.
+func encodeData(data []byte, version int) []byte {
+	var bits bitBuffer
+	bits.append(0b0100, 4)
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	capacity := 8 * dataCodewords(version)
+	bits.append(0, min(4, capacity-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	return bits.bytes()
+}
+
+// addErrorCorrection splits the data codewords into blocks, appends the
+// Reed-Solomon codewords of each block, and interleaves the blocks.
+func addErrorCorrection(data []byte, version int) []byte {
+	blocks, eccLength := blockCount[version], eccPerBlock[version]
+	raw := rawCodewords(version)
+	shortBlocks := blocks - raw%blocks
+	shortLength := raw/blocks - eccLength
+	divisor := rsDivisor(eccLength)
+
+	dataBlocks := make([][]byte, blocks)
+	eccBlocks := make([][]byte, blocks)
+	offset := 0
+	for i := range dataBlocks {
+		length := shortLength
+		if i >= shortBlocks {
+			length++
+		}
+		dataBlocks[i] = data[offset : offset+length]
+		eccBlocks[i] = rsRemainder(dataBlocks[i], divisor)
+		offset += length
+	}
+
+	result := make([]byte, 0, raw)
+	for i := 0; i <= shortLength; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < eccLength; i++ {
+		for _, block := range eccBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// drawFunctionPatterns draws the timing, finder, alignment, and version patterns
+// and reserves the format areas, so codewords skip them.
+func (c *Code) drawFunctionPatterns() {
+	for i := 0; i < c.Size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with their light separators in three corners
+	for _, corner := range [][2]int{{3, 3}, {c.Size - 4, 3}, {3, c.Size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := corner[0]+dx, corner[1]+dy
+				if x >= 0 && x < c.Size && y >= 0 && y < c.Size {
+					distance := max(abs(dx), abs(dy))
+					c.setFunction(x, y, distance != 2 && distance != 4)
+				}
+			}
+		}
+	}
+
+	// Alignment patterns everywhere but on the finder patterns
+	positions := alignmentPositions(c.Version)
+	last := len(positions) - 1
+	for i, row := range positions {
+		for j, column := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					c.setFunction(column+dx, row+dy, max(abs(dx), abs(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+// alignmentPositions returns the row and column centers of the alignment patterns.
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	count := version/7 + 2
+	step := (version*4 + count*2 + 1) / (count*2 - 2) * 2
+	if version == 32 {
+		step = 26
+	}
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, position := count-1, 4*version+10; i >= 1; i, position = i-1, position-step {
+		positions[i] = position
+	}
+	return positions
+}
+
+// formatBits returns the 15 format information bits of level M and a mask.
+func formatBits(mask int) int {
+	data := formatLevelM<<3 | mask
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = remainder<<1 ^ (remainder>>9)*0x537
+	}
+	return (data<<10 | remainder) ^ 0x5412
+}
+
+// drawFormatBits draws both copies of the format information and the dark module.
+func (c *Code) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return bits>>i&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, bit(i))
+	}
+	c.setFunction(8, 7, bit(6))
+	c.setFunction(8, 8, bit(7))
+	c.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.Size-15+i, bit(i))
+	}
+	c.setFunction(8, c.Size-8, true)
+}
+
+// versionBits returns the 18 version information bits of versions 7 and up.
+func versionBits(version int) int {
+	remainder := version
+	for i := 0; i < 12; i++ {
+		remainder = remainder<<1 ^ (remainder>>11)*0x1F25
+	}
+	return version<<12 | remainder
+}
+
+// drawVersion draws both copies of the version information of versions 7 and up.
+func (c *Code) drawVersion() {
+	if c.Version < 7 {
+		return
+	}
+	bits := versionBits(c.Version)
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 != 0
+		a, b := c.Size-11+i%3, i/3
+		c.setFunction(a, b, dark)
+		c.setFunction(b, a, dark)
+	}
+}
+
+// setFunction sets a module of a function pattern.
+func (c *Code) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.function[y][x] = true
+}
+
+// drawCodewords places the codeword bits in the zigzag order of the standard:
+// upwards and downwards in two-module columns from the right, skipping the
+// vertical timing pattern and the function patterns.
+func (c *Code) drawCodewords(codewords []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vertical := 0; vertical < c.Size; vertical++ {
+			y := vertical
+			if upward {
+				y = c.Size - 1 - vertical
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if c.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				c.modules[y][x] = codewords[i/8]>>(7-i%8)&1 != 0
+				i++
+			}
+		}
+	}
+}
+
+// applyMask inverts the non-function modules selected by a mask pattern. Applying
+// the same mask twice restores the modules.
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.function[y][x] && masked(mask, x, y) {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// masked reports whether a mask pattern inverts the module in column x and row y.
+func masked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// finderLike is the dark-light pattern of a finder pattern with four light modules
+// on one side, which scanners could mistake for a real one.
+var finderLike = [][]bool{
+	{true, false, true, true, true, false, true, false, false, false, false},
+	{false, false, false, false, true, false, true, true, true, false, true},
+}
+
+// penalty scores the symbol by the four penalty rules of the standard: runs of the
+// same color, 2x2 blocks, finder-like patterns, and imbalance of dark and light.
+func (c *Code) penalty() int {
+	penalty, dark := 0, 0
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.Size && y+1 < c.Size {
+				color := c.modules[y][x]
+				if c.modules[y][x+1] == color && c.modules[y+1][x] == color && c.modules[y+1][x+1] == color {
+					penalty += 3
+				}
+			}
+		}
+	}
+
+	for _, horizontal := range []bool{true, false} {
+		for line := 0; line < c.Size; line++ {
+			at := func(i int) bool {
+				if horizontal {
+					return c.modules[line][i]
+				}
+				return c.modules[i][line]
+			}
+
+			run := 1
+			for i := 1; i <= c.Size; i++ {
+				if i < c.Size && at(i) == at(i-1) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					penalty += run - 2
+				}
+				run = 1
+			}
+
+			for start := 0; start+len(finderLike[0]) <= c.Size; start++ {
+				for _, pattern := range finderLike {
+					matches := true
+					for i, want := range pattern {
+						if at(start+i) != want {
+							matches = false
+							break
+						}
+					}
+					if matches {
+						penalty += 40
+					}
+				}
+			}
+		}
+	}
+
+	total := c.Size * c.Size
+	percent := dark * 100 / total
+	penalty += abs(percent-50) / 5 * 10
+	return penalty
+}
+
+// abs returns the absolute value of an integer.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// bitBuffer collects bits most significant first.
+type bitBuffer []bool
+
+// append adds the lowest length bits of value.
+func (b *bitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 != 0)
+	}
+}
+
+// bytes packs the bits into bytes; the length is a multiple of eight.
+func (b bitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 1 << (7 - i%8)
+		}
+	}
+	return result
+}
+
+// rsDivisor returns the Reed-Solomon generator polynomial of a degree, without its
+// leading term, with coefficients from the highest to the lowest power.
+func rsDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder returns the Reed-Solomon error correction codewords of data.
+func rsRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range divisor {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies two elements of GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
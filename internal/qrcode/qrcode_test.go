@@ -0,0 +1,224 @@
+package qrcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReedSolomon(t *testing.T) {
+	// Data and error correction codewords of "HELLO WORLD" as a 1-M symbol
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !bytes.Equal(got, want) {
+		t.Errorf("rsRemainder() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	for _, tt := range []struct{ mask, want int }{{0, 0b101010000010010}, {5, 0b100000011001110}, {4, 0b100010111111001}, {7, 0b100101010100000}} {
+		if got := formatBits(tt.mask); got != tt.want {
+			t.Errorf("formatBits(%d) = %015b, want %015b", tt.mask, got, tt.want)
+		}
+	}
+	for _, tt := range []struct{ version, want int }{{7, 0b000111110010010100}, {40, 0b101000110001101001}} {
+		if got := versionBits(tt.version); got != tt.want {
+			t.Errorf("versionBits(%d) = %018b, want %018b", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	tests := map[int][]int{
+		1:  nil,
+		2:  {6, 18},
+		7:  {6, 22, 38},
+		16: {6, 26, 50, 74},
+		32: {6, 34, 60, 86, 112, 138},
+		39: {6, 26, 54, 82, 110, 138, 166},
+	}
+	for version, want := range tests {
+		got := alignmentPositions(version)
+		if len(got) != len(want) {
+			t.Errorf("alignmentPositions(%d) = %v, want %v", version, got, want)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("alignmentPositions(%d) = %v, want %v", version, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		text        string
+		wantVersion int
+	}{
+		{"https://example.org", 2},
+		{"https://maps.example.org/trips/2025-10-28/index.html#map=14/37.7749/-122.4194", 5},
+		{strings.Repeat("a", 200), 10},
+		{strings.Repeat("b", 1000), 26},
+		{strings.Repeat("c", MaxLength), 40},
+	}
+	for _, tt := range tests {
+		code, err := Encode(tt.text)
+		if err != nil {
+			t.Fatalf("Encode(%d bytes) error = %v", len(tt.text), err)
+		}
+		if code.Version != tt.wantVersion || code.Size != 4*tt.wantVersion+17 {
+			t.Errorf("Encode(%d bytes) = version %d, size %d, want version %d", len(tt.text), code.Version, code.Size, tt.wantVersion)
+		}
+		if got := decode(t, code); got != tt.text {
+			t.Errorf("decoded %q, want %q", got, tt.text)
+		}
+	}
+
+	if _, err := Encode(""); err == nil {
+		t.Error("Encode(\"\") returned no error")
+	}
+	if _, err := Encode(strings.Repeat("d", MaxLength+1)); err == nil {
+		t.Error("Encode() accepted text longer than MaxLength")
+	}
+}
+
+func TestFinderPatterns(t *testing.T) {
+	code, _ := Encode("https://example.org")
+	// Rows through the center of the top left and top right finder patterns
+	for _, x := range []int{0, 2, 3, 4, 6, code.Size - 7, code.Size - 5, code.Size - 1} {
+		if !code.Dark(x, 3) {
+			t.Errorf("module (%d, 3) of a finder pattern is light", x)
+		}
+	}
+	for _, x := range []int{1, 5, 7, code.Size - 8, code.Size - 6, code.Size - 2} {
+		if code.Dark(x, 1) {
+			t.Errorf("module (%d, 1) of a finder pattern or separator is dark", x)
+		}
+	}
+	if !code.Dark(8, code.Size-8) {
+		t.Error("dark module is light")
+	}
+}
+
+func TestSVG(t *testing.T) {
+	code, _ := Encode("https://example.org")
+	svg := code.SVG(96)
+	for _, want := range []string{
+		`width="96" height="96" viewBox="0 0 33 33"`,
+		`<rect width="33" height="33" fill="#FFFFFF"/>`,
+		"M4 4h1v1h-1z", // top left corner of the first finder pattern
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG() missing %q", want)
+		}
+	}
+}
+
+// decode reads a symbol back the way a scanner would: the mask from the format
+// information, the codewords in placement order, the blocks checked against their
+// error correction codewords, and the byte mode text.
+func decode(t *testing.T, code *Code) string {
+	t.Helper()
+	format := 0
+	for i := 14; i >= 9; i-- {
+		format = format<<1 | bit(code.Dark(14-i, 8))
+	}
+	format = format<<1 | bit(code.Dark(7, 8))
+	format = format<<1 | bit(code.Dark(8, 8))
+	format = format<<1 | bit(code.Dark(8, 7))
+	for i := 5; i >= 0; i-- {
+		format = format<<1 | bit(code.Dark(8, i))
+	}
+	mask := -1
+	for m := 0; m < 8; m++ {
+		if formatBits(m) == format {
+			mask = m
+		}
+	}
+	if mask < 0 {
+		t.Fatalf("format bits %015b match no level M mask", format)
+	}
+
+	// A fresh symbol of the same version tells the function modules apart
+	reference := &Code{Version: code.Version, Size: code.Size, modules: grid(code.Size), function: grid(code.Size)}
+	reference.drawFunctionPatterns()
+	var bits bitBuffer
+	for right := code.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vertical := 0; vertical < code.Size; vertical++ {
+			y := vertical
+			if (right+1)&2 == 0 {
+				y = code.Size - 1 - vertical
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if !reference.function[y][x] {
+					bits = append(bits, code.Dark(x, y) != masked(mask, x, y))
+				}
+			}
+		}
+	}
+	codewords := bits[:len(bits)/8*8].bytes()
+	if len(codewords) != rawCodewords(code.Version) {
+		t.Fatalf("read %d codewords, want %d", len(codewords), rawCodewords(code.Version))
+	}
+
+	// Undo the interleaving and check each block
+	blocks, eccLength := blockCount[code.Version], eccPerBlock[code.Version]
+	raw := rawCodewords(code.Version)
+	shortBlocks, shortLength := blocks-raw%blocks, raw/blocks-eccLength
+	dataBlocks := make([][]byte, blocks)
+	next := 0
+	for i := 0; i <= shortLength; i++ {
+		for b := range dataBlocks {
+			if i < shortLength || b >= shortBlocks {
+				dataBlocks[b] = append(dataBlocks[b], codewords[next])
+				next++
+			}
+		}
+	}
+	var data []byte
+	for b, block := range dataBlocks {
+		ecc := make([]byte, eccLength)
+		for i := range ecc {
+			ecc[i] = codewords[next+i*blocks+b]
+		}
+		if !bytes.Equal(rsRemainder(block, rsDivisor(eccLength)), ecc) {
+			t.Fatalf("block %d fails its error correction check", b)
+		}
+		data = append(data, block...)
+	}
+
+	var stream bitBuffer
+	for _, b := range data {
+		stream.append(int(b), 8)
+	}
+	read := func(offset, length int) int {
+		value := 0
+		for _, dark := range stream[offset : offset+length] {
+			value = value<<1 | bit(dark)
+		}
+		return value
+	}
+	if mode := read(0, 4); mode != 0b0100 {
+		t.Fatalf("mode = %04b, want byte mode", mode)
+	}
+	length := read(4, countBits(code.Version))
+	text := make([]byte, length)
+	for i := range text {
+		text[i] = byte(read(4+countBits(code.Version)+8*i, 8))
+	}
+	return string(text)
+}
+
+// bit converts a module to a bit.
+func bit(dark bool) int {
+	if dark {
+		return 1
+	}
+	return 0
+}
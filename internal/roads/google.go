@@ -0,0 +1,200 @@
+package roads
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/progress"
+)
+
+// ProviderGoogle snaps tracks with the Google Roads API.
+const ProviderGoogle = "google"
+
+// GoogleRoadsURL is the Google Roads API snapToRoads endpoint.
+const GoogleRoadsURL = "https://roads.googleapis.com/v1/snapToRoads"
+
+// googleMaxPoints is the largest path the Roads API accepts in one request.
+const googleMaxPoints = 100
+
+func init() {
+	Register(ProviderGoogle, newGoogleSnapper)
+}
+
+// GoogleSnapper snaps tracks using the Google Roads API.
+//
+// @struct GoogleSnapper
+// @description Road snapping through roads.googleapis.com
+// @property APIKey string Google API key with the Roads API enabled
+// @property Interpolate bool Insert extra points so the path follows road curves
+// @property BaseURL string Endpoint URL (overridable for testing)
+// @property Client *http.Client HTTP client used for requests
+type GoogleSnapper struct {
+	APIKey      string       // @field APIKey Google API key with the Roads API enabled
+	Interpolate bool         // @field Interpolate Follow road geometry between points
+	BaseURL     string       // @field BaseURL snapToRoads endpoint URL
+	Client      *http.Client // @field Client HTTP client for API requests
+
+	progress *progress.Bar // @field progress Bar counting the points sent (nil for none)
+}
+
+// newGoogleSnapper creates a GoogleSnapper from configuration.
+func newGoogleSnapper(cfg *config.SnapConfig, apiKey string) (Snapper, error) {
+	if cfg.APIKey != "" {
+		apiKey = cfg.APIKey
+	}
+	if apiKey == "" || apiKey == "DEMO" {
+		return nil, fmt.Errorf("google Roads API requires an API key")
+	}
+
+	return &GoogleSnapper{
+		APIKey:      apiKey,
+		Interpolate: cfg.Interpolate,
+		BaseURL:     GoogleRoadsURL,
+		Client:      &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// googleSnapResponse is the JSON body returned by snapToRoads.
+type googleSnapResponse struct {
+	SnappedPoints []struct {
+		Location struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"location"`
+		OriginalIndex *int `json:"originalIndex"`
+	} `json:"snappedPoints"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Snap sends the track to the Roads API in batches of up to 100 points.
+//
+// @method Snap
+// @description Replaces GPS positions with their road-matched equivalents
+// @param ctx context.Context Cancels the remaining requests
+// @param points gps.Points Chronologically sorted GPS points
+// @return gps.Points Snapped track; interpolated points get timestamps between their neighbors
+// @return error Error if any API request fails
+// @note Points the API cannot match are kept at their original position
+func (s *GoogleSnapper) Snap(ctx context.Context, points gps.Points) (gps.Points, error) {
+	var result gps.Points
+	for start := 0; start < len(points); start += googleMaxPoints {
+		end := start + googleMaxPoints
+		if end > len(points) {
+			end = len(points)
+		}
+
+		snapped, err := s.snapBatch(ctx, points[start:end])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, snapped...)
+		s.progress.Set(int64(end))
+	}
+	return result, nil
+}
+
+// SetProgress reports the points sent to the Roads API to bar.
+func (s *GoogleSnapper) SetProgress(bar *progress.Bar) {
+	s.progress = bar
+}
+
+// snapBatch snaps a single request-sized batch of points.
+func (s *GoogleSnapper) snapBatch(ctx context.Context, batch gps.Points) (gps.Points, error) {
+	path := make([]string, len(batch))
+	for i, p := range batch {
+		path[i] = fmt.Sprintf("%.6f,%.6f", p.Latitude, p.Longitude)
+	}
+
+	query := url.Values{}
+	query.Set("path", strings.Join(path, "|"))
+	query.Set("interpolate", fmt.Sprint(s.Interpolate))
+	query.Set("key", s.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.BaseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("roads API request failed: %w", err)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("roads API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body googleSnapResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("cannot decode roads API response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		message := resp.Status
+		if body.Error != nil {
+			message = body.Error.Message
+		}
+		return nil, fmt.Errorf("roads API error: %s", message)
+	}
+
+	// Matched points replace their originals; interpolated points are collected
+	// between the originals they fall after
+	result := append(gps.Points{}, batch...)
+	inserted := make(map[int]gps.Points)
+	last := -1
+	for _, sp := range body.SnappedPoints {
+		if sp.OriginalIndex != nil && *sp.OriginalIndex >= 0 && *sp.OriginalIndex < len(batch) {
+			last = *sp.OriginalIndex
+			result[last].Latitude = sp.Location.Latitude
+			result[last].Longitude = sp.Location.Longitude
+			continue
+		}
+		inserted[last] = append(inserted[last], gps.Point{
+			Latitude:  sp.Location.Latitude,
+			Longitude: sp.Location.Longitude,
+		})
+	}
+
+	return mergeInterpolated(result, inserted), nil
+}
+
+// mergeInterpolated inserts the interpolated points after the original they follow
+// (index -1 means before the first original), spacing their timestamps evenly
+// between the surrounding originals.
+func mergeInterpolated(points gps.Points, inserted map[int]gps.Points) gps.Points {
+	if len(inserted) == 0 {
+		return points
+	}
+
+	var merged gps.Points
+	for i := -1; i < len(points); i++ {
+		if i >= 0 {
+			merged = append(merged, points[i])
+		}
+		extra := inserted[i]
+		if len(extra) == 0 {
+			continue
+		}
+
+		// Interpolated points before the first original take its timestamp
+		from := points[0].Timestamp
+		to := from
+		if i >= 0 {
+			from = points[i].Timestamp
+			to = from
+			if i+1 < len(points) {
+				to = points[i+1].Timestamp
+			}
+		}
+		step := to.Sub(from) / time.Duration(len(extra)+1)
+		for j, p := range extra {
+			p.Timestamp = from.Add(step * time.Duration(j+1))
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
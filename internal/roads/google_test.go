@@ -0,0 +1,104 @@
+package roads
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestGoogleSnapperSnap(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("key") != "test-key" || r.URL.Query().Get("interpolate") != "true" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+
+		// Snap every point 0.0001 degrees north and interpolate one point after the first
+		path := strings.Split(r.URL.Query().Get("path"), "|")
+		var snapped []string
+		for i, coord := range path {
+			var lat, lng float64
+			fmt.Sscanf(coord, "%f,%f", &lat, &lng)
+			snapped = append(snapped, fmt.Sprintf(`{"location":{"latitude":%f,"longitude":%f},"originalIndex":%d}`, lat+0.0001, lng, i))
+			if i == 0 {
+				snapped = append(snapped, fmt.Sprintf(`{"location":{"latitude":%f,"longitude":%f}}`, lat+0.0001, lng+0.00005))
+			}
+		}
+		fmt.Fprintf(w, `{"snappedPoints":[%s]}`, strings.Join(snapped, ","))
+	}))
+	defer server.Close()
+
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	var points gps.Points
+	for i := 0; i < 150; i++ {
+		points = append(points, gps.Point{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Latitude:  37.0,
+			Longitude: -122.0 + float64(i)*0.0001,
+			Title:     fmt.Sprintf("P%d", i),
+		})
+	}
+
+	snapper := &GoogleSnapper{APIKey: "test-key", Interpolate: true, BaseURL: server.URL, Client: server.Client()}
+	got, err := snapper.Snap(context.Background(), points)
+	if err != nil {
+		t.Fatalf("Snap() error = %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("Snap() made %d requests, want 2 batches", requests)
+	}
+	if len(got) != 152 {
+		t.Fatalf("Snap() returned %d points, want 152 (150 + 2 interpolated)", len(got))
+	}
+	if got[0].Title != "P0" || got[0].Latitude != 37.0001 {
+		t.Errorf("Snap()[0] = %+v, want snapped P0", got[0])
+	}
+
+	// The interpolated point sits halfway in time between its neighbors
+	if got[1].Title != "" || !got[1].Timestamp.Equal(start.Add(30*time.Second)) {
+		t.Errorf("Snap()[1] = %+v, want interpolated point at +30s", got[1])
+	}
+	if got[2].Title != "P1" {
+		t.Errorf("Snap()[2].Title = %q, want P1", got[2].Title)
+	}
+}
+
+func TestGoogleSnapperError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error":{"message":"API key not valid"}}`)
+	}))
+	defer server.Close()
+
+	snapper := &GoogleSnapper{APIKey: "bad", BaseURL: server.URL, Client: server.Client()}
+	_, err := snapper.Snap(context.Background(), gps.Points{{Latitude: 1, Longitude: 1}})
+	if err == nil || !strings.Contains(err.Error(), "API key not valid") {
+		t.Errorf("Snap() error = %v, want API error message", err)
+	}
+}
+
+func TestGoogleSnapperCancelled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"snappedPoints":[]}`)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	snapper := &GoogleSnapper{APIKey: "key", BaseURL: server.URL, Client: server.Client()}
+	_, err := snapper.Snap(ctx, gps.Points{{Latitude: 1, Longitude: 1}})
+	if !errors.Is(err, context.Canceled) || requests != 0 {
+		t.Errorf("Snap() after cancel = %v with %d requests, want context.Canceled and none", err, requests)
+	}
+}
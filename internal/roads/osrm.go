@@ -0,0 +1,209 @@
+package roads
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/progress"
+)
+
+// ProviderOSRM snaps tracks with a self-hosted OSRM match service.
+const ProviderOSRM = "osrm"
+
+// Defaults for the OSRM provider.
+const (
+	DefaultOSRMURL     = "http://localhost:5000"
+	DefaultOSRMProfile = "driving"
+)
+
+// osrmMaxPoints matches the default max-matching-size of osrm-routed.
+const osrmMaxPoints = 100
+
+func init() {
+	Register(ProviderOSRM, newOSRMSnapper)
+}
+
+// OSRMSnapper snaps tracks using the OSRM match service.
+//
+// @struct OSRMSnapper
+// @description Map matching through a self-hosted osrm-routed instance
+// @property BaseURL string OSRM server URL (e.g. http://localhost:5000)
+// @property Profile string Routing profile loaded on the server (driving, walking, cycling)
+// @property Interpolate bool Insert road geometry points between matched GPS points
+// @property Client *http.Client HTTP client used for requests
+type OSRMSnapper struct {
+	BaseURL     string       // @field BaseURL OSRM server URL
+	Profile     string       // @field Profile Routing profile name
+	Interpolate bool         // @field Interpolate Follow road geometry between points
+	Client      *http.Client // @field Client HTTP client for API requests
+
+	progress *progress.Bar // @field progress Bar counting the points sent (nil for none)
+}
+
+// newOSRMSnapper creates an OSRMSnapper from configuration. No API key is needed.
+func newOSRMSnapper(cfg *config.SnapConfig, _ string) (Snapper, error) {
+	snapper := &OSRMSnapper{
+		BaseURL:     strings.TrimSuffix(cfg.URL, "/"),
+		Profile:     cfg.Profile,
+		Interpolate: cfg.Interpolate,
+		Client:      &http.Client{Timeout: 30 * time.Second},
+	}
+	if snapper.BaseURL == "" {
+		snapper.BaseURL = DefaultOSRMURL
+	}
+	if snapper.Profile == "" {
+		snapper.Profile = DefaultOSRMProfile
+	}
+	if _, err := url.Parse(snapper.BaseURL); err != nil {
+		return nil, fmt.Errorf("invalid OSRM URL %s: %w", snapper.BaseURL, err)
+	}
+	return snapper, nil
+}
+
+// osrmMatchResponse is the JSON body returned by the match service.
+type osrmMatchResponse struct {
+	Code        string `json:"code"`
+	Message     string `json:"message"`
+	Tracepoints []*struct {
+		Location       [2]float64 `json:"location"` // [lng, lat]
+		MatchingsIndex int        `json:"matchings_index"`
+	} `json:"tracepoints"`
+	Matchings []struct {
+		Geometry struct {
+			Coordinates [][2]float64 `json:"coordinates"` // [lng, lat]
+		} `json:"geometry"`
+	} `json:"matchings"`
+}
+
+// Snap sends the track to the OSRM match service in batches of up to 100 points.
+//
+// @method Snap
+// @description Replaces GPS positions with their map-matched equivalents
+// @param ctx context.Context Cancels the remaining requests
+// @param points gps.Points Chronologically sorted GPS points
+// @return gps.Points Matched track; interpolated points get timestamps between their neighbors
+// @return error Error if any request fails
+// @note Points OSRM discards as outliers are kept at their original position
+func (s *OSRMSnapper) Snap(ctx context.Context, points gps.Points) (gps.Points, error) {
+	var result gps.Points
+	for start := 0; start < len(points); start += osrmMaxPoints {
+		end := start + osrmMaxPoints
+		if end > len(points) {
+			end = len(points)
+		}
+
+		snapped, err := s.snapBatch(ctx, points[start:end])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, snapped...)
+		s.progress.Set(int64(end))
+	}
+	return result, nil
+}
+
+// SetProgress reports the points sent to the match service to bar.
+func (s *OSRMSnapper) SetProgress(bar *progress.Bar) {
+	s.progress = bar
+}
+
+// snapBatch matches a single request-sized batch of points.
+func (s *OSRMSnapper) snapBatch(ctx context.Context, batch gps.Points) (gps.Points, error) {
+	// OSRM needs at least two coordinates to match
+	if len(batch) < 2 {
+		return batch, nil
+	}
+
+	coords := make([]string, len(batch))
+	timestamps := make([]string, len(batch))
+	for i, p := range batch {
+		coords[i] = fmt.Sprintf("%.6f,%.6f", p.Longitude, p.Latitude)
+		timestamps[i] = fmt.Sprint(p.Timestamp.Unix())
+	}
+
+	query := url.Values{}
+	query.Set("timestamps", strings.Join(timestamps, ";"))
+	query.Set("geometries", "geojson")
+	query.Set("overview", "full")
+	query.Set("gaps", "ignore")
+	requestURL := fmt.Sprintf("%s/match/v1/%s/%s?%s", s.BaseURL, s.Profile, strings.Join(coords, ";"), query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("OSRM request failed: %w", err)
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OSRM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body osrmMatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("cannot decode OSRM response: %w", err)
+	}
+
+	// NoMatch means nothing in this batch is near a road; keep the raw points
+	if body.Code == "NoMatch" {
+		return batch, nil
+	}
+	if body.Code != "Ok" {
+		return nil, fmt.Errorf("OSRM match error: %s %s", body.Code, body.Message)
+	}
+
+	result := append(gps.Points{}, batch...)
+	for i, tp := range body.Tracepoints {
+		if tp != nil && i < len(result) {
+			result[i].Longitude, result[i].Latitude = tp.Location[0], tp.Location[1]
+		}
+	}
+
+	if !s.Interpolate {
+		return result, nil
+	}
+	return mergeInterpolated(result, s.geometryBetween(body, result)), nil
+}
+
+// geometryBetween collects the road geometry vertices lying between consecutive
+// matched points of the same matching, keyed by the index of the earlier point.
+func (s *OSRMSnapper) geometryBetween(body osrmMatchResponse, matched gps.Points) map[int]gps.Points {
+	inserted := make(map[int]gps.Points)
+	cursor := make([]int, len(body.Matchings)) // search position within each geometry
+	prev := make([]int, len(body.Matchings))   // previous matched point per matching
+	for m := range prev {
+		prev[m] = -1
+	}
+
+	for i, tp := range body.Tracepoints {
+		if tp == nil || tp.MatchingsIndex < 0 || tp.MatchingsIndex >= len(body.Matchings) {
+			continue
+		}
+		m := tp.MatchingsIndex
+		coords := body.Matchings[m].Geometry.Coordinates
+
+		// Find the closest geometry vertex at or after the previous match
+		best, bestDist := cursor[m], math.Inf(1)
+		for j := cursor[m]; j < len(coords); j++ {
+			d := math.Hypot(coords[j][0]-tp.Location[0], coords[j][1]-tp.Location[1])
+			if d < bestDist {
+				best, bestDist = j, d
+			}
+		}
+
+		if prev[m] >= 0 && best > cursor[m]+1 {
+			for _, c := range coords[cursor[m]+1 : best] {
+				inserted[prev[m]] = append(inserted[prev[m]], gps.Point{Latitude: c[1], Longitude: c[0]})
+			}
+		}
+		cursor[m], prev[m] = best, i
+	}
+	return inserted
+}
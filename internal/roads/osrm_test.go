@@ -0,0 +1,116 @@
+package roads
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/progress"
+)
+
+func TestNewOSRMSnapper(t *testing.T) {
+	snapper, err := New(&config.SnapConfig{Provider: ProviderOSRM}, "")
+	if err != nil {
+		t.Fatalf("New(osrm) error = %v", err)
+	}
+	osrm := snapper.(*OSRMSnapper)
+	if osrm.BaseURL != DefaultOSRMURL || osrm.Profile != DefaultOSRMProfile {
+		t.Errorf("New(osrm) defaults = %+v", osrm)
+	}
+
+	snapper, _ = New(&config.SnapConfig{Provider: ProviderOSRM, URL: "http://osrm.local/", Profile: "cycling"}, "")
+	if osrm := snapper.(*OSRMSnapper); osrm.BaseURL != "http://osrm.local" || osrm.Profile != "cycling" {
+		t.Errorf("New(osrm) configured = %+v", osrm)
+	}
+}
+
+func TestOSRMSnapperSnap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/match/v1/driving/") {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("timestamps") == "" {
+			t.Error("request missing timestamps")
+		}
+
+		// Three points: the middle one is an outlier OSRM discards; the road bends
+		// through one extra vertex between the first and last matched points
+		fmt.Fprint(w, `{
+			"code": "Ok",
+			"tracepoints": [
+				{"location": [-122.0, 37.0001], "matchings_index": 0},
+				null,
+				{"location": [-121.998, 37.0001], "matchings_index": 0}
+			],
+			"matchings": [{"geometry": {"coordinates": [
+				[-122.0, 37.0001], [-121.999, 37.0005], [-121.998, 37.0001]
+			]}}]
+		}`)
+	}))
+	defer server.Close()
+
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.0, Longitude: -122.0, Title: "A"},
+		{Timestamp: start.Add(time.Minute), Latitude: 37.01, Longitude: -121.999, Title: "B"},
+		{Timestamp: start.Add(2 * time.Minute), Latitude: 37.0, Longitude: -121.998, Title: "C"},
+	}
+
+	snapper := &OSRMSnapper{BaseURL: server.URL, Profile: "driving", Client: server.Client()}
+	bar := progress.New(io.Discard, "Snapping", int64(len(points)), "points")
+	snapper.SetProgress(bar)
+	got, err := snapper.Snap(context.Background(), points)
+	if err != nil {
+		t.Fatalf("Snap() error = %v", err)
+	}
+	if len(got) != 3 || got[0].Latitude != 37.0001 || got[1].Latitude != 37.01 {
+		t.Errorf("Snap() without interpolation = %+v", got)
+	}
+	if !strings.Contains(bar.String(), "(3/3 points)") {
+		t.Errorf("Snap() progress = %q, want every point sent", bar.String())
+	}
+
+	snapper.Interpolate = true
+	got, err = snapper.Snap(context.Background(), points)
+	if err != nil {
+		t.Fatalf("Snap() error = %v", err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("Snap() with interpolation returned %d points, want 4", len(got))
+	}
+	if got[1].Title != "" || got[1].Latitude != 37.0005 || !got[1].Timestamp.Equal(start.Add(30*time.Second)) {
+		t.Errorf("Snap()[1] = %+v, want road vertex at +30s", got[1])
+	}
+}
+
+func TestOSRMSnapperErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "-1.000000") {
+			fmt.Fprint(w, `{"code": "NoMatch", "message": "Could not match the trace."}`)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"code": "InvalidQuery", "message": "Query string malformed"}`)
+	}))
+	defer server.Close()
+
+	snapper := &OSRMSnapper{BaseURL: server.URL, Profile: "driving", Client: server.Client()}
+
+	// NoMatch keeps the raw points instead of failing
+	offRoad := gps.Points{{Latitude: 1, Longitude: -1}, {Latitude: 1.1, Longitude: -1}}
+	if got, err := snapper.Snap(context.Background(), offRoad); err != nil || len(got) != 2 {
+		t.Errorf("Snap() on NoMatch = %v, %v, want raw points", got, err)
+	}
+
+	_, err := snapper.Snap(context.Background(), gps.Points{{Latitude: 1, Longitude: 2}, {Latitude: 1.1, Longitude: 2}})
+	if err == nil || !strings.Contains(err.Error(), "InvalidQuery") {
+		t.Errorf("Snap() error = %v, want InvalidQuery", err)
+	}
+}
@@ -0,0 +1,89 @@
+// Package roads provides road-network snapping for GPS tracks.
+//
+// @title Road Snapping Package
+// @version 1.0
+// @description Snaps jittery GPS tracks onto the road network before rendering
+// @description Providers are pluggable behind the Snapper interface
+//
+// Features:
+// - Snapper interface for road-matching services
+// - Google Roads API provider with batching and interpolation
+// - OSRM match service provider for self-hosted, unbilled map matching
+// - Provider registry selected from configuration
+// - Progress reporting for tracks sent in many requests
+package roads
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/progress"
+)
+
+// Snapper snaps a chronologically ordered GPS track onto the road network.
+// Implementations keep the original timestamps and metadata for matched points and
+// may insert extra points so the result follows the road geometry. Cancelling
+// ctx stops the requests still to be sent.
+type Snapper interface {
+	Snap(ctx context.Context, points gps.Points) (gps.Points, error)
+}
+
+// ProgressReporter is implemented by snappers that report how many points of
+// the track they have sent, so long tracks show their progress. The caller
+// finishes the bar once snapping is done.
+type ProgressReporter interface {
+	SetProgress(bar *progress.Bar)
+}
+
+// Factory creates a Snapper from the snapping configuration and the API key
+// configured for the map provider.
+type Factory func(cfg *config.SnapConfig, apiKey string) (Snapper, error)
+
+// providers maps provider names to their factories.
+var providers = map[string]Factory{}
+
+// Register makes a snapping provider available under a name for configuration.
+// Registering the same name twice replaces the earlier factory.
+//
+// @function Register
+// @description Adds a pluggable road snapping provider
+// @param name string Provider name used in processing.snap_to_roads.provider
+// @param factory Factory Constructor for the provider
+// @example roads.Register("custom", newCustomSnapper)
+func Register(name string, factory Factory) {
+	providers[name] = factory
+}
+
+// Providers returns the names of all registered providers in sorted order.
+func Providers() []string {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the Snapper configured in processing.snap_to_roads.
+//
+// @function New
+// @description Looks up and constructs the configured snapping provider
+// @param cfg *config.SnapConfig Snapping configuration
+// @param apiKey string Map provider API key, used when cfg has no key of its own
+// @return Snapper Configured provider, or nil when snapping is disabled
+// @return error Error if the provider is unknown or cannot be created
+// @example snapper, err := roads.New(&cfg.Processing.SnapToRoads, cfg.GoogleMaps.APIKey)
+func New(cfg *config.SnapConfig, apiKey string) (Snapper, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+
+	factory, ok := providers[cfg.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown snap-to-roads provider %q (available: %v)", cfg.Provider, Providers())
+	}
+	return factory(cfg, apiKey)
+}
@@ -0,0 +1,67 @@
+// Package roads_test provides unit tests for road-network snapping.
+// It tests the provider registry, Google Roads API batching and response mapping,
+// and timestamp assignment for interpolated points.
+package roads
+
+import (
+	"context"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// stubSnapper returns the points unchanged.
+type stubSnapper struct{}
+
+func (stubSnapper) Snap(_ context.Context, points gps.Points) (gps.Points, error) { return points, nil }
+
+func TestNew(t *testing.T) {
+	if snapper, err := New(&config.SnapConfig{}, "key"); snapper != nil || err != nil {
+		t.Errorf("New(disabled) = %v, %v, want nil, nil", snapper, err)
+	}
+
+	if _, err := New(&config.SnapConfig{Provider: "teleport"}, "key"); err == nil {
+		t.Error("New(unknown) error = nil, want error")
+	}
+
+	snapper, err := New(&config.SnapConfig{Provider: ProviderGoogle, Interpolate: true}, "map-key")
+	if err != nil {
+		t.Fatalf("New(google) error = %v", err)
+	}
+	google, ok := snapper.(*GoogleSnapper)
+	if !ok || google.APIKey != "map-key" || !google.Interpolate {
+		t.Errorf("New(google) = %+v", snapper)
+	}
+
+	// A dedicated key takes precedence over the map key
+	snapper, _ = New(&config.SnapConfig{Provider: ProviderGoogle, APIKey: "roads-key"}, "map-key")
+	if snapper.(*GoogleSnapper).APIKey != "roads-key" {
+		t.Errorf("New(google) APIKey = %q, want roads-key", snapper.(*GoogleSnapper).APIKey)
+	}
+
+	if _, err := New(&config.SnapConfig{Provider: ProviderGoogle}, "DEMO"); err == nil {
+		t.Error("New(google) with DEMO key error = nil, want error")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("stub", func(*config.SnapConfig, string) (Snapper, error) { return stubSnapper{}, nil })
+	defer delete(providers, "stub")
+
+	snapper, err := New(&config.SnapConfig{Provider: "stub"}, "")
+	if err != nil {
+		t.Fatalf("New(stub) error = %v", err)
+	}
+	if _, ok := snapper.(stubSnapper); !ok {
+		t.Errorf("New(stub) = %T, want stubSnapper", snapper)
+	}
+
+	found := false
+	for _, name := range Providers() {
+		found = found || name == "stub"
+	}
+	if !found {
+		t.Errorf("Providers() = %v, missing stub", Providers())
+	}
+}
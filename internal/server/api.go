@@ -0,0 +1,415 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/csv"
+	"github.com/saratily/geo-chrono/internal/export"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/gpx"
+	"github.com/saratily/geo-chrono/internal/store"
+)
+
+// DefaultMaxUploadSize is the largest accepted upload in bytes when
+// server.max_upload_size is not set.
+const DefaultMaxUploadSize = 32 << 20
+
+// Upload formats accepted by POST /tracks.
+const (
+	UploadCSV = "csv"
+	UploadGPX = "gpx"
+)
+
+// Loader processes a stored track into a job the way the serve command
+// processes its input: filter stages, statistics, and enabled lookups.
+type Loader func(track *store.Track) (*export.Job, error)
+
+// loadedTrack is a stored track, without its points, with its processed job.
+type loadedTrack struct {
+	track *store.Track
+	job   *export.Job
+}
+
+// trackInfo describes a stored track in API responses.
+type trackInfo struct {
+	ID      string    `json:"id"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	Owner   string    `json:"owner,omitempty"`  // Uploading user, when server users are configured
+	Public  bool      `json:"public"`           // Whether anyone may view the track
+	Points  int       `json:"points,omitempty"` // Uploaded points, left out of listings
+	Map     string    `json:"map"`              // URL path of the track's map
+	Stats   string    `json:"stats"`            // URL path of the track's statistics
+}
+
+// mergeInfo describes a stored track after points were merged into it.
+type mergeInfo struct {
+	trackInfo
+	Added int `json:"added"` // Points the track did not hold yet
+}
+
+// EnableAPI adds the track API to the server: POST /tracks uploads a CSV or GPX
+// track, GET /tracks lists the stored tracks, GET /tracks/{id} describes one,
+// and GET /maps/{id}.html and GET /tracks/{id}/stats.json return a track's map
+// and statistics. POST /tracks/{id}/points adds the new points of a CSV or GPX
+// upload to a stored track.
+//
+// With server users configured, uploading and listing take an API token, each
+// user sees their own tracks, and PATCH /tracks/{id} shares or unshares a
+// track. Only the owner may share a track or add points to it. GET /users/{name}
+// lists a user's shared tracks.
+//
+// @method EnableAPI
+// @description Turns the preview server into a small track service
+// @param tracks store.Store Storage keeping uploaded tracks
+// @param load Loader Processes a stored track into a job for rendering
+// @note The most recently requested processed tracks are kept in memory, until
+// points are added to them
+// @example srv.EnableAPI(store.NewMemory(), loader)
+func (s *Server) EnableAPI(tracks store.Store, load Loader) {
+	s.tracks = tracks
+	s.load = load
+	s.loaded = newTrackCache(maxLoadedTracks)
+	s.users = s.current().Config.Server.Users
+	s.mux.HandleFunc("/tracks", s.serveTracks)
+	s.mux.HandleFunc("/tracks/", s.serveTrack)
+	s.mux.HandleFunc("/maps/", s.serveTrackMap)
+	s.mux.HandleFunc("/users/", s.serveUser)
+}
+
+// serveTracks lists the stored tracks or stores an uploaded one. With server
+// users configured, both take a token and the listing holds the user's tracks.
+func (s *Server) serveTracks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		user, ok := s.requireUser(w, r)
+		if !ok {
+			return
+		}
+		tracks, err := s.tracks.List()
+		if err != nil {
+			slog.Error("Cannot list tracks", "error", err)
+			http.Error(w, "cannot list tracks", http.StatusInternalServerError)
+			return
+		}
+		infos := make([]trackInfo, 0, len(tracks))
+		for _, track := range tracks {
+			if track.Owner == user {
+				infos = append(infos, infoFor(track))
+			}
+		}
+		writeJSON(w, http.StatusOK, infos)
+	case http.MethodPost:
+		if user, ok := s.requireUser(w, r); ok {
+			s.uploadTrack(w, r, user)
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// uploadTrack stores the track in the request body for its owner. The track is
+// processed before it is stored, so tracks that cannot be mapped are rejected
+// up front. Without server users every track is public; with them, tracks are
+// private unless the public query parameter is true.
+func (s *Server) uploadTrack(w http.ResponseWriter, r *http.Request, owner string) {
+	public := !s.authEnabled()
+	if value := r.URL.Query().Get("public"); value != "" && !public {
+		var err error
+		if public, err = strconv.ParseBool(value); err != nil {
+			http.Error(w, fmt.Sprintf("invalid public parameter %q", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	points, ok := s.readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := store.NewID()
+	if err != nil {
+		slog.Error("Cannot store track", "error", err)
+		http.Error(w, "cannot store track", http.StatusInternalServerError)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "Track " + id
+	}
+	track := &store.Track{ID: id, Name: name, Created: time.Now().UTC(), Owner: owner, Public: public, Points: points}
+
+	job, err := s.load(track)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot process track: %v", err), http.StatusUnprocessableEntity)
+		return
+	}
+	if err := s.tracks.Save(track); err != nil {
+		slog.Error("Cannot store track", "error", err)
+		http.Error(w, "cannot store track", http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	s.loaded.put(id, &loadedTrack{track: summary(track), job: job})
+	s.mu.Unlock()
+
+	info := infoFor(track)
+	info.Points = len(points)
+	w.Header().Set("Location", info.Map)
+	writeJSON(w, http.StatusCreated, info)
+}
+
+// mergeTrack adds the points of the upload in the request body that a stored
+// track does not hold yet, keeping the ones it holds. The track is processed
+// again on its next request.
+func (s *Server) mergeTrack(w http.ResponseWriter, r *http.Request, id string) {
+	user, ok := s.requireUser(w, r)
+	if !ok {
+		return
+	}
+	points, ok := s.readUpload(w, r)
+	if !ok {
+		return
+	}
+	track, ok := s.ownTrack(w, r, id, user, "add points to")
+	if !ok {
+		return
+	}
+
+	added, err := s.tracks.Merge(id, points)
+	if errors.Is(err, store.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("Cannot store track", "track", id, "error", err)
+		http.Error(w, "cannot store track", http.StatusInternalServerError)
+		return
+	}
+	if added > 0 {
+		s.mu.Lock()
+		s.loaded.remove(id)
+		s.mu.Unlock()
+	}
+
+	info := mergeInfo{trackInfo: infoFor(track), Added: added}
+	info.Points = len(track.Points) + added
+	writeJSON(w, http.StatusOK, info)
+}
+
+// readUpload reads and parses the track uploaded in the request body, writing
+// the error response and returning false if it holds no points.
+func (s *Server) readUpload(w http.ResponseWriter, r *http.Request) (gps.Points, bool) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxUploadSize()))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("upload larger than %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot read upload: %v", err), http.StatusBadRequest)
+		return nil, false
+	}
+
+	points, err := s.parseUpload(r, data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot read track: %v", err), http.StatusBadRequest)
+		return nil, false
+	}
+	if points.IsEmpty() {
+		http.Error(w, "no valid GPS points found in upload", http.StatusBadRequest)
+		return nil, false
+	}
+	return points, true
+}
+
+// parseUpload reads the uploaded points as CSV, with the configured column
+// mapping, or as GPX. The format query parameter names the format; without it,
+// a GPX or XML content type or data starting with "<" is read as GPX.
+func (s *Server) parseUpload(r *http.Request, data []byte) (gps.Points, error) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = UploadCSV
+		if strings.Contains(mediaType, "gpx") || strings.HasSuffix(mediaType, "xml") || bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+			format = UploadGPX
+		}
+	}
+
+	switch format {
+	case UploadCSV:
+		// Duplicates are left for the pipeline's dedupe stage, as for the input file
+		cfg := s.current().Config
+		readOptions := cfg.Processing
+		readOptions.RemoveDuplicates = false
+		return csv.NewReader(&cfg.Input.CSVFormat, &readOptions).Read(bytes.NewReader(data))
+	case UploadGPX:
+		return gpx.Parse(data)
+	default:
+		return nil, fmt.Errorf("unknown format %q (use %s or %s)", format, UploadCSV, UploadGPX)
+	}
+}
+
+// serveTrack describes a stored track at /tracks/{id}, changes its sharing with
+// PATCH there, adds points to it with POST /tracks/{id}/points, and returns its
+// statistics at /tracks/{id}/stats.json.
+func (s *Server) serveTrack(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/tracks/")
+	if store.ValidID(rest) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			if loaded, ok := s.viewTrack(w, r, rest); ok {
+				writeJSON(w, http.StatusOK, infoFor(loaded.track))
+			}
+		case http.MethodPatch:
+			s.shareTrack(w, r, rest)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PATCH")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	if id, ok := strings.CutSuffix(rest, "/points"); ok && store.ValidID(id) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.mergeTrack(w, r, id)
+		return
+	}
+	id, ok := strings.CutSuffix(rest, "/stats.json")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r) {
+		return
+	}
+	loaded, ok := s.viewTrack(w, r, id)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := loaded.job.Summary.WriteJSON(&buf); err != nil {
+		slog.Error("Cannot write track statistics", "track", id, "error", err)
+		http.Error(w, "cannot write statistics", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
+
+// serveTrackMap renders the map of a stored track at /maps/{id}.html.
+func (s *Server) serveTrackMap(w http.ResponseWriter, r *http.Request) {
+	id, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/maps/"), ".html")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r) {
+		return
+	}
+	if loaded, ok := s.viewTrack(w, r, id); ok {
+		renderMap(w, loaded.job, nil, "")
+	}
+}
+
+// viewTrack returns a stored track the caller may view with its processed job,
+// writing the error response and returning false if there is none. Tracks
+// hidden from the caller are reported as not found.
+func (s *Server) viewTrack(w http.ResponseWriter, r *http.Request, id string) (*loadedTrack, bool) {
+	user, ok := s.authenticate(w, r)
+	if !ok {
+		return nil, false
+	}
+	if !store.ValidID(id) {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	s.mu.Lock()
+	loaded, ok := s.loaded.get(id)
+	s.mu.Unlock()
+	if ok {
+		if !s.visible(loaded.track, user) {
+			http.NotFound(w, r)
+			return nil, false
+		}
+		return loaded, true
+	}
+
+	// Check the stored owner and sharing before processing, so hidden tracks
+	// are neither processed nor kept for other users
+	track, err := s.tracks.Load(id)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && !s.visible(track, user)) {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	var job *export.Job
+	if err == nil {
+		job, err = s.load(track)
+	}
+	if err != nil {
+		slog.Error("Cannot load track", "track", id, "error", err)
+		http.Error(w, "cannot load track", http.StatusInternalServerError)
+		return nil, false
+	}
+	loaded = &loadedTrack{track: summary(track), job: job}
+	s.mu.Lock()
+	s.loaded.put(id, loaded)
+	s.mu.Unlock()
+	return loaded, true
+}
+
+// maxUploadSize returns the configured upload limit in bytes.
+func (s *Server) maxUploadSize() int64 {
+	if size := s.current().Config.Server.MaxUploadSize; size > 0 {
+		return size
+	}
+	return DefaultMaxUploadSize
+}
+
+// infoFor describes a stored track with the URL paths of its map and statistics.
+func infoFor(track *store.Track) trackInfo {
+	return trackInfo{
+		ID:      track.ID,
+		Name:    track.Name,
+		Created: track.Created,
+		Owner:   track.Owner,
+		Public:  track.Public,
+		Map:     "/maps/" + track.ID + ".html",
+		Stats:   "/tracks/" + track.ID + "/stats.json",
+	}
+}
+
+// summary returns a copy of track without its points.
+func summary(track *store.Track) *store.Track {
+	info := *track
+	info.Points = nil
+	return &info
+}
+
+// writeJSON writes v as an indented JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		slog.Error("Cannot encode response", "error", err)
+		http.Error(w, "cannot encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
@@ -0,0 +1,166 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/export"
+	"github.com/saratily/geo-chrono/internal/stats"
+	"github.com/saratily/geo-chrono/internal/store"
+)
+
+// testAPI returns a server with the track API, whose loader titles maps with the
+// track name and rejects tracks of a single point.
+func testAPI(maxUploadSize int64) *Server {
+	job := testJob(false)
+	job.Config.Server.MaxUploadSize = maxUploadSize
+	s := New(job)
+	s.EnableAPI(store.NewMemory(), func(track *store.Track) (*export.Job, error) {
+		if len(track.Points) < 2 {
+			return nil, errors.New("track needs at least two points")
+		}
+		cfg := *job.Config
+		cfg.Map.Title = track.Name
+		return &export.Job{Points: track.Points, Summary: stats.Compute(track.Points, &cfg.Statistics), Config: &cfg}, nil
+	})
+	return s
+}
+
+func request(handler http.Handler, method, target, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIUpload(t *testing.T) {
+	csvTrack := "timestamp,latitude,longitude\n2025-10-28T10:00:00Z,37.7749,-122.4194\n2025-10-28T10:10:00Z,37.7849,-122.4094\n"
+	gpxTrack := `<gpx version="1.1"><trk><trkseg>` +
+		`<trkpt lat="47.37" lon="8.54"><time>2025-10-28T10:00:00Z</time></trkpt>` +
+		`<trkpt lat="47.38" lon="8.55"><time>2025-10-28T10:10:00Z</time></trkpt>` +
+		`</trkseg></trk></gpx>`
+
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		wantTitle   string
+	}{
+		{name: "csv", target: "/tracks?name=Bay+Walk", contentType: "text/csv", body: csvTrack, wantTitle: "Bay Walk"},
+		{name: "gpx by content type", target: "/tracks?name=Zurich", contentType: "application/gpx+xml", body: gpxTrack, wantTitle: "Zurich"},
+		{name: "gpx sniffed", target: "/tracks", body: "\n" + gpxTrack, wantTitle: "Track "},
+		{name: "format parameter", target: "/tracks?format=CSV&name=Named", contentType: "application/octet-stream", body: csvTrack, wantTitle: "Named"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testAPI(0)
+			rec := request(s, http.MethodPost, tt.target, tt.contentType, tt.body)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("POST %s = %d %s", tt.target, rec.Code, rec.Body)
+			}
+			var info trackInfo
+			if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+				t.Fatalf("POST %s response: %v", tt.target, err)
+			}
+			if !store.ValidID(info.ID) || info.Points != 2 || rec.Header().Get("Location") != "/maps/"+info.ID+".html" {
+				t.Errorf("POST %s = %+v, Location %q", tt.target, info, rec.Header().Get("Location"))
+			}
+
+			// The stored track is served as a map, statistics, and in the listing
+			page := request(s, http.MethodGet, info.Map, "", "")
+			if page.Code != http.StatusOK || !strings.Contains(page.Body.String(), "<title>"+tt.wantTitle) {
+				t.Errorf("GET %s = %d, want the map titled %q", info.Map, page.Code, tt.wantTitle)
+			}
+			if strings.Contains(page.Body.String(), "EventSource") {
+				t.Errorf("GET %s listens for live points of the input track", info.Map)
+			}
+			summary := request(s, http.MethodGet, info.Stats, "", "")
+			if summary.Code != http.StatusOK || !strings.Contains(summary.Body.String(), `"points": 2`) {
+				t.Errorf("GET %s = %d %s", info.Stats, summary.Code, summary.Body)
+			}
+			list := request(s, http.MethodGet, "/tracks", "", "")
+			if list.Code != http.StatusOK || !strings.Contains(list.Body.String(), `"id": "`+info.ID+`"`) {
+				t.Errorf("GET /tracks = %d %s", list.Code, list.Body)
+			}
+		})
+	}
+}
+
+func TestAPIMerge(t *testing.T) {
+	s := testAPI(0)
+	rec := request(s, http.MethodPost, "/tracks", "text/csv", "timestamp,latitude,longitude\n2025-10-28T10:00:00Z,37.7749,-122.4194\n2025-10-28T10:10:00Z,37.7849,-122.4094\n")
+	var info trackInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("POST /tracks = %d %s", rec.Code, rec.Body)
+	}
+	// Process the track once, so that the merge has to replace the cached job
+	request(s, http.MethodGet, info.Stats, "", "")
+
+	target := "/tracks/" + info.ID + "/points"
+	for _, tt := range []struct {
+		body       string
+		wantAdded  int
+		wantPoints int
+	}{
+		{body: "timestamp,latitude,longitude\n2025-10-28T10:10:00Z,37.7849,-122.4094\n2025-10-28T10:20:00Z,37.7949,-122.3994\n", wantAdded: 1, wantPoints: 3},
+		{body: "timestamp,latitude,longitude\n2025-10-28T10:20:00Z,37.7949,-122.3994\n", wantAdded: 0, wantPoints: 3},
+	} {
+		rec := request(s, http.MethodPost, target, "text/csv", tt.body)
+		var merged mergeInfo
+		if err := json.Unmarshal(rec.Body.Bytes(), &merged); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("POST %s = %d %s", target, rec.Code, rec.Body)
+		}
+		if merged.ID != info.ID || merged.Added != tt.wantAdded || merged.Points != tt.wantPoints {
+			t.Errorf("POST %s = %+v, want %d added of %d points", target, merged, tt.wantAdded, tt.wantPoints)
+		}
+		if summary := request(s, http.MethodGet, info.Stats, "", ""); !strings.Contains(summary.Body.String(), fmt.Sprintf(`"points": %d`, tt.wantPoints)) {
+			t.Errorf("GET %s after merging = %s, want %d points", info.Stats, summary.Body, tt.wantPoints)
+		}
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+	}{
+		{name: "unknown map", method: http.MethodGet, target: "/maps/00000000000000aa.html", wantStatus: http.StatusNotFound},
+		{name: "unknown stats", method: http.MethodGet, target: "/tracks/00000000000000aa/stats.json", wantStatus: http.StatusNotFound},
+		{name: "invalid id", method: http.MethodGet, target: "/maps/not-an-id.html", wantStatus: http.StatusNotFound},
+		{name: "unknown track", method: http.MethodGet, target: "/tracks/00000000000000aa", wantStatus: http.StatusNotFound},
+		{name: "other track path", method: http.MethodGet, target: "/tracks/00000000000000aa/map", wantStatus: http.StatusNotFound},
+		{name: "share unknown track", method: http.MethodPatch, target: "/tracks/00000000000000aa", body: `{"public": true}`, wantStatus: http.StatusNotFound},
+		{name: "add points to unknown track", method: http.MethodPost, target: "/tracks/00000000000000aa/points", body: "timestamp,latitude,longitude\n2025-10-28T10:00:00Z,37.7749,-122.4194\n", wantStatus: http.StatusNotFound},
+		{name: "add no points", method: http.MethodPost, target: "/tracks/00000000000000aa/points", body: "timestamp,latitude,longitude\n", wantStatus: http.StatusBadRequest},
+		{name: "points method", method: http.MethodGet, target: "/tracks/00000000000000aa/points", wantStatus: http.StatusMethodNotAllowed},
+		{name: "share without setting", method: http.MethodPatch, target: "/tracks/00000000000000aa", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown format", method: http.MethodPost, target: "/tracks?format=kml", body: "<kml/>", wantStatus: http.StatusBadRequest},
+		{name: "no points", method: http.MethodPost, target: "/tracks", body: "timestamp,latitude,longitude\n", wantStatus: http.StatusBadRequest},
+		{name: "unprocessable", method: http.MethodPost, target: "/tracks", body: "timestamp,latitude,longitude\n2025-10-28T10:00:00Z,37.7749,-122.4194\n", wantStatus: http.StatusUnprocessableEntity},
+		{name: "too large", method: http.MethodPost, target: "/tracks", body: strings.Repeat("x", 200), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "method", method: http.MethodDelete, target: "/tracks", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testAPI(100)
+			if rec := request(s, tt.method, tt.target, "", tt.body); rec.Code != tt.wantStatus {
+				t.Errorf("%s %s = %d %s, want %d", tt.method, tt.target, rec.Code, rec.Body, tt.wantStatus)
+			}
+			if list := request(s, http.MethodGet, "/tracks", "", ""); strings.TrimSpace(list.Body.String()) != "[]" {
+				t.Errorf("GET /tracks = %s after a failed request, want no tracks", list.Body)
+			}
+		})
+	}
+}
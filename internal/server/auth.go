@@ -0,0 +1,227 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/store"
+)
+
+// maxShareRequestSize is the largest accepted body of a sharing change.
+const maxShareRequestSize = 4 << 10
+
+// shareRequest is the body of PATCH /tracks/{id}.
+type shareRequest struct {
+	Public *bool `json:"public"` // Whether anyone may view the track
+}
+
+// userPage lists the tracks of a server user at /users/{name}.
+var userPage = template.Must(template.New("user").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Tracks of {{.User}}</title>
+<style>
+body { font-family: Arial, sans-serif; margin: 2em auto; max-width: 40em; padding: 0 1em; color: #333; }
+li { margin: 0.5em 0; }
+.meta { color: #777; font-size: 0.9em; }
+</style>
+</head>
+<body>
+<h1>Tracks of {{.User}}</h1>
+{{if .Tracks}}<ul>
+{{range .Tracks}}<li><a href="{{.Map}}">{{.Name}}</a> <span class="meta">{{.Created.Format "2006-01-02 15:04"}} UTC{{if not .Public}}, private{{end}}</span></li>
+{{end}}</ul>
+{{else}}<p>No shared tracks yet.</p>
+{{end}}</body>
+</html>
+`))
+
+// userPageData is the data of userPage.
+type userPageData struct {
+	User   string
+	Tracks []userPageTrack
+}
+
+// userPageTrack is a track listed on userPage.
+type userPageTrack struct {
+	trackInfo
+	Map template.URL // Map link, carrying the owner's token query parameter
+}
+
+// authEnabled reports whether server users are configured. Without them the
+// track API is open and every track is public.
+func (s *Server) authEnabled() bool {
+	return len(s.users) > 0
+}
+
+// authenticate returns the server user making the request, identified by the
+// token in an "Authorization: Bearer" header or, for links opened in a browser,
+// the token query parameter. Anonymous requests return "". A token matching no
+// user gets a 401 response and false.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if !s.authEnabled() {
+		return "", true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	if token == "" {
+		return "", true
+	}
+	for _, user := range s.users {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(user.Token)) == 1 {
+			return user.Name, true
+		}
+	}
+	unauthorized(w, "invalid API token")
+	return "", false
+}
+
+// requireUser is authenticate for requests that need a server user whenever
+// users are configured.
+func (s *Server) requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	user, ok := s.authenticate(w, r)
+	if ok && user == "" && s.authEnabled() {
+		unauthorized(w, "API token required")
+		return "", false
+	}
+	return user, ok
+}
+
+// visible reports whether user may view the map and statistics of track.
+func (s *Server) visible(track *store.Track, user string) bool {
+	return !s.authEnabled() || track.Public || track.Owner == user
+}
+
+// shareTrack sets whether anyone may view a stored track, as requested by the
+// JSON body {"public": true} or {"public": false}. Only the owner may.
+func (s *Server) shareTrack(w http.ResponseWriter, r *http.Request, id string) {
+	user, ok := s.requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var share shareRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxShareRequestSize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&share); err != nil || share.Public == nil {
+		http.Error(w, `request body must be {"public": true} or {"public": false}`, http.StatusBadRequest)
+		return
+	}
+
+	track, ok := s.ownTrack(w, r, id, user, "share")
+	if !ok {
+		return
+	}
+
+	track.Public = *share.Public
+	if err := s.tracks.Save(track); err != nil {
+		slog.Error("Cannot store track", "track", id, "error", err)
+		http.Error(w, "cannot store track", http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	if loaded, ok := s.loaded.get(id); ok {
+		s.loaded.put(id, &loadedTrack{track: summary(track), job: loaded.job})
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, infoFor(track))
+}
+
+// ownTrack returns a stored track of user, writing the error response and
+// returning false if there is none. The action, such as "share", names what
+// only the owner may do in the error for other users who can view the track;
+// tracks hidden from them are reported as not found.
+func (s *Server) ownTrack(w http.ResponseWriter, r *http.Request, id, user, action string) (*store.Track, bool) {
+	track, err := s.tracks.Load(id)
+	if errors.Is(err, store.ErrNotFound) {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("Cannot load track", "track", id, "error", err)
+		http.Error(w, "cannot load track", http.StatusInternalServerError)
+		return nil, false
+	}
+	if track.Owner != user {
+		if s.visible(track, user) {
+			http.Error(w, "only the owner may "+action+" a track", http.StatusForbidden)
+		} else {
+			http.NotFound(w, r)
+		}
+		return nil, false
+	}
+	return track, true
+}
+
+// serveUser renders the page of a server user at /users/{name}, listing their
+// public tracks, or all of them for the user themselves.
+func (s *Server) serveUser(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/users/")
+	if !s.hasUser(name) {
+		http.NotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r) {
+		return
+	}
+	caller, ok := s.authenticate(w, r)
+	if !ok {
+		return
+	}
+
+	tracks, err := s.tracks.List()
+	if err != nil {
+		slog.Error("Cannot list tracks", "error", err)
+		http.Error(w, "cannot list tracks", http.StatusInternalServerError)
+		return
+	}
+	// The owner's private maps open with the token the page was opened with
+	var query string
+	if token := r.URL.Query().Get("token"); caller == name && token != "" {
+		query = "?token=" + url.QueryEscape(token)
+	}
+	data := userPageData{User: name}
+	for _, track := range tracks {
+		if track.Owner == name && s.visible(track, caller) {
+			info := infoFor(track)
+			data.Tracks = append(data.Tracks, userPageTrack{trackInfo: info, Map: template.URL(info.Map + query)})
+		}
+	}
+
+	var buf strings.Builder
+	if err := userPage.Execute(&buf, data); err != nil {
+		slog.Error("Cannot render user page", "user", name, "error", err)
+		http.Error(w, "cannot render page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, buf.String())
+}
+
+// hasUser reports whether name is a configured server user.
+func (s *Server) hasUser(name string) bool {
+	for _, user := range s.users {
+		if user.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// unauthorized writes a 401 response asking for a bearer token.
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="geo-chrono"`)
+	http.Error(w, message, http.StatusUnauthorized)
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/export"
+	"github.com/saratily/geo-chrono/internal/store"
+)
+
+const (
+	aliceToken = "alice-0123456789abcdef"
+	bobToken   = "bob-0123456789abcdef"
+)
+
+// testUsersAPI returns a track API server with the users alice and bob.
+func testUsersAPI(t *testing.T) *Server {
+	t.Helper()
+	s := testAPI(0)
+	s.users = []config.ServerUserConfig{{Name: "alice", Token: aliceToken}, {Name: "bob", Token: bobToken}}
+	return s
+}
+
+func requestAs(handler http.Handler, method, target, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// upload stores a two point track as alice and returns its description.
+func upload(t *testing.T, s *Server, target string) trackInfo {
+	t.Helper()
+	csvTrack := "timestamp,latitude,longitude\n2025-10-28T10:00:00Z,37.7749,-122.4194\n2025-10-28T10:10:00Z,37.7849,-122.4094\n"
+	rec := requestAs(s, http.MethodPost, target, aliceToken, csvTrack)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST %s = %d %s", target, rec.Code, rec.Body)
+	}
+	var info trackInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("POST %s response: %v", target, err)
+	}
+	return info
+}
+
+func TestAuthUpload(t *testing.T) {
+	s := testUsersAPI(t)
+	for _, token := range []string{"", "not-a-valid-token-at-all"} {
+		rec := requestAs(s, http.MethodPost, "/tracks", token, "timestamp,latitude,longitude\n")
+		if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+			t.Errorf("POST /tracks with token %q = %d, want 401 with a challenge", token, rec.Code)
+		}
+	}
+
+	info := upload(t, s, "/tracks?name=Private")
+	if info.Owner != "alice" || info.Public {
+		t.Errorf("POST /tracks = %+v, want a private track of alice", info)
+	}
+	if shared := upload(t, s, "/tracks?name=Shared&public=true"); !shared.Public {
+		t.Errorf("POST /tracks?public=true = %+v, want a public track", shared)
+	}
+	if rec := requestAs(s, http.MethodPost, "/tracks?public=maybe", aliceToken, ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST /tracks?public=maybe = %d, want 400", rec.Code)
+	}
+
+	// Listings hold the caller's own tracks only
+	if rec := requestAs(s, http.MethodGet, "/tracks", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /tracks without token = %d, want 401", rec.Code)
+	}
+	if rec := requestAs(s, http.MethodGet, "/tracks", aliceToken, ""); strings.Count(rec.Body.String(), `"owner": "alice"`) != 2 {
+		t.Errorf("GET /tracks as alice = %s, want her two tracks", rec.Body)
+	}
+	if rec := requestAs(s, http.MethodGet, "/tracks", bobToken, ""); strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("GET /tracks as bob = %s, want no tracks", rec.Body)
+	}
+}
+
+func TestAuthSharing(t *testing.T) {
+	s := testUsersAPI(t)
+	info := upload(t, s, "/tracks?name=Walk")
+	track := "/tracks/" + info.ID
+
+	// A private track is only found by its owner, with a header or query token
+	for _, target := range []string{info.Map, info.Stats, track} {
+		for token, want := range map[string]int{"": http.StatusNotFound, bobToken: http.StatusNotFound, aliceToken: http.StatusOK} {
+			if rec := requestAs(s, http.MethodGet, target, token, ""); rec.Code != want {
+				t.Errorf("GET %s with token %q = %d, want %d", target, token, rec.Code, want)
+			}
+		}
+	}
+	if rec := requestAs(s, http.MethodGet, info.Map+"?token="+aliceToken, "", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET %s?token= = %d, want 200", info.Map, rec.Code)
+	}
+
+	// Only the owner shares it, after which anyone views it
+	share := `{"public": true}`
+	if rec := requestAs(s, http.MethodPatch, track, "", share); rec.Code != http.StatusUnauthorized {
+		t.Errorf("PATCH %s without token = %d, want 401", track, rec.Code)
+	}
+	if rec := requestAs(s, http.MethodPatch, track, bobToken, share); rec.Code != http.StatusNotFound {
+		t.Errorf("PATCH %s as bob = %d, want 404", track, rec.Code)
+	}
+	if rec := requestAs(s, http.MethodPatch, track, aliceToken, share); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"public": true`) {
+		t.Errorf("PATCH %s as alice = %d %s", track, rec.Code, rec.Body)
+	}
+	if rec := requestAs(s, http.MethodGet, info.Map, "", ""); rec.Code != http.StatusOK {
+		t.Errorf("GET %s of a shared track = %d, want 200", info.Map, rec.Code)
+	}
+	if rec := requestAs(s, http.MethodPatch, track, bobToken, `{"public": false}`); rec.Code != http.StatusForbidden {
+		t.Errorf("PATCH %s of a shared track as bob = %d, want 403", track, rec.Code)
+	}
+
+	// Only the owner adds points to it
+	points := track + "/points"
+	later := "timestamp,latitude,longitude\n2025-10-28T10:20:00Z,37.7949,-122.3994\n"
+	if rec := requestAs(s, http.MethodPost, points, "", later); rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST %s without token = %d, want 401", points, rec.Code)
+	}
+	if rec := requestAs(s, http.MethodPost, points, bobToken, later); rec.Code != http.StatusForbidden {
+		t.Errorf("POST %s of a shared track as bob = %d, want 403", points, rec.Code)
+	}
+	if rec := requestAs(s, http.MethodPost, points, aliceToken, later); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"added": 1`) {
+		t.Errorf("POST %s as alice = %d %s", points, rec.Code, rec.Body)
+	}
+
+	// Unsharing hides it again, even though its map was cached
+	if rec := requestAs(s, http.MethodPatch, track, aliceToken, `{"public": false}`); rec.Code != http.StatusOK {
+		t.Errorf("PATCH %s unsharing = %d %s", track, rec.Code, rec.Body)
+	}
+	if rec := requestAs(s, http.MethodGet, info.Map, "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET %s of an unshared track = %d, want 404", info.Map, rec.Code)
+	}
+}
+
+func TestAuthHiddenTrackNotLoaded(t *testing.T) {
+	s := testUsersAPI(t)
+	info := upload(t, s, "/tracks?name=Diary")
+	s.loaded.remove(info.ID)
+
+	// Other users neither get a private track processed nor kept in memory
+	loads := 0
+	load := s.load
+	s.load = func(track *store.Track) (*export.Job, error) {
+		loads++
+		return load(track)
+	}
+	if rec := requestAs(s, http.MethodGet, info.Map, bobToken, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET %s as bob = %d, want 404", info.Map, rec.Code)
+	}
+	if _, ok := s.loaded.get(info.ID); loads != 0 || ok {
+		t.Errorf("GET %s as bob processed the track %d times, cached = %v", info.Map, loads, ok)
+	}
+	if rec := requestAs(s, http.MethodGet, info.Map, aliceToken, ""); rec.Code != http.StatusOK || loads != 1 {
+		t.Errorf("GET %s as alice = %d after %d loads, want 200 after 1", info.Map, rec.Code, loads)
+	}
+}
+
+func TestAuthUserPage(t *testing.T) {
+	s := testUsersAPI(t)
+	private := upload(t, s, "/tracks?name=Diary")
+	public := upload(t, s, "/tracks?name=Summit&public=1")
+
+	visitor := requestAs(s, http.MethodGet, "/users/alice", "", "")
+	if visitor.Code != http.StatusOK || !strings.Contains(visitor.Body.String(), public.Map) || strings.Contains(visitor.Body.String(), private.Map) {
+		t.Errorf("GET /users/alice = %d, want only the public track\n%s", visitor.Code, visitor.Body)
+	}
+	owner := requestAs(s, http.MethodGet, "/users/alice?token="+aliceToken, "", "")
+	if !strings.Contains(owner.Body.String(), private.Map+"?token="+aliceToken) || !strings.Contains(owner.Body.String(), "private") {
+		t.Errorf("GET /users/alice as alice, want the private track linked with her token\n%s", owner.Body)
+	}
+	if rec := requestAs(s, http.MethodGet, "/users/bob", "", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "No shared tracks") {
+		t.Errorf("GET /users/bob = %d %s", rec.Code, rec.Body)
+	}
+	for _, target := range []string{"/users/carol", "/users/", "/users/alice/tracks"} {
+		if rec := requestAs(s, http.MethodGet, target, "", ""); rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s = %d, want 404", target, rec.Code)
+		}
+	}
+
+	// Without users the API stays open and has no user pages
+	open := testAPI(0)
+	if rec := requestAs(open, http.MethodGet, "/users/alice", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /users/alice without users = %d, want 404", rec.Code)
+	}
+}
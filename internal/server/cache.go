@@ -0,0 +1,60 @@
+package server
+
+import "container/list"
+
+// maxLoadedTracks bounds how many processed stored tracks the server keeps in
+// memory; the least recently requested track is processed again when needed.
+const maxLoadedTracks = 32
+
+// trackCache keeps the most recently used processed tracks by ID, dropping the
+// least recently used one when it is full. It is not safe for concurrent use;
+// the server guards it with its mutex.
+type trackCache struct {
+	size    int                      // Most tracks kept
+	order   *list.List               // Track IDs, most recently used first
+	entries map[string]*list.Element // Elements of order by track ID
+	tracks  map[string]*loadedTrack  // Processed tracks by ID
+}
+
+// newTrackCache creates a trackCache keeping up to size tracks.
+func newTrackCache(size int) *trackCache {
+	return &trackCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		tracks:  make(map[string]*loadedTrack),
+	}
+}
+
+// get returns the track with the given ID and marks it as recently used.
+func (c *trackCache) get(id string) (*loadedTrack, bool) {
+	loaded, ok := c.tracks[id]
+	if ok {
+		c.order.MoveToFront(c.entries[id])
+	}
+	return loaded, ok
+}
+
+// put stores a track, replacing an earlier one with the same ID, and drops the
+// least recently used track if the cache is full.
+func (c *trackCache) put(id string, loaded *loadedTrack) {
+	if element, ok := c.entries[id]; ok {
+		c.order.MoveToFront(element)
+	} else {
+		c.entries[id] = c.order.PushFront(id)
+	}
+	c.tracks[id] = loaded
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back().Value.(string))
+	}
+}
+
+// remove drops the track with the given ID, if it is kept.
+func (c *trackCache) remove(id string) {
+	if element, ok := c.entries[id]; ok {
+		c.order.Remove(element)
+		delete(c.entries, id)
+		delete(c.tracks, id)
+	}
+}
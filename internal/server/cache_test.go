@@ -0,0 +1,35 @@
+package server
+
+import "testing"
+
+func TestTrackCache(t *testing.T) {
+	c := newTrackCache(2)
+	a, b, d := &loadedTrack{}, &loadedTrack{}, &loadedTrack{}
+	c.put("a", a)
+	c.put("b", b)
+
+	// Reading a keeps it, so adding a third track drops b
+	if got, ok := c.get("a"); !ok || got != a {
+		t.Fatalf("get(a) = %v, %v, want the stored track", got, ok)
+	}
+	c.put("d", d)
+	if _, ok := c.get("b"); ok {
+		t.Error("get(b) found the least recently used track after the cache filled")
+	}
+	for id, want := range map[string]*loadedTrack{"a": a, "d": d} {
+		if got, ok := c.get(id); !ok || got != want {
+			t.Errorf("get(%s) = %v, %v, want the stored track", id, got, ok)
+		}
+	}
+
+	// Replacing a track keeps one entry, and removed tracks are gone
+	c.put("a", b)
+	if got, _ := c.get("a"); got != b || c.order.Len() != 2 {
+		t.Errorf("put(a) again = %v with %d entries, want the new track with 2", got, c.order.Len())
+	}
+	c.remove("a")
+	c.remove("missing")
+	if _, ok := c.get("a"); ok || c.order.Len() != 1 {
+		t.Errorf("get(a) after remove = %v with %d entries, want none left but d", ok, c.order.Len())
+	}
+}
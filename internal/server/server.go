@@ -0,0 +1,316 @@
+// Package server serves generated maps over HTTP for previewing.
+//
+// @title Map Server Package
+// @version 1.0
+// @description Renders the map of a processed track in memory on each request
+// @description Serves the track data in the export formats next to it
+// @description Pushes newly ingested points to open maps as server-sent events
+//
+// Features:
+// - The interactive map at the root path, without writing output files
+// - GeoJSON, GPX, KML, and statistics endpoints for the same track
+// - Download buttons on the map linking to the data endpoints
+// - Live updates extending open maps as the track grows
+// - Track API storing uploaded CSV and GPX tracks with their maps and statistics
+// - API tokens giving users their own tracks, sharing, and user pages
+// - Prometheus metrics at /metrics
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/export"
+	"github.com/saratily/geo-chrono/internal/geojson"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/gpx"
+	"github.com/saratily/geo-chrono/internal/kml"
+	"github.com/saratily/geo-chrono/internal/mapgen"
+	"github.com/saratily/geo-chrono/internal/metrics"
+	"github.com/saratily/geo-chrono/internal/store"
+)
+
+// DefaultAddr is the address the server listens on when none is given.
+const DefaultAddr = ":8080"
+
+// eventsPath is the URL path of the server-sent event stream of new points.
+const eventsPath = "/events"
+
+// subscriberBuffer is how many updates a slow browser may fall behind before
+// further updates to it are dropped.
+const subscriberBuffer = 16
+
+// endpoint is a data endpoint serving the track in one format.
+type endpoint struct {
+	path      string                                   // URL path
+	label     string                                   // Download button text
+	mediaType string                                   // Content-Type of the response
+	write     func(job *export.Job, w io.Writer) error // Writes the response body
+}
+
+// endpoints lists the data endpoints in download button order.
+var endpoints = []endpoint{
+	{
+		path:      "/track.gpx",
+		label:     "Download GPX",
+		mediaType: "application/gpx+xml",
+		write:     func(job *export.Job, w io.Writer) error { return gpx.Write(w, job.Points, job.Config.Map.Title) },
+	},
+	{
+		path:      "/track.kml",
+		label:     "Download KML",
+		mediaType: "application/vnd.google-earth.kml+xml",
+		write:     func(job *export.Job, w io.Writer) error { return kml.Write(w, job.Points, job.Config) },
+	},
+	{
+		path:      "/track.geojson",
+		label:     "Download GeoJSON",
+		mediaType: "application/geo+json",
+		write:     func(job *export.Job, w io.Writer) error { return geojson.Write(w, job.Points, job.Config.Map.Title) },
+	},
+	{
+		path:      "/stats.json",
+		mediaType: "application/json",
+		write:     func(job *export.Job, w io.Writer) error { return job.Summary.WriteJSON(w) },
+	},
+}
+
+// Server serves the map and data of one processed track.
+//
+// @struct Server
+// @description HTTP handler rendering a track's map and data in memory
+// @property job *export.Job Processed track, statistics, and configuration
+// @property subscribers map[chan []byte]struct{} Event streams of the open maps
+type Server struct {
+	mu          sync.RWMutex             // Guards job, subscribers, and loaded
+	job         *export.Job              // @field job Processed track, statistics, and configuration
+	subscribers map[chan []byte]struct{} // @field subscribers Event streams of the open maps
+	mux         *http.ServeMux
+
+	// Track API, set by EnableAPI
+	tracks store.Store               // Uploaded tracks
+	load   Loader                    // Processes stored tracks
+	loaded *trackCache               // Recently processed stored tracks by ID
+	users  []config.ServerUserConfig // API accounts; empty for an open API
+}
+
+// New creates a Server for a processed track.
+//
+// @function New
+// @description Creates the HTTP handler of serve mode
+// @param job *export.Job Processed track with its statistics and configuration
+// @return *Server Server routing the map and data endpoints
+// @example http.ListenAndServe(":8080", server.New(job))
+func New(job *export.Job) *Server {
+	s := &Server{job: job, subscribers: make(map[chan []byte]struct{}), mux: http.NewServeMux()}
+	s.mux.HandleFunc("/", s.serveMap)
+	s.mux.HandleFunc(eventsPath, s.serveEvents)
+	s.mux.Handle("/metrics", metrics.Handler())
+	for _, e := range endpoints {
+		s.mux.HandleFunc(e.path, s.serveData(e))
+	}
+	return s
+}
+
+// Update replaces the served track, for example after the input file grew, and
+// pushes the points added at its end to every open map.
+//
+// @method Update
+// @description Swaps in a reprocessed track and notifies live maps
+// @param job *export.Job Reprocessed track with its statistics and configuration
+// @note Maps opened later render the whole new track; a track that shrank or
+// changed before its old end only shows on those
+func (s *Server) Update(job *export.Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := len(s.job.Points)
+	s.job = job
+	if len(job.Points) <= previous {
+		return
+	}
+	data, err := json.Marshal(livePoints(job.Points[previous:]))
+	if err != nil {
+		slog.Error("Cannot encode new points", "error", err)
+		return
+	}
+	for subscriber := range s.subscribers {
+		select {
+		case subscriber <- data:
+		default:
+			// Never let one stalled browser hold up the others
+		}
+	}
+}
+
+// ServeHTTP routes a request to the map or a data endpoint.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// serveMap renders the interactive map. Download buttons, when output.downloads is
+// set, link to the data endpoints instead of exported files.
+func (s *Server) serveMap(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if !allowMethod(w, r) {
+		return
+	}
+
+	job := s.current()
+	renderMap(w, job, downloads(job), "."+eventsPath)
+}
+
+// renderMap writes the map of a job with the given download buttons, listening
+// for live points at the live URL unless it is empty.
+func renderMap(w http.ResponseWriter, job *export.Job, downloads []mapgen.Download, live string) {
+	generator := mapgen.NewGenerator(job.Config)
+	generator.SetReference(job.Reference)
+	generator.SetDownloads(downloads)
+	generator.SetWeather(job.Weather)
+	generator.SetTiles(job.Tiles)
+	generator.SetLive(live)
+
+	// Render fully before responding, so a failed render is reported as an error page
+	var buf bytes.Buffer
+	start := time.Now()
+	err := generator.GenerateTo(&buf, job.Points)
+	metrics.GenerationSeconds.ObserveSince(start)
+	if err != nil {
+		slog.Error("Cannot render map", "error", err)
+		http.Error(w, fmt.Sprintf("cannot render map: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// serveData returns the handler of a data endpoint.
+func (s *Server) serveData(e endpoint) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !allowMethod(w, r) {
+			return
+		}
+		var buf bytes.Buffer
+		if err := e.write(s.current(), &buf); err != nil {
+			slog.Error("Cannot write export", "path", e.path, "error", err)
+			http.Error(w, fmt.Sprintf("cannot write %s: %v", e.path, err), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", e.mediaType)
+		w.Write(buf.Bytes())
+	}
+}
+
+// serveEvents streams the points added by each Update to one open map until the
+// browser disconnects.
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r) {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	updates := make(chan []byte, subscriberBuffer)
+	s.mu.Lock()
+	s.subscribers[updates] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subscribers, updates)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-updates:
+			if _, err := fmt.Fprintf(w, "event: points\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// current returns the served track.
+func (s *Server) current() *export.Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.job
+}
+
+// livePoint is a point pushed to open maps, in the form their addLivePoints
+// function reads.
+type livePoint struct {
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	Timestamp   string  `json:"timestamp"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Category    string  `json:"category,omitempty"`
+	Elevation   float64 `json:"elevation,omitempty"`
+	User        string  `json:"user,omitempty"`
+}
+
+// livePoints converts points for pushing, with timestamps written as on the page.
+func livePoints(points gps.Points) []livePoint {
+	live := make([]livePoint, len(points))
+	for i, point := range points {
+		live[i] = livePoint{
+			Lat:         point.Latitude,
+			Lng:         point.Longitude,
+			Timestamp:   point.Timestamp.Format("2006-01-02 15:04:05"),
+			Title:       point.Title,
+			Description: point.Description,
+			Category:    point.Category,
+			Elevation:   point.Elevation,
+			User:        point.User,
+		}
+	}
+	return live
+}
+
+// downloads returns the map's download buttons for the track data endpoints.
+func downloads(job *export.Job) []mapgen.Download {
+	if !job.Config.Output.Downloads {
+		return nil
+	}
+	var downloads []mapgen.Download
+	for _, e := range endpoints {
+		if e.label != "" {
+			downloads = append(downloads, mapgen.Download{Label: e.label, File: e.path[1:], URL: template.URL("." + e.path)})
+		}
+	}
+	return downloads
+}
+
+// allowMethod rejects requests other than GET and HEAD, reporting whether the
+// request may proceed.
+func allowMethod(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/export"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/stats"
+)
+
+func testJob(downloads bool) *export.Job {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.7749, Longitude: -122.4194},
+		{Timestamp: start.Add(10 * time.Minute), Latitude: 37.7849, Longitude: -122.4094},
+	}
+	cfg := &config.Config{
+		GoogleMaps: config.GoogleMapsConfig{APIKey: "test-key"},
+		Input:      config.InputConfig{CSVFormat: config.CSVFormatConfig{HasHeader: true}},
+		Map:        config.MapConfig{Title: "Served Trip"},
+		Output:     config.OutputConfig{Downloads: downloads},
+		Path:       config.PathConfig{Style: config.PathStyleConfig{Color: "#FF0000", Opacity: 1, Weight: 2}},
+	}
+	return &export.Job{Points: points, Summary: stats.Compute(points, &cfg.Statistics), Config: cfg}
+}
+
+func TestServer(t *testing.T) {
+	handler := New(testJob(false))
+
+	tests := []struct {
+		path        string
+		wantStatus  int
+		wantType    string
+		wantContent string
+	}{
+		{path: "/", wantStatus: http.StatusOK, wantType: "text/html; charset=utf-8", wantContent: "Served Trip"},
+		{path: "/track.gpx", wantStatus: http.StatusOK, wantType: "application/gpx+xml", wantContent: "<gpx"},
+		{path: "/track.kml", wantStatus: http.StatusOK, wantType: "application/vnd.google-earth.kml+xml", wantContent: "<kml"},
+		{path: "/track.geojson", wantStatus: http.StatusOK, wantType: "application/geo+json", wantContent: `"FeatureCollection"`},
+		{path: "/stats.json", wantStatus: http.StatusOK, wantType: "application/json", wantContent: `"points": 2`},
+		{path: "/metrics", wantStatus: http.StatusOK, wantType: "text/plain; version=0.0.4; charset=utf-8", wantContent: "geochrono_generation_duration_seconds_count"},
+		{path: "/missing.html", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("GET %s status = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+			}
+			if tt.wantType != "" && rec.Header().Get("Content-Type") != tt.wantType {
+				t.Errorf("GET %s Content-Type = %q, want %q", tt.path, rec.Header().Get("Content-Type"), tt.wantType)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantContent) {
+				t.Errorf("GET %s body missing %q", tt.path, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestServerMethods(t *testing.T) {
+	handler := New(testJob(false))
+	for _, path := range []string{"/", "/track.gpx", "/metrics"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+			t.Errorf("POST %s = %d, Allow %q, want 405", path, rec.Code, rec.Header().Get("Allow"))
+		}
+	}
+}
+
+func TestServerDownloads(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(testJob(true)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	html := rec.Body.String()
+	for _, want := range []string{`href="./track.gpx"`, `href="./track.kml"`, `href="./track.geojson"`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("map missing download link %q", want)
+		}
+	}
+	if strings.Contains(html, "stats.json") {
+		t.Error("map offers a download button for the statistics endpoint")
+	}
+}
+
+func TestServerEvents(t *testing.T) {
+	job := testJob(false)
+	handler := New(job)
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/events")
+	if err != nil {
+		t.Fatalf("GET /events error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET /events Content-Type = %q", resp.Header.Get("Content-Type"))
+	}
+
+	// The headers arrive once the stream is subscribed, so the update reaches it
+	grown := *job
+	grown.Points = append(append(gps.Points{}, job.Points...), gps.Point{
+		Timestamp: job.Points[1].Timestamp.Add(5 * time.Minute), Latitude: 37.79, Longitude: -122.40, Title: "Pier",
+	})
+	handler.Update(&grown)
+
+	reader := bufio.NewReader(resp.Body)
+	var event []string
+	for len(event) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading event error = %v", err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			event = append(event, line)
+		}
+	}
+	want := []string{"event: points", `data: [{"lat":37.79,"lng":-122.4,"timestamp":"2025-10-28 10:15:00","title":"Pier"}]`}
+	if event[0] != want[0] || event[1] != want[1] {
+		t.Errorf("event = %q, want %q", event, want)
+	}
+
+	// Maps opened after the update render the grown track
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !strings.Contains(rec.Body.String(), "Pier") || !strings.Contains(rec.Body.String(), `new EventSource("./events")`) {
+		t.Error("map does not render the updated track with live updates")
+	}
+}
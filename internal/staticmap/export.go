@@ -0,0 +1,136 @@
+package staticmap
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// Image providers selectable with output.image.provider.
+const (
+	ProviderLocal  = "local"  // Track drawn locally, no map background or network access
+	ProviderGoogle = "google" // Google Static Maps API with road map background
+)
+
+// Default image size in pixels when none is configured.
+const (
+	DefaultWidth  = 800
+	DefaultHeight = 600
+)
+
+// Supported image formats.
+const (
+	FormatPNG  = "png"
+	FormatJPEG = "jpeg"
+)
+
+// FormatForFile returns the image format implied by the file extension
+// (.png, .jpg, or .jpeg).
+func FormatForFile(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".png":
+		return FormatPNG, nil
+	case ".jpg", ".jpeg":
+		return FormatJPEG, nil
+	}
+	return "", fmt.Errorf("unsupported image file %s (use .png, .jpg, or .jpeg)", filename)
+}
+
+// Encode writes the image in the given format.
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatPNG:
+		return png.Encode(w, img)
+	case FormatJPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: 90})
+	}
+	return fmt.Errorf("unsupported image format %q", format)
+}
+
+// ParseColor converts a "#RRGGBB" color into an opaque RGBA color.
+// Returns ok=false for any other notation.
+func ParseColor(hex string) (c color.RGBA, ok bool) {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}, true
+}
+
+// WriteFile renders the GPS track as a static PNG or JPEG map image, chosen by the
+// file extension, for reports where an interactive HTML map cannot be used.
+//
+// @function WriteFile
+// @description Exports a static map image using the configured provider
+// @param ctx context.Context Cancels the provider request
+// @param filename string Path of the image to create (.png, .jpg, or .jpeg)
+// @param points gps.Points GPS points in path order
+// @param cfg *config.Config Configuration providing the image settings, path style, and API key
+// @return error Error if rendering, the provider request, or writing fails
+// @note The google provider sends the simplified track to the Static Maps API
+// @example err := staticmap.WriteFile(ctx, "route.png", points, cfg)
+func WriteFile(ctx context.Context, filename string, points gps.Points, cfg *config.Config) error {
+	format, err := FormatForFile(filename)
+	if err != nil {
+		return err
+	}
+
+	imageCfg := cfg.Output.Image
+	width, height := imageCfg.Width, imageCfg.Height
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	if height <= 0 {
+		height = DefaultHeight
+	}
+
+	var data []byte
+	switch imageCfg.Provider {
+	case "", ProviderLocal:
+		c, ok := ParseColor(cfg.Path.Style.Color)
+		if !ok {
+			c = trackColor
+		}
+		img, err := Render(points, width, height, c)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := Encode(&buf, img, format); err != nil {
+			return fmt.Errorf("cannot encode map image: %w", err)
+		}
+		data = buf.Bytes()
+	case ProviderGoogle:
+		renderer, err := NewGoogleRenderer(cfg.GoogleMaps.APIKey)
+		if err != nil {
+			return err
+		}
+		data, err = renderer.Render(ctx, points, width, height, format, &cfg.Path.Style)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown image provider %q (use local or google)", imageCfg.Provider)
+	}
+
+	if err := os.WriteFile(filename, data, 0o644); err != nil {
+		return fmt.Errorf("cannot write map image %s: %w", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package staticmap
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestFormatForFile(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string
+		wantErr  bool
+	}{
+		{filename: "route.png", want: FormatPNG},
+		{filename: "out/Route.JPG", want: FormatJPEG},
+		{filename: "route.jpeg", want: FormatJPEG},
+		{filename: "route.gif", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := FormatForFile(tt.filename)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("FormatForFile(%q) = %q, %v, want %q (error %v)", tt.filename, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	if got, ok := ParseColor("#FF8000"); !ok || got != (color.RGBA{R: 0xFF, G: 0x80, A: 0xFF}) {
+		t.Errorf("ParseColor(#FF8000) = %v, %v", got, ok)
+	}
+	for _, bad := range []string{"", "red", "#FFF", "#GG0000"} {
+		if _, ok := ParseColor(bad); ok {
+			t.Errorf("ParseColor(%q) ok = true, want false", bad)
+		}
+	}
+}
+
+func TestRenderSize(t *testing.T) {
+	points := gps.Points{{Latitude: 0, Longitude: 0}, {Latitude: 0, Longitude: 1}}
+	img, err := Render(points, 200, 100, trackColor)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Errorf("Render() size = %dx%d, want 200x100", b.Dx(), b.Dy())
+	}
+	if _, err := Render(points, 0, 100, trackColor); err == nil {
+		t.Error("Render() with zero width error = nil, want error")
+	}
+}
+
+func TestWriteFileLocal(t *testing.T) {
+	points := gps.Points{
+		{Latitude: 37.7749, Longitude: -122.4194},
+		{Latitude: 37.8044, Longitude: -122.2711},
+	}
+	cfg := &config.Config{}
+	cfg.Path.Style.Color = "#FF0000"
+	cfg.Output.Image = config.ImageConfig{Width: 120, Height: 80}
+
+	dir := t.TempDir()
+	for _, name := range []string{"map.png", "map.jpg"} {
+		filename := filepath.Join(dir, name)
+		if err := WriteFile(context.Background(), filename, points, cfg); err != nil {
+			t.Fatalf("WriteFile(%s) error = %v", name, err)
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decode := png.Decode
+		if name == "map.jpg" {
+			decode = jpeg.Decode
+		}
+		img, err := decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("WriteFile(%s) wrote an undecodable image: %v", name, err)
+		}
+		if b := img.Bounds(); b.Dx() != 120 || b.Dy() != 80 {
+			t.Errorf("WriteFile(%s) size = %dx%d, want 120x80", name, b.Dx(), b.Dy())
+		}
+	}
+
+	cfg.Output.Image.Provider = "bing"
+	if err := WriteFile(context.Background(), filepath.Join(dir, "map.png"), points, cfg); err == nil {
+		t.Error("WriteFile() with unknown provider error = nil, want error")
+	}
+}
@@ -0,0 +1,169 @@
+package staticmap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// GoogleStaticMapsURL is the Google Static Maps API endpoint.
+const GoogleStaticMapsURL = "https://maps.googleapis.com/maps/api/staticmap"
+
+// ErrAPI is wrapped by errors of requests to the Static Maps API, so callers can
+// tell a failed service from a file that cannot be written.
+var ErrAPI = errors.New("static maps API")
+
+// googleMaxPathLength keeps the encoded path well inside the API's 16,384 character
+// URL limit once escaped.
+const googleMaxPathLength = 8000
+
+// GoogleRenderer fetches static map images from the Google Static Maps API.
+//
+// @struct GoogleRenderer
+// @description Static map images with a Google road map background
+// @property APIKey string Google API key with the Maps Static API enabled
+// @property BaseURL string Endpoint URL (overridable for testing)
+// @property Client *http.Client HTTP client used for requests
+type GoogleRenderer struct {
+	APIKey  string       // @field APIKey Google API key with the Maps Static API enabled
+	BaseURL string       // @field BaseURL Static Maps endpoint URL
+	Client  *http.Client // @field Client HTTP client for API requests
+}
+
+// NewGoogleRenderer creates a GoogleRenderer for the API key.
+// Returns an error for an empty or demonstration key.
+func NewGoogleRenderer(apiKey string) (*GoogleRenderer, error) {
+	if apiKey == "" || apiKey == "DEMO" {
+		return nil, fmt.Errorf("google Static Maps API requires an API key")
+	}
+	return &GoogleRenderer{
+		APIKey:  apiKey,
+		BaseURL: GoogleStaticMapsURL,
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Render requests a map image showing the track with start and end markers.
+// Long tracks are simplified until the encoded path fits in the request URL.
+//
+// @method Render
+// @description Downloads a static map of the track from Google
+// @param ctx context.Context Cancels the request
+// @param points gps.Points GPS points in path order
+// @param width int Image width in pixels (the API caps this at 640 without a premium plan)
+// @param height int Image height in pixels
+// @param format string Image format (png or jpeg)
+// @param style *config.PathStyleConfig Path color, opacity, and weight
+// @return []byte Encoded image returned by the API
+// @return error Error if there are no points or the request fails
+// @example data, err := renderer.Render(ctx, points, 640, 480, staticmap.FormatPNG, &cfg.Path.Style)
+func (g *GoogleRenderer) Render(ctx context.Context, points gps.Points, width, height int, format string, style *config.PathStyleConfig) ([]byte, error) {
+	if points.IsEmpty() {
+		return nil, fmt.Errorf("cannot render map without GPS points")
+	}
+
+	path := encodePolyline(points)
+	for tolerance := 1.0; len(path) > googleMaxPathLength; tolerance *= 2 {
+		path = encodePolyline(points.Simplify(tolerance))
+	}
+
+	// Frame the padded track ourselves; the API's own fit leaves the path touching the edges
+	minLat, maxLat, minLng, maxLng := points.BoundsPadded(ImagePadding)
+	west, north := gps.LatLngToWorld(maxLat, minLng)
+	east, south := gps.LatLngToWorld(minLat, maxLng)
+	centerLat, centerLng := gps.WorldToLatLng((west+east)/2, (north+south)/2)
+
+	query := url.Values{}
+	query.Set("size", fmt.Sprintf("%dx%d", width, height))
+	query.Set("center", fmt.Sprintf("%.6f,%.6f", centerLat, centerLng))
+	query.Set("zoom", strconv.Itoa(gps.ZoomForBounds(minLat, maxLat, minLng, maxLng, width, height)))
+	query.Set("format", map[string]string{FormatPNG: "png", FormatJPEG: "jpg"}[format])
+	query.Set("path", pathStyle(style)+"|enc:"+path)
+	first, last := points[0], points[len(points)-1]
+	query.Add("markers", fmt.Sprintf("color:green|label:S|%.6f,%.6f", first.Latitude, first.Longitude))
+	query.Add("markers", fmt.Sprintf("color:red|label:E|%.6f,%.6f", last.Latitude, last.Longitude))
+	query.Set("key", g.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.BaseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w request failed: %w", ErrAPI, err)
+	}
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w request failed: %w", ErrAPI, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %w response: %w", ErrAPI, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w error: %s: %s", ErrAPI, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
+
+// pathStyle formats the path style as Static Maps path parameters, with the color as
+// 0xRRGGBBAA.
+func pathStyle(style *config.PathStyleConfig) string {
+	c, ok := ParseColor(style.Color)
+	if !ok {
+		c = trackColor
+	}
+	alpha := 0xFF
+	if style.Opacity > 0 && style.Opacity < 1 {
+		alpha = int(style.Opacity*255 + 0.5)
+	}
+	weight := style.Weight
+	if weight <= 0 {
+		weight = 3
+	}
+	return fmt.Sprintf("color:0x%02X%02X%02X%02X|weight:%d", c.R, c.G, c.B, alpha, weight)
+}
+
+// encodePolyline encodes the points with Google's encoded polyline algorithm at five
+// decimal places of precision.
+func encodePolyline(points gps.Points) string {
+	var sb strings.Builder
+	var prevLat, prevLng int64
+	for _, p := range points {
+		lat := round5(p.Latitude)
+		lng := round5(p.Longitude)
+		encodeValue(&sb, lat-prevLat)
+		encodeValue(&sb, lng-prevLng)
+		prevLat, prevLng = lat, lng
+	}
+	return sb.String()
+}
+
+// round5 scales a coordinate to an integer number of 1e-5 degrees, rounding half away
+// from zero as the reference implementation does.
+func round5(v float64) int64 {
+	if v < 0 {
+		return -int64(-v*1e5 + 0.5)
+	}
+	return int64(v*1e5 + 0.5)
+}
+
+// encodeValue appends one signed delta as five-bit chunks.
+func encodeValue(sb *strings.Builder, v int64) {
+	u := v << 1
+	if v < 0 {
+		u = ^u
+	}
+	for u >= 0x20 {
+		sb.WriteByte(byte((0x20 | (u & 0x1F)) + 63))
+		u >>= 5
+	}
+	sb.WriteByte(byte(u + 63))
+}
@@ -0,0 +1,74 @@
+package staticmap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestEncodePolyline(t *testing.T) {
+	// Reference example from the encoded polyline algorithm documentation
+	points := gps.Points{
+		{Latitude: 38.5, Longitude: -120.2},
+		{Latitude: 40.7, Longitude: -120.95},
+		{Latitude: 43.252, Longitude: -126.453},
+	}
+	if got, want := encodePolyline(points), "_p~iF~ps|U_ulLnnqC_mqNvxq`@"; got != want {
+		t.Errorf("encodePolyline() = %q, want %q", got, want)
+	}
+}
+
+func TestGoogleRendererRender(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("key") != "test-key" || q.Get("size") != "640x480" || q.Get("format") != "jpg" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		if q.Get("zoom") != "8" || !strings.HasPrefix(q.Get("center"), "2.00") || !strings.HasSuffix(q.Get("center"), ",3.000000") {
+			t.Errorf("center = %q, zoom = %q, want the padded track framed at zoom 8", q.Get("center"), q.Get("zoom"))
+		}
+		if !strings.HasPrefix(q.Get("path"), "color:0xFF0000CC|weight:4|enc:") {
+			t.Errorf("path = %q, want style then encoded polyline", q.Get("path"))
+		}
+		if markers := q["markers"]; len(markers) != 2 || !strings.Contains(markers[0], "label:S") {
+			t.Errorf("markers = %v, want start and end", markers)
+		}
+		fmt.Fprint(w, "image-bytes")
+	}))
+	defer server.Close()
+
+	renderer := &GoogleRenderer{APIKey: "test-key", BaseURL: server.URL, Client: server.Client()}
+	style := &config.PathStyleConfig{Color: "#FF0000", Opacity: 0.8, Weight: 4}
+	data, err := renderer.Render(context.Background(), gps.Points{{Latitude: 1, Longitude: 2}, {Latitude: 3, Longitude: 4}}, 640, 480, FormatJPEG, style)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if string(data) != "image-bytes" {
+		t.Errorf("Render() = %q, want the response body", data)
+	}
+}
+
+func TestGoogleRendererErrors(t *testing.T) {
+	if _, err := NewGoogleRenderer("DEMO"); err == nil {
+		t.Error("NewGoogleRenderer(DEMO) error = nil, want error")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "The provided API key is invalid.")
+	}))
+	defer server.Close()
+
+	renderer := &GoogleRenderer{APIKey: "bad", BaseURL: server.URL, Client: server.Client()}
+	_, err := renderer.Render(context.Background(), gps.Points{{Latitude: 1, Longitude: 1}}, 100, 100, FormatPNG, &config.PathStyleConfig{})
+	if err == nil || !strings.Contains(err.Error(), "API key is invalid") || !errors.Is(err, ErrAPI) {
+		t.Errorf("Render() error = %v, want API error message", err)
+	}
+}
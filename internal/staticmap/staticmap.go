@@ -0,0 +1,174 @@
+// Package staticmap provides server-side rendering of GPS tracks into raster images.
+//
+// @title Static Map Rendering Package
+// @version 1.0
+// @description Draws GPS tracks into PNG and JPEG images, locally or via Google Static Maps
+// @description Shared by every thumbnail consumer so previews look the same everywhere
+//
+// Features:
+// - Web Mercator projection (shared gps helpers) fitted to the track extent
+// - Track line with start and end markers
+// - PNG encoding and data URI embedding
+// - Static map image export for reports (PNG or JPEG)
+// - Google Static Maps API backgrounds with encoded polylines
+package staticmap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// DefaultThumbnailSize is the edge length in pixels used for track thumbnails.
+const DefaultThumbnailSize = 128
+
+// Colors used when drawing thumbnails, matching the map legend.
+var (
+	backgroundColor = color.RGBA{R: 0xF5, G: 0xF5, B: 0xF5, A: 0xFF}
+	trackColor      = color.RGBA{R: 0x1E, G: 0x88, B: 0xE5, A: 0xFF}
+	startColor      = color.RGBA{G: 0xCC, A: 0xFF}
+	endColor        = color.RGBA{R: 0xFF, A: 0xFF}
+)
+
+// Thumbnail renders the GPS track as a square PNG image.
+//
+// @function Thumbnail
+// @description Draws the track line and start/end markers scaled to fit the image
+// @param points gps.Points GPS points in path order
+// @param size int Width and height of the image in pixels
+// @return []byte PNG-encoded image
+// @return error Error if the size is not positive, there are no points, or encoding fails
+// @example png, err := staticmap.Thumbnail(points, staticmap.DefaultThumbnailSize)
+func Thumbnail(points gps.Points, size int) ([]byte, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid thumbnail size %d", size)
+	}
+
+	img, err := Render(points, size, size, trackColor)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("cannot encode thumbnail: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Render draws the GPS track into an image of the given size: the track line in the
+// given color with green start and red end markers on a light background. Line and
+// marker sizes scale with the shorter image edge.
+//
+// @function Render
+// @description Draws a track image without any map provider or network access
+// @param points gps.Points GPS points in path order
+// @param width int Image width in pixels
+// @param height int Image height in pixels
+// @param c color.RGBA Track line color
+// @return *image.RGBA Rendered image
+// @return error Error if the size is not positive or there are no points
+// @example img, err := staticmap.Render(points, 800, 600, color.RGBA{B: 0xFF, A: 0xFF})
+func Render(points gps.Points, width, height int, c color.RGBA) (*image.RGBA, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid image size %dx%d", width, height)
+	}
+	if points.IsEmpty() {
+		return nil, errors.New("cannot render map without GPS points")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: backgroundColor}, image.Point{}, draw.Src)
+
+	edge := float64(min(width, height))
+	pixels := fitToImage(points, width, height)
+	lineWidth := math.Max(1, edge/64)
+	for i := 1; i < len(pixels); i++ {
+		drawLine(img, pixels[i-1], pixels[i], lineWidth, c)
+	}
+
+	marker := math.Max(2, edge/32)
+	fillCircle(img, pixels[0], marker, startColor)
+	fillCircle(img, pixels[len(pixels)-1], marker, endColor)
+	return img, nil
+}
+
+// DataURI encodes PNG bytes as a data URI suitable for an img src attribute.
+func DataURI(pngData []byte) string {
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngData)
+}
+
+// pixel is a position in image coordinates.
+type pixel struct {
+	X, Y float64
+}
+
+// ImagePadding is the fraction of the track extent added on every side of static
+// images, leaving a 10% margin around the track along its longer axis.
+const ImagePadding = 0.125
+
+// fitToImage projects the points with Web Mercator and scales them uniformly so the
+// track's padded bounds fill the image, centered along the axis with room to spare.
+func fitToImage(points gps.Points, width, height int) []pixel {
+	minLat, maxLat, minLng, maxLng := points.BoundsPadded(ImagePadding)
+	minX, minY := gps.LatLngToWorld(maxLat, minLng)
+	maxX, maxY := gps.LatLngToWorld(minLat, maxLng)
+
+	scale := math.Inf(1)
+	if extent := maxX - minX; extent > 0 {
+		scale = float64(width) / extent
+	}
+	if extent := maxY - minY; extent > 0 {
+		scale = math.Min(scale, float64(height)/extent)
+	}
+	if math.IsInf(scale, 1) {
+		scale = 0
+	}
+
+	// Center the scaled bounds; single-point tracks land in the middle of the image
+	offsetX := (float64(width) - (maxX-minX)*scale) / 2
+	offsetY := (float64(height) - (maxY-minY)*scale) / 2
+	projected := make([]pixel, len(points))
+	for i, p := range points {
+		x, y := gps.LatLngToWorld(p.Latitude, p.Longitude)
+		projected[i] = pixel{
+			X: offsetX + (x-minX)*scale,
+			Y: offsetY + (y-minY)*scale,
+		}
+	}
+	return projected
+}
+
+// drawLine strokes a line between two pixels by stamping discs every half pixel.
+func drawLine(img *image.RGBA, from, to pixel, width float64, c color.RGBA) {
+	steps := int(math.Ceil(math.Hypot(to.X-from.X, to.Y-from.Y)*2)) + 1
+	for s := 0; s <= steps; s++ {
+		t := float64(s) / float64(steps)
+		fillCircle(img, pixel{X: from.X + (to.X-from.X)*t, Y: from.Y + (to.Y-from.Y)*t}, width/2, c)
+	}
+}
+
+// fillCircle paints a solid disc centered on the pixel, clipped to the image bounds.
+func fillCircle(img *image.RGBA, center pixel, radius float64, c color.RGBA) {
+	bounds := img.Bounds()
+	minX := int(math.Floor(center.X - radius))
+	maxX := int(math.Ceil(center.X + radius))
+	minY := int(math.Floor(center.Y - radius))
+	maxY := int(math.Ceil(center.Y + radius))
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			dx, dy := float64(x)+0.5-center.X, float64(y)+0.5-center.Y
+			if dx*dx+dy*dy <= radius*radius+0.25 && image.Pt(x, y).In(bounds) {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Package staticmap_test provides unit tests for static track rendering.
+// It tests thumbnail encoding, projection and fitting, and data URI embedding.
+package staticmap
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestThumbnail(t *testing.T) {
+	points := gps.Points{
+		{Latitude: 37.7749, Longitude: -122.4194},
+		{Latitude: 37.7849, Longitude: -122.4094},
+		{Latitude: 37.7949, Longitude: -122.4194},
+	}
+
+	data, err := Thumbnail(points, 64)
+	if err != nil {
+		t.Fatalf("Thumbnail() error = %v", err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Thumbnail() produced invalid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 64 || b.Dy() != 64 {
+		t.Errorf("Thumbnail() size = %dx%d, want 64x64", b.Dx(), b.Dy())
+	}
+
+	// The track passes through the image, so not every pixel is background
+	background := img.At(0, 0)
+	drawn := 0
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			if img.At(x, y) != background {
+				drawn++
+			}
+		}
+	}
+	if drawn == 0 {
+		t.Error("Thumbnail() drew nothing")
+	}
+
+	// Rendering is deterministic so thumbnails can be cached and compared
+	again, _ := Thumbnail(points, 64)
+	if !bytes.Equal(data, again) {
+		t.Error("Thumbnail() output differs between identical calls")
+	}
+}
+
+func TestThumbnailErrors(t *testing.T) {
+	if _, err := Thumbnail(gps.Points{}, 64); err == nil {
+		t.Error("Thumbnail() with no points error = nil, want error")
+	}
+	if _, err := Thumbnail(gps.Points{{Latitude: 1, Longitude: 1}}, 0); err == nil {
+		t.Error("Thumbnail() with zero size error = nil, want error")
+	}
+	if _, err := Thumbnail(gps.Points{{Latitude: 1, Longitude: 1}}, 16); err != nil {
+		t.Errorf("Thumbnail() with single point error = %v", err)
+	}
+}
+
+func TestFitToImage(t *testing.T) {
+	points := gps.Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 1},
+		{Latitude: 0.5, Longitude: 1},
+	}
+
+	pixels := fitToImage(points, 100, 100)
+
+	// The wider east-west extent spans the usable area between the 10% margins
+	if math.Abs(pixels[0].X-10) > 1e-9 || math.Abs(pixels[1].X-90) > 1e-9 {
+		t.Errorf("fitToImage() x range = %v..%v, want 10..90", pixels[0].X, pixels[1].X)
+	}
+	// North is up, so the northernmost point has the smallest y
+	if pixels[2].Y >= pixels[1].Y {
+		t.Errorf("fitToImage() y for north point = %v, want less than %v", pixels[2].Y, pixels[1].Y)
+	}
+
+	single := fitToImage(gps.Points{{Latitude: 10, Longitude: 10}}, 100, 100)
+	if single[0].X != 50 || single[0].Y != 50 {
+		t.Errorf("fitToImage() single point = %+v, want center", single[0])
+	}
+}
+
+func TestDataURI(t *testing.T) {
+	got := DataURI([]byte("png"))
+	if !strings.HasPrefix(got, "data:image/png;base64,") || !strings.HasSuffix(got, "cG5n") {
+		t.Errorf("DataURI() = %q", got)
+	}
+}
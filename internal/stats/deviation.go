@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// DefaultOffRouteThreshold is the distance in meters beyond which a point counts as
+// off-route when no threshold is configured.
+const DefaultOffRouteThreshold = 50.0
+
+// Deviation describes how far a recorded track strayed from a reference route.
+//
+// @struct Deviation
+// @description Off-route distance statistics comparing a track with a planned route
+// @property MaxDistance float64 Largest distance from the route in meters
+// @property AvgDistance float64 Mean distance from the route in meters
+// @property MaxPoint gps.Point Track point farthest from the route
+// @property Threshold float64 Distance in meters beyond which a point is off-route
+// @property OffRoutePoints int Number of track points beyond the threshold
+// @property OffRouteFraction float64 Share of track points beyond the threshold (0-1)
+type Deviation struct {
+	MaxDistance      float64   // @field MaxDistance Largest distance from the route in meters
+	AvgDistance      float64   // @field AvgDistance Mean distance from the route in meters
+	MaxPoint         gps.Point // @field MaxPoint Track point farthest from the route
+	Threshold        float64   // @field Threshold Off-route distance threshold in meters
+	OffRoutePoints   int       // @field OffRoutePoints Number of track points beyond the threshold
+	OffRouteFraction float64   // @field OffRouteFraction Share of track points beyond the threshold
+}
+
+// CompareTracks measures how far each point of a recorded track lies from a reference
+// route, such as a planned route loaded from GPX.
+//
+// @function CompareTracks
+// @description Computes off-route distance statistics for a track against a reference
+// @param actual gps.Points Recorded track points
+// @param reference gps.Points Planned route, treated as a polyline in slice order
+// @param threshold float64 Off-route distance in meters (DefaultOffRouteThreshold if not positive)
+// @return *Deviation Deviation statistics, or nil when either track is empty
+// @logic Each point's deviation is its distance to the nearest segment of the reference,
+// @logic so the result does not depend on the two tracks sharing timestamps or spacing
+// @example deviation := CompareTracks(points, planned, 25)
+func CompareTracks(actual, reference gps.Points, threshold float64) *Deviation {
+	if len(actual) == 0 || len(reference) == 0 {
+		return nil
+	}
+	if threshold <= 0 {
+		threshold = DefaultOffRouteThreshold
+	}
+
+	deviation := &Deviation{Threshold: threshold}
+	var total float64
+	for i, point := range actual {
+		distance, _ := reference.DistanceToPath(point)
+		total += distance
+		if i == 0 || distance > deviation.MaxDistance {
+			deviation.MaxDistance = distance
+			deviation.MaxPoint = point
+		}
+		if distance > threshold {
+			deviation.OffRoutePoints++
+		}
+	}
+
+	deviation.AvgDistance = total / float64(len(actual))
+	deviation.OffRouteFraction = float64(deviation.OffRoutePoints) / float64(len(actual))
+	return deviation
+}
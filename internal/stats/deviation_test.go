@@ -0,0 +1,47 @@
+package stats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestCompareTracks(t *testing.T) {
+	planned := gps.Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0, Longitude: 0.01},
+	}
+	// 0.0001 degrees of latitude is ~11.1 meters
+	actual := gps.Points{
+		{Latitude: 0, Longitude: 0},
+		{Latitude: 0.0001, Longitude: 0.003},
+		{Latitude: 0.001, Longitude: 0.006},
+		{Latitude: 0, Longitude: 0.01},
+	}
+
+	got := CompareTracks(actual, planned, 0)
+	if got == nil {
+		t.Fatal("CompareTracks() = nil, want deviation")
+	}
+	if math.Abs(got.MaxDistance-111.2) > 0.5 {
+		t.Errorf("CompareTracks() MaxDistance = %v, want ~111.2", got.MaxDistance)
+	}
+	if got.MaxPoint.Latitude != 0.001 {
+		t.Errorf("CompareTracks() MaxPoint = %+v, want the third point", got.MaxPoint)
+	}
+	if math.Abs(got.AvgDistance-(111.2+11.1)/4) > 0.5 {
+		t.Errorf("CompareTracks() AvgDistance = %v, want ~%v", got.AvgDistance, (111.2+11.1)/4)
+	}
+	if got.Threshold != DefaultOffRouteThreshold || got.OffRoutePoints != 1 || got.OffRouteFraction != 0.25 {
+		t.Errorf("CompareTracks() off-route = %d (%v) at %v m, want 1 (0.25) at %v m",
+			got.OffRoutePoints, got.OffRouteFraction, got.Threshold, DefaultOffRouteThreshold)
+	}
+
+	if got := CompareTracks(actual, planned, 5); got.OffRoutePoints != 2 {
+		t.Errorf("CompareTracks() with 5 m threshold OffRoutePoints = %d, want 2", got.OffRoutePoints)
+	}
+	if got := CompareTracks(actual, nil, 0); got != nil {
+		t.Errorf("CompareTracks() without reference = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,467 @@
+// Package stats provides route statistics calculation for GPS tracks.
+//
+// @title Route Statistics Package
+// @version 1.0
+// @description Computes summary statistics for chronologically ordered GPS tracks
+// @description Separates moving and stopped time using a configurable speed threshold
+//
+// Features:
+// - Total distance and duration
+// - Moving time vs stopped time analysis
+// - Moving average, overall average, and maximum speed
+// - Pace and per-kilometer (or per-mile) split times
+// - Initial and average bearing with compass labels
+// - Time range, bounding box, and per-user breakdown
+// - JSON export
+// - Human-readable formatting helpers
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// DefaultStoppedSpeedThreshold is the speed (km/h) below which a segment counts as
+// stopped time when no threshold is configured.
+const DefaultStoppedSpeedThreshold = 1.0
+
+// DefaultLoopThreshold is the maximum start-to-end distance in meters for a track to
+// count as a loop when no threshold is configured.
+const DefaultLoopThreshold = 100.0
+
+// Distance unit lengths in meters used for splits and pace calculations.
+const (
+	MetersPerKilometer = 1000.0
+	MetersPerMile      = 1609.344
+)
+
+// Summary holds the computed statistics for a GPS track.
+//
+// @struct Summary
+// @description Aggregate route statistics for a chronologically sorted track
+// @property Points int Number of GPS points in the track
+// @property Distance float64 Total distance traveled in meters
+// @property Duration time.Duration Elapsed time from first to last point
+// @property MovingTime time.Duration Time spent on segments at or above the stopped threshold
+// @property StoppedTime time.Duration Time spent on segments below the stopped threshold
+// @property MovingAvgSpeed float64 Average speed while moving in km/h
+// @property AvgSpeed float64 Average speed over the whole duration in km/h
+// @property MaxSpeed float64 Fastest segment speed in km/h
+// @property Pace time.Duration Moving time per distance unit
+// @property Splits []Split Per-kilometer or per-mile split times
+// @property InitialBearing float64 Heading of the first moving segment in degrees
+// @property AverageBearing float64 Distance-weighted mean heading in degrees
+// @property HasBearing bool Whether the track moved at all, making bearings meaningful
+// @property Deviation *Deviation Off-route statistics against a reference route (nil without one)
+// @property Loop bool Whether the track returns to its starting point
+// @property LoopDirection string Traversal direction of a loop (gps.Clockwise, gps.Counterclockwise, or empty)
+// @property Start time.Time Earliest timestamp in the track
+// @property End time.Time Latest timestamp in the track
+// @property Bounds Bounds Bounding box of all points
+// @property Users []UserSummary Statistics per user for tracks shared by several users
+type Summary struct {
+	Points         int           // @field Points Number of GPS points in the track
+	Distance       float64       // @field Distance Total distance traveled in meters
+	Duration       time.Duration // @field Duration Elapsed time from first to last point
+	MovingTime     time.Duration // @field MovingTime Time spent moving
+	StoppedTime    time.Duration // @field StoppedTime Time spent stopped
+	MovingAvgSpeed float64       // @field MovingAvgSpeed Average speed while moving (km/h)
+	AvgSpeed       float64       // @field AvgSpeed Average speed over the whole duration (km/h)
+	MaxSpeed       float64       // @field MaxSpeed Fastest segment speed (km/h)
+	Pace           time.Duration // @field Pace Moving time per distance unit
+	Splits         []Split       // @field Splits Per-unit split times
+	InitialBearing float64       // @field InitialBearing Heading of the first moving segment (degrees)
+	AverageBearing float64       // @field AverageBearing Distance-weighted mean heading (degrees)
+	HasBearing     bool          // @field HasBearing Whether bearings are defined for this track
+	Deviation      *Deviation    // @field Deviation Off-route statistics against a reference route (nil without one)
+	Loop           bool          // @field Loop Whether the track returns to its starting point
+	LoopDirection  string        // @field LoopDirection Clockwise or counterclockwise traversal of a loop
+	Start          time.Time     // @field Start Earliest timestamp in the track
+	End            time.Time     // @field End Latest timestamp in the track
+	Bounds         Bounds        // @field Bounds Bounding box of all points
+	Users          []UserSummary // @field Users Per-user statistics (nil unless several users share the track)
+}
+
+// Bounds is the geographic bounding box of a track in decimal degrees.
+type Bounds struct {
+	North float64 // Northern latitude limit
+	South float64 // Southern latitude limit
+	East  float64 // Eastern longitude limit
+	West  float64 // Western longitude limit
+}
+
+// UserSummary holds the statistics of one user's points in a shared track.
+type UserSummary struct {
+	User    string   // User name from the input data
+	Summary *Summary // Statistics computed from that user's points only
+}
+
+// Split holds timing for one kilometer (or mile) of the track.
+//
+// @struct Split
+// @description Elapsed time for a single distance unit along the track
+// @property Number int One-based split number
+// @property Distance float64 Distance covered in meters (shorter for the final partial split)
+// @property Duration time.Duration Elapsed time for this split
+// @property Pace time.Duration Time per full distance unit at this split's speed
+type Split struct {
+	Number   int           // @field Number One-based split number
+	Distance float64       // @field Distance Distance covered in meters
+	Duration time.Duration // @field Duration Elapsed time for this split
+	Pace     time.Duration // @field Pace Time per full distance unit
+}
+
+// Compute calculates summary statistics for the provided GPS points.
+//
+// @function Compute
+// @description Calculates distance, duration, and moving/stopped time for a track
+// @param points gps.Points GPS points sorted in chronological order
+// @param cfg *config.StatisticsConfig Statistics settings (stopped speed threshold, units)
+// @return *Summary Computed route statistics
+// @logic Each segment slower than the threshold is counted as stopped time
+// @logic Split boundaries are interpolated linearly within a segment
+// @logic Distances use the configured method (Haversine by default, or Vincenty)
+// @example summary := stats.Compute(points, &cfg.Statistics)
+func Compute(points gps.Points, cfg *config.StatisticsConfig) *Summary {
+	threshold := DefaultStoppedSpeedThreshold
+	if cfg != nil && cfg.StoppedSpeedThreshold > 0 {
+		threshold = cfg.StoppedSpeedThreshold
+	}
+
+	distanceFn := gps.DistanceFunc(gps.Point.DistanceTo)
+	if cfg != nil {
+		distanceFn, _ = gps.DistanceFuncFor(cfg.DistanceMethod)
+	}
+
+	summary := &Summary{
+		Points:   len(points),
+		Duration: points.Duration(),
+	}
+	if len(points) > 0 {
+		summary.Start, summary.End = points.TimeRange()
+		minLat, maxLat, minLng, maxLng := points.Bounds()
+		summary.Bounds = Bounds{North: maxLat, South: minLat, East: maxLng, West: minLng}
+	}
+
+	// Classify every segment as moving or stopped based on its average speed
+	var movingDistance float64
+	for i := 1; i < len(points); i++ {
+		prev, curr := points[i-1], points[i]
+		distance := distanceFn(prev, curr)
+		elapsed := curr.Timestamp.Sub(prev.Timestamp)
+		summary.Distance += distance
+
+		// The initial bearing comes from the first segment that actually moves
+		if distance > 0 && !summary.HasBearing {
+			summary.InitialBearing = prev.BearingTo(curr)
+			summary.HasBearing = true
+		}
+
+		if elapsed <= 0 {
+			continue
+		}
+
+		speed := distance / elapsed.Seconds() * 3.6
+		if speed > summary.MaxSpeed {
+			summary.MaxSpeed = speed
+		}
+
+		if speed < threshold {
+			summary.StoppedTime += elapsed
+		} else {
+			summary.MovingTime += elapsed
+			movingDistance += distance
+		}
+	}
+
+	if summary.MovingTime > 0 {
+		summary.MovingAvgSpeed = movingDistance / summary.MovingTime.Seconds() * 3.6
+	}
+	if summary.HasBearing {
+		summary.AverageBearing, _ = points.AverageBearing()
+	}
+	if summary.Duration > 0 {
+		summary.AvgSpeed = summary.Distance / summary.Duration.Seconds() * 3.6
+	}
+
+	// Pace and splits are expressed per kilometer or per mile
+	unit := MetersPerKilometer
+	if cfg != nil && cfg.DistanceUnits == "imperial" {
+		unit = MetersPerMile
+	}
+	if movingDistance > 0 {
+		summary.Pace = paceFor(movingDistance, summary.MovingTime, unit)
+	}
+	summary.Splits = computeSplits(points, unit, distanceFn)
+
+	// Classify round trips and the direction they were run in
+	loopThreshold := DefaultLoopThreshold
+	if cfg != nil && cfg.LoopThreshold > 0 {
+		loopThreshold = cfg.LoopThreshold
+	}
+	if points.IsLoop(loopThreshold) {
+		summary.Loop = true
+		summary.LoopDirection = points.Direction()
+	}
+
+	// Shared tracks interleave several users, so each user is also summarized alone
+	if users := points.Users(); len(users) > 1 {
+		tracks := points.ByUser()
+		for _, user := range users {
+			summary.Users = append(summary.Users, UserSummary{User: user, Summary: Compute(tracks[user], cfg)})
+		}
+	}
+
+	return summary
+}
+
+// computeSplits divides the track into consecutive segments of one distance unit,
+// interpolating the crossing time within the GPS segment that spans each boundary.
+// A final partial split is included when the track does not end on a boundary.
+func computeSplits(points gps.Points, unit float64, distanceFn gps.DistanceFunc) []Split {
+	if len(points) < 2 {
+		return nil
+	}
+
+	var splits []Split
+	splitStart := points[0].Timestamp
+	var covered float64 // distance covered within the current split
+
+	for i := 1; i < len(points); i++ {
+		prev, curr := points[i-1], points[i]
+		segment := distanceFn(prev, curr)
+		elapsed := curr.Timestamp.Sub(prev.Timestamp)
+		consumed := 0.0
+
+		// Emit a split for every unit boundary crossed within this segment
+		for covered+(segment-consumed) >= unit {
+			consumed += unit - covered
+			crossing := prev.Timestamp.Add(time.Duration(float64(elapsed) * consumed / segment))
+			splits = append(splits, newSplit(len(splits)+1, unit, crossing.Sub(splitStart), unit))
+			splitStart = crossing
+			covered = 0
+		}
+		covered += segment - consumed
+	}
+
+	// Record the remaining partial split, ignoring GPS jitter below one meter
+	if covered >= 1 {
+		end := points[len(points)-1].Timestamp
+		splits = append(splits, newSplit(len(splits)+1, covered, end.Sub(splitStart), unit))
+	}
+
+	return splits
+}
+
+// newSplit builds a Split and derives its pace per full distance unit.
+func newSplit(number int, distance float64, duration time.Duration, unit float64) Split {
+	return Split{
+		Number:   number,
+		Distance: distance,
+		Duration: duration,
+		Pace:     paceFor(distance, duration, unit),
+	}
+}
+
+// paceFor returns the time needed to cover one distance unit at the given rate.
+func paceFor(distance float64, duration time.Duration, unit float64) time.Duration {
+	if distance <= 0 {
+		return 0
+	}
+	return time.Duration(float64(duration) * unit / distance)
+}
+
+// summaryJSON is the machine-readable representation of a Summary.
+// Durations are expressed in seconds and distances in meters.
+type summaryJSON struct {
+	Points             int            `json:"points"`
+	DistanceMeters     float64        `json:"distance_meters"`
+	DurationSeconds    float64        `json:"duration_seconds"`
+	MovingTimeSeconds  float64        `json:"moving_time_seconds"`
+	StoppedTimeSeconds float64        `json:"stopped_time_seconds"`
+	MovingAvgSpeedKmh  float64        `json:"moving_avg_speed_kmh"`
+	AvgSpeedKmh        float64        `json:"avg_speed_kmh"`
+	MaxSpeedKmh        float64        `json:"max_speed_kmh"`
+	PaceSeconds        float64        `json:"pace_seconds"`
+	InitialBearing     *float64       `json:"initial_bearing_degrees"`
+	AverageBearing     *float64       `json:"average_bearing_degrees"`
+	Splits             []splitJSON    `json:"splits"`
+	Deviation          *deviationJSON `json:"deviation,omitempty"`
+	Loop               bool           `json:"loop"`
+	LoopDirection      string         `json:"loop_direction,omitempty"`
+	StartTime          *time.Time     `json:"start_time,omitempty"`
+	EndTime            *time.Time     `json:"end_time,omitempty"`
+	Bounds             *boundsJSON    `json:"bounds,omitempty"`
+	Users              []userJSON     `json:"users,omitempty"`
+}
+
+// boundsJSON is the machine-readable representation of Bounds.
+type boundsJSON struct {
+	North float64 `json:"north"`
+	South float64 `json:"south"`
+	East  float64 `json:"east"`
+	West  float64 `json:"west"`
+}
+
+// userJSON is one entry of the per-user breakdown: the user's name followed by
+// the same fields as the overall summary.
+type userJSON struct {
+	User string `json:"user"`
+	summaryJSON
+}
+
+// deviationJSON is the machine-readable representation of a Deviation.
+type deviationJSON struct {
+	MaxDistanceMeters float64 `json:"max_distance_meters"`
+	AvgDistanceMeters float64 `json:"avg_distance_meters"`
+	ThresholdMeters   float64 `json:"off_route_threshold_meters"`
+	OffRoutePoints    int     `json:"off_route_points"`
+	OffRouteFraction  float64 `json:"off_route_fraction"`
+}
+
+// splitJSON is the machine-readable representation of a Split.
+type splitJSON struct {
+	Number          int     `json:"number"`
+	DistanceMeters  float64 `json:"distance_meters"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	PaceSeconds     float64 `json:"pace_seconds"`
+}
+
+// MarshalJSON encodes the summary with durations in seconds and distances in meters,
+// so the output is easy to consume from dashboards and scripts.
+func (s *Summary) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.toJSON())
+}
+
+// toJSON converts the summary, including any per-user breakdown, to its
+// machine-readable representation.
+func (s *Summary) toJSON() summaryJSON {
+	out := summaryJSON{
+		Points:             s.Points,
+		DistanceMeters:     s.Distance,
+		DurationSeconds:    s.Duration.Seconds(),
+		MovingTimeSeconds:  s.MovingTime.Seconds(),
+		StoppedTimeSeconds: s.StoppedTime.Seconds(),
+		MovingAvgSpeedKmh:  s.MovingAvgSpeed,
+		AvgSpeedKmh:        s.AvgSpeed,
+		MaxSpeedKmh:        s.MaxSpeed,
+		PaceSeconds:        s.Pace.Seconds(),
+		Splits:             []splitJSON{},
+		Loop:               s.Loop,
+		LoopDirection:      s.LoopDirection,
+	}
+	// Bearings are null for stationary tracks rather than a misleading north
+	if s.HasBearing {
+		out.InitialBearing = &s.InitialBearing
+		out.AverageBearing = &s.AverageBearing
+	}
+	if s.Deviation != nil {
+		out.Deviation = &deviationJSON{
+			MaxDistanceMeters: s.Deviation.MaxDistance,
+			AvgDistanceMeters: s.Deviation.AvgDistance,
+			ThresholdMeters:   s.Deviation.Threshold,
+			OffRoutePoints:    s.Deviation.OffRoutePoints,
+			OffRouteFraction:  s.Deviation.OffRouteFraction,
+		}
+	}
+	for _, split := range s.Splits {
+		out.Splits = append(out.Splits, splitJSON{
+			Number:          split.Number,
+			DistanceMeters:  split.Distance,
+			DurationSeconds: split.Duration.Seconds(),
+			PaceSeconds:     split.Pace.Seconds(),
+		})
+	}
+	// Time range and bounds are omitted for empty tracks
+	if s.Points > 0 {
+		out.StartTime, out.EndTime = &s.Start, &s.End
+		out.Bounds = &boundsJSON{North: s.Bounds.North, South: s.Bounds.South, East: s.Bounds.East, West: s.Bounds.West}
+	}
+	for _, user := range s.Users {
+		out.Users = append(out.Users, userJSON{User: user.User, summaryJSON: user.Summary.toJSON()})
+	}
+	return out
+}
+
+// WriteJSON writes the summary as indented JSON to the provided writer.
+//
+// @method WriteJSON
+// @description Exports route statistics and splits as machine-readable JSON
+// @param w io.Writer Destination for the JSON document
+// @return error Error if encoding or writing fails
+// @example err := summary.WriteJSON(file)
+func (s *Summary) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("cannot encode statistics: %w", err)
+	}
+	return nil
+}
+
+// FormatDuration renders a duration in a compact human-readable form such as
+// "1h 05m" or "12m 30s", suitable for stats bars and console output.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+
+	if hours > 0 {
+		return fmt.Sprintf("%dh %02dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm %02ds", minutes, seconds)
+}
+
+// FormatPace renders a pace (time per distance unit) such as "5:30 /km" or "8:51 /mi".
+func FormatPace(pace time.Duration, units string) string {
+	pace = pace.Round(time.Second)
+	suffix := "/km"
+	if units == "imperial" {
+		suffix = "/mi"
+	}
+	return fmt.Sprintf("%d:%02d %s", int(pace.Minutes()), int(pace.Seconds())%60, suffix)
+}
+
+// FormatSpeed renders a speed given in km/h using the configured distance units.
+// Imperial units are converted to mph; any other value is treated as metric.
+func FormatSpeed(kmh float64, units string) string {
+	if units == "imperial" {
+		return fmt.Sprintf("%.1f mph", kmh*0.621371)
+	}
+	return fmt.Sprintf("%.1f km/h", kmh)
+}
+
+// FormatBearing renders a bearing in degrees with its compass label, such as "45° NE".
+func FormatBearing(bearing float64) string {
+	degrees := math.Mod(math.Round(bearing), 360)
+	return fmt.Sprintf("%.0f° %s", degrees, gps.CardinalDirection(bearing))
+}
+
+// FormatLoop describes the shape of a route for display, such as "Loop (clockwise)",
+// "Loop (out and back)", or "One way".
+func FormatLoop(summary *Summary) string {
+	switch {
+	case !summary.Loop:
+		return "One way"
+	case summary.LoopDirection == "":
+		return "Loop (out and back)"
+	default:
+		return fmt.Sprintf("Loop (%s)", summary.LoopDirection)
+	}
+}
+
+// FormatDistance renders a distance given in meters using the configured distance units.
+// Imperial units are converted to miles; any other value is treated as metric.
+func FormatDistance(meters float64, units string) string {
+	if units == "imperial" {
+		return fmt.Sprintf("%.2f mi", meters/1609.344)
+	}
+	return fmt.Sprintf("%.2f km", meters/1000)
+}
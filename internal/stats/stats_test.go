@@ -0,0 +1,446 @@
+// Package stats_test provides unit tests for route statistics calculation.
+// It tests distance and duration totals, moving versus stopped time classification,
+// threshold configuration, and human-readable formatting helpers.
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestCompute(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+
+	// Move ~1.1 km in 10 minutes, stand still for 20 minutes, then move again
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0},
+		{Timestamp: start.Add(10 * time.Minute), Latitude: 0.01, Longitude: 0},
+		{Timestamp: start.Add(30 * time.Minute), Latitude: 0.01, Longitude: 0},
+		{Timestamp: start.Add(40 * time.Minute), Latitude: 0.02, Longitude: 0},
+	}
+
+	summary := Compute(points, &config.StatisticsConfig{StoppedSpeedThreshold: 1})
+
+	if summary.Points != 4 {
+		t.Errorf("Compute().Points = %d, want 4", summary.Points)
+	}
+	if summary.Duration != 40*time.Minute {
+		t.Errorf("Compute().Duration = %v, want 40m", summary.Duration)
+	}
+	if summary.MovingTime != 20*time.Minute {
+		t.Errorf("Compute().MovingTime = %v, want 20m", summary.MovingTime)
+	}
+	if summary.StoppedTime != 20*time.Minute {
+		t.Errorf("Compute().StoppedTime = %v, want 20m", summary.StoppedTime)
+	}
+	if math.Abs(summary.Distance-2223.9) > 1 {
+		t.Errorf("Compute().Distance = %v, want ~2223.9", summary.Distance)
+	}
+	if math.Abs(summary.MovingAvgSpeed-6.67) > 0.01 {
+		t.Errorf("Compute().MovingAvgSpeed = %v, want ~6.67", summary.MovingAvgSpeed)
+	}
+}
+
+func TestComputeThreshold(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+
+	// ~6.7 km/h walking pace for 10 minutes
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0},
+		{Timestamp: start.Add(10 * time.Minute), Latitude: 0.01, Longitude: 0},
+	}
+
+	tests := []struct {
+		name        string
+		cfg         *config.StatisticsConfig
+		wantMoving  time.Duration
+		wantStopped time.Duration
+	}{
+		{
+			name:       "nil config uses default threshold",
+			cfg:        nil,
+			wantMoving: 10 * time.Minute,
+		},
+		{
+			name:        "threshold above walking pace",
+			cfg:         &config.StatisticsConfig{StoppedSpeedThreshold: 10},
+			wantStopped: 10 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := Compute(points, tt.cfg)
+			if summary.MovingTime != tt.wantMoving {
+				t.Errorf("Compute().MovingTime = %v, want %v", summary.MovingTime, tt.wantMoving)
+			}
+			if summary.StoppedTime != tt.wantStopped {
+				t.Errorf("Compute().StoppedTime = %v, want %v", summary.StoppedTime, tt.wantStopped)
+			}
+		})
+	}
+}
+
+func TestComputeEmpty(t *testing.T) {
+	summary := Compute(gps.Points{}, &config.StatisticsConfig{})
+	if summary.Points != 0 || summary.Distance != 0 || summary.MovingAvgSpeed != 0 {
+		t.Errorf("Compute() on empty points = %+v, want zero summary", summary)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0m 00s"},
+		{90 * time.Second, "1m 30s"},
+		{65 * time.Minute, "1h 05m"},
+		{25*time.Hour + 30*time.Minute, "25h 30m"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSpeedAndDistance(t *testing.T) {
+	if got := FormatSpeed(10, "metric"); got != "10.0 km/h" {
+		t.Errorf("FormatSpeed(metric) = %q", got)
+	}
+	if got := FormatSpeed(10, "imperial"); got != "6.2 mph" {
+		t.Errorf("FormatSpeed(imperial) = %q", got)
+	}
+	if got := FormatDistance(1500, ""); got != "1.50 km" {
+		t.Errorf("FormatDistance(metric) = %q", got)
+	}
+	if got := FormatDistance(1609.344, "imperial"); got != "1.00 mi" {
+		t.Errorf("FormatDistance(imperial) = %q", got)
+	}
+}
+
+func TestComputeSpeedsAndSplits(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+
+	// 0.01 degrees of latitude is ~1111.95 m; cover it in 5 minutes, then again in 10 minutes
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0},
+		{Timestamp: start.Add(5 * time.Minute), Latitude: 0.01, Longitude: 0},
+		{Timestamp: start.Add(15 * time.Minute), Latitude: 0.02, Longitude: 0},
+	}
+
+	summary := Compute(points, &config.StatisticsConfig{DistanceUnits: "metric"})
+
+	if math.Abs(summary.MaxSpeed-13.34) > 0.01 {
+		t.Errorf("Compute().MaxSpeed = %v, want ~13.34", summary.MaxSpeed)
+	}
+	if math.Abs(summary.AvgSpeed-8.90) > 0.01 {
+		t.Errorf("Compute().AvgSpeed = %v, want ~8.90", summary.AvgSpeed)
+	}
+	if summary.Pace.Round(time.Second) != 6*time.Minute+45*time.Second {
+		t.Errorf("Compute().Pace = %v, want 6m45s", summary.Pace.Round(time.Second))
+	}
+
+	if len(summary.Splits) != 3 {
+		t.Fatalf("Compute().Splits has %d entries, want 3", len(summary.Splits))
+	}
+
+	// First kilometer is entirely within the fast segment
+	if got := summary.Splits[0].Duration.Round(time.Second); got != 4*time.Minute+30*time.Second {
+		t.Errorf("Splits[0].Duration = %v, want 4m30s", got)
+	}
+	if summary.Splits[0].Distance != 1000 {
+		t.Errorf("Splits[0].Distance = %v, want 1000", summary.Splits[0].Distance)
+	}
+
+	// The final split is partial and carries the remaining distance
+	last := summary.Splits[2]
+	if math.Abs(last.Distance-223.9) > 0.5 {
+		t.Errorf("last split Distance = %v, want ~223.9", last.Distance)
+	}
+
+	var total time.Duration
+	for _, split := range summary.Splits {
+		total += split.Duration
+	}
+	if total.Round(time.Second) != 15*time.Minute {
+		t.Errorf("sum of split durations = %v, want 15m", total)
+	}
+}
+
+func TestComputeSplitsImperial(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0},
+		{Timestamp: start.Add(20 * time.Minute), Latitude: 0.02, Longitude: 0},
+	}
+
+	summary := Compute(points, &config.StatisticsConfig{DistanceUnits: "imperial"})
+	if len(summary.Splits) != 2 {
+		t.Fatalf("Compute().Splits has %d entries, want 2", len(summary.Splits))
+	}
+	if summary.Splits[0].Distance != MetersPerMile {
+		t.Errorf("Splits[0].Distance = %v, want %v", summary.Splits[0].Distance, MetersPerMile)
+	}
+}
+
+func TestSummaryWriteJSON(t *testing.T) {
+	summary := &Summary{
+		Points:     2,
+		Distance:   1500,
+		Duration:   10 * time.Minute,
+		MovingTime: 8 * time.Minute,
+		Splits: []Split{
+			{Number: 1, Distance: 1000, Duration: 5 * time.Minute, Pace: 5 * time.Minute},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := summary.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON() produced invalid JSON: %v", err)
+	}
+	if decoded["duration_seconds"] != 600.0 {
+		t.Errorf("duration_seconds = %v, want 600", decoded["duration_seconds"])
+	}
+	if decoded["moving_time_seconds"] != 480.0 {
+		t.Errorf("moving_time_seconds = %v, want 480", decoded["moving_time_seconds"])
+	}
+	splits, ok := decoded["splits"].([]interface{})
+	if !ok || len(splits) != 1 {
+		t.Fatalf("splits = %v, want one entry", decoded["splits"])
+	}
+	if _, ok := decoded["deviation"]; ok {
+		t.Error("deviation present without a reference route")
+	}
+	if _, ok := decoded["users"]; ok {
+		t.Error("users present for a single-user track")
+	}
+
+	// Deviation statistics are included once a reference route was compared
+	summary.Deviation = &Deviation{MaxDistance: 120, AvgDistance: 30, Threshold: 50, OffRoutePoints: 1, OffRouteFraction: 0.5}
+	buf.Reset()
+	if err := summary.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	decoded = nil
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON() produced invalid JSON: %v", err)
+	}
+	deviation, ok := decoded["deviation"].(map[string]interface{})
+	if !ok || deviation["max_distance_meters"] != 120.0 || deviation["off_route_fraction"] != 0.5 {
+		t.Errorf("deviation = %v, want max 120 m and fraction 0.5", decoded["deviation"])
+	}
+}
+
+func TestComputeBreakdown(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.00, Longitude: -122.00, User: "alice"},
+		{Timestamp: start.Add(time.Minute), Latitude: 37.10, Longitude: -122.20, User: "bob"},
+		{Timestamp: start.Add(2 * time.Minute), Latitude: 37.01, Longitude: -122.00, User: "alice"},
+		{Timestamp: start.Add(3 * time.Minute), Latitude: 37.11, Longitude: -122.20, User: "bob"},
+		{Timestamp: start.Add(4 * time.Minute), Latitude: 37.12, Longitude: -122.20, User: "bob"},
+	}
+
+	summary := Compute(points, nil)
+	if !summary.Start.Equal(start) || !summary.End.Equal(start.Add(4*time.Minute)) {
+		t.Errorf("time range = %v - %v, want %v - %v", summary.Start, summary.End, start, start.Add(4*time.Minute))
+	}
+	want := Bounds{North: 37.12, South: 37.00, East: -122.00, West: -122.20}
+	if summary.Bounds != want {
+		t.Errorf("Bounds = %+v, want %+v", summary.Bounds, want)
+	}
+
+	if len(summary.Users) != 2 {
+		t.Fatalf("len(Users) = %d, want 2", len(summary.Users))
+	}
+	for i, tt := range []struct {
+		user   string
+		points int
+	}{{"alice", 2}, {"bob", 3}} {
+		got := summary.Users[i]
+		if got.User != tt.user || got.Summary.Points != tt.points {
+			t.Errorf("Users[%d] = %s with %d points, want %s with %d", i, got.User, got.Summary.Points, tt.user, tt.points)
+		}
+		if got.Summary.Users != nil {
+			t.Errorf("Users[%d] has a nested breakdown", i)
+		}
+	}
+
+	// Per-user distances exclude the jumps between interleaved users
+	alice := summary.Users[0].Summary.Distance
+	if alice < 1000 || alice > 1200 {
+		t.Errorf("alice distance = %.0f m, want ~1110 m", alice)
+	}
+
+	var buf bytes.Buffer
+	if err := summary.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var decoded struct {
+		StartTime string             `json:"start_time"`
+		Bounds    map[string]float64 `json:"bounds"`
+		Users     []struct {
+			User           string  `json:"user"`
+			Points         int     `json:"points"`
+			DistanceMeters float64 `json:"distance_meters"`
+		} `json:"users"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteJSON() produced invalid JSON: %v", err)
+	}
+	if decoded.StartTime != "2025-10-28T10:00:00Z" {
+		t.Errorf("start_time = %q, want 2025-10-28T10:00:00Z", decoded.StartTime)
+	}
+	if decoded.Bounds["north"] != 37.12 || decoded.Bounds["west"] != -122.20 {
+		t.Errorf("bounds = %v, want north 37.12 and west -122.2", decoded.Bounds)
+	}
+	if len(decoded.Users) != 2 || decoded.Users[1].User != "bob" || decoded.Users[1].Points != 3 || decoded.Users[0].DistanceMeters != alice {
+		t.Errorf("users = %+v, want alice and bob breakdowns", decoded.Users)
+	}
+
+	// Single-user tracks have no breakdown
+	if single := Compute(points.ByUser()["bob"], nil); single.Users != nil {
+		t.Errorf("single-user Users = %v, want nil", single.Users)
+	}
+}
+
+func TestFormatPace(t *testing.T) {
+	if got := FormatPace(5*time.Minute+30*time.Second, "metric"); got != "5:30 /km" {
+		t.Errorf("FormatPace(metric) = %q", got)
+	}
+	if got := FormatPace(8*time.Minute+51*time.Second, "imperial"); got != "8:51 /mi" {
+		t.Errorf("FormatPace(imperial) = %q", got)
+	}
+}
+
+func TestComputeBearings(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+
+	// Stand still, head north, then head east for a shorter distance
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0},
+		{Timestamp: start.Add(time.Minute), Latitude: 0, Longitude: 0},
+		{Timestamp: start.Add(11 * time.Minute), Latitude: 0.02, Longitude: 0},
+		{Timestamp: start.Add(16 * time.Minute), Latitude: 0.02, Longitude: 0.01},
+	}
+
+	summary := Compute(points, nil)
+	if !summary.HasBearing {
+		t.Fatal("Compute().HasBearing = false, want true")
+	}
+	if math.Abs(summary.InitialBearing) > 0.01 {
+		t.Errorf("Compute().InitialBearing = %v, want 0", summary.InitialBearing)
+	}
+	if summary.AverageBearing < 20 || summary.AverageBearing > 35 {
+		t.Errorf("Compute().AverageBearing = %v, want north-northeast", summary.AverageBearing)
+	}
+
+	stationary := Compute(gps.Points{points[0], points[1]}, nil)
+	if stationary.HasBearing {
+		t.Error("Compute() on stationary track HasBearing = true, want false")
+	}
+	data, err := json.Marshal(stationary)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !bytes.Contains(data, []byte(`"initial_bearing_degrees":null`)) {
+		t.Errorf("stationary JSON = %s, want null initial bearing", data)
+	}
+}
+
+func TestFormatBearing(t *testing.T) {
+	tests := []struct {
+		bearing float64
+		want    string
+	}{
+		{0, "0° N"},
+		{44.6, "45° NE"},
+		{200, "200° S"},
+		{359.7, "0° N"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatBearing(tt.bearing); got != tt.want {
+			t.Errorf("FormatBearing(%v) = %q, want %q", tt.bearing, got, tt.want)
+		}
+	}
+}
+
+func TestComputeDistanceMethod(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0},
+		{Timestamp: start.Add(time.Hour), Latitude: 0, Longitude: 1},
+	}
+
+	haversine := Compute(points, &config.StatisticsConfig{DistanceMethod: gps.DistanceHaversine})
+	vincenty := Compute(points, &config.StatisticsConfig{DistanceMethod: gps.DistanceVincenty})
+
+	// One degree of longitude on the equator is longer on the ellipsoid than the sphere
+	if math.Abs(haversine.Distance-111195) > 1 {
+		t.Errorf("haversine Distance = %v, want ~111195", haversine.Distance)
+	}
+	if math.Abs(vincenty.Distance-111319.491) > 0.01 {
+		t.Errorf("vincenty Distance = %v, want ~111319.491", vincenty.Distance)
+	}
+	if math.Abs(vincenty.MaxSpeed-111.319491) > 0.0001 {
+		t.Errorf("vincenty MaxSpeed = %v, want ~111.319", vincenty.MaxSpeed)
+	}
+}
+
+func TestComputeLoop(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	// Clockwise around a ~1 km square: north, east, south, then back west
+	corners := [][2]float64{{0, 0}, {0.009, 0}, {0.009, 0.009}, {0, 0.009}, {0.0002, 0}}
+	var loop gps.Points
+	for i, c := range corners {
+		loop = append(loop, gps.Point{Timestamp: start.Add(time.Duration(i) * 5 * time.Minute), Latitude: c[0], Longitude: c[1]})
+	}
+
+	tests := []struct {
+		name      string
+		points    gps.Points
+		cfg       *config.StatisticsConfig
+		wantLoop  bool
+		wantLabel string
+	}{
+		{name: "clockwise loop", points: loop, wantLoop: true, wantLabel: "Loop (clockwise)"},
+		{name: "one way", points: loop[:3], wantLabel: "One way"},
+		{
+			name:      "end too far for configured threshold",
+			points:    loop,
+			cfg:       &config.StatisticsConfig{LoopThreshold: 10},
+			wantLabel: "One way",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := Compute(tt.points, tt.cfg)
+			if summary.Loop != tt.wantLoop {
+				t.Errorf("Compute() Loop = %v, want %v", summary.Loop, tt.wantLoop)
+			}
+			if got := FormatLoop(summary); got != tt.wantLabel {
+				t.Errorf("FormatLoop() = %q, want %q", got, tt.wantLabel)
+			}
+		})
+	}
+
+	if got := FormatLoop(&Summary{Loop: true}); got != "Loop (out and back)" {
+		t.Errorf("FormatLoop() without direction = %q, want %q", got, "Loop (out and back)")
+	}
+}
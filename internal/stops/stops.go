@@ -0,0 +1,146 @@
+// Package stops provides detection of the places where a GPS track stayed put.
+//
+// @title Stop Detection Package
+// @version 1.0
+// @description Finds stops where a track lingered within a small radius
+// @description Reports the arrival, departure, and dwell time of each stop
+//
+// Features:
+// - Stay point detection with a configurable radius and minimum dwell time
+// - Separate detection for each user of a shared multi-user track
+// - Stop location averaged over the points recorded during the stop
+package stops
+
+import (
+	"sort"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// DefaultRadius is the distance in meters a track may wander while stopped, used
+// when none is configured.
+const DefaultRadius = 50.0
+
+// DefaultMinDuration is the shortest stay reported as a stop when no minimum is
+// configured.
+const DefaultMinDuration = 5 * time.Minute
+
+// DefaultColor is the marker color of stops when none is configured.
+const DefaultColor = "#795548"
+
+// Stop represents a place where a track stayed within the stop radius.
+//
+// @struct Stop
+// @description Stay of a track at one place, with its arrival and departure
+// @property User string User whose track stopped (empty for single-user tracks)
+// @property Arrival time.Time Timestamp of the first point of the stop
+// @property Departure time.Time Timestamp of the last point of the stop
+// @property Location gps.Point Average position and elevation of the stop's points
+// @property Points []int Indexes of the stop's points in the detected points
+type Stop struct {
+	User      string    // @field User User whose track stopped
+	Arrival   time.Time // @field Arrival Timestamp of the first point of the stop
+	Departure time.Time // @field Departure Timestamp of the last point of the stop
+	Location  gps.Point // @field Location Average position of the stop's points
+	Points    []int     // @field Points Indexes of the stop's points in the detected points
+}
+
+// Duration returns how long the track stayed at the stop.
+func (s Stop) Duration() time.Duration {
+	return s.Departure.Sub(s.Arrival)
+}
+
+// Detect finds the stops of each user's track. A stop begins at a point and takes in
+// the points that follow while they stay within the radius of it; the run counts as a
+// stop when it lasts at least the minimum duration.
+//
+// @function Detect
+// @description Computes the stops of chronologically sorted GPS points
+// @param points gps.Points GPS points sorted by timestamp
+// @param radius float64 Distance in meters from the first point of a stop that ends it
+// @param minDuration time.Duration Shortest stay reported as a stop
+// @return []Stop Stops ordered by arrival time, then by user
+// @logic Points of different users are never part of the same stop
+// @example found := stops.Detect(points, 50, 5*time.Minute)
+func Detect(points gps.Points, radius float64, minDuration time.Duration) []Stop {
+	// Indexes of each user's points, in track order
+	var users []string
+	tracks := make(map[string][]int)
+	for i, point := range points {
+		if _, ok := tracks[point.User]; !ok {
+			users = append(users, point.User)
+		}
+		tracks[point.User] = append(tracks[point.User], i)
+	}
+
+	var found []Stop
+	for _, user := range users {
+		found = append(found, detectTrack(points, tracks[user], radius, minDuration)...)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if !found[i].Arrival.Equal(found[j].Arrival) {
+			return found[i].Arrival.Before(found[j].Arrival)
+		}
+		return found[i].User < found[j].User
+	})
+	return found
+}
+
+// DetectPoints detects stops using the configured radius and minimum duration,
+// falling back to the package defaults.
+func DetectPoints(points gps.Points, cfg *config.StopsConfig) []Stop {
+	radius := cfg.Radius
+	if radius <= 0 {
+		radius = DefaultRadius
+	}
+	minDuration := time.Duration(cfg.MinDurationSeconds) * time.Second
+	if minDuration <= 0 {
+		minDuration = DefaultMinDuration
+	}
+
+	return Detect(points, radius, minDuration)
+}
+
+// detectTrack finds the stops among the points at the given indexes, which belong to
+// a single user.
+func detectTrack(points gps.Points, track []int, radius float64, minDuration time.Duration) []Stop {
+	var found []Stop
+	for i := 0; i < len(track); {
+		anchor := points[track[i]]
+		j := i + 1
+		for j < len(track) && anchor.DistanceTo(points[track[j]]) <= radius {
+			j++
+		}
+
+		last := points[track[j-1]]
+		if j-i < 2 || last.Timestamp.Sub(anchor.Timestamp) < minDuration {
+			i++
+			continue
+		}
+
+		stop := Stop{
+			User:      anchor.User,
+			Arrival:   anchor.Timestamp,
+			Departure: last.Timestamp,
+			Points:    append([]int(nil), track[i:j]...),
+		}
+		for _, index := range stop.Points {
+			stop.Location.Latitude += points[index].Latitude
+			stop.Location.Longitude += points[index].Longitude
+			stop.Location.Elevation += points[index].Elevation
+		}
+		n := float64(len(stop.Points))
+		stop.Location.Latitude /= n
+		stop.Location.Longitude /= n
+		stop.Location.Elevation /= n
+		stop.Location.Timestamp = stop.Arrival
+		stop.Location.User = stop.User
+
+		found = append(found, stop)
+		i = j
+	}
+	return found
+}
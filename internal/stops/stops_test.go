@@ -0,0 +1,114 @@
+// Package stops_test provides unit tests for stop detection.
+// It tests the radius and minimum duration thresholds, per-user detection on shared
+// tracks, and the averaged stop locations.
+package stops
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// metersPerDegree converts meters of latitude into degrees when building test tracks
+const metersPerDegree = 111195.0
+
+func track(user string, start time.Time, step time.Duration, meters ...float64) gps.Points {
+	points := make(gps.Points, len(meters))
+	for i, m := range meters {
+		points[i] = gps.Point{Timestamp: start.Add(time.Duration(i) * step), Latitude: m / metersPerDegree, User: user}
+	}
+	return points
+}
+
+func TestDetect(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		points     gps.Points
+		want       [][]int
+		wantLength []time.Duration
+	}{
+		{
+			name:       "walk, stop, walk",
+			points:     track("", start, 2*time.Minute, 0, 500, 510, 505, 520, 1000),
+			want:       [][]int{{1, 2, 3, 4}},
+			wantLength: []time.Duration{6 * time.Minute},
+		},
+		{
+			name:   "pause shorter than the minimum",
+			points: track("", start, time.Minute, 0, 500, 510, 1000),
+		},
+		{
+			name:   "drifting beyond the radius",
+			points: track("", start, 2*time.Minute, 0, 40, 80, 120, 160),
+		},
+		{
+			name:       "whole track is one stop",
+			points:     track("", start, 5*time.Minute, 0, 10, 5),
+			want:       [][]int{{0, 1, 2}},
+			wantLength: []time.Duration{10 * time.Minute},
+		},
+		{
+			name:       "users stop separately",
+			points:     append(track("alice", start, 5*time.Minute, 0, 0, 900), track("bob", start.Add(time.Minute), 5*time.Minute, 0, 800, 800)...),
+			want:       [][]int{{0, 1}, {4, 5}},
+			wantLength: []time.Duration{5 * time.Minute, 5 * time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Detect(tt.points, 50, 5*time.Minute)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Detect() found %d stops, want %d", len(got), len(tt.want))
+			}
+			for i, stop := range got {
+				if len(stop.Points) != len(tt.want[i]) || stop.Points[0] != tt.want[i][0] || stop.Points[len(stop.Points)-1] != tt.want[i][len(tt.want[i])-1] {
+					t.Errorf("stop %d points = %v, want %v", i, stop.Points, tt.want[i])
+				}
+				if stop.Duration() != tt.wantLength[i] {
+					t.Errorf("stop %d Duration() = %v, want %v", i, stop.Duration(), tt.wantLength[i])
+				}
+				if stop.User != tt.points[stop.Points[0]].User {
+					t.Errorf("stop %d User = %q, want %q", i, stop.User, tt.points[stop.Points[0]].User)
+				}
+			}
+		})
+	}
+}
+
+func TestStopLocation(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := track("", start, 5*time.Minute, 1000, 1010, 1030)
+
+	got := Detect(points, 50, 5*time.Minute)
+	if len(got) != 1 {
+		t.Fatalf("Detect() found %d stops, want 1", len(got))
+	}
+	if want := 1013.333 / metersPerDegree; math.Abs(got[0].Location.Latitude-want) > 1e-7 {
+		t.Errorf("Location.Latitude = %v, want %v", got[0].Location.Latitude, want)
+	}
+	if !got[0].Location.Timestamp.Equal(start) {
+		t.Errorf("Location.Timestamp = %v, want the arrival %v", got[0].Location.Timestamp, start)
+	}
+}
+
+func TestDetectPointsDefaults(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	// 4 minutes within 30 m: a stop only with a shorter minimum duration
+	points := track("", start, 2*time.Minute, 0, 30, 0)
+
+	if got := DetectPoints(points, &config.StopsConfig{}); len(got) != 0 {
+		t.Errorf("DetectPoints() with defaults found %d stops, want 0", len(got))
+	}
+	if got := DetectPoints(points, &config.StopsConfig{MinDurationSeconds: 180}); len(got) != 1 {
+		t.Errorf("DetectPoints() with a 3 minute minimum found %d stops, want 1", len(got))
+	}
+	if got := DetectPoints(points, &config.StopsConfig{Radius: 20, MinDurationSeconds: 180}); len(got) != 0 {
+		t.Errorf("DetectPoints() with a 20 m radius found %d stops, want 0", len(got))
+	}
+}
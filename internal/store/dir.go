@@ -0,0 +1,137 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// BackendDir is the name of the directory backend.
+const BackendDir = "dir"
+
+func init() {
+	Register(BackendDir, func(cfg *config.StorageConfig) (Store, error) { return NewDir(cfg.Dir) })
+}
+
+// Dir keeps each track as a JSON file named after its ID in a directory, so
+// tracks survive restarts and can be backed up with the directory.
+//
+// @struct Dir
+// @description Store persisting tracks as files
+// @property Path string Directory holding the track files
+type Dir struct {
+	Path string     // @field Path Directory holding the track files
+	mu   sync.Mutex // Serializes merges, which read and rewrite a file
+}
+
+// NewDir opens a directory store, creating the directory if needed.
+//
+// @function NewDir
+// @description Opens the directory backend
+// @param path string Directory holding the track files
+// @return *Dir Store writing to path
+// @return error Error if path is empty or the directory cannot be created
+// @example tracks, err := store.NewDir("/var/lib/geo-chrono/tracks")
+func NewDir(path string) (*Dir, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the dir storage backend needs server.storage.dir")
+	}
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, fmt.Errorf("cannot create storage directory %s: %w", path, err)
+	}
+	return &Dir{Path: path}, nil
+}
+
+// Save stores a track under its ID, replacing an earlier version. The file is written under a temporary
+// name first, so a crash never leaves a partial track behind.
+func (d *Dir) Save(track *Track) error {
+	if !ValidID(track.ID) {
+		return fmt.Errorf("invalid track ID %q", track.ID)
+	}
+	data, err := json.Marshal(track)
+	if err != nil {
+		return fmt.Errorf("cannot encode track %s: %w", track.ID, err)
+	}
+
+	file := d.file(track.ID)
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("cannot write track %s: %w", track.ID, err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("cannot write track %s: %w", track.ID, err)
+	}
+	return nil
+}
+
+// Load returns the track with the given ID, or ErrNotFound.
+func (d *Dir) Load(id string) (*Track, error) {
+	if !ValidID(id) {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(d.file(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read track %s: %w", id, err)
+	}
+
+	var track Track
+	if err := json.Unmarshal(data, &track); err != nil {
+		return nil, fmt.Errorf("cannot decode track %s: %w", id, err)
+	}
+	return &track, nil
+}
+
+// List returns every track without its points, oldest first.
+func (d *Dir) List() ([]*Track, error) {
+	entries, err := os.ReadDir(d.Path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list tracks: %w", err)
+	}
+
+	var tracks []*Track
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || !ValidID(id) {
+			continue
+		}
+		track, err := d.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, summary(track))
+	}
+	sortTracks(tracks)
+	return tracks, nil
+}
+
+// Merge appends the points the track does not hold yet, rewriting its file.
+func (d *Dir) Merge(id string, points gps.Points) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	track, err := d.Load(id)
+	if err != nil {
+		return 0, err
+	}
+	track, added := merged(track, points)
+	if added == 0 {
+		return 0, nil
+	}
+	return added, d.Save(track)
+}
+
+// file returns the path of the file holding a track.
+func (d *Dir) file(id string) string {
+	return filepath.Join(d.Path, id+".json")
+}
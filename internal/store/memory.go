@@ -0,0 +1,79 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// BackendMemory is the name of the in-memory backend.
+const BackendMemory = "memory"
+
+func init() {
+	Register(BackendMemory, func(*config.StorageConfig) (Store, error) { return NewMemory(), nil })
+}
+
+// Memory keeps tracks in memory; they are lost when the server stops.
+//
+// @struct Memory
+// @description Store for previews and tests that need no persistence
+// @property tracks map[string]*Track Stored tracks by ID
+type Memory struct {
+	mu     sync.RWMutex
+	tracks map[string]*Track // @field tracks Stored tracks by ID
+}
+
+// NewMemory creates an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{tracks: make(map[string]*Track)}
+}
+
+// Save stores a track under its ID, replacing an earlier version.
+func (m *Memory) Save(track *Track) error {
+	if !ValidID(track.ID) {
+		return fmt.Errorf("invalid track ID %q", track.ID)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tracks[track.ID] = track
+	return nil
+}
+
+// Load returns the track with the given ID, or ErrNotFound.
+func (m *Memory) Load(id string) (*Track, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	track, ok := m.tracks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return track, nil
+}
+
+// List returns every track without its points, oldest first.
+func (m *Memory) List() ([]*Track, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	tracks := make([]*Track, 0, len(m.tracks))
+	for _, track := range m.tracks {
+		tracks = append(tracks, summary(track))
+	}
+	sortTracks(tracks)
+	return tracks, nil
+}
+
+// Merge appends the points the track does not hold yet. Earlier Loads keep
+// returning the track as it was.
+func (m *Memory) Merge(id string, points gps.Points) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	track, ok := m.tracks[id]
+	if !ok {
+		return 0, ErrNotFound
+	}
+	track, added := merged(track, points)
+	m.tracks[id] = track
+	return added, nil
+}
@@ -0,0 +1,6 @@
+//go:build postgres
+
+package store
+
+// Link the pgx PostgreSQL driver, registered as "pgx", into the postgres backend.
+import _ "github.com/jackc/pgx/v5/stdlib"
@@ -0,0 +1,389 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// Names of the SQL database backends.
+const (
+	BackendSQLite   = "sqlite"
+	BackendPostgres = "postgres"
+)
+
+func init() {
+	Register(BackendSQLite, func(cfg *config.StorageConfig) (Store, error) { return openSQL(BackendSQLite, cfg) })
+	Register(BackendPostgres, func(cfg *config.StorageConfig) (Store, error) { return openSQL(BackendPostgres, cfg) })
+}
+
+// openSQL opens a SQL store from the storage configuration.
+func openSQL(backend string, cfg *config.StorageConfig) (Store, error) {
+	s, err := NewSQL(backend, cfg.Driver, cfg.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PostGIS {
+		if err := s.EnablePostGIS(); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// dialect holds what differs between the supported SQL databases.
+type dialect struct {
+	backend  string // Backend name in server.storage.backend
+	driver   string // Default database/sql driver name
+	tag      string // Build tag linking the default driver into the binary
+	numbered bool   // Whether placeholders are numbered ($1) instead of ?
+	serial   bool   // Whether to use a single connection, as writers lock the whole database
+	lock     string // Clause locking the selected rows until the transaction ends
+}
+
+var (
+	sqliteDialect   = dialect{backend: BackendSQLite, driver: "sqlite", tag: "sqlite", serial: true}
+	postgresDialect = dialect{backend: BackendPostgres, driver: "pgx", tag: "postgres", numbered: true, lock: " FOR UPDATE"}
+)
+
+// sqlSchema creates the tables of a SQL store. Timestamps are kept as RFC 3339
+// text, which every driver reads back the same way and which keeps the offset
+// the points were recorded with.
+var sqlSchema = []string{
+	`CREATE TABLE IF NOT EXISTS tracks (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		created TEXT NOT NULL,
+		owner TEXT NOT NULL DEFAULT '',
+		public BOOLEAN NOT NULL DEFAULT FALSE
+	)`,
+	`CREATE TABLE IF NOT EXISTS points (
+		track_id TEXT NOT NULL REFERENCES tracks (id) ON DELETE CASCADE,
+		seq INTEGER NOT NULL,
+		recorded TEXT NOT NULL,
+		latitude DOUBLE PRECISION NOT NULL,
+		longitude DOUBLE PRECISION NOT NULL,
+		elevation DOUBLE PRECISION NOT NULL DEFAULT 0,
+		title TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		category TEXT NOT NULL DEFAULT '',
+		user_name TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (track_id, seq)
+	)`,
+}
+
+// postgisSchema adds a PostGIS geometry column, kept up to date by the database,
+// and a spatial index on it to the points table, for queries by location.
+var postgisSchema = []string{
+	`CREATE EXTENSION IF NOT EXISTS postgis`,
+	`ALTER TABLE points ADD COLUMN IF NOT EXISTS geom geometry(Point, 4326)
+		GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)) STORED`,
+	`CREATE INDEX IF NOT EXISTS points_geom ON points USING GIST (geom)`,
+}
+
+// SQL keeps tracks and their points in tables of a SQLite or PostgreSQL
+// database, through whichever database/sql driver the binary includes.
+//
+// @struct SQL
+// @description Store persisting tracks in a SQL database
+// @property db *sql.DB Open database handle
+// @property dialect dialect Placeholder style of the database
+// @note The default drivers, modernc.org/sqlite and github.com/jackc/pgx/v5,
+// are only linked in when building with the sqlite or postgres tag
+type SQL struct {
+	db      *sql.DB // @field db Open database handle
+	dialect dialect // @field dialect Placeholder style of the database
+}
+
+// NewSQL opens a SQL store and creates its tables if needed.
+//
+// @function NewSQL
+// @description Opens the sqlite or postgres backend
+// @param backend string BackendSQLite or BackendPostgres
+// @param driver string database/sql driver name ("" for the backend's default)
+// @param dsn string Data source name, such as a file path or connection URL
+// @return *SQL Store writing to the database
+// @return error Error if the driver is missing or the database cannot be set up
+// @example tracks, err := store.NewSQL(store.BackendSQLite, "", "tracks.db")
+// @note Build with -tags sqlite or -tags postgres to include the default driver
+func NewSQL(backend, driver, dsn string) (*SQL, error) {
+	var d dialect
+	switch backend {
+	case BackendSQLite:
+		d = sqliteDialect
+	case BackendPostgres:
+		d = postgresDialect
+	default:
+		return nil, fmt.Errorf("unknown SQL storage backend %q", backend)
+	}
+	if dsn == "" {
+		return nil, fmt.Errorf("the %s storage backend needs server.storage.dsn", backend)
+	}
+	if driver == "" {
+		driver = d.driver
+	}
+	if !hasDriver(driver) {
+		return nil, fmt.Errorf("the %s storage backend needs the %q database driver, which this binary does not include (rebuild with -tags %s)", backend, driver, d.tag)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s database: %w", backend, err)
+	}
+	if d.serial {
+		db.SetMaxOpenConns(1)
+	}
+	for _, statement := range sqlSchema {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("cannot create %s tables: %w", backend, err)
+		}
+	}
+	return &SQL{db: db, dialect: d}, nil
+}
+
+// EnablePostGIS adds a geometry column with a spatial index to the points
+// table of a PostgreSQL store, installing the PostGIS extension if needed.
+// The database fills the column from the latitude and longitude of each point.
+//
+// @method EnablePostGIS
+// @description Makes stored points queryable with PostGIS functions
+// @return error Error for SQLite or if PostGIS is not available
+// @example err := tracks.EnablePostGIS()
+// @note Generated columns need PostgreSQL 12 or later
+func (s *SQL) EnablePostGIS() error {
+	if s.dialect.backend != BackendPostgres {
+		return fmt.Errorf("server.storage.postgis needs the %s storage backend, not %s", BackendPostgres, s.dialect.backend)
+	}
+	for _, statement := range postgisSchema {
+		if _, err := s.db.Exec(statement); err != nil {
+			return fmt.Errorf("cannot set up PostGIS: %w", err)
+		}
+	}
+	return nil
+}
+
+// Save stores a track under its ID, replacing an earlier version and its
+// points in one transaction.
+func (s *SQL) Save(track *Track) error {
+	if !ValidID(track.ID) {
+		return fmt.Errorf("invalid track ID %q", track.ID)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("cannot write track %s: %w", track.ID, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(s.rebind(`INSERT INTO tracks (id, name, created, owner, public) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET name = excluded.name, created = excluded.created, owner = excluded.owner, public = excluded.public`),
+		track.ID, track.Name, formatTime(track.Created), track.Owner, track.Public)
+	if err == nil {
+		_, err = tx.Exec(s.rebind(`DELETE FROM points WHERE track_id = ?`), track.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write track %s: %w", track.ID, err)
+	}
+	if err := s.insertPoints(tx, track.ID, 0, track.Points); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("cannot write track %s: %w", track.ID, err)
+	}
+	return nil
+}
+
+// Merge inserts the points the track does not hold yet after its stored ones,
+// leaving those untouched, in one transaction.
+func (s *SQL) Merge(id string, points gps.Points) (int, error) {
+	if !ValidID(id) {
+		return 0, ErrNotFound
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("cannot write track %s: %w", id, err)
+	}
+	defer tx.Rollback()
+
+	// Lock the track, so that concurrent merges do not number points alike
+	err = tx.QueryRow(s.rebind(`SELECT id FROM tracks WHERE id = ?`+s.dialect.lock), id).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("cannot read track %s: %w", id, err)
+	}
+
+	rows, err := tx.Query(s.rebind(`SELECT seq, recorded, latitude, longitude, user_name FROM points WHERE track_id = ? ORDER BY seq`), id)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read points of track %s: %w", id, err)
+	}
+	seen := make(map[pointKey]bool)
+	next := 0
+	for rows.Next() {
+		var p gps.Point
+		var recorded string
+		if err := rows.Scan(&next, &recorded, &p.Latitude, &p.Longitude, &p.User); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("cannot read points of track %s: %w", id, err)
+		}
+		if p.Timestamp, err = time.Parse(time.RFC3339Nano, recorded); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("cannot read points of track %s: %w", id, err)
+		}
+		seen[keyOf(p)] = true
+		next++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("cannot read points of track %s: %w", id, err)
+	}
+
+	fresh := unseen(seen, points)
+	if len(fresh) == 0 {
+		return 0, nil
+	}
+	if err := s.insertPoints(tx, id, next, fresh); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("cannot write track %s: %w", id, err)
+	}
+	return len(fresh), nil
+}
+
+// Load returns the track with the given ID, or ErrNotFound.
+func (s *SQL) Load(id string) (*Track, error) {
+	if !ValidID(id) {
+		return nil, ErrNotFound
+	}
+	track, err := scanTrack(s.db.QueryRow(s.rebind(`SELECT id, name, created, owner, public FROM tracks WHERE id = ?`), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read track %s: %w", id, err)
+	}
+
+	rows, err := s.db.Query(s.rebind(`SELECT recorded, latitude, longitude, elevation, title, description, category, user_name
+		FROM points WHERE track_id = ? ORDER BY seq`), id)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read points of track %s: %w", id, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p gps.Point
+		var recorded string
+		if err := rows.Scan(&recorded, &p.Latitude, &p.Longitude, &p.Elevation, &p.Title, &p.Description, &p.Category, &p.User); err != nil {
+			return nil, fmt.Errorf("cannot read points of track %s: %w", id, err)
+		}
+		if p.Timestamp, err = time.Parse(time.RFC3339Nano, recorded); err != nil {
+			return nil, fmt.Errorf("cannot read points of track %s: %w", id, err)
+		}
+		track.Points = append(track.Points, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read points of track %s: %w", id, err)
+	}
+	return track, nil
+}
+
+// List returns every track without its points, oldest first.
+func (s *SQL) List() ([]*Track, error) {
+	rows, err := s.db.Query(`SELECT id, name, created, owner, public FROM tracks`)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list tracks: %w", err)
+	}
+	defer rows.Close()
+
+	var tracks []*Track
+	for rows.Next() {
+		track, err := scanTrack(rows)
+		if err != nil {
+			return nil, fmt.Errorf("cannot list tracks: %w", err)
+		}
+		tracks = append(tracks, track)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot list tracks: %w", err)
+	}
+	sortTracks(tracks)
+	return tracks, nil
+}
+
+// Close closes the database.
+func (s *SQL) Close() error {
+	return s.db.Close()
+}
+
+// insertPoints inserts points of a track, numbering them from first on.
+func (s *SQL) insertPoints(tx *sql.Tx, id string, first int, points gps.Points) error {
+	insert, err := tx.Prepare(s.rebind(`INSERT INTO points
+		(track_id, seq, recorded, latitude, longitude, elevation, title, description, category, user_name)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`))
+	if err != nil {
+		return fmt.Errorf("cannot write points of track %s: %w", id, err)
+	}
+	defer insert.Close()
+	for i, p := range points {
+		if _, err := insert.Exec(id, first+i, formatTime(p.Timestamp), p.Latitude, p.Longitude, p.Elevation, p.Title, p.Description, p.Category, p.User); err != nil {
+			return fmt.Errorf("cannot write points of track %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// rebind rewrites the ? placeholders of query for the database.
+func (s *SQL) rebind(query string) string {
+	if !s.dialect.numbered {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// scanTrack reads a row of the tracks table.
+func scanTrack(row interface{ Scan(...any) error }) (*Track, error) {
+	var track Track
+	var created string
+	if err := row.Scan(&track.ID, &track.Name, &created, &track.Owner, &track.Public); err != nil {
+		return nil, err
+	}
+	var err error
+	if track.Created, err = time.Parse(time.RFC3339Nano, created); err != nil {
+		return nil, err
+	}
+	return &track, nil
+}
+
+// formatTime formats a timestamp for the text columns of the schema.
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// hasDriver reports whether a database/sql driver is registered under name.
+func hasDriver(name string) bool {
+	for _, driver := range sql.Drivers() {
+		if driver == name {
+			return true
+		}
+	}
+	return false
+}
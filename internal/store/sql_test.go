@@ -0,0 +1,195 @@
+package store
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeDriverName is the database/sql driver registered by the tests.
+const fakeDriverName = "storetest"
+
+func init() {
+	sql.Register(fakeDriverName, &fakeDriver{databases: make(map[string]*fakeDatabase)})
+}
+
+// fakeDriver is a database/sql driver that understands exactly the statements
+// of the SQL store, so its queries and scanning can be tested without a
+// database server or cgo.
+type fakeDriver struct {
+	mu        sync.Mutex
+	databases map[string]*fakeDatabase // Databases by DSN
+}
+
+// fakeDatabase holds the rows of the tracks and points tables.
+type fakeDatabase struct {
+	mu      sync.Mutex
+	tracks  map[string][]driver.Value   // Track rows by ID
+	points  map[string][][]driver.Value // Point rows from seq on by track ID, in seq order
+	postgis bool                        // Whether the PostGIS statements ran
+}
+
+type fakeConn struct{ db *fakeDatabase }
+
+type fakeStmt struct {
+	db    *fakeDatabase
+	query string
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (d *fakeDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.databases[dsn] == nil {
+		d.databases[dsn] = &fakeDatabase{tracks: make(map[string][]driver.Value), points: make(map[string][][]driver.Value)}
+	}
+	return &fakeConn{db: d.databases[dsn]}, nil
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *fakeConn) Commit() error             { return nil }
+func (c *fakeConn) Rollback() error           { return nil }
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE"):
+	case strings.HasPrefix(s.query, "CREATE EXTENSION IF NOT EXISTS postgis"),
+		strings.HasPrefix(s.query, "ALTER TABLE points ADD COLUMN IF NOT EXISTS geom"),
+		strings.HasPrefix(s.query, "CREATE INDEX IF NOT EXISTS points_geom"):
+		s.db.postgis = true
+	case strings.HasPrefix(s.query, "INSERT INTO tracks"):
+		s.db.tracks[args[0].(string)] = args
+	case strings.HasPrefix(s.query, "DELETE FROM points WHERE track_id = ?"):
+		delete(s.db.points, args[0].(string))
+	case strings.HasPrefix(s.query, "INSERT INTO points"):
+		id := args[0].(string)
+		s.db.points[id] = append(s.db.points[id], args[1:])
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	rows := &fakeRows{}
+	switch {
+	case strings.HasPrefix(s.query, "SELECT id, name, created, owner, public FROM tracks WHERE id = ?"):
+		rows.columns = []string{"id", "name", "created", "owner", "public"}
+		if track, ok := s.db.tracks[args[0].(string)]; ok {
+			rows.rows = append(rows.rows, track)
+		}
+	case s.query == "SELECT id, name, created, owner, public FROM tracks":
+		rows.columns = []string{"id", "name", "created", "owner", "public"}
+		for _, track := range s.db.tracks {
+			rows.rows = append(rows.rows, track)
+		}
+	case strings.HasPrefix(s.query, "SELECT id FROM tracks WHERE id = "):
+		rows.columns = []string{"id"}
+		if track, ok := s.db.tracks[args[0].(string)]; ok {
+			rows.rows = append(rows.rows, track[:1])
+		}
+	case strings.HasPrefix(s.query, "SELECT recorded,") && strings.HasSuffix(s.query, "WHERE track_id = ? ORDER BY seq"):
+		rows.columns = []string{"recorded", "latitude", "longitude", "elevation", "title", "description", "category", "user_name"}
+		for _, point := range s.db.points[args[0].(string)] {
+			rows.rows = append(rows.rows, point[1:])
+		}
+	case strings.HasPrefix(s.query, "SELECT seq, recorded, latitude, longitude, user_name") && strings.HasSuffix(s.query, "WHERE track_id = ? ORDER BY seq"):
+		rows.columns = []string{"seq", "recorded", "latitude", "longitude", "user_name"}
+		for _, point := range s.db.points[args[0].(string)] {
+			rows.rows = append(rows.rows, []driver.Value{point[0], point[1], point[2], point[3], point[8]})
+		}
+	default:
+		return nil, fmt.Errorf("unexpected query %q", s.query)
+	}
+	return rows, nil
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestNewSQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		driver  string
+		dsn     string
+		wantErr string
+	}{
+		{name: "sqlite", backend: BackendSQLite, driver: fakeDriverName, dsn: "new"},
+		{name: "postgres", backend: BackendPostgres, driver: fakeDriverName, dsn: "new"},
+		{name: "missing driver", backend: BackendSQLite, driver: "missing", dsn: "tracks.db", wantErr: "-tags sqlite"},
+		{name: "missing dsn", backend: BackendPostgres, driver: fakeDriverName, wantErr: "server.storage.dsn"},
+		{name: "unknown backend", backend: "mysql", driver: fakeDriverName, dsn: "new", wantErr: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSQL(tt.backend, tt.driver, tt.dsn)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("NewSQL() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("NewSQL() error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSQLEnablePostGIS(t *testing.T) {
+	postgres, err := NewSQL(BackendPostgres, fakeDriverName, t.Name())
+	if err != nil {
+		t.Fatalf("NewSQL() error = %v", err)
+	}
+	if err := postgres.EnablePostGIS(); err != nil {
+		t.Errorf("EnablePostGIS() error = %v", err)
+	}
+	database := postgres.db.Driver().(*fakeDriver).databases[t.Name()]
+	if !database.postgis {
+		t.Error("EnablePostGIS() did not add the geometry column")
+	}
+
+	sqlite, err := NewSQL(BackendSQLite, fakeDriverName, t.Name()+"/sqlite")
+	if err != nil {
+		t.Fatalf("NewSQL() error = %v", err)
+	}
+	if err := sqlite.EnablePostGIS(); err == nil || !strings.Contains(err.Error(), "server.storage.postgis") {
+		t.Errorf("EnablePostGIS() on sqlite error = %v, want one naming server.storage.postgis", err)
+	}
+}
+
+func TestSQLRebind(t *testing.T) {
+	query := "UPDATE tracks SET name = ? WHERE id = ?"
+	if got := (&SQL{dialect: sqliteDialect}).rebind(query); got != query {
+		t.Errorf("sqlite rebind() = %q, want the query unchanged", got)
+	}
+	if got, want := (&SQL{dialect: postgresDialect}).rebind(query), "UPDATE tracks SET name = $1 WHERE id = $2"; got != want {
+		t.Errorf("postgres rebind() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+//go:build sqlite || postgres
+
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// postgresDSNVariable names the environment variable with the connection URL of
+// an empty PostgreSQL database with PostGIS for the postgres tests.
+const postgresDSNVariable = "GEOCHRONO_TEST_POSTGRES_DSN"
+
+// TestSQLDatabases runs the store checks against real databases through the
+// drivers linked in by the sqlite and postgres build tags.
+func TestSQLDatabases(t *testing.T) {
+	t.Run(BackendSQLite, func(t *testing.T) {
+		if !hasDriver(sqliteDialect.driver) {
+			t.Skip("built without -tags sqlite")
+		}
+		database, err := NewSQL(BackendSQLite, "", filepath.Join(t.TempDir(), "tracks.db"))
+		if err != nil {
+			t.Fatalf("NewSQL() error = %v", err)
+		}
+		defer database.Close()
+		testStore(t, database)
+	})
+
+	t.Run(BackendPostgres, func(t *testing.T) {
+		if !hasDriver(postgresDialect.driver) {
+			t.Skip("built without -tags postgres")
+		}
+		dsn := os.Getenv(postgresDSNVariable)
+		if dsn == "" {
+			t.Skip(postgresDSNVariable + " is not set")
+		}
+		database, err := NewSQL(BackendPostgres, "", dsn)
+		if err != nil {
+			t.Fatalf("NewSQL() error = %v", err)
+		}
+		defer database.Close()
+		if err := database.EnablePostGIS(); err != nil {
+			t.Fatalf("EnablePostGIS() error = %v", err)
+		}
+		testStore(t, database)
+
+		var near int
+		err = database.db.QueryRow(`SELECT count(*) FROM points
+			WHERE ST_DWithin(geom::geography, ST_SetSRID(ST_MakePoint(-122.4194, 37.7749), 4326)::geography, 100)`).Scan(&near)
+		if err != nil || near != 1 {
+			t.Errorf("points within 100 m of the first one = %d, %v, want 1", near, err)
+		}
+	})
+}
@@ -0,0 +1,6 @@
+//go:build sqlite
+
+package store
+
+// Link the pure Go SQLite driver, registered as "sqlite", into the sqlite backend.
+import _ "modernc.org/sqlite"
@@ -0,0 +1,190 @@
+// Package store keeps uploaded GPS tracks for serve mode's track API.
+//
+// @title Track Storage Package
+// @version 1.0
+// @description Saves and loads uploaded tracks by ID
+// @description Backends are pluggable behind the Store interface
+//
+// Features:
+// - Store interface for track persistence
+// - In-memory backend for previews and tests
+// - Directory backend keeping one JSON file per track
+// - SQLite and PostgreSQL backends through database/sql drivers
+// - Backend registry selected from configuration
+// - Incremental merging of new points into stored tracks
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// DefaultBackend is the storage backend used when none is configured.
+const DefaultBackend = BackendMemory
+
+// ErrNotFound is returned when no track has the requested ID.
+var ErrNotFound = errors.New("track not found")
+
+// idPattern matches the IDs handed out by NewID, so other strings never reach a
+// backend, for example as part of a file path.
+var idPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// Track is an uploaded GPS track.
+//
+// @struct Track
+// @description Stored track with its points and upload metadata
+// @property ID string Unique track ID from NewID
+// @property Name string Display name, used as the map title
+// @property Created time.Time When the track was uploaded
+// @property Owner string Name of the uploading server user (empty without users)
+// @property Public bool Whether anyone may view the track's map and statistics
+// @property Points gps.Points Uploaded GPS points in track order
+type Track struct {
+	ID      string     `json:"id"`              // @field ID Unique track ID from NewID
+	Name    string     `json:"name"`            // @field Name Display name, used as the map title
+	Created time.Time  `json:"created"`         // @field Created When the track was uploaded
+	Owner   string     `json:"owner,omitempty"` // @field Owner Name of the uploading server user
+	Public  bool       `json:"public"`          // @field Public Whether anyone may view the track
+	Points  gps.Points `json:"points"`          // @field Points Uploaded GPS points in track order
+}
+
+// Store saves and loads tracks. Implementations must be safe for concurrent use.
+type Store interface {
+	// Save stores a track under its ID, replacing an earlier version.
+	Save(track *Track) error
+	// Load returns the track with the given ID, or ErrNotFound.
+	Load(id string) (*Track, error)
+	// List returns every track without its points, oldest first.
+	List() ([]*Track, error)
+	// Merge appends the points the track with the given ID does not hold yet,
+	// keeping its stored points as they are, and returns how many were added.
+	// Returns ErrNotFound if there is no such track.
+	Merge(id string, points gps.Points) (int, error)
+}
+
+// Factory creates a Store from the storage configuration.
+type Factory func(cfg *config.StorageConfig) (Store, error)
+
+// backends maps backend names to their factories.
+var backends = map[string]Factory{}
+
+// Register makes a storage backend available under a name for configuration.
+// Registering the same name twice replaces the earlier factory.
+//
+// @function Register
+// @description Adds a pluggable track storage backend
+// @param name string Backend name used in server.storage.backend
+// @param factory Factory Constructor for the backend
+// @example store.Register("custom", newCustomStore)
+func Register(name string, factory Factory) {
+	backends[name] = factory
+}
+
+// Backends returns the names of all registered backends in sorted order.
+func Backends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New opens the Store configured in server.storage.
+//
+// @function New
+// @description Looks up and constructs the configured storage backend
+// @param cfg *config.StorageConfig Storage configuration
+// @return Store Opened backend (DefaultBackend when none is configured)
+// @return error Error if the backend is unknown or cannot be opened
+// @example tracks, err := store.New(&cfg.Server.Storage)
+func New(cfg *config.StorageConfig) (Store, error) {
+	name := cfg.Backend
+	if name == "" {
+		name = DefaultBackend
+	}
+
+	factory, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown storage backend %q (available: %v)", name, Backends())
+	}
+	return factory(cfg)
+}
+
+// NewID returns a random track ID.
+func NewID() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("cannot generate track ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// ValidID reports whether id has the form of the IDs handed out by NewID.
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// summary returns a copy of track without its points, as returned by List.
+func summary(track *Track) *Track {
+	return &Track{ID: track.ID, Name: track.Name, Created: track.Created, Owner: track.Owner, Public: track.Public}
+}
+
+// sortTracks orders tracks oldest first, by ID among tracks uploaded together.
+func sortTracks(tracks []*Track) {
+	sort.Slice(tracks, func(i, j int) bool {
+		if !tracks[i].Created.Equal(tracks[j].Created) {
+			return tracks[i].Created.Before(tracks[j].Created)
+		}
+		return tracks[i].ID < tracks[j].ID
+	})
+}
+
+// pointKey identifies a point when merging: a track never holds two points of
+// the same user recorded at the same time and place.
+type pointKey struct {
+	recorded  int64 // Timestamp in Unix nanoseconds
+	latitude  float64
+	longitude float64
+	user      string
+}
+
+// keyOf returns the merge key of a point.
+func keyOf(p gps.Point) pointKey {
+	return pointKey{recorded: p.Timestamp.UnixNano(), latitude: p.Latitude, longitude: p.Longitude, user: p.User}
+}
+
+// unseen returns the points whose keys are not in seen, in their order and
+// without repeats, adding their keys to seen.
+func unseen(seen map[pointKey]bool, points gps.Points) gps.Points {
+	var fresh gps.Points
+	for _, p := range points {
+		if key := keyOf(p); !seen[key] {
+			seen[key] = true
+			fresh = append(fresh, p)
+		}
+	}
+	return fresh
+}
+
+// merged returns a copy of track with the points it does not hold yet appended,
+// and how many were added, for backends that rewrite whole tracks.
+func merged(track *Track, points gps.Points) (*Track, int) {
+	seen := make(map[pointKey]bool, len(track.Points))
+	for _, p := range track.Points {
+		seen[keyOf(p)] = true
+	}
+	fresh := unseen(seen, points)
+
+	copied := *track
+	copied.Points = append(append(make(gps.Points, 0, len(track.Points)+len(fresh)), track.Points...), fresh...)
+	return &copied, len(fresh)
+}
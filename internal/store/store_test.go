@@ -0,0 +1,127 @@
+package store
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestNew(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "tracks")
+	tests := []struct {
+		name    string
+		cfg     config.StorageConfig
+		wantErr bool
+	}{
+		{name: "default is memory", cfg: config.StorageConfig{}},
+		{name: "memory", cfg: config.StorageConfig{Backend: BackendMemory}},
+		{name: "dir", cfg: config.StorageConfig{Backend: BackendDir, Dir: dir}},
+		{name: "dir without path", cfg: config.StorageConfig{Backend: BackendDir}, wantErr: true},
+		{name: "sqlite", cfg: config.StorageConfig{Backend: BackendSQLite, Driver: fakeDriverName, DSN: t.Name()}},
+		{name: "sqlite without driver", cfg: config.StorageConfig{Backend: BackendSQLite, Driver: "missing", DSN: "tracks.db"}, wantErr: true},
+		{name: "postgres with postgis", cfg: config.StorageConfig{Backend: BackendPostgres, Driver: fakeDriverName, DSN: t.Name(), PostGIS: true}},
+		{name: "sqlite with postgis", cfg: config.StorageConfig{Backend: BackendSQLite, Driver: fakeDriverName, DSN: t.Name(), PostGIS: true}, wantErr: true},
+		{name: "unknown backend", cfg: config.StorageConfig{Backend: "mongodb"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(&tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStores(t *testing.T) {
+	dir, err := NewDir(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDir() error = %v", err)
+	}
+
+	database, err := NewSQL(BackendSQLite, fakeDriverName, t.Name())
+	if err != nil {
+		t.Fatalf("NewSQL() error = %v", err)
+	}
+
+	for name, tracks := range map[string]Store{BackendMemory: NewMemory(), BackendDir: dir, BackendSQLite: database} {
+		t.Run(name, func(t *testing.T) { testStore(t, tracks) })
+	}
+}
+
+// testStore checks that a store keeps tracks as it should. The store must be empty.
+func testStore(t *testing.T, tracks Store) {
+	t.Helper()
+	created := time.Date(2025, 10, 28, 18, 0, 0, 0, time.UTC)
+	first := &Track{ID: "00000000000000aa", Name: "Morning", Created: created, Points: gps.Points{
+		{Timestamp: created.Add(-8 * time.Hour), Latitude: 37.7749, Longitude: -122.4194, Title: "Start", User: "alice"},
+		{Timestamp: created.Add(-7 * time.Hour), Latitude: 37.7849, Longitude: -122.4094, Elevation: 12},
+	}}
+	second := &Track{ID: "00000000000000bb", Name: "Evening", Created: created.Add(time.Hour), Owner: "alice"}
+	for _, track := range []*Track{second, first} {
+		if err := tracks.Save(track); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	got, err := tracks.Load(first.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got.Name != "Morning" || len(got.Points) != 2 || got.Points[0].User != "alice" || got.Points[1].Elevation != 12 || !got.Points[0].Timestamp.Equal(first.Points[0].Timestamp) {
+		t.Errorf("Load() = %+v, want %+v", got, first)
+	}
+
+	list, err := tracks.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || list[0].ID != first.ID || list[1].ID != second.ID || list[0].Points != nil {
+		t.Errorf("List() = %+v, want Morning then Evening without points", list)
+	}
+
+	for _, id := range []string{"00000000000000cc", "../../etc/passwd"} {
+		if _, err := tracks.Load(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Load(%q) error = %v, want ErrNotFound", id, err)
+		}
+	}
+	if err := tracks.Save(&Track{ID: "../escape"}); err == nil {
+		t.Error("Save() accepted an invalid ID")
+	}
+
+	later := gps.Point{Timestamp: created.Add(-6 * time.Hour), Latitude: 37.7949, Longitude: -122.3994, User: "alice"}
+	added, err := tracks.Merge(first.ID, gps.Points{first.Points[1], later, later})
+	if err != nil || added != 1 {
+		t.Fatalf("Merge() = %d, %v, want 1 point added", added, err)
+	}
+	if len(got.Points) != 2 {
+		t.Errorf("Merge() changed an earlier Load to %d points", len(got.Points))
+	}
+	merged, err := tracks.Load(first.ID)
+	if err != nil {
+		t.Fatalf("Load() after Merge() error = %v", err)
+	}
+	if len(merged.Points) != 3 || merged.Points[0].Title != "Start" || merged.Points[1].Elevation != 12 || !merged.Points[2].Timestamp.Equal(later.Timestamp) {
+		t.Errorf("Load() after Merge() = %+v, want the stored points followed by the new one", merged.Points)
+	}
+	if added, err := tracks.Merge(first.ID, merged.Points); err != nil || added != 0 {
+		t.Errorf("Merge() of stored points = %d, %v, want none added", added, err)
+	}
+	if _, err := tracks.Merge("00000000000000cc", gps.Points{later}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Merge() of a missing track error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestNewID(t *testing.T) {
+	a, err := NewID()
+	if err != nil {
+		t.Fatalf("NewID() error = %v", err)
+	}
+	b, _ := NewID()
+	if !ValidID(a) || !ValidID(b) || a == b {
+		t.Errorf("NewID() = %q, %q, want two different valid IDs", a, b)
+	}
+}
@@ -0,0 +1,291 @@
+// Package tiles bundles the map tiles around a GPS track for offline maps.
+//
+// @title Offline Tiles Package
+// @version 1.0
+// @description Downloads or reads the XYZ raster tiles covering a track
+// @description Returns them as data URIs for embedding in a self-contained page
+//
+// Features:
+// - Tiles covering the track's bounds, with a one-tile margin, over a zoom range
+// - Tile servers over HTTP(S) or pre-fetched tile directories on disk
+// - A tile limit guarding against accidental bulk downloads
+// - Progress reporting in bytes fetched
+package tiles
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/progress"
+)
+
+// Defaults for unset offline tile settings.
+const (
+	DefaultMinZoom  = 10
+	DefaultMaxZoom  = 16
+	DefaultMaxTiles = 500
+)
+
+// userAgent identifies tile requests, as tile usage policies such as
+// OpenStreetMap's require.
+const userAgent = "geo-chrono (offline tile bundling)"
+
+// Tile identifies a map tile in the XYZ scheme.
+//
+// @struct Tile
+// @description Position of a tile in the Web Mercator tile grid
+// @property Z int Zoom level
+// @property X int Column from the antimeridian eastwards
+// @property Y int Row from the north edge southwards
+type Tile struct {
+	Z int // @field Z Zoom level
+	X int // @field X Column from the antimeridian eastwards
+	Y int // @field Y Row from the north edge southwards
+}
+
+// Key returns the tile's "z/x/y" key used by the page to look up bundled tiles.
+func (t Tile) Key() string {
+	return fmt.Sprintf("%d/%d/%d", t.Z, t.X, t.Y)
+}
+
+// URL fills in the {z}, {x}, {y}, and {s} placeholders of a tile URL template,
+// using the first subdomain "a" for {s}.
+func (t Tile) URL(template string) string {
+	return strings.NewReplacer(
+		"{z}", strconv.Itoa(t.Z),
+		"{x}", strconv.Itoa(t.X),
+		"{y}", strconv.Itoa(t.Y),
+		"{s}", "a",
+	).Replace(template)
+}
+
+// tileAt returns the tile containing a coordinate at a zoom level, projected as
+// static map images are, with latitudes clamped to gps.MaxMercatorLatitude.
+func tileAt(lat, lng float64, zoom int) Tile {
+	x, y := gps.LatLngToPixel(lat, lng, zoom)
+	last := 1<<zoom - 1
+	return Tile{Z: zoom, X: max(0, min(last, int(x/gps.TileSize))), Y: max(0, min(last, int(y/gps.TileSize)))}
+}
+
+// tileRange returns the corner tiles of a bounding box at a zoom level, widened
+// by a margin of one tile on every side so the map can be panned a little past
+// the track.
+func tileRange(minLat, maxLat, minLng, maxLng float64, zoom int) (northWest, southEast Tile) {
+	last := 1<<zoom - 1
+	northWest, southEast = tileAt(maxLat, minLng, zoom), tileAt(minLat, maxLng, zoom)
+	northWest.X, northWest.Y = max(0, northWest.X-1), max(0, northWest.Y-1)
+	southEast.X, southEast.Y = min(last, southEast.X+1), min(last, southEast.Y+1)
+	return northWest, southEast
+}
+
+// Count returns how many tiles Covering returns for the same arguments, from the
+// corner tiles of each zoom level alone, so large ranges can be refused without
+// listing their tiles.
+//
+// @function Count
+// @description Counts the tiles around a track over a zoom range
+// @param points gps.Points GPS points
+// @param minZoom int Lowest zoom level
+// @param maxZoom int Highest zoom level
+// @return int Number of tiles (0 without points)
+func Count(points gps.Points, minZoom, maxZoom int) int {
+	if points.IsEmpty() {
+		return 0
+	}
+	minLat, maxLat, minLng, maxLng := points.Bounds()
+	count := 0
+	for zoom := minZoom; zoom <= maxZoom; zoom++ {
+		northWest, southEast := tileRange(minLat, maxLat, minLng, maxLng, zoom)
+		count += (southEast.X - northWest.X + 1) * (southEast.Y - northWest.Y + 1)
+	}
+	return count
+}
+
+// Covering returns the tiles covering the bounds of the points at each zoom level
+// from minZoom to maxZoom, with a margin of one tile on every side so the map can
+// be panned a little past the track.
+//
+// @function Covering
+// @description Lists the tiles around a track over a zoom range
+// @param points gps.Points GPS points
+// @param minZoom int Lowest zoom level
+// @param maxZoom int Highest zoom level
+// @return []Tile Tiles ordered by zoom level, row, and column (nil without points)
+// @note Check Count first for zoom ranges that may cover many tiles
+func Covering(points gps.Points, minZoom, maxZoom int) []Tile {
+	count := Count(points, minZoom, maxZoom)
+	if count == 0 {
+		return nil
+	}
+
+	minLat, maxLat, minLng, maxLng := points.Bounds()
+	tiles := make([]Tile, 0, count)
+	for zoom := minZoom; zoom <= maxZoom; zoom++ {
+		northWest, southEast := tileRange(minLat, maxLat, minLng, maxLng, zoom)
+		for y := northWest.Y; y <= southEast.Y; y++ {
+			for x := northWest.X; x <= southEast.X; x++ {
+				tiles = append(tiles, Tile{Z: zoom, X: x, Y: y})
+			}
+		}
+	}
+	return tiles
+}
+
+// Bundle holds the tiles embedded in an offline page.
+//
+// @struct Bundle
+// @description Tile images by key with the zoom range they cover
+// @property Tiles map[string]string Tile images as data URIs by "z/x/y" key
+// @property MinZoom int Lowest bundled zoom level
+// @property MaxZoom int Highest bundled zoom level
+type Bundle struct {
+	Tiles   map[string]string // @field Tiles Tile data URIs by "z/x/y" key
+	MinZoom int               // @field MinZoom Lowest bundled zoom level
+	MaxZoom int               // @field MaxZoom Highest bundled zoom level
+}
+
+// Client fetches tiles from a tile server or a directory of pre-fetched tiles.
+//
+// @struct Client
+// @description Tile source and limits of an offline bundle
+// @property URL string Tile URL template, or a file path template for tiles on disk
+// @property Client *http.Client HTTP client used for tile servers
+// @property MinZoom int Lowest zoom level to bundle
+// @property MaxZoom int Highest zoom level to bundle
+// @property MaxTiles int Largest number of tiles to bundle
+// @property Progress *progress.Bar Bar counting the tile bytes fetched (nil for none)
+type Client struct {
+	URL      string        // @field URL Tile URL or file path template
+	Client   *http.Client  // @field Client HTTP client for tile servers
+	MinZoom  int           // @field MinZoom Lowest zoom level to bundle
+	MaxZoom  int           // @field MaxZoom Highest zoom level to bundle
+	MaxTiles int           // @field MaxTiles Largest number of tiles to bundle
+	Progress *progress.Bar // @field Progress Bar counting the tile bytes fetched
+}
+
+// New creates a Client for a tile URL template from configuration, applying the
+// default zoom range and tile limit. An unset min_zoom defaults to
+// DefaultMinZoom, while min_zoom: 0 bundles from the whole-world zoom level.
+//
+// @function New
+// @description Creates an offline tile client
+// @param tileURL string Tile URL template with {z}, {x}, {y} placeholders, or a file path template
+// @param cfg *config.OfflineTilesConfig Zoom range and tile limit
+// @return *Client Client for the tile source
+// @return error Error if the template lacks a placeholder or the zoom range is empty
+// @example client, err := tiles.New(cfg.Map.TileURL, &cfg.Map.OfflineTiles)
+func New(tileURL string, cfg *config.OfflineTilesConfig) (*Client, error) {
+	client := &Client{
+		URL:      tileURL,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+		MinZoom:  DefaultMinZoom,
+		MaxZoom:  cfg.MaxZoom,
+		MaxTiles: cfg.MaxTiles,
+	}
+	if cfg.MinZoom != nil {
+		client.MinZoom = *cfg.MinZoom
+	}
+	if client.MaxZoom == 0 {
+		client.MaxZoom = DefaultMaxZoom
+	}
+	if client.MaxTiles == 0 {
+		client.MaxTiles = DefaultMaxTiles
+	}
+
+	for _, placeholder := range []string{"{z}", "{x}", "{y}"} {
+		if !strings.Contains(tileURL, placeholder) {
+			return nil, fmt.Errorf("tile URL %q has no %s placeholder", tileURL, placeholder)
+		}
+	}
+	if client.MinZoom > client.MaxZoom {
+		return nil, fmt.Errorf("offline tiles min_zoom %d is above max_zoom %d", client.MinZoom, client.MaxZoom)
+	}
+	return client, nil
+}
+
+// Fetch bundles the tiles covering the track. Tiles the source does not have,
+// such as those missing from a partial pre-fetched directory, are left out and
+// drawn blank.
+//
+// @method Fetch
+// @description Downloads or reads the tiles around a track
+// @param ctx context.Context Cancels the tiles still to be fetched
+// @param points gps.Points GPS points of the track
+// @return *Bundle Tiles as data URIs with the bundled zoom range
+// @return error Error if more than MaxTiles tiles are needed or a tile cannot be fetched
+// @note Tiles are fetched one at a time to go easy on public tile servers
+func (c *Client) Fetch(ctx context.Context, points gps.Points) (*Bundle, error) {
+	if count := Count(points, c.MinZoom, c.MaxZoom); count > c.MaxTiles {
+		return nil, fmt.Errorf("offline tiles need %d tiles at zoom %d-%d, more than max_tiles %d (lower max_zoom or raise max_tiles)",
+			count, c.MinZoom, c.MaxZoom, c.MaxTiles)
+	}
+
+	covering := Covering(points, c.MinZoom, c.MaxZoom)
+	bundle := &Bundle{Tiles: make(map[string]string, len(covering)), MinZoom: c.MinZoom, MaxZoom: c.MaxZoom}
+	for _, tile := range covering {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		data, contentType, err := c.fetchTile(ctx, tile.URL(c.URL))
+		if err != nil {
+			return nil, fmt.Errorf("cannot fetch tile %s: %w", tile.Key(), err)
+		}
+		c.Progress.Add(int64(len(data)))
+		if data == nil {
+			continue
+		}
+		bundle.Tiles[tile.Key()] = "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+	}
+	return bundle, nil
+}
+
+// fetchTile returns the image and content type of one tile, or nil data when the
+// source has no such tile. URLs without an http or https scheme are file paths.
+func (c *Client) fetchTile(ctx context.Context, location string) ([]byte, string, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		data, err := os.ReadFile(strings.TrimPrefix(location, "file://"))
+		if os.IsNotExist(err) {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return data, http.DetectContentType(data), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("tile request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("tile server error: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot read tile: %w", err)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
@@ -0,0 +1,190 @@
+package tiles
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestTileAt(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		zoom     int
+		want     Tile
+	}{
+		{0, 0, 0, Tile{0, 0, 0}},
+		{37.7749, -122.4194, 10, Tile{10, 163, 395}},
+		{51.5074, -0.1278, 12, Tile{12, 2046, 1362}},
+		{89.9, 180, 2, Tile{2, 3, 0}}, // clamped to the grid
+		{-89.9, -180, 2, Tile{2, 0, 3}},
+	}
+	for _, tt := range tests {
+		if got := tileAt(tt.lat, tt.lng, tt.zoom); got != tt.want {
+			t.Errorf("tileAt(%g, %g, %d) = %+v, want %+v", tt.lat, tt.lng, tt.zoom, got, tt.want)
+		}
+	}
+}
+
+func TestCovering(t *testing.T) {
+	if got := Covering(nil, 10, 12); got != nil {
+		t.Errorf("Covering(nil) = %v, want nil", got)
+	}
+
+	points := gps.Points{{Latitude: 37.7749, Longitude: -122.4194}}
+	covering := Covering(points, 10, 11)
+	if len(covering) != 18 {
+		t.Fatalf("Covering() = %d tiles, want 3x3 at two zoom levels", len(covering))
+	}
+	if covering[0] != (Tile{10, 162, 394}) || covering[8] != (Tile{10, 164, 396}) {
+		t.Errorf("Covering() zoom 10 spans %+v to %+v, want a one-tile margin", covering[0], covering[8])
+	}
+
+	// The margin stops at the edges of the world
+	if got := Covering(points, 0, 0); len(got) != 1 {
+		t.Errorf("Covering() at zoom 0 = %v, want the single world tile", got)
+	}
+}
+
+func TestCount(t *testing.T) {
+	if got := Count(nil, 10, 12); got != 0 {
+		t.Errorf("Count(nil) = %d, want 0", got)
+	}
+
+	// Count matches Covering without listing the tiles
+	points := gps.Points{{Latitude: 37.7749, Longitude: -122.4194}, {Latitude: 37.3382, Longitude: -121.8863}}
+	for _, zooms := range [][2]int{{0, 0}, {0, 3}, {10, 11}, {12, 14}} {
+		if got, want := Count(points, zooms[0], zooms[1]), len(Covering(points, zooms[0], zooms[1])); got != want {
+			t.Errorf("Count(%d-%d) = %d, want %d", zooms[0], zooms[1], got, want)
+		}
+	}
+
+	// A whole-world track at a deep zoom level is counted, not listed
+	world := gps.Points{{Latitude: -89, Longitude: -180}, {Latitude: 89, Longitude: 180}}
+	if got := Count(world, 19, 19); got != 1<<38 {
+		t.Errorf("Count(world, 19) = %d, want every tile of zoom 19", got)
+	}
+}
+
+func TestTileURL(t *testing.T) {
+	tile := Tile{Z: 12, X: 2046, Y: 1362}
+	got := tile.URL("https://{s}.tile.example.org/{z}/{x}/{y}.png")
+	if got != "https://a.tile.example.org/12/2046/1362.png" {
+		t.Errorf("URL() = %s", got)
+	}
+	if tile.Key() != "12/2046/1362" {
+		t.Errorf("Key() = %s", tile.Key())
+	}
+}
+
+func TestNew(t *testing.T) {
+	client, err := New("https://tile.example.org/{z}/{x}/{y}.png", &config.OfflineTilesConfig{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if client.MinZoom != DefaultMinZoom || client.MaxZoom != DefaultMaxZoom || client.MaxTiles != DefaultMaxTiles {
+		t.Errorf("New() = zoom %d-%d, %d tiles, want the defaults", client.MinZoom, client.MaxZoom, client.MaxTiles)
+	}
+
+	if _, err := New("https://tile.example.org/{z}/{x}.png", &config.OfflineTilesConfig{}); err == nil {
+		t.Error("New() accepted a tile URL without {y}")
+	}
+	minZoom := 17
+	if _, err := New("https://tile.example.org/{z}/{x}/{y}.png", &config.OfflineTilesConfig{MinZoom: &minZoom}); err == nil {
+		t.Error("New() accepted min_zoom above the default max_zoom")
+	}
+
+	// min_zoom: 0 is kept rather than replaced by the default
+	minZoom = 0
+	if client, err := New("https://tile.example.org/{z}/{x}/{y}.png", &config.OfflineTilesConfig{MinZoom: &minZoom}); err != nil || client.MinZoom != 0 {
+		t.Errorf("New() with min_zoom 0 = %+v, %v, want zoom from 0", client, err)
+	}
+}
+
+func TestFetchServer(t *testing.T) {
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.UserAgent())
+		if r.URL.Path == "/10/163/395.png" {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("center"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL + "/{z}/{x}/{y}.png", Client: server.Client(), MinZoom: 10, MaxZoom: 10, MaxTiles: 9}
+	bundle, err := client.Fetch(context.Background(), gps.Points{{Latitude: 37.7749, Longitude: -122.4194}})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(userAgents) != 9 || userAgents[0] != userAgent {
+		t.Errorf("Fetch() made %d requests with User-Agent %q", len(userAgents), userAgents)
+	}
+	if len(bundle.Tiles) != 1 {
+		t.Fatalf("Fetch() bundled %d tiles, want only the one the server has", len(bundle.Tiles))
+	}
+	if got := bundle.Tiles["10/163/395"]; got != "data:image/png;base64,Y2VudGVy" {
+		t.Errorf("bundled tile = %s", got)
+	}
+	if bundle.MinZoom != 10 || bundle.MaxZoom != 10 {
+		t.Errorf("bundle zoom = %d-%d, want 10-10", bundle.MinZoom, bundle.MaxZoom)
+	}
+}
+
+func TestFetchServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL + "/{z}/{x}/{y}.png", Client: server.Client(), MinZoom: 10, MaxZoom: 10, MaxTiles: 9}
+	if _, err := client.Fetch(context.Background(), gps.Points{{Latitude: 37.7749, Longitude: -122.4194}}); err == nil {
+		t.Error("Fetch() ignored a tile server error")
+	}
+}
+
+func TestFetchTooManyTiles(t *testing.T) {
+	client := &Client{URL: "/tiles/{z}/{x}/{y}.png", MinZoom: 10, MaxZoom: 12, MaxTiles: 20}
+	_, err := client.Fetch(context.Background(), gps.Points{{Latitude: 37.7749, Longitude: -122.4194}})
+	if err == nil || !strings.Contains(err.Error(), "max_tiles 20") {
+		t.Errorf("Fetch() error = %v, want the tile limit", err)
+	}
+}
+
+func TestFetchDirectory(t *testing.T) {
+	dir := t.TempDir()
+	png := []byte("\x89PNG\r\n\x1a\n")
+	if err := os.MkdirAll(filepath.Join(dir, "10", "163"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "10", "163", "395.png"), png, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &Client{URL: "file://" + dir + "/{z}/{x}/{y}.png", MinZoom: 10, MaxZoom: 10, MaxTiles: 9}
+	bundle, err := client.Fetch(context.Background(), gps.Points{{Latitude: 37.7749, Longitude: -122.4194}})
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(bundle.Tiles) != 1 || !strings.HasPrefix(bundle.Tiles["10/163/395"], "data:image/png;base64,") {
+		t.Errorf("Fetch() = %v, want the one pre-fetched PNG tile", bundle.Tiles)
+	}
+}
+
+func TestFetchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := &Client{URL: "file://" + t.TempDir() + "/{z}/{x}/{y}.png", MinZoom: 10, MaxZoom: 10, MaxTiles: 9}
+	if _, err := client.Fetch(ctx, gps.Points{{Latitude: 37.7749, Longitude: -122.4194}}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Fetch() after cancel error = %v, want context.Canceled", err)
+	}
+}
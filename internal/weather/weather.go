@@ -0,0 +1,261 @@
+// Package weather provides historical weather lookups for GPS tracks.
+//
+// @title Historical Weather Package
+// @version 1.0
+// @description Queries an Open-Meteo compatible archive API for the hourly weather of a track
+// @description Reports the temperature and conditions of each hour the track was recorded
+//
+// Features:
+// - Hourly temperature and WMO weather code for the track's dates and location
+// - Celsius or Fahrenheit temperatures
+// - No API key required for the public Open-Meteo archive
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+// DefaultURL is the public Open-Meteo historical weather API, used when no URL is
+// configured.
+const DefaultURL = "https://archive-api.open-meteo.com/v1/archive"
+
+// Temperature units reported by the API.
+const (
+	Celsius    = "°C"
+	Fahrenheit = "°F"
+)
+
+// hourLayout is the format of the hourly timestamps returned by the API, in UTC.
+const hourLayout = "2006-01-02T15:04"
+
+// Observation is the weather of one hour.
+//
+// @struct Observation
+// @description Hourly temperature and conditions at the track's location
+// @property Time time.Time Start of the hour in UTC
+// @property Temperature float64 Air temperature two meters above ground
+// @property Code int WMO weather interpretation code
+type Observation struct {
+	Time        time.Time // @field Time Start of the hour in UTC
+	Temperature float64   // @field Temperature Air temperature in the report's unit
+	Code        int       // @field Code WMO weather interpretation code
+}
+
+// Conditions describes the observation's weather code, such as "Light rain".
+func (o Observation) Conditions() string {
+	return Conditions(o.Code)
+}
+
+// Report holds the hourly weather for the period of a track.
+//
+// @struct Report
+// @description Hourly observations covering the days a track was recorded
+// @property Hourly []Observation Observations in chronological order
+// @property Unit string Temperature unit of the observations (°C or °F)
+type Report struct {
+	Hourly []Observation // @field Hourly Observations in chronological order
+	Unit   string        // @field Unit Temperature unit of the observations
+}
+
+// At returns the observation of the hour containing t.
+func (r *Report) At(t time.Time) (Observation, bool) {
+	hour := t.UTC().Truncate(time.Hour)
+	for _, observation := range r.Hourly {
+		if observation.Time.Equal(hour) {
+			return observation, true
+		}
+	}
+	return Observation{}, false
+}
+
+// Between returns the observations of the hours from the one containing from to
+// the one containing to.
+func (r *Report) Between(from, to time.Time) []Observation {
+	first, last := from.UTC().Truncate(time.Hour), to.UTC().Truncate(time.Hour)
+	var found []Observation
+	for _, observation := range r.Hourly {
+		if !observation.Time.Before(first) && !observation.Time.After(last) {
+			found = append(found, observation)
+		}
+	}
+	return found
+}
+
+// Client looks up historical weather from an Open-Meteo compatible archive API.
+//
+// @struct Client
+// @description Historical weather lookups over HTTP
+// @property URL string Archive API endpoint
+// @property Client *http.Client HTTP client used for requests
+type Client struct {
+	URL    string       // @field URL Archive API endpoint
+	Client *http.Client // @field Client HTTP client for API requests
+}
+
+// New creates a Client from configuration.
+//
+// @function New
+// @description Creates a historical weather client
+// @param cfg *config.WeatherConfig Weather settings
+// @return *Client Client for the configured API (the public Open-Meteo archive by default)
+// @return error Error if the URL is invalid
+// @example client, err := weather.New(&cfg.Weather)
+func New(cfg *config.WeatherConfig) (*Client, error) {
+	client := &Client{URL: cfg.URL, Client: &http.Client{Timeout: 30 * time.Second}}
+	if client.URL == "" {
+		client.URL = DefaultURL
+	}
+	if _, err := url.Parse(client.URL); err != nil {
+		return nil, fmt.Errorf("invalid weather URL %s: %w", client.URL, err)
+	}
+	return client, nil
+}
+
+// archiveResponse is the JSON body returned by the archive API.
+type archiveResponse struct {
+	Error  bool   `json:"error"`
+	Reason string `json:"reason"`
+	Hourly struct {
+		Time        []string   `json:"time"`
+		Temperature []*float64 `json:"temperature_2m"`
+		Code        []*int     `json:"weather_code"`
+	} `json:"hourly"`
+}
+
+// Lookup fetches the hourly weather for the days the track was recorded, at the
+// center of the track.
+//
+// @method Lookup
+// @description Queries the archive API for the weather during a track
+// @param ctx context.Context Cancels the request
+// @param points gps.Points GPS points; untimed points are ignored
+// @param fahrenheit bool Report temperatures in Fahrenheit instead of Celsius
+// @return *Report Hourly weather (without observations when no point is timed)
+// @return error Error if the request fails or the API reports an error
+// @note A single location is queried, so long journeys get the weather at their center
+// @note Hours the archive has no data for yet, such as the last few days, are left out
+func (c *Client) Lookup(ctx context.Context, points gps.Points, fahrenheit bool) (*Report, error) {
+	timed := points.Filter(func(p gps.Point) bool { return !p.Timestamp.IsZero() })
+	report := &Report{Unit: Celsius}
+	if fahrenheit {
+		report.Unit = Fahrenheit
+	}
+	if len(timed) == 0 {
+		return report, nil
+	}
+
+	start, end := timed[0].Timestamp.UTC(), timed[0].Timestamp.UTC()
+	for _, point := range timed {
+		t := point.Timestamp.UTC()
+		if t.Before(start) {
+			start = t
+		}
+		if t.After(end) {
+			end = t
+		}
+	}
+	lat, lng := timed.SphericalCenter()
+
+	query := url.Values{}
+	query.Set("latitude", fmt.Sprintf("%.4f", lat))
+	query.Set("longitude", fmt.Sprintf("%.4f", lng))
+	query.Set("start_date", start.Format("2006-01-02"))
+	query.Set("end_date", end.Format("2006-01-02"))
+	query.Set("hourly", "temperature_2m,weather_code")
+	query.Set("timezone", "GMT")
+	if fahrenheit {
+		query.Set("temperature_unit", "fahrenheit")
+	}
+	separator := "?"
+	if strings.Contains(c.URL, "?") {
+		separator = "&"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL+separator+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("weather request failed: %w", err)
+	}
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("weather request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body archiveResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("cannot decode weather response: %w", err)
+	}
+	if body.Error || resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("weather API error: %s %s", resp.Status, body.Reason)
+	}
+
+	for i, value := range body.Hourly.Time {
+		if i >= len(body.Hourly.Temperature) || i >= len(body.Hourly.Code) {
+			break
+		}
+		// Missing values are null, as for hours not yet in the archive
+		if body.Hourly.Temperature[i] == nil || body.Hourly.Code[i] == nil {
+			continue
+		}
+		hour, err := time.Parse(hourLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weather time %q: %w", value, err)
+		}
+		report.Hourly = append(report.Hourly, Observation{
+			Time:        hour,
+			Temperature: *body.Hourly.Temperature[i],
+			Code:        *body.Hourly.Code[i],
+		})
+	}
+	return report, nil
+}
+
+// conditions describes the WMO weather interpretation codes used by Open-Meteo.
+var conditions = map[int]string{
+	0:  "Clear sky",
+	1:  "Mainly clear",
+	2:  "Partly cloudy",
+	3:  "Overcast",
+	45: "Fog",
+	48: "Rime fog",
+	51: "Light drizzle",
+	53: "Drizzle",
+	55: "Dense drizzle",
+	56: "Freezing drizzle",
+	57: "Freezing drizzle",
+	61: "Light rain",
+	63: "Rain",
+	65: "Heavy rain",
+	66: "Freezing rain",
+	67: "Freezing rain",
+	71: "Light snow",
+	73: "Snow",
+	75: "Heavy snow",
+	77: "Snow grains",
+	80: "Rain showers",
+	81: "Rain showers",
+	82: "Violent rain showers",
+	85: "Snow showers",
+	86: "Heavy snow showers",
+	95: "Thunderstorm",
+	96: "Thunderstorm with hail",
+	99: "Thunderstorm with hail",
+}
+
+// Conditions describes a WMO weather interpretation code, such as "Overcast" for 3.
+// Unknown codes are described as "Unknown".
+func Conditions(code int) string {
+	if description, ok := conditions[code]; ok {
+		return description
+	}
+	return "Unknown"
+}
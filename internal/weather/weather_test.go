@@ -0,0 +1,109 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+)
+
+func TestNew(t *testing.T) {
+	client, err := New(&config.WeatherConfig{})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if client.URL != DefaultURL {
+		t.Errorf("New() URL = %s, want %s", client.URL, DefaultURL)
+	}
+
+	client, _ = New(&config.WeatherConfig{URL: "http://weather.local/v1/archive"})
+	if client.URL != "http://weather.local/v1/archive" {
+		t.Errorf("New() configured URL = %s", client.URL)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("start_date") != "2025-10-28" || query.Get("end_date") != "2025-10-29" {
+			t.Errorf("dates = %s to %s", query.Get("start_date"), query.Get("end_date"))
+		}
+		if query.Get("latitude") != "37.0000" || query.Get("longitude") != "-122.0000" {
+			t.Errorf("location = %s, %s", query.Get("latitude"), query.Get("longitude"))
+		}
+		if query.Get("temperature_unit") != "fahrenheit" {
+			t.Errorf("temperature_unit = %q, want fahrenheit", query.Get("temperature_unit"))
+		}
+
+		// The last hour has no data yet
+		fmt.Fprint(w, `{
+			"hourly": {
+				"time": ["2025-10-28T10:00", "2025-10-28T11:00", "2025-10-28T12:00"],
+				"temperature_2m": [55.4, 57.2, null],
+				"weather_code": [3, 61, null]
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	start := time.Date(2025, 10, 28, 10, 15, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.0, Longitude: -122.0},
+		{Latitude: 37.0, Longitude: -122.0}, // untimed points are ignored
+		{Timestamp: start.Add(24 * time.Hour), Latitude: 37.0, Longitude: -122.0},
+	}
+
+	client := &Client{URL: server.URL, Client: server.Client()}
+	report, err := client.Lookup(context.Background(), points, true)
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if report.Unit != Fahrenheit || len(report.Hourly) != 2 {
+		t.Fatalf("Lookup() = %+v, want 2 hours in °F", report)
+	}
+
+	observation, ok := report.At(start.Add(time.Hour))
+	if !ok || observation.Temperature != 57.2 || observation.Conditions() != "Light rain" {
+		t.Errorf("At(11:15) = %+v, %v", observation, ok)
+	}
+	if _, ok := report.At(start.Add(2 * time.Hour)); ok {
+		t.Error("At(12:15) found an hour without data")
+	}
+	if got := report.Between(start, start.Add(30*time.Minute)); len(got) != 1 {
+		t.Errorf("Between(10:15, 10:45) = %+v, want the 10:00 hour", got)
+	}
+}
+
+func TestLookupError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": true, "reason": "Parameter 'start_date' is out of allowed range"}`)
+	}))
+	defer server.Close()
+
+	client := &Client{URL: server.URL, Client: server.Client()}
+	points := gps.Points{{Timestamp: time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)}}
+	if _, err := client.Lookup(context.Background(), points, false); err == nil {
+		t.Error("Lookup() error = nil for an API error")
+	}
+
+	// Untimed tracks need no request
+	report, err := (&Client{URL: "http://127.0.0.1:0"}).Lookup(context.Background(), gps.Points{{Latitude: 1}}, false)
+	if err != nil || report.Unit != Celsius || len(report.Hourly) != 0 {
+		t.Errorf("Lookup(untimed) = %+v, %v", report, err)
+	}
+}
+
+func TestConditions(t *testing.T) {
+	tests := map[int]string{0: "Clear sky", 3: "Overcast", 95: "Thunderstorm", 42: "Unknown"}
+	for code, want := range tests {
+		if got := Conditions(code); got != want {
+			t.Errorf("Conditions(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
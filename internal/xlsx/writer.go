@@ -0,0 +1,339 @@
+// Package xlsx provides export of GPS tracks as Excel workbooks.
+//
+// @title XLSX Writer Package
+// @version 1.0
+// @description Writes cleaned GPS points and route statistics as an Office Open XML workbook
+// @description Produces .xlsx files for Excel, LibreOffice Calc, and Google Sheets without external dependencies
+//
+// Features:
+// - Points sheet with real date cells, coordinates, and point metadata
+// - Summary sheet with distance, durations, speeds, time range, and bounds
+// - Per-user breakdown for tracks shared by several users
+// - Distances and speeds in the configured metric or imperial units
+// - Frozen, bold header rows
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/stats"
+)
+
+// Cell style indexes into the cellXfs list of stylesXML.
+const (
+	styleDefault  = 0
+	styleDateTime = 1
+	styleDuration = 2
+	styleHeader   = 3
+)
+
+// excelEpoch is day zero of Excel's 1900 date system, adjusted for its fictional
+// 29 February 1900 so serial numbers of dates from March 1900 onwards are correct.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// cell is one spreadsheet value. Exactly one of text, number, or time is used,
+// chosen by kind.
+type cell struct {
+	kind   byte // 's' text, 'n' number, 't' date and time, 'd' duration, 'h' header text
+	text   string
+	number float64
+	time   time.Time
+}
+
+// sheet is a named worksheet whose first row is a header.
+type sheet struct {
+	name string
+	rows [][]cell
+}
+
+// part is one file of the workbook package.
+type part struct {
+	name    string
+	content string
+}
+
+// Helpers building cells of each kind.
+func text(s string) cell            { return cell{kind: 's', text: s} }
+func header(s string) cell          { return cell{kind: 'h', text: s} }
+func number(f float64) cell         { return cell{kind: 'n', number: f} }
+func dateTime(t time.Time) cell     { return cell{kind: 't', time: t} }
+func duration(d time.Duration) cell { return cell{kind: 'd', number: d.Hours() / 24} }
+
+// Write encodes GPS points and their statistics as an XLSX workbook with a Points
+// sheet listing every cleaned point and a Summary sheet of route statistics.
+//
+// @function Write
+// @description Writes GPS points and route statistics as an Excel workbook
+// @param w io.Writer Destination for the workbook
+// @param points gps.Points Chronologically sorted, processed GPS points
+// @param summary *stats.Summary Route statistics shown on the Summary sheet
+// @param cfg *config.Config Configuration providing the title and distance units
+// @return error Error if encoding or writing fails
+// @example err := xlsx.Write(file, points, stats.Compute(points, &cfg.Statistics), cfg)
+func Write(w io.Writer, points gps.Points, summary *stats.Summary, cfg *config.Config) error {
+	sheets := []sheet{pointsSheet(points), summarySheet(summary, cfg)}
+
+	archive := zip.NewWriter(w)
+	files := []part{
+		{"[Content_Types].xml", contentTypesXML(len(sheets))},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", workbookXML(sheets)},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML(len(sheets))},
+		{"xl/styles.xml", stylesXML},
+	}
+	for i, s := range sheets {
+		files = append(files, part{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheetXML(s)})
+	}
+
+	for _, file := range files {
+		entry, err := archive.Create(file.name)
+		if err != nil {
+			return fmt.Errorf("cannot create workbook part %s: %w", file.name, err)
+		}
+		if _, err := io.WriteString(entry, file.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// WriteFile writes GPS points and their statistics as an XLSX workbook to the named file.
+//
+// @function WriteFile
+// @description Creates an Excel workbook from GPS points
+// @param filename string Path of the .xlsx file to create
+// @param points gps.Points Chronologically sorted, processed GPS points
+// @param summary *stats.Summary Route statistics shown on the Summary sheet
+// @param cfg *config.Config Configuration providing the title and distance units
+// @return error Error if the file cannot be created or written
+// @example err := xlsx.WriteFile("trip.xlsx", points, summary, cfg)
+func WriteFile(filename string, points gps.Points, summary *stats.Summary, cfg *config.Config) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("cannot create XLSX file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	if err := Write(file, points, summary, cfg); err != nil {
+		return fmt.Errorf("cannot write XLSX file %s: %w", filename, err)
+	}
+	return file.Close()
+}
+
+// pointsSheet lists every point, adding the elevation and user columns only when
+// the track has them.
+func pointsSheet(points gps.Points) sheet {
+	withElevation := points.HasElevation()
+	withUser := len(points.Users()) > 0
+
+	head := []cell{header("#"), header("Timestamp"), header("Latitude"), header("Longitude")}
+	if withElevation {
+		head = append(head, header("Elevation (m)"))
+	}
+	if withUser {
+		head = append(head, header("User"))
+	}
+	head = append(head, header("Title"), header("Description"), header("Category"))
+
+	rows := [][]cell{head}
+	for i, point := range points {
+		row := []cell{number(float64(i + 1)), dateTime(point.Timestamp), number(point.Latitude), number(point.Longitude)}
+		if withElevation {
+			row = append(row, number(point.Elevation))
+		}
+		if withUser {
+			row = append(row, text(point.User))
+		}
+		row = append(row, text(point.Title), text(point.Description), text(point.Category))
+		rows = append(rows, row)
+	}
+	return sheet{name: "Points", rows: rows}
+}
+
+// summarySheet lists the route statistics as metric, value, and unit rows,
+// followed by a per-user table when several users share the track.
+func summarySheet(summary *stats.Summary, cfg *config.Config) sheet {
+	unit, distanceUnit, speedUnit := stats.MetersPerKilometer, "km", "km/h"
+	if cfg.Statistics.DistanceUnits == "imperial" {
+		unit, distanceUnit, speedUnit = stats.MetersPerMile, "mi", "mph"
+	}
+	speed := func(kmh float64) cell { return number(kmh * 1000 / unit) }
+
+	rows := [][]cell{
+		{header("Metric"), header("Value"), header("Unit")},
+		{text("Title"), text(cfg.Map.Title), text("")},
+		{text("Points"), number(float64(summary.Points)), text("")},
+		{text("Distance"), number(summary.Distance / unit), text(distanceUnit)},
+		{text("Duration"), duration(summary.Duration), text("h:mm:ss")},
+		{text("Moving Time"), duration(summary.MovingTime), text("h:mm:ss")},
+		{text("Stopped Time"), duration(summary.StoppedTime), text("h:mm:ss")},
+		{text("Average Speed"), speed(summary.AvgSpeed), text(speedUnit)},
+		{text("Moving Average Speed"), speed(summary.MovingAvgSpeed), text(speedUnit)},
+		{text("Max Speed"), speed(summary.MaxSpeed), text(speedUnit)},
+		{text("Pace"), duration(summary.Pace), text("per " + distanceUnit)},
+	}
+	if summary.Points > 0 {
+		rows = append(rows,
+			[]cell{text("Start"), dateTime(summary.Start), text("")},
+			[]cell{text("End"), dateTime(summary.End), text("")},
+			[]cell{text("North"), number(summary.Bounds.North), text("degrees")},
+			[]cell{text("South"), number(summary.Bounds.South), text("degrees")},
+			[]cell{text("East"), number(summary.Bounds.East), text("degrees")},
+			[]cell{text("West"), number(summary.Bounds.West), text("degrees")},
+		)
+	}
+
+	if len(summary.Users) > 0 {
+		rows = append(rows, nil, []cell{
+			header("User"), header("Points"), header("Distance (" + distanceUnit + ")"),
+			header("Duration"), header("Moving Time"), header("Average Speed (" + speedUnit + ")"),
+		})
+		for _, user := range summary.Users {
+			rows = append(rows, []cell{
+				text(user.User), number(float64(user.Summary.Points)), number(user.Summary.Distance / unit),
+				duration(user.Summary.Duration), duration(user.Summary.MovingTime), speed(user.Summary.AvgSpeed),
+			})
+		}
+	}
+	return sheet{name: "Summary", rows: rows}
+}
+
+// worksheetXML renders a sheet with inline strings, freezing its header row.
+func worksheetXML(s sheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	b.WriteString(`<sheetData>`)
+	for r, row := range s.rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, value := range row {
+			ref := ColumnName(c) + strconv.Itoa(r+1)
+			switch value.kind {
+			case 's', 'h':
+				if value.text == "" {
+					continue
+				}
+				style := styleDefault
+				if value.kind == 'h' {
+					style = styleHeader
+				}
+				fmt.Fprintf(&b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(value.text))
+			case 't':
+				if value.time.IsZero() {
+					continue
+				}
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleDateTime, formatNumber(serial(value.time)))
+			case 'd':
+				fmt.Fprintf(&b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleDuration, formatNumber(value.number))
+			default:
+				fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, formatNumber(value.number))
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// ColumnName converts a zero-based column index to its spreadsheet letters
+// (0 is "A", 25 is "Z", 26 is "AA").
+func ColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// serial converts a timestamp to an Excel date serial number. Excel dates carry no
+// time zone, so the timestamp's wall clock time is used as shown on the map.
+func serial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
+
+// formatNumber renders a cell value with the shortest exact representation.
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// escape returns s with XML special characters escaped.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// workbookXML lists the sheets of the workbook.
+func workbookXML(sheets []sheet) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, s := range sheets {
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(s.name), i+1, i+1)
+	}
+	b.WriteString(`</sheets></workbook>`)
+	return b.String()
+}
+
+// workbookRelsXML links the workbook to its worksheets and styles.
+func workbookRelsXML(sheetCount int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i, i)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheetCount+1)
+	b.WriteString(`</Relationships>`)
+	return b.String()
+}
+
+// contentTypesXML declares the content type of every part in the package.
+func contentTypesXML(sheetCount int) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := 1; i <= sheetCount; i++ {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i)
+	}
+	b.WriteString(`</Types>`)
+	return b.String()
+}
+
+// rootRelsXML points the package at its workbook.
+const rootRelsXML = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+// stylesXML defines the cell styles referenced by the style constants: general,
+// date and time (built-in format 22), elapsed duration (built-in format 46), and
+// bold headers.
+const stylesXML = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="46" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
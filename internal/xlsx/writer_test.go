@@ -0,0 +1,152 @@
+// Package xlsx_test provides unit tests for the Excel workbook writer.
+// It tests the package structure, worksheet contents, date serial numbers,
+// unit conversion, and the per-user breakdown.
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saratily/geo-chrono/internal/config"
+	"github.com/saratily/geo-chrono/internal/gps"
+	"github.com/saratily/geo-chrono/internal/stats"
+)
+
+// readWorkbook writes the points to an in-memory workbook and returns its parts by name.
+func readWorkbook(t *testing.T, points gps.Points, cfg *config.Config) map[string]string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, points, stats.Compute(points, &cfg.Statistics), cfg); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Write() produced an invalid zip archive: %v", err)
+	}
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Every part must be well-formed XML
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("part %s is not well-formed XML: %v", file.Name, err)
+			}
+		}
+		parts[file.Name] = string(content)
+	}
+	return parts
+}
+
+func TestWrite(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 37.7749, Longitude: -122.4194, Title: "Start & <home>", User: "alice"},
+		{Timestamp: start.Add(10 * time.Minute), Latitude: 37.7849, Longitude: -122.4094, User: "bob", Category: "park"},
+	}
+	cfg := &config.Config{Map: config.MapConfig{Title: "Trip"}}
+	parts := readWorkbook(t, points, cfg)
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("workbook missing part %s", name)
+		}
+	}
+	for _, want := range []string{`<sheet name="Points"`, `<sheet name="Summary"`} {
+		if !strings.Contains(parts["xl/workbook.xml"], want) {
+			t.Errorf("workbook.xml missing %s", want)
+		}
+	}
+
+	pointsSheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<t xml:space="preserve">User</t>`,
+		`<t xml:space="preserve">Start &amp; &lt;home&gt;</t>`,
+		`<c r="B2" s="1"><v>45958.416666666664</v></c>`, // 2025-10-28 10:00
+		`<c r="C2"><v>37.7749</v></c>`,
+		`<c r="E3" s="0" t="inlineStr"><is><t xml:space="preserve">bob</t></is></c>`,
+	} {
+		if !strings.Contains(pointsSheet, want) {
+			t.Errorf("Points sheet missing %s", want)
+		}
+	}
+	if strings.Contains(pointsSheet, "Elevation") {
+		t.Error("Points sheet has an elevation column without elevation data")
+	}
+
+	summarySheet := parts["xl/worksheets/sheet2.xml"]
+	for _, want := range []string{
+		`<t xml:space="preserve">Distance</t>`,
+		`<t xml:space="preserve">km/h</t>`,
+		`<c r="B5" s="2"><v>0.006944444444444444</v></c>`, // 10 minutes
+		`<t xml:space="preserve">alice</t>`,
+	} {
+		if !strings.Contains(summarySheet, want) {
+			t.Errorf("Summary sheet missing %s", want)
+		}
+	}
+}
+
+func TestWriteImperial(t *testing.T) {
+	start := time.Date(2025, 10, 28, 10, 0, 0, 0, time.UTC)
+	points := gps.Points{
+		{Timestamp: start, Latitude: 0, Longitude: 0, Elevation: 12},
+		{Timestamp: start.Add(time.Hour), Latitude: 0, Longitude: 0.01, Elevation: 15},
+	}
+	cfg := &config.Config{Statistics: config.StatisticsConfig{DistanceUnits: "imperial"}}
+	parts := readWorkbook(t, points, cfg)
+
+	if !strings.Contains(parts["xl/worksheets/sheet1.xml"], "Elevation (m)") {
+		t.Error("Points sheet missing the elevation column")
+	}
+	summarySheet := parts["xl/worksheets/sheet2.xml"]
+	if !strings.Contains(summarySheet, `<t xml:space="preserve">mph</t>`) {
+		t.Error("Summary sheet not in imperial units")
+	}
+	if strings.Contains(summarySheet, `<t xml:space="preserve">User</t>`) {
+		t.Error("Summary sheet has a per-user table for a single-user track")
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"}
+	for index, want := range tests {
+		if got := ColumnName(index); got != want {
+			t.Errorf("ColumnName(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestSerial(t *testing.T) {
+	tests := []struct {
+		time time.Time
+		want float64
+	}{
+		{time.Date(1900, 3, 1, 0, 0, 0, 0, time.UTC), 61},
+		{time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), 45658.5},
+		// Wall clock time is kept regardless of the time zone
+		{time.Date(2025, 1, 1, 12, 0, 0, 0, time.FixedZone("PST", -8*3600)), 45658.5},
+	}
+	for _, tt := range tests {
+		if got := serial(tt.time); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("serial(%v) = %v, want %v", tt.time, got, tt.want)
+		}
+	}
+}